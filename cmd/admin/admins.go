@@ -0,0 +1,106 @@
+package main
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+)
+
+// admins lists, grants or revokes admin rights
+func (a *app) admins(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: admins requires list, add or remove", errUsage)
+	}
+
+	fs := newFlagSet("admins " + args[0])
+	userID := fs.Int64("user", 0, "Telegram user ID")
+	yes := fs.Bool("yes", false, "confirm revoking admin rights")
+	if err := fs.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if args[0] == "list" {
+		return a.listAdmins(ctx)
+	}
+	if args[0] != "add" && args[0] != "remove" {
+		return fmt.Errorf("%w: unknown admins command %q", errUsage, args[0])
+	}
+	if !utils.IsValidTelegramUserID(*userID) {
+		return fmt.Errorf("%w: --user is required", errUsage)
+	}
+
+	if args[0] == "add" {
+		added, err := a.repo.AddAdmin(ctx, &models.Admin{UserID: *userID, Role: models.RoleAdmin, AddedAt: time.Now()})
+		if err != nil {
+			return err
+		}
+		if !added {
+			return fmt.Errorf("user %d is already an admin", *userID)
+		}
+		if err := a.audit(ctx, models.AuditEntry{Action: models.AuditAdminAdded, TargetUserID: *userID}); err != nil {
+			return err
+		}
+
+		if a.json {
+			return a.printJSON(map[string]interface{}{"added": *userID})
+		}
+		fmt.Fprintf(a.out, "User %d is now an admin\n", *userID)
+		return nil
+	}
+
+	if !*yes {
+		return fmt.Errorf("refusing to revoke admin rights of user %d without --yes", *userID)
+	}
+	removed, err := a.repo.RemoveAdmin(ctx, *userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return fmt.Errorf("user %d is not an admin", *userID)
+	}
+	if err := a.audit(ctx, models.AuditEntry{Action: models.AuditAdminRemoved, TargetUserID: *userID}); err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(map[string]interface{}{"removed": *userID})
+	}
+	fmt.Fprintf(a.out, "User %d is no longer an admin\n", *userID)
+	return nil
+}
+
+// listAdmins prints the admins stored in the database; the super-admin is configured with
+// SUPER_ADMIN_ID instead
+func (a *app) listAdmins(ctx context.Context) error {
+	admins, err := a.repo.GetAdmins(ctx)
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(admins)
+	}
+
+	if len(admins) == 0 {
+		fmt.Fprintln(a.out, "No admins besides the super-admin.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USER ID\tROLE\tADDED AT\tADDED BY")
+	for _, admin := range admins {
+		addedBy := "cli"
+		if admin.AddedBy != 0 {
+			addedBy = fmt.Sprintf("%d", admin.AddedBy)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n",
+			admin.UserID,
+			admin.Role,
+			utils.FormatTime(admin.AddedAt, "2006-01-02 15:04"),
+			addedBy)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"attendance-bot/internal/reports"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"os"
+)
+
+// export writes the attendance of a period in one of the formats of cmd/export
+func (a *app) export(ctx context.Context, args []string) error {
+	fs := newFlagSet("export")
+	from := fs.String("from", "", "first day of the period")
+	to := fs.String("to", "", "last day of the period (defaults to --from)")
+	format := fs.String("format", "csv", "csv, pivot, xlsx or json")
+	department := fs.String("department", "", "only users of this department")
+	outPath := fs.String("out", "", `output file (default attendance_<from>_to_<to>.<ext>, "-" for stdout)`)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if *from == "" {
+		return fmt.Errorf("%w: --from is required", errUsage)
+	}
+	if *to == "" {
+		*to = *from
+	}
+	if !utils.IsValidDateFormat(*from) || !utils.IsValidDateFormat(*to) || *from > *to {
+		return fmt.Errorf("invalid period %q to %q, expected YYYY-MM-DD", *from, *to)
+	}
+	exportFormat, ok := reports.ExportFormats[*format]
+	if !ok {
+		return fmt.Errorf("%w: unknown format %q", errUsage, *format)
+	}
+
+	records, err := a.repo.GetAttendanceReportRange(ctx, *from, *to)
+	if err != nil {
+		return err
+	}
+	leaves, err := a.repo.GetApprovedLeaves(ctx, *from, *to)
+	if err != nil {
+		return err
+	}
+	leave := models.ExpandLeave(leaves, *from, *to)
+	shifts, err := a.repo.GetShiftAssignments(ctx)
+	if err != nil {
+		return err
+	}
+	if *department != "" {
+		records, leave = reports.FilterDepartment(records, leave, *department)
+	}
+
+	if *outPath == "" {
+		*outPath = fmt.Sprintf("attendance_%s_to_%s.%s", *from, *to, exportFormat.Extension)
+		if *format == "pivot" {
+			*outPath = fmt.Sprintf("attendance_pivot_%s_to_%s.%s", *from, *to, exportFormat.Extension)
+		}
+	}
+	if *outPath == "-" {
+		if err := exportFormat.Write(a.out, records, leave, shifts); err != nil {
+			return err
+		}
+	} else if err := writeExport(*outPath, exportFormat.Write, records, leave, shifts); err != nil {
+		return err
+	}
+
+	details := fmt.Sprintf("attendance %s..%s %s", *from, *to, *format)
+	if *department != "" {
+		details += " " + *department
+	}
+	if err := a.audit(ctx, models.AuditEntry{Action: models.AuditReportDownloaded, Details: details + " via cmd/admin"}); err != nil {
+		return err
+	}
+
+	// Keep stdout clean for the report itself
+	if *outPath == "-" {
+		return nil
+	}
+	if a.json {
+		return a.printJSON(map[string]interface{}{"records": len(records), "leave_days": len(leave), "written": *outPath})
+	}
+	fmt.Fprintf(a.out, "Exported %d records and %d leave days to %s\n", len(records), len(leave), *outPath)
+	return nil
+}
+
+// writeExport creates the output file and writes the export into it
+func writeExport(path string, write reports.Writer, records []models.AttendanceRecord, leave []models.LeaveDay, shifts *models.ShiftAssignments) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	if err := write(file, records, leave, shifts); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %w", err)
+	}
+	return nil
+}
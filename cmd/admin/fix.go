@@ -0,0 +1,70 @@
+package main
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// fix corrects a user's check-in or check-out of a day with the same rules as the bot's /fix
+func (a *app) fix(ctx context.Context, args []string) error {
+	fs := newFlagSet("fix")
+	userID := fs.Int64("user", 0, "Telegram user ID")
+	date := fs.String("date", "", "date in YYYY-MM-DD format")
+	attendanceType := fs.String("type", "", "check_in or check_out")
+	clock := fs.String("time", "", "corrected time in HH:MM format (--timezone)")
+	remove := fs.Bool("delete", false, "delete the record instead")
+	yes := fs.Bool("yes", false, "confirm the deletion")
+	reason := fs.String("reason", "", "why the record is corrected, kept in the audit log")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if !utils.IsValidTelegramUserID(*userID) {
+		return fmt.Errorf("%w: --user is required", errUsage)
+	}
+	if !utils.IsValidDateFormat(*date) {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", *date)
+	}
+	if *attendanceType != "check_in" && *attendanceType != "check_out" {
+		return fmt.Errorf("invalid type %q, expected check_in or check_out", *attendanceType)
+	}
+
+	correction := attendance.Correction{
+		UserID: *userID,
+		Date:   *date,
+		Type:   *attendanceType,
+		Delete: *remove,
+		Reason: strings.TrimSpace(*reason),
+	}
+	switch {
+	case *remove && *clock != "":
+		return fmt.Errorf("%w: --time and --delete cannot be combined", errUsage)
+	case *remove && !*yes:
+		return fmt.Errorf("refusing to delete the %s of user %d on %s without --yes", *attendanceType, *userID, *date)
+	case !*remove:
+		parsed, err := utils.ParseClock(*clock)
+		if err != nil {
+			return fmt.Errorf("%w: --time HH:MM or --delete is required", errUsage)
+		}
+		correction.Clock = parsed
+	}
+
+	entry, refusal, err := a.service.FixAttendance(ctx, correction)
+	if err != nil {
+		return err
+	}
+	if refusal != "" {
+		return errors.New(refusal)
+	}
+
+	if a.json {
+		return a.printJSON(entry)
+	}
+
+	fmt.Fprintf(a.out, "Corrected record %d: %s (%s)\n", entry.RecordID, entry.Details, entry.Action)
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"attendance-bot/internal/importer"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// importCSV loads historical attendance from a CSV file
+func (a *app) importCSV(ctx context.Context, args []string) error {
+	fs := newFlagSet("import")
+	file := fs.String("file", "", "CSV file with columns user_id,name,date,type,time")
+	rejectsPath := fs.String("rejects", "", "where to write rejected rows (defaults to <file>.rejects.csv)")
+	dryRun := fs.Bool("dry-run", false, "parse and validate without writing")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if *file == "" {
+		return fmt.Errorf("%w: --file is required", errUsage)
+	}
+	if *rejectsPath == "" {
+		*rejectsPath = strings.TrimSuffix(*file, ".csv") + ".rejects.csv"
+	}
+
+	input, err := os.Open(*file)
+	if err != nil {
+		return fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer input.Close()
+
+	result, err := importer.Import(ctx, a.repo, input, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Rejects) > 0 {
+		if err := writeImportRejects(*rejectsPath, result.Rejects); err != nil {
+			return err
+		}
+	}
+
+	if !result.DryRun && result.Inserted > 0 {
+		err := a.audit(ctx, models.AuditEntry{
+			Action:  models.AuditAttendanceImported,
+			Details: fmt.Sprintf("%s: %d of %d rows inserted", filepath.Base(*file), result.Inserted, result.Rows),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if a.json {
+		return a.printJSON(result)
+	}
+
+	if result.DryRun {
+		fmt.Fprintf(a.out, "Dry run: %d rows, %d valid (%d matched by name or employee ID), %d invalid\n",
+			result.Rows, result.Valid, result.Resolved, result.Errors)
+	} else {
+		fmt.Fprintf(a.out, "Imported %d rows: %d inserted, %d skipped (duplicate), %d errors\n",
+			result.Rows, result.Inserted, result.Duplicates, result.Errors)
+	}
+	if len(result.Rejects) > 0 {
+		fmt.Fprintf(a.out, "Rejected rows written to %s\n", *rejectsPath)
+	}
+
+	return nil
+}
+
+// writeImportRejects writes rejected rows with their line number and reason
+func writeImportRejects(path string, rejects []importer.Reject) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create rejects file: %w", err)
+	}
+
+	if err := importer.WriteRejects(file, rejects); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close rejects file: %w", err)
+	}
+	return nil
+}
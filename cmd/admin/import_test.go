@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// importFixture copies a fixture CSV from testdata into a temporary directory, so the rejects
+// file is written next to the copy, and returns the copy's path and a fresh database path
+func importFixture(t *testing.T, name string) (file, dbPath string) {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	dir := t.TempDir()
+	file = filepath.Join(dir, name)
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatalf("failed to copy fixture: %v", err)
+	}
+	return file, filepath.Join(dir, "attendance.db")
+}
+
+func TestImportValidFile(t *testing.T) {
+	file, dbPath := importFixture(t, "import_valid.csv")
+
+	out, err := runAdmin(t, dbPath, "import", "--file", file)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if want := "Imported 3 rows: 3 inserted, 0 skipped (duplicate), 0 errors\n"; out != want {
+		t.Errorf("import printed %q, want %q", out, want)
+	}
+
+	records, err := openTestRepository(t, dbPath).ListAttendance(context.Background(), 1001, "2024-03-04")
+	if err != nil || len(records) != 2 {
+		t.Fatalf("ListAttendance = %d records, %v; want Budi's two", len(records), err)
+	}
+	// 08:00 in Jakarta is 01:00 UTC
+	if want := time.Date(2024, 3, 4, 1, 0, 0, 0, time.UTC); !records[0].Timestamp.Equal(want) {
+		t.Errorf("check-in at %v, want %v", records[0].Timestamp.UTC(), want)
+	}
+	if records[0].LastName == nil || *records[0].LastName != "Santoso" {
+		t.Errorf("check-in last name = %v, want Santoso", records[0].LastName)
+	}
+
+	// Importing the same file again skips every row as a duplicate
+	out, err = runAdmin(t, dbPath, "import", "--file", file)
+	if err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+	if !strings.HasPrefix(out, "Imported 3 rows: 0 inserted, 3 skipped (duplicate), 0 errors\n") {
+		t.Errorf("second import printed %q", out)
+	}
+	if records, _ := openTestRepository(t, dbPath).ListAttendance(context.Background(), 0, ""); len(records) != 3 {
+		t.Errorf("%d records after importing twice, want 3", len(records))
+	}
+}
+
+func TestImportMalformedRows(t *testing.T) {
+	file, dbPath := importFixture(t, "import_malformed.csv")
+
+	out, err := runAdmin(t, dbPath, "import", "--file", file)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if !strings.HasPrefix(out, "Imported 10 rows: 2 inserted, 0 skipped (duplicate), 8 errors\n") {
+		t.Errorf("import printed %q", out)
+	}
+	if records, _ := openTestRepository(t, dbPath).ListAttendance(context.Background(), 0, ""); len(records) != 2 {
+		t.Errorf("%d records imported, want the 2 valid ones", len(records))
+	}
+
+	rejectsFile, err := os.Open(strings.TrimSuffix(file, ".csv") + ".rejects.csv")
+	if err != nil {
+		t.Fatalf("failed to open rejects file: %v", err)
+	}
+	defer rejectsFile.Close()
+	reader := csv.NewReader(rejectsFile)
+	reader.FieldsPerRecord = -1
+	rejects, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read rejects file: %v", err)
+	}
+
+	want := map[string]string{
+		"3":  "invalid date",
+		"4":  "invalid type",
+		"5":  "invalid time",
+		"6":  "expected 5 columns",
+		"7":  "name is empty",
+		"8":  "invalid user_id",
+		"9":  "bare \"",
+		"10": "invalid date",
+	}
+	if len(rejects) != len(want)+1 || rejects[0][0] != "line" || rejects[0][1] != "reason" {
+		t.Fatalf("rejects file =\n%v\nwant a header and %d rows", rejects, len(want))
+	}
+	for _, reject := range rejects[1:] {
+		if reason, ok := want[reject[0]]; !ok || !strings.Contains(reject[1], reason) {
+			t.Errorf("line %s rejected for %q, want %q", reject[0], reject[1], reason)
+		}
+	}
+}
+
+func TestImportDryRunWritesNothing(t *testing.T) {
+	file, dbPath := importFixture(t, "import_malformed.csv")
+
+	out, err := runAdmin(t, dbPath, "import", "--file", file, "--dry-run")
+	if err != nil {
+		t.Fatalf("import --dry-run: %v", err)
+	}
+	if !strings.HasPrefix(out, "Dry run: 10 rows, 2 valid (0 matched by name or employee ID), 8 invalid\n") {
+		t.Errorf("import --dry-run printed %q", out)
+	}
+	if records, _ := openTestRepository(t, dbPath).ListAttendance(context.Background(), 0, ""); len(records) != 0 {
+		t.Errorf("dry run wrote %d records", len(records))
+	}
+}
+
+func TestImportRejectsFile(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "attendance.db")
+	headerless := filepath.Join(dir, "headerless.csv")
+	if err := os.WriteFile(headerless, []byte("1001,Budi,2024-03-04,check_in,08:00\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if _, err := runAdmin(t, dbPath, "import", "--file", headerless); err == nil || !strings.Contains(err.Error(), "header") {
+		t.Errorf("import of a file without header = %v, want a header error", err)
+	}
+	if _, err := runAdmin(t, dbPath, "import", "--file", filepath.Join(dir, "missing.csv")); err == nil {
+		t.Error("import of a missing file succeeded")
+	}
+	if _, err := runAdmin(t, dbPath, "import"); !errors.Is(err, errUsage) {
+		t.Errorf("import without --file = %v, want a usage error", err)
+	}
+}
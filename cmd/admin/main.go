@@ -0,0 +1,432 @@
+package main
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage: admin [--db path] [--json] [--lang id|en] [--timezone NAME] <command> [flags]
+
+Commands:
+  list   [--date YYYY-MM-DD] [--user ID]                 List attendance records
+  add    --user ID --name NAME --date YYYY-MM-DD --time HH:MM --type check_in|check_out
+                                                         Add an attendance record
+  fix    --user ID --date YYYY-MM-DD --type check_in|check_out (--time HH:MM | --delete --yes)
+         [--reason TEXT]                                 Correct a day's record like /fix
+  delete --id ID --yes                                   Delete an attendance record
+  alias  set --user ID --first NAME [--last NAME]        Set a user's display alias
+  alias  clear --user ID --yes                           Remove a user's display alias
+  admins list                                            List the admins
+  admins add --user ID                                   Grant admin rights
+  admins remove --user ID --yes                          Revoke admin rights
+  export --from YYYY-MM-DD [--to YYYY-MM-DD] [--format csv|pivot|xlsx|json]
+         [--department NAME] [--out PATH]                Export attendance like cmd/export
+  report daily [--date YYYY-MM-DD] [--department NAME]   Print a day's attendance report
+  report monthly [--month YYYY-MM] [--department NAME] [--pdf PATH]
+                                                         Print or write a monthly summary
+  import --file PATH [--rejects PATH] [--dry-run]        Import historical records from CSV
+                                                         (columns: user_id,name,date,type,time)
+
+The database (--db PATH or a postgres:// URL) defaults to DATABASE_URL, DATABASE_PATH or
+data/attendance.db. Reports and refusals are written in --lang (default en). Dates and times are
+in --timezone, which defaults to TIMEZONE or Asia/Jakarta. fix and report follow
+OVERTIME_THRESHOLD_MINUTES and HOLIDAYS like the bot.
+`
+
+// errUsage indicates invalid command-line usage
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	// Interrupting cancels the queries in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// app holds the shared state for a single CLI invocation
+type app struct {
+	repo    database.Repository
+	service *attendance.Service
+	out     io.Writer
+	json    bool
+	lang    string
+}
+
+// run parses global flags, opens the database and dispatches the subcommand
+func run(ctx context.Context, args []string, out io.Writer) error {
+	global := flag.NewFlagSet("admin", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	dbPath := global.String("db", getEnvWithDefault("DATABASE_URL", getEnvWithDefault("DATABASE_PATH", "data/attendance.db")), "SQLite database path or PostgreSQL URL")
+	jsonOutput := global.Bool("json", false, "print machine-readable JSON")
+	lang := global.String("lang", "en", "language of reports and refusals")
+	timezone := global.String("timezone", getEnvWithDefault("TIMEZONE", utils.DefaultTimezone), "timezone of dates and times")
+
+	if err := global.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if global.NArg() == 0 {
+		return fmt.Errorf("%w: missing command", errUsage)
+	}
+	if !i18n.IsSupported(*lang) {
+		return fmt.Errorf("%w: unsupported language %q, expected one of %v", errUsage, *lang, i18n.Supported())
+	}
+	if err := utils.SetLocation(*timezone); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	db, err := database.NewDB(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := database.NewRepository(db)
+	service, err := newService(repo)
+	if err != nil {
+		return err
+	}
+
+	a := &app{
+		repo:    repo,
+		service: service,
+		out:     out,
+		json:    *jsonOutput,
+		lang:    *lang,
+	}
+	ctx = i18n.NewContext(ctx, *lang)
+
+	command := global.Arg(0)
+	rest := global.Args()[1:]
+
+	switch command {
+	case "list":
+		return a.list(ctx, rest)
+	case "add":
+		return a.add(ctx, rest)
+	case "fix":
+		return a.fix(ctx, rest)
+	case "delete":
+		return a.delete(ctx, rest)
+	case "alias":
+		return a.alias(ctx, rest)
+	case "admins":
+		return a.admins(ctx, rest)
+	case "export":
+		return a.export(ctx, rest)
+	case "report":
+		return a.report(ctx, rest)
+	case "import":
+		return a.importCSV(ctx, rest)
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, command)
+	}
+}
+
+// list prints attendance records filtered by date and/or user
+func (a *app) list(ctx context.Context, args []string) error {
+	fs := newFlagSet("list")
+	date := fs.String("date", "", "date in YYYY-MM-DD format")
+	userID := fs.Int64("user", 0, "Telegram user ID")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if *date != "" && !utils.IsValidDateFormat(*date) {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", *date)
+	}
+
+	records, err := a.repo.ListAttendance(ctx, *userID, *date)
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(records)
+	}
+
+	if len(records) == 0 {
+		fmt.Fprintln(a.out, "No records found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSER ID\tUSERNAME\tNAME\tDATE\tTYPE\tTIME")
+	for _, record := range records {
+		name := record.FirstName
+		if record.LastName != nil && *record.LastName != "" {
+			name += " " + *record.LastName
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			record.ID,
+			record.UserID,
+			record.Username,
+			name,
+			record.Date,
+			record.Type,
+			utils.FormatTime(record.Timestamp, "HH:mm:ss"))
+	}
+	return w.Flush()
+}
+
+// add inserts an attendance record through the repository so the UNIQUE constraint applies.
+// Archived days and names without letters are refused like the bot's /fix and /alias refuse them.
+func (a *app) add(ctx context.Context, args []string) error {
+	fs := newFlagSet("add")
+	userID := fs.Int64("user", 0, "Telegram user ID")
+	name := fs.String("name", "", "display name (first and optional last name)")
+	username := fs.String("username", "", "Telegram username (defaults to user_<ID>)")
+	date := fs.String("date", "", "date in YYYY-MM-DD format")
+	clock := fs.String("time", "", "time in HH:MM format (--timezone)")
+	attendanceType := fs.String("type", "", "check_in or check_out")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if !utils.IsValidTelegramUserID(*userID) {
+		return fmt.Errorf("%w: --user is required", errUsage)
+	}
+	if *attendanceType != "check_in" && *attendanceType != "check_out" {
+		return fmt.Errorf("invalid type %q, expected check_in or check_out", *attendanceType)
+	}
+	if !utils.IsValidDateFormat(*date) {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", *date)
+	}
+
+	timestamp, err := time.ParseInLocation("2006-01-02 15:04", *date+" "+*clock, utils.Location)
+	if err != nil {
+		return fmt.Errorf("invalid date/time %q %q: %w", *date, *clock, err)
+	}
+
+	nameParts := strings.Fields(*name)
+	if len(nameParts) == 0 {
+		return fmt.Errorf("%w: --name is required", errUsage)
+	}
+	firstName := utils.SanitizeName(nameParts[0])
+	if firstName == "" {
+		return errors.New(i18n.T(a.lang, "alias.invalid_first_name"))
+	}
+	var lastName *string
+	if len(nameParts) > 1 {
+		if lastNameVal := utils.SanitizeName(strings.Join(nameParts[1:], " ")); lastNameVal != "" {
+			lastName = &lastNameVal
+		}
+	}
+
+	// Archived days are read-only, their records are no longer in the attendance table
+	archivedBefore, err := a.repo.GetArchivedBefore(ctx)
+	if err != nil {
+		return err
+	}
+	if archivedBefore != "" && *date < archivedBefore {
+		return errors.New(i18n.T(a.lang, "fix.archived", "Before", archivedBefore))
+	}
+
+	if *username == "" {
+		*username = fmt.Sprintf("user_%d", *userID)
+	}
+
+	record, err := a.repo.InsertAttendance(ctx, &models.AttendanceRecord{
+		UserID:    *userID,
+		Username:  utils.SanitizeUsername(*username),
+		FirstName: firstName,
+		LastName:  lastName,
+		Timestamp: timestamp,
+		Type:      *attendanceType,
+		Date:      *date,
+		Source:    models.SourceAdmin,
+	})
+	if err != nil {
+		return err
+	}
+	err = a.audit(ctx, models.AuditEntry{
+		Action:       models.AuditAttendanceAdded,
+		TargetUserID: record.UserID,
+		RecordID:     record.ID,
+		Details:      fmt.Sprintf("%s %s: - → %s", record.Type, record.Date, utils.FormatTime(record.Timestamp, "HH:mm")),
+	})
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(record)
+	}
+
+	fmt.Fprintf(a.out, "Added record %d: user %d %s on %s at %s\n",
+		record.ID, record.UserID, record.Type, record.Date, utils.FormatTime(record.Timestamp, "HH:mm"))
+	return nil
+}
+
+// delete removes an attendance record by ID
+func (a *app) delete(ctx context.Context, args []string) error {
+	fs := newFlagSet("delete")
+	id := fs.Int64("id", 0, "attendance record ID")
+	yes := fs.Bool("yes", false, "confirm the deletion")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if *id <= 0 {
+		return fmt.Errorf("%w: --id is required", errUsage)
+	}
+	if !*yes {
+		return fmt.Errorf("refusing to delete record %d without --yes", *id)
+	}
+
+	record, err := a.repo.GetAttendanceRecord(ctx, *id)
+	if err != nil {
+		return err
+	}
+	deleted := false
+	if record != nil {
+		if deleted, err = a.repo.DeleteAttendance(ctx, *id); err != nil {
+			return err
+		}
+	}
+	if !deleted {
+		return fmt.Errorf("record %d not found", *id)
+	}
+	err = a.audit(ctx, models.AuditEntry{
+		Action:       models.AuditAttendanceDeleted,
+		TargetUserID: record.UserID,
+		RecordID:     record.ID,
+		Details:      fmt.Sprintf("%s %s: %s → -", record.Type, record.Date, utils.FormatTime(record.Timestamp, "HH:mm")),
+	})
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(map[string]interface{}{"deleted": *id})
+	}
+
+	fmt.Fprintf(a.out, "Deleted record %d\n", *id)
+	return nil
+}
+
+// alias sets or clears a user's display alias
+func (a *app) alias(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: alias requires set or clear", errUsage)
+	}
+
+	fs := newFlagSet("alias " + args[0])
+	userID := fs.Int64("user", 0, "Telegram user ID")
+	first := fs.String("first", "", "alias first name")
+	last := fs.String("last", "", "alias last name")
+	yes := fs.Bool("yes", false, "confirm removing the alias")
+	if err := fs.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if !utils.IsValidTelegramUserID(*userID) {
+		return fmt.Errorf("%w: --user is required", errUsage)
+	}
+
+	switch args[0] {
+	case "set":
+		firstName := utils.SanitizeName(*first)
+		if firstName == "" {
+			return fmt.Errorf("%w: --first is required", errUsage)
+		}
+		var lastName *string
+		if lastNameVal := utils.SanitizeName(*last); lastNameVal != "" {
+			lastName = &lastNameVal
+		}
+
+		if err := a.repo.SetUserAlias(ctx, *userID, firstName, lastName); err != nil {
+			return err
+		}
+		aliasName := firstName
+		if lastName != nil {
+			aliasName += " " + *lastName
+		}
+		err := a.audit(ctx, models.AuditEntry{Action: models.AuditAliasSet, TargetUserID: *userID, Details: aliasName})
+		if err != nil {
+			return err
+		}
+
+		if a.json {
+			return a.printJSON(models.UserAlias{UserID: *userID, FirstName: firstName, LastName: lastName})
+		}
+		fmt.Fprintf(a.out, "Alias set for user %d\n", *userID)
+		return nil
+
+	case "clear":
+		if !*yes {
+			return fmt.Errorf("refusing to clear alias for user %d without --yes", *userID)
+		}
+
+		deleted, err := a.repo.DeleteUserAlias(ctx, *userID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return fmt.Errorf("user %d has no alias", *userID)
+		}
+		if err := a.audit(ctx, models.AuditEntry{Action: models.AuditAliasCleared, TargetUserID: *userID}); err != nil {
+			return err
+		}
+
+		if a.json {
+			return a.printJSON(map[string]interface{}{"cleared": *userID})
+		}
+		fmt.Fprintf(a.out, "Alias cleared for user %d\n", *userID)
+		return nil
+
+	default:
+		return fmt.Errorf("%w: unknown alias command %q", errUsage, args[0])
+	}
+}
+
+// audit records a change made with the CLI in the audit log, with actor ID 0
+func (a *app) audit(ctx context.Context, entry models.AuditEntry) error {
+	entry.CreatedAt = time.Now()
+	if err := a.repo.InsertAuditEntry(ctx, &entry); err != nil {
+		return fmt.Errorf("change made but not recorded in the audit log: %w", err)
+	}
+	return nil
+}
+
+// printJSON writes v as indented JSON
+func (a *app) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(a.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// newFlagSet creates a subcommand flag set that reports errors instead of exiting
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// getEnvWithDefault returns the environment variable value or a default if not set
+func getEnvWithDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestDB returns the path of a fresh database in a temporary directory
+func newTestDB(t *testing.T) string {
+	t.Helper()
+	return filepath.Join(t.TempDir(), "attendance.db")
+}
+
+// runAdmin runs the CLI against the database at dbPath, returning what it printed
+func runAdmin(t *testing.T, dbPath string, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	err := run(context.Background(), append([]string{"--db", dbPath, "--timezone", "Asia/Jakarta"}, args...), &out)
+	return out.String(), err
+}
+
+// openTestRepository opens the database the CLI wrote to
+func openTestRepository(t *testing.T, dbPath string) database.Repository {
+	t.Helper()
+
+	db, err := database.NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return database.NewRepository(db)
+}
+
+func TestAddAndList(t *testing.T) {
+	dbPath := newTestDB(t)
+
+	out, err := runAdmin(t, dbPath, "add", "--user", "1001", "--name", "Budi Santoso", "--date", "2024-03-04", "--time", "08:15", "--type", "check_in")
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if want := "Added record 1: user 1001 check_in on 2024-03-04 at 08:15\n"; out != want {
+		t.Errorf("add printed %q, want %q", out, want)
+	}
+
+	out, err = runAdmin(t, dbPath, "list", "--date", "2024-03-04")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ID") {
+		t.Fatalf("list printed %q, want a header and one row", out)
+	}
+	for _, field := range []string{"1001", "user_1001", "Budi Santoso", "2024-03-04", "check_in", "08:15:00"} {
+		if !strings.Contains(lines[1], field) {
+			t.Errorf("list row %q lacks %q", lines[1], field)
+		}
+	}
+
+	out, err = runAdmin(t, dbPath, "--json", "list", "--user", "1001")
+	if err != nil {
+		t.Fatalf("list --json: %v", err)
+	}
+	var records []models.AttendanceRecord
+	if err := json.Unmarshal([]byte(out), &records); err != nil {
+		t.Fatalf("list --json printed invalid JSON %q: %v", out, err)
+	}
+	if len(records) != 1 || records[0].FirstName != "Budi" || records[0].Source != models.SourceAdmin {
+		t.Errorf("list --json = %+v, want Budi's admin record", records)
+	}
+
+	if out, _ := runAdmin(t, dbPath, "list", "--date", "2024-03-05"); out != "No records found.\n" {
+		t.Errorf("list of an empty day printed %q", out)
+	}
+}
+
+func TestAddValidates(t *testing.T) {
+	dbPath := newTestDB(t)
+	add := []string{"add", "--user", "1001", "--name", "Budi", "--date", "2024-03-04", "--time", "08:15", "--type", "check_in"}
+	if _, err := runAdmin(t, dbPath, add...); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	if _, err := runAdmin(t, dbPath, add...); !errors.Is(err, database.ErrDuplicate) {
+		t.Errorf("second check-in of the day = %v, want ErrDuplicate", err)
+	}
+
+	tests := [][]string{
+		{"add", "--user", "1001", "--name", "Budi", "--date", "2024-03-04", "--time", "08:15", "--type", "lunch"},
+		{"add", "--user", "1001", "--name", "Budi", "--date", "04-03-2024", "--time", "08:15", "--type", "check_out"},
+		{"add", "--user", "1001", "--name", "Budi", "--date", "2024-03-04", "--time", "25:00", "--type", "check_out"},
+		{"add", "--user", "1001", "--name", " ", "--date", "2024-03-04", "--time", "17:00", "--type", "check_out"},
+		{"add", "--name", "Budi", "--date", "2024-03-04", "--time", "17:00", "--type", "check_out"},
+	}
+	for _, args := range tests {
+		if _, err := runAdmin(t, dbPath, args...); err == nil {
+			t.Errorf("%v accepted", args)
+		}
+	}
+}
+
+// TestAddRefusals checks that add refuses what the bot refuses, with the bot's messages
+func TestAddRefusals(t *testing.T) {
+	dbPath := newTestDB(t)
+	if err := openTestRepository(t, dbPath).SetArchivedBefore(context.Background(), "2024-01-01"); err != nil {
+		t.Fatalf("SetArchivedBefore: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		lang     string
+		fullName string
+		date     string
+		want     string // Error message, empty if the record is added
+	}{
+		{name: "archived day", lang: "en", fullName: "Budi", date: "2023-12-31", want: i18n.T("en", "fix.archived", "Before", "2024-01-01")},
+		{name: "archived day in Indonesian", lang: "id", fullName: "Budi", date: "2023-06-01", want: i18n.T("id", "fix.archived", "Before", "2024-01-01")},
+		{name: "first name without letters", lang: "en", fullName: "123 Santoso", date: "2024-03-04", want: i18n.T("en", "alias.invalid_first_name")},
+		{name: "first name of symbols", lang: "en", fullName: "<>", date: "2024-03-04", want: i18n.T("en", "alias.invalid_first_name")},
+		{name: "first day after the archive", lang: "en", fullName: "Budi !!", date: "2024-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runAdmin(t, dbPath, "--lang", tt.lang, "add", "--user", "1001", "--name", tt.fullName,
+				"--date", tt.date, "--time", "08:15", "--type", "check_in")
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("add = %v, want the record added", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("add = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	records, err := openTestRepository(t, dbPath).ListAttendance(context.Background(), 1001, "")
+	if err != nil {
+		t.Fatalf("ListAttendance: %v", err)
+	}
+	if len(records) != 1 || records[0].LastName != nil {
+		t.Errorf("records = %+v, want the one added without a last name", records)
+	}
+}
+
+func TestDeleteNeedsYes(t *testing.T) {
+	dbPath := newTestDB(t)
+	if _, err := runAdmin(t, dbPath, "add", "--user", "1001", "--name", "Budi", "--date", "2024-03-04", "--time", "08:15", "--type", "check_in"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	if _, err := runAdmin(t, dbPath, "delete", "--id", "1"); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("delete without --yes = %v, want a refusal", err)
+	}
+	if out, err := runAdmin(t, dbPath, "delete", "--id", "1", "--yes"); err != nil || out != "Deleted record 1\n" {
+		t.Errorf("delete --yes = %q, %v", out, err)
+	}
+	if _, err := runAdmin(t, dbPath, "delete", "--id", "1", "--yes"); err == nil {
+		t.Error("deleting a deleted record succeeded")
+	}
+
+	entries, err := openTestRepository(t, dbPath).GetAuditLog(context.Background(), 1001, 10)
+	if err != nil {
+		t.Fatalf("GetAuditLog: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Action != models.AuditAttendanceDeleted {
+		t.Errorf("audit log = %+v, want the addition and the deletion", entries)
+	}
+}
+
+func TestAlias(t *testing.T) {
+	dbPath := newTestDB(t)
+
+	if out, err := runAdmin(t, dbPath, "alias", "set", "--user", "1001", "--first", "Pak", "--last", "Budi"); err != nil || out != "Alias set for user 1001\n" {
+		t.Fatalf("alias set = %q, %v", out, err)
+	}
+	alias, err := openTestRepository(t, dbPath).GetUserAlias(context.Background(), 1001)
+	if err != nil || alias == nil || alias.FirstName != "Pak" || alias.LastName == nil || *alias.LastName != "Budi" {
+		t.Errorf("stored alias = %+v, %v; want Pak Budi", alias, err)
+	}
+
+	if _, err := runAdmin(t, dbPath, "alias", "clear", "--user", "1001"); err == nil {
+		t.Error("alias clear without --yes succeeded")
+	}
+	if out, err := runAdmin(t, dbPath, "alias", "clear", "--user", "1001", "--yes"); err != nil || out != "Alias cleared for user 1001\n" {
+		t.Errorf("alias clear --yes = %q, %v", out, err)
+	}
+	if _, err := runAdmin(t, dbPath, "alias", "clear", "--user", "1001", "--yes"); err == nil {
+		t.Error("clearing a cleared alias succeeded")
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	dbPath := newTestDB(t)
+
+	tests := [][]string{
+		{},
+		{"frobnicate"},
+		{"alias"},
+		{"alias", "rename", "--user", "1001"},
+		{"list", "--bogus"},
+		{"--lang", "fr", "list"},
+	}
+	for _, args := range tests {
+		if _, err := runAdmin(t, dbPath, args...); !errors.Is(err, errUsage) {
+			t.Errorf("%v = %v, want a usage error", args, err)
+		}
+	}
+}
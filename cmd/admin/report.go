@@ -0,0 +1,90 @@
+package main
+
+import (
+	"attendance-bot/internal/reports"
+	"attendance-bot/internal/utils"
+	"context"
+	"fmt"
+	"os"
+)
+
+// report prints the daily attendance report or a monthly summary as the bot sends them
+func (a *app) report(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: report requires daily or monthly", errUsage)
+	}
+
+	switch args[0] {
+	case "daily":
+		return a.dailyReport(ctx, args[1:])
+	case "monthly":
+		return a.monthlyReport(ctx, args[1:])
+	default:
+		return fmt.Errorf("%w: unknown report %q", errUsage, args[0])
+	}
+}
+
+// dailyReport prints the attendance report of a day, like /report
+func (a *app) dailyReport(ctx context.Context, args []string) error {
+	fs := newFlagSet("report daily")
+	date := fs.String("date", utils.GetTodayDate(), "date in YYYY-MM-DD format")
+	department := fs.String("department", "", "only users of this department")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if !utils.IsValidDateFormat(*date) {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", *date)
+	}
+
+	report, err := a.service.GenerateDepartmentReport(ctx, *date, *department, a.lang)
+	if err != nil {
+		return err
+	}
+
+	if a.json {
+		return a.printJSON(map[string]interface{}{"date": *date, "department": *department, "report": report})
+	}
+	fmt.Fprintln(a.out, report)
+	return nil
+}
+
+// monthlyReport prints the per-employee summary of a month, like /monthly, or writes it as a PDF
+func (a *app) monthlyReport(ctx context.Context, args []string) error {
+	fs := newFlagSet("report monthly")
+	month := fs.String("month", utils.Now().Format("2006-01"), "month in YYYY-MM format")
+	department := fs.String("department", "", "only users of this department")
+	pdfPath := fs.String("pdf", "", "write the summary as a PDF file instead")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	summary, err := a.service.GetMonthlySummary(ctx, *month, *department)
+	if err != nil {
+		return err
+	}
+
+	if *pdfPath != "" {
+		file, err := os.Create(*pdfPath)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		if err := reports.WriteSummaryPDF(file, summary, a.lang, utils.Now()); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("failed to close output file: %w", err)
+		}
+		fmt.Fprintf(a.out, "Monthly summary of %s written to %s\n", *month, *pdfPath)
+		return nil
+	}
+
+	if a.json {
+		return a.printJSON(summary)
+	}
+	for _, message := range reports.FormatMonthlySummary(summary, a.lang) {
+		fmt.Fprintln(a.out, message)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/scheduler"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// newService creates the attendance service behind fix and report, with the bot's settings
+// that change their results read from the same environment variables
+func newService(repo database.Repository) (*attendance.Service, error) {
+	// The CLI never checks codes, so the service needs no TOTP secret
+	service := attendance.NewService(repo, attendance.NewTOTPService(""))
+
+	if value := os.Getenv("OVERTIME_THRESHOLD_MINUTES"); value != "" {
+		minutes, err := strconv.Atoi(value)
+		if err != nil || minutes < 0 || minutes > 1440 {
+			return nil, fmt.Errorf("invalid value for OVERTIME_THRESHOLD_MINUTES: %q", value)
+		}
+		service.SetOvertimeThreshold(time.Duration(minutes) * time.Minute)
+	}
+
+	holidays, err := scheduler.ParseHolidays(strings.Split(os.Getenv("HOLIDAYS"), ","))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for HOLIDAYS: %w", err)
+	}
+	service.SetFixedHolidays(holidays)
+
+	return service, nil
+}
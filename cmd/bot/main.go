@@ -1,67 +1,377 @@
 package main
 
 import (
+	"attendance-bot/internal/api"
 	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/backup"
 	"attendance-bot/internal/bot"
 	"attendance-bot/internal/config"
 	"attendance-bot/internal/database"
+	"attendance-bot/internal/health"
+	"attendance-bot/internal/logging"
 	"attendance-bot/internal/reports"
+	"attendance-bot/internal/utils"
+	"attendance-bot/internal/webhooks"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func main() {
-	// Initialize logger
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
+	flags, err := config.ParseFlags(os.Args[1:], os.Stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.LoadWithFlags(flags)
 	if err != nil {
-		logger.Error("Failed to load configuration", "error", err)
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	// Dates, shifts and schedules are in the configured timezone from here on
+	if err := utils.SetLocation(cfg.Timezone); err != nil {
+		slog.Error("Failed to set timezone", "error", err)
 		os.Exit(1)
 	}
 
-	logger.Info("Configuration loaded", "environment", cfg.Environment)
+	// Initialize logger
+	logger, logFile, err := newLogger(cfg)
+	if err != nil {
+		slog.Error("Failed to initialize logger", "error", err)
+		os.Exit(1)
+	}
+	if logFile != nil {
+		defer logFile.Close()
+		go reopenOnHangup(logFile, logger)
+	}
+	// Log lines without a request-scoped logger, such as queries at start-up, use the same handler
+	slog.SetDefault(logger)
+
+	logger.Info("Configuration loaded", "config", cfg)
 
 	// Initialize database
-	db, err := database.NewSQLiteDB(cfg.DatabasePath)
+	db, err := openDatabase(cfg)
 	if err != nil {
 		logger.Error("Failed to initialize database", "error", err)
 		os.Exit(1)
 	}
 	defer db.Close()
 
-	logger.Info("Database initialized", "path", cfg.DatabasePath)
+	logger.Info("Database initialized", "engine", db.Engine(), "database", database.RedactTarget(cfg.DatabaseTarget()))
 
 	// Initialize repository
 	repo := database.NewRepository(db)
 
+	// Initialize TOTP service
+	if !attendance.ValidateSecret(cfg.TOTPSecret) {
+		logger.Error("Invalid TOTP_SECRET: must be base32 and decode to at least 10 bytes")
+		os.Exit(1)
+	}
+	if cfg.TOTPSecretPrevious != "" && !attendance.ValidateSecret(cfg.TOTPSecretPrevious) {
+		logger.Error("Invalid TOTP_SECRET_PREVIOUS: must be base32 and decode to at least 10 bytes")
+		os.Exit(1)
+	}
+
+	algorithm, err := attendance.ParseAlgorithm(cfg.TOTPAlgorithm)
+	if err != nil {
+		logger.Error("Invalid TOTP algorithm", "error", err)
+		os.Exit(1)
+	}
+	totpService := attendance.NewTOTPServiceWithOptions(cfg.TOTPSecret, &attendance.TOTPOptions{
+		Algorithm: algorithm,
+		Digits:    cfg.TOTPDigits,
+		Period:    cfg.TOTPPeriod,
+		Skew:      &cfg.TOTPSkew,
+	})
+
 	// Initialize attendance service
-	attendanceService := attendance.NewService(repo, cfg.TOTPSecret)
+	attendanceService := attendance.NewService(repo, totpService)
+	attendanceService.SetReportFreshness(time.Duration(cfg.ReportCacheSeconds) * time.Second)
+	attendanceService.SetExpectedWorkHours(time.Duration(cfg.ExpectedWorkHours) * time.Hour)
+	attendanceService.SetPhotoWindow(time.Duration(cfg.PhotoWindow) * time.Minute)
+	attendanceService.SetOvertimeThreshold(time.Duration(cfg.OvertimeThreshold) * time.Minute)
+	attendanceService.SetMaxBreaks(cfg.MaxBreaks)
+	attendanceService.SetFixedHolidays(cfg.Holidays)
+	if cfg.HolidayFeedURL != "" {
+		attendanceService.SetHolidayFeed(attendance.NewNagerHolidayFeed(cfg.HolidayFeedURL))
+	}
+	attendanceService.SetGeofenceMode(cfg.GeofenceMode)
+	attendanceService.SetRequireRegistration(cfg.RequireRegistration)
+	attendanceService.SetWFHApproval(cfg.WFHApproval)
+	attendanceService.SetOTPLockout(cfg.OTPLockoutLimit, time.Duration(cfg.OTPFailureWindow)*time.Minute,
+		time.Duration(cfg.OTPLockoutMinutes)*time.Minute)
+
+	// SQLite databases are backed up on BACKUP_SCHEDULE and with /backup now
+	if db.Engine() == database.SQLite {
+		backups := backup.New(db, cfg.BackupDir, cfg.BackupKeep)
+		if cfg.BackupS3Bucket != "" {
+			uploader, err := backup.NewS3(backup.S3Options{
+				Endpoint:  cfg.BackupS3Endpoint,
+				Bucket:    cfg.BackupS3Bucket,
+				Region:    cfg.BackupS3Region,
+				Prefix:    cfg.BackupS3Prefix,
+				AccessKey: cfg.BackupS3AccessKey,
+				SecretKey: cfg.BackupS3SecretKey,
+			})
+			if err != nil {
+				logger.Error("Invalid backup upload settings", "error", err)
+				os.Exit(1)
+			}
+			backups.SetUploader(uploader)
+		}
+		attendanceService.SetBackups(backups)
+	}
 
-	// Initialize CSV generator
-	csvGenerator := reports.NewCSVGenerator("temp")
+	// Configure encryption of per-user secrets at rest
+	if cfg.SecretsKey != "" {
+		cipher, err := newSecretCipher(cfg.SecretsKey)
+		if err != nil {
+			logger.Error("Invalid SECRETS_ENCRYPTION_KEY", "error", err)
+			os.Exit(1)
+		}
+		attendanceService.SetSecretCipher(cipher)
+
+		if cfg.SecretsKeyPrevious != "" {
+			previous, err := newSecretCipher(cfg.SecretsKeyPrevious)
+			if err != nil {
+				logger.Error("Invalid SECRETS_ENCRYPTION_KEY_PREVIOUS", "error", err)
+				os.Exit(1)
+			}
+			count, err := attendanceService.ReencryptSecrets(context.Background(), previous)
+			if err != nil {
+				logger.Error("Failed to re-encrypt user secrets", "error", err)
+				os.Exit(1)
+			}
+			logger.Info("Re-encrypted user secrets with the new key", "count", count)
+		}
+	}
+	if err := attendanceService.CheckSecretsEncryption(context.Background()); err != nil {
+		logger.Error("Encrypted user secrets cannot be read", "error", err)
+		os.Exit(1)
+	}
+
+	// Keep accepting the previous secret during a rotation grace period
+	if cfg.TOTPSecretPrevious != "" {
+		previousTOTP := attendance.NewTOTPServiceWithOptions(cfg.TOTPSecretPrevious, &attendance.TOTPOptions{
+			Algorithm: algorithm,
+			Digits:    cfg.TOTPDigits,
+			Period:    cfg.TOTPPeriod,
+			Skew:      &cfg.TOTPSkew,
+		})
+		grace := time.Duration(cfg.TOTPRotationGrace) * 24 * time.Hour
+		attendanceService.SetSecretRotation(previousTOTP, cfg.TOTPRotatedAt, grace)
+		logger.Info("TOTP secret rotation active", "rotated_at", cfg.TOTPRotatedAt.Format("2006-01-02"), "grace_days", cfg.TOTPRotationGrace)
+	}
+
+	// A secret rotated with /rotatesecret replaces the configured ones, until TOTP_SECRET is changed
+	rotated, discarded, err := attendanceService.LoadRotatedSecret(context.Background())
+	if err != nil {
+		logger.Error("Rotated TOTP secret cannot be read", "error", err)
+		os.Exit(1)
+	}
+	if rotated {
+		logger.Info("Using the TOTP secret rotated with /rotatesecret, TOTP_SECRET and TOTP_SECRET_PREVIOUS are ignored")
+	}
+	if discarded {
+		logger.Warn("TOTP_SECRET changed since the last /rotatesecret, the rotated secret was discarded")
+	}
+
+	if flags.DryRun {
+		if err := dryRun(cfg, logger); err != nil {
+			logger.Error("Dry run failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Initialize report generators
+	csvGenerator := reports.NewCSVGenerator()
+	xlsxGenerator := reports.NewXLSXGenerator("temp")
+	pdfGenerator := reports.NewPDFGenerator("temp")
 
 	// Initialize bot
-	botInstance := bot.NewBot(cfg.BotToken, attendanceService, csvGenerator, cfg, logger)
+	botInstance := bot.NewBot(cfg.BotToken, attendanceService, csvGenerator, xlsxGenerator, pdfGenerator, cfg, logger)
 
 	// Set up graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// Start bot in a goroutine
-	go func() {
-		if err := botInstance.Start(); err != nil {
-			logger.Error("Bot error", "error", err)
-			os.Exit(1)
+	// Send attendance events to external systems if configured. Deliveries outlive the signal
+	// context, so events of updates still in flight at shutdown are queued before they stop.
+	var webhooksDone chan struct{}
+	webhooksCtx, stopWebhooks := context.WithCancel(context.Background())
+	defer stopWebhooks()
+	if len(cfg.EventWebhookURLs) > 0 {
+		dispatcher := webhooks.NewDispatcher(cfg.EventWebhookURLs, cfg.EventWebhookSecret, cfg.EventWebhookTries, attendanceService, logger)
+		attendanceService.AddAttendanceHook(dispatcher.Notify)
+		webhooksDone = make(chan struct{})
+		go func() {
+			defer close(webhooksDone)
+			dispatcher.Run(webhooksCtx)
+		}()
+		logger.Info("Event webhooks enabled", "urls", len(cfg.EventWebhookURLs), "signed", cfg.EventWebhookSecret != "")
+	}
+
+	// Start the health endpoints if configured
+	var healthServer *health.Server
+	if cfg.HealthAddr != "" {
+		healthServer = health.NewServer(cfg.HealthAddr, db, botInstance, attendanceService, logger)
+		go func() {
+			if err := healthServer.Start(); err != nil {
+				logger.Error("Health server error", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
+	// Start the read-only HTTP API if configured
+	var apiServer *api.Server
+	if cfg.APIAddr != "" {
+		apiServer = api.NewServer(cfg.APIAddr, cfg.APIKeys, attendanceService, logger)
+		go func() {
+			if err := apiServer.Start(); err != nil {
+				logger.Error("API server error", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
+	// Run the bot until a shutdown signal; Start returns once in-flight updates have finished
+	// and the update offset is saved, so the deferred database close happens last
+	botErr := botInstance.Start(ctx)
+	if botErr != nil {
+		logger.Error("Bot error", "error", botErr)
+	} else {
+		logger.Info("Shutting down gracefully...")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if apiServer != nil {
+		if err := apiServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to stop API server", "error", err)
+		}
+	}
+	if healthServer != nil {
+		if err := healthServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to stop health server", "error", err)
 		}
-	}()
+	}
+
+	// Events not delivered by now are recorded as webhook failures, which needs the database
+	if webhooksDone != nil {
+		stopWebhooks()
+		<-webhooksDone
+	}
+
+	if botErr != nil {
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+// newLogger builds the application logger from the configured level, format and destination.
+// The returned writer is nil unless LOG_FILE is set.
+func newLogger(cfg *config.Config) (*slog.Logger, *logging.RotatingWriter, error) {
+	options := &slog.HandlerOptions{Level: logging.ParseLevel(cfg.LogLevel)}
+	if cfg.LogFile == "" {
+		return slog.New(newLogHandler(cfg.LogFormat, os.Stdout, options)), nil, nil
+	}
+
+	file, err := logging.NewRotatingWriter(cfg.LogFile, &logging.RotateOptions{
+		MaxSize:    int64(cfg.LogMaxSizeMB) * 1024 * 1024,
+		MaxBackups: cfg.LogMaxBackups,
+		MaxAge:     time.Duration(cfg.LogMaxAgeDays) * 24 * time.Hour,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handler := newLogHandler(cfg.LogFormat, file, options)
+	if cfg.LogStdoutLevel != "off" {
+		stdoutOptions := &slog.HandlerOptions{Level: logging.ParseLevel(cfg.LogStdoutLevel)}
+		handler = logging.NewTeeHandler(handler, newLogHandler(cfg.LogFormat, os.Stdout, stdoutOptions))
+	}
+
+	return slog.New(handler), file, nil
+}
+
+// newLogHandler returns a text or JSON handler writing to w
+func newLogHandler(format string, w io.Writer, options *slog.HandlerOptions) slog.Handler {
+	if format == "json" {
+		return slog.NewJSONHandler(w, options)
+	}
+	return slog.NewTextHandler(w, options)
+}
+
+// reopenOnHangup reopens the log file on SIGHUP, so logrotate can move it away
+func reopenOnHangup(file *logging.RotatingWriter, logger *slog.Logger) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	for range hangup {
+		if err := file.Reopen(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to reopen log file: %v\n", err)
+			continue
+		}
+		logger.Info("Log file reopened")
+	}
+}
+
+// dryRun checks the bot token against Telegram; configuration and database were already verified
+func dryRun(cfg *config.Config, logger *slog.Logger) error {
+	me, err := bot.NewTelegramAPIWithOptions(cfg.BotToken, &bot.TelegramAPIOptions{APIURL: cfg.TelegramAPIURL}).GetMe(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to reach Telegram: %w", err)
+	}
+
+	logger.Info("Dry run successful", "bot_username", me.Username)
+	return nil
+}
+
+// newSecretCipher builds a secret cipher from a base64-encoded key
+func newSecretCipher(encodedKey string) (*attendance.SecretCipher, error) {
+	key, err := attendance.ParseEncryptionKey(encodedKey)
+	if err != nil {
+		return nil, err
+	}
+	return attendance.NewSecretCipher(key)
+}
+
+// openDatabase opens the database, migrating it automatically unless AUTO_MIGRATE=false,
+// in which case the schema must already be up to date (see cmd/migrate)
+func openDatabase(cfg *config.Config) (*database.DB, error) {
+	if cfg.AutoMigrate {
+		return database.NewDB(cfg.DatabaseTarget())
+	}
+
+	db, err := database.OpenDB(cfg.DatabaseTarget())
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := db.PendingMigrations(0)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if len(pending) > 0 {
+		db.Close()
+		return nil, fmt.Errorf("%d pending migrations, run cmd/migrate up", len(pending))
+	}
 
-	// Wait for shutdown signal
-	<-sigChan
-	logger.Info("Shutting down gracefully...")
+	return db, nil
 }
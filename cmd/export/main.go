@@ -0,0 +1,196 @@
+package main
+
+import (
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/reports"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+const usage = `Usage: export [flags]
+
+Flags:
+  --db PATH|URL                 SQLite database or PostgreSQL URL (defaults to DATABASE_URL,
+                                DATABASE_PATH or data/attendance.db)
+  --from YYYY-MM-DD             First day of the period (required)
+  --to YYYY-MM-DD               Last day of the period (defaults to --from)
+  --format csv|pivot|xlsx|json  Output format (default csv, identical to the bot's /csv report)
+  --department NAME             Only users of this department of the employee directory
+  --work-mode office|remote     Only attendance recorded at the office, or on days worked from home
+  --out PATH                    Output file (default attendance_<from>_to_<to>.<ext>, "-" for stdout)
+  --allow-empty                 Write the file even when the period has no records
+  --timezone NAME               Timezone of the dates and times (defaults to TIMEZONE or Asia/Jakarta)
+`
+
+// errUsage indicates invalid command-line usage
+var errUsage = errors.New("invalid usage")
+
+// errEmpty indicates the requested period has no records
+var errEmpty = errors.New("no attendance records in the requested period")
+
+func main() {
+	// Interrupting cancels the queries in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run parses flags, reads the period from a read-only database and writes the report
+func run(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	dbPath := fs.String("db", getEnvWithDefault("DATABASE_URL", getEnvWithDefault("DATABASE_PATH", "data/attendance.db")), "SQLite database path or PostgreSQL URL")
+	from := fs.String("from", "", "first day of the period")
+	to := fs.String("to", "", "last day of the period")
+	format := fs.String("format", "csv", "csv, pivot, xlsx or json")
+	department := fs.String("department", "", "only users of this department")
+	workMode := fs.String("work-mode", "", "only office or remote attendance")
+	outPath := fs.String("out", "", "output file")
+	allowEmpty := fs.Bool("allow-empty", false, "write the file even when there are no records")
+	timezone := fs.String("timezone", getEnvWithDefault("TIMEZONE", utils.DefaultTimezone), "timezone of dates and times")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected arguments %v", errUsage, fs.Args())
+	}
+	if err := utils.SetLocation(*timezone); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if *from == "" {
+		return fmt.Errorf("%w: --from is required", errUsage)
+	}
+	if *to == "" {
+		*to = *from
+	}
+	if !utils.IsValidDateFormat(*from) || !utils.IsValidDateFormat(*to) {
+		return fmt.Errorf("invalid period %q to %q, expected YYYY-MM-DD", *from, *to)
+	}
+	if *from > *to {
+		return fmt.Errorf("--from %s is after --to %s", *from, *to)
+	}
+
+	exportFormat, ok := reports.ExportFormats[*format]
+	if !ok {
+		return fmt.Errorf("%w: unknown format %q", errUsage, *format)
+	}
+	if *workMode != "" && *workMode != reports.WorkModeOffice && *workMode != reports.WorkModeRemote {
+		return fmt.Errorf("%w: unknown work mode %q", errUsage, *workMode)
+	}
+
+	db, err := database.OpenDBReadOnly(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := database.NewRepository(db)
+	records, err := repo.GetAttendanceReportRange(ctx, *from, *to)
+	if err != nil {
+		return err
+	}
+	leaves, err := repo.GetApprovedLeaves(ctx, *from, *to)
+	if err != nil {
+		return err
+	}
+	leave := models.ExpandLeave(leaves, *from, *to)
+	shifts, err := repo.GetShiftAssignments(ctx)
+	if err != nil {
+		return err
+	}
+	if *department != "" {
+		records, leave = reports.FilterDepartment(records, leave, *department)
+	}
+	if *workMode != "" {
+		records, leave = reports.FilterWorkMode(records, leave, *workMode)
+	}
+	if len(records) == 0 && len(leave) == 0 && !*allowEmpty {
+		return fmt.Errorf("%w %s to %s (use --allow-empty to write anyway)", errEmpty, *from, *to)
+	}
+
+	if *outPath == "" {
+		*outPath = defaultFilename(*from, *to, *format, exportFormat.Extension)
+	}
+
+	summary := out
+	if *outPath == "-" {
+		// Keep stdout clean for the report itself
+		summary = os.Stderr
+		if err := exportFormat.Write(out, records, leave, shifts); err != nil {
+			return err
+		}
+	} else if err := writeReport(*outPath, exportFormat.Write, records, leave, shifts); err != nil {
+		return err
+	}
+
+	users := make(map[int64]struct{})
+	for _, record := range records {
+		users[record.UserID] = struct{}{}
+	}
+
+	fmt.Fprintf(summary, "Period:  %s to %s\n", *from, *to)
+	if *department != "" {
+		fmt.Fprintf(summary, "Department: %s\n", *department)
+	}
+	if *workMode != "" {
+		fmt.Fprintf(summary, "Work mode: %s\n", *workMode)
+	}
+	fmt.Fprintf(summary, "Records: %d\n", len(records))
+	fmt.Fprintf(summary, "Users:   %d\n", len(users))
+	fmt.Fprintf(summary, "Leave:   %d days\n", len(leave))
+	if *outPath != "-" {
+		fmt.Fprintf(summary, "Written: %s\n", *outPath)
+	}
+
+	return nil
+}
+
+// writeReport creates the output file and writes the report into it
+func writeReport(path string, write reports.Writer, records []models.AttendanceRecord, leave []models.LeaveDay, shifts *models.ShiftAssignments) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	if err := write(file, records, leave, shifts); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %w", err)
+	}
+	return nil
+}
+
+// defaultFilename returns the output name used when --out is not given
+func defaultFilename(from, to, format, ext string) string {
+	if format == "pivot" {
+		return fmt.Sprintf("attendance_pivot_%s_to_%s.%s", from, to, ext)
+	}
+	return fmt.Sprintf("attendance_%s_to_%s.%s", from, to, ext)
+}
+
+// getEnvWithDefault returns the environment variable value or a default if not set
+func getEnvWithDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
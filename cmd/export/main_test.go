@@ -0,0 +1,184 @@
+package main
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/reports"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestDB creates a database with a few days of attendance, breaks and leave, returning its path
+func newTestDB(t *testing.T) string {
+	t.Helper()
+
+	if err := utils.SetLocation("Asia/Jakarta"); err != nil {
+		t.Fatalf("SetLocation: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "attendance.db")
+	db, err := database.NewDB(path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	repo := database.NewRepository(db)
+	ctx := context.Background()
+
+	at := func(date, clock string) time.Time {
+		timestamp, err := time.ParseInLocation("2006-01-02 15:04", date+" "+clock, utils.Location)
+		if err != nil {
+			t.Fatalf("bad fixture time: %v", err)
+		}
+		return timestamp
+	}
+	lastName := `"Jr", Santoso`
+	records := []models.AttendanceRecord{
+		{UserID: 1001, Username: "budi", FirstName: "Budi", LastName: &lastName, Timestamp: at("2024-03-04", "08:05"), Type: "check_in", Date: "2024-03-04"},
+		{UserID: 1001, Username: "budi", FirstName: "Budi", LastName: &lastName, Timestamp: at("2024-03-04", "12:00"), Type: "break_start", Date: "2024-03-04"},
+		{UserID: 1001, Username: "budi", FirstName: "Budi", LastName: &lastName, Timestamp: at("2024-03-04", "12:40"), Type: "break_end", Date: "2024-03-04"},
+		{UserID: 1001, Username: "budi", FirstName: "Budi", LastName: &lastName, Timestamp: at("2024-03-04", "17:30"), Type: "check_out", Date: "2024-03-04"},
+		{UserID: 1002, Username: "sari", FirstName: "Sári", Timestamp: at("2024-03-05", "09:20"), Type: "check_in", Date: "2024-03-05"},
+	}
+	if _, _, err := repo.InsertAttendanceBatch(ctx, records); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+
+	leave, err := repo.InsertLeave(ctx, &models.Leave{
+		UserID: 1003, Username: "dewi", FirstName: "Dewi", Type: models.LeaveSakit,
+		StartDate: "2024-03-04", EndDate: "2024-03-05", Reason: "demam", Status: models.LeavePending, RequestedAt: at("2024-03-03", "20:00"),
+	})
+	if err != nil {
+		t.Fatalf("InsertLeave: %v", err)
+	}
+	if _, err := repo.DecideLeave(ctx, leave.ID, models.LeaveApproved, 900, at("2024-03-03", "21:00")); err != nil {
+		t.Fatalf("DecideLeave: %v", err)
+	}
+
+	return path
+}
+
+// runExport runs the export tool, returning what it printed
+func runExport(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	err := run(context.Background(), append([]string{"--timezone", "Asia/Jakarta"}, args...), &out)
+	return out.String(), err
+}
+
+// botReport returns the CSV report the bot's /fullreport sends for the period, generated the way
+// it does: with the attendance service and the CSV generator
+func botReport(t *testing.T, dbPath, from, to string) []byte {
+	t.Helper()
+
+	db, err := database.NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	service := attendance.NewService(database.NewRepository(db), attendance.NewTOTPService("JBSWY3DPEHPK3PXP"))
+	ctx := context.Background()
+
+	records, err := service.GetAttendanceReportRange(ctx, from, to)
+	if err != nil {
+		t.Fatalf("GetAttendanceReportRange: %v", err)
+	}
+	leave, err := service.GetLeaveDays(ctx, from, to)
+	if err != nil {
+		t.Fatalf("GetLeaveDays: %v", err)
+	}
+	data, err := reports.NewCSVGenerator().GenerateAttendanceReport(records, leave)
+	if err != nil {
+		t.Fatalf("GenerateAttendanceReport: %v", err)
+	}
+	return data
+}
+
+func TestExportMatchesBotReport(t *testing.T) {
+	dbPath := newTestDB(t)
+	outPath := filepath.Join(t.TempDir(), "report.csv")
+
+	summary, err := runExport(t, "--db", dbPath, "--from", "2024-03-04", "--to", "2024-03-05", "--out", outPath)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	for _, line := range []string{"Period:  2024-03-04 to 2024-03-05\n", "Records: 5\n", "Users:   2\n", "Leave:   2 days\n"} {
+		if !strings.Contains(summary, line) {
+			t.Errorf("summary lacks %q:\n%s", line, summary)
+		}
+	}
+
+	exported, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("report not written: %v", err)
+	}
+	if want := botReport(t, dbPath, "2024-03-04", "2024-03-05"); !bytes.Equal(exported, want) {
+		t.Errorf("cmd/export CSV differs from the bot's:\n%s\nwant\n%s", exported, want)
+	}
+	for _, field := range []string{`"""Jr"", Santoso"`, "Sári", "break_start", "Sakit"} {
+		if !bytes.Contains(exported, []byte(field)) {
+			t.Errorf("report lacks %s:\n%s", field, exported)
+		}
+	}
+}
+
+func TestExportToStdout(t *testing.T) {
+	dbPath := newTestDB(t)
+
+	out, err := runExport(t, "--db", dbPath, "--from", "2024-03-04", "--out", "-")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if want := botReport(t, dbPath, "2024-03-04", "2024-03-04"); out != string(want) {
+		t.Errorf("exported CSV on stdout =\n%s\nwant only the report\n%s", out, want)
+	}
+}
+
+func TestExportEmptyPeriod(t *testing.T) {
+	dbPath := newTestDB(t)
+	outPath := filepath.Join(t.TempDir(), "report.csv")
+
+	if _, err := runExport(t, "--db", dbPath, "--from", "2024-04-01", "--out", outPath); !errors.Is(err, errEmpty) {
+		t.Errorf("export of an empty period = %v, want errEmpty", err)
+	}
+	if _, err := os.Stat(outPath); err == nil {
+		t.Error("export of an empty period wrote a file")
+	}
+
+	if _, err := runExport(t, "--db", dbPath, "--from", "2024-04-01", "--out", outPath, "--allow-empty"); err != nil {
+		t.Fatalf("export --allow-empty: %v", err)
+	}
+	if data, _ := os.ReadFile(outPath); !bytes.HasPrefix(data, []byte("ID,")) || bytes.Count(data, []byte("\n")) != 1 {
+		t.Errorf("export --allow-empty wrote %q, want only the header", data)
+	}
+}
+
+func TestExportValidatesFlags(t *testing.T) {
+	dbPath := newTestDB(t)
+
+	tests := []struct {
+		args  []string
+		usage bool
+	}{
+		{[]string{"--db", dbPath}, true},
+		{[]string{"--db", dbPath, "--from", "2024-03-04", "--format", "pdf"}, true},
+		{[]string{"--db", dbPath, "--from", "2024-03-04", "--work-mode", "hybrid"}, true},
+		{[]string{"--db", dbPath, "--from", "2024-03-04", "extra"}, true},
+		{[]string{"--db", dbPath, "--from", "04-03-2024"}, false},
+		{[]string{"--db", dbPath, "--from", "2024-03-05", "--to", "2024-03-04"}, false},
+	}
+	for _, tt := range tests {
+		_, err := runExport(t, tt.args...)
+		if err == nil || errors.Is(err, errUsage) != tt.usage {
+			t.Errorf("%v = %v, want a usage error %v", tt.args, err, tt.usage)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/importer"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const usage = `Usage: import [flags]
+
+Flags:
+  --db PATH|URL      SQLite database or PostgreSQL URL (defaults to DATABASE_URL,
+                     DATABASE_PATH or data/attendance.db)
+  --file PATH        CSV file with the columns user_id,name,date,type,time (required, "-" for stdin)
+  --rejects PATH     Where to write rejected rows (default <file>.rejects.csv, or stderr for stdin)
+  --dry-run          Validate the rows and match names without writing anything
+  --json             Print the summary as JSON
+  --timezone NAME    Timezone of the times (defaults to TIMEZONE or Asia/Jakarta)
+
+user_id is a Telegram user ID or an employee ID of the employee directory. Left empty, the
+registered employee with the name is looked up. Times are HH:MM or HH:MM:SS in --timezone. Valid
+rows are inserted in one transaction; rows already recorded are skipped as duplicates.
+`
+
+// errUsage indicates invalid command-line usage
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	// Interrupting cancels the queries in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run parses flags, imports the CSV file and prints a summary
+func run(ctx context.Context, args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	dbPath := fs.String("db", getEnvWithDefault("DATABASE_URL", getEnvWithDefault("DATABASE_PATH", "data/attendance.db")), "SQLite database path or PostgreSQL URL")
+	file := fs.String("file", "", "CSV file to import")
+	rejectsPath := fs.String("rejects", "", "where to write rejected rows")
+	dryRun := fs.Bool("dry-run", false, "validate without writing")
+	jsonOutput := fs.Bool("json", false, "print machine-readable JSON")
+	timezone := fs.String("timezone", getEnvWithDefault("TIMEZONE", utils.DefaultTimezone), "timezone of the times")
+
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected arguments %v", errUsage, fs.Args())
+	}
+	if err := utils.SetLocation(*timezone); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if *file == "" {
+		return fmt.Errorf("%w: --file is required", errUsage)
+	}
+
+	input := in
+	if *file != "-" {
+		opened, err := os.Open(*file)
+		if err != nil {
+			return fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer opened.Close()
+		input = opened
+
+		if *rejectsPath == "" {
+			*rejectsPath = strings.TrimSuffix(*file, ".csv") + ".rejects.csv"
+		}
+	}
+
+	db, err := database.NewDB(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := database.NewRepository(db)
+	result, err := importer.Import(ctx, repo, input, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Rejects) > 0 {
+		if err := writeRejects(*rejectsPath, result.Rejects); err != nil {
+			return err
+		}
+	}
+
+	if !result.DryRun && result.Inserted > 0 {
+		entry := models.AuditEntry{
+			CreatedAt: time.Now(),
+			Action:    models.AuditAttendanceImported,
+			Details:   fmt.Sprintf("%s: %d of %d rows inserted", filepath.Base(*file), result.Inserted, result.Rows),
+		}
+		if err := repo.InsertAuditEntry(ctx, &entry); err != nil {
+			return fmt.Errorf("rows imported but not recorded in the audit log: %w", err)
+		}
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	fmt.Fprintf(out, "Rows:       %d\n", result.Rows)
+	fmt.Fprintf(out, "Valid:      %d (%d matched by name or employee ID)\n", result.Valid, result.Resolved)
+	fmt.Fprintf(out, "Invalid:    %d\n", result.Errors)
+	if result.DryRun {
+		fmt.Fprintln(out, "Dry run:    nothing written")
+	} else {
+		fmt.Fprintf(out, "Inserted:   %d\n", result.Inserted)
+		fmt.Fprintf(out, "Duplicates: %d\n", result.Duplicates)
+	}
+	if len(result.Rejects) > 0 && *rejectsPath != "" {
+		fmt.Fprintf(out, "Rejected rows written to %s\n", *rejectsPath)
+	}
+
+	return nil
+}
+
+// writeRejects writes rejected rows to path, or to stderr when no path is given
+func writeRejects(path string, rejects []importer.Reject) error {
+	if path == "" {
+		return importer.WriteRejects(os.Stderr, rejects)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create rejects file: %w", err)
+	}
+
+	if err := importer.WriteRejects(file, rejects); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close rejects file: %w", err)
+	}
+	return nil
+}
+
+// getEnvWithDefault returns the environment variable value or a default if not set
+func getEnvWithDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
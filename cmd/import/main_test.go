@@ -0,0 +1,181 @@
+package main
+
+import (
+	"attendance-bot/internal/database"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// importFixture copies a fixture CSV from testdata into a temporary directory, so the rejects
+// file is written next to the copy, and returns the copy's path and a fresh database path
+func importFixture(t *testing.T, name string) (file, dbPath string) {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	dir := t.TempDir()
+	file = filepath.Join(dir, name)
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatalf("failed to copy fixture: %v", err)
+	}
+	return file, filepath.Join(dir, "attendance.db")
+}
+
+// runImport runs the import tool, returning what it printed
+func runImport(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	err := run(context.Background(), append([]string{"--timezone", "Asia/Jakarta"}, args...), strings.NewReader(""), &out)
+	return out.String(), err
+}
+
+// countRecords returns the number of attendance records in the database
+func countRecords(t *testing.T, dbPath string) int {
+	t.Helper()
+
+	db, err := database.NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	records, err := database.NewRepository(db).ListAttendance(context.Background(), 0, "")
+	if err != nil {
+		t.Fatalf("ListAttendance: %v", err)
+	}
+	return len(records)
+}
+
+func TestImportValidFile(t *testing.T) {
+	file, dbPath := importFixture(t, "valid.csv")
+
+	out, err := runImport(t, "--db", dbPath, "--file", file)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if !strings.Contains(out, "Inserted:   3\n") || !strings.Contains(out, "Invalid:    0\n") {
+		t.Errorf("import printed:\n%s", out)
+	}
+
+	db, err := database.NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+	records, err := database.NewRepository(db).ListAttendance(context.Background(), 1001, "2024-03-04")
+	if err != nil || len(records) != 2 {
+		t.Fatalf("ListAttendance = %d records, %v; want Budi's two", len(records), err)
+	}
+	// 08:00 in Jakarta is 01:00 UTC
+	if want := time.Date(2024, 3, 4, 1, 0, 0, 0, time.UTC); !records[0].Timestamp.Equal(want) {
+		t.Errorf("check-in at %v, want %v", records[0].Timestamp.UTC(), want)
+	}
+	if records[0].LastName == nil || *records[0].LastName != "Santoso" {
+		t.Errorf("check-in last name = %v, want Santoso", records[0].LastName)
+	}
+
+	// Importing the same file again skips every row as a duplicate
+	out, err = runImport(t, "--db", dbPath, "--file", file)
+	if err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+	if !strings.Contains(out, "Inserted:   0\n") || !strings.Contains(out, "Duplicates: 3\n") {
+		t.Errorf("second import printed:\n%s", out)
+	}
+	if n := countRecords(t, dbPath); n != 3 {
+		t.Errorf("%d records after importing twice, want 3", n)
+	}
+}
+
+func TestImportMalformedRows(t *testing.T) {
+	file, dbPath := importFixture(t, "malformed.csv")
+
+	out, err := runImport(t, "--db", dbPath, "--file", file)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	for _, line := range []string{"Rows:       11\n", "Invalid:    9\n", "Inserted:   2\n", "Duplicates: 0\n"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("import printed no %q:\n%s", line, out)
+		}
+	}
+	if n := countRecords(t, dbPath); n != 2 {
+		t.Errorf("%d records imported, want the 2 valid ones", n)
+	}
+
+	rejectsFile, err := os.Open(strings.TrimSuffix(file, ".csv") + ".rejects.csv")
+	if err != nil {
+		t.Fatalf("failed to open rejects file: %v", err)
+	}
+	defer rejectsFile.Close()
+	reader := csv.NewReader(rejectsFile)
+	reader.FieldsPerRecord = -1
+	rejects, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read rejects file: %v", err)
+	}
+
+	want := map[string]string{
+		"3":  "invalid date",
+		"4":  "invalid type",
+		"5":  "invalid time",
+		"6":  "expected 5 columns",
+		"7":  "name is empty",
+		"8":  "invalid user_id",
+		"9":  "bare \"",
+		"10": "in the future",
+		"11": "invalid date",
+	}
+	if len(rejects) != len(want)+1 || rejects[0][0] != "line" || rejects[0][1] != "reason" {
+		t.Fatalf("rejects file =\n%v\nwant a header and %d rows", rejects, len(want))
+	}
+	for _, reject := range rejects[1:] {
+		if reason, ok := want[reject[0]]; !ok || !strings.Contains(reject[1], reason) {
+			t.Errorf("line %s rejected for %q, want %q", reject[0], reject[1], reason)
+		}
+	}
+}
+
+func TestImportDryRunWritesNothing(t *testing.T) {
+	file, dbPath := importFixture(t, "malformed.csv")
+
+	out, err := runImport(t, "--db", dbPath, "--file", file, "--dry-run")
+	if err != nil {
+		t.Fatalf("import --dry-run: %v", err)
+	}
+	if !strings.Contains(out, "Valid:      2 ") || !strings.Contains(out, "Dry run:    nothing written") {
+		t.Errorf("import --dry-run printed:\n%s", out)
+	}
+	if n := countRecords(t, dbPath); n != 0 {
+		t.Errorf("dry run wrote %d records", n)
+	}
+}
+
+func TestImportRejectsFile(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "attendance.db")
+	headerless := filepath.Join(dir, "headerless.csv")
+	if err := os.WriteFile(headerless, []byte("1001,Budi,2024-03-04,check_in,08:00\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if _, err := runImport(t, "--db", dbPath, "--file", headerless); err == nil || !strings.Contains(err.Error(), "header") {
+		t.Errorf("import of a file without header = %v, want a header error", err)
+	}
+	if _, err := runImport(t, "--db", dbPath, "--file", filepath.Join(dir, "missing.csv")); err == nil {
+		t.Error("import of a missing file succeeded")
+	}
+	if _, err := runImport(t, "--db", dbPath); !errors.Is(err, errUsage) {
+		t.Errorf("import without --file = %v, want a usage error", err)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"attendance-bot/internal/database"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage: migrate [--db path|url] <command> [flags]
+
+Commands:
+  status                       Show applied and pending migrations
+  up [--to N] [--dry-run]      Apply pending migrations (up to version N)
+  down --to N [--dry-run]      Revert applied migrations newer than version N, dropping their data
+
+The database (--db PATH or a postgres:// URL) defaults to DATABASE_URL, DATABASE_PATH or
+data/attendance.db.
+`
+
+// errUsage indicates invalid command-line usage
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run parses global flags, opens the database without migrating it and dispatches the command
+func run(args []string, out io.Writer) error {
+	global := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	dbPath := global.String("db", getEnvWithDefault("DATABASE_URL", getEnvWithDefault("DATABASE_PATH", "data/attendance.db")), "SQLite database path or PostgreSQL URL")
+
+	if err := global.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if global.NArg() == 0 {
+		return fmt.Errorf("%w: missing command", errUsage)
+	}
+
+	db, err := database.OpenDB(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch global.Arg(0) {
+	case "status":
+		return status(db, out)
+	case "up":
+		return up(db, global.Args()[1:], out)
+	case "down":
+		return down(db, global.Args()[1:], out)
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, global.Arg(0))
+	}
+}
+
+// status prints every migration with its applied state
+func status(db *database.DB, out io.Writer) error {
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	pending := 0
+	for _, s := range statuses {
+		state := "pending"
+		if s.Applied {
+			state = "applied " + s.AppliedAt.Format(time.RFC3339)
+		} else {
+			pending++
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, state)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\n%d pending, latest version %d\n", pending, database.LatestVersion())
+	return nil
+}
+
+// up applies pending migrations, or lists them with --dry-run
+func up(db *database.DB, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("up", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	to := fs.Int("to", 0, "stop after applying this version (default latest)")
+	dryRun := fs.Bool("dry-run", false, "list the migrations that would run without applying them")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if *dryRun {
+		pending, err := db.PendingMigrations(*to)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			fmt.Fprintln(out, "Database is up to date.")
+			return nil
+		}
+		for _, m := range pending {
+			fmt.Fprintf(out, "Would apply %d: %s\n", m.Version, m.Name)
+		}
+		return nil
+	}
+
+	applied, err := db.Migrate(*to, func(m database.Migration, took time.Duration) {
+		fmt.Fprintf(out, "Applied %d: %s (%s)\n", m.Version, m.Name, took.Round(time.Millisecond))
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(applied) == 0 {
+		fmt.Fprintln(out, "Database is up to date.")
+	}
+	return nil
+}
+
+// down reverts applied migrations newer than --to, or lists them with --dry-run. The target is
+// required so a bare "down" cannot drop the whole schema.
+func down(db *database.DB, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("down", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	to := fs.Int("to", -1, "keep migrations up to this version, 0 reverts all of them")
+	dryRun := fs.Bool("dry-run", false, "list the migrations that would be reverted without reverting them")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if *to < 0 {
+		return fmt.Errorf("%w: down requires --to", errUsage)
+	}
+
+	if *dryRun {
+		applied, err := db.AppliedMigrations(*to)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "Nothing to revert.")
+			return nil
+		}
+		for _, m := range applied {
+			fmt.Fprintf(out, "Would revert %d: %s\n", m.Version, m.Name)
+		}
+		return nil
+	}
+
+	reverted, err := db.Revert(*to, func(m database.Migration, took time.Duration) {
+		fmt.Fprintf(out, "Reverted %d: %s (%s)\n", m.Version, m.Name, took.Round(time.Millisecond))
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(reverted) == 0 {
+		fmt.Fprintln(out, "Nothing to revert.")
+	}
+	return nil
+}
+
+// getEnvWithDefault returns the environment variable value or a default if not set
+func getEnvWithDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
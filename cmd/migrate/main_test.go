@@ -0,0 +1,161 @@
+package main
+
+import (
+	"attendance-bot/internal/database"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// legacySchema is the schema the bot created at startup before it had migrations
+const legacySchema = `
+CREATE TABLE IF NOT EXISTS attendance (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	username TEXT NOT NULL,
+	first_name TEXT NOT NULL,
+	last_name TEXT,
+	timestamp TEXT NOT NULL,
+	type TEXT NOT NULL CHECK (type IN ('check_in', 'check_out')),
+	date TEXT NOT NULL,
+	UNIQUE(user_id, date, type)
+);
+CREATE INDEX IF NOT EXISTS idx_user_date ON attendance(user_id, date);
+CREATE INDEX IF NOT EXISTS idx_date ON attendance(date);
+CREATE INDEX IF NOT EXISTS idx_user_id ON attendance(user_id);
+CREATE INDEX IF NOT EXISTS idx_type ON attendance(type);
+CREATE TABLE IF NOT EXISTS alias (
+	user_id INTEGER PRIMARY KEY,
+	first_name TEXT NOT NULL,
+	last_name TEXT
+);
+INSERT INTO attendance (user_id, username, first_name, last_name, timestamp, type, date) VALUES
+	(1001, 'budi', 'Budi', 'Santoso', '2024-03-04T08:05:00+07:00', 'check_in', '2024-03-04'),
+	(1001, 'budi', 'Budi', 'Santoso', '2024-03-04T17:10:00+07:00', 'check_out', '2024-03-04'),
+	(1002, 'sari', 'Sari', NULL, '2024-03-04T09:20:00+07:00', 'check_in', '2024-03-04');
+INSERT INTO alias (user_id, first_name, last_name) VALUES (1001, 'Pak', 'Budi');
+`
+
+// newLegacyDB creates a database file as written by the bot before it had migrations
+func newLegacyDB(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "attendance.db")
+	db, err := database.OpenDB(path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(legacySchema); err != nil {
+		t.Fatalf("failed to create legacy schema: %v", err)
+	}
+	return path
+}
+
+// runMigrate runs the migrate tool, returning what it printed
+func runMigrate(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	err := run(args, &out)
+	return out.String(), err
+}
+
+func TestUpMigratesLegacyDatabase(t *testing.T) {
+	path := newLegacyDB(t)
+	latest := database.LatestVersion()
+
+	out, err := runMigrate(t, "--db", path, "status")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if want := fmt.Sprintf("%d pending, latest version %d", latest, latest); !strings.Contains(out, want) {
+		t.Errorf("status of the legacy database lacks %q:\n%s", want, out)
+	}
+
+	out, err = runMigrate(t, "--db", path, "up", "--dry-run")
+	if err != nil {
+		t.Fatalf("up --dry-run: %v", err)
+	}
+	if n := strings.Count(out, "Would apply "); n != latest {
+		t.Errorf("up --dry-run listed %d migrations, want %d:\n%s", n, latest, out)
+	}
+	if out, _ := runMigrate(t, "--db", path, "status"); !strings.Contains(out, fmt.Sprintf("%d pending", latest)) {
+		t.Errorf("up --dry-run applied migrations:\n%s", out)
+	}
+
+	out, err = runMigrate(t, "--db", path, "up")
+	if err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if n := strings.Count(out, "Applied "); n != latest {
+		t.Errorf("up applied %d migrations, want %d:\n%s", n, latest, out)
+	}
+	if out, _ := runMigrate(t, "--db", path, "status"); !strings.Contains(out, "0 pending") {
+		t.Errorf("status after up:\n%s", out)
+	}
+	if out, err := runMigrate(t, "--db", path, "up"); err != nil || out != "Database is up to date.\n" {
+		t.Errorf("second up = %q, %v", out, err)
+	}
+
+	// The legacy records and alias survive, and the migrated schema takes new kinds of records
+	db, err := database.NewDB(path)
+	if err != nil {
+		t.Fatalf("failed to open migrated database: %v", err)
+	}
+	defer db.Close()
+	repo := database.NewRepository(db)
+	ctx := context.Background()
+
+	records, err := repo.ListAttendance(ctx, 1001, "2024-03-04")
+	if err != nil || len(records) != 2 {
+		t.Fatalf("ListAttendance = %d records, %v; want Budi's two", len(records), err)
+	}
+	if want := time.Date(2024, 3, 4, 1, 5, 0, 0, time.UTC); !records[0].Timestamp.Equal(want) || records[0].Type != "check_in" {
+		t.Errorf("legacy check-in = %s at %v, want check_in at %v", records[0].Type, records[0].Timestamp.UTC(), want)
+	}
+	alias, err := repo.GetUserAlias(ctx, 1001)
+	if err != nil || alias == nil || alias.FirstName != "Pak" {
+		t.Errorf("legacy alias = %+v, %v", alias, err)
+	}
+	if _, err := db.Exec(`INSERT INTO attendance (user_id, username, first_name, timestamp, type, date)
+		VALUES (1002, 'sari', 'Sari', '2024-03-04T05:00:00Z', 'break_start', '2024-03-04')`); err != nil {
+		t.Errorf("migrated schema rejects a break record: %v", err)
+	}
+}
+
+func TestUpToVersion(t *testing.T) {
+	path := newLegacyDB(t)
+	latest := database.LatestVersion()
+
+	out, err := runMigrate(t, "--db", path, "up", "--to", "3")
+	if err != nil {
+		t.Fatalf("up --to 3: %v", err)
+	}
+	if n := strings.Count(out, "Applied "); n != 3 {
+		t.Errorf("up --to 3 applied %d migrations:\n%s", n, out)
+	}
+	if out, _ := runMigrate(t, "--db", path, "status"); !strings.Contains(out, fmt.Sprintf("%d pending", latest-3)) {
+		t.Errorf("status after up --to 3:\n%s", out)
+	}
+
+	if _, err := runMigrate(t, "--db", path, "up", "--to", fmt.Sprint(latest+1)); err == nil {
+		t.Error("up to an unknown version succeeded")
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "attendance.db")
+
+	for _, args := range [][]string{{"--db", path}, {"--db", path, "sideways"}, {"--db", path, "down"}} {
+		if _, err := runMigrate(t, args...); !errors.Is(err, errUsage) {
+			t.Errorf("%v = %v, want a usage error", args, err)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"attendance-bot/internal/attendance"
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSecret = "JBSWY3DPEHPK3PXP"
+
+func TestParseFlagsDefaults(t *testing.T) {
+	opts, err := parseFlags(nil)
+	if err != nil {
+		t.Fatalf("parseFlags: %v", err)
+	}
+	if opts.issuer != attendance.DefaultIssuer || opts.account != attendance.DefaultAccountName || opts.secret != "" {
+		t.Errorf("defaults = %+v", opts)
+	}
+	if opts.algorithm != attendance.AlgorithmSHA1 || opts.digits != 6 || opts.period != 30 || opts.force {
+		t.Errorf("default parameters = %+v", opts)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	opts, err := parseFlags([]string{"--issuer", "Kantor Pusat", "--account", "Budi", "--secret", "jbsw y3dp ehpk 3pxp",
+		"--algorithm", "sha-256", "--digits", "8", "--period", "60", "--qr", "totp.png", "--env", ".env", "--force"})
+	if err != nil {
+		t.Fatalf("parseFlags: %v", err)
+	}
+	want := options{
+		issuer:    "Kantor Pusat",
+		account:   "Budi",
+		secret:    testSecret,
+		algorithm: attendance.AlgorithmSHA256,
+		digits:    8,
+		period:    60,
+		qrPath:    "totp.png",
+		envPath:   ".env",
+		force:     true,
+	}
+	if *opts != want {
+		t.Errorf("parseFlags = %+v, want %+v", *opts, want)
+	}
+}
+
+func TestParseFlagsURI(t *testing.T) {
+	opts, err := parseFlags([]string{"--uri", "otpauth://totp/Acme:Budi?secret=JBSWY3DPEHPK3PXP&algorithm=SHA512&digits=8&period=60"})
+	if err != nil {
+		t.Fatalf("parseFlags: %v", err)
+	}
+	if opts.issuer != "Acme" || opts.account != "Budi" || opts.secret != testSecret ||
+		opts.algorithm != attendance.AlgorithmSHA512 || opts.digits != 8 || opts.period != 60 {
+		t.Errorf("parseFlags(--uri) = %+v", opts)
+	}
+}
+
+func TestParseFlagsRejects(t *testing.T) {
+	tests := [][]string{
+		{"--secret", "short"},
+		{"--secret", "JBSWY3DPEHPK3PX1"},
+		{"--digits", "5"},
+		{"--digits", "9"},
+		{"--period", "0"},
+		{"--algorithm", "MD5"},
+		{"--issuer", " "},
+		{"--account", ""},
+		{"--secret", testSecret, "--uri", "otpauth://totp/A:b?secret=" + testSecret},
+		{"--uri", "https://example.com"},
+		{"extra"},
+		{"--unknown"},
+	}
+	for _, args := range tests {
+		if _, err := parseFlags(args); err == nil {
+			t.Errorf("parseFlags(%q) accepted", args)
+		}
+	}
+}
+
+func TestRunWithExistingSecret(t *testing.T) {
+	opts, _ := parseFlags([]string{"--secret", testSecret, "--issuer", "Attendance Bot", "--account", "Budi"})
+
+	var out bytes.Buffer
+	if err := run(opts, &out); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	for _, line := range []string{
+		"Using existing TOTP Secret: " + testSecret + "\n",
+		"OTP Auth URL: otpauth://totp/Attendance%20Bot:Budi?secret=" + testSecret + "&issuer=Attendance%20Bot&algorithm=SHA1&digits=6&period=30\n",
+		"1. Set TOTP_SECRET=" + testSecret + " in your .env file",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("output lacks %q:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestRunGeneratesSecret(t *testing.T) {
+	opts, _ := parseFlags(nil)
+
+	var out bytes.Buffer
+	if err := run(opts, &out); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	line, _, _ := strings.Cut(out.String(), "\n")
+	secret, ok := strings.CutPrefix(line, "Generated TOTP Secret: ")
+	if !ok || !attendance.ValidateSecret(secret) {
+		t.Errorf("first line %q does not give a valid generated secret", line)
+	}
+}
+
+func TestRunWritesQRCode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "totp.png")
+	opts, _ := parseFlags([]string{"--secret", testSecret, "--qr", path})
+
+	if err := run(opts, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("QR code not written: %v", err)
+	}
+	defer file.Close()
+	if _, err := png.Decode(file); err != nil {
+		t.Errorf("QR code is not a PNG: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("QR code written with mode %v, want 0600", info.Mode().Perm())
+	}
+
+	// A second run neither replaces the file without --force nor fails with it
+	if err := run(opts, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("run over an existing QR code = %v, want a refusal", err)
+	}
+	opts.force = true
+	if err := run(opts, &bytes.Buffer{}); err != nil {
+		t.Errorf("run --force over an existing QR code: %v", err)
+	}
+}
+
+func TestRunWritesNewEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	opts, _ := parseFlags([]string{"--secret", testSecret, "--digits", "8", "--env", path})
+
+	if err := run(opts, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("env file not written: %v", err)
+	}
+	for _, line := range []string{"TOTP_SECRET=" + testSecret + "\n", "TOTP_ALGORITHM=SHA1\n", "TOTP_DIGITS=8\n", "TOTP_PERIOD=30\n", "BOT_TOKEN="} {
+		if !strings.Contains(string(content), line) {
+			t.Errorf("env file lacks %q:\n%s", line, content)
+		}
+	}
+}
+
+func TestRunUpdatesEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	existing := "BOT_TOKEN=123:abc\nTOTP_SECRET=OLDSECRETOLDSECRET\n# keep me\nTOTP_DIGITS=6"
+	if err := os.WriteFile(path, []byte(existing), 0o600); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+	opts, _ := parseFlags([]string{"--secret", testSecret, "--digits", "8", "--env", path})
+
+	if err := run(opts, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("run over an existing env file = %v, want a refusal", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != existing {
+		t.Errorf("env file changed without --force:\n%s", content)
+	}
+
+	opts.force = true
+	if err := run(opts, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run --force: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	want := "BOT_TOKEN=123:abc\nTOTP_SECRET=" + testSecret + "\n# keep me\nTOTP_DIGITS=8\nTOTP_ALGORITHM=SHA1\nTOTP_PERIOD=30\n"
+	if string(content) != want {
+		t.Errorf("updated env file =\n%q\nwant\n%q", content, want)
+	}
+}
+
+func TestSetEnvValue(t *testing.T) {
+	tests := []struct {
+		content, want string
+	}{
+		{"", "KEY=new\n"},
+		{"A=1", "A=1\nKEY=new\n"},
+		{"A=1\n", "A=1\nKEY=new\n"},
+		{"KEY=old\nA=1\n", "KEY=new\nA=1\n"},
+		{"  KEY=old\n", "KEY=new\n"},
+		{"KEYS=other\n", "KEYS=other\nKEY=new\n"},
+	}
+	for _, tt := range tests {
+		if got := setEnvValue(tt.content, "KEY", "new"); got != tt.want {
+			t.Errorf("setEnvValue(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/utils"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage: verify [flags]
+
+Prints the codes the bot currently expects so "my code doesn't work" reports can be diagnosed.
+Output goes to stdout only; do not redirect it into log files, it contains valid codes.
+
+Flags:
+  --secret SECRET        Base32 TOTP secret (defaults to TOTP_SECRET)
+  --uri URI              otpauth://totp/ URI setting the secret, algorithm, digits and period
+                         (defaults to TOTP_URI)
+  --code CODE            Code to check against the nearby windows
+  --user ID              Check a user's per-user HOTP secret instead (requires --db and SECRETS_ENCRYPTION_KEY)
+  --db PATH|URL          SQLite database or PostgreSQL URL used with --user
+  --algorithm NAME       SHA1, SHA256 or SHA512 (defaults to TOTP_ALGORITHM or SHA1)
+  --digits N             Code length (defaults to TOTP_DIGITS or 6)
+  --period N             Time step in seconds (defaults to TOTP_PERIOD or 30)
+  --skew N               Steps the bot accepts either side (defaults to TOTP_SKEW or 1)
+  --timezone NAME        Timezone times are shown in (defaults to TIMEZONE or Asia/Jakarta)
+`
+
+// windowRange is how many time steps either side of now are printed
+const windowRange = 2
+
+// errUsage indicates invalid command-line usage
+var errUsage = errors.New("invalid usage")
+
+// options holds the parsed command-line flags
+type options struct {
+	secret    string
+	code      string
+	userID    int64
+	dbPath    string
+	algorithm attendance.Algorithm
+	digits    int
+	period    int
+	skew      int
+}
+
+func main() {
+	// Interrupting cancels the queries in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout, time.Now()); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(os.Stderr, usage)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the flags and prints the diagnosis for the TOTP secret or the user's HOTP secret
+func run(ctx context.Context, args []string, out io.Writer, now time.Time) error {
+	opts, err := parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	if opts.userID != 0 {
+		return verifyUser(ctx, opts, out)
+	}
+	return verifyTOTP(opts, out, now)
+}
+
+// parseFlags parses the command-line arguments, falling back to the bot's environment variables
+func parseFlags(args []string) (*options, error) {
+	opts := &options{}
+
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&opts.secret, "secret", os.Getenv("TOTP_SECRET"), "base32 TOTP secret")
+	uri := fs.String("uri", os.Getenv("TOTP_URI"), "otpauth URI")
+	fs.StringVar(&opts.code, "code", "", "code to check")
+	fs.Int64Var(&opts.userID, "user", 0, "Telegram user ID with a per-user secret")
+	fs.StringVar(&opts.dbPath, "db", "", "SQLite database path or PostgreSQL URL")
+	algorithm := fs.String("algorithm", getEnvWithDefault("TOTP_ALGORITHM", "SHA1"), "HMAC algorithm")
+	digits := fs.String("digits", getEnvWithDefault("TOTP_DIGITS", "6"), "code length")
+	period := fs.String("period", getEnvWithDefault("TOTP_PERIOD", "30"), "time step in seconds")
+	skew := fs.String("skew", getEnvWithDefault("TOTP_SKEW", "1"), "accepted steps either side")
+	timezone := fs.String("timezone", getEnvWithDefault("TIMEZONE", utils.DefaultTimezone), "timezone times are shown in")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("%w: unexpected arguments %v", errUsage, fs.Args())
+	}
+	if err := utils.SetLocation(*timezone); err != nil {
+		return nil, fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	// A key URI replaces the secret and its parameters
+	if *uri != "" {
+		explicitSecret := false
+		fs.Visit(func(f *flag.Flag) { explicitSecret = explicitSecret || f.Name == "secret" })
+		if explicitSecret {
+			return nil, fmt.Errorf("%w: --secret and --uri cannot be combined", errUsage)
+		}
+		key, err := utils.ParseOTPAuthURI(*uri)
+		if err != nil {
+			return nil, err
+		}
+		opts.secret = key.Secret
+		*algorithm, *digits, *period = key.Algorithm, strconv.Itoa(key.Digits), strconv.Itoa(key.Period)
+	}
+
+	var err error
+	if opts.algorithm, err = attendance.ParseAlgorithm(*algorithm); err != nil {
+		return nil, err
+	}
+	if opts.digits, err = strconv.Atoi(*digits); err != nil || opts.digits < 6 || opts.digits > 8 {
+		return nil, fmt.Errorf("invalid digits %q, expected 6 to 8", *digits)
+	}
+	if opts.period, err = strconv.Atoi(*period); err != nil || opts.period <= 0 {
+		return nil, fmt.Errorf("invalid period %q", *period)
+	}
+	if opts.skew, err = strconv.Atoi(*skew); err != nil || opts.skew < 0 {
+		return nil, fmt.Errorf("invalid skew %q", *skew)
+	}
+
+	opts.code = strings.ReplaceAll(strings.TrimSpace(opts.code), " ", "")
+
+	if opts.userID != 0 {
+		if opts.dbPath == "" {
+			return nil, fmt.Errorf("%w: --user requires --db", errUsage)
+		}
+		return opts, nil
+	}
+
+	if opts.secret == "" {
+		return nil, fmt.Errorf("%w: --secret, --uri, TOTP_SECRET or TOTP_URI is required", errUsage)
+	}
+	if !attendance.ValidateSecret(opts.secret) {
+		return nil, fmt.Errorf("secret is not a valid base32 secret of at least 10 bytes")
+	}
+
+	return opts, nil
+}
+
+// verifyTOTP prints the codes around now and where the supplied code matches
+func verifyTOTP(opts *options, out io.Writer, now time.Time) error {
+	skew := opts.skew
+	totp := attendance.NewTOTPServiceWithOptions(opts.secret, &attendance.TOTPOptions{
+		Algorithm: opts.algorithm,
+		Digits:    opts.digits,
+		Period:    opts.period,
+		Skew:      &skew,
+	})
+
+	period := time.Duration(opts.period) * time.Second
+	stepStart := time.Unix(now.Unix()/int64(opts.period)*int64(opts.period), 0)
+
+	fmt.Fprintf(out, "Server time:  %s (%s UTC, unix %d)\n",
+		now.In(utils.Location).Format("2006-01-02 15:04:05 MST"), now.UTC().Format("15:04:05"), now.Unix())
+	fmt.Fprintf(out, "Parameters:   %s, %d digits, %ds period, bot accepts ±%d steps\n",
+		opts.algorithm, opts.digits, opts.period, opts.skew)
+	fmt.Fprintf(out, "Secret:       %s\n", maskSecret(opts.secret))
+	fmt.Fprintln(out)
+
+	matched := false
+	matchOffset := 0
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OFFSET\tCODE\tVALID FROM\tVALID UNTIL\tACCEPTED\tMATCH")
+	for offset := -windowRange; offset <= windowRange; offset++ {
+		start := stepStart.Add(time.Duration(offset) * period)
+		code, err := totp.GenerateAt(start)
+		if err != nil {
+			return err
+		}
+
+		accepted := "no"
+		if abs(offset) <= opts.skew {
+			accepted = "yes"
+		}
+
+		match := ""
+		if opts.code != "" && opts.code == code {
+			match = "<=="
+			if !matched {
+				matched, matchOffset = true, offset
+			}
+		}
+
+		fmt.Fprintf(w, "%+d\t%s\t%s\t%s\t%s\t%s\n",
+			offset, code,
+			start.In(utils.Location).Format("15:04:05"),
+			start.Add(period).In(utils.Location).Format("15:04:05"),
+			accepted, match)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\nCurrent code %s expires in %d seconds\n", mustCode(totp, now), opts.period-int(now.Unix()%int64(opts.period)))
+
+	if opts.code == "" {
+		return nil
+	}
+
+	fmt.Fprintln(out)
+	if len(opts.code) != opts.digits {
+		fmt.Fprintf(out, "Code %q has %d digits, the bot expects %d\n", opts.code, len(opts.code), opts.digits)
+		return nil
+	}
+	if !matched {
+		fmt.Fprintf(out, "Code does not match any window within ±%d steps (±%s).\n", windowRange, time.Duration(windowRange)*period)
+		fmt.Fprintln(out, "Hint: the authenticator was probably set up with a different secret or algorithm, or the device clock is far off.")
+		return nil
+	}
+
+	fmt.Fprintf(out, "Code matches window offset %+d\n", matchOffset)
+	switch {
+	case matchOffset == 0:
+		fmt.Fprintln(out, "Clocks agree; the bot accepts this code.")
+	case abs(matchOffset) <= opts.skew:
+		fmt.Fprintf(out, "Accepted through skew tolerance. Hint: the device clock is roughly %s %s the server.\n",
+			time.Duration(abs(matchOffset))*period, aheadOrBehind(matchOffset))
+	default:
+		fmt.Fprintf(out, "Rejected: outside the ±%d step tolerance. Hint: the device clock is roughly %s %s the server; "+
+			"enable automatic time (NTP) on the phone or check the server clock.\n",
+			opts.skew, time.Duration(abs(matchOffset))*period, aheadOrBehind(matchOffset))
+	}
+
+	return nil
+}
+
+// verifyUser prints the next HOTP codes for a user enrolled with a per-user secret
+func verifyUser(ctx context.Context, opts *options, out io.Writer) error {
+	db, err := database.OpenDBReadOnly(opts.dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := database.NewRepository(db)
+
+	enrollment, err := repo.GetHOTPEnrollment(ctx, opts.userID)
+	if err != nil {
+		return err
+	}
+	if enrollment == nil {
+		return fmt.Errorf("user %d has no per-user secret; they verify with the shared TOTP secret", opts.userID)
+	}
+
+	stored, err := repo.GetUserSecret(ctx, opts.userID)
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		return fmt.Errorf("user %d is enrolled but has no stored secret", opts.userID)
+	}
+
+	key, err := attendance.ParseEncryptionKey(os.Getenv("SECRETS_ENCRYPTION_KEY"))
+	if err != nil {
+		return err
+	}
+	cipher, err := attendance.NewSecretCipher(key)
+	if err != nil {
+		return err
+	}
+	secret, err := cipher.Decrypt(stored.Ciphertext, stored.Nonce)
+	if err != nil {
+		return err
+	}
+
+	hotp := attendance.NewHOTPService(opts.algorithm, opts.digits, attendance.DefaultHOTPWindow)
+
+	fmt.Fprintf(out, "User:         %d (HOTP code sheet)\n", opts.userID)
+	fmt.Fprintf(out, "Parameters:   %s, %d digits, look-ahead window %d\n", opts.algorithm, opts.digits, attendance.DefaultHOTPWindow)
+	fmt.Fprintf(out, "Secret:       %s\n", maskSecret(secret))
+	fmt.Fprintf(out, "Next counter: %d\n\n", enrollment.Counter)
+
+	codes, err := hotp.GenerateBatch(secret, enrollment.Counter, attendance.DefaultHOTPWindow+1)
+	if err != nil {
+		return err
+	}
+
+	matchCounter := -1
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COUNTER\tCODE\tMATCH")
+	for i, code := range codes {
+		match := ""
+		if opts.code != "" && opts.code == code && matchCounter < 0 {
+			match = "<=="
+			matchCounter = i
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", enrollment.Counter+uint64(i), code, match)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if opts.code == "" {
+		return nil
+	}
+
+	fmt.Fprintln(out)
+	if matchCounter < 0 {
+		fmt.Fprintln(out, "Code does not match the next codes. Hint: it was already used, or comes from an older code sheet.")
+		return nil
+	}
+	fmt.Fprintf(out, "Code matches counter %d (%d codes skipped); the bot accepts it.\n",
+		enrollment.Counter+uint64(matchCounter), matchCounter)
+	return nil
+}
+
+// mustCode returns the code for now, or a placeholder if generation fails
+func mustCode(totp *attendance.TOTPService, now time.Time) string {
+	code, err := totp.GenerateAt(now)
+	if err != nil {
+		return "?"
+	}
+	return code
+}
+
+// maskSecret shows only the first and last characters of a secret
+func maskSecret(secret string) string {
+	secret = strings.TrimRight(utils.NormalizeSecret(secret), "=")
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", len(secret)-8) + secret[len(secret)-4:]
+}
+
+// aheadOrBehind describes the direction of a clock offset derived from the matching window
+func aheadOrBehind(offset int) string {
+	if offset > 0 {
+		return "ahead of"
+	}
+	return "behind"
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// getEnvWithDefault returns the environment variable value or a default if not set
+func getEnvWithDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
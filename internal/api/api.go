@@ -0,0 +1,461 @@
+package api
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/reports"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pagination and history limits
+const (
+	defaultLimit       = 100
+	maxLimit           = 500
+	defaultHistoryDays = 30
+	maxHistoryDays     = 366
+	maxExportDays      = 366
+	maxExportBodyBytes = 1 << 10
+)
+
+// Service is the read-only subset of the attendance service exposed over HTTP
+type Service interface {
+	GetAttendanceReportRange(ctx context.Context, startDate, endDate string) ([]models.AttendanceRecord, error)
+	GetUserAttendanceHistory(ctx context.Context, userID int64, days int) ([]models.AttendanceRecord, error)
+	DisplayName(ctx context.Context, record *models.AttendanceRecord) string
+	GetShiftAssignments(ctx context.Context) (*models.ShiftAssignments, error)
+	GetLeaveDays(ctx context.Context, startDate, endDate string) ([]models.LeaveDay, error)
+	Audit(ctx context.Context, entry models.AuditEntry)
+}
+
+// Page is a paginated JSON response
+type Page struct {
+	Data   interface{} `json:"data"`
+	Total  int         `json:"total"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+}
+
+// ErrorResponse is the JSON body of a failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// DayEntry is one user's attendance for a day in the daily report
+type DayEntry struct {
+	UserID       int64      `json:"user_id"`
+	Username     string     `json:"username"`
+	Name         string     `json:"name"`
+	CheckIn      *time.Time `json:"check_in,omitempty"`
+	CheckOut     *time.Time `json:"check_out,omitempty"`
+	Late         bool       `json:"late"`
+	LeftEarly    bool       `json:"left_early"`
+	Remote       bool       `json:"remote"` // Worked from home
+	WorkDuration string     `json:"work_duration,omitempty"`
+}
+
+// Report is the JSON body of the daily report
+type Report struct {
+	Date      string     `json:"date"`
+	Users     int        `json:"users"`
+	CheckIns  int        `json:"check_ins"`
+	CheckOuts int        `json:"check_outs"`
+	Entries   []DayEntry `json:"entries"`
+}
+
+// ExportRequest is the JSON body of a report export
+type ExportRequest struct {
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	Format     string `json:"format"`               // csv (default), pivot, xlsx or json, as in cmd/export
+	Department string `json:"department,omitempty"` // Limits the export to a department of the employee directory
+	WorkMode   string `json:"work_mode,omitempty"`  // Limits the export to office or remote attendance
+}
+
+// Server serves read-only attendance data to clients holding an API key
+type Server struct {
+	service Service
+	keys    map[string]string // API keys by client name
+	logger  *slog.Logger
+	server  *http.Server
+}
+
+// clientKey is the context key of the name of the API key a request was made with
+type clientKey struct{}
+
+// NewServer creates an API server listening on addr, accepting requests bearing one of keys, which
+// maps client names to their API keys
+func NewServer(addr string, keys map[string]string, service Service, logger *slog.Logger) *Server {
+	s := &Server{
+		service: service,
+		keys:    keys,
+		logger:  logger,
+	}
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return s
+}
+
+// Handler returns the HTTP handler serving the /api/v1 endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/attendance", s.handleAttendance)
+	mux.HandleFunc("GET /api/v1/users/{id}/history", s.handleUserHistory)
+	mux.HandleFunc("GET /api/v1/report/today", s.handleReportToday)
+	mux.HandleFunc("POST /api/v1/reports/export", s.handleExport)
+	return s.withRequestID(s.requireKey(mux))
+}
+
+// Start serves requests until Shutdown is called
+func (s *Server) Start() error {
+	s.logger.Info("API server listening", "addr", s.server.Addr)
+
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the server, waiting for in-flight requests to finish
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// requestIDPattern matches X-Request-ID values taken over from a proxy; others are replaced
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// withRequestID tags each request with the X-Request-ID of a reverse proxy or a fresh request ID,
+// echoed in the response, and a logger carrying it, so the service and repository log lines of
+// the request can be correlated
+func (s *Server) withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = logging.NewRequestID()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		logger := s.logger.With("request_id", requestID, "method", r.Method, "path", r.URL.Path)
+		ctx := logging.WithRequestID(r.Context(), requestID)
+		next.ServeHTTP(w, r.WithContext(logging.NewContext(ctx, logger)))
+	})
+}
+
+// requireKey rejects requests without a configured API key, given as a bearer token or in the
+// X-API-Key header, and records the key's client name in the request context
+func (s *Server) requireKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			key = token
+		}
+
+		client := s.clientFor(key)
+		if client == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="attendance"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid API key")
+			return
+		}
+		ctx := context.WithValue(r.Context(), clientKey{}, client)
+		ctx = logging.NewContext(ctx, logging.FromContext(ctx).With("client", client))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// clientFor returns the client name of an API key, or "" if the key is not configured. Every key
+// is compared in constant time so the response time does not reveal which keys exist.
+func (s *Server) clientFor(key string) string {
+	if key == "" {
+		return ""
+	}
+
+	var client string
+	for name, candidate := range s.keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(candidate)) == 1 {
+			client = name
+		}
+	}
+	return client
+}
+
+// handleAttendance lists the attendance records of one date, today by default
+func (s *Server) handleAttendance(w http.ResponseWriter, r *http.Request) {
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = utils.GetTodayDate()
+	}
+	if !utils.IsValidDateFormat(date) {
+		writeError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+		return
+	}
+
+	limit, offset, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	records, err := s.service.GetAttendanceReportRange(r.Context(), date, date)
+	if err != nil {
+		s.writeServiceError(w, r, err)
+		return
+	}
+	records = models.WithoutBreaks(records)
+
+	writeJSON(w, http.StatusOK, paginate(records, limit, offset))
+}
+
+// handleUserHistory lists a user's attendance records over the last days
+func (s *Server) handleUserHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || !utils.IsValidTelegramUserID(userID) {
+		writeError(w, http.StatusBadRequest, "user id must be a positive integer")
+		return
+	}
+
+	days := defaultHistoryDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		days, err = strconv.Atoi(value)
+		if err != nil || days <= 0 || days > maxHistoryDays {
+			writeError(w, http.StatusBadRequest, "days must be between 1 and 366")
+			return
+		}
+	}
+
+	limit, offset, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	records, err := s.service.GetUserAttendanceHistory(r.Context(), userID, days)
+	if err != nil {
+		s.writeServiceError(w, r, err)
+		return
+	}
+	records = models.WithoutBreaks(records)
+	if len(records) == 0 {
+		writeError(w, http.StatusNotFound, "no attendance found for user")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginate(records, limit, offset))
+}
+
+// handleReportToday returns today's attendance paired per user, with display names
+func (s *Server) handleReportToday(w http.ResponseWriter, r *http.Request) {
+	today := utils.GetTodayDate()
+	records, err := s.service.GetAttendanceReportRange(r.Context(), today, today)
+	if err != nil {
+		s.writeServiceError(w, r, err)
+		return
+	}
+	shifts, err := s.service.GetShiftAssignments(r.Context())
+	if err != nil {
+		s.writeServiceError(w, r, err)
+		return
+	}
+
+	report := Report{Date: today, Entries: []DayEntry{}}
+	for _, day := range models.GroupByDay(records) {
+		record := day.Record()
+		entry := DayEntry{
+			UserID:   day.UserID,
+			Username: record.Username,
+			Name:     s.service.DisplayName(r.Context(), record),
+			Remote:   record.Remote,
+		}
+		if day.CheckIn != nil {
+			entry.CheckIn = &day.CheckIn.Timestamp
+			entry.Late = utils.IsLate(day.CheckIn.Timestamp, shifts.For(day.UserID))
+			report.CheckIns++
+		}
+		if day.CheckOut != nil {
+			entry.CheckOut = &day.CheckOut.Timestamp
+			report.CheckOuts++
+		}
+		if day.CheckIn != nil && day.CheckOut != nil {
+			entry.WorkDuration = utils.CalculateWorkDuration(day.CheckIn.Timestamp, day.CheckOut.Timestamp, day.BreakTime(), utils.DefaultLanguage)
+			entry.LeftEarly = utils.LeftEarly(day.CheckIn.Timestamp, day.CheckOut.Timestamp, shifts.For(day.UserID))
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	report.Users = len(report.Entries)
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleExport returns an attendance report file for a date range, in the formats of cmd/export
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	var request ExportRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxExportBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "body must be a JSON object with start_date, end_date and format")
+		return
+	}
+
+	if request.EndDate == "" {
+		request.EndDate = request.StartDate
+	}
+	start, startErr := utils.ParseDate(request.StartDate)
+	end, endErr := utils.ParseDate(request.EndDate)
+	if startErr != nil || endErr != nil {
+		writeError(w, http.StatusBadRequest, "start_date and end_date must be YYYY-MM-DD")
+		return
+	}
+	if end.Before(start) || end.Sub(start) >= maxExportDays*24*time.Hour {
+		writeError(w, http.StatusBadRequest, "the period must run forwards and span at most 366 days")
+		return
+	}
+
+	if request.Format == "" {
+		request.Format = "csv"
+	}
+	format, ok := reports.ExportFormats[request.Format]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "format must be csv, pivot, xlsx or json")
+		return
+	}
+	if request.WorkMode != "" && request.WorkMode != reports.WorkModeOffice && request.WorkMode != reports.WorkModeRemote {
+		writeError(w, http.StatusBadRequest, "work_mode must be office or remote")
+		return
+	}
+
+	records, err := s.service.GetAttendanceReportRange(r.Context(), request.StartDate, request.EndDate)
+	if err != nil {
+		s.writeServiceError(w, r, err)
+		return
+	}
+	leave, err := s.service.GetLeaveDays(r.Context(), request.StartDate, request.EndDate)
+	if err != nil {
+		s.writeServiceError(w, r, err)
+		return
+	}
+	shifts, err := s.service.GetShiftAssignments(r.Context())
+	if err != nil {
+		s.writeServiceError(w, r, err)
+		return
+	}
+	if request.Department != "" {
+		records, leave = reports.FilterDepartment(records, leave, request.Department)
+	}
+	if request.WorkMode != "" {
+		records, leave = reports.FilterWorkMode(records, leave, request.WorkMode)
+	}
+
+	// Render fully before responding, so a failure can still be reported as an error status
+	var body bytes.Buffer
+	if err := format.Write(&body, records, leave, shifts); err != nil {
+		s.writeServiceError(w, r, err)
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("Report exported over API",
+		"audit", "api_export",
+		"start_date", request.StartDate,
+		"end_date", request.EndDate,
+		"format", request.Format,
+		"department", request.Department,
+		"work_mode", request.WorkMode,
+		"records", len(records))
+	s.service.Audit(r.Context(), models.AuditEntry{
+		Action: models.AuditReportDownloaded,
+		Details: fmt.Sprintf("attendance %s..%s %s%s via API (%s)", request.StartDate, request.EndDate, request.Format,
+			departmentSuffix(request.Department), r.Context().Value(clientKey{})),
+	})
+
+	filename := fmt.Sprintf("attendance_%s_to_%s.%s", request.StartDate, request.EndDate, format.Extension)
+	if request.Format == "pivot" {
+		filename = fmt.Sprintf("attendance_pivot_%s_to_%s.%s", request.StartDate, request.EndDate, format.Extension)
+	}
+	w.Header().Set("Content-Type", format.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+// writeServiceError maps a service error to a status code, hiding internal details from clients
+func (s *Server) writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, attendance.ErrInvalidDateRange) {
+		writeError(w, http.StatusBadRequest, "invalid date range")
+		return
+	}
+
+	logging.FromContext(r.Context()).Error("API request failed", "error", err)
+	writeError(w, http.StatusInternalServerError, "internal error")
+}
+
+// parsePage reads the limit and offset query parameters, writing a 400 response if they are invalid
+func parsePage(w http.ResponseWriter, r *http.Request) (limit, offset int, ok bool) {
+	limit, offset = defaultLimit, 0
+	query := r.URL.Query()
+
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxLimit {
+			writeError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return 0, 0, false
+		}
+		limit = parsed
+	}
+
+	if value := query.Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			writeError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return 0, 0, false
+		}
+		offset = parsed
+	}
+
+	return limit, offset, true
+}
+
+// paginate returns one page of records
+func paginate(records []models.AttendanceRecord, limit, offset int) Page {
+	page := Page{Total: len(records), Limit: limit, Offset: offset}
+
+	start := min(offset, len(records))
+	end := min(start+limit, len(records))
+	page.Data = append([]models.AttendanceRecord{}, records[start:end]...)
+
+	return page
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, ErrorResponse{Error: message})
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// departmentSuffix names the department an export is limited to in its audit details
+func departmentSuffix(department string) string {
+	if department == "" {
+		return ""
+	}
+	return " " + department
+}
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/reports"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testKey is the API key of the "wallboard" client of servers created by newTestServer
+const testKey = "s3cret-key"
+
+// newTestServer serves the API over a fresh SQLite database seeded with records
+func newTestServer(t *testing.T, records ...models.AttendanceRecord) (http.Handler, *attendance.Service) {
+	t.Helper()
+
+	db, err := database.NewSQLiteDB(filepath.Join(t.TempDir(), "attendance.db"))
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := database.NewRepository(db)
+	if len(records) > 0 {
+		if _, _, err := repo.InsertAttendanceBatch(context.Background(), records); err != nil {
+			t.Fatalf("InsertAttendanceBatch: %v", err)
+		}
+	}
+
+	service := attendance.NewService(repo, attendance.NewTOTPService("JBSWY3DPEHPK3PXP"))
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer("127.0.0.1:0", map[string]string{"wallboard": testKey}, service, logger)
+	return server.Handler(), service
+}
+
+// request sends a request with the test API key and returns the response
+func request(t *testing.T, handler http.Handler, method, target string, body io.Reader) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := httptest.NewRequest(method, target, body)
+	r.Header.Set("Authorization", "Bearer "+testKey)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// decodePage decodes a page of attendance records, failing unless the status is 200
+func decodePage(t *testing.T, w *httptest.ResponseRecorder) (Page, []models.AttendanceRecord) {
+	t.Helper()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var page struct {
+		Page
+		Data []models.AttendanceRecord `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("bad JSON %s: %v", w.Body, err)
+	}
+	return page.Page, page.Data
+}
+
+// assertError checks an error response's status and message
+func assertError(t *testing.T, w *httptest.ResponseRecorder, code int, message string) {
+	t.Helper()
+
+	if w.Code != code {
+		t.Errorf("status = %d, want %d: %s", w.Code, code, w.Body)
+	}
+	var response ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("bad JSON %s: %v", w.Body, err)
+	}
+	if response.Error != message {
+		t.Errorf("error = %q, want %q", response.Error, message)
+	}
+}
+
+// day returns records of a user's day: a check-in, a break and a check-out
+func day(userID int64, name, date string, checkIn, checkOut time.Time) []models.AttendanceRecord {
+	return []models.AttendanceRecord{
+		{UserID: userID, FirstName: name, Timestamp: checkIn, Type: "check_in", Date: date},
+		{UserID: userID, FirstName: name, Timestamp: checkIn.Add(3 * time.Hour), Type: "break_start", Date: date},
+		{UserID: userID, FirstName: name, Timestamp: checkIn.Add(4 * time.Hour), Type: "break_end", Date: date},
+		{UserID: userID, FirstName: name, Timestamp: checkOut, Type: "check_out", Date: date},
+	}
+}
+
+func TestAuth(t *testing.T) {
+	handler, _ := newTestServer(t)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"no key", nil, http.StatusUnauthorized},
+		{"wrong bearer", map[string]string{"Authorization": "Bearer nope"}, http.StatusUnauthorized},
+		{"empty bearer", map[string]string{"Authorization": "Bearer "}, http.StatusUnauthorized},
+		{"basic scheme", map[string]string{"Authorization": "Basic " + testKey}, http.StatusUnauthorized},
+		{"key prefix", map[string]string{"Authorization": "Bearer " + testKey[:4]}, http.StatusUnauthorized},
+		{"bearer", map[string]string{"Authorization": "Bearer " + testKey}, http.StatusOK},
+		{"header", map[string]string{"X-API-Key": testKey}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/attendance?date=2024-03-04", nil)
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want == http.StatusUnauthorized {
+				assertError(t, w, http.StatusUnauthorized, "missing or invalid API key")
+				if got := w.Header().Get("WWW-Authenticate"); got != `Bearer realm="attendance"` {
+					t.Errorf("WWW-Authenticate = %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	handler, _ := newTestServer(t)
+
+	tests := []struct {
+		name     string
+		incoming string
+		echoed   bool
+	}{
+		{"from proxy", "proxy-id.42", true},
+		{"invalid", "bad id\n", false},
+		{"too long", strings.Repeat("a", 65), false},
+		{"missing", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/attendance", nil)
+			r.Header.Set("X-Request-ID", tt.incoming)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			// Unauthorized responses carry the request ID too
+			got := w.Header().Get("X-Request-ID")
+			if tt.echoed && got != tt.incoming {
+				t.Errorf("X-Request-ID = %q, want %q", got, tt.incoming)
+			}
+			if !tt.echoed && (got == "" || got == tt.incoming) {
+				t.Errorf("X-Request-ID = %q, want a fresh ID", got)
+			}
+		})
+	}
+}
+
+func TestAttendance(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2024, 3, day, hour, 0, 0, 0, utils.Location) }
+	var records []models.AttendanceRecord
+	records = append(records, day(1, "Sari", "2024-03-04", at(4, 8), at(4, 17))...)
+	records = append(records, day(2, "Budi", "2024-03-04", at(4, 9), at(4, 18))...)
+	records = append(records, day(1, "Sari", "2024-03-05", at(5, 8), at(5, 17))...)
+	handler, _ := newTestServer(t, records...)
+
+	page, data := decodePage(t, request(t, handler, http.MethodGet, "/api/v1/attendance?date=2024-03-04", nil))
+	if page.Total != 4 || page.Limit != defaultLimit || page.Offset != 0 || len(data) != 4 {
+		t.Fatalf("page = %+v with %d records, want 4 check-ins and check-outs", page, len(data))
+	}
+	for _, record := range data {
+		if record.Date != "2024-03-04" || (record.Type != "check_in" && record.Type != "check_out") {
+			t.Errorf("record = %s on %s, want check-ins and check-outs of 2024-03-04", record.Type, record.Date)
+		}
+	}
+
+	page, data = decodePage(t, request(t, handler, http.MethodGet, "/api/v1/attendance?date=2024-03-04&limit=3&offset=2", nil))
+	if page.Total != 4 || page.Limit != 3 || page.Offset != 2 || len(data) != 2 {
+		t.Errorf("page = %+v with %d records, want the last 2 of 4", page, len(data))
+	}
+
+	w := request(t, handler, http.MethodGet, "/api/v1/attendance?date=2024-03-04&offset=10", nil)
+	page, data = decodePage(t, w)
+	if page.Total != 4 || len(data) != 0 || !strings.Contains(w.Body.String(), `"data":[]`) {
+		t.Errorf("page past the end = %s, want an empty data array", w.Body)
+	}
+
+	page, _ = decodePage(t, request(t, handler, http.MethodGet, "/api/v1/attendance?date=2024-01-01", nil))
+	if page.Total != 0 {
+		t.Errorf("empty date total = %d, want 0", page.Total)
+	}
+}
+
+func TestAttendanceBadRequests(t *testing.T) {
+	handler, _ := newTestServer(t)
+
+	tests := []struct {
+		query   string
+		message string
+	}{
+		{"date=04-03-2024", "date must be YYYY-MM-DD"},
+		{"date=today", "date must be YYYY-MM-DD"},
+		{"limit=0", "limit must be between 1 and 500"},
+		{"limit=501", "limit must be between 1 and 500"},
+		{"limit=ten", "limit must be between 1 and 500"},
+		{"offset=-1", "offset must be a non-negative integer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assertError(t, request(t, handler, http.MethodGet, "/api/v1/attendance?"+tt.query, nil), http.StatusBadRequest, tt.message)
+		})
+	}
+}
+
+func TestUserHistory(t *testing.T) {
+	yesterday := utils.Now().AddDate(0, 0, -1)
+	date := yesterday.Format("2006-01-02")
+	checkIn := time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 8, 0, 0, 0, utils.Location)
+	handler, _ := newTestServer(t, day(1001, "Sari", date, checkIn, checkIn.Add(9*time.Hour))...)
+
+	page, data := decodePage(t, request(t, handler, http.MethodGet, "/api/v1/users/1001/history?days=7", nil))
+	if page.Total != 2 || len(data) != 2 || data[0].UserID != 1001 {
+		t.Errorf("history = %+v with %+v, want the check-in and check-out of user 1001", page, data)
+	}
+
+	assertError(t, request(t, handler, http.MethodGet, "/api/v1/users/1002/history", nil), http.StatusNotFound, "no attendance found for user")
+
+	for _, target := range []string{"/api/v1/users/abc/history", "/api/v1/users/-5/history", "/api/v1/users/0/history"} {
+		assertError(t, request(t, handler, http.MethodGet, target, nil), http.StatusBadRequest, "user id must be a positive integer")
+	}
+	for _, days := range []string{"0", "367", "x"} {
+		assertError(t, request(t, handler, http.MethodGet, "/api/v1/users/1001/history?days="+days, nil), http.StatusBadRequest, "days must be between 1 and 366")
+	}
+}
+
+func TestReportToday(t *testing.T) {
+	now := utils.Now()
+	today := now.Format("2006-01-02")
+	at := func(hour, minute int) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, utils.Location)
+	}
+	records := day(1, "Sari", today, at(8, 30), at(17, 30))
+	records = append(records, models.AttendanceRecord{UserID: 2, FirstName: "Budi", Timestamp: at(9, 15), Type: "check_in", Date: today})
+	handler, _ := newTestServer(t, records...)
+
+	w := request(t, handler, http.MethodGet, "/api/v1/report/today", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var report Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("bad JSON %s: %v", w.Body, err)
+	}
+
+	if report.Date != today || report.Users != 2 || report.CheckIns != 2 || report.CheckOuts != 1 {
+		t.Fatalf("report = %+v, want 2 users, 2 check-ins and 1 check-out today", report)
+	}
+	sari, budi := report.Entries[0], report.Entries[1]
+	if sari.Name != "Sari" || sari.Late || sari.CheckOut == nil || sari.WorkDuration != "8 jam 0 menit" {
+		t.Errorf("Sari = %+v, want on time with 8 hours after the break", sari)
+	}
+	if budi.Name != "Budi" || !budi.Late || budi.CheckOut != nil || budi.WorkDuration != "" {
+		t.Errorf("Budi = %+v, want late without a check-out", budi)
+	}
+}
+
+func TestExport(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2024, 3, day, hour, 0, 0, 0, utils.Location) }
+	records := day(1, "Sari", "2024-03-04", at(4, 8), at(4, 17))
+	handler, service := newTestServer(t, records...)
+
+	w := request(t, handler, http.MethodPost, "/api/v1/reports/export", strings.NewReader(`{"start_date":"2024-03-04","end_date":"2024-03-05"}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="attendance_2024-03-04_to_2024-03-05.csv"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	ctx := context.Background()
+	stored, _ := service.GetAttendanceReportRange(ctx, "2024-03-04", "2024-03-05")
+	leave, _ := service.GetLeaveDays(ctx, "2024-03-04", "2024-03-05")
+	shifts, _ := service.GetShiftAssignments(ctx)
+	var want bytes.Buffer
+	if err := reports.ExportFormats["csv"].Write(&want, stored, leave, shifts); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if !bytes.Equal(w.Body.Bytes(), want.Bytes()) {
+		t.Errorf("export =\n%s\nwant\n%s", w.Body, want.String())
+	}
+
+	tests := []struct {
+		body    string
+		message string
+	}{
+		{`not json`, "body must be a JSON object with start_date, end_date and format"},
+		{`{"start_date":"2024-03-04","admin":true}`, "body must be a JSON object with start_date, end_date and format"},
+		{`{"start_date":"2024-3-4"}`, "start_date and end_date must be YYYY-MM-DD"},
+		{`{"start_date":"2024-03-05","end_date":"2024-03-04"}`, "the period must run forwards and span at most 366 days"},
+		{`{"start_date":"2024-01-01","end_date":"2025-01-01"}`, "the period must run forwards and span at most 366 days"},
+		{`{"start_date":"2024-03-04","format":"docx"}`, "format must be csv, pivot, xlsx or json"},
+		{`{"start_date":"2024-03-04","work_mode":"beach"}`, "work_mode must be office or remote"},
+	}
+	for _, tt := range tests {
+		assertError(t, request(t, handler, http.MethodPost, "/api/v1/reports/export", strings.NewReader(tt.body)), http.StatusBadRequest, tt.message)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	handler, _ := newTestServer(t)
+
+	for _, target := range []string{"/api/v1/attendance", "/api/v1/users/1/history", "/api/v1/report/today"} {
+		for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+			if w := request(t, handler, method, target, nil); w.Code != http.StatusMethodNotAllowed {
+				t.Errorf("%s %s = %d, want 405", method, target, w.Code)
+			}
+		}
+	}
+}
+
+// failingService fails every query, as a broken database would
+type failingService struct {
+	Service
+	err error
+}
+
+func (s failingService) GetAttendanceReportRange(context.Context, string, string) ([]models.AttendanceRecord, error) {
+	return nil, s.err
+}
+
+func TestServiceErrors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name    string
+		err     error
+		code    int
+		message string
+	}{
+		{"storage", &database.StorageError{Op: "query attendance", Err: errors.New("disk I/O error")}, http.StatusInternalServerError, "internal error"},
+		{"invalid range", attendance.ErrInvalidDateRange, http.StatusBadRequest, "invalid date range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewServer("", map[string]string{"wallboard": testKey}, failingService{err: tt.err}, logger).Handler()
+			w := request(t, handler, http.MethodGet, "/api/v1/attendance?date=2024-03-04", nil)
+			assertError(t, w, tt.code, tt.message)
+			if strings.Contains(w.Body.String(), "disk") {
+				t.Errorf("response leaks the internal error: %s", w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package attendance
+
+import (
+	"attendance-bot/pkg/models"
+	"context"
+	"time"
+)
+
+// AddAdmin grants a user admin rights on behalf of addedBy, returning false if they already had them
+func (s *Service) AddAdmin(ctx context.Context, userID, addedBy int64) (bool, error) {
+	return s.repo.AddAdmin(ctx, &models.Admin{
+		UserID:  userID,
+		Role:    models.RoleAdmin,
+		AddedBy: addedBy,
+		AddedAt: time.Now(),
+	})
+}
+
+// RemoveAdmin revokes a user's admin rights, returning false if they had none
+func (s *Service) RemoveAdmin(ctx context.Context, userID int64) (bool, error) {
+	return s.repo.RemoveAdmin(ctx, userID)
+}
+
+// GetAdmins returns the admins stored in the database, in the order they were added
+func (s *Service) GetAdmins(ctx context.Context) ([]models.Admin, error) {
+	return s.repo.GetAdmins(ctx)
+}
+
+// GetAdminRole returns the role stored for the user, or "" if they are not an admin
+func (s *Service) GetAdminRole(ctx context.Context, userID int64) (string, error) {
+	admin, err := s.repo.GetAdmin(ctx, userID)
+	if err != nil || admin == nil {
+		return "", err
+	}
+	return admin.Role, nil
+}
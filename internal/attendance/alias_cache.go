@@ -0,0 +1,100 @@
+package attendance
+
+import (
+	"attendance-bot/pkg/models"
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Alias cache sizing. Entries expire after aliasCacheTTL so changes made outside this
+// process, e.g. with the admin CLI, are picked up without a restart.
+const (
+	aliasCacheSize = 1024
+	aliasCacheTTL  = 5 * time.Minute
+)
+
+// aliasCache is a bounded LRU cache of user aliases with a TTL. Users without an alias are
+// cached too, as a nil alias, since they are the common case. It is safe for concurrent use.
+type aliasCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[int64]*list.Element
+	order   *list.List // Front is the most recently used
+	now     func() time.Time
+}
+
+// aliasEntry is one cached lookup
+type aliasEntry struct {
+	userID  int64
+	alias   *models.UserAlias
+	expires time.Time
+}
+
+// newAliasCache creates a cache holding up to size aliases for ttl each
+func newAliasCache(size int, ttl time.Duration) *aliasCache {
+	return &aliasCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[int64]*list.Element, size),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// get returns the cached alias for the user and whether there was a fresh entry
+func (c *aliasCache) get(userID int64) (*models.UserAlias, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[userID]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*aliasEntry)
+	if c.now().After(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.alias, true
+}
+
+// set caches the alias for the user, evicting the least recently used entry when full
+func (c *aliasCache) set(userID int64, alias *models.UserAlias) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if element, ok := c.entries[userID]; ok {
+		entry := element.Value.(*aliasEntry)
+		entry.alias = alias
+		entry.expires = expires
+		c.order.MoveToFront(element)
+		return
+	}
+
+	if c.order.Len() >= c.size {
+		c.remove(c.order.Back())
+	}
+	c.entries[userID] = c.order.PushFront(&aliasEntry{userID: userID, alias: alias, expires: expires})
+}
+
+// invalidate drops the cached alias for the user
+func (c *aliasCache) invalidate(userID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[userID]; ok {
+		c.remove(element)
+	}
+}
+
+// remove deletes an element; the caller holds the lock
+func (c *aliasCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*aliasEntry).userID)
+}
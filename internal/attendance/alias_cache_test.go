@@ -0,0 +1,169 @@
+package attendance
+
+import (
+	"attendance-bot/internal/database"
+	"attendance-bot/pkg/models"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// aliasCountingRepo counts the alias lookups reaching the repository
+type aliasCountingRepo struct {
+	database.Repository
+	lookups atomic.Int64
+}
+
+func (r *aliasCountingRepo) GetUserAlias(ctx context.Context, userID int64) (*models.UserAlias, error) {
+	r.lookups.Add(1)
+	return r.Repository.GetUserAlias(ctx, userID)
+}
+
+// newAliasTestService creates a service whose alias lookups are counted
+func newAliasTestService(t *testing.T) (*Service, *aliasCountingRepo) {
+	t.Helper()
+
+	repo := &aliasCountingRepo{Repository: newTestRepository(t)}
+	return NewService(repo, NewTOTPService(testSecret)), repo
+}
+
+func TestAliasCacheWarmReadsSkipRepository(t *testing.T) {
+	service, repo := newAliasTestService(t)
+	ctx := context.Background()
+
+	last := "Wijaya"
+	if err := service.SetUserAlias(ctx, 1, "Sari", &last); err != nil {
+		t.Fatalf("SetUserAlias: %v", err)
+	}
+
+	record := &models.AttendanceRecord{UserID: 1, FirstName: "sari_w"}
+	if got := service.DisplayName(ctx, record); got != "Sari Wijaya" {
+		t.Fatalf("DisplayName() = %q, want %q", got, "Sari Wijaya")
+	}
+	cold := repo.lookups.Load()
+
+	for range 10 {
+		if got := service.DisplayName(ctx, record); got != "Sari Wijaya" {
+			t.Fatalf("DisplayName() = %q, want %q", got, "Sari Wijaya")
+		}
+		if _, err := service.GetUserAlias(ctx, 1); err != nil {
+			t.Fatalf("GetUserAlias: %v", err)
+		}
+	}
+	if warm := repo.lookups.Load() - cold; warm != 0 {
+		t.Errorf("repository lookups on warm reads = %d, want 0", warm)
+	}
+
+	// Users without an alias are cached too
+	other := &models.AttendanceRecord{UserID: 2, FirstName: "Budi"}
+	service.DisplayName(ctx, other)
+	cold = repo.lookups.Load()
+	for range 10 {
+		if got := service.DisplayName(ctx, other); got != "Budi" {
+			t.Fatalf("DisplayName() = %q, want %q", got, "Budi")
+		}
+	}
+	if warm := repo.lookups.Load() - cold; warm != 0 {
+		t.Errorf("repository lookups on warm reads without an alias = %d, want 0", warm)
+	}
+}
+
+func TestAliasCacheInvalidatedBySetAndDelete(t *testing.T) {
+	service, _ := newAliasTestService(t)
+	ctx := context.Background()
+	record := &models.AttendanceRecord{UserID: 1, FirstName: "sari_w"}
+
+	steps := []struct {
+		name   string
+		change func() error
+		want   string
+	}{
+		{"no alias", func() error { return nil }, "sari_w"},
+		{"set", func() error { return service.SetUserAlias(ctx, 1, "Sari", nil) }, "Sari"},
+		{"replaced", func() error { return service.SetUserAlias(ctx, 1, "Sari Dewi", nil) }, "Sari Dewi"},
+		{"deleted", func() error {
+			_, err := service.DeleteUserAlias(ctx, 1)
+			return err
+		}, "sari_w"},
+	}
+
+	for _, step := range steps {
+		if err := step.change(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		// Read twice so the second read is served from the cache
+		for range 2 {
+			if got := service.DisplayName(ctx, record); got != step.want {
+				t.Errorf("%s: DisplayName() = %q, want %q", step.name, got, step.want)
+			}
+		}
+	}
+}
+
+func TestAliasCacheExpires(t *testing.T) {
+	cache := newAliasCache(8, time.Minute)
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	cache.set(1, &models.UserAlias{UserID: 1, FirstName: "Sari"})
+	now = now.Add(time.Minute)
+	if alias, ok := cache.get(1); !ok || alias.FirstName != "Sari" {
+		t.Errorf("get() at the TTL = %v, %v, want the cached alias", alias, ok)
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := cache.get(1); ok {
+		t.Error("get() after the TTL found an entry, want none")
+	}
+}
+
+func TestAliasCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newAliasCache(2, time.Hour)
+
+	cache.set(1, nil)
+	cache.set(2, nil)
+	cache.get(1)
+	cache.set(3, nil) // evicts 2, the least recently used
+
+	for userID, want := range map[int64]bool{1: true, 2: false, 3: true} {
+		if _, ok := cache.get(userID); ok != want {
+			t.Errorf("user %d cached = %v, want %v", userID, ok, want)
+		}
+	}
+	if got := cache.order.Len(); got != 2 {
+		t.Errorf("entries = %d, want 2", got)
+	}
+}
+
+// TestAliasCacheConcurrent sets each user's alias from one goroutine while others read it, as
+// workers handling different users' updates do
+func TestAliasCacheConcurrent(t *testing.T) {
+	service, _ := newAliasTestService(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			userID := int64(i % 4)
+			for j := range 20 {
+				if i < 4 && j%5 == 0 {
+					if err := service.SetUserAlias(ctx, userID, "Alias", nil); err != nil {
+						t.Errorf("SetUserAlias: %v", err)
+					}
+				}
+				service.DisplayName(ctx, &models.AttendanceRecord{UserID: userID, FirstName: "Name"})
+			}
+		}()
+	}
+	wg.Wait()
+
+	for userID := range int64(4) {
+		if got := service.DisplayName(ctx, &models.AttendanceRecord{UserID: userID, FirstName: "Name"}); got != "Alias" {
+			t.Errorf("DisplayName(%d) = %q, want %q", userID, got, "Alias")
+		}
+	}
+}
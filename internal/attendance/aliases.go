@@ -0,0 +1,85 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"time"
+)
+
+// FindAliasConflicts returns the names of other users that the alias would be mistaken for:
+// their aliases and Telegram names that are equal ignoring case and spacing
+func (s *Service) FindAliasConflicts(ctx context.Context, userID int64, firstName string, lastName *string) ([]models.NameMatch, error) {
+	last := ""
+	if lastName != nil {
+		last = *lastName
+	}
+	return s.repo.FindNameMatches(ctx, userID, utils.NameKey(firstName, last))
+}
+
+// RequestAlias stores a conflicting alias for admin approval, replacing the user's previous
+// request. The user's current alias stays in effect until the request is approved.
+func (s *Service) RequestAlias(ctx context.Context, userID int64, firstName string, lastName *string) error {
+	return s.repo.SaveAliasRequest(ctx, &models.AliasRequest{
+		UserID:      userID,
+		FirstName:   firstName,
+		LastName:    lastName,
+		RequestedAt: time.Now(),
+	})
+}
+
+// ApproveAlias sets the user's requested alias, returning nil if they have no pending request
+func (s *Service) ApproveAlias(ctx context.Context, userID int64) (*models.AliasRequest, error) {
+	request, err := s.repo.GetAliasRequest(ctx, userID)
+	if err != nil || request == nil {
+		return nil, err
+	}
+
+	if err := s.SetUserAlias(ctx, userID, request.FirstName, request.LastName); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.DeleteAliasRequest(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// RejectAlias discards the user's requested alias, returning nil if they have no pending request
+func (s *Service) RejectAlias(ctx context.Context, userID int64) (*models.AliasRequest, error) {
+	request, err := s.repo.GetAliasRequest(ctx, userID)
+	if err != nil || request == nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.DeleteAliasRequest(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// CancelAliasRequest discards the user's pending alias request, if any, once they set an
+// alias that needs no approval
+func (s *Service) CancelAliasRequest(ctx context.Context, userID int64) error {
+	_, err := s.repo.DeleteAliasRequest(ctx, userID)
+	return err
+}
+
+// GetAliasRequests returns the alias requests awaiting approval, oldest first
+func (s *Service) GetAliasRequests(ctx context.Context) ([]models.AliasRequest, error) {
+	return s.repo.GetAliasRequests(ctx)
+}
+
+// GetNameCollisions returns the names shared by more than one user, grouped by normalized name
+func (s *Service) GetNameCollisions(ctx context.Context) ([]models.NameMatch, error) {
+	return s.repo.GetNameCollisions(ctx)
+}
+
+// FullName joins a first name and an optional last name
+func FullName(firstName string, lastName *string) string {
+	if lastName != nil && *lastName != "" {
+		return firstName + " " + *lastName
+	}
+	return firstName
+}
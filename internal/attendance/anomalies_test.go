@@ -0,0 +1,77 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestGetMissingCheckoutsExcludesToday leaves check-ins open two days ago, yesterday and today:
+// today's is left out, the end of the range is moved to yesterday and aliases name the users
+func TestGetMissingCheckoutsExcludesToday(t *testing.T) {
+	service, repo := newTestService(t)
+	ctx := context.Background()
+
+	now := utils.Now()
+	day := func(offset int) string { return utils.AddDays(now, offset).Format("2006-01-02") }
+	record := func(userID int64, date string) models.AttendanceRecord {
+		return models.AttendanceRecord{UserID: userID, FirstName: "Budi", Username: "budi", Timestamp: now, Type: "check_in", Date: date}
+	}
+	if _, _, err := repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{
+		record(1, day(-2)),
+		record(2, day(-1)),
+		record(1, day(0)),
+	}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+	lastName := "Santoso"
+	if err := service.SetUserAlias(ctx, 2, "Bambang", &lastName); err != nil {
+		t.Fatalf("SetUserAlias: %v", err)
+	}
+
+	missing, end, err := service.GetMissingCheckouts(ctx, day(-2), day(0))
+	if err != nil {
+		t.Fatalf("GetMissingCheckouts: %v", err)
+	}
+	if end != day(-1) {
+		t.Errorf("end = %s, want yesterday %s", end, day(-1))
+	}
+	if len(missing) != 2 {
+		t.Fatalf("missing = %+v, want the days before today", missing)
+	}
+	if missing[0].UserID != 1 || missing[0].Date != day(-2) || missing[0].Name != "Budi" || missing[0].Username != "budi" {
+		t.Errorf("first = %+v, want Budi two days ago", missing[0])
+	}
+	if missing[1].UserID != 2 || missing[1].Date != day(-1) || missing[1].Name != "Bambang Santoso" {
+		t.Errorf("second = %+v, want the alias Bambang Santoso yesterday", missing[1])
+	}
+
+	missing, end, err = service.GetMissingCheckouts(ctx, day(0), day(0))
+	if err != nil || len(missing) != 0 || end != day(-1) {
+		t.Errorf("today only = %+v ending %s (%v), want nothing ending yesterday", missing, end, err)
+	}
+}
+
+func TestGetMissingCheckoutsInvalidRange(t *testing.T) {
+	service, _ := newTestService(t)
+
+	tests := []struct {
+		name       string
+		start, end string
+	}{
+		{"bad start", "2024-3-1", "2024-03-10"},
+		{"bad end", "2024-03-01", "10-03-2024"},
+		{"backwards", "2024-03-10", "2024-03-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := service.GetMissingCheckouts(context.Background(), tt.start, tt.end)
+			if !errors.Is(err, ErrInvalidDateRange) {
+				t.Errorf("GetMissingCheckouts(%s, %s) error = %v, want ErrInvalidDateRange", tt.start, tt.end, err)
+			}
+		})
+	}
+}
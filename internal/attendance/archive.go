@@ -0,0 +1,80 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// archiveBatchSize is the number of records moved per archive transaction
+const archiveBatchSize = 500
+
+// ErrInvalidArchiveYear is returned when archiving the current year, a future year or an implausible one
+var ErrInvalidArchiveYear = errors.New("invalid archive year")
+
+// ArchiveResult summarises an archive run
+type ArchiveResult struct {
+	Before   string // Records dated before this date (YYYY-MM-DD) were archived
+	Moved    int    // Records moved by this run
+	Batches  int    // Transactions committed
+	Archived int    // Records before the cutoff now in the archive, including earlier runs
+}
+
+// ArchiveYear moves every record dated in year or earlier into the archive, in transactional
+// batches. An interrupted run leaves each batch either fully moved or untouched, so running it
+// again resumes where it stopped. progress, if not nil, is called after each batch.
+func (s *Service) ArchiveYear(ctx context.Context, year int, progress func(moved int)) (*ArchiveResult, error) {
+	if year < 2000 || year >= utils.Now().Year() {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidArchiveYear, year)
+	}
+
+	before := fmt.Sprintf("%04d-01-01", year+1)
+
+	// Reads must include the archive before the first record leaves the attendance table
+	current, err := s.repo.GetArchivedBefore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if before > current {
+		if err := s.repo.SetArchivedBefore(ctx, before); err != nil {
+			return nil, err
+		}
+	}
+
+	hotBefore, archivedBefore, err := s.repo.CountAttendanceBefore(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ArchiveResult{Before: before}
+	for {
+		moved, err := s.repo.ArchiveAttendanceBatch(ctx, before, archiveBatchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to archive batch %d: %w", result.Batches+1, err)
+		}
+		if moved == 0 {
+			break
+		}
+
+		result.Moved += moved
+		result.Batches++
+		if progress != nil {
+			progress(result.Moved)
+		}
+	}
+
+	// Every record counted before the run must now be in the archive and nowhere else
+	hotAfter, archivedAfter, err := s.repo.CountAttendanceBefore(ctx, before)
+	if err != nil {
+		return result, err
+	}
+	result.Archived = archivedAfter
+
+	if hotAfter != 0 || archivedAfter != archivedBefore+hotBefore {
+		return result, fmt.Errorf("archive counts do not reconcile: %d records left, %d archived, expected %d",
+			hotAfter, archivedAfter, archivedBefore+hotBefore)
+	}
+
+	return result, nil
+}
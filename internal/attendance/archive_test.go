@@ -0,0 +1,217 @@
+package attendance
+
+import (
+	"attendance-bot/internal/reports"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seedArchiveDays inserts a check-in and check-out per user and day from 1 November 2022 to
+// 31 January 2023, and returns how many were dated in 2022
+func seedArchiveDays(t *testing.T, service *Service, users int) int {
+	t.Helper()
+
+	var records []models.AttendanceRecord
+	in2022 := 0
+	end := time.Date(2023, 2, 1, 0, 0, 0, 0, utils.Location)
+	for day := time.Date(2022, 11, 1, 0, 0, 0, 0, utils.Location); day.Before(end); day = day.AddDate(0, 0, 1) {
+		for user := 1; user <= users; user++ {
+			date := day.Format("2006-01-02")
+			// Users share check-in times, so the output depends on the order of equal timestamps
+			checkIn := day.Add(8*time.Hour + time.Duration(user%3)*time.Minute)
+			records = append(records,
+				models.AttendanceRecord{UserID: int64(user), FirstName: fmt.Sprintf("User%d", user), Timestamp: checkIn, Type: "check_in", Date: date},
+				models.AttendanceRecord{UserID: int64(user), FirstName: fmt.Sprintf("User%d", user), Timestamp: checkIn.Add(9 * time.Hour), Type: "check_out", Date: date},
+			)
+			if day.Year() == 2022 {
+				in2022 += 2
+			}
+		}
+	}
+	if _, _, err := service.repo.InsertAttendanceBatch(context.Background(), records); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+	return in2022
+}
+
+// exportCSV returns the CSV report from startDate to endDate
+func exportCSV(t *testing.T, service *Service, startDate, endDate string) []byte {
+	t.Helper()
+
+	records, err := service.GetAttendanceReportRange(context.Background(), startDate, endDate)
+	if err != nil {
+		t.Fatalf("GetAttendanceReportRange: %v", err)
+	}
+	csv, err := reports.NewCSVGenerator().GenerateAttendanceReport(records, nil)
+	if err != nil {
+		t.Fatalf("GenerateAttendanceReport: %v", err)
+	}
+	return csv
+}
+
+// archiveRanges are the export periods compared before and after archiving 2022: archived only,
+// spanning the cutoff and not archived
+var archiveRanges = [][2]string{
+	{"2022-11-01", "2022-12-31"},
+	{"2022-12-15", "2023-01-15"},
+	{"2023-01-01", "2023-01-31"},
+}
+
+// TestArchiveYearKeepsExports archives 2022 in several batches: the CSV of every period is
+// identical before and after, and a second run moves nothing
+func TestArchiveYearKeepsExports(t *testing.T) {
+	service, repo := newTestService(t)
+	ctx := context.Background()
+	in2022 := seedArchiveDays(t, service, 9)
+	if in2022 <= 2*archiveBatchSize {
+		t.Fatalf("seeded %d records in 2022, want more than two batches", in2022)
+	}
+
+	before := make([][]byte, len(archiveRanges))
+	for i, r := range archiveRanges {
+		before[i] = exportCSV(t, service, r[0], r[1])
+		if !bytes.Contains(before[i], []byte("User9")) {
+			t.Fatalf("CSV from %s to %s has no attendance:\n%s", r[0], r[1], before[i])
+		}
+	}
+
+	var progress []int
+	result, err := service.ArchiveYear(ctx, 2022, func(moved int) { progress = append(progress, moved) })
+	if err != nil {
+		t.Fatalf("ArchiveYear: %v", err)
+	}
+	batches := (in2022 + archiveBatchSize - 1) / archiveBatchSize
+	if result.Before != "2023-01-01" || result.Moved != in2022 || result.Batches != batches || result.Archived != in2022 {
+		t.Errorf("ArchiveYear = %+v, want %d records moved in %d batches before 2023-01-01", *result, in2022, batches)
+	}
+	if len(progress) != batches || progress[len(progress)-1] != in2022 {
+		t.Errorf("progress = %v, want %d calls ending at %d", progress, batches, in2022)
+	}
+
+	hot, archived, err := repo.CountAttendanceBefore(ctx, "2023-01-01")
+	if err != nil {
+		t.Fatalf("CountAttendanceBefore: %v", err)
+	}
+	if hot != 0 || archived != in2022 {
+		t.Errorf("CountAttendanceBefore = %d, %d, want 0, %d", hot, archived, in2022)
+	}
+
+	for i, r := range archiveRanges {
+		if after := exportCSV(t, service, r[0], r[1]); !bytes.Equal(after, before[i]) {
+			t.Errorf("CSV from %s to %s changed after archiving:\n%s\nwant\n%s", r[0], r[1], after, before[i])
+		}
+	}
+
+	again, err := service.ArchiveYear(ctx, 2022, nil)
+	if err != nil {
+		t.Fatalf("ArchiveYear again: %v", err)
+	}
+	if again.Moved != 0 || again.Batches != 0 || again.Archived != in2022 {
+		t.Errorf("ArchiveYear again = %+v, want nothing moved", *again)
+	}
+}
+
+// TestImportRefusesArchivedDays imports rows into an archived year and the year after: the
+// archived rows are rejected, the report keeps its record count, and archiving again still works
+func TestImportRefusesArchivedDays(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	in2022 := seedArchiveDays(t, service, 2)
+	if _, err := service.ArchiveYear(ctx, 2022, nil); err != nil {
+		t.Fatalf("ArchiveYear: %v", err)
+	}
+	countRecords := func(startDate, endDate string) int {
+		t.Helper()
+		records, err := service.GetAttendanceReportRange(ctx, startDate, endDate)
+		if err != nil {
+			t.Fatalf("GetAttendanceReportRange: %v", err)
+		}
+		return len(records)
+	}
+	archived, live := countRecords("2022-11-01", "2022-12-31"), countRecords("2023-01-01", "2023-01-31")
+
+	csv := "user_id,name,date,type,time\n" +
+		"1,User1,2022-12-12,check_in,08:00\n" + // Archived day the user already has
+		"3,User3,2022-12-12,check_in,08:00\n" + // Archived day, new user
+		"3,User3,2023-01-12,check_in,08:00\n"
+	result, err := service.ImportAttendance(ctx, strings.NewReader(csv), "test.csv", false, 1)
+	if err != nil {
+		t.Fatalf("ImportAttendance: %v", err)
+	}
+	if result.Inserted != 1 || result.Errors != 2 {
+		t.Errorf("ImportAttendance = %+v, want 1 inserted and 2 rejected", *result)
+	}
+	for _, reject := range result.Rejects {
+		if !strings.Contains(reject.Reason, "archived") {
+			t.Errorf("line %d rejected for %q, want an archived day", reject.Line, reject.Reason)
+		}
+	}
+
+	if got := countRecords("2022-11-01", "2022-12-31"); got != archived {
+		t.Errorf("archived period has %d records after the import, want %d", got, archived)
+	}
+	if got := countRecords("2023-01-01", "2023-01-31"); got != live+1 {
+		t.Errorf("live period has %d records after the import, want %d", got, live+1)
+	}
+
+	again, err := service.ArchiveYear(ctx, 2022, nil)
+	if err != nil {
+		t.Fatalf("ArchiveYear again: %v", err)
+	}
+	if again.Moved != 0 || again.Archived != in2022 {
+		t.Errorf("ArchiveYear again = %+v, want nothing moved and %d archived", *again, in2022)
+	}
+}
+
+// TestArchiveYearResumes interrupts an archive run after one batch: exports are unchanged while
+// it is half done, and the next run moves the rest
+func TestArchiveYearResumes(t *testing.T) {
+	service, repo := newTestService(t)
+	ctx := context.Background()
+	in2022 := seedArchiveDays(t, service, 9)
+	before := exportCSV(t, service, "2022-12-15", "2023-01-15")
+
+	// The first steps of a run that stopped after its first batch
+	if err := repo.SetArchivedBefore(ctx, "2023-01-01"); err != nil {
+		t.Fatalf("SetArchivedBefore: %v", err)
+	}
+	moved, err := repo.ArchiveAttendanceBatch(ctx, "2023-01-01", archiveBatchSize)
+	if err != nil {
+		t.Fatalf("ArchiveAttendanceBatch: %v", err)
+	}
+	if moved != archiveBatchSize {
+		t.Fatalf("moved = %d, want %d", moved, archiveBatchSize)
+	}
+	if during := exportCSV(t, service, "2022-12-15", "2023-01-15"); !bytes.Equal(during, before) {
+		t.Errorf("CSV changed while half archived:\n%s\nwant\n%s", during, before)
+	}
+
+	result, err := service.ArchiveYear(ctx, 2022, nil)
+	if err != nil {
+		t.Fatalf("ArchiveYear: %v", err)
+	}
+	if result.Moved != in2022-archiveBatchSize || result.Archived != in2022 {
+		t.Errorf("ArchiveYear = %+v, want the remaining %d records moved", *result, in2022-archiveBatchSize)
+	}
+	if after := exportCSV(t, service, "2022-12-15", "2023-01-15"); !bytes.Equal(after, before) {
+		t.Errorf("CSV changed after resuming:\n%s\nwant\n%s", after, before)
+	}
+}
+
+func TestArchiveYearRefusesRecentYears(t *testing.T) {
+	service, _ := newTestService(t)
+	current := utils.Now().Year()
+
+	for _, year := range []int{current, current + 1, 1999} {
+		if _, err := service.ArchiveYear(context.Background(), year, nil); !errors.Is(err, ErrInvalidArchiveYear) {
+			t.Errorf("ArchiveYear(%d) error = %v, want ErrInvalidArchiveYear", year, err)
+		}
+	}
+}
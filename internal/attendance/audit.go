@@ -0,0 +1,42 @@
+package attendance
+
+import (
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+// AuditLogLimit is how many entries the audit log shows at once
+const AuditLogLimit = 20
+
+// Audit records a sensitive operation in the audit log. A failure to record it is logged rather
+// than returned, since the operation itself already happened.
+func (s *Service) Audit(ctx context.Context, entry models.AuditEntry) {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if err := s.repo.InsertAuditEntry(ctx, &entry); err != nil {
+		logging.FromContext(ctx).Error("Failed to record audit entry", "action", entry.Action, "error", err)
+	}
+}
+
+// GetAuditLog returns the newest AuditLogLimit audit log entries concerning the user or, with
+// userID 0, anyone
+func (s *Service) GetAuditLog(ctx context.Context, userID int64) ([]models.AuditEntry, error) {
+	return s.repo.GetAuditLog(ctx, userID, AuditLogLimit)
+}
+
+// GetAuditLogRange returns the audit log entries recorded on the days from startDate to endDate
+// (inclusive, utils.Location), oldest first
+func (s *Service) GetAuditLogRange(ctx context.Context, startDate, endDate string) ([]models.AuditEntry, error) {
+	start, startErr := time.ParseInLocation("2006-01-02", startDate, utils.Location)
+	end, endErr := time.ParseInLocation("2006-01-02", endDate, utils.Location)
+	if startErr != nil || endErr != nil || start.After(end) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidDateRange, startDate, endDate)
+	}
+
+	return s.repo.GetAuditLogRange(ctx, start, end.AddDate(0, 0, 1))
+}
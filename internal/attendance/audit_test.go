@@ -0,0 +1,105 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// seedAuditLog records a report download by admin 900, a rejected OTP of user 1 and an alias
+// change of user 2, on 4, 5 and 6 March 2024 at 23:30 WIB
+func seedAuditLog(t *testing.T, service *Service) {
+	t.Helper()
+
+	entries := []models.AuditEntry{
+		{ActorID: 900, Action: models.AuditReportDownloaded, Details: "daily 2024-03-04"},
+		{ActorID: 1, Action: models.AuditOTPFailed, TargetUserID: 1, Details: "1 of 5"},
+		{ActorID: 2, Action: models.AuditAliasSet, TargetUserID: 2, Details: "- → Budi"},
+	}
+	for i, entry := range entries {
+		entry.CreatedAt = time.Date(2024, 3, 4+i, 23, 30, 0, 0, utils.Location)
+		service.Audit(context.Background(), entry)
+	}
+}
+
+// auditActions lists the actions of entries in order
+func auditActions(entries []models.AuditEntry) string {
+	actions := make([]string, len(entries))
+	for i, entry := range entries {
+		actions[i] = entry.Action
+	}
+	return fmt.Sprint(actions)
+}
+
+// TestGetAuditLog reads the newest entries, of anyone or of one user
+func TestGetAuditLog(t *testing.T) {
+	service, _ := newTestService(t)
+	seedAuditLog(t, service)
+
+	tests := []struct {
+		name   string
+		userID int64
+		want   []string
+	}{
+		{"anyone", 0, []string{models.AuditAliasSet, models.AuditOTPFailed, models.AuditReportDownloaded}},
+		{"user", 1, []string{models.AuditOTPFailed}},
+		{"actor only", 900, nil},
+		{"nobody", 3, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := service.GetAuditLog(context.Background(), tt.userID)
+			if err != nil {
+				t.Fatalf("GetAuditLog: %v", err)
+			}
+			if got, want := auditActions(entries), fmt.Sprint(tt.want); got != want {
+				t.Errorf("GetAuditLog(%d) = %s, want %s", tt.userID, got, want)
+			}
+		})
+	}
+}
+
+// TestGetAuditLogRange reads the entries of days in utils.Location, oldest first, and refuses
+// malformed or reversed ranges
+func TestGetAuditLogRange(t *testing.T) {
+	service, _ := newTestService(t)
+	seedAuditLog(t, service)
+
+	tests := []struct {
+		name      string
+		startDate string
+		endDate   string
+		want      []string
+		wantErr   bool
+	}{
+		{name: "all days", startDate: "2024-03-01", endDate: "2024-03-31",
+			want: []string{models.AuditReportDownloaded, models.AuditOTPFailed, models.AuditAliasSet}},
+		{name: "one day", startDate: "2024-03-05", endDate: "2024-03-05", want: []string{models.AuditOTPFailed}},
+		{name: "before", startDate: "2024-03-01", endDate: "2024-03-03"},
+		{name: "reversed", startDate: "2024-03-06", endDate: "2024-03-04", wantErr: true},
+		{name: "malformed", startDate: "2024-3-4", endDate: "2024-03-06", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := service.GetAuditLogRange(context.Background(), tt.startDate, tt.endDate)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDateRange) {
+					t.Errorf("GetAuditLogRange error = %v, want ErrInvalidDateRange", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetAuditLogRange: %v", err)
+			}
+			if got, want := auditActions(entries), fmt.Sprint(tt.want); got != want {
+				t.Errorf("GetAuditLogRange(%s, %s) = %s, want %s", tt.startDate, tt.endDate, got, want)
+			}
+		})
+	}
+}
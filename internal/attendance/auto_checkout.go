@@ -0,0 +1,92 @@
+package attendance
+
+import (
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GetOpenCheckIns returns the check-ins of date without a check-out whose shift has ended by at.
+// Users still on an overnight shift are left out.
+func (s *Service) GetOpenCheckIns(ctx context.Context, date string, at time.Time) ([]models.AttendanceRecord, error) {
+	open, _, err := s.openCheckIns(ctx, date, at)
+	return open, err
+}
+
+// AutoCheckOut records an auto_checkout check-out for every check-in of date still open at at,
+// timestamped at the end of the user's shift, or at at when the shift has no end or ended before
+// the check-in. Auto check-outs carry no overtime. It returns the recorded check-outs; users who
+// checked out meanwhile are skipped.
+func (s *Service) AutoCheckOut(ctx context.Context, date string, at time.Time) ([]models.AttendanceRecord, error) {
+	logger := logging.FromContext(ctx)
+
+	open, shifts, err := s.openCheckIns(ctx, date, at)
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded []models.AttendanceRecord
+	for i := range open {
+		checkIn := &open[i]
+		timestamp := at
+		if end, ok := utils.ShiftEnd(checkIn.Timestamp, shifts.For(checkIn.UserID)); ok && end.After(checkIn.Timestamp) {
+			timestamp = end
+		}
+
+		record, err := s.repo.InsertAttendance(ctx, &models.AttendanceRecord{
+			UserID:    checkIn.UserID,
+			Username:  checkIn.Username,
+			FirstName: checkIn.FirstName,
+			LastName:  checkIn.LastName,
+			Timestamp: timestamp,
+			Type:      "check_out",
+			Date:      date,
+			Source:    models.SourceAuto,
+			Remote:    checkIn.Remote,
+		})
+		if errors.Is(err, database.ErrDuplicate) {
+			logger.Debug("Check-out recorded before auto check-out", "target_user_id", checkIn.UserID, "date", date)
+			continue
+		}
+		if err != nil {
+			return recorded, fmt.Errorf("failed to save auto check-out: %w", err)
+		}
+
+		logger.Info("Attendance recorded", "type", record.Type, "date", date, "record_id", record.ID, "source", record.Source)
+		recorded = append(recorded, *record)
+		s.notifyRecorded(record)
+	}
+
+	if len(recorded) > 0 {
+		s.reports.invalidate(date)
+	}
+	return recorded, nil
+}
+
+// openCheckIns returns the check-ins of date without a check-out whose shift has ended by at,
+// along with the shift assignments used to decide
+func (s *Service) openCheckIns(ctx context.Context, date string, at time.Time) ([]models.AttendanceRecord, *models.ShiftAssignments, error) {
+	records, err := s.repo.GetMissingCheckouts(ctx, date, date)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get missing check-outs: %w", err)
+	}
+
+	shifts, err := s.GetShiftAssignments(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get shift assignments: %w", err)
+	}
+
+	open := records[:0]
+	for _, record := range records {
+		if end, ok := utils.ShiftEnd(record.Timestamp, shifts.For(record.UserID)); ok && end.After(at) {
+			continue
+		}
+		open = append(open, record)
+	}
+	return open, shifts, nil
+}
@@ -0,0 +1,98 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"testing"
+	"time"
+)
+
+// TestAutoCheckOut closes the open check-ins of 4 March 2024 at 23:30 for users on a day shift, a
+// night shift and no shift, and one who checked out themselves
+func TestAutoCheckOut(t *testing.T) {
+	service, repo := newTestService(t)
+	ctx := context.Background()
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, utils.Location)
+	}
+	run := at(4, 23, 30)
+
+	for _, shift := range []models.Shift{
+		{Name: "day", Start: "08:00", End: "16:00"},
+		{Name: "night", Start: "22:00", End: "06:00"},
+	} {
+		if err := service.SaveShift(ctx, &shift); err != nil {
+			t.Fatalf("SaveShift: %v", err)
+		}
+	}
+	for userID, shift := range map[int64]string{1: "day", 2: "day", 3: "night"} {
+		if err := service.AssignShift(ctx, userID, shift, 900); err != nil {
+			t.Fatalf("AssignShift: %v", err)
+		}
+	}
+
+	record := func(userID int64, timestamp time.Time, recordType string) models.AttendanceRecord {
+		return models.AttendanceRecord{UserID: userID, FirstName: "User", Timestamp: timestamp, Type: recordType, Date: "2024-03-04"}
+	}
+	if _, _, err := repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{
+		record(1, at(4, 8, 0), "check_in"),
+		record(2, at(4, 8, 0), "check_in"),
+		record(2, at(4, 17, 0), "check_out"),
+		record(3, at(4, 22, 0), "check_in"),
+		record(4, at(4, 9, 0), "check_in"),
+	}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+
+	open, err := service.GetOpenCheckIns(ctx, "2024-03-04", run)
+	if err != nil {
+		t.Fatalf("GetOpenCheckIns: %v", err)
+	}
+	if len(open) != 2 {
+		t.Errorf("open check-ins = %+v, want users 1 and 4", open)
+	}
+
+	recorded, err := service.AutoCheckOut(ctx, "2024-03-04", run)
+	if err != nil {
+		t.Fatalf("AutoCheckOut: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		userID int64
+		want   time.Time // Zero when no check-out is recorded
+	}{
+		{"day shift, at the end of the shift", 1, at(4, 16, 0)},
+		{"checked out themselves", 2, time.Time{}},
+		{"night shift, still working", 3, time.Time{}},
+		{"no shift end, at the run", 4, run},
+	}
+
+	byUser := make(map[int64]models.AttendanceRecord)
+	for _, record := range recorded {
+		byUser[record.UserID] = record
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, ok := byUser[tt.userID]
+			if tt.want.IsZero() {
+				if ok {
+					t.Errorf("check-out recorded at %v, want none", record.Timestamp)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("no check-out recorded")
+			}
+			if !record.Timestamp.Equal(tt.want) || record.Source != models.SourceAuto || record.Type != "check_out" {
+				t.Errorf("check-out = %s %s at %v, want an auto check-out at %v", record.Type, record.Source, record.Timestamp, tt.want)
+			}
+		})
+	}
+
+	// A second run finds nothing left to close
+	if again, err := service.AutoCheckOut(ctx, "2024-03-04", run); err != nil || len(again) != 0 {
+		t.Errorf("second AutoCheckOut = %d records, %v; want none", len(again), err)
+	}
+}
@@ -0,0 +1,50 @@
+package attendance
+
+import (
+	"attendance-bot/internal/backup"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrBackupsDisabled is returned when making a backup of a database that is not backed up
+var ErrBackupsDisabled = errors.New("backups not configured")
+
+// SetBackups sets the manager making database backups; nil disables backups
+func (s *Service) SetBackups(backups *backup.Manager) {
+	s.backups = backups
+}
+
+// BackupsEnabled reports whether database backups can be made
+func (s *Service) BackupsEnabled() bool {
+	return s.backups != nil
+}
+
+// Backup makes a backup of the database on behalf of actorID, 0 for scheduled backups. A backup
+// that was written but not pruned or uploaded is returned with the error.
+func (s *Service) Backup(ctx context.Context, actorID int64) (*backup.Backup, error) {
+	if s.backups == nil {
+		return nil, ErrBackupsDisabled
+	}
+
+	made, err := s.backups.Run(ctx)
+	if made == nil {
+		return nil, err
+	}
+
+	s.Audit(ctx, models.AuditEntry{
+		ActorID: actorID,
+		Action:  models.AuditBackupCreated,
+		Details: fmt.Sprintf("%s (%d bytes, uploaded: %t)", made.Name, made.Size, made.Uploaded),
+	})
+	return made, err
+}
+
+// GetBackups returns the backups kept, newest first
+func (s *Service) GetBackups() ([]backup.Backup, error) {
+	if s.backups == nil {
+		return nil, ErrBackupsDisabled
+	}
+	return s.backups.List()
+}
@@ -0,0 +1,108 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxBreaks is the number of breaks a day allowed unless SetMaxBreaks is called
+const DefaultMaxBreaks = 3
+
+// Break errors
+var (
+	ErrBreaksDisabled    = errors.New("breaks are disabled")
+	ErrBreakNotCheckedIn = errors.New("not checked in")
+	ErrBreakCheckedOut   = errors.New("already checked out")
+	ErrOnBreak           = errors.New("break already started")
+	ErrNotOnBreak        = errors.New("no break started")
+	ErrBreakLimit        = errors.New("break limit reached")
+)
+
+// SetMaxBreaks sets how many breaks a user may take a day; 0 turns breaks off
+func (s *Service) SetMaxBreaks(limit int) {
+	s.maxBreaks = limit
+}
+
+// MaxBreaks returns how many breaks a user may take a day, 0 when breaks are off
+func (s *Service) MaxBreaks() int {
+	return s.maxBreaks
+}
+
+// StartBreak records the start of a break for a user checked in today and not checked out yet,
+// returning it with the user's status of today. The record's identity must be set. It fails with
+// ErrBreakLimit once MaxBreaks were started today.
+func (s *Service) StartBreak(ctx context.Context, record *models.AttendanceRecord) (*models.AttendanceRecord, *models.AttendanceStatus, error) {
+	status, err := s.breakStatus(ctx, record.UserID)
+	if err != nil {
+		return nil, status, err
+	}
+	switch {
+	case status.OnBreak():
+		return nil, status, ErrOnBreak
+	case status.BreakCount() >= s.maxBreaks:
+		return nil, status, ErrBreakLimit
+	}
+
+	saved, err := s.recordBreak(ctx, record, "break_start", status)
+	return saved, status, err
+}
+
+// EndBreak records the end of the user's current break, returning it with the user's status of today
+func (s *Service) EndBreak(ctx context.Context, record *models.AttendanceRecord) (*models.AttendanceRecord, *models.AttendanceStatus, error) {
+	status, err := s.breakStatus(ctx, record.UserID)
+	if err != nil {
+		return nil, status, err
+	}
+	if !status.OnBreak() {
+		return nil, status, ErrNotOnBreak
+	}
+
+	saved, err := s.recordBreak(ctx, record, "break_end", status)
+	return saved, status, err
+}
+
+// breakStatus returns the user's attendance status of today, failing unless they may start or
+// end a break
+func (s *Service) breakStatus(ctx context.Context, userID int64) (*models.AttendanceStatus, error) {
+	if s.maxBreaks <= 0 {
+		return nil, ErrBreaksDisabled
+	}
+
+	now, err := s.UserNow(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status, err := s.repo.GetUserAttendanceStatus(ctx, userID, now.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance status: %w", err)
+	}
+	switch {
+	case !status.HasCheckedIn:
+		return status, ErrBreakNotCheckedIn
+	case status.HasCheckedOut:
+		return status, ErrBreakCheckedOut
+	}
+	return status, nil
+}
+
+// recordBreak saves a break start or end on the day of the check-in, working from home like the
+// check-in, and adds it to status
+func (s *Service) recordBreak(ctx context.Context, record *models.AttendanceRecord, breakType string, status *models.AttendanceStatus) (*models.AttendanceRecord, error) {
+	record.Timestamp = utils.Now()
+	record.Type = breakType
+	record.Date = status.CheckInRecord.Date
+	record.Source = models.SourceSelf
+	record.Remote = status.CheckInRecord.Remote
+
+	saved, err := s.repo.InsertAttendance(ctx, record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save break: %w", err)
+	}
+
+	status.Breaks = append(status.Breaks, saved)
+	s.reports.invalidate(record.Date)
+	return saved, nil
+}
@@ -0,0 +1,83 @@
+package attendance
+
+import (
+	"attendance-bot/pkg/models"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// BypassCodeTTL is how long an issued bypass code may be used
+const BypassCodeTTL = 15 * time.Minute
+
+// IssueBypassCode creates a single-use code letting userID record attendance without their
+// authenticator. The code has the same format as an OTP so it is submitted the same way;
+// only its HMAC, keyed with the secrets encryption key, is stored.
+func (s *Service) IssueBypassCode(ctx context.Context, userID, issuedBy int64) (string, time.Time, error) {
+	if s.cipher == nil {
+		return "", time.Time{}, ErrEncryptionKeyMissing
+	}
+
+	code, err := randomDigits(s.totp.Digits())
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate bypass code: %w", err)
+	}
+
+	now := time.Now()
+	saved, err := s.repo.InsertBypassCode(ctx, &models.BypassCode{
+		UserID:    userID,
+		CodeHash:  s.hashBypassCode(userID, code),
+		IssuedBy:  issuedBy,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(BypassCodeTTL),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return code, saved.ExpiresAt, nil
+}
+
+// findBypassCode returns the user's bypass code matching code, or nil if code is not one. Without
+// an encryption key no code can have been issued.
+func (s *Service) findBypassCode(ctx context.Context, userID int64, code string) (*models.BypassCode, error) {
+	if s.cipher == nil {
+		return nil, nil
+	}
+	return s.repo.FindBypassCode(ctx, userID, s.hashBypassCode(userID, code))
+}
+
+// hashBypassCode hashes a code together with the user it was issued to, so equal codes issued to
+// different users have different hashes. The hash is keyed: the six-digit codes could otherwise be
+// recovered from a copy of the database by trying all of them.
+func (s *Service) hashBypassCode(userID int64, code string) string {
+	return s.cipher.MAC(fmt.Sprintf("%d:%s", userID, code))
+}
+
+// randomDigits returns a uniformly random string of n decimal digits
+func randomDigits(n int) (string, error) {
+	digits := make([]byte, n)
+	for i := range digits {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + d.Int64())
+	}
+	return string(digits), nil
+}
+
+// bypassRefusal returns why a bypass code can no longer be redeemed, or "" if it can. The reply
+// to the user is the message bypass.<reason>.
+func bypassRefusal(code *models.BypassCode, now time.Time) string {
+	switch {
+	case code.UsedAt != nil:
+		return "used"
+	case !now.Before(code.ExpiresAt):
+		return "expired"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,226 @@
+package attendance
+
+import (
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBypassRefusal(t *testing.T) {
+	now := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	used := now.Add(-time.Minute)
+
+	tests := []struct {
+		name string
+		code models.BypassCode
+		want string
+	}{
+		{"fresh", models.BypassCode{ExpiresAt: now.Add(BypassCodeTTL)}, ""},
+		{"last second", models.BypassCode{ExpiresAt: now.Add(time.Second)}, ""},
+		{"expiring now", models.BypassCode{ExpiresAt: now}, "expired"},
+		{"expired", models.BypassCode{ExpiresAt: now.Add(-time.Hour)}, "expired"},
+		{"used", models.BypassCode{ExpiresAt: now.Add(BypassCodeTTL), UsedAt: &used}, "used"},
+		{"used and expired", models.BypassCode{ExpiresAt: now.Add(-time.Hour), UsedAt: &used}, "used"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bypassRefusal(&tt.code, now); got != tt.want {
+				t.Errorf("bypassRefusal = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// newBypassTestService is newTestService with an encryption key, which bypass codes require
+func newBypassTestService(t *testing.T) (*Service, database.Repository) {
+	t.Helper()
+
+	service, repo := newTestService(t)
+	service.SetSecretCipher(newTestCipher(t))
+	return service, repo
+}
+
+func TestBypassCodeHash(t *testing.T) {
+	service, _ := newBypassTestService(t)
+	otherKey, _ := newBypassTestService(t)
+	unkeyed := sha256.Sum256([]byte("1:024680"))
+	hash := service.hashBypassCode(1, "024680")
+
+	tests := []struct {
+		name  string
+		other string
+		equal bool
+	}{
+		{"same user and code", service.hashBypassCode(1, "024680"), true},
+		{"another code", service.hashBypassCode(1, "024681"), false},
+		{"another user", service.hashBypassCode(2, "024680"), false},
+		{"another key", otherKey.hashBypassCode(1, "024680"), false},
+		{"unkeyed SHA-256", hex.EncodeToString(unkeyed[:]), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.other == hash; got != tt.equal {
+				t.Errorf("hash %s equal to %s = %v, want %v", tt.other, hash, got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestBypassCodeRequiresEncryptionKey(t *testing.T) {
+	service, _ := newTestService(t)
+	var log bytes.Buffer
+
+	if _, _, err := service.IssueBypassCode(context.Background(), 1, 99); !errors.Is(err, ErrEncryptionKeyMissing) {
+		t.Errorf("IssueBypassCode without a key = %v, want ErrEncryptionKeyMissing", err)
+	}
+	if result := markAttendance(t, service, &log, 1, "024680"); result.Success || result.Bypass {
+		t.Errorf("code submitted without a key = %+v, want it refused as an OTP", result)
+	}
+}
+
+// markAttendance submits code for userID, logging to log
+func markAttendance(t *testing.T, service *Service, log *bytes.Buffer, userID int64, code string) *AttendanceResult {
+	t.Helper()
+
+	ctx := logging.NewContext(context.Background(), slog.New(slog.NewTextHandler(log, nil)))
+	result, err := service.MarkAttendance(ctx, userID, "sari", "Sari", nil, code)
+	if err != nil {
+		t.Fatalf("MarkAttendance: %v", err)
+	}
+	return result
+}
+
+// TestBypassCodeRedeemedOnce checks in with an issued bypass code, then tries to check out with it
+func TestBypassCodeRedeemedOnce(t *testing.T) {
+	service, repo := newBypassTestService(t)
+	var log bytes.Buffer
+	const userID = 1
+
+	code, expires, err := service.IssueBypassCode(context.Background(), userID, 99)
+	if err != nil {
+		t.Fatalf("IssueBypassCode: %v", err)
+	}
+	if len(code) != 6 || time.Until(expires) > BypassCodeTTL || time.Until(expires) < BypassCodeTTL-time.Minute {
+		t.Fatalf("IssueBypassCode = %q until %v, want 6 digits for %v", code, expires, BypassCodeTTL)
+	}
+	stored, err := repo.FindBypassCode(context.Background(), userID, code)
+	if err != nil || stored != nil {
+		t.Errorf("FindBypassCode by the plain code = %+v (%v), want only the hash stored", stored, err)
+	}
+
+	result := markAttendance(t, service, &log, userID, code)
+	if !result.Success || !result.Bypass || !strings.Contains(result.Message, i18n.T(i18n.Default, "bypass.recorded")) {
+		t.Fatalf("first use = %+v, want a check-in recorded with the bypass code", result)
+	}
+	if result.Record.Source != models.SourceBypass {
+		t.Errorf("Source = %q, want %q", result.Record.Source, models.SourceBypass)
+	}
+	if !strings.Contains(log.String(), "audit=bypass_used") {
+		t.Errorf("log = %q, want the use audited", log.String())
+	}
+
+	log.Reset()
+	result = markAttendance(t, service, &log, userID, code)
+	if result.Success || result.Message != i18n.T(i18n.Default, "bypass.used") {
+		t.Errorf("reuse = %+v, want it refused as used", result)
+	}
+	if !strings.Contains(log.String(), "audit=bypass_rejected") || !strings.Contains(log.String(), "reason=used") {
+		t.Errorf("log = %q, want the reuse audited", log.String())
+	}
+
+	records, err := repo.ListAttendance(context.Background(), userID, "")
+	if err != nil {
+		t.Fatalf("ListAttendance: %v", err)
+	}
+	if len(records) != 1 || records[0].Type != "check_in" || records[0].Source != models.SourceBypass {
+		t.Errorf("records = %+v, want only the check-in", records)
+	}
+}
+
+func TestBypassCodeRefused(t *testing.T) {
+	t.Run("expired", func(t *testing.T) {
+		service, repo := newBypassTestService(t)
+		var log bytes.Buffer
+		issued := time.Now().Add(-BypassCodeTTL - time.Minute)
+		if _, err := repo.InsertBypassCode(context.Background(), &models.BypassCode{
+			UserID: 1, CodeHash: service.hashBypassCode(1, "024680"), IssuedBy: 99, IssuedAt: issued, ExpiresAt: issued.Add(BypassCodeTTL),
+		}); err != nil {
+			t.Fatalf("InsertBypassCode: %v", err)
+		}
+
+		result := markAttendance(t, service, &log, 1, "024680")
+		if result.Success || result.Message != i18n.T(i18n.Default, "bypass.expired") {
+			t.Errorf("expired code = %+v, want it refused as expired", result)
+		}
+		if !strings.Contains(log.String(), "reason=expired") {
+			t.Errorf("log = %q, want the refusal audited", log.String())
+		}
+		assertNoAttendance(t, service, 1)
+	})
+
+	t.Run("superseded", func(t *testing.T) {
+		service, _ := newBypassTestService(t)
+		var log bytes.Buffer
+		first, _, err := service.IssueBypassCode(context.Background(), 1, 99)
+		if err != nil {
+			t.Fatalf("IssueBypassCode: %v", err)
+		}
+		second, _, err := service.IssueBypassCode(context.Background(), 1, 99)
+		if err != nil {
+			t.Fatalf("IssueBypassCode: %v", err)
+		}
+		if first == second {
+			t.Skip("both codes are equal by chance")
+		}
+
+		result := markAttendance(t, service, &log, 1, first)
+		if result.Success || result.Message != i18n.T(i18n.Default, "bypass.expired") {
+			t.Errorf("superseded code = %+v, want it refused as expired", result)
+		}
+		assertNoAttendance(t, service, 1)
+
+		if result := markAttendance(t, service, &log, 1, second); !result.Success {
+			t.Errorf("new code = %+v, want it accepted", result)
+		}
+	})
+
+	t.Run("another user's", func(t *testing.T) {
+		service, _ := newBypassTestService(t)
+		var log bytes.Buffer
+		code, _, err := service.IssueBypassCode(context.Background(), 1, 99)
+		if err != nil {
+			t.Fatalf("IssueBypassCode: %v", err)
+		}
+
+		result := markAttendance(t, service, &log, 2, code)
+		if result.Success || result.Bypass {
+			t.Errorf("user 2 with user 1's code = %+v, want it refused", result)
+		}
+		assertNoAttendance(t, service, 2)
+	})
+}
+
+// assertNoAttendance fails if userID has any attendance recorded
+func assertNoAttendance(t *testing.T, service *Service, userID int64) {
+	t.Helper()
+
+	records, err := service.repo.ListAttendance(context.Background(), userID, "")
+	if err != nil {
+		t.Fatalf("ListAttendance: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("records = %+v, want none", records)
+	}
+}
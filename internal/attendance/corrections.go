@@ -0,0 +1,162 @@
+package attendance
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+// nameLookupDays is how far back a user's name is looked up when adding their first record of a day
+const nameLookupDays = 90
+
+// Correction is an admin's fix to one attendance record: the user's check-in or check-out of Date
+// is set to Clock, or deleted when Delete is set
+type Correction struct {
+	UserID  int64
+	Date    string // YYYY-MM-DD format
+	Type    string // "check_in" or "check_out"
+	Clock   utils.Clock
+	Delete  bool
+	ActorID int64
+	Reason  string
+}
+
+// FixAttendance applies a correction and records it in the audit log. A check-out set before the
+// check-in is taken to be on the next day, as after an overnight shift, and its overtime is
+// computed anew. It returns a refusal
+// message instead when the correction would leave the day inconsistent.
+func (s *Service) FixAttendance(ctx context.Context, correction Correction) (*models.AuditEntry, string, error) {
+	lang := i18n.FromContext(ctx)
+
+	day, err := time.ParseInLocation("2006-01-02", correction.Date, utils.Location)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrInvalidDateRange, correction.Date)
+	}
+
+	// Archived days are read-only, their records are no longer in the attendance table
+	archivedBefore, err := s.repo.GetArchivedBefore(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get archive boundary: %w", err)
+	}
+	if archivedBefore != "" && correction.Date < archivedBefore {
+		return nil, i18n.T(lang, "fix.archived", "Before", archivedBefore), nil
+	}
+
+	status, err := s.repo.GetUserAttendanceStatus(ctx, correction.UserID, correction.Date)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get attendance status: %w", err)
+	}
+	before, other := status.CheckInRecord, status.CheckOutRecord
+	if correction.Type == "check_out" {
+		before, other = status.CheckOutRecord, status.CheckInRecord
+	}
+
+	var after *models.AttendanceRecord
+	action := models.AuditAttendanceDeleted
+	if correction.Delete {
+		if before == nil {
+			return nil, i18n.T(lang, "fix.nothing_to_delete"), nil
+		}
+		if correction.Type == "check_in" && other != nil {
+			return nil, i18n.T(lang, "fix.delete_check_out_first"), nil
+		}
+	} else {
+		timestamp := correction.Clock.On(day)
+		switch {
+		case correction.Type == "check_out" && other == nil:
+			return nil, i18n.T(lang, "fix.check_in_first"), nil
+		case correction.Type == "check_out" && !timestamp.After(other.Timestamp):
+			timestamp = timestamp.AddDate(0, 0, 1)
+		case correction.Type == "check_in" && other != nil && !timestamp.Before(other.Timestamp):
+			return nil, i18n.T(lang, "fix.after_check_out", "Time", utils.FormatTime(other.Timestamp, "HH:mm")), nil
+		}
+		if timestamp.After(time.Now()) {
+			return nil, i18n.T(lang, "fix.future"), nil
+		}
+
+		if before != nil {
+			corrected := *before
+			after = &corrected
+			action = models.AuditAttendanceChanged
+		} else {
+			after, err = s.newCorrectionRecord(ctx, correction, other)
+			if err != nil {
+				return nil, "", err
+			}
+			if after == nil {
+				return nil, i18n.T(lang, "fix.unknown_user", "UserID", correction.UserID, "Days", nameLookupDays), nil
+			}
+			action = models.AuditAttendanceAdded
+		}
+		after.Timestamp = timestamp
+		after.Source = models.SourceAdmin
+		if after.Type == "check_out" {
+			after.OvertimeMinutes, err = s.overtimeMinutes(ctx, correction.UserID, other.Timestamp, timestamp)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	entry, err := s.repo.CorrectAttendance(ctx, before, after, &models.AuditEntry{
+		CreatedAt:    time.Now(),
+		ActorID:      correction.ActorID,
+		Action:       action,
+		TargetUserID: correction.UserID,
+		Details: fmt.Sprintf("%s %s: %s → %s", correction.Type, correction.Date,
+			correctionTime(before, correction.Date), correctionTime(after, correction.Date)),
+		Reason: correction.Reason,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to correct attendance: %w", err)
+	}
+
+	s.reports.invalidate(correction.Date)
+	return entry, "", nil
+}
+
+// newCorrectionRecord starts the record an admin adds for the user, named like the user's other
+// record of the day or their latest one. It returns nil if the user has no recent attendance.
+func (s *Service) newCorrectionRecord(ctx context.Context, correction Correction, other *models.AttendanceRecord) (*models.AttendanceRecord, error) {
+	named := other
+	if named == nil {
+		history, err := s.repo.GetUserAttendanceHistory(ctx, correction.UserID, nameLookupDays)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get attendance history: %w", err)
+		}
+		if len(history) == 0 {
+			return nil, nil
+		}
+		named = &history[0]
+	}
+
+	remote, err := s.worksRemotely(ctx, correction.UserID, correction.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AttendanceRecord{
+		UserID:    correction.UserID,
+		Username:  named.Username,
+		FirstName: named.FirstName,
+		LastName:  named.LastName,
+		Type:      correction.Type,
+		Date:      correction.Date,
+		Remote:    remote,
+	}, nil
+}
+
+// correctionTime renders the time of a record for the audit log: HH:MM, with the date when it is
+// not the attendance date, or "-" when there is no record
+func correctionTime(record *models.AttendanceRecord, date string) string {
+	if record == nil {
+		return "-"
+	}
+	if utils.FormatDate(record.Timestamp, "yyyy-MM-dd") != date {
+		return utils.FormatDate(record.Timestamp, "yyyy-MM-dd") + " " + utils.FormatTime(record.Timestamp, "HH:mm")
+	}
+	return utils.FormatTime(record.Timestamp, "HH:mm")
+}
@@ -0,0 +1,146 @@
+package attendance
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"testing"
+	"time"
+)
+
+// TestFixAttendance applies corrections over a user with a full day on 4 March 2024 and another
+// with only a check-in on 5 March, with attendance archived before 2024: each correction is either
+// recorded in the audit log or refused with a message
+func TestFixAttendance(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, utils.Location)
+	}
+	record := func(userID int64, name string, timestamp time.Time, recordType string) models.AttendanceRecord {
+		return models.AttendanceRecord{
+			UserID: userID, FirstName: name, Timestamp: timestamp, Type: recordType, Date: timestamp.Format("2006-01-02"),
+		}
+	}
+	seed := []models.AttendanceRecord{
+		record(1, "Sari", at(4, 8, 0), "check_in"),
+		record(1, "Sari", at(4, 17, 0), "check_out"),
+		record(2, "Budi", at(5, 20, 0), "check_in"),
+	}
+
+	tests := []struct {
+		name       string
+		correction Correction
+		refusal    string // Expected refusal, empty if the correction is applied
+		action     string
+		details    string
+		want       time.Time // Corrected timestamp, zero when the record is deleted
+	}{
+		{
+			name:       "change check-in",
+			correction: Correction{UserID: 1, Date: "2024-03-04", Type: "check_in", Clock: utils.Clock{Hour: 7, Minute: 30}},
+			action:     models.AuditAttendanceChanged,
+			details:    "check_in 2024-03-04: 08:00 → 07:30",
+			want:       at(4, 7, 30),
+		},
+		{
+			name:       "delete check-out",
+			correction: Correction{UserID: 1, Date: "2024-03-04", Type: "check_out", Delete: true},
+			action:     models.AuditAttendanceDeleted,
+			details:    "check_out 2024-03-04: 17:00 → -",
+		},
+		{
+			name:       "add overnight check-out",
+			correction: Correction{UserID: 2, Date: "2024-03-05", Type: "check_out", Clock: utils.Clock{Hour: 4}},
+			action:     models.AuditAttendanceAdded,
+			details:    "check_out 2024-03-05: - → 2024-03-06 04:00",
+			want:       at(6, 4, 0),
+		},
+		{
+			name:       "check-in after check-out",
+			correction: Correction{UserID: 1, Date: "2024-03-04", Type: "check_in", Clock: utils.Clock{Hour: 18}},
+			refusal:    i18n.T(i18n.Default, "fix.after_check_out", "Time", "17:00"),
+		},
+		{
+			name:       "delete check-in before check-out",
+			correction: Correction{UserID: 1, Date: "2024-03-04", Type: "check_in", Delete: true},
+			refusal:    i18n.T(i18n.Default, "fix.delete_check_out_first"),
+		},
+		{
+			name:       "delete missing record",
+			correction: Correction{UserID: 2, Date: "2024-03-05", Type: "check_out", Delete: true},
+			refusal:    i18n.T(i18n.Default, "fix.nothing_to_delete"),
+		},
+		{
+			name:       "check-out without check-in",
+			correction: Correction{UserID: 1, Date: "2024-03-06", Type: "check_out", Clock: utils.Clock{Hour: 17}},
+			refusal:    i18n.T(i18n.Default, "fix.check_in_first"),
+		},
+		{
+			name:       "unknown user",
+			correction: Correction{UserID: 3, Date: "2024-03-04", Type: "check_in", Clock: utils.Clock{Hour: 8}},
+			refusal:    i18n.T(i18n.Default, "fix.unknown_user", "UserID", 3, "Days", nameLookupDays),
+		},
+		{
+			name:       "future",
+			correction: Correction{UserID: 1, Date: "2099-01-01", Type: "check_in", Clock: utils.Clock{Hour: 8}},
+			refusal:    i18n.T(i18n.Default, "fix.future"),
+		},
+		{
+			name:       "archived",
+			correction: Correction{UserID: 1, Date: "2023-12-29", Type: "check_in", Clock: utils.Clock{Hour: 8}},
+			refusal:    i18n.T(i18n.Default, "fix.archived", "Before", "2024-01-01"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService(t)
+			ctx := context.Background()
+			if _, _, err := repo.InsertAttendanceBatch(ctx, seed); err != nil {
+				t.Fatalf("InsertAttendanceBatch: %v", err)
+			}
+			if err := repo.SetArchivedBefore(ctx, "2024-01-01"); err != nil {
+				t.Fatalf("SetArchivedBefore: %v", err)
+			}
+
+			tt.correction.ActorID = 900
+			entry, refusal, err := service.FixAttendance(ctx, tt.correction)
+			if err != nil {
+				t.Fatalf("FixAttendance: %v", err)
+			}
+			if refusal != tt.refusal {
+				t.Errorf("refusal = %q, want %q", refusal, tt.refusal)
+			}
+			if tt.refusal != "" {
+				if entry != nil {
+					t.Errorf("refused correction recorded %+v", *entry)
+				}
+				return
+			}
+			if entry == nil {
+				t.Fatal("FixAttendance returned no audit entry")
+			}
+			if entry.Action != tt.action || entry.Details != tt.details || entry.ActorID != 900 || entry.TargetUserID != tt.correction.UserID {
+				t.Errorf("audit entry = %+v, want %s %q", *entry, tt.action, tt.details)
+			}
+
+			status, err := repo.GetUserAttendanceStatus(ctx, tt.correction.UserID, tt.correction.Date)
+			if err != nil {
+				t.Fatalf("GetUserAttendanceStatus: %v", err)
+			}
+			corrected := status.CheckInRecord
+			if tt.correction.Type == "check_out" {
+				corrected = status.CheckOutRecord
+			}
+			switch {
+			case tt.want.IsZero() && corrected != nil:
+				t.Errorf("%s at %v, want it deleted", tt.correction.Type, corrected.Timestamp)
+			case tt.want.IsZero():
+			case corrected == nil:
+				t.Errorf("%s missing, want it at %v", tt.correction.Type, tt.want)
+			case !corrected.Timestamp.Equal(tt.want) || corrected.Source != models.SourceAdmin:
+				t.Errorf("%s at %v from %q, want %v from %q", tt.correction.Type, corrected.Timestamp, corrected.Source, tt.want, models.SourceAdmin)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package attendance
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEncryptionKeyMissing indicates per-user secrets exist or are needed but no encryption key is configured
+var ErrEncryptionKeyMissing = errors.New("secrets encryption key is not configured")
+
+// SecretCipher encrypts per-user secrets at rest using AES-256-GCM, and keys the hashes of
+// stored bypass codes
+type SecretCipher struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+// ParseEncryptionKey decodes a base64-encoded 32-byte key
+func ParseEncryptionKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encryption key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// NewSecretCipher creates a cipher from a 32-byte key
+func NewSecretCipher(key []byte) (*SecretCipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	// The MAC key is derived from the encryption key rather than being the key itself
+	derive := hmac.New(sha256.New, key)
+	derive.Write([]byte("attendance-bot mac key"))
+
+	return &SecretCipher{aead: aead, macKey: derive.Sum(nil)}, nil
+}
+
+// MAC returns the hex-encoded HMAC-SHA256 of message, so values such as bypass codes can be
+// stored without being guessable from the database alone
+func (c *SecretCipher) MAC(message string) string {
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Encrypt seals the plaintext and returns the ciphertext with its random nonce
+func (c *SecretCipher) Encrypt(plaintext string) (ciphertext, nonce []byte, err error) {
+	nonce = make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	ciphertext = c.aead.Seal(nil, nonce, []byte(plaintext), nil)
+	return ciphertext, nonce, nil
+}
+
+// Decrypt opens the ciphertext, failing if it was sealed with a different key or tampered with
+func (c *SecretCipher) Decrypt(ciphertext, nonce []byte) (string, error) {
+	if len(nonce) != c.aead.NonceSize() {
+		return "", fmt.Errorf("invalid nonce length %d", len(nonce))
+	}
+
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,118 @@
+package attendance
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSecretCipherRoundTrip(t *testing.T) {
+	cipher := newTestCipher(t)
+
+	for _, plaintext := range []string{testSecret, "", "ünïcode secret"} {
+		ciphertext, nonce, err := cipher.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("Encrypt(%q): %v", plaintext, err)
+		}
+		if plaintext != "" && bytes.Contains(ciphertext, []byte(plaintext)) {
+			t.Errorf("ciphertext of %q contains the plaintext", plaintext)
+		}
+
+		decrypted, err := cipher.Decrypt(ciphertext, nonce)
+		if err != nil || decrypted != plaintext {
+			t.Errorf("Decrypt = %q, %v; want %q", decrypted, err, plaintext)
+		}
+	}
+
+	// The same plaintext gets a fresh nonce each time
+	first, firstNonce, _ := cipher.Encrypt(testSecret)
+	second, secondNonce, _ := cipher.Encrypt(testSecret)
+	if bytes.Equal(firstNonce, secondNonce) || bytes.Equal(first, second) {
+		t.Error("encrypting the same secret twice gave the same nonce or ciphertext")
+	}
+}
+
+func TestSecretCipherRejectsWrongKeyAndTampering(t *testing.T) {
+	cipher := newTestCipher(t)
+	ciphertext, nonce, err := cipher.Encrypt(testSecret)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	if _, err := newTestCipher(t).Decrypt(ciphertext, nonce); err == nil {
+		t.Error("Decrypt with another key succeeded")
+	}
+
+	tampered := bytes.Clone(ciphertext)
+	tampered[0] ^= 1
+	if _, err := cipher.Decrypt(tampered, nonce); err == nil {
+		t.Error("Decrypt of a tampered ciphertext succeeded")
+	}
+
+	if _, err := cipher.Decrypt(ciphertext, nonce[:4]); err == nil {
+		t.Error("Decrypt with a short nonce succeeded")
+	}
+}
+
+func TestSecretCipherMAC(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	cipher, err := NewSecretCipher(key)
+	if err != nil {
+		t.Fatalf("NewSecretCipher: %v", err)
+	}
+	same, _ := NewSecretCipher(bytes.Clone(key))
+
+	mac := cipher.MAC("1:024680")
+	if len(mac) != 64 {
+		t.Errorf("MAC = %q, want 64 hex digits", mac)
+	}
+	if got := same.MAC("1:024680"); got != mac {
+		t.Errorf("MAC with the same key = %q, want %q", got, mac)
+	}
+	if got := newTestCipher(t).MAC("1:024680"); got == mac {
+		t.Error("MAC with another key is equal")
+	}
+}
+
+func TestParseEncryptionKey(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+
+	parsed, err := ParseEncryptionKey(" " + base64.StdEncoding.EncodeToString(key) + "\n")
+	if err != nil || !bytes.Equal(parsed, key) {
+		t.Errorf("ParseEncryptionKey = %x, %v; want the 32 byte key", parsed, err)
+	}
+
+	for _, encoded := range []string{"not base64!", base64.StdEncoding.EncodeToString(key[:16])} {
+		if _, err := ParseEncryptionKey(encoded); err == nil {
+			t.Errorf("ParseEncryptionKey(%q) accepted an invalid key", encoded)
+		}
+	}
+	if _, err := NewSecretCipher(key[:16]); err == nil {
+		t.Error("NewSecretCipher accepted a 16 byte key")
+	}
+}
+
+func TestEnrolledSecretStoredEncrypted(t *testing.T) {
+	service, repo := newTestService(t)
+	ctx := context.Background()
+	const userID = 1001
+
+	if _, err := service.EnrollHOTP(ctx, userID); !errors.Is(err, ErrEncryptionKeyMissing) {
+		t.Errorf("EnrollHOTP without a key = %v, want ErrEncryptionKeyMissing", err)
+	}
+
+	enrollment := enrollTestHOTP(t, service, userID)
+	stored, err := repo.GetUserSecret(ctx, userID)
+	if err != nil || stored == nil {
+		t.Fatalf("GetUserSecret = %+v, %v", stored, err)
+	}
+	if strings.Contains(string(stored.Ciphertext), enrollment.Secret) {
+		t.Error("the per-user secret is stored in plaintext")
+	}
+	if secret, err := service.cipher.Decrypt(stored.Ciphertext, stored.Nonce); err != nil || secret != enrollment.Secret {
+		t.Errorf("stored secret decrypts to %q, %v; want the enrolled secret", secret, err)
+	}
+}
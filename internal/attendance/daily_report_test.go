@@ -0,0 +1,38 @@
+package attendance
+
+import (
+	"attendance-bot/pkg/models"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDailyReportEarlyMorning reports a day whose first check-in, at 06:30 WIB, falls on the
+// previous day in UTC: the header names the report date and lateness is judged in WIB
+func TestDailyReportEarlyMorning(t *testing.T) {
+	service, repo := newTestService(t)
+	ctx := context.Background()
+
+	if _, _, err := repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{
+		{UserID: 1, FirstName: "Sari", Timestamp: time.Date(2024, 3, 3, 23, 30, 0, 0, time.UTC), Type: "check_in", Date: "2024-03-04"},
+		{UserID: 1, FirstName: "Sari", Timestamp: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC), Type: "check_out", Date: "2024-03-04"},
+		{UserID: 2, FirstName: "Budi", Timestamp: time.Date(2024, 3, 4, 2, 15, 0, 0, time.UTC), Type: "check_in", Date: "2024-03-04"},
+	}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+
+	report, err := service.GenerateAttendanceReportFor(ctx, "2024-03-04", "id")
+	if err != nil {
+		t.Fatalf("GenerateAttendanceReportFor: %v", err)
+	}
+
+	for _, want := range []string{"Senin, 04 Maret 2024", "06:30 ✅", "17:00", "09:15 ⚠️"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not contain %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "03 Maret 2024") {
+		t.Errorf("report names the UTC date of the early check-in:\n%s", report)
+	}
+}
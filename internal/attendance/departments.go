@@ -0,0 +1,127 @@
+package attendance
+
+import (
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownDepartment is returned for a department nobody in the employee directory belongs to
+var ErrUnknownDepartment = errors.New("unknown department")
+
+// GetDepartments returns the departments of the employee directory in name order
+func (s *Service) GetDepartments(ctx context.Context) ([]string, error) {
+	employees, err := s.repo.GetRegisteredEmployees(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registered employees: %w", err)
+	}
+
+	var departments []string
+	seen := make(map[string]bool)
+	for _, employee := range employees {
+		if employee.Department != "" && !seen[employee.Department] {
+			seen[employee.Department] = true
+			departments = append(departments, employee.Department)
+		}
+	}
+	sort.Strings(departments)
+	return departments, nil
+}
+
+// ResolveDepartment returns the department of the employee directory named name, ignoring case
+// and spacing, so "/report human  resources" finds "Human Resources"
+func (s *Service) ResolveDepartment(ctx context.Context, name string) (string, error) {
+	departments, err := s.GetDepartments(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	key := strings.ToLower(strings.Join(strings.Fields(name), " "))
+	for _, department := range departments {
+		if strings.ToLower(department) == key {
+			return department, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownDepartment, name)
+}
+
+// departmentsByUser maps every registered user to their department; unregistered users have none
+func (s *Service) departmentsByUser(ctx context.Context) (map[int64]string, error) {
+	employees, err := s.employeesByUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	departments := make(map[int64]string, len(employees))
+	for userID, employee := range employees {
+		departments[userID] = employee.Department
+	}
+	return departments, nil
+}
+
+// employeesByUser maps every registered user to their directory entry
+func (s *Service) employeesByUser(ctx context.Context) (map[int64]models.RegisteredEmployee, error) {
+	employees, err := s.repo.GetRegisteredEmployees(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registered employees: %w", err)
+	}
+
+	byUser := make(map[int64]models.RegisteredEmployee, len(employees))
+	for _, employee := range employees {
+		byUser[employee.UserID] = employee
+	}
+	return byUser, nil
+}
+
+// departmentCount counts the users of one department in a daily report
+type departmentCount struct {
+	present, late, leave int
+}
+
+// departmentCounts counts report users per department, "" being users without one
+type departmentCounts struct {
+	counts map[string]*departmentCount
+}
+
+// newDepartmentCounts creates empty department counts
+func newDepartmentCounts() *departmentCounts {
+	return &departmentCounts{counts: make(map[string]*departmentCount)}
+}
+
+// of returns the counts of a department, adding it when it is new
+func (d *departmentCounts) of(department string) *departmentCount {
+	counts, ok := d.counts[department]
+	if !ok {
+		counts = &departmentCount{}
+		d.counts[department] = counts
+	}
+	return counts
+}
+
+// named reports whether any counted user belongs to a department
+func (d *departmentCounts) named() bool {
+	for department := range d.counts {
+		if department != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// names returns the counted departments in name order, users without one last
+func (d *departmentCounts) names() []string {
+	names := make([]string, 0, len(d.counts))
+	for department := range d.counts {
+		names = append(names, department)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "" || names[j] == "" {
+			return names[j] == "" && names[i] != ""
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
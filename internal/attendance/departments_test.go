@@ -0,0 +1,145 @@
+package attendance
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// registerTestEmployees puts user 1 in Human Resources, user 2 in IT and user 3 in no department
+func registerTestEmployees(t *testing.T, service *Service) {
+	t.Helper()
+
+	for userID, department := range map[int64]string{1: "Human Resources", 2: "IT", 3: ""} {
+		employee := &models.RegisteredEmployee{UserID: userID, EmployeeID: fmt.Sprintf("EMP-%d", userID), Department: department}
+		if _, err := service.RegisterEmployee(context.Background(), employee, 900); err != nil {
+			t.Fatalf("RegisterEmployee: %v", err)
+		}
+	}
+}
+
+func TestResolveDepartment(t *testing.T) {
+	service, _ := newTestService(t)
+	registerTestEmployees(t, service)
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr error
+	}{
+		{name: "Human Resources", want: "Human Resources"},
+		{name: "human  resources", want: "Human Resources"},
+		{name: " it ", want: "IT"},
+		{name: "Human", wantErr: ErrUnknownDepartment},
+		{name: "", wantErr: ErrUnknownDepartment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.ResolveDepartment(context.Background(), tt.name)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("ResolveDepartment(%q) = %q, %v; want %q, %v", tt.name, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestGenerateDepartmentReport reports 4 March 2024, on which user 1 came early, user 2 came late
+// and user 3 came early: a department's report lists its users only, and the report of everyone
+// sums up each department
+func TestGenerateDepartmentReport(t *testing.T) {
+	checkIn := func(userID int64, hour int) models.AttendanceRecord {
+		timestamp := time.Date(2024, 3, 4, hour, 0, 0, 0, utils.Location)
+		return models.AttendanceRecord{
+			UserID: userID, FirstName: fmt.Sprintf("User%d", userID), Timestamp: timestamp, Type: "check_in", Date: "2024-03-04",
+		}
+	}
+
+	tests := []struct {
+		name       string
+		department string
+		want       []string
+		notWant    []string
+	}{
+		{
+			name: "everyone",
+			want: []string{"User1", "User2", "User3",
+				i18n.T("en", "report.department_heading"),
+				i18n.T("en", "report.department_entry", "Department", "Human Resources", "Present", 1, "Late", 0, "Leave", 0),
+				i18n.T("en", "report.department_entry", "Department", "IT", "Present", 1, "Late", 1, "Leave", 0),
+				i18n.T("en", "report.department_entry", "Department", i18n.T("en", "employee.no_department"), "Present", 1, "Late", 0, "Leave", 0)},
+		},
+		{
+			name:       "one department",
+			department: "IT",
+			want:       []string{"User2", i18n.T("en", "report.department", "Department", "IT")},
+			notWant:    []string{"User1", "User3", i18n.T("en", "report.department_heading")},
+		},
+		{
+			name:       "department without attendance",
+			department: "Sales",
+			want:       []string{i18n.T("en", "report.empty_department", "Department", "Sales", "Date", "2024-03-04")},
+			notWant:    []string{"User1", "User2", "User3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService(t)
+			ctx := context.Background()
+			registerTestEmployees(t, service)
+			if _, _, err := repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{checkIn(1, 7), checkIn(2, 11), checkIn(3, 7)}); err != nil {
+				t.Fatalf("InsertAttendanceBatch: %v", err)
+			}
+
+			report, err := service.GenerateDepartmentReport(ctx, "2024-03-04", tt.department, "en")
+			if err != nil {
+				t.Fatalf("GenerateDepartmentReport: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(report, want) {
+					t.Errorf("report =\n%s\nwant it to contain %q", report, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(report, notWant) {
+					t.Errorf("report =\n%s\nwant it without %q", report, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestDepartmentCountsNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		departments []string
+		want        []string
+		named       bool
+	}{
+		{"none", nil, []string{}, false},
+		{"without department only", []string{""}, []string{""}, false},
+		{"in name order, without department last", []string{"IT", "", "Finance", "IT"}, []string{"Finance", "IT", ""}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := newDepartmentCounts()
+			for _, department := range tt.departments {
+				counts.of(department).present++
+			}
+			if got := counts.names(); fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+				t.Errorf("names = %q, want %q", got, tt.want)
+			}
+			if counts.named() != tt.named {
+				t.Errorf("named = %v, want %v", counts.named(), tt.named)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package attendance
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ErrEmployeeIDTaken is returned when registering a user under an employee ID another user has
+var ErrEmployeeIDTaken = errors.New("employee id already registered to another user")
+
+// ErrInvalidEmployeeID is returned for an employee ID that is empty, too long or has odd characters
+var ErrInvalidEmployeeID = errors.New("invalid employee id")
+
+// ErrInvalidDepartment is returned for a department name that is too long or has odd characters
+var ErrInvalidDepartment = errors.New("invalid department")
+
+// employeeIDPattern matches the employee IDs accepted in the directory, e.g. EMP-0042 or 19870321.2
+var employeeIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]{0,31}$`)
+
+// departmentPattern matches the department names accepted in the directory. They appear in
+// Markdown reports, so Markdown syntax is not allowed.
+var departmentPattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N} &.,'/()-]*$`)
+
+// maxDepartmentLength bounds department names, in bytes, keeping "/report <department>" buttons
+// within Telegram's 64-byte callback data
+const maxDepartmentLength = 40
+
+// SetRequireRegistration lets only users in the employee directory mark attendance when required
+func (s *Service) SetRequireRegistration(required bool) {
+	s.requireRegistration = required
+}
+
+// RequiresRegistration reports whether only registered users may mark attendance
+func (s *Service) RequiresRegistration() bool {
+	return s.requireRegistration
+}
+
+// checkRegistration returns the refusal of an attempt by a user missing from the employee
+// directory when registration is required, or nil if the user may mark attendance
+func (s *Service) checkRegistration(ctx context.Context, userID int64) (*AttendanceResult, error) {
+	if !s.requireRegistration {
+		return nil, nil
+	}
+
+	employee, err := s.repo.GetRegisteredEmployee(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registered employee: %w", err)
+	}
+	if employee != nil {
+		return nil, nil
+	}
+
+	logging.FromContext(ctx).Info("Attendance refused, user not registered")
+	return &AttendanceResult{
+		Success: false,
+		Message: i18n.T(i18n.FromContext(ctx), "employee.not_registered", "UserID", userID),
+	}, nil
+}
+
+// RegisterEmployee adds a user to the employee directory, or updates their employee ID and
+// department, on behalf of actorID. It returns false if the user was already registered. The
+// department is changed to the spelling the directory already has for it.
+func (s *Service) RegisterEmployee(ctx context.Context, employee *models.RegisteredEmployee, actorID int64) (bool, error) {
+	if !employeeIDPattern.MatchString(employee.EmployeeID) {
+		return false, fmt.Errorf("%w: %q", ErrInvalidEmployeeID, employee.EmployeeID)
+	}
+	if employee.Department != "" && !departmentPattern.MatchString(employee.Department) {
+		return false, fmt.Errorf("%w: %q", ErrInvalidDepartment, employee.Department)
+	}
+	if len(employee.Department) > maxDepartmentLength {
+		return false, fmt.Errorf("%w: longer than %d bytes", ErrInvalidDepartment, maxDepartmentLength)
+	}
+
+	// A department is spelled as it already is in the directory, so reports group it as one
+	if employee.Department != "" {
+		department, err := s.ResolveDepartment(ctx, employee.Department)
+		switch {
+		case err == nil:
+			employee.Department = department
+		case !errors.Is(err, ErrUnknownDepartment):
+			return false, err
+		}
+	}
+
+	holder, err := s.repo.FindRegisteredEmployee(ctx, employee.EmployeeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to find registered employee: %w", err)
+	}
+	if holder != nil && holder.UserID != employee.UserID {
+		return false, fmt.Errorf("%w: %s belongs to user %d", ErrEmployeeIDTaken, employee.EmployeeID, holder.UserID)
+	}
+
+	existing, err := s.repo.GetRegisteredEmployee(ctx, employee.UserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get registered employee: %w", err)
+	}
+
+	employee.RegisteredBy = actorID
+	employee.RegisteredAt = time.Now()
+	if err := s.repo.SaveRegisteredEmployee(ctx, employee); err != nil {
+		return false, fmt.Errorf("failed to save registered employee: %w", err)
+	}
+
+	details := describeEmployee(employee)
+	if existing != nil {
+		details = describeEmployee(existing) + " → " + details
+	}
+	s.Audit(ctx, models.AuditEntry{
+		ActorID:      actorID,
+		Action:       models.AuditEmployeeRegistered,
+		TargetUserID: employee.UserID,
+		Details:      details,
+	})
+	return existing == nil, nil
+}
+
+// describeEmployee returns the employee ID and, if any, the department for the audit log
+func describeEmployee(employee *models.RegisteredEmployee) string {
+	if employee.Department == "" {
+		return employee.EmployeeID
+	}
+	return employee.EmployeeID + ", " + employee.Department
+}
+
+// UnregisterEmployee removes a user from the employee directory on behalf of actorID, returning
+// false if they were not registered
+func (s *Service) UnregisterEmployee(ctx context.Context, userID, actorID int64) (bool, error) {
+	employee, err := s.repo.GetRegisteredEmployee(ctx, userID)
+	if err != nil || employee == nil {
+		return false, err
+	}
+	removed, err := s.repo.DeleteRegisteredEmployee(ctx, userID)
+	if err != nil || !removed {
+		return false, err
+	}
+
+	s.Audit(ctx, models.AuditEntry{
+		ActorID:      actorID,
+		Action:       models.AuditEmployeeUnregistered,
+		TargetUserID: userID,
+		Details:      describeEmployee(employee),
+	})
+	return true, nil
+}
+
+// GetRegisteredEmployee returns the user's directory entry, or nil if they are not registered
+func (s *Service) GetRegisteredEmployee(ctx context.Context, userID int64) (*models.RegisteredEmployee, error) {
+	return s.repo.GetRegisteredEmployee(ctx, userID)
+}
+
+// GetRegisteredEmployees returns the employee directory ordered by department and employee ID
+func (s *Service) GetRegisteredEmployees(ctx context.Context) ([]models.RegisteredEmployee, error) {
+	return s.repo.GetRegisteredEmployees(ctx)
+}
@@ -0,0 +1,138 @@
+package attendance
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestRegisterEmployee registers users in a directory that has user 1 as EMP-1 of Human Resources
+func TestRegisterEmployee(t *testing.T) {
+	tests := []struct {
+		name       string
+		employee   models.RegisteredEmployee
+		added      bool
+		department string // Department stored
+		details    string // Audit entry details
+		wantErr    error
+	}{
+		{name: "new user", employee: models.RegisteredEmployee{UserID: 2, EmployeeID: "EMP-2"},
+			added: true, details: "EMP-2"},
+		{name: "new user in a department", employee: models.RegisteredEmployee{UserID: 2, EmployeeID: "19870321.2", Department: "IT"},
+			added: true, department: "IT", details: "19870321.2, IT"},
+		{name: "existing department spelled differently", employee: models.RegisteredEmployee{UserID: 2, EmployeeID: "EMP-2", Department: "human  resources"},
+			added: true, department: "Human Resources", details: "EMP-2, Human Resources"},
+		{name: "change of department", employee: models.RegisteredEmployee{UserID: 1, EmployeeID: "EMP-1", Department: "IT"},
+			department: "IT", details: "EMP-1, Human Resources → EMP-1, IT"},
+		{name: "employee ID of another user", employee: models.RegisteredEmployee{UserID: 2, EmployeeID: "EMP-1"},
+			wantErr: ErrEmployeeIDTaken},
+		{name: "empty employee ID", employee: models.RegisteredEmployee{UserID: 2},
+			wantErr: ErrInvalidEmployeeID},
+		{name: "employee ID with spaces", employee: models.RegisteredEmployee{UserID: 2, EmployeeID: "EMP 2"},
+			wantErr: ErrInvalidEmployeeID},
+		{name: "department with Markdown", employee: models.RegisteredEmployee{UserID: 2, EmployeeID: "EMP-2", Department: "*IT*"},
+			wantErr: ErrInvalidDepartment},
+		{name: "department too long", employee: models.RegisteredEmployee{UserID: 2, EmployeeID: "EMP-2", Department: strings.Repeat("x", maxDepartmentLength+1)},
+			wantErr: ErrInvalidDepartment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			ctx := context.Background()
+			if _, err := service.RegisterEmployee(ctx, &models.RegisteredEmployee{UserID: 1, EmployeeID: "EMP-1", Department: "Human Resources"}, 900); err != nil {
+				t.Fatalf("RegisterEmployee: %v", err)
+			}
+
+			employee := tt.employee
+			added, err := service.RegisterEmployee(ctx, &employee, 900)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("RegisterEmployee error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RegisterEmployee: %v", err)
+			}
+			if added != tt.added {
+				t.Errorf("RegisterEmployee added = %v, want %v", added, tt.added)
+			}
+
+			stored, err := service.GetRegisteredEmployee(ctx, tt.employee.UserID)
+			if err != nil || stored == nil {
+				t.Fatalf("GetRegisteredEmployee = %+v, %v", stored, err)
+			}
+			if stored.EmployeeID != tt.employee.EmployeeID || stored.Department != tt.department || stored.RegisteredBy != 900 {
+				t.Errorf("stored %+v, want %s in %q registered by 900", *stored, tt.employee.EmployeeID, tt.department)
+			}
+
+			entries, err := service.GetAuditLog(ctx, tt.employee.UserID)
+			if err != nil {
+				t.Fatalf("GetAuditLog: %v", err)
+			}
+			if len(entries) == 0 || entries[0].Action != models.AuditEmployeeRegistered || entries[0].Details != tt.details {
+				t.Errorf("audit log = %+v, want %s %q first", entries, models.AuditEmployeeRegistered, tt.details)
+			}
+		})
+	}
+}
+
+func TestUnregisterEmployee(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	if _, err := service.RegisterEmployee(ctx, &models.RegisteredEmployee{UserID: 1, EmployeeID: "EMP-1"}, 900); err != nil {
+		t.Fatalf("RegisterEmployee: %v", err)
+	}
+
+	for _, want := range []bool{true, false} {
+		removed, err := service.UnregisterEmployee(ctx, 1, 900)
+		if err != nil || removed != want {
+			t.Errorf("UnregisterEmployee = %v, %v; want %v", removed, err, want)
+		}
+	}
+	if employee, err := service.GetRegisteredEmployee(ctx, 1); err != nil || employee != nil {
+		t.Errorf("GetRegisteredEmployee after unregistering = %+v, %v; want nil", employee, err)
+	}
+}
+
+// TestCheckRegistration refuses unregistered users only when registration is required
+func TestCheckRegistration(t *testing.T) {
+	tests := []struct {
+		name       string
+		required   bool
+		registered bool
+		refused    bool
+	}{
+		{"not required, unregistered", false, false, false},
+		{"required, registered", true, true, false},
+		{"required, unregistered", true, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			service.SetRequireRegistration(tt.required)
+			ctx := context.Background()
+			if tt.registered {
+				if _, err := service.RegisterEmployee(ctx, &models.RegisteredEmployee{UserID: 1, EmployeeID: "EMP-1"}, 900); err != nil {
+					t.Fatalf("RegisterEmployee: %v", err)
+				}
+			}
+
+			refusal, err := service.checkRegistration(ctx, 1)
+			if err != nil {
+				t.Fatalf("checkRegistration: %v", err)
+			}
+			if refused := refusal != nil; refused != tt.refused {
+				t.Fatalf("refused = %v, want %v", refused, tt.refused)
+			}
+			if want := i18n.T(i18n.Default, "employee.not_registered", "UserID", 1); tt.refused && refusal.Message != want {
+				t.Errorf("refusal = %q, want %q", refusal.Message, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package attendance
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Geofence modes, set with GEOFENCE_MODE
+const (
+	GeofenceOff     = "off"     // Locations are recorded when shared, nothing is checked
+	GeofenceFlag    = "flag"    // Check-ins without a location inside a geofence are marked in the daily report
+	GeofenceRequire = "require" // Check-ins without a location inside a geofence are refused
+)
+
+// LocationFreshness is how long a shared location applies to the user's attendance
+const LocationFreshness = 5 * time.Minute
+
+// MaxGeofenceRadius bounds the radius of a geofence, in meters
+const MaxGeofenceRadius = 10000
+
+// ErrInvalidGeofence is returned when saving a geofence with an invalid name, position or radius
+var ErrInvalidGeofence = errors.New("invalid geofence")
+
+// LocationCheck describes where a location lies relative to the geofences
+type LocationCheck struct {
+	Inside   string  // Geofence containing the location, empty when outside all of them
+	Nearest  string  // Geofence whose center is closest, empty when none are defined
+	Distance float64 // Meters from the center of the nearest geofence
+}
+
+// locate finds the geofence containing a location and the nearest one. When geofences overlap,
+// the one whose center is closest wins.
+func locate(geofences []models.Geofence, latitude, longitude float64) LocationCheck {
+	check := LocationCheck{Distance: math.Inf(1)}
+	for _, geofence := range geofences {
+		distance := utils.Distance(latitude, longitude, geofence.Latitude, geofence.Longitude)
+		if distance < check.Distance {
+			check.Nearest, check.Distance = geofence.Name, distance
+		}
+	}
+	for _, geofence := range geofences {
+		if geofence.Name == check.Nearest && check.Distance <= float64(geofence.Radius) {
+			check.Inside = geofence.Name
+		}
+	}
+	return check
+}
+
+// sharedLocation is a location a user shared for their next attendance
+type sharedLocation struct {
+	latitude, longitude float64
+	at                  time.Time
+}
+
+// locationStore keeps each user's last shared location. It is safe for concurrent use.
+type locationStore struct {
+	mu        sync.Mutex
+	locations map[int64]sharedLocation
+}
+
+// newLocationStore creates an empty location store
+func newLocationStore() *locationStore {
+	return &locationStore{locations: make(map[int64]sharedLocation)}
+}
+
+// put stores the user's location, replacing the previous one, and drops expired locations
+func (l *locationStore) put(userID int64, location sharedLocation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for id, previous := range l.locations {
+		if location.at.Sub(previous.at) > LocationFreshness {
+			delete(l.locations, id)
+		}
+	}
+	l.locations[userID] = location
+}
+
+// get returns the user's location if it was shared within LocationFreshness of now
+func (l *locationStore) get(userID int64, now time.Time) (sharedLocation, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	location, ok := l.locations[userID]
+	if !ok || now.Sub(location.at) > LocationFreshness {
+		return sharedLocation{}, false
+	}
+	return location, true
+}
+
+// SetGeofenceMode sets how check-ins are judged against the geofences, see Geofence*
+func (s *Service) SetGeofenceMode(mode string) {
+	s.geofenceMode = mode
+}
+
+// GeofenceMode returns how check-ins are judged against the geofences
+func (s *Service) GeofenceMode() string {
+	return s.geofenceMode
+}
+
+// ShareLocation keeps a location the user shared, at the given time, for their attendance within
+// LocationFreshness, and returns where it lies relative to the geofences
+func (s *Service) ShareLocation(ctx context.Context, userID int64, latitude, longitude float64, at time.Time) (LocationCheck, error) {
+	if !utils.IsValidCoordinate(latitude, longitude) {
+		return LocationCheck{}, fmt.Errorf("invalid location %f,%f", latitude, longitude)
+	}
+
+	geofences, err := s.repo.GetGeofences(ctx)
+	if err != nil {
+		return LocationCheck{}, fmt.Errorf("failed to get geofences: %w", err)
+	}
+
+	s.locations.put(userID, sharedLocation{latitude: latitude, longitude: longitude, at: at})
+	return locate(geofences, latitude, longitude), nil
+}
+
+// SaveGeofence creates or replaces a geofence
+func (s *Service) SaveGeofence(ctx context.Context, geofence *models.Geofence) error {
+	if !shiftNamePattern.MatchString(geofence.Name) {
+		return fmt.Errorf("%w: name %q", ErrInvalidGeofence, geofence.Name)
+	}
+	if !utils.IsValidCoordinate(geofence.Latitude, geofence.Longitude) {
+		return fmt.Errorf("%w: position %f,%f", ErrInvalidGeofence, geofence.Latitude, geofence.Longitude)
+	}
+	if geofence.Radius < 1 || geofence.Radius > MaxGeofenceRadius {
+		return fmt.Errorf("%w: radius of %d meters", ErrInvalidGeofence, geofence.Radius)
+	}
+
+	return s.repo.SaveGeofence(ctx, geofence)
+}
+
+// DeleteGeofence deletes a geofence, returning false if it did not exist
+func (s *Service) DeleteGeofence(ctx context.Context, name string) (bool, error) {
+	return s.repo.DeleteGeofence(ctx, name)
+}
+
+// GetGeofences returns the geofences ordered by name
+func (s *Service) GetGeofences(ctx context.Context) ([]models.Geofence, error) {
+	return s.repo.GetGeofences(ctx)
+}
+
+// applyLocation sets the location the user shared, if still fresh, on the record. With
+// GEOFENCE_MODE=require it returns a refusal message when the record would be a check-in and
+// the location is missing or outside all geofences; without geofences nothing is refused.
+func (s *Service) applyLocation(ctx context.Context, record *models.AttendanceRecord) (string, error) {
+	logger := logging.FromContext(ctx)
+	lang := i18n.FromContext(ctx)
+
+	shared, ok := s.locations.get(record.UserID, time.Now())
+	if !ok && s.geofenceMode != GeofenceRequire {
+		return "", nil
+	}
+
+	geofences, err := s.repo.GetGeofences(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get geofences: %w", err)
+	}
+
+	var check LocationCheck
+	if ok {
+		check = locate(geofences, shared.latitude, shared.longitude)
+		record.Latitude, record.Longitude = &shared.latitude, &shared.longitude
+		record.Geofence = check.Inside
+	}
+	if s.geofenceMode != GeofenceRequire || len(geofences) == 0 || check.Inside != "" {
+		return "", nil
+	}
+
+	// Only check-ins are held to the geofences
+	status, err := s.repo.GetUserAttendanceStatus(ctx, record.UserID, utils.GetTodayDate())
+	if err != nil {
+		return "", fmt.Errorf("failed to get attendance status: %w", err)
+	}
+	if status.HasCheckedIn {
+		return "", nil
+	}
+
+	// Users working from home are not expected at the office
+	remote, err := s.worksRemotely(ctx, record.UserID, utils.GetTodayDate())
+	if err != nil || remote {
+		return "", err
+	}
+
+	if !ok {
+		logger.Info("Check-in refused without location")
+		return i18n.T(lang, "geofence.location_required", "Minutes", int(LocationFreshness.Minutes())), nil
+	}
+	logger.Info("Check-in refused outside geofences", "nearest", check.Nearest, "distance_m", int(check.Distance))
+	return i18n.T(lang, "geofence.outside", "Geofence", check.Nearest, "Distance", int(check.Distance)), nil
+}
@@ -0,0 +1,102 @@
+package attendance
+
+import (
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLocate(t *testing.T) {
+	// 0.001 degrees of latitude are about 111 meters
+	geofences := []models.Geofence{
+		{Name: "office", Latitude: -6.1754, Longitude: 106.8272, Radius: 200},
+		{Name: "warehouse", Latitude: -6.1774, Longitude: 106.8272, Radius: 150},
+	}
+
+	tests := []struct {
+		name      string
+		geofences []models.Geofence
+		latitude  float64
+		inside    string
+		nearest   string
+		distance  float64 // Meters, within 5
+	}{
+		{"office center", geofences, -6.1754, "office", "office", 0},
+		{"inside the office radius", geofences, -6.17405, "office", "office", 150},
+		{"outside every radius", geofences, -6.1727, "", "office", 300},
+		{"overlap, nearest center wins", geofences, -6.1766, "warehouse", "warehouse", 89},
+		{"no geofences", nil, -6.1754, "", "", math.Inf(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := locate(tt.geofences, tt.latitude, 106.8272)
+			if check.Inside != tt.inside || check.Nearest != tt.nearest {
+				t.Errorf("locate = inside %q, nearest %q, want %q, %q", check.Inside, check.Nearest, tt.inside, tt.nearest)
+			}
+			if math.IsInf(tt.distance, 1) != math.IsInf(check.Distance, 1) || math.Abs(check.Distance-tt.distance) > 5 {
+				t.Errorf("distance = %.0fm, want about %.0fm", check.Distance, tt.distance)
+			}
+		})
+	}
+}
+
+func TestSaveGeofenceValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		geofence models.Geofence
+		want     error
+	}{
+		{"valid", models.Geofence{Name: "office", Latitude: -6.1754, Longitude: 106.8272, Radius: 200}, nil},
+		{"largest radius", models.Geofence{Name: "campus", Latitude: 0, Longitude: 0, Radius: MaxGeofenceRadius}, nil},
+		{"invalid name", models.Geofence{Name: "Head Office", Latitude: -6.1754, Longitude: 106.8272, Radius: 200}, ErrInvalidGeofence},
+		{"latitude off the globe", models.Geofence{Name: "office", Latitude: -91, Longitude: 106.8272, Radius: 200}, ErrInvalidGeofence},
+		{"longitude off the globe", models.Geofence{Name: "office", Latitude: -6.1754, Longitude: 181, Radius: 200}, ErrInvalidGeofence},
+		{"no radius", models.Geofence{Name: "office", Latitude: -6.1754, Longitude: 106.8272}, ErrInvalidGeofence},
+		{"radius too large", models.Geofence{Name: "office", Latitude: -6.1754, Longitude: 106.8272, Radius: MaxGeofenceRadius + 1}, ErrInvalidGeofence},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			if err := service.SaveGeofence(context.Background(), &tt.geofence); !errors.Is(err, tt.want) {
+				t.Errorf("SaveGeofence(%+v) = %v, want %v", tt.geofence, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocationStoreFreshness(t *testing.T) {
+	store := newLocationStore()
+	shared := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	store.put(1, sharedLocation{latitude: -6.1754, longitude: 106.8272, at: shared})
+
+	tests := []struct {
+		name   string
+		userID int64
+		now    time.Time
+		want   bool
+	}{
+		{"just shared", 1, shared, true},
+		{"last moment", 1, shared.Add(LocationFreshness), true},
+		{"expired", 1, shared.Add(LocationFreshness + time.Second), false},
+		{"another user", 2, shared, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := store.get(tt.userID, tt.now); ok != tt.want {
+				t.Errorf("get(%d) at +%v = %v, want %v", tt.userID, tt.now.Sub(shared), ok, tt.want)
+			}
+		})
+	}
+
+	// Storing a later location drops the ones that expired meanwhile
+	store.put(2, sharedLocation{at: shared.Add(LocationFreshness + time.Minute)})
+	if _, ok := store.locations[1]; ok {
+		t.Error("expired location of user 1 was kept")
+	}
+}
@@ -0,0 +1,60 @@
+package attendance
+
+import (
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+// RegisterGroup makes a group chat an office channel on behalf of userID, returning false if it
+// already was one
+func (s *Service) RegisterGroup(ctx context.Context, chatID int64, title string, userID int64) (bool, error) {
+	added, err := s.repo.AddOfficeGroup(ctx, &models.OfficeGroup{
+		ChatID:       chatID,
+		Title:        title,
+		RegisteredBy: userID,
+		RegisteredAt: time.Now(),
+	})
+	if err != nil || !added {
+		return false, err
+	}
+
+	s.Audit(ctx, models.AuditEntry{
+		ActorID: userID,
+		Action:  models.AuditGroupRegistered,
+		Details: fmt.Sprintf("%s (%d)", title, chatID),
+	})
+	return true, nil
+}
+
+// UnregisterGroup stops a group chat being an office channel, returning false if it was not one.
+// actorID is 0 when the bot itself gave up on the group, e.g. after being removed from it.
+func (s *Service) UnregisterGroup(ctx context.Context, chatID, actorID int64) (bool, error) {
+	group, err := s.repo.GetOfficeGroup(ctx, chatID)
+	if err != nil || group == nil {
+		return false, err
+	}
+	removed, err := s.repo.RemoveOfficeGroup(ctx, chatID)
+	if err != nil || !removed {
+		return false, err
+	}
+
+	s.Audit(ctx, models.AuditEntry{
+		ActorID: actorID,
+		Action:  models.AuditGroupUnregistered,
+		Details: fmt.Sprintf("%s (%d)", group.Title, chatID),
+	})
+	return true, nil
+}
+
+// IsOfficeGroup reports whether the chat was registered as an office channel
+func (s *Service) IsOfficeGroup(ctx context.Context, chatID int64) (bool, error) {
+	group, err := s.repo.GetOfficeGroup(ctx, chatID)
+	return group != nil, err
+}
+
+// GetOfficeGroups returns the office channels, oldest registration first
+func (s *Service) GetOfficeGroups(ctx context.Context) ([]models.OfficeGroup, error) {
+	return s.repo.GetOfficeGroups(ctx)
+}
@@ -0,0 +1,31 @@
+package attendance
+
+import (
+	"attendance-bot/internal/database"
+	"path/filepath"
+	"testing"
+)
+
+// testSecret is the base32 TOTP secret of the services created by newTestService
+const testSecret = "JBSWY3DPEHPK3PXP"
+
+// newTestService creates a service over a fresh, migrated SQLite database in a temporary directory
+func newTestService(t *testing.T) (*Service, database.Repository) {
+	t.Helper()
+
+	repo := newTestRepository(t)
+	return NewService(repo, NewTOTPService(testSecret)), repo
+}
+
+// newTestRepository opens a fresh, migrated SQLite database in a temporary directory
+func newTestRepository(t *testing.T) database.Repository {
+	t.Helper()
+
+	db, err := database.NewSQLiteDB(filepath.Join(t.TempDir(), "attendance.db"))
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return database.NewRepository(db)
+}
@@ -0,0 +1,83 @@
+package attendance
+
+import (
+	"attendance-bot/pkg/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// holidayFeedTimeout bounds a request for a year's holidays
+const holidayFeedTimeout = 15 * time.Second
+
+// HolidayFeed lists the national holidays of a year
+type HolidayFeed interface {
+	Holidays(ctx context.Context, year int) ([]models.Holiday, error)
+}
+
+// NagerHolidayFeed reads holidays from an API answering like Nager.Date's PublicHolidays endpoint:
+// a JSON array of objects with date, localName and name
+type NagerHolidayFeed struct {
+	url    string // With {year} in place of the year
+	client *http.Client
+}
+
+// NewNagerHolidayFeed creates a holiday feed reading the URL, with {year} replaced by the year
+func NewNagerHolidayFeed(url string) *NagerHolidayFeed {
+	return &NagerHolidayFeed{url: url, client: &http.Client{Timeout: holidayFeedTimeout}}
+}
+
+// Holidays returns the holidays of year, named in the local language where the feed has it
+func (f *NagerHolidayFeed) Holidays(ctx context.Context, year int) ([]models.Holiday, error) {
+	url := strings.ReplaceAll(f.url, "{year}", strconv.Itoa(year))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holidays: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("holiday feed answered %s", resp.Status)
+	}
+
+	var listed []struct {
+		Date      string `json:"date"`
+		LocalName string `json:"localName"`
+		Name      string `json:"name"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&listed); err != nil {
+		return nil, fmt.Errorf("failed to decode holidays: %w", err)
+	}
+
+	// A date listed twice, e.g. two observances on one day, keeps its first name
+	seen := make(map[string]bool, len(listed))
+	holidays := make([]models.Holiday, 0, len(listed))
+	for _, entry := range listed {
+		if _, err := time.Parse("2006-01-02", entry.Date); err != nil {
+			return nil, fmt.Errorf("invalid holiday date %q", entry.Date)
+		}
+		name := strings.Join(strings.Fields(entry.LocalName), " ")
+		if name == "" {
+			name = strings.Join(strings.Fields(entry.Name), " ")
+		}
+		if seen[entry.Date] || name == "" {
+			continue
+		}
+		seen[entry.Date] = true
+		if runes := []rune(name); len(runes) > maxHolidayName {
+			name = strings.TrimSpace(string(runes[:maxHolidayName]))
+		}
+		holidays = append(holidays, models.Holiday{Date: entry.Date, Name: name})
+	}
+	return holidays, nil
+}
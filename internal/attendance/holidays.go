@@ -0,0 +1,192 @@
+package attendance
+
+import (
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidHoliday is returned for a holiday with an invalid date or name
+var ErrInvalidHoliday = errors.New("invalid holiday")
+
+// ErrHolidayImportDisabled is returned when importing holidays without a holiday feed configured
+var ErrHolidayImportDisabled = errors.New("holiday import not configured")
+
+// holidayNamePattern matches the holiday names admins may give, e.g. "Hari Raya Idul Fitri 1446 H"
+var holidayNamePattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N} &.,'’/()-]*$`)
+
+// maxHolidayName bounds holiday names, in characters
+const maxHolidayName = 60
+
+// SetFixedHolidays sets the holidays of the HOLIDAYS setting. They count like those kept with
+// /holiday but cannot be removed with it.
+func (s *Service) SetFixedHolidays(dates map[string]bool) {
+	s.fixedHolidays = dates
+}
+
+// SetHolidayFeed sets where /holiday import gets national holidays from; nil disables importing
+func (s *Service) SetHolidayFeed(feed HolidayFeed) {
+	s.holidayFeed = feed
+}
+
+// AddHoliday adds a holiday on date (YYYY-MM-DD) on behalf of actorID, or renames the one on that
+// date. It returns false if a holiday was renamed.
+func (s *Service) AddHoliday(ctx context.Context, date, name string, actorID int64) (bool, error) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return false, fmt.Errorf("%w: date %q", ErrInvalidHoliday, date)
+	}
+	name = strings.Join(strings.Fields(strings.Trim(name, `"“” `)), " ")
+	if !holidayNamePattern.MatchString(name) || utf8.RuneCountInString(name) > maxHolidayName {
+		return false, fmt.Errorf("%w: name %q", ErrInvalidHoliday, name)
+	}
+
+	existing, err := s.repo.GetHolidays(ctx, date, date)
+	if err != nil {
+		return false, fmt.Errorf("failed to get holidays: %w", err)
+	}
+
+	holiday := &models.Holiday{
+		Date:    date,
+		Name:    name,
+		Source:  models.HolidaySourceAdmin,
+		AddedBy: actorID,
+		AddedAt: time.Now(),
+	}
+	if err := s.repo.SaveHoliday(ctx, holiday); err != nil {
+		return false, fmt.Errorf("failed to save holiday: %w", err)
+	}
+
+	details := date + " " + name
+	if len(existing) > 0 {
+		details = date + " " + existing[0].Name + " → " + name
+	}
+	s.Audit(ctx, models.AuditEntry{
+		ActorID: actorID,
+		Action:  models.AuditHolidayAdded,
+		Details: details,
+	})
+	return len(existing) == 0, nil
+}
+
+// RemoveHoliday removes the holiday on date on behalf of actorID, returning false if there was none
+func (s *Service) RemoveHoliday(ctx context.Context, date string, actorID int64) (bool, error) {
+	existing, err := s.repo.GetHolidays(ctx, date, date)
+	if err != nil || len(existing) == 0 {
+		return false, err
+	}
+	removed, err := s.repo.DeleteHoliday(ctx, date)
+	if err != nil || !removed {
+		return false, err
+	}
+
+	s.Audit(ctx, models.AuditEntry{
+		ActorID: actorID,
+		Action:  models.AuditHolidayRemoved,
+		Details: date + " " + existing[0].Name,
+	})
+	return true, nil
+}
+
+// ImportHolidays adds the national holidays of year from the holiday feed on behalf of actorID.
+// Dates that already have a holiday keep it. It returns how many holidays were added and how
+// many the feed listed.
+func (s *Service) ImportHolidays(ctx context.Context, year int, actorID int64) (int, int, error) {
+	if s.holidayFeed == nil {
+		return 0, 0, ErrHolidayImportDisabled
+	}
+
+	holidays, err := s.holidayFeed.Holidays(ctx, year)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to fetch holidays of %d: %w", year, err)
+	}
+	now := time.Now()
+	for i := range holidays {
+		holidays[i].Source = models.HolidaySourceImport
+		holidays[i].AddedBy = actorID
+		holidays[i].AddedAt = now
+	}
+
+	added, err := s.repo.InsertHolidays(ctx, holidays)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to save holidays: %w", err)
+	}
+
+	logging.FromContext(ctx).Info("Holidays imported", "year", year, "listed", len(holidays), "added", added)
+	s.Audit(ctx, models.AuditEntry{
+		ActorID: actorID,
+		Action:  models.AuditHolidaysImported,
+		Details: fmt.Sprintf("%d: %d of %d added", year, added, len(holidays)),
+	})
+	return added, len(holidays), nil
+}
+
+// GetHolidays returns the holidays from startDate to endDate (inclusive), those of the HOLIDAYS
+// setting included, ordered by date
+func (s *Service) GetHolidays(ctx context.Context, startDate, endDate string) ([]models.Holiday, error) {
+	holidays, err := s.repo.GetHolidays(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holidays: %w", err)
+	}
+
+	kept := make(map[string]bool, len(holidays))
+	for _, holiday := range holidays {
+		kept[holiday.Date] = true
+	}
+	added := false
+	for date := range s.fixedHolidays {
+		if date >= startDate && date <= endDate && !kept[date] {
+			holidays = append(holidays, models.Holiday{Date: date, Source: models.HolidaySourceConfig})
+			added = true
+		}
+	}
+	if added {
+		sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date < holidays[j].Date })
+	}
+	return holidays, nil
+}
+
+// IsHoliday reports whether day, in utils.Location, is a holiday
+func (s *Service) IsHoliday(ctx context.Context, day time.Time) (bool, error) {
+	date := day.In(utils.Location).Format("2006-01-02")
+	holidays, err := s.GetHolidays(ctx, date, date)
+	return len(holidays) > 0, err
+}
+
+// GetWorkingDays returns the dates from startDate to endDate (inclusive) that are neither on a
+// weekend nor a holiday
+func (s *Service) GetWorkingDays(ctx context.Context, startDate, endDate string) ([]string, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDateRange, startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDateRange, endDate)
+	}
+
+	holidays, err := s.GetHolidays(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	off := make(map[string]bool, len(holidays))
+	for _, holiday := range holidays {
+		off[holiday.Date] = true
+	}
+
+	var days []string
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday && !off[date] {
+			days = append(days, date)
+		}
+	}
+	return days, nil
+}
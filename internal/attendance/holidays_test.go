@@ -0,0 +1,201 @@
+package attendance
+
+import (
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestAddHoliday adds holidays next to Nyepi, kept on 11 March 2024
+func TestAddHoliday(t *testing.T) {
+	tests := []struct {
+		name    string
+		date    string
+		holiday string
+		added   bool
+		stored  string
+		details string
+		wantErr bool
+	}{
+		{name: "new", date: "2024-04-10", holiday: "Idul Fitri 1445 H", added: true,
+			stored: "Idul Fitri 1445 H", details: "2024-04-10 Idul Fitri 1445 H"},
+		{name: "quoted with spaces", date: "2024-04-10", holiday: `"Idul  Fitri"`, added: true,
+			stored: "Idul Fitri", details: "2024-04-10 Idul Fitri"},
+		{name: "rename", date: "2024-03-11", holiday: "Hari Suci Nyepi", stored: "Hari Suci Nyepi",
+			details: "2024-03-11 Nyepi → Hari Suci Nyepi"},
+		{name: "invalid date", date: "2024-02-30", holiday: "Leap", wantErr: true},
+		{name: "empty name", date: "2024-04-10", holiday: `""`, wantErr: true},
+		{name: "Markdown in name", date: "2024-04-10", holiday: "*Lebaran*", wantErr: true},
+		{name: "name too long", date: "2024-04-10", holiday: strings.Repeat("x", maxHolidayName+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			ctx := context.Background()
+			if _, err := service.AddHoliday(ctx, "2024-03-11", "Nyepi", 900); err != nil {
+				t.Fatalf("AddHoliday: %v", err)
+			}
+
+			added, err := service.AddHoliday(ctx, tt.date, tt.holiday, 900)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidHoliday) {
+					t.Errorf("AddHoliday(%q, %q) error = %v, want ErrInvalidHoliday", tt.date, tt.holiday, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddHoliday: %v", err)
+			}
+			if added != tt.added {
+				t.Errorf("AddHoliday added = %v, want %v", added, tt.added)
+			}
+
+			holidays, err := service.GetHolidays(ctx, tt.date, tt.date)
+			if err != nil {
+				t.Fatalf("GetHolidays: %v", err)
+			}
+			if len(holidays) != 1 || holidays[0].Name != tt.stored || holidays[0].Source != models.HolidaySourceAdmin {
+				t.Errorf("GetHolidays = %+v, want %q added by an admin", holidays, tt.stored)
+			}
+
+			entries, err := service.GetAuditLog(ctx, 0)
+			if err != nil {
+				t.Fatalf("GetAuditLog: %v", err)
+			}
+			if entries[0].Action != models.AuditHolidayAdded || entries[0].Details != tt.details {
+				t.Errorf("audit entry = %+v, want %s %q", entries[0], models.AuditHolidayAdded, tt.details)
+			}
+		})
+	}
+}
+
+// TestGetWorkingDays counts the working days of weeks in March 2024 around Nyepi on Monday 11
+// March, kept with /holiday, and Good Friday on 29 March, in the HOLIDAYS setting
+func TestGetWorkingDays(t *testing.T) {
+	tests := []struct {
+		name      string
+		startDate string
+		endDate   string
+		want      []string
+		wantErr   bool
+	}{
+		{name: "week without holidays", startDate: "2024-03-04", endDate: "2024-03-10",
+			want: []string{"2024-03-04", "2024-03-05", "2024-03-06", "2024-03-07", "2024-03-08"}},
+		{name: "kept holiday", startDate: "2024-03-11", endDate: "2024-03-13",
+			want: []string{"2024-03-12", "2024-03-13"}},
+		{name: "configured holiday", startDate: "2024-03-28", endDate: "2024-03-31",
+			want: []string{"2024-03-28"}},
+		{name: "weekend", startDate: "2024-03-09", endDate: "2024-03-10"},
+		{name: "malformed", startDate: "2024-03", endDate: "2024-03-10", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			ctx := context.Background()
+			service.SetFixedHolidays(map[string]bool{"2024-03-29": true})
+			if _, err := service.AddHoliday(ctx, "2024-03-11", "Nyepi", 900); err != nil {
+				t.Fatalf("AddHoliday: %v", err)
+			}
+
+			days, err := service.GetWorkingDays(ctx, tt.startDate, tt.endDate)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDateRange) {
+					t.Errorf("GetWorkingDays error = %v, want ErrInvalidDateRange", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetWorkingDays: %v", err)
+			}
+			if fmt.Sprint(days) != fmt.Sprint(tt.want) {
+				t.Errorf("GetWorkingDays(%s, %s) = %v, want %v", tt.startDate, tt.endDate, days, tt.want)
+			}
+		})
+	}
+}
+
+// TestImportHolidays imports the holidays of 2024 from a fake Nager.Date feed over Nyepi, already
+// kept with /holiday
+func TestImportHolidays(t *testing.T) {
+	const listed = `[
+		{"date": "2024-01-01", "localName": "Tahun Baru Masehi", "name": "New Year's Day"},
+		{"date": "2024-03-11", "localName": "Hari Suci Nyepi", "name": "Day of Silence"},
+		{"date": "2024-03-29", "localName": "", "name": "Good  Friday"},
+		{"date": "2024-03-29", "localName": "Wafat Isa Almasih", "name": "Good Friday"}
+	]`
+
+	tests := []struct {
+		name     string
+		response string
+		status   int
+		noFeed   bool
+		added    int
+		listed   int
+		want     map[string]string // Names of the holidays of 2024 afterwards
+		fails    bool
+		wantErr  error
+	}{
+		{name: "import", response: listed, status: http.StatusOK, added: 2, listed: 3,
+			want: map[string]string{"2024-01-01": "Tahun Baru Masehi", "2024-03-11": "Nyepi", "2024-03-29": "Good Friday"}},
+		{name: "feed down", status: http.StatusServiceUnavailable, fails: true, want: map[string]string{"2024-03-11": "Nyepi"}},
+		{name: "malformed date", response: `[{"date": "2024-13-01", "localName": "Bad"}]`, status: http.StatusOK, fails: true,
+			want: map[string]string{"2024-03-11": "Nyepi"}},
+		{name: "no feed", noFeed: true, fails: true, wantErr: ErrHolidayImportDisabled, want: map[string]string{"2024-03-11": "Nyepi"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requested = r.URL.Path
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.response)
+			}))
+			defer server.Close()
+
+			service, _ := newTestService(t)
+			ctx := context.Background()
+			if !tt.noFeed {
+				service.SetHolidayFeed(NewNagerHolidayFeed(server.URL + "/PublicHolidays/{year}/ID"))
+			}
+			if _, err := service.AddHoliday(ctx, "2024-03-11", "Nyepi", 900); err != nil {
+				t.Fatalf("AddHoliday: %v", err)
+			}
+
+			added, total, err := service.ImportHolidays(ctx, 2024, 900)
+			switch {
+			case !tt.fails && err != nil:
+				t.Fatalf("ImportHolidays: %v", err)
+			case tt.fails && err == nil:
+				t.Errorf("ImportHolidays succeeded, want an error")
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("ImportHolidays error = %v, want %v", err, tt.wantErr)
+			}
+			if added != tt.added || total != tt.listed {
+				t.Errorf("ImportHolidays = %d of %d added, want %d of %d", added, total, tt.added, tt.listed)
+			}
+			if !tt.noFeed && requested != "/PublicHolidays/2024/ID" {
+				t.Errorf("feed requested %q, want the holidays of 2024", requested)
+			}
+
+			holidays, err := service.GetHolidays(ctx, "2024-01-01", "2024-12-31")
+			if err != nil {
+				t.Fatalf("GetHolidays: %v", err)
+			}
+			got := make(map[string]string, len(holidays))
+			for _, holiday := range holidays {
+				got[holiday.Date] = holiday.Name
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("holidays = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package attendance
+
+import (
+	"strings"
+)
+
+// DefaultHOTPWindow is the number of counter values checked ahead of the stored counter
+const DefaultHOTPWindow = 10
+
+// HOTPService handles counter-based One-Time Password operations (RFC 4226)
+type HOTPService struct {
+	algorithm Algorithm
+	digits    int
+	window    int
+}
+
+// NewHOTPService creates a new HOTP service with the given algorithm, code length and look-ahead window
+func NewHOTPService(algorithm Algorithm, digits, window int) *HOTPService {
+	if algorithm == "" {
+		algorithm = AlgorithmSHA1
+	}
+	if digits <= 0 {
+		digits = 6
+	}
+	if window < 0 {
+		window = DefaultHOTPWindow
+	}
+
+	return &HOTPService{
+		algorithm: algorithm,
+		digits:    digits,
+		window:    window,
+	}
+}
+
+// Generate creates the HOTP code for a specific counter value
+func (h *HOTPService) Generate(secret string, counter uint64) (string, error) {
+	return generateCode(secret, counter, h.algorithm, h.digits)
+}
+
+// GenerateBatch creates count consecutive codes starting at the given counter, for printed code sheets
+func (h *HOTPService) GenerateBatch(secret string, start uint64, count int) ([]string, error) {
+	codes := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		code, err := h.Generate(secret, start+uint64(i))
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// Verify checks the code against the counter and the look-ahead window.
+// On success it returns the counter value that should be stored next.
+func (h *HOTPService) Verify(secret, code string, counter uint64) (uint64, bool, error) {
+	// Remove any spaces or formatting
+	code = strings.ReplaceAll(code, " ", "")
+
+	if len(code) != h.digits {
+		return counter, false, nil
+	}
+
+	for i := 0; i <= h.window; i++ {
+		expected, err := h.Generate(secret, counter+uint64(i))
+		if err != nil {
+			return counter, false, err
+		}
+		if codesEqual(code, expected) {
+			return counter + uint64(i) + 1, true, nil
+		}
+	}
+
+	return counter, false, nil
+}
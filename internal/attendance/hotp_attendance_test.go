@@ -0,0 +1,134 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// enrollTestHOTP enrolls the user in counter-based codes, returning the enrollment with its secret
+func enrollTestHOTP(t *testing.T, service *Service, userID int64) *models.HOTPEnrollment {
+	t.Helper()
+
+	service.SetSecretCipher(newTestCipher(t))
+	enrollment, err := service.EnrollHOTP(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("EnrollHOTP: %v", err)
+	}
+	return enrollment
+}
+
+// hotpCounter returns the user's stored HOTP counter
+func hotpCounter(t *testing.T, service *Service, userID int64) uint64 {
+	t.Helper()
+
+	enrollment, err := service.repo.GetHOTPEnrollment(context.Background(), userID)
+	if err != nil || enrollment == nil {
+		t.Fatalf("GetHOTPEnrollment = %+v, %v", enrollment, err)
+	}
+	return enrollment.Counter
+}
+
+func TestMarkAttendanceHOTPCounterAdvancesWithRecord(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	const userID = 1001
+	enrollment := enrollTestHOTP(t, service, userID)
+
+	code, err := service.hotp.Generate(enrollment.Secret, 0)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	result, err := service.MarkAttendance(ctx, userID, "budi", "Budi", nil, code)
+	if err != nil || !result.Success {
+		t.Fatalf("MarkAttendance = %+v, %v; want a check-in", result, err)
+	}
+	if counter := hotpCounter(t, service, userID); counter != 1 {
+		t.Errorf("counter after a recorded check-in = %d, want 1", counter)
+	}
+
+	result, err = service.MarkAttendance(ctx, userID, "budi", "Budi", nil, code)
+	if err != nil || result.Success || !result.OTPRejected {
+		t.Errorf("reused code = %+v, %v; want it rejected", result, err)
+	}
+}
+
+func TestMarkAttendanceHOTPCounterKeptWhenNothingRecorded(t *testing.T) {
+	service, repo := newTestService(t)
+	ctx := context.Background()
+	const userID = 1001
+	enrollment := enrollTestHOTP(t, service, userID)
+
+	now := utils.Now()
+	today := utils.GetTodayDate()
+	if _, _, err := repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{
+		{UserID: userID, FirstName: "Budi", Timestamp: now.Add(-2 * time.Hour), Type: "check_in", Date: today},
+		{UserID: userID, FirstName: "Budi", Timestamp: now.Add(-time.Hour), Type: "check_out", Date: today},
+	}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+
+	code, err := service.hotp.Generate(enrollment.Secret, 0)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	result, err := service.MarkAttendance(ctx, userID, "budi", "Budi", nil, code)
+	if err != nil || result.Success {
+		t.Fatalf("MarkAttendance on a complete day = %+v, %v; want it refused", result, err)
+	}
+	if counter := hotpCounter(t, service, userID); counter != 0 {
+		t.Errorf("counter after nothing was recorded = %d, want 0", counter)
+	}
+}
+
+func TestMarkAttendanceHOTPConcurrentSameCode(t *testing.T) {
+	service, repo := newTestService(t)
+	ctx := context.Background()
+	const userID = 1001
+	enrollment := enrollTestHOTP(t, service, userID)
+
+	code, err := service.hotp.Generate(enrollment.Secret, 0)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	const attempts = 5
+	results := make([]*AttendanceResult, attempts)
+	var wg sync.WaitGroup
+	for i := range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var err error
+			if results[i], err = service.MarkAttendance(ctx, userID, "budi", "Budi", nil, code); err != nil {
+				t.Errorf("MarkAttendance: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	successes := 0
+	for _, result := range results {
+		if result != nil && result.Success {
+			successes++
+		}
+	}
+	if successes != 1 {
+		t.Errorf("%d attempts with the same code succeeded, want 1", successes)
+	}
+
+	records, err := repo.GetDailyReport(ctx, utils.GetTodayDate())
+	if err != nil {
+		t.Fatalf("GetDailyReport: %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("%d records saved, want 1", len(records))
+	}
+	if counter := hotpCounter(t, service, userID); counter != 1 {
+		t.Errorf("counter = %d, want 1", counter)
+	}
+}
@@ -0,0 +1,90 @@
+package attendance
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRFC4226Vectors(t *testing.T) {
+	// RFC 4226 Appendix D, for the secret "12345678901234567890"
+	codes := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	hotp := NewHOTPService(AlgorithmSHA1, 6, DefaultHOTPWindow)
+
+	for counter, want := range codes {
+		code, err := hotp.Generate(rfc6238Seeds[AlgorithmSHA1], uint64(counter))
+		if err != nil {
+			t.Fatalf("Generate(%d): %v", counter, err)
+		}
+		if code != want {
+			t.Errorf("code for counter %d = %s, want %s", counter, code, want)
+		}
+	}
+
+	batch, err := hotp.GenerateBatch(rfc6238Seeds[AlgorithmSHA1], 3, 4)
+	if err != nil {
+		t.Fatalf("GenerateBatch: %v", err)
+	}
+	for i, code := range batch {
+		if code != codes[3+i] {
+			t.Errorf("batch code %d = %s, want %s", i, code, codes[3+i])
+		}
+	}
+}
+
+func TestHOTPVerifyResynchronizes(t *testing.T) {
+	hotp := NewHOTPService(AlgorithmSHA1, 6, 5)
+	secret := rfc6238Seeds[AlgorithmSHA1]
+	const counter = 2
+
+	tests := []struct {
+		name    string
+		code    string
+		valid   bool
+		counter uint64
+	}{
+		{"current", "359152", true, 3},
+		{"ahead within window", "287922", true, 7},
+		{"last in window", "162583", true, 8},
+		{"beyond window", "399871", false, counter},
+		{"already used", "287082", false, counter},
+		{"spaced", "359 152", true, 3},
+		{"wrong length", "35915", false, counter},
+	}
+
+	for _, tt := range tests {
+		next, valid, err := hotp.Verify(secret, tt.code, counter)
+		if err != nil {
+			t.Fatalf("%s: Verify: %v", tt.name, err)
+		}
+		if valid != tt.valid || next != tt.counter {
+			t.Errorf("%s: Verify(%q) = %d, %v; want %d, %v", tt.name, tt.code, next, valid, tt.counter, tt.valid)
+		}
+	}
+}
+
+func TestVerifyHOTPStoresResynchronizedCounter(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	const userID = 1001
+	enrollment := enrollTestHOTP(t, service, userID)
+
+	// The user skipped four printed codes
+	code, _ := service.hotp.Generate(enrollment.Secret, 4)
+	if valid, err := service.VerifyHOTP(ctx, userID, code); err != nil || !valid {
+		t.Fatalf("VerifyHOTP(code 4) = %v, %v; want valid", valid, err)
+	}
+	if counter := hotpCounter(t, service, userID); counter != 5 {
+		t.Errorf("counter after code 4 = %d, want 5", counter)
+	}
+
+	// Neither the used code nor a skipped one is accepted anymore
+	skipped, _ := service.hotp.Generate(enrollment.Secret, 2)
+	for _, old := range []string{code, skipped} {
+		if valid, err := service.VerifyHOTP(ctx, userID, old); err != nil || valid {
+			t.Errorf("VerifyHOTP(%s) after resync = %v, %v; want rejected", old, valid, err)
+		}
+	}
+	if counter := hotpCounter(t, service, userID); counter != 5 {
+		t.Errorf("counter after rejected codes = %d, want 5", counter)
+	}
+}
@@ -0,0 +1,34 @@
+package attendance
+
+import (
+	"attendance-bot/internal/importer"
+	"attendance-bot/internal/logging"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"io"
+)
+
+// ImportAttendance imports historical attendance from a CSV file on behalf of actorID, see
+// importer.Import. Nothing is written when dryRun is set.
+func (s *Service) ImportAttendance(ctx context.Context, r io.Reader, filename string, dryRun bool, actorID int64) (*importer.Result, error) {
+	result, err := importer.Import(ctx, s.repo, r, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if result.DryRun || result.Inserted == 0 {
+		return result, nil
+	}
+
+	for _, date := range result.Dates {
+		s.reports.invalidate(date)
+	}
+
+	logging.FromContext(ctx).Info("Attendance imported", "file", filename, "rows", result.Rows, "inserted", result.Inserted)
+	s.Audit(ctx, models.AuditEntry{
+		ActorID: actorID,
+		Action:  models.AuditAttendanceImported,
+		Details: fmt.Sprintf("%s: %d of %d rows inserted", filename, result.Inserted, result.Rows),
+	})
+	return result, nil
+}
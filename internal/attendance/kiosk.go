@@ -0,0 +1,106 @@
+package attendance
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNoPersonalCode is returned when recording kiosk attendance for a user not enrolled in HOTP
+var ErrNoPersonalCode = errors.New("user has no personal code")
+
+// GetKioskEmployees returns the employees who can record attendance at the kiosk, ordered by
+// name. Only users enrolled in HOTP have a personal code; the shared TOTP code would let
+// anyone at the kiosk record attendance for anyone else.
+func (s *Service) GetKioskEmployees(ctx context.Context) ([]models.Employee, error) {
+	employees, err := s.repo.GetHOTPEmployees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Employees without Telegram may have neither an alias nor a record yet
+	for i := range employees {
+		if employees[i].Username == "" {
+			employees[i].Username = fmt.Sprintf("user_%d", employees[i].UserID)
+		}
+		if employees[i].FirstName == "" {
+			employees[i].FirstName = fmt.Sprintf("Karyawan %d", employees[i].UserID)
+		}
+	}
+
+	sort.SliceStable(employees, func(i, j int) bool {
+		return employeeSortKey(employees[i]) < employeeSortKey(employees[j])
+	})
+
+	return employees, nil
+}
+
+// employeeSortKey orders employees by name, ignoring case and spacing
+func employeeSortKey(employee models.Employee) string {
+	return utils.NameKey(FullName(employee.FirstName, employee.LastName), "")
+}
+
+// MarkKioskAttendance verifies the employee's personal HOTP code and records their next
+// attendance with source kiosk
+func (s *Service) MarkKioskAttendance(ctx context.Context, employee models.Employee, code string) (*AttendanceResult, error) {
+	result, err := s.markKioskAttendance(ctx, employee, code)
+	s.observeMark(result, err)
+	return result, err
+}
+
+// markKioskAttendance verifies the code against the employee's enrollment and records the attendance
+func (s *Service) markKioskAttendance(ctx context.Context, employee models.Employee, code string) (*AttendanceResult, error) {
+	if !utils.ValidateOTP(code, s.totp.Digits()) {
+		return &AttendanceResult{
+			Success: false,
+			Message: i18n.T(i18n.FromContext(ctx), "kiosk.invalid_format", "Digits", s.totp.Digits()),
+		}, nil
+	}
+
+	if refusal, err := s.checkRegistration(ctx, employee.UserID); refusal != nil || err != nil {
+		return refusal, err
+	}
+
+	if refusal, err := s.checkOTPLockout(ctx, employee.UserID); refusal != nil || err != nil {
+		return refusal, err
+	}
+
+	enrollment, err := s.repo.GetHOTPEnrollment(ctx, employee.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hotp enrollment: %w", err)
+	}
+	if enrollment == nil {
+		return nil, fmt.Errorf("user %d: %w", employee.UserID, ErrNoPersonalCode)
+	}
+
+	advance, err := s.matchHOTPEnrollment(ctx, enrollment, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify hotp: %w", err)
+	}
+	if advance == nil {
+		logging.FromContext(ctx).Debug("Kiosk code rejected", "employee_id", employee.UserID)
+		result := &AttendanceResult{
+			Success:     false,
+			Message:     i18n.T(i18n.FromContext(ctx), "kiosk.rejected"),
+			OTPRejected: true,
+		}
+		if err := s.countOTPFailure(ctx, employee.UserID, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	s.clearOTPFailures(ctx, employee.UserID)
+
+	return s.recordNextAttendance(ctx, &models.AttendanceRecord{
+		UserID:    employee.UserID,
+		Username:  employee.Username,
+		FirstName: employee.FirstName,
+		LastName:  employee.LastName,
+		Source:    models.SourceKiosk,
+	}, nil, advance)
+}
@@ -0,0 +1,60 @@
+package attendance
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnsupportedLanguage is returned when a user picks a language without a message catalog
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// ResolveLanguage returns the language to write to a user in: the one they chose with /language,
+// else the one matching their Telegram language_code. The latter is stored, so notifications sent
+// outside a conversation with the user use it too.
+func (s *Service) ResolveLanguage(ctx context.Context, userID int64, telegramCode string) (string, error) {
+	stored, err := s.repo.GetUserLanguage(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if stored != nil && (stored.Chosen || telegramCode == "") {
+		return stored.Language, nil
+	}
+	if telegramCode == "" {
+		return i18n.Default, nil
+	}
+
+	detected := i18n.Normalize(telegramCode)
+	if stored == nil || stored.Language != detected {
+		err := s.repo.SaveUserLanguage(ctx, &models.UserLanguage{UserID: userID, Language: detected, UpdatedAt: time.Now()})
+		if err != nil {
+			return "", err
+		}
+	}
+	return detected, nil
+}
+
+// GetUserLanguage returns the stored language of a user, or i18n.Default if none is stored
+func (s *Service) GetUserLanguage(ctx context.Context, userID int64) (string, error) {
+	stored, err := s.repo.GetUserLanguage(ctx, userID)
+	if err != nil || stored == nil {
+		return i18n.Default, err
+	}
+	return stored.Language, nil
+}
+
+// SetUserLanguage stores the language a user chose, which then takes precedence over their
+// Telegram language_code. An empty lang drops the choice, returning to language_code.
+func (s *Service) SetUserLanguage(ctx context.Context, userID int64, lang, telegramCode string) error {
+	language := &models.UserLanguage{UserID: userID, Language: lang, Chosen: true, UpdatedAt: time.Now()}
+	if lang == "" {
+		language.Language, language.Chosen = i18n.Normalize(telegramCode), false
+	} else if !i18n.IsSupported(lang) {
+		return fmt.Errorf("%w: %q", ErrUnsupportedLanguage, lang)
+	}
+
+	return s.repo.SaveUserLanguage(ctx, language)
+}
@@ -0,0 +1,38 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"sort"
+)
+
+// GetLateArrivals returns the check-ins of a date (YYYY-MM-DD) late for their user's shift, earliest first
+func (s *Service) GetLateArrivals(ctx context.Context, date string) ([]models.LateArrival, error) {
+	records, err := s.repo.GetDailyReport(ctx, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily report: %w", err)
+	}
+
+	shifts, err := s.GetShiftAssignments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift assignments: %w", err)
+	}
+
+	var late []models.LateArrival
+	for _, day := range models.GroupByDay(records) {
+		if day.CheckIn == nil {
+			continue
+		}
+		shift := shifts.For(day.UserID)
+		if utils.IsLate(day.CheckIn.Timestamp, shift) {
+			late = append(late, models.LateArrival{Record: *day.CheckIn, Shift: shift})
+		}
+	}
+
+	sort.Slice(late, func(i, j int) bool {
+		return late[i].Record.Timestamp.Before(late[j].Record.Timestamp)
+	})
+	return late, nil
+}
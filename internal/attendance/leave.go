@@ -0,0 +1,113 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxLeaveDays is the longest period a single leave request may cover
+const MaxLeaveDays = 31
+
+// MaxLeaveBackdateDays is how far in the past a leave may start, e.g. sick leave reported afterwards
+const MaxLeaveBackdateDays = 30
+
+// Leave request errors
+var (
+	ErrUnknownLeaveType = errors.New("unknown leave type")
+	ErrLeaveTooLong     = errors.New("leave period too long")
+	ErrLeaveTooOld      = errors.New("leave starts too far in the past")
+	ErrLeaveOverlap     = errors.New("leave overlaps an existing request")
+)
+
+// RequestLeave validates and stores a pending leave request
+func (s *Service) RequestLeave(ctx context.Context, leave *models.Leave) (*models.Leave, error) {
+	if _, ok := models.LeaveTypeLabels[leave.Type]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownLeaveType, leave.Type)
+	}
+
+	start, err := utils.ParseDate(leave.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: start date %q", ErrInvalidDateRange, leave.StartDate)
+	}
+	end, err := utils.ParseDate(leave.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: end date %q", ErrInvalidDateRange, leave.EndDate)
+	}
+	if start.After(end) {
+		return nil, fmt.Errorf("%w: %s is after %s", ErrInvalidDateRange, leave.StartDate, leave.EndDate)
+	}
+	if days := int(end.Sub(start).Hours()/24) + 1; days > MaxLeaveDays {
+		return nil, fmt.Errorf("%w: %d days", ErrLeaveTooLong, days)
+	}
+	if earliest := utils.AddDays(utils.Now(), -MaxLeaveBackdateDays).Format("2006-01-02"); leave.StartDate < earliest {
+		return nil, fmt.Errorf("%w: %s", ErrLeaveTooOld, leave.StartDate)
+	}
+
+	overlap, err := s.repo.HasOverlappingLeave(ctx, leave.UserID, leave.StartDate, leave.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	if overlap {
+		return nil, ErrLeaveOverlap
+	}
+
+	request := *leave
+	request.Status = models.LeavePending
+	request.RequestedAt = time.Now()
+	return s.repo.InsertLeave(ctx, &request)
+}
+
+// DecideLeave approves or rejects a pending leave request. It returns the request and false
+// if it does not exist or was already decided, so a second press of a button changes nothing.
+func (s *Service) DecideLeave(ctx context.Context, id int64, approve bool, decidedBy int64) (*models.Leave, bool, error) {
+	status := models.LeaveRejected
+	if approve {
+		status = models.LeaveApproved
+	}
+
+	decided, err := s.repo.DecideLeave(ctx, id, status, decidedBy, time.Now())
+	if err != nil {
+		return nil, false, err
+	}
+
+	leave, err := s.repo.GetLeave(ctx, id)
+	if err != nil || leave == nil {
+		return nil, false, err
+	}
+
+	// Approved leave appears in the reports of its days
+	if decided && approve {
+		for _, day := range models.ExpandLeave([]models.Leave{*leave}, leave.StartDate, leave.EndDate) {
+			s.reports.invalidate(day.Date)
+		}
+	}
+
+	return leave, decided, nil
+}
+
+// GetLeaveDays returns the days of approved leave between startDate and endDate (inclusive)
+func (s *Service) GetLeaveDays(ctx context.Context, startDate, endDate string) ([]models.LeaveDay, error) {
+	leaves, err := s.repo.GetApprovedLeaves(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return models.ExpandLeave(leaves, startDate, endDate), nil
+}
+
+// GetUpcomingLeaves returns the user's leave requests that have not ended yet
+func (s *Service) GetUpcomingLeaves(ctx context.Context, userID int64) ([]models.Leave, error) {
+	return s.repo.GetUserLeaves(ctx, userID, utils.GetTodayDate())
+}
+
+// LeaveName returns the display name of the leave's user, preferring their alias
+func (s *Service) LeaveName(ctx context.Context, leave *models.Leave) string {
+	return s.formatUserName(ctx, &models.AttendanceRecord{
+		UserID:    leave.UserID,
+		FirstName: leave.FirstName,
+		LastName:  leave.LastName,
+	})
+}
@@ -0,0 +1,104 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRequestLeave(t *testing.T) {
+	day := func(offset int) string {
+		return utils.AddDays(utils.Now(), offset).Format("2006-01-02")
+	}
+
+	tests := []struct {
+		name  string
+		leave models.Leave
+		want  error
+	}{
+		{"one day", models.Leave{Type: models.LeaveCuti, StartDate: day(10), EndDate: day(10)}, nil},
+		{"longest period", models.Leave{Type: models.LeaveIzin, StartDate: day(40), EndDate: day(40 + MaxLeaveDays - 1)}, nil},
+		{"sick leave reported afterwards", models.Leave{Type: models.LeaveSakit, StartDate: day(-MaxLeaveBackdateDays), EndDate: day(-1)}, nil},
+		{"unknown type", models.Leave{Type: "holiday", StartDate: day(10), EndDate: day(10)}, ErrUnknownLeaveType},
+		{"invalid start", models.Leave{Type: models.LeaveCuti, StartDate: "2024-02-30", EndDate: day(10)}, ErrInvalidDateRange},
+		{"invalid end", models.Leave{Type: models.LeaveCuti, StartDate: day(10), EndDate: "tomorrow"}, ErrInvalidDateRange},
+		{"reversed", models.Leave{Type: models.LeaveCuti, StartDate: day(11), EndDate: day(10)}, ErrInvalidDateRange},
+		{"too long", models.Leave{Type: models.LeaveCuti, StartDate: day(10), EndDate: day(10 + MaxLeaveDays)}, ErrLeaveTooLong},
+		{"too old", models.Leave{Type: models.LeaveSakit, StartDate: day(-MaxLeaveBackdateDays - 1), EndDate: day(-1)}, ErrLeaveTooOld},
+		{"overlapping", models.Leave{Type: models.LeaveCuti, StartDate: day(4), EndDate: day(6)}, ErrLeaveOverlap},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			ctx := context.Background()
+			// An existing request the overlapping one collides with
+			if _, err := service.RequestLeave(ctx, &models.Leave{UserID: 1, FirstName: "Sari", Type: models.LeaveCuti, StartDate: day(5), EndDate: day(5)}); err != nil {
+				t.Fatalf("RequestLeave: %v", err)
+			}
+
+			tt.leave.UserID, tt.leave.FirstName, tt.leave.Reason = 1, "Sari", "family"
+			saved, err := service.RequestLeave(ctx, &tt.leave)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("RequestLeave = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (saved.ID == 0 || saved.Status != models.LeavePending) {
+				t.Errorf("saved leave = %+v, want a pending request with an ID", saved)
+			}
+		})
+	}
+}
+
+func TestDecideLeave(t *testing.T) {
+	tests := []struct {
+		name    string
+		approve bool
+		status  string
+		days    int
+	}{
+		{"approved", true, models.LeaveApproved, 3},
+		{"rejected", false, models.LeaveRejected, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			ctx := context.Background()
+			start := utils.AddDays(utils.Now(), 10).Format("2006-01-02")
+			end := utils.AddDays(utils.Now(), 12).Format("2006-01-02")
+			leave, err := service.RequestLeave(ctx, &models.Leave{UserID: 1, FirstName: "Sari", Type: models.LeaveCuti, StartDate: start, EndDate: end})
+			if err != nil {
+				t.Fatalf("RequestLeave: %v", err)
+			}
+
+			decided, changed, err := service.DecideLeave(ctx, leave.ID, tt.approve, 900)
+			if err != nil || !changed {
+				t.Fatalf("DecideLeave = %v, %v; want the request decided", changed, err)
+			}
+			if decided.Status != tt.status || decided.DecidedBy != 900 || decided.DecidedAt == nil {
+				t.Errorf("decided leave = %+v, want %s by 900", decided, tt.status)
+			}
+
+			// A second press of either button changes nothing
+			again, changed, err := service.DecideLeave(ctx, leave.ID, !tt.approve, 901)
+			if err != nil || changed || again.Status != tt.status || again.DecidedBy != 900 {
+				t.Errorf("second DecideLeave = %+v, %v, %v; want the first decision kept", again, changed, err)
+			}
+
+			days, err := service.GetLeaveDays(ctx, start, end)
+			if err != nil {
+				t.Fatalf("GetLeaveDays: %v", err)
+			}
+			if len(days) != tt.days {
+				t.Errorf("%d leave days, want %d", len(days), tt.days)
+			}
+		})
+	}
+
+	service, _ := newTestService(t)
+	if leave, changed, err := service.DecideLeave(context.Background(), 99, true, 900); err != nil || changed || leave != nil {
+		t.Errorf("DecideLeave of a missing request = %+v, %v, %v; want nil, false", leave, changed, err)
+	}
+}
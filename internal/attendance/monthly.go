@@ -0,0 +1,190 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GetMonthlySummary aggregates each employee's attendance for a month given as YYYY-MM, as
+// GetPeriodSummary does for the month's days
+func (s *Service) GetMonthlySummary(ctx context.Context, month, department string) (*models.MonthlySummary, error) {
+	first, err := time.ParseInLocation("2006-01", month, utils.Location)
+	if err != nil {
+		return nil, fmt.Errorf("%w: month %q", ErrInvalidDateRange, month)
+	}
+
+	summary, err := s.GetPeriodSummary(ctx, first.Format("2006-01-02"), first.AddDate(0, 1, -1).Format("2006-01-02"), department)
+	if err != nil {
+		return nil, err
+	}
+	summary.Month = month
+	return summary, nil
+}
+
+// GetPeriodSummary aggregates each employee's attendance from startDate to endDate (YYYY-MM-DD):
+// days present, days late by their shift, average check-in time, total work time, overtime, days
+// without a check-out and days absent. Periods are counted up to today, and today is never counted
+// as missing a check-out or absent since they may still arrive. Only working days, neither on a
+// weekend nor a holiday, without approved leave count as absent. A department limits the summary
+// to its users; without one, users are grouped by department with totals per department once
+// anyone belongs to one.
+func (s *Service) GetPeriodSummary(ctx context.Context, startDate, endDate, department string) (*models.MonthlySummary, error) {
+	today := utils.Now().Format("2006-01-02")
+	summary := &models.MonthlySummary{
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Department: department,
+	}
+	if summary.EndDate > today {
+		summary.EndDate = today
+	}
+	if summary.StartDate > summary.EndDate {
+		return summary, nil
+	}
+
+	days, err := s.repo.GetAttendanceDays(ctx, summary.StartDate, summary.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := s.GetShiftAssignments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	employees, err := s.employeesByUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workingDays, err := s.GetWorkingDays(ctx, summary.StartDate, summary.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	summary.WorkingDays = len(workingDays)
+	if summary.Holidays, err = s.GetHolidays(ctx, summary.StartDate, summary.EndDate); err != nil {
+		return nil, err
+	}
+	leave, err := s.GetLeaveDays(ctx, summary.StartDate, summary.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	excused := make(map[userDay]bool, len(days)+len(leave))
+	for i := range days {
+		excused[userDay{days[i].UserID, days[i].Date}] = true
+	}
+	for _, day := range leave {
+		excused[userDay{day.Leave.UserID, day.Date}] = true
+	}
+
+	// Days are ordered by user, so each user's days are consecutive. Check-in minutes since
+	// midnight are summed per user for the average.
+	var checkInMinutes []int
+	for i := range days {
+		day := &days[i]
+		if i == 0 || days[i-1].UserID != day.UserID {
+			employee := employees[day.UserID]
+			summary.Users = append(summary.Users, models.MonthlyUserSummary{
+				UserID:     day.UserID,
+				EmployeeID: employee.EmployeeID,
+				Department: employee.Department,
+			})
+			checkInMinutes = append(checkInMinutes, 0)
+		}
+		n := len(summary.Users) - 1
+		user := &summary.Users[n]
+
+		// The latest day's identity is the most current one
+		user.Name = s.formatUserName(ctx, &models.AttendanceRecord{UserID: day.UserID, FirstName: day.FirstName, LastName: day.LastName})
+		user.Username = day.Username
+		user.DaysPresent++
+		if day.Overtime > 0 {
+			user.Overtime += day.Overtime
+			user.DaysOvertime++
+		}
+
+		if day.CheckIn == nil {
+			continue
+		}
+		local := day.CheckIn.In(utils.Location)
+		checkInMinutes[n] += local.Hour()*60 + local.Minute()
+		user.DaysCheckedIn++
+		if utils.IsLate(*day.CheckIn, shifts.For(day.UserID)) {
+			user.DaysLate++
+		}
+
+		switch {
+		case day.CheckOut != nil:
+			if duration := utils.NewWorkDuration(*day.CheckIn, *day.CheckOut, day.Breaks); duration.Countable() {
+				user.WorkDuration += duration.Duration
+			}
+		case day.Date != today:
+			user.MissingCheckouts++
+		}
+	}
+	for i := range summary.Users {
+		user := &summary.Users[i]
+		if user.DaysCheckedIn > 0 {
+			user.AverageCheckIn = time.Duration(checkInMinutes[i]/user.DaysCheckedIn) * time.Minute
+		}
+		for _, date := range workingDays {
+			if date != today && !excused[userDay{user.UserID, date}] {
+				user.DaysAbsent++
+			}
+		}
+	}
+
+	if department != "" {
+		summary.Users = slices.DeleteFunc(summary.Users, func(user models.MonthlyUserSummary) bool {
+			return user.Department != department
+		})
+	}
+
+	// Users without a department are listed last
+	sort.SliceStable(summary.Users, func(i, j int) bool {
+		a, b := &summary.Users[i], &summary.Users[j]
+		if a.Department != b.Department {
+			return b.Department == "" || (a.Department != "" && a.Department < b.Department)
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	if department == "" {
+		summary.Departments = summarizeDepartments(summary.Users)
+	}
+
+	return summary, nil
+}
+
+// userDay identifies one user's day
+type userDay struct {
+	userID int64
+	date   string
+}
+
+// summarizeDepartments totals users sorted by department, returning nil when nobody has one
+func summarizeDepartments(users []models.MonthlyUserSummary) []models.MonthlyDepartmentSummary {
+	var departments []models.MonthlyDepartmentSummary
+	named := false
+	for i := range users {
+		user := &users[i]
+		if len(departments) == 0 || departments[len(departments)-1].Name != user.Department {
+			departments = append(departments, models.MonthlyDepartmentSummary{Name: user.Department})
+			named = named || user.Department != ""
+		}
+		department := &departments[len(departments)-1]
+		department.Users++
+		department.DaysPresent += user.DaysPresent
+		department.DaysLate += user.DaysLate
+		department.WorkDuration += user.WorkDuration
+		department.Overtime += user.Overtime
+		department.DaysAbsent += user.DaysAbsent
+	}
+
+	if !named {
+		return nil
+	}
+	return departments
+}
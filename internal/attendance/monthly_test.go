@@ -0,0 +1,51 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"testing"
+	"time"
+)
+
+// TestPeriodSummaryExcludesInvalidDurations gives a user a normal day, a day whose check-out was
+// corrected to before the check-in and a day over 24 hours: only the normal day is totalled
+func TestPeriodSummaryExcludesInvalidDurations(t *testing.T) {
+	service, repo := newTestService(t)
+	ctx := context.Background()
+
+	at := func(day, hour int) time.Time {
+		return time.Date(2024, 3, day, hour, 0, 0, 0, utils.Location)
+	}
+	record := func(day, hour int, recordType string, date int) models.AttendanceRecord {
+		return models.AttendanceRecord{
+			UserID: 1, FirstName: "Sari", Timestamp: at(day, hour), Type: recordType,
+			Date: time.Date(2024, 3, date, 0, 0, 0, 0, utils.Location).Format("2006-01-02"),
+		}
+	}
+	if _, _, err := repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{
+		record(4, 8, "check_in", 4),
+		record(4, 16, "check_out", 4), // 8 hours
+		record(5, 9, "check_in", 5),
+		record(5, 7, "check_out", 5), // Check-out before check-in
+		record(6, 8, "check_in", 6),
+		record(7, 17, "check_out", 6), // 33 hours
+	}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+
+	summary, err := service.GetPeriodSummary(ctx, "2024-03-04", "2024-03-08", "")
+	if err != nil {
+		t.Fatalf("GetPeriodSummary: %v", err)
+	}
+	if len(summary.Users) != 1 {
+		t.Fatalf("users = %d, want 1", len(summary.Users))
+	}
+	user := summary.Users[0]
+	if user.WorkDuration != 8*time.Hour {
+		t.Errorf("WorkDuration = %v, want 8h0m0s from the valid day only", user.WorkDuration)
+	}
+	if user.DaysPresent != 3 || user.MissingCheckouts != 0 {
+		t.Errorf("DaysPresent, MissingCheckouts = %d, %d, want 3, 0", user.DaysPresent, user.MissingCheckouts)
+	}
+}
@@ -0,0 +1,90 @@
+package attendance
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGenerateOnShiftReport lists who is working at noon on 4 March 2024 for several days of
+// check-ins and check-outs
+func TestGenerateOnShiftReport(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, utils.Location)
+	record := func(userID int64, name string, day, hour int, recordType string) models.AttendanceRecord {
+		timestamp := time.Date(2024, 3, day, hour, 0, 0, 0, utils.Location)
+		return models.AttendanceRecord{
+			UserID: userID, FirstName: name, Timestamp: timestamp, Type: recordType, Date: timestamp.Format("2006-01-02"),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		records []models.AttendanceRecord
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "nobody checked in",
+			want: []string{i18n.T("en", "who.empty")},
+		},
+		{
+			name:    "checked in",
+			records: []models.AttendanceRecord{record(1, "Sari", 4, 8, "check_in")},
+			want:    []string{"1. **Sari**", "In: 08:00 · ⏱️ 4 h 0 min", "Total: 1"},
+		},
+		{
+			name: "earliest first",
+			records: []models.AttendanceRecord{
+				record(1, "Sari", 4, 9, "check_in"),
+				record(2, "Budi", 4, 7, "check_in"),
+			},
+			want: []string{"1. **Budi**", "2. **Sari**", "Total: 2"},
+		},
+		{
+			name: "checked out",
+			records: []models.AttendanceRecord{
+				record(1, "Sari", 4, 8, "check_in"),
+				record(1, "Sari", 4, 11, "check_out"),
+				record(2, "Budi", 4, 8, "check_in"),
+			},
+			want:    []string{"1. **Budi**", "Total: 1"},
+			notWant: []string{"Sari"},
+		},
+		{
+			name:    "checked in the day before",
+			records: []models.AttendanceRecord{record(1, "Sari", 3, 20, "check_in")},
+			want:    []string{i18n.T("en", "who.empty")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService(t)
+			ctx := context.Background()
+			if len(tt.records) > 0 {
+				if _, _, err := repo.InsertAttendanceBatch(ctx, tt.records); err != nil {
+					t.Fatalf("InsertAttendanceBatch: %v", err)
+				}
+			}
+
+			report, err := service.GenerateOnShiftReport(ctx, now, "en")
+			if err != nil {
+				t.Fatalf("GenerateOnShiftReport: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(report, want) {
+					t.Errorf("report =\n%s\nwant it to contain %q", report, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(report, notWant) {
+					t.Errorf("report =\n%s\nwant it without %q", report, notWant)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package attendance
+
+import (
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// otpLimiter counts failed OTP attempts per user and locks a user out once they reach the limit
+// within the window. Counters live only in the database, which counts failures atomically, so
+// replicas sharing it agree and a lockout survives a restart.
+type otpLimiter struct {
+	repo     database.Repository
+	limit    int
+	window   time.Duration
+	duration time.Duration
+}
+
+// newOTPLimiter creates a limiter locking users out for duration after limit failures within window
+func newOTPLimiter(repo database.Repository, limit int, window, duration time.Duration) *otpLimiter {
+	return &otpLimiter{
+		repo:     repo,
+		limit:    limit,
+		window:   window,
+		duration: duration,
+	}
+}
+
+// lockedUntil returns until when the user is locked out, or the zero time if they are not
+func (l *otpLimiter) lockedUntil(ctx context.Context, userID int64, now time.Time) (time.Time, error) {
+	counter, err := l.repo.GetOTPLockout(ctx, userID)
+	if err != nil || counter == nil || !counter.LockedUntil.After(now) {
+		return time.Time{}, err
+	}
+	return counter.LockedUntil, nil
+}
+
+// fail counts a failed attempt made at now and returns the user's counter, locked out once the
+// failures reach the limit. A window starts with its first failure, and a new one after a lockout.
+func (l *otpLimiter) fail(ctx context.Context, userID int64, now time.Time) (models.OTPLockout, error) {
+	counter, err := l.repo.CountOTPFailure(ctx, userID, now, now.Add(-l.window), l.limit, now.Add(l.duration))
+	if err != nil {
+		return models.OTPLockout{}, err
+	}
+	return *counter, nil
+}
+
+// reset clears the user's counter, returning false if they had none
+func (l *otpLimiter) reset(ctx context.Context, userID int64) (bool, error) {
+	return l.repo.DeleteOTPLockout(ctx, userID)
+}
+
+// SetOTPLockout locks users out for duration once they fail limit OTPs within window. A limit of
+// 0 disables lockouts.
+func (s *Service) SetOTPLockout(limit int, window, duration time.Duration) {
+	if limit <= 0 {
+		s.otpLimiter = nil
+		return
+	}
+	s.otpLimiter = newOTPLimiter(s.repo, limit, window, duration)
+}
+
+// checkOTPLockout returns the refusal of an attempt by a locked out user, or nil if the user may
+// try a code
+func (s *Service) checkOTPLockout(ctx context.Context, userID int64) (*AttendanceResult, error) {
+	if s.otpLimiter == nil {
+		return nil, nil
+	}
+
+	until, err := s.otpLimiter.lockedUntil(ctx, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get otp lockout: %w", err)
+	}
+	if until.IsZero() {
+		return nil, nil
+	}
+
+	logging.FromContext(ctx).Warn("OTP attempt while locked out", "locked_until", until)
+	return &AttendanceResult{
+		Success:   false,
+		Message:   lockoutMessage(i18n.FromContext(ctx), until, time.Now()),
+		LockedOut: true,
+	}, nil
+}
+
+// countOTPFailure counts the user's rejected OTP and, once it locks them out, says so in the
+// result and records the lockout in the audit log
+func (s *Service) countOTPFailure(ctx context.Context, userID int64, result *AttendanceResult) error {
+	if s.otpLimiter == nil {
+		return nil
+	}
+
+	now := time.Now()
+	counter, err := s.otpLimiter.fail(ctx, userID, now)
+	if err != nil {
+		return fmt.Errorf("failed to count failed otp: %w", err)
+	}
+	if counter.LockedUntil.IsZero() {
+		return nil
+	}
+
+	logging.FromContext(ctx).Warn("User locked out after failed OTPs",
+		"audit", models.AuditOTPLocked,
+		"failures", counter.Failures,
+		"locked_until", counter.LockedUntil)
+	s.Audit(ctx, models.AuditEntry{
+		CreatedAt:    now,
+		ActorID:      userID,
+		Action:       models.AuditOTPLocked,
+		TargetUserID: userID,
+		Details: fmt.Sprintf("%d failures since %s, locked until %s", counter.Failures,
+			utils.FormatTime(counter.WindowStart, "HH:mm"), utils.FormatTime(counter.LockedUntil, "HH:mm")),
+	})
+
+	result.Message = lockoutMessage(i18n.FromContext(ctx), counter.LockedUntil, now)
+	result.Lockout = &counter
+	return nil
+}
+
+// clearOTPFailures forgets the user's failed OTPs after a successful verification
+func (s *Service) clearOTPFailures(ctx context.Context, userID int64) {
+	if s.otpLimiter == nil {
+		return
+	}
+	if _, err := s.otpLimiter.reset(ctx, userID); err != nil {
+		logging.FromContext(ctx).Error("Failed to clear failed OTP counter", "error", err)
+	}
+}
+
+// UnlockOTP lifts a user's lockout and forgets their failed OTPs on an admin's behalf, returning
+// false if the user was not locked out
+func (s *Service) UnlockOTP(ctx context.Context, userID, actorID int64) (bool, error) {
+	if s.otpLimiter == nil {
+		return false, nil
+	}
+
+	until, err := s.otpLimiter.lockedUntil(ctx, userID, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to get otp lockout: %w", err)
+	}
+	if _, err := s.otpLimiter.reset(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to delete otp lockout: %w", err)
+	}
+	if until.IsZero() {
+		return false, nil
+	}
+
+	s.Audit(ctx, models.AuditEntry{
+		ActorID:      actorID,
+		Action:       models.AuditOTPUnlocked,
+		TargetUserID: userID,
+		Details:      "locked until " + utils.FormatTime(until, "HH:mm"),
+	})
+	return true, nil
+}
+
+// GetOTPLockouts returns the users currently locked out, those locked out longest first
+func (s *Service) GetOTPLockouts(ctx context.Context) ([]models.OTPLockout, error) {
+	return s.repo.GetOTPLockoutsUntil(ctx, time.Now())
+}
+
+// lockoutMessage tells a locked out user in lang how long until they may try again
+func lockoutMessage(lang string, until, now time.Time) string {
+	return i18n.T(lang, "otp.locked_out",
+		"Minutes", int(math.Ceil(until.Sub(now).Minutes())), "Until", utils.FormatTime(until, "HH:mm"))
+}
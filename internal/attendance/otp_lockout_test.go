@@ -0,0 +1,201 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOTPLockoutSharedBetweenReplicas(t *testing.T) {
+	repo := newTestRepository(t)
+	first := NewService(repo, NewTOTPService(testSecret))
+	second := NewService(repo, NewTOTPService(testSecret))
+	first.SetOTPLockout(3, 10*time.Minute, 15*time.Minute)
+	second.SetOTPLockout(3, 10*time.Minute, 15*time.Minute)
+
+	ctx := context.Background()
+	const userID = 1001
+	for _, replica := range []*Service{first, second, first} {
+		if err := replica.countOTPFailure(ctx, userID, &AttendanceResult{}); err != nil {
+			t.Fatalf("countOTPFailure: %v", err)
+		}
+	}
+
+	refusal, err := second.checkOTPLockout(ctx, userID)
+	if err != nil {
+		t.Fatalf("checkOTPLockout: %v", err)
+	}
+	if refusal == nil || !refusal.LockedOut {
+		t.Fatalf("second replica does not see the lockout after 3 failures across replicas")
+	}
+
+	unlocked, err := second.UnlockOTP(ctx, userID, 1)
+	if err != nil || !unlocked {
+		t.Fatalf("UnlockOTP = %v, %v; want true", unlocked, err)
+	}
+	if refusal, err := first.checkOTPLockout(ctx, userID); err != nil || refusal != nil {
+		t.Errorf("first replica still refuses after an unlock on the second: %+v, %v", refusal, err)
+	}
+}
+
+func TestOTPLockoutCountsConcurrentFailures(t *testing.T) {
+	repo := newTestRepository(t)
+	replicas := []*Service{NewService(repo, NewTOTPService(testSecret)), NewService(repo, NewTOTPService(testSecret))}
+	for _, replica := range replicas {
+		replica.SetOTPLockout(100, time.Hour, time.Hour)
+	}
+
+	ctx := context.Background()
+	const userID, failures = 1001, 20
+	var wg sync.WaitGroup
+	for i := range failures {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := replicas[i%2].countOTPFailure(ctx, userID, &AttendanceResult{}); err != nil {
+				t.Errorf("countOTPFailure: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	counter, err := repo.GetOTPLockout(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetOTPLockout: %v", err)
+	}
+	if counter == nil || counter.Failures != failures {
+		t.Errorf("counter = %+v, want %d failures", counter, failures)
+	}
+}
+
+func TestOTPLockoutWindow(t *testing.T) {
+	service, repo := newTestService(t)
+	service.SetOTPLockout(2, 10*time.Minute, 15*time.Minute)
+
+	ctx := context.Background()
+	const userID = 1001
+	start := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	counter, err := service.otpLimiter.fail(ctx, userID, start)
+	if err != nil || counter.Failures != 1 || !counter.LockedUntil.IsZero() {
+		t.Fatalf("first failure = %+v, %v", counter, err)
+	}
+
+	// A failure after the window starts a new one instead of locking the user out
+	counter, err = service.otpLimiter.fail(ctx, userID, start.Add(10*time.Minute))
+	if err != nil || counter.Failures != 1 || !counter.WindowStart.Equal(start.Add(10*time.Minute)) {
+		t.Fatalf("failure after the window = %+v, %v; want a new window", counter, err)
+	}
+
+	counter, err = service.otpLimiter.fail(ctx, userID, start.Add(15*time.Minute))
+	if err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+	if want := start.Add(30 * time.Minute); counter.Failures != 2 || !counter.LockedUntil.Equal(want) {
+		t.Errorf("failure within the window = %+v, want 2 failures locked until %v", counter, want)
+	}
+
+	// The failure after a lockout starts a new window
+	counter, err = service.otpLimiter.fail(ctx, userID, start.Add(31*time.Minute))
+	if err != nil || counter.Failures != 1 || !counter.LockedUntil.IsZero() {
+		t.Errorf("failure after the lockout = %+v, %v; want a new window", counter, err)
+	}
+
+	stored, err := repo.GetOTPLockout(ctx, userID)
+	if err != nil || stored == nil || stored.Failures != 1 {
+		t.Errorf("stored counter = %+v, %v", stored, err)
+	}
+}
+
+// TestOTPLockoutAfterFailures counts rejected OTPs up to and past the limit, then has an admin
+// unlock the user: lockouts and unlocks are recorded in the audit log
+func TestOTPLockoutAfterFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      int
+		failures   int
+		lockedOut  bool
+		wantAudit  []string // Actions recorded, newest first
+		wantUnlock bool
+	}{
+		{name: "disabled", limit: 0, failures: 5},
+		{name: "below the limit", limit: 3, failures: 2},
+		{name: "at the limit", limit: 3, failures: 3, lockedOut: true,
+			wantAudit: []string{models.AuditOTPUnlocked, models.AuditOTPLocked}, wantUnlock: true},
+		{name: "limit of one", limit: 1, failures: 1, lockedOut: true,
+			wantAudit: []string{models.AuditOTPUnlocked, models.AuditOTPLocked}, wantUnlock: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			service.SetOTPLockout(tt.limit, 10*time.Minute, 15*time.Minute)
+
+			ctx := context.Background()
+			const userID = 1001
+			var result AttendanceResult
+			for range tt.failures {
+				if err := service.countOTPFailure(ctx, userID, &result); err != nil {
+					t.Fatalf("countOTPFailure: %v", err)
+				}
+			}
+			if lockedOut := result.Lockout != nil; lockedOut != tt.lockedOut {
+				t.Errorf("locked out after %d failures = %v, want %v", tt.failures, lockedOut, tt.lockedOut)
+			}
+
+			refusal, err := service.checkOTPLockout(ctx, userID)
+			if err != nil {
+				t.Fatalf("checkOTPLockout: %v", err)
+			}
+			if refused := refusal != nil && refusal.LockedOut; refused != tt.lockedOut {
+				t.Errorf("next attempt refused = %v, want %v", refused, tt.lockedOut)
+			}
+
+			unlocked, err := service.UnlockOTP(ctx, userID, 900)
+			if err != nil {
+				t.Fatalf("UnlockOTP: %v", err)
+			}
+			if unlocked != tt.wantUnlock {
+				t.Errorf("UnlockOTP = %v, want %v", unlocked, tt.wantUnlock)
+			}
+			if refusal, err := service.checkOTPLockout(ctx, userID); err != nil || refusal != nil {
+				t.Errorf("attempt after the unlock refused: %+v, %v", refusal, err)
+			}
+
+			entries, err := service.GetAuditLog(ctx, userID)
+			if err != nil {
+				t.Fatalf("GetAuditLog: %v", err)
+			}
+			if got, want := auditActions(entries), fmt.Sprint(tt.wantAudit); got != want {
+				t.Errorf("audit log = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestLockoutMessage(t *testing.T) {
+	now := time.Date(2024, 3, 4, 8, 0, 0, 0, utils.Location)
+
+	tests := []struct {
+		name  string
+		until time.Time
+		want  string
+	}{
+		{"whole minutes", now.Add(15 * time.Minute), "15 minutes (at 08:15)"},
+		{"rounded up", now.Add(14*time.Minute + time.Second), "15 minutes (at 08:14)"},
+		{"last minute", now.Add(30 * time.Second), "1 minutes (at 08:00)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lockoutMessage("en", tt.until, now); !strings.Contains(got, tt.want) {
+				t.Errorf("lockoutMessage = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
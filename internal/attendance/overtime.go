@@ -0,0 +1,63 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultOvertimeThreshold is the overtime threshold used unless SetOvertimeThreshold is called
+const DefaultOvertimeThreshold = 30 * time.Minute
+
+// SetOvertimeThreshold sets how long past the end of their shift a user must check out for the
+// time to count as overtime; 0 counts every minute
+func (s *Service) SetOvertimeThreshold(threshold time.Duration) {
+	s.overtimeThreshold = threshold
+}
+
+// OvertimeThreshold returns how long past the end of their shift a user must check out for the
+// time to count as overtime
+func (s *Service) OvertimeThreshold() time.Duration {
+	return s.overtimeThreshold
+}
+
+// overtimeMinutes returns the whole minutes of overtime of a check-out after checkIn by the
+// user's shift, or 0 when they fall short of the threshold. Shifts without an end have none.
+func (s *Service) overtimeMinutes(ctx context.Context, userID int64, checkIn, checkOut time.Time) (int, error) {
+	shift, err := s.GetUserShift(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get shift: %w", err)
+	}
+	return thresholdOvertime(utils.Overtime(checkIn, checkOut, shift), s.overtimeThreshold), nil
+}
+
+// thresholdOvertime returns overtime in whole minutes, or 0 when it is shorter than threshold
+func thresholdOvertime(overtime, threshold time.Duration) int {
+	if overtime <= 0 || overtime < threshold {
+		return 0
+	}
+	return int(overtime / time.Minute)
+}
+
+// GetOvertimeSummary totals each employee's overtime for a month given as YYYY-MM, of everyone
+// or of one department. Users without overtime are left out.
+func (s *Service) GetOvertimeSummary(ctx context.Context, month, department string) (*models.MonthlySummary, error) {
+	summary, err := s.GetMonthlySummary(ctx, month, department)
+	if err != nil {
+		return nil, err
+	}
+
+	users := summary.Users[:0]
+	for _, user := range summary.Users {
+		if user.Overtime > 0 {
+			users = append(users, user)
+		}
+	}
+	summary.Users = users
+	if department == "" {
+		summary.Departments = summarizeDepartments(summary.Users)
+	}
+	return summary, nil
+}
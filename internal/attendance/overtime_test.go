@@ -0,0 +1,141 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestThresholdOvertime(t *testing.T) {
+	tests := []struct {
+		name      string
+		overtime  time.Duration
+		threshold time.Duration
+		want      int
+	}{
+		{"none", 0, DefaultOvertimeThreshold, 0},
+		{"below threshold", 29 * time.Minute, DefaultOvertimeThreshold, 0},
+		{"at threshold", 30 * time.Minute, DefaultOvertimeThreshold, 30},
+		{"whole minutes", 90*time.Minute + 59*time.Second, DefaultOvertimeThreshold, 90},
+		{"no threshold", time.Minute, 0, 1},
+		{"under a minute", 59 * time.Second, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := thresholdOvertime(tt.overtime, tt.threshold); got != tt.want {
+				t.Errorf("thresholdOvertime(%v, %v) = %d, want %d", tt.overtime, tt.threshold, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOvertimeMinutes checks out users on a day shift ending at 16:00, a night shift ending at
+// 06:00 and no shift, with the default threshold
+func TestOvertimeMinutes(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, utils.Location)
+	}
+
+	tests := []struct {
+		name     string
+		userID   int64
+		checkIn  time.Time
+		checkOut time.Time
+		want     int
+	}{
+		{"day shift, on time", 1, at(4, 8, 0), at(4, 16, 10), 0},
+		{"day shift, late", 1, at(4, 8, 0), at(4, 17, 15), 75},
+		{"night shift, next morning", 2, at(4, 22, 0), at(5, 7, 0), 60},
+		{"night shift, before the end", 2, at(4, 22, 0), at(5, 5, 0), 0},
+		{"no shift end", 3, at(4, 8, 0), at(4, 23, 0), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			service.SetOvertimeThreshold(DefaultOvertimeThreshold)
+			ctx := context.Background()
+			for _, shift := range []models.Shift{
+				{Name: "day", Start: "08:00", End: "16:00"},
+				{Name: "night", Start: "22:00", End: "06:00"},
+			} {
+				if err := service.SaveShift(ctx, &shift); err != nil {
+					t.Fatalf("SaveShift: %v", err)
+				}
+			}
+			for userID, shift := range map[int64]string{1: "day", 2: "night"} {
+				if err := service.AssignShift(ctx, userID, shift, 900); err != nil {
+					t.Fatalf("AssignShift: %v", err)
+				}
+			}
+
+			got, err := service.overtimeMinutes(ctx, tt.userID, tt.checkIn, tt.checkOut)
+			if err != nil {
+				t.Fatalf("overtimeMinutes: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("overtimeMinutes = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetOvertimeSummary totals the overtime of March 2024 of user 1 in Human Resources, with two
+// days of overtime, user 2 in IT, with one, and user 3, with none
+func TestGetOvertimeSummary(t *testing.T) {
+	workday := func(userID int64, day, overtime int) []models.AttendanceRecord {
+		date := time.Date(2024, 3, day, 0, 0, 0, 0, utils.Location)
+		return []models.AttendanceRecord{
+			{UserID: userID, FirstName: "User", Timestamp: date.Add(8 * time.Hour), Type: "check_in", Date: date.Format("2006-01-02")},
+			{UserID: userID, FirstName: "User", Timestamp: date.Add(16*time.Hour + time.Duration(overtime)*time.Minute),
+				Type: "check_out", Date: date.Format("2006-01-02"), OvertimeMinutes: overtime},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		department string
+		want       map[int64]time.Duration
+		days       map[int64]int
+	}{
+		{"everyone", "", map[int64]time.Duration{1: 90 * time.Minute, 2: 45 * time.Minute}, map[int64]int{1: 2, 2: 1}},
+		{"one department", "IT", map[int64]time.Duration{2: 45 * time.Minute}, map[int64]int{2: 1}},
+		{"department without overtime", "Sales", map[int64]time.Duration{}, map[int64]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService(t)
+			ctx := context.Background()
+			registerTestEmployees(t, service)
+			var records []models.AttendanceRecord
+			records = append(records, workday(1, 4, 60)...)
+			records = append(records, workday(1, 5, 30)...)
+			records = append(records, workday(2, 4, 45)...)
+			records = append(records, workday(3, 4, 0)...)
+			if _, _, err := repo.InsertAttendanceBatch(ctx, records); err != nil {
+				t.Fatalf("InsertAttendanceBatch: %v", err)
+			}
+
+			summary, err := service.GetOvertimeSummary(ctx, "2024-03", tt.department)
+			if err != nil {
+				t.Fatalf("GetOvertimeSummary: %v", err)
+			}
+			if len(summary.Users) != len(tt.want) {
+				t.Errorf("GetOvertimeSummary has %d users, want %d", len(summary.Users), len(tt.want))
+			}
+			for _, user := range summary.Users {
+				if user.Overtime != tt.want[user.UserID] || user.DaysOvertime != tt.days[user.UserID] {
+					t.Errorf("user %d: %v overtime on %d days, want %v on %d days",
+						user.UserID, user.Overtime, user.DaysOvertime, tt.want[user.UserID], tt.days[user.UserID])
+				}
+			}
+			if tt.department == "" && len(summary.Departments) != 2 {
+				t.Errorf("GetOvertimeSummary has %d departments, want 2", len(summary.Departments))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Errors returned when attaching a photo to a check-in
+var (
+	ErrNoRecentCheckIn = errors.New("no recent check-in to attach a photo to")
+	ErrPhotoAttached   = errors.New("check-in already has a photo")
+)
+
+// SetPhotoWindow sets how long after a check-in a selfie may be attached to it; 0 turns photos off
+func (s *Service) SetPhotoWindow(window time.Duration) {
+	s.photoWindow = window
+}
+
+// PhotoWindow returns how long after a check-in a selfie may be attached, 0 when photos are off
+func (s *Service) PhotoWindow() time.Duration {
+	return s.photoWindow
+}
+
+// AttachPhoto attaches a photo, sent at sentAt, to the user's check-in of that day as proof of
+// presence. The check-in must have been verified by the user's own code (OTP or bypass) within
+// the photo window, and only one photo is kept per check-in.
+func (s *Service) AttachPhoto(ctx context.Context, userID int64, fileID string, sentAt time.Time) (*models.AttendanceRecord, error) {
+	if s.photoWindow <= 0 {
+		return nil, fmt.Errorf("%w: photos are off", ErrNoRecentCheckIn)
+	}
+
+	date := sentAt.In(utils.Location).Format("2006-01-02")
+	status, err := s.repo.GetUserAttendanceStatus(ctx, userID, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance status: %w", err)
+	}
+
+	checkIn := status.CheckInRecord
+	if checkIn == nil || (checkIn.Source != models.SourceOTP && checkIn.Source != models.SourceBypass) {
+		return nil, fmt.Errorf("%w: no check-in of %s by the user's code", ErrNoRecentCheckIn, date)
+	}
+	// Telegram dates have second precision, so a photo sent right after the check-in may seem earlier
+	if elapsed := sentAt.Sub(checkIn.Timestamp.Truncate(time.Second)); elapsed < 0 || elapsed > s.photoWindow {
+		return nil, fmt.Errorf("%w: checked in at %s", ErrNoRecentCheckIn, checkIn.Timestamp.Format(time.RFC3339))
+	}
+	if checkIn.PhotoFileID != "" {
+		return nil, fmt.Errorf("%w: record %d", ErrPhotoAttached, checkIn.ID)
+	}
+
+	attached, err := s.repo.SetAttendancePhoto(ctx, checkIn.ID, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach photo: %w", err)
+	}
+	if !attached {
+		return nil, fmt.Errorf("%w: record %d", ErrPhotoAttached, checkIn.ID)
+	}
+
+	checkIn.PhotoFileID = fileID
+	return checkIn, nil
+}
+
+// GetAttendanceRecord returns the record with the given ID, archived or not, or nil if there is none
+func (s *Service) GetAttendanceRecord(ctx context.Context, id int64) (*models.AttendanceRecord, error) {
+	return s.repo.GetAttendanceRecord(ctx, id)
+}
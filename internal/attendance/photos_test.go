@@ -0,0 +1,67 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestAttachPhoto sends a selfie at several times after check-ins of several sources, with a 10
+// minute photo window
+func TestAttachPhoto(t *testing.T) {
+	checkIn := time.Date(2024, 3, 4, 8, 0, 0, 500_000_000, utils.Location)
+
+	tests := []struct {
+		name   string
+		source string
+		window time.Duration
+		sentAt time.Time
+		want   error
+	}{
+		{"right after an OTP check-in", models.SourceOTP, 10 * time.Minute, checkIn.Truncate(time.Second), nil},
+		{"end of the window", models.SourceOTP, 10 * time.Minute, checkIn.Truncate(time.Second).Add(10 * time.Minute), nil},
+		{"bypass check-in", models.SourceBypass, 10 * time.Minute, checkIn.Add(time.Minute), nil},
+		{"after the window", models.SourceOTP, 10 * time.Minute, checkIn.Add(11 * time.Minute), ErrNoRecentCheckIn},
+		{"before the check-in", models.SourceOTP, 10 * time.Minute, checkIn.Add(-time.Minute), ErrNoRecentCheckIn},
+		{"another day", models.SourceOTP, 10 * time.Minute, checkIn.AddDate(0, 0, 1), ErrNoRecentCheckIn},
+		{"check-in by an admin", models.SourceAdmin, 10 * time.Minute, checkIn.Add(time.Minute), ErrNoRecentCheckIn},
+		{"kiosk check-in", models.SourceKiosk, 10 * time.Minute, checkIn.Add(time.Minute), ErrNoRecentCheckIn},
+		{"photos off", models.SourceOTP, 0, checkIn.Add(time.Minute), ErrNoRecentCheckIn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService(t)
+			ctx := context.Background()
+			service.SetPhotoWindow(tt.window)
+			if _, err := repo.InsertAttendance(ctx, &models.AttendanceRecord{
+				UserID: 1, FirstName: "Sari", Timestamp: checkIn, Type: "check_in", Date: "2024-03-04", Source: tt.source,
+			}); err != nil {
+				t.Fatalf("InsertAttendance: %v", err)
+			}
+
+			record, err := service.AttachPhoto(ctx, 1, "photo-1", tt.sentAt)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("AttachPhoto = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				return
+			}
+			if record.PhotoFileID != "photo-1" {
+				t.Errorf("PhotoFileID = %q, want photo-1", record.PhotoFileID)
+			}
+
+			// Only the first photo is kept
+			if _, err := service.AttachPhoto(ctx, 1, "photo-2", tt.sentAt); !errors.Is(err, ErrPhotoAttached) {
+				t.Errorf("second AttachPhoto = %v, want ErrPhotoAttached", err)
+			}
+			stored, err := service.GetAttendanceRecord(ctx, record.ID)
+			if err != nil || stored.PhotoFileID != "photo-1" {
+				t.Errorf("stored record = %+v, %v; want photo-1 attached", stored, err)
+			}
+		})
+	}
+}
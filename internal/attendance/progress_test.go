@@ -0,0 +1,167 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"testing"
+	"time"
+)
+
+// TestGetWorkProgress computes a user's progress on 4 March 2024 from records at fixed times,
+// with the clock fixed by the now passed in
+func TestGetWorkProgress(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, utils.Location)
+	}
+	record := func(recordType string, timestamp time.Time) models.AttendanceRecord {
+		return models.AttendanceRecord{
+			UserID: 1, FirstName: "Sari", Timestamp: timestamp, Type: recordType,
+			Date: timestamp.In(utils.Location).Format("2006-01-02"),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		records []models.AttendanceRecord
+		target  time.Duration
+		now     time.Time
+		want    WorkProgress
+	}{
+		{
+			name: "not checked in",
+			now:  at(4, 10, 0),
+			want: WorkProgress{},
+		},
+		{
+			name:    "checked in yesterday only",
+			records: []models.AttendanceRecord{record("check_in", at(3, 8, 0))},
+			now:     at(4, 10, 0),
+			want:    WorkProgress{},
+		},
+		{
+			name:    "no target",
+			records: []models.AttendanceRecord{record("check_in", at(4, 8, 0))},
+			now:     at(4, 13, 12),
+			want:    WorkProgress{CheckedIn: true, CheckIn: at(4, 8, 0), Elapsed: 5*time.Hour + 12*time.Minute},
+		},
+		{
+			name:    "target remaining",
+			records: []models.AttendanceRecord{record("check_in", at(4, 8, 0))},
+			target:  8 * time.Hour,
+			now:     at(4, 13, 12),
+			want: WorkProgress{
+				CheckedIn: true, CheckIn: at(4, 8, 0), Elapsed: 5*time.Hour + 12*time.Minute,
+				Target: 8 * time.Hour, Remaining: 2*time.Hour + 48*time.Minute,
+			},
+		},
+		{
+			name:    "target reached",
+			records: []models.AttendanceRecord{record("check_in", at(4, 8, 0))},
+			target:  8 * time.Hour,
+			now:     at(4, 17, 30),
+			want: WorkProgress{
+				CheckedIn: true, CheckIn: at(4, 8, 0), Elapsed: 9*time.Hour + 30*time.Minute, Target: 8 * time.Hour,
+			},
+		},
+		{
+			name: "ended break",
+			records: []models.AttendanceRecord{
+				record("check_in", at(4, 8, 0)),
+				record("break_start", at(4, 12, 0)),
+				record("break_end", at(4, 12, 45)),
+			},
+			target: 8 * time.Hour,
+			now:    at(4, 14, 0),
+			want: WorkProgress{
+				CheckedIn: true, CheckIn: at(4, 8, 0), Elapsed: 5*time.Hour + 15*time.Minute, Breaks: 45 * time.Minute,
+				Target: 8 * time.Hour, Remaining: 2*time.Hour + 45*time.Minute,
+			},
+		},
+		{
+			name: "on break",
+			records: []models.AttendanceRecord{
+				record("check_in", at(4, 8, 0)),
+				record("break_start", at(4, 12, 0)),
+			},
+			now: at(4, 12, 20),
+			want: WorkProgress{
+				CheckedIn: true, CheckIn: at(4, 8, 0), Elapsed: 4 * time.Hour, Breaks: 20 * time.Minute, OnBreak: true,
+			},
+		},
+		{
+			name: "checked out",
+			records: []models.AttendanceRecord{
+				record("check_in", at(4, 8, 0)),
+				record("break_start", at(4, 12, 0)),
+				record("break_end", at(4, 13, 0)),
+				record("check_out", at(4, 16, 30)),
+			},
+			target: 8 * time.Hour,
+			now:    at(4, 21, 0),
+			want: WorkProgress{
+				CheckedIn: true, CheckedOut: true, CheckIn: at(4, 8, 0), CheckOut: at(4, 16, 30),
+				Elapsed: 7*time.Hour + 30*time.Minute, Breaks: time.Hour, Target: 8 * time.Hour, Remaining: 30 * time.Minute,
+			},
+		},
+		{
+			name: "checked out on break",
+			records: []models.AttendanceRecord{
+				record("check_in", at(4, 8, 0)),
+				record("break_start", at(4, 15, 0)),
+				record("check_out", at(4, 16, 0)),
+			},
+			now: at(4, 21, 0),
+			want: WorkProgress{
+				CheckedIn: true, CheckedOut: true, CheckIn: at(4, 8, 0), CheckOut: at(4, 16, 0),
+				Elapsed: 7 * time.Hour, Breaks: time.Hour,
+			},
+		},
+		{
+			name:    "clock behind check-in",
+			records: []models.AttendanceRecord{record("check_in", at(4, 8, 0))},
+			target:  8 * time.Hour,
+			now:     at(4, 7, 55),
+			want: WorkProgress{
+				CheckedIn: true, CheckIn: at(4, 8, 0), Target: 8 * time.Hour, Remaining: 8 * time.Hour,
+			},
+		},
+		{
+			name:    "now in UTC",
+			records: []models.AttendanceRecord{record("check_in", at(4, 6, 30))},
+			now:     time.Date(2024, 3, 4, 1, 0, 0, 0, time.UTC), // 08:00 WIB
+			want:    WorkProgress{CheckedIn: true, CheckIn: at(4, 6, 30), Elapsed: 90 * time.Minute},
+		},
+		{
+			name:    "UTC day before in WIB",
+			records: []models.AttendanceRecord{record("check_in", at(4, 6, 30))},
+			now:     time.Date(2024, 3, 3, 23, 45, 0, 0, time.UTC), // 06:45 WIB on the 4th
+			want:    WorkProgress{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService(t)
+			ctx := context.Background()
+			service.SetExpectedWorkHours(tt.target)
+			if len(tt.records) > 0 {
+				if _, _, err := repo.InsertAttendanceBatch(ctx, tt.records); err != nil {
+					t.Fatalf("InsertAttendanceBatch: %v", err)
+				}
+			}
+
+			got, err := service.GetWorkProgress(ctx, 1, tt.now)
+			if err != nil {
+				t.Fatalf("GetWorkProgress: %v", err)
+			}
+			if !got.CheckIn.Equal(tt.want.CheckIn) || !got.CheckOut.Equal(tt.want.CheckOut) {
+				t.Errorf("CheckIn, CheckOut = %v, %v, want %v, %v", got.CheckIn, got.CheckOut, tt.want.CheckIn, tt.want.CheckOut)
+			}
+			got.CheckIn, got.CheckOut = tt.want.CheckIn, tt.want.CheckOut
+			if *got != tt.want {
+				t.Errorf("GetWorkProgress = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package attendance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// DefaultIssuer is the issuer shown in authenticator apps for the shared secret
+	DefaultIssuer = "Attendance Bot"
+	// DefaultAccountName is the account name shown in authenticator apps for the shared secret
+	DefaultAccountName = "Employee"
+	// QRCodeSize is the width and height in pixels of the QR code images
+	QRCodeSize = 256
+)
+
+// GenerateQRCode renders the otpauth:// URI as a PNG QR code of the given size in pixels
+func (t *TOTPService) GenerateQRCode(accountName, issuer string, size int) ([]byte, error) {
+	return encodeQRCode(t.GenerateKeyURI(accountName, issuer), size)
+}
+
+// encodeQRCode renders uri as a PNG QR code of the given size in pixels, or QRCodeSize if size is
+// not positive
+func encodeQRCode(uri string, size int) ([]byte, error) {
+	if uri == "" {
+		return nil, errors.New("failed to generate QR code: empty URI")
+	}
+	if size <= 0 {
+		size = QRCodeSize
+	}
+
+	png, err := qrcode.Encode(uri, qrcode.Medium, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code: %w", err)
+	}
+
+	return png, nil
+}
+
+// EnrollmentQRCode renders the current shared secret as a PNG QR code for an authenticator app
+func (s *Service) EnrollmentQRCode(ctx context.Context) ([]byte, error) {
+	if err := s.syncRotatedSecret(ctx); err != nil {
+		return nil, err
+	}
+	return s.verifier.Current().GenerateQRCode(DefaultAccountName, DefaultIssuer, QRCodeSize)
+}
@@ -0,0 +1,66 @@
+package attendance
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// TestGenerateQRCode renders QR codes of several sizes and decodes each back to its otpauth URI
+func TestGenerateQRCode(t *testing.T) {
+	totp := NewTOTPService(testSecret)
+
+	tests := []struct {
+		name     string
+		account  string
+		issuer   string
+		size     int
+		wantSize int
+	}{
+		{"default size", DefaultAccountName, DefaultIssuer, QRCodeSize, QRCodeSize},
+		{"size not given", DefaultAccountName, DefaultIssuer, 0, QRCodeSize},
+		{"negative size", "budi", "Attendance", -1, QRCodeSize},
+		{"large", "sari@example.com", "PT Maju Jaya", 512, 512},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := totp.GenerateQRCode(tt.account, tt.issuer, tt.size)
+			if err != nil {
+				t.Fatalf("GenerateQRCode: %v", err)
+			}
+			if len(data) == 0 {
+				t.Fatal("GenerateQRCode returned an empty image")
+			}
+
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("failed to decode PNG: %v", err)
+			}
+			if bounds := img.Bounds(); bounds.Dx() != tt.wantSize || bounds.Dy() != tt.wantSize {
+				t.Errorf("image is %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), tt.wantSize, tt.wantSize)
+			}
+
+			bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+			if err != nil {
+				t.Fatalf("failed to binarize image: %v", err)
+			}
+			result, err := qrcode.NewQRCodeReader().Decode(bitmap, nil)
+			if err != nil {
+				t.Fatalf("failed to decode QR code: %v", err)
+			}
+			if want := totp.GenerateKeyURI(tt.account, tt.issuer); result.GetText() != want {
+				t.Errorf("QR code holds %q, want %q", result.GetText(), want)
+			}
+		})
+	}
+}
+
+func TestEncodeQRCodeRejectsEmptyURI(t *testing.T) {
+	if data, err := encodeQRCode("", QRCodeSize); err == nil {
+		t.Errorf("encodeQRCode(\"\") = %d bytes, want an error", len(data))
+	}
+}
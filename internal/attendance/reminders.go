@@ -0,0 +1,123 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// DefaultReminderLead is how many minutes before the shift start the check-in reminder is sent
+const DefaultReminderLead = 15
+
+// MaxReminderLead bounds the lead time of the check-in reminder
+const MaxReminderLead = 120
+
+// ErrInvalidReminderLead is returned when enabling reminders with a lead time out of range
+var ErrInvalidReminderLead = errors.New("invalid reminder lead time")
+
+// EnableReminders turns on reminders for the user, leadMinutes before their shift starts and when
+// it ends. Enabling them again only changes the lead time.
+func (s *Service) EnableReminders(ctx context.Context, userID int64, leadMinutes int) error {
+	if leadMinutes < 1 || leadMinutes > MaxReminderLead {
+		return fmt.Errorf("%w: %d minutes", ErrInvalidReminderLead, leadMinutes)
+	}
+
+	return s.repo.SaveReminder(ctx, &models.ReminderPreference{
+		UserID:      userID,
+		LeadMinutes: leadMinutes,
+		CreatedAt:   time.Now(),
+	})
+}
+
+// DisableReminders turns off reminders for the user, returning false if they were not enabled
+func (s *Service) DisableReminders(ctx context.Context, userID int64) (bool, error) {
+	return s.repo.DeleteReminder(ctx, userID)
+}
+
+// GetReminderPreference returns the user's reminder preference, or nil if reminders are off
+func (s *Service) GetReminderPreference(ctx context.Context, userID int64) (*models.ReminderPreference, error) {
+	return s.repo.GetReminder(ctx, userID)
+}
+
+// DueReminders returns the reminders due in the minute of now: a check-in reminder the user's lead
+// time before their shift starts if they have not checked in, and a check-out reminder when their
+// shift ends if they checked in but not out. Users on approved leave are not reminded, and shifts
+// ending the next day get no check-out reminder since a check-out after midnight starts a new day.
+func (s *Service) DueReminders(ctx context.Context, now time.Time) ([]models.Reminder, error) {
+	preferences, err := s.repo.GetReminders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminders: %w", err)
+	}
+	if len(preferences) == 0 {
+		return nil, nil
+	}
+
+	shifts, err := s.GetShiftAssignments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shift assignments: %w", err)
+	}
+
+	// Only the users whose shift starts or ends now, in their own timezone, need their attendance
+	// looked up. Users in other timezones may be on another day.
+	var candidates []models.Reminder
+	var dates []string
+	for _, preference := range preferences {
+		shift := shifts.For(preference.UserID)
+		start, err := utils.ParseClock(shift.Start)
+		if err != nil {
+			continue
+		}
+		local := now.In(utils.ShiftLocation(shift))
+		minute := local.Hour()*60 + local.Minute()
+		if (start.Minutes()-preference.LeadMinutes+24*60)%(24*60) == minute {
+			candidates = append(candidates, models.Reminder{UserID: preference.UserID, Type: "check_in", Shift: shift})
+			dates = append(dates, local.Format("2006-01-02"))
+		}
+		if end, err := utils.ParseClock(shift.End); err == nil && end.Minutes() > start.Minutes() && end.Minutes() == minute {
+			candidates = append(candidates, models.Reminder{UserID: preference.UserID, Type: "check_out", Shift: shift})
+			dates = append(dates, local.Format("2006-01-02"))
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	first, last := slices.Min(dates), slices.Max(dates)
+	leaves, err := s.repo.GetApprovedLeaves(ctx, first, last)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approved leaves: %w", err)
+	}
+
+	var due []models.Reminder
+	for i, reminder := range candidates {
+		today := dates[i]
+		if onLeave(leaves, reminder.UserID, today) {
+			continue
+		}
+
+		status, err := s.repo.GetUserAttendanceStatus(ctx, reminder.UserID, today)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get attendance status: %w", err)
+		}
+		if reminder.Type == "check_in" && !status.HasCheckedIn ||
+			reminder.Type == "check_out" && status.HasCheckedIn && !status.HasCheckedOut {
+			due = append(due, reminder)
+		}
+	}
+
+	return due, nil
+}
+
+// onLeave reports whether one of the approved leaves covers the user on date
+func onLeave(leaves []models.Leave, userID int64, date string) bool {
+	for _, leave := range leaves {
+		if leave.UserID == userID && leave.StartDate <= date && date <= leave.EndDate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEnableRemindersLead(t *testing.T) {
+	tests := []struct {
+		lead int
+		want error
+	}{
+		{1, nil},
+		{DefaultReminderLead, nil},
+		{MaxReminderLead, nil},
+		{0, ErrInvalidReminderLead},
+		{-5, ErrInvalidReminderLead},
+		{MaxReminderLead + 1, ErrInvalidReminderLead},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.lead), func(t *testing.T) {
+			service, _ := newTestService(t)
+			if err := service.EnableReminders(context.Background(), 1, tt.lead); !errors.Is(err, tt.want) {
+				t.Errorf("EnableReminders(%d) = %v, want %v", tt.lead, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestDueReminders runs DueReminders at several times of today for users on the 08:00-16:00
+// default shift: 1 reminds 15 minutes ahead and has not checked in, 2 reminds 30 minutes ahead and
+// checked in at 07:20, 3 has reminders off and 4 is on approved leave
+func TestDueReminders(t *testing.T) {
+	service, repo := newTestService(t)
+	ctx := context.Background()
+	now := utils.Now()
+	today := now.Format("2006-01-02")
+	at := func(hour, minute int) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, utils.Location)
+	}
+
+	if err := service.SaveShift(ctx, &models.Shift{Name: models.DefaultShiftName, Start: "08:00", End: "16:00"}); err != nil {
+		t.Fatalf("SaveShift: %v", err)
+	}
+	for userID, lead := range map[int64]int{1: 15, 2: 30, 4: 15} {
+		if err := service.EnableReminders(ctx, userID, lead); err != nil {
+			t.Fatalf("EnableReminders: %v", err)
+		}
+	}
+	for _, userID := range []int64{2, 3, 4} {
+		if _, _, err := repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{
+			{UserID: userID, FirstName: "User", Timestamp: at(7, 20), Type: "check_in", Date: today},
+		}); err != nil {
+			t.Fatalf("InsertAttendanceBatch: %v", err)
+		}
+	}
+	leave, err := service.RequestLeave(ctx, &models.Leave{UserID: 4, FirstName: "User", Type: models.LeaveIzin, StartDate: today, EndDate: today})
+	if err != nil {
+		t.Fatalf("RequestLeave: %v", err)
+	}
+	if _, _, err := service.DecideLeave(ctx, leave.ID, true, 900); err != nil {
+		t.Fatalf("DecideLeave: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want []string // user:type of the due reminders
+	}{
+		{"30 minutes before the start", at(7, 30), nil},
+		{"15 minutes before the start", at(7, 45), []string{"1:check_in"}},
+		{"during the shift", at(12, 0), nil},
+		{"end of the shift", at(16, 0), []string{"2:check_out"}},
+		{"seconds into the minute", at(16, 0).Add(30 * time.Second), []string{"2:check_out"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due, err := service.DueReminders(ctx, tt.at)
+			if err != nil {
+				t.Fatalf("DueReminders: %v", err)
+			}
+			var got []string
+			for _, reminder := range due {
+				got = append(got, fmt.Sprintf("%d:%s", reminder.UserID, reminder.Type))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("DueReminders(%s) = %v, want %v", tt.at.Format("15:04:05"), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package attendance
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// defaultReportFreshness is how long a rendered daily report is reused
+const defaultReportFreshness = 30 * time.Second
+
+// reportMemo caches rendered reports by date and language for a short freshness window. Concurrent
+// requests for a report that is being computed wait for and share that computation.
+type reportMemo struct {
+	mu      sync.Mutex
+	window  time.Duration
+	entries map[reportKey]*reportCall
+	now     func() time.Time
+}
+
+// reportKey identifies a rendered report
+type reportKey struct {
+	date       string
+	department string
+	lang       string
+}
+
+// reportCall is one computation of a report, finished once done is closed
+type reportCall struct {
+	done     chan struct{}
+	report   string
+	err      error
+	computed time.Time
+}
+
+// newReportMemo creates a memo reusing reports for window; 0 only shares in-flight computations
+func newReportMemo(window time.Duration) *reportMemo {
+	return &reportMemo{
+		window:  window,
+		entries: make(map[reportKey]*reportCall),
+		now:     time.Now,
+	}
+}
+
+// do returns the report for date and department in lang, computing it only when there is no fresh
+// or in-flight result. Waiting for a computation in flight stops when ctx is done, and a
+// computation that panics fails with an error for everyone sharing it.
+func (m *reportMemo) do(ctx context.Context, date, department, lang string, compute func() (string, error)) (string, error) {
+	key := reportKey{date: date, department: department, lang: lang}
+	m.mu.Lock()
+	m.pruneLocked()
+
+	if call, ok := m.entries[key]; ok {
+		select {
+		case <-call.done:
+			if call.err == nil && m.now().Sub(call.computed) < m.window {
+				m.mu.Unlock()
+				return call.report, nil
+			}
+		default:
+			m.mu.Unlock()
+			select {
+			case <-call.done:
+				return call.report, call.err
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+	}
+
+	call := &reportCall{done: make(chan struct{})}
+	m.entries[key] = call
+	m.mu.Unlock()
+
+	m.compute(call, compute)
+	return call.report, call.err
+}
+
+// compute runs a report computation, always finishing the call so its waiters are released
+func (m *reportMemo) compute(call *reportCall, compute func() (string, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.report, call.err = "", fmt.Errorf("report computation panicked: %v\n%s", r, debug.Stack())
+		}
+		call.computed = m.now()
+		close(call.done)
+	}()
+
+	call.report, call.err = compute()
+}
+
+// invalidate drops the cached reports for date in every department and language, so the next request recomputes them
+func (m *reportMemo) invalidate(date string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.entries {
+		if key.date == date {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// invalidateAll drops every cached report, e.g. when settings shared by all days change
+func (m *reportMemo) invalidateAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clear(m.entries)
+}
+
+// pruneLocked drops finished entries past the freshness window; the caller holds the lock
+func (m *reportMemo) pruneLocked() {
+	for key, call := range m.entries {
+		select {
+		case <-call.done:
+			if m.now().Sub(call.computed) >= m.window {
+				delete(m.entries, key)
+			}
+		default:
+		}
+	}
+}
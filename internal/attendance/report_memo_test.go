@@ -0,0 +1,203 @@
+package attendance
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReportMemoReusesFreshReports(t *testing.T) {
+	memo := newReportMemo(time.Minute)
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	memo.now = func() time.Time { return now }
+
+	var computed int
+	compute := func() (string, error) {
+		computed++
+		return "report", nil
+	}
+
+	for range 3 {
+		if _, err := memo.do(context.Background(), "2024-03-01", "", "en", compute); err != nil {
+			t.Fatalf("do: %v", err)
+		}
+	}
+	if computed != 1 {
+		t.Fatalf("computed %d times within the window, want 1", computed)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := memo.do(context.Background(), "2024-03-01", "", "en", compute); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if computed != 2 {
+		t.Fatalf("computed %d times after the window, want 2", computed)
+	}
+}
+
+func TestReportMemoInvalidate(t *testing.T) {
+	memo := newReportMemo(time.Hour)
+
+	version := "old"
+	compute := func() (string, error) { return version, nil }
+	if _, err := memo.do(context.Background(), "2024-03-01", "", "en", compute); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	if _, err := memo.do(context.Background(), "2024-03-02", "", "en", compute); err != nil {
+		t.Fatalf("do: %v", err)
+	}
+
+	version = "new"
+	memo.invalidate("2024-03-01")
+
+	if report, _ := memo.do(context.Background(), "2024-03-01", "", "en", compute); report != "new" {
+		t.Errorf("invalidated date = %q, want %q", report, "new")
+	}
+	if report, _ := memo.do(context.Background(), "2024-03-02", "", "en", compute); report != "old" {
+		t.Errorf("other date = %q, want the cached %q", report, "old")
+	}
+
+	version = "newest"
+	memo.invalidateAll()
+	if report, _ := memo.do(context.Background(), "2024-03-02", "", "en", compute); report != "newest" {
+		t.Errorf("after invalidateAll = %q, want %q", report, "newest")
+	}
+}
+
+func TestReportMemoSharesComputation(t *testing.T) {
+	memo := newReportMemo(time.Minute)
+
+	release := make(chan struct{})
+	var computed atomic.Int32
+	compute := func() (string, error) {
+		computed.Add(1)
+		<-release
+		return "report", nil
+	}
+
+	const callers = 10
+	var started, wg sync.WaitGroup
+	reports := make([]string, callers)
+	started.Add(callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			reports[i], _ = memo.do(context.Background(), "2024-03-01", "", "en", compute)
+		}()
+	}
+	started.Wait()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := computed.Load(); n != 1 {
+		t.Errorf("computed %d times, want 1", n)
+	}
+	for i, report := range reports {
+		if report != "report" {
+			t.Errorf("caller %d got %q", i, report)
+		}
+	}
+}
+
+func TestReportMemoPanicReleasesWaiters(t *testing.T) {
+	memo := newReportMemo(time.Minute)
+
+	entered := make(chan struct{})
+	waiter := make(chan error, 1)
+	go func() {
+		<-entered
+		_, err := memo.do(context.Background(), "2024-03-01", "", "en", func() (string, error) {
+			return "", errors.New("waiter must not compute")
+		})
+		waiter <- err
+	}()
+
+	_, err := memo.do(context.Background(), "2024-03-01", "", "en", func() (string, error) {
+		close(entered)
+		time.Sleep(20 * time.Millisecond)
+		panic("boom")
+	})
+	if err == nil || !strings.Contains(err.Error(), "panicked: boom") {
+		t.Fatalf("computing caller error = %v, want the panic", err)
+	}
+
+	select {
+	case err := <-waiter:
+		if err == nil || strings.Contains(err.Error(), "must not compute") {
+			t.Errorf("waiter error = %v, want the shared panic", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter not released after the computation panicked")
+	}
+
+	report, err := memo.do(context.Background(), "2024-03-01", "", "en", func() (string, error) { return "recovered", nil })
+	if err != nil || report != "recovered" {
+		t.Errorf("after a panic = %q, %v; want a fresh computation", report, err)
+	}
+}
+
+func TestReportMemoWaiterContextCancelled(t *testing.T) {
+	memo := newReportMemo(time.Minute)
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	go memo.do(context.Background(), "2024-03-01", "", "en", func() (string, error) {
+		close(entered)
+		<-release
+		return "report", nil
+	})
+	<-entered
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := memo.do(ctx, "2024-03-01", "", "en", func() (string, error) { return "", nil })
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("waiter error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter kept waiting after its context ended")
+	}
+}
+
+func TestGenerateAttendanceReportInvalidatedOnImport(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	date := "2024-03-01"
+
+	before, err := service.GenerateAttendanceReportFor(ctx, date, "en")
+	if err != nil {
+		t.Fatalf("GenerateAttendanceReportFor: %v", err)
+	}
+	if strings.Contains(before, "Budi") {
+		t.Fatalf("report before the import already lists Budi:\n%s", before)
+	}
+
+	csv := "user_id,name,date,type,time\n1001,Budi Santoso,2024-03-01,check_in,08:00\n"
+	if _, err := service.ImportAttendance(ctx, strings.NewReader(csv), "test.csv", false, 1); err != nil {
+		t.Fatalf("ImportAttendance: %v", err)
+	}
+
+	after, err := service.GenerateAttendanceReportFor(ctx, date, "en")
+	if err != nil {
+		t.Fatalf("GenerateAttendanceReportFor: %v", err)
+	}
+	if !strings.Contains(after, "Budi") {
+		t.Errorf("report after the import does not list Budi, cached report served:\n%s", after)
+	}
+}
@@ -0,0 +1,91 @@
+package attendance
+
+import (
+	"sync"
+	"time"
+)
+
+// RotatingVerifier accepts codes from the current secret and, during a grace period after
+// a rotation, from the previous secret as well. It is safe for concurrent use.
+type RotatingVerifier struct {
+	mu         sync.RWMutex
+	current    *TOTPService
+	previous   *TOTPService
+	graceUntil time.Time
+	now        func() time.Time
+}
+
+// NewRotatingVerifier creates a verifier that also accepts the previous secret until rotatedAt+grace.
+// A nil previous service disables rotation and only the current secret is accepted.
+func NewRotatingVerifier(current, previous *TOTPService, rotatedAt time.Time, grace time.Duration) *RotatingVerifier {
+	return &RotatingVerifier{
+		current:    current,
+		previous:   previous,
+		graceUntil: rotatedAt.Add(grace),
+		now:        time.Now,
+	}
+}
+
+// InGracePeriod reports whether codes from the previous secret are still accepted
+func (v *RotatingVerifier) InGracePeriod() bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.previous != nil && v.now().Before(v.graceUntil)
+}
+
+// GraceUntil returns the moment the previous secret stops being accepted
+func (v *RotatingVerifier) GraceUntil() time.Time {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.graceUntil
+}
+
+// Check verifies the token against the current secret, falling back to the previous secret
+// while the grace period lasts
+func (v *RotatingVerifier) Check(token string) (VerifyResult, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	now := v.now()
+
+	result, err := v.current.checkAt(token, now.Unix())
+	if err != nil || result.Valid {
+		return result, err
+	}
+
+	if v.previous == nil || !now.Before(v.graceUntil) {
+		return result, nil
+	}
+
+	result, err = v.previous.checkAt(token, now.Unix())
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	result.PreviousSecret = result.Valid
+
+	return result, nil
+}
+
+// Current returns the service of the current secret
+func (v *RotatingVerifier) Current() *TOTPService {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.current
+}
+
+// Rotate makes next the current secret, accepting the replaced one until graceUntil
+func (v *RotatingVerifier) Rotate(next *TOTPService, graceUntil time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.previous = v.current
+	v.current = next
+	v.graceUntil = graceUntil
+}
+
+// Set replaces both secrets, accepting codes of previous until graceUntil
+func (v *RotatingVerifier) Set(current, previous *TOTPService, graceUntil time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.current = current
+	v.previous = previous
+	v.graceUntil = graceUntil
+}
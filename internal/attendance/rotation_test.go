@@ -0,0 +1,79 @@
+package attendance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRotatingVerifierGraceBoundary(t *testing.T) {
+	current := NewTOTPService("KRSXG5CTMVRXEZLU")
+	previous := NewTOTPService(testSecret)
+	rotatedAt := time.Unix(1_699_999_980, 0)
+	const grace = 7 * 24 * time.Hour
+	verifier := NewRotatingVerifier(current, previous, rotatedAt, grace)
+
+	if got := verifier.GraceUntil(); !got.Equal(rotatedAt.Add(grace)) {
+		t.Errorf("GraceUntil = %v, want %v", got, rotatedAt.Add(grace))
+	}
+
+	tests := []struct {
+		name          string
+		now           time.Time
+		previousValid bool
+	}{
+		{"at rotation", rotatedAt, true},
+		{"a second before the end", rotatedAt.Add(grace - time.Second), true},
+		{"at the end", rotatedAt.Add(grace), false},
+		{"after the end", rotatedAt.Add(grace + time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		verifier.now = func() time.Time { return tt.now }
+
+		if got := verifier.InGracePeriod(); got != tt.previousValid {
+			t.Errorf("%s: InGracePeriod = %v, want %v", tt.name, got, tt.previousValid)
+		}
+
+		code, _ := current.GenerateAt(tt.now)
+		result, err := verifier.Check(code)
+		if err != nil || !result.Valid || result.PreviousSecret {
+			t.Errorf("%s: current code = %+v, %v; want valid from the current secret", tt.name, result, err)
+		}
+
+		code, _ = previous.GenerateAt(tt.now)
+		result, err = verifier.Check(code)
+		if err != nil {
+			t.Fatalf("%s: Check: %v", tt.name, err)
+		}
+		if result.Valid != tt.previousValid || result.PreviousSecret != tt.previousValid {
+			t.Errorf("%s: previous code = %+v, want valid and from the previous secret %v", tt.name, result, tt.previousValid)
+		}
+	}
+}
+
+func TestRotatingVerifierWithoutPrevious(t *testing.T) {
+	verifier := NewRotatingVerifier(NewTOTPService(testSecret), nil, time.Now(), time.Hour)
+
+	if verifier.InGracePeriod() {
+		t.Error("InGracePeriod without a previous secret")
+	}
+	code, _ := NewTOTPService("KRSXG5CTMVRXEZLU").Generate()
+	if result, err := verifier.Check(code); err != nil || result.Valid {
+		t.Errorf("code of another secret = %+v, %v; want rejected", result, err)
+	}
+}
+
+func TestRotatingVerifierRotate(t *testing.T) {
+	first := NewTOTPService(testSecret)
+	second := NewTOTPService("KRSXG5CTMVRXEZLU")
+	verifier := NewRotatingVerifier(first, nil, time.Time{}, 0)
+
+	verifier.Rotate(second, time.Now().Add(time.Hour))
+	if verifier.Current() != second || !verifier.InGracePeriod() {
+		t.Fatalf("after Rotate current = %p, in grace %v; want the new secret in a grace period", verifier.Current(), verifier.InGracePeriod())
+	}
+	code, _ := first.Generate()
+	if result, _ := verifier.Check(code); !result.Valid || !result.PreviousSecret {
+		t.Errorf("code of the replaced secret = %+v, want accepted as the previous secret", result)
+	}
+}
@@ -0,0 +1,235 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// totpRotationKey is the bot_state key holding the secrets of the last /rotatesecret, encrypted
+// with SECRETS_ENCRYPTION_KEY
+const totpRotationKey = "totp_rotation"
+
+// rotationNoticeDays is how far back users must have recorded attendance to be sent the new
+// secret after a rotation
+const rotationNoticeDays = 90
+
+// ErrRotationInProgress indicates the previous secret of the last rotation is still accepted
+var ErrRotationInProgress = errors.New("secret rotation still in its grace period")
+
+// storedRotation is the persisted outcome of a rotation
+type storedRotation struct {
+	Secret     string    `json:"secret"`
+	Previous   string    `json:"previous"`
+	GraceUntil time.Time `json:"grace_until"`
+	Configured string    `json:"configured,omitempty"` // TOTP_SECRET when rotating, empty for older rotations
+}
+
+// configuredChanged reports whether TOTP_SECRET was changed since the rotation, meaning an admin
+// replaced the secret by hand. Rotations stored before Configured was recorded replaced
+// TOTP_SECRET when they are the first one, so it is compared with the replaced secret.
+func (r storedRotation) configuredChanged(configured string) bool {
+	if r.Configured != "" {
+		return r.Configured != configured
+	}
+	return r.Previous != configured && r.Secret != configured
+}
+
+// RotateSecret replaces the shared TOTP secret with a freshly generated one on an admin's behalf.
+// Codes of the replaced secret are accepted for grace, and the new secret is kept in the database
+// in place of TOTP_SECRET, where other replicas pick it up. It returns until when the replaced
+// secret is accepted.
+func (s *Service) RotateSecret(ctx context.Context, actorID int64, grace time.Duration) (time.Time, error) {
+	if s.cipher == nil {
+		return time.Time{}, ErrEncryptionKeyMissing
+	}
+
+	s.rotating.Lock()
+	defer s.rotating.Unlock()
+	sealed, err := s.repo.GetBotState(ctx, totpRotationKey)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get rotated secret: %w", err)
+	}
+	if err := s.useRotationLocked(sealed); err != nil {
+		return time.Time{}, err
+	}
+	if s.verifier.InGracePeriod() {
+		return s.verifier.GraceUntil(), ErrRotationInProgress
+	}
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	current := s.verifier.Current()
+	rotation := storedRotation{
+		Secret:     secret,
+		Previous:   current.secret,
+		GraceUntil: time.Now().Add(grace).UTC(),
+		Configured: s.totp.secret,
+	}
+	sealed, err = sealRotation(s.cipher, rotation)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := s.repo.SetBotState(ctx, totpRotationKey, sealed); err != nil {
+		return time.Time{}, fmt.Errorf("failed to save rotated secret: %w", err)
+	}
+
+	s.verifier.Rotate(current.WithSecret(secret), rotation.GraceUntil)
+	s.rotation = sealed
+	s.Audit(ctx, models.AuditEntry{
+		ActorID: actorID,
+		Action:  models.AuditTOTPRotated,
+		Details: "previous secret accepted until " + utils.FormatDate(rotation.GraceUntil, "yyyy-MM-dd") + " " +
+			utils.FormatTime(rotation.GraceUntil, "HH:mm"),
+	})
+	return rotation.GraceUntil, nil
+}
+
+// LoadRotatedSecret switches to the secrets of the last /rotatesecret, if any, replacing
+// TOTP_SECRET and TOTP_SECRET_PREVIOUS. It reports whether a rotated secret is used, and whether
+// one was discarded because TOTP_SECRET was changed since the rotation: an explicit change of the
+// configuration wins over the stored secret.
+func (s *Service) LoadRotatedSecret(ctx context.Context) (rotated, discarded bool, err error) {
+	sealed, err := s.repo.GetBotState(ctx, totpRotationKey)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to get rotated secret: %w", err)
+	}
+	if sealed == "" {
+		return false, false, nil
+	}
+	if s.cipher == nil {
+		return false, false, fmt.Errorf("%w: a secret rotated with /rotatesecret is stored", ErrEncryptionKeyMissing)
+	}
+
+	rotation, err := openRotation(s.cipher, sealed)
+	if err != nil {
+		return false, false, err
+	}
+	if rotation.configuredChanged(s.totp.secret) {
+		if err := s.repo.SetBotState(ctx, totpRotationKey, ""); err != nil {
+			return false, false, fmt.Errorf("failed to discard rotated secret: %w", err)
+		}
+		return false, true, nil
+	}
+
+	s.rotating.Lock()
+	defer s.rotating.Unlock()
+	if err := s.useRotationLocked(sealed); err != nil {
+		return false, false, err
+	}
+	return true, false, nil
+}
+
+// syncRotatedSecret reloads the stored rotation before codes are checked against the shared secret,
+// so a /rotatesecret run by any replica sharing the database takes effect on all of them
+func (s *Service) syncRotatedSecret(ctx context.Context) error {
+	sealed, err := s.repo.GetBotState(ctx, totpRotationKey)
+	if err != nil {
+		return fmt.Errorf("failed to get rotated secret: %w", err)
+	}
+
+	s.rotating.Lock()
+	defer s.rotating.Unlock()
+	return s.useRotationLocked(sealed)
+}
+
+// useRotationLocked switches the verifier to a stored rotation unless it already uses it. Nothing
+// changes when none is stored. The caller holds rotating.
+func (s *Service) useRotationLocked(sealed string) error {
+	if sealed == "" || sealed == s.rotation {
+		return nil
+	}
+	if s.cipher == nil {
+		return fmt.Errorf("%w: a secret rotated with /rotatesecret is stored", ErrEncryptionKeyMissing)
+	}
+
+	rotation, err := openRotation(s.cipher, sealed)
+	if err != nil {
+		return err
+	}
+
+	s.verifier.Set(s.totp.WithSecret(rotation.Secret), s.totp.WithSecret(rotation.Previous), rotation.GraceUntil)
+	s.rotation = sealed
+	return nil
+}
+
+// GetRotationRecipients returns the users to send the new secret after a rotation: those who
+// recorded attendance recently and verify with the shared secret rather than a personal code
+func (s *Service) GetRotationRecipients(ctx context.Context) ([]int64, error) {
+	since := utils.FormatDate(time.Now().AddDate(0, 0, -rotationNoticeDays), "yyyy-MM-dd")
+	return s.repo.GetSharedSecretUserIDs(ctx, since)
+}
+
+// reencryptRotation re-encrypts the stored rotation from the previous key to the current one,
+// returning false if there is none or it already uses the current key
+func (s *Service) reencryptRotation(ctx context.Context, previous *SecretCipher) (bool, error) {
+	sealed, err := s.repo.GetBotState(ctx, totpRotationKey)
+	if err != nil || sealed == "" {
+		return false, err
+	}
+	if _, err := openRotation(s.cipher, sealed); err == nil {
+		return false, nil
+	}
+
+	rotation, err := openRotation(previous, sealed)
+	if err != nil {
+		return false, fmt.Errorf("failed to decrypt rotated secret with previous key: %w", err)
+	}
+	if sealed, err = sealRotation(s.cipher, rotation); err != nil {
+		return false, err
+	}
+	if err := s.repo.SetBotState(ctx, totpRotationKey, sealed); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sealRotation encrypts a rotation as "nonce.ciphertext", both base64 encoded
+func sealRotation(cipher *SecretCipher, rotation storedRotation) (string, error) {
+	plaintext, err := json.Marshal(rotation)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode rotated secret: %w", err)
+	}
+
+	ciphertext, nonce, err := cipher.Encrypt(string(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(nonce) + "." + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// openRotation decrypts a rotation sealed by sealRotation
+func openRotation(cipher *SecretCipher, sealed string) (storedRotation, error) {
+	var rotation storedRotation
+
+	encodedNonce, encodedCiphertext, ok := strings.Cut(sealed, ".")
+	if !ok {
+		return rotation, fmt.Errorf("%w: malformed rotated secret", ErrInvalidSecret)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(encodedNonce)
+	if err != nil {
+		return rotation, fmt.Errorf("%w: malformed rotated secret: %v", ErrInvalidSecret, err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encodedCiphertext)
+	if err != nil {
+		return rotation, fmt.Errorf("%w: malformed rotated secret: %v", ErrInvalidSecret, err)
+	}
+
+	plaintext, err := cipher.Decrypt(ciphertext, nonce)
+	if err != nil {
+		return rotation, err
+	}
+	if err := json.Unmarshal([]byte(plaintext), &rotation); err != nil {
+		return rotation, fmt.Errorf("failed to decode rotated secret: %w", err)
+	}
+	return rotation, nil
+}
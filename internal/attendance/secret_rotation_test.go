@@ -0,0 +1,175 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newTestCipher creates a cipher with a random key
+func newTestCipher(t *testing.T) *SecretCipher {
+	t.Helper()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	cipher, err := NewSecretCipher(key)
+	if err != nil {
+		t.Fatalf("NewSecretCipher: %v", err)
+	}
+	return cipher
+}
+
+func TestRotateSecretReachesOtherReplicas(t *testing.T) {
+	repo := newTestRepository(t)
+	cipher := newTestCipher(t)
+	first := NewService(repo, NewTOTPService(testSecret))
+	second := NewService(repo, NewTOTPService(testSecret))
+	first.SetSecretCipher(cipher)
+	second.SetSecretCipher(cipher)
+
+	ctx := context.Background()
+	if _, err := first.RotateSecret(ctx, 1, time.Hour); err != nil {
+		t.Fatalf("RotateSecret: %v", err)
+	}
+	rotated := first.verifier.Current()
+	code, err := rotated.Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	if err := second.syncRotatedSecret(ctx); err != nil {
+		t.Fatalf("syncRotatedSecret: %v", err)
+	}
+	result, err := second.verifier.Check(code)
+	if err != nil || !result.Valid || result.PreviousSecret {
+		t.Errorf("second replica check of a code of the rotated secret = %+v, %v", result, err)
+	}
+
+	// The grace period of the first replica's rotation holds on the second one too
+	if _, err := second.RotateSecret(ctx, 1, time.Hour); err != ErrRotationInProgress {
+		t.Errorf("second rotation during the grace period = %v, want %v", err, ErrRotationInProgress)
+	}
+}
+
+func TestLoadRotatedSecretConfiguredChange(t *testing.T) {
+	repo := newTestRepository(t)
+	cipher := newTestCipher(t)
+	ctx := context.Background()
+
+	original := NewService(repo, NewTOTPService(testSecret))
+	original.SetSecretCipher(cipher)
+	if _, err := original.RotateSecret(ctx, 1, time.Hour); err != nil {
+		t.Fatalf("RotateSecret: %v", err)
+	}
+	rotatedSecret := original.verifier.Current().secret
+
+	// Restarting with the same TOTP_SECRET keeps the rotated secret
+	restarted := NewService(repo, NewTOTPService(testSecret))
+	restarted.SetSecretCipher(cipher)
+	rotated, discarded, err := restarted.LoadRotatedSecret(ctx)
+	if err != nil || !rotated || discarded {
+		t.Fatalf("LoadRotatedSecret with the same TOTP_SECRET = %v, %v, %v; want rotated", rotated, discarded, err)
+	}
+	if got := restarted.verifier.Current().secret; got != rotatedSecret {
+		t.Errorf("current secret = %q, want the rotated %q", got, rotatedSecret)
+	}
+
+	// Restarting with a new TOTP_SECRET discards it
+	const configured = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	changed := NewService(repo, NewTOTPService(configured))
+	changed.SetSecretCipher(cipher)
+	rotated, discarded, err = changed.LoadRotatedSecret(ctx)
+	if err != nil || rotated || !discarded {
+		t.Fatalf("LoadRotatedSecret with a new TOTP_SECRET = %v, %v, %v; want discarded", rotated, discarded, err)
+	}
+	if got := changed.verifier.Current().secret; got != configured {
+		t.Errorf("current secret = %q, want the configured %q", got, configured)
+	}
+	if sealed, err := repo.GetBotState(ctx, totpRotationKey); err != nil || sealed != "" {
+		t.Errorf("stored rotation = %q, %v; want it cleared", sealed, err)
+	}
+}
+
+func TestStoredRotationConfiguredChanged(t *testing.T) {
+	tests := []struct {
+		name       string
+		rotation   storedRotation
+		configured string
+		want       bool
+	}{
+		{"same configured secret", storedRotation{Secret: "B", Previous: "A", Configured: "A"}, "A", false},
+		{"new configured secret", storedRotation{Secret: "B", Previous: "A", Configured: "A"}, "C", true},
+		{"second rotation", storedRotation{Secret: "C", Previous: "B", Configured: "A"}, "A", false},
+		{"older rotation unchanged", storedRotation{Secret: "B", Previous: "A"}, "A", false},
+		{"older rotation copied to the configuration", storedRotation{Secret: "B", Previous: "A"}, "B", false},
+		{"older rotation changed", storedRotation{Secret: "B", Previous: "A"}, "C", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.rotation.configuredChanged(tc.configured); got != tc.want {
+				t.Errorf("configuredChanged(%q) = %v, want %v", tc.configured, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestGetRotationRecipients picks who is sent the new secret after a rotation: users with
+// attendance in the last rotationNoticeDays days who are not enrolled in counter-based codes
+func TestGetRotationRecipients(t *testing.T) {
+	type attended struct {
+		userID  int64
+		daysAgo int
+	}
+
+	tests := []struct {
+		name     string
+		attended []attended
+		hotp     []int64
+		want     []int64
+	}{
+		{name: "nobody attended"},
+		{name: "recent users in ID order", attended: []attended{{3, 0}, {1, 1}, {2, rotationNoticeDays}}, want: []int64{1, 2, 3}},
+		{name: "several records of a user", attended: []attended{{1, 0}, {1, 1}, {1, 2}}, want: []int64{1}},
+		{name: "too long ago", attended: []attended{{1, 0}, {2, rotationNoticeDays + 1}}, want: []int64{1}},
+		{name: "enrolled in HOTP", attended: []attended{{1, 0}, {2, 0}}, hotp: []int64{2}, want: []int64{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService(t)
+			ctx := context.Background()
+
+			records := make([]models.AttendanceRecord, len(tt.attended))
+			for i, a := range tt.attended {
+				timestamp := time.Now().In(utils.Location).AddDate(0, 0, -a.daysAgo)
+				records[i] = models.AttendanceRecord{
+					UserID: a.userID, FirstName: fmt.Sprintf("User%d", a.userID), Timestamp: timestamp,
+					Type: "check_in", Date: timestamp.Format("2006-01-02"),
+				}
+			}
+			if len(records) > 0 {
+				if _, _, err := repo.InsertAttendanceBatch(ctx, records); err != nil {
+					t.Fatalf("InsertAttendanceBatch: %v", err)
+				}
+			}
+			for _, userID := range tt.hotp {
+				enrollTestHOTP(t, service, userID)
+			}
+
+			recipients, err := service.GetRotationRecipients(ctx)
+			if err != nil {
+				t.Fatalf("GetRotationRecipients: %v", err)
+			}
+			if fmt.Sprint(recipients) != fmt.Sprint(tt.want) {
+				t.Errorf("GetRotationRecipients = %v, want %v", recipients, tt.want)
+			}
+		})
+	}
+}
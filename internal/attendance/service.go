@@ -1,59 +1,398 @@
 package attendance
 
 import (
+	"attendance-bot/internal/backup"
 	"attendance-bot/internal/database"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/metrics"
 	"attendance-bot/internal/utils"
 	"attendance-bot/pkg/models"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// ErrInvalidDateRange is returned for a malformed date or a range whose start is after its end
+var ErrInvalidDateRange = errors.New("invalid date range")
+
 // Service handles attendance business logic
 type Service struct {
-	repo *database.Repository
-	totp *TOTPService
+	repo     database.Repository
+	totp     *TOTPService
+	hotp     *HOTPService
+	verifier *RotatingVerifier
+	rotating sync.Mutex // Serializes /rotatesecret and reloading its stored outcome
+	rotation string     // Stored rotation the verifier uses, "" for the configured secrets; guarded by rotating
+	cipher   *SecretCipher
+	aliases  *aliasCache
+	reports  *reportMemo
+	expected time.Duration // Expected working time per day, 0 when not configured
+	recorded []func(record *models.AttendanceRecord)
+
+	photoWindow time.Duration // Time after a check-in a selfie may be attached, 0 when photos are off
+
+	geofenceMode string         // How check-ins are judged against the geofences, see Geofence*
+	locations    *locationStore // Locations users shared for their next attendance
+
+	otpLimiter *otpLimiter // Locks users out after too many rejected OTPs, nil when disabled
+
+	requireRegistration bool // Only users in the employee directory may mark attendance
+
+	overtimeThreshold time.Duration // Shortest time past the end of the shift recorded as overtime
+	maxBreaks         int           // Breaks a user may take a day, 0 when /break is off
+
+	fixedHolidays map[string]bool // Dates of the HOLIDAYS setting
+	holidayFeed   HolidayFeed     // Source of /holiday import, nil when disabled
+
+	wfhApproval bool // Requests to work from home wait for an admin's approval
+
+	backups *backup.Manager // Makes database backups, nil when the database is not backed up
 }
 
 // AttendanceResult represents the result of an attendance operation
 type AttendanceResult struct {
-	Success bool                     `json:"success"`
-	Message string                   `json:"message"`
-	Record  *models.AttendanceRecord `json:"record,omitempty"`
+	Success        bool                     `json:"success"`
+	Message        string                   `json:"message"`
+	Record         *models.AttendanceRecord `json:"record,omitempty"`
+	SkewOffset     int                      `json:"-"` // Time step offset of the matched OTP, for clock drift diagnostics
+	PreviousSecret bool                     `json:"-"` // The OTP matched the previous secret during a rotation grace period
+	OTPRejected    bool                     `json:"-"` // The OTP was well-formed but failed verification
+	Bypass         bool                     `json:"-"` // Attendance was verified with an admin-issued bypass code
+	LockedOut      bool                     `json:"-"` // The attempt was refused because the user is locked out
+	Lockout        *models.OTPLockout       `json:"-"` // The rejected OTP locked the user out
 }
 
 // NewService creates a new attendance service
-func NewService(repo *database.Repository, totpSecret string) *Service {
+func NewService(repo database.Repository, totp *TOTPService) *Service {
 	return &Service{
-		repo: repo,
-		totp: NewTOTPService(totpSecret),
+		repo:     repo,
+		totp:     totp,
+		hotp:     NewHOTPService(totp.Algorithm(), totp.Digits(), DefaultHOTPWindow),
+		verifier: NewRotatingVerifier(totp, nil, time.Time{}, 0),
+		aliases:  newAliasCache(aliasCacheSize, aliasCacheTTL),
+		reports:  newReportMemo(defaultReportFreshness),
+
+		geofenceMode: GeofenceOff,
+		locations:    newLocationStore(),
+
+		overtimeThreshold: DefaultOvertimeThreshold,
+		maxBreaks:         DefaultMaxBreaks,
+	}
+}
+
+// SetExpectedWorkHours sets the working time expected per day, used to show the time remaining
+func (s *Service) SetExpectedWorkHours(expected time.Duration) {
+	s.expected = expected
+}
+
+// AddAttendanceHook adds a function called after each successfully recorded attendance.
+// It runs on the caller's goroutine and must not block.
+func (s *Service) AddAttendanceHook(hook func(record *models.AttendanceRecord)) {
+	s.recorded = append(s.recorded, hook)
+}
+
+// notifyRecorded passes a new record to the attendance hooks
+func (s *Service) notifyRecorded(record *models.AttendanceRecord) {
+	for _, hook := range s.recorded {
+		hook(record)
+	}
+}
+
+// SetReportFreshness sets how long a rendered daily report is reused before it is regenerated
+func (s *Service) SetReportFreshness(window time.Duration) {
+	s.reports = newReportMemo(window)
+}
+
+// SetSecretCipher configures the cipher used to encrypt per-user secrets at rest
+func (s *Service) SetSecretCipher(cipher *SecretCipher) {
+	s.cipher = cipher
+}
+
+// CheckSecretsEncryption fails when encrypted per-user secrets exist but no key is configured
+func (s *Service) CheckSecretsEncryption(ctx context.Context) error {
+	if s.cipher != nil {
+		return nil
+	}
+
+	count, err := s.repo.CountUserSecrets(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: %d encrypted user secrets exist", ErrEncryptionKeyMissing, count)
+	}
+
+	return nil
+}
+
+// ReencryptSecrets re-encrypts every stored user secret, and a rotated shared secret, from the
+// previous key to the current one
+func (s *Service) ReencryptSecrets(ctx context.Context, previous *SecretCipher) (int, error) {
+	if s.cipher == nil {
+		return 0, ErrEncryptionKeyMissing
+	}
+
+	secrets, err := s.repo.ListUserSecrets(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var updated []models.UserSecret
+	for _, secret := range secrets {
+		// Rows already sealed with the current key are left untouched
+		if _, err := s.cipher.Decrypt(secret.Ciphertext, secret.Nonce); err == nil {
+			continue
+		}
+
+		plaintext, err := previous.Decrypt(secret.Ciphertext, secret.Nonce)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decrypt secret for user %d with previous key: %w", secret.UserID, err)
+		}
+
+		ciphertext, nonce, err := s.cipher.Encrypt(plaintext)
+		if err != nil {
+			return 0, err
+		}
+		updated = append(updated, models.UserSecret{UserID: secret.UserID, Ciphertext: ciphertext, Nonce: nonce})
+	}
+
+	if len(updated) > 0 {
+		if err := s.repo.UpdateUserSecrets(ctx, updated); err != nil {
+			return 0, err
+		}
+	}
+
+	// The secrets of a /rotatesecret are sealed with the same key
+	rotated, err := s.reencryptRotation(ctx, previous)
+	if err != nil {
+		return 0, err
+	}
+	if rotated {
+		return len(updated) + 1, nil
+	}
+
+	return len(updated), nil
+}
+
+// loadUserSecret decrypts the user's stored secret into the enrollment
+func (s *Service) loadUserSecret(ctx context.Context, enrollment *models.HOTPEnrollment) error {
+	if s.cipher == nil {
+		return ErrEncryptionKeyMissing
+	}
+
+	secret, err := s.repo.GetUserSecret(ctx, enrollment.UserID)
+	if err != nil {
+		return err
 	}
+	if secret == nil {
+		return fmt.Errorf("%w: no secret stored for user %d", ErrInvalidSecret, enrollment.UserID)
+	}
+
+	plaintext, err := s.cipher.Decrypt(secret.Ciphertext, secret.Nonce)
+	if err != nil {
+		return err
+	}
+
+	enrollment.Secret = plaintext
+	return nil
+}
+
+// SetSecretRotation keeps accepting codes from the previous secret until rotatedAt+grace
+func (s *Service) SetSecretRotation(previous *TOTPService, rotatedAt time.Time, grace time.Duration) {
+	s.verifier = NewRotatingVerifier(s.totp, previous, rotatedAt, grace)
+}
+
+// CheckTOTP reports whether codes can be checked against the current secret, which /rotatesecret
+// may have replaced since start-up
+func (s *Service) CheckTOTP() error {
+	return s.verifier.Current().Validate()
+}
+
+// OTPDigits returns the number of digits expected in an attendance OTP
+func (s *Service) OTPDigits() int {
+	return s.totp.Digits()
+}
+
+// OTPPeriod returns how long a TOTP code is valid
+func (s *Service) OTPPeriod() time.Duration {
+	return time.Duration(s.totp.Period()) * time.Second
 }
 
 // MarkAttendance processes an attendance request
-func (s *Service) MarkAttendance(userID int64, username, firstName string, lastName *string, otp string) (*AttendanceResult, error) {
+func (s *Service) MarkAttendance(ctx context.Context, userID int64, username, firstName string, lastName *string, otp string) (*AttendanceResult, error) {
+	result, err := s.markAttendance(ctx, userID, username, firstName, lastName, otp)
+	s.observeMark(result, err)
+	return result, err
+}
+
+// observeMark counts an attempt to mark attendance and passes a new record to the attendance hooks
+func (s *Service) observeMark(result *AttendanceResult, err error) {
+	metrics.AttendanceMarks.Inc(markOutcome(result, err))
+	if err == nil && result.Success && result.Record != nil {
+		s.notifyRecorded(result.Record)
+	}
+}
+
+// markOutcome classifies a MarkAttendance result for metrics
+func markOutcome(result *AttendanceResult, err error) string {
+	switch {
+	case err != nil:
+		return "error"
+	case result.OTPRejected:
+		return "invalid_otp"
+	case result.LockedOut:
+		return "locked_out"
+	case !result.Success:
+		return "refused"
+	default:
+		return "success"
+	}
+}
+
+// markAttendance verifies the OTP and records the user's next attendance
+func (s *Service) markAttendance(ctx context.Context, userID int64, username, firstName string, lastName *string, otp string) (*AttendanceResult, error) {
+	logger := logging.FromContext(ctx)
+	lang := i18n.FromContext(ctx)
+
 	// Validate OTP
-	if !utils.ValidateOTP(otp) {
+	if !utils.ValidateOTP(otp, s.totp.Digits()) {
 		return &AttendanceResult{
 			Success: false,
-			Message: "❌ Format OTP tidak valid. Harap masukkan 6 digit angka.",
+			Message: i18n.T(lang, "otp.invalid_format", "Digits", s.totp.Digits()),
 		}, nil
 	}
 
-	// Verify TOTP
-	if !s.totp.Verify(otp) {
-		return &AttendanceResult{
-			Success: false,
-			Message: "❌ Kode OTP tidak valid atau sudah kedaluwarsa. Silakan coba dengan kode yang baru.",
-		}, nil
+	if refusal, err := s.checkRegistration(ctx, userID); refusal != nil || err != nil {
+		return refusal, err
 	}
 
-	// Get current date and time
-	now := utils.NowInJakarta()
-	dateKey := utils.FormatDate(now, "yyyy-MM-dd")
+	// Locked out users are refused before their code is looked at, so it cannot be guessed
+	if refusal, err := s.checkOTPLockout(ctx, userID); refusal != nil || err != nil {
+		return refusal, err
+	}
+
+	record := &models.AttendanceRecord{
+		UserID:    userID,
+		Username:  username,
+		FirstName: firstName,
+		LastName:  lastName,
+		Source:    models.SourceOTP,
+	}
+
+	// Checked before the code so a refused check-in leaves a personal HOTP code unused
+	refusal, err := s.applyLocation(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	if refusal != "" {
+		return &AttendanceResult{Success: false, Message: refusal}, nil
+	}
+
+	// A bypass code issued by an admin replaces the user's authenticator once
+	bypass, err := s.findBypassCode(ctx, userID, otp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bypass code: %w", err)
+	}
+	if bypass != nil {
+		if reason := bypassRefusal(bypass, time.Now()); reason != "" {
+			logger.Warn("Bypass code rejected", "audit", "bypass_rejected", "bypass_id", bypass.ID, "reason", reason)
+			return &AttendanceResult{Success: false, Message: i18n.T(lang, "bypass."+reason)}, nil
+		}
+	}
+
+	// Verify the OTP using the user's enrollment type
+	enrollment, err := s.repo.GetHOTPEnrollment(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hotp enrollment: %w", err)
+	}
+
+	var verification VerifyResult
+	var advance *hotpAdvance
+	if bypass != nil {
+		verification.Valid = true
+	} else if enrollment != nil {
+		advance, err = s.matchHOTPEnrollment(ctx, enrollment, otp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify hotp: %w", err)
+		}
+		verification.Valid = advance != nil
+	} else {
+		if err := s.syncRotatedSecret(ctx); err != nil {
+			return nil, err
+		}
+		verification, err = s.verifier.Check(otp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify totp: %w", err)
+		}
+	}
+
+	if !verification.Valid {
+		logger.Debug("OTP rejected", "enrollment", enrollmentKind(enrollment))
+		result := &AttendanceResult{
+			Success:     false,
+			Message:     i18n.T(lang, "otp.rejected"),
+			OTPRejected: true,
+		}
+		if err := s.countOTPFailure(ctx, userID, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	s.clearOTPFailures(ctx, userID)
+
+	if bypass != nil {
+		record.Source = models.SourceBypass
+	}
+
+	result, err := s.recordNextAttendance(ctx, record, bypass, advance)
+	if err != nil || !result.Success {
+		return result, err
+	}
+
+	if bypass != nil {
+		logger.Warn("Bypass code used", "audit", "bypass_used", "bypass_id", bypass.ID, "issued_by", bypass.IssuedBy, "record_id", result.Record.ID)
+		result.Message += "\n\n" + i18n.T(lang, "bypass.recorded")
+	}
+
+	// Nudge users still on the old secret to re-scan before the grace period ends
+	if verification.PreviousSecret {
+		result.Message += "\n\n" + i18n.T(lang, "otp.previous_secret",
+			"Until", utils.FormatDateIn(s.verifier.GraceUntil(), "dd MMMM yyyy", lang))
+	}
+
+	result.SkewOffset = verification.Offset
+	result.PreviousSecret = verification.PreviousSecret
+	result.Bypass = bypass != nil
+	return result, nil
+}
+
+// recordNextAttendance records the user's next attendance of the day: a check-in, or a
+// check-out once checked in. The record's identity and source must be set; its time, type and
+// date are filled in. A bypass code or the HOTP counter advance of the matched code, if not nil, is
+// redeemed in the same transaction, so it stays unused when nothing is recorded. The result is
+// unsuccessful if both were recorded already, or if a concurrent request recorded the same
+// attendance or used the same code first.
+func (s *Service) recordNextAttendance(ctx context.Context, record *models.AttendanceRecord, bypass *models.BypassCode, advance *hotpAdvance) (*AttendanceResult, error) {
+	logger := logging.FromContext(ctx)
+	lang := i18n.FromContext(ctx)
+
+	// Get current date and time, in the timezone the user works in
+	now, err := s.UserNow(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	location := now.Location()
+	dateKey := now.Format("2006-01-02")
 
 	// Check current attendance status
-	status, err := s.repo.GetUserAttendanceStatus(userID, dateKey)
+	status, err := s.repo.GetUserAttendanceStatus(ctx, record.UserID, dateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get attendance status: %w", err)
 	}
@@ -65,40 +404,71 @@ func (s *Service) MarkAttendance(userID int64, username, firstName string, lastN
 	if !status.HasCheckedIn {
 		// First attendance of the day - check in
 		attendanceType = "check_in"
-		timeStr := utils.FormatTime(now, "HH:mm")
-		message = fmt.Sprintf("✅ **Absen Masuk** tercatat!\n⏰ Waktu: %s", timeStr)
+		message = i18n.T(lang, "attendance.checked_in", "Time", utils.FormatTimeIn(now, "HH:mm", location))
 	} else if !status.HasCheckedOut {
 		// Second attendance of the day - check out
 		attendanceType = "check_out"
-		checkInTime := status.CheckInRecord.Timestamp
-		timeStr := utils.FormatTime(now, "HH:mm")
-		workDuration := utils.CalculateWorkDuration(checkInTime, now)
-		message = fmt.Sprintf("🏠 **Absen Pulang** tercatat!\n⏰ Waktu: %s\n⌛ Durasi kerja: %s", timeStr, workDuration)
+		message = i18n.T(lang, "attendance.checked_out",
+			"Time", utils.FormatTimeIn(now, "HH:mm", location),
+			"Duration", utils.CalculateWorkDuration(status.CheckInRecord.Timestamp, now, status.BreakTime(now), lang))
+
+		record.OvertimeMinutes, err = s.overtimeMinutes(ctx, record.UserID, status.CheckInRecord.Timestamp, now)
+		if err != nil {
+			return nil, err
+		}
+		if record.OvertimeMinutes > 0 {
+			message += "\n" + i18n.T(lang, "attendance.overtime",
+				"Overtime", utils.FormatDuration(time.Duration(record.OvertimeMinutes)*time.Minute, lang))
+		}
 	} else {
 		// Both check-in and check-out already done
+		logger.Debug("Attendance already complete", "date", dateKey)
 		return &AttendanceResult{
 			Success: false,
-			Message: "❌ Anda sudah absen lengkap hari ini (masuk dan pulang)!",
+			Message: i18n.T(lang, "attendance.complete"),
 		}, nil
 	}
 
-	// Create attendance record
-	record := &models.AttendanceRecord{
-		UserID:    userID,
-		Username:  username,
-		FirstName: firstName,
-		LastName:  lastName,
-		Timestamp: now,
-		Type:      attendanceType,
-		Date:      dateKey,
+	// Stored timestamps stay in utils.Location so they sort as text; only the date is the user's
+	record.Timestamp = now.In(utils.Location)
+	record.Type = attendanceType
+	record.Date = dateKey
+
+	// Attendance on a day approved for working from home is marked remote
+	record.Remote, err = s.worksRemotely(ctx, record.UserID, dateKey)
+	if err != nil {
+		return nil, err
 	}
 
-	// Insert into database
-	savedRecord, err := s.repo.InsertAttendance(record)
+	// Insert into database, redeeming the bypass code or HOTP code in the same transaction
+	var savedRecord *models.AttendanceRecord
+	if bypass != nil {
+		savedRecord, err = s.repo.RedeemBypassCode(ctx, bypass.ID, record, time.Now())
+		if errors.Is(err, database.ErrNotFound) {
+			// Used or expired between the check above and now
+			logger.Warn("Bypass code rejected", "audit", "bypass_rejected", "bypass_id", bypass.ID, "reason", "used")
+			return &AttendanceResult{Success: false, Message: i18n.T(lang, "bypass.used")}, nil
+		}
+	} else if advance != nil {
+		savedRecord, err = s.repo.InsertAttendanceAdvancingHOTP(ctx, record, advance.expected, advance.next)
+		if errors.Is(err, database.ErrNotFound) {
+			// A concurrent request used this code or a later one first
+			logger.Info("HOTP code used concurrently", "counter", advance.expected)
+			return &AttendanceResult{Success: false, Message: i18n.T(lang, "otp.rejected"), OTPRejected: true}, nil
+		}
+	} else {
+		savedRecord, err = s.repo.InsertAttendance(ctx, record)
+	}
+	if errors.Is(err, database.ErrDuplicate) {
+		// A concurrent message, e.g. the same code sent twice, recorded this attendance first
+		return s.duplicateAttendance(ctx, record, location)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to save attendance: %w", err)
 	}
 
+	s.reports.invalidate(dateKey)
+	logger.Info("Attendance recorded", "type", attendanceType, "date", dateKey, "record_id", savedRecord.ID, "source", record.Source)
 	return &AttendanceResult{
 		Success: true,
 		Message: message,
@@ -106,63 +476,409 @@ func (s *Service) MarkAttendance(userID int64, username, firstName string, lastN
 	}, nil
 }
 
+// duplicateAttendance describes the attendance a concurrent request recorded before record could
+// be, with its time in location. The status check in recordNextAttendance and the insert are not
+// atomic; the UNIQUE constraint on (user_id, date, type) settles the race on both engines, and the
+// bypass code, if any, stays unused.
+func (s *Service) duplicateAttendance(ctx context.Context, record *models.AttendanceRecord, location *time.Location) (*AttendanceResult, error) {
+	lang := i18n.FromContext(ctx)
+
+	status, err := s.repo.GetUserAttendanceStatus(ctx, record.UserID, record.Date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance status: %w", err)
+	}
+
+	existing := status.CheckInRecord
+	if record.Type == "check_out" {
+		existing = status.CheckOutRecord
+	}
+	recordedAt := record.Timestamp
+	if existing != nil {
+		recordedAt = existing.Timestamp
+	}
+
+	logging.FromContext(ctx).Info("Duplicate attendance refused", "type", record.Type, "date", record.Date)
+	return &AttendanceResult{
+		Success: false,
+		Message: i18n.T(lang, "attendance.duplicate",
+			"Type", i18n.T(lang, "attendance.type."+record.Type),
+			"Time", utils.FormatTimeIn(recordedAt, "HH:mm", location)),
+	}, nil
+}
+
+// enrollmentKind names the OTP scheme a user is verified against, for logging
+func enrollmentKind(enrollment *models.HOTPEnrollment) string {
+	if enrollment != nil {
+		return "hotp"
+	}
+	return "totp"
+}
+
+// VerifyHOTP checks a counter-based code for the user and advances their stored counter on success
+func (s *Service) VerifyHOTP(ctx context.Context, userID int64, code string) (bool, error) {
+	enrollment, err := s.repo.GetHOTPEnrollment(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get hotp enrollment: %w", err)
+	}
+	if enrollment == nil {
+		return false, fmt.Errorf("user %d is not enrolled in hotp: %w", userID, database.ErrNotFound)
+	}
+
+	return s.verifyHOTPEnrollment(ctx, enrollment, code)
+}
+
+// hotpAdvance moves a user's HOTP counter past a matched code, so the code can never be reused
+type hotpAdvance struct {
+	expected uint64 // Counter when the code was matched
+	next     uint64 // Counter after the matched code
+}
+
+// verifyHOTPEnrollment verifies the code against the enrollment and persists the resynchronized counter
+func (s *Service) verifyHOTPEnrollment(ctx context.Context, enrollment *models.HOTPEnrollment, code string) (bool, error) {
+	advance, err := s.matchHOTPEnrollment(ctx, enrollment, code)
+	if err != nil || advance == nil {
+		return false, err
+	}
+
+	advanced, err := s.repo.AdvanceHOTPCounter(ctx, enrollment.UserID, advance.expected, advance.next)
+	if err != nil {
+		return false, fmt.Errorf("failed to advance hotp counter: %w", err)
+	}
+
+	return advanced, nil
+}
+
+// matchHOTPEnrollment verifies the code against the enrollment without using it up, returning the
+// counter advance that does, or nil if the code does not match
+func (s *Service) matchHOTPEnrollment(ctx context.Context, enrollment *models.HOTPEnrollment, code string) (*hotpAdvance, error) {
+	if err := s.loadUserSecret(ctx, enrollment); err != nil {
+		return nil, err
+	}
+
+	next, ok, err := s.hotp.Verify(enrollment.Secret, code, enrollment.Counter)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &hotpAdvance{expected: enrollment.Counter, next: next}, nil
+}
+
+// EnrollHOTP switches a user to counter-based codes with a freshly generated secret
+func (s *Service) EnrollHOTP(ctx context.Context, userID int64) (*models.HOTPEnrollment, error) {
+	if s.cipher == nil {
+		return nil, ErrEncryptionKeyMissing
+	}
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, nonce, err := s.cipher.Encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment := &models.HOTPEnrollment{
+		UserID:  userID,
+		Secret:  secret,
+		Counter: 0,
+	}
+	userSecret := &models.UserSecret{
+		UserID:     userID,
+		Ciphertext: ciphertext,
+		Nonce:      nonce,
+	}
+
+	if err := s.repo.SetHOTPEnrollment(ctx, enrollment, userSecret); err != nil {
+		return nil, fmt.Errorf("failed to save hotp enrollment: %w", err)
+	}
+
+	return enrollment, nil
+}
+
+// UnenrollHOTP returns a user to time-based codes
+func (s *Service) UnenrollHOTP(ctx context.Context, userID int64) error {
+	return s.repo.DeleteHOTPEnrollment(ctx, userID)
+}
+
+// GenerateHOTPSheet returns the next count codes for a user's printed code sheet
+func (s *Service) GenerateHOTPSheet(ctx context.Context, userID int64, count int) ([]string, error) {
+	enrollment, err := s.repo.GetHOTPEnrollment(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hotp enrollment: %w", err)
+	}
+	if enrollment == nil {
+		return nil, fmt.Errorf("user %d is not enrolled in hotp: %w", userID, database.ErrNotFound)
+	}
+
+	if err := s.loadUserSecret(ctx, enrollment); err != nil {
+		return nil, err
+	}
+
+	return s.hotp.GenerateBatch(enrollment.Secret, enrollment.Counter, count)
+}
+
+// RecordFailedOTP stores a rejected OTP attempt and returns how many failures the user
+// has accumulated within the given window, including this one
+func (s *Service) RecordFailedOTP(ctx context.Context, userID int64, username, chatType, code string, window time.Duration) (int, error) {
+	now := time.Now()
+
+	failure := &models.FailedOTP{
+		UserID:    userID,
+		Username:  username,
+		ChatType:  chatType,
+		Code:      truncateCode(code),
+		Timestamp: now,
+	}
+
+	if err := s.repo.InsertFailedOTP(ctx, failure); err != nil {
+		return 0, err
+	}
+	err := s.repo.InsertAuditEntry(ctx, &models.AuditEntry{
+		CreatedAt:    now,
+		ActorID:      userID,
+		Action:       models.AuditOTPFailed,
+		TargetUserID: userID,
+		Details:      "chat: " + chatType,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return s.repo.CountFailedOTPsSince(ctx, userID, now.Add(-window))
+}
+
+// GetRecentFailedOTPs returns failed OTP attempts within the last given duration
+func (s *Service) GetRecentFailedOTPs(ctx context.Context, since time.Duration) ([]models.FailedOTP, error) {
+	return s.repo.GetFailedOTPsSince(ctx, time.Now().Add(-since))
+}
+
+// truncateCode keeps only the first two characters of a code so stored failures never leak a usable code
+func truncateCode(code string) string {
+	code = strings.TrimSpace(code)
+	if len(code) <= 2 {
+		return strings.Repeat("*", len(code))
+	}
+	return code[:2] + strings.Repeat("*", len(code)-2)
+}
+
 // GetUserAttendanceStatus returns a user's attendance status for today
-func (s *Service) GetUserAttendanceStatus(userID int64, date string) (*models.AttendanceStatus, error) {
-	return s.repo.GetUserAttendanceStatus(userID, date)
+func (s *Service) GetUserAttendanceStatus(ctx context.Context, userID int64, date string) (*models.AttendanceStatus, error) {
+	return s.repo.GetUserAttendanceStatus(ctx, userID, date)
+}
+
+// WorkProgress describes how long a user has been at work today
+type WorkProgress struct {
+	CheckedIn  bool
+	CheckedOut bool
+	CheckIn    time.Time
+	CheckOut   time.Time
+	Elapsed    time.Duration // Since check-in, up to check-out or now, less breaks
+	Breaks     time.Duration // Time on breaks, including one still going on
+	OnBreak    bool
+	Target     time.Duration // Expected working time, 0 when not configured
+	Remaining  time.Duration // Until Target is reached, 0 once it is
+}
+
+// GetWorkProgress returns the user's elapsed working time for the day of now, in the location now
+// carries
+func (s *Service) GetWorkProgress(ctx context.Context, userID int64, now time.Time) (*WorkProgress, error) {
+	status, err := s.repo.GetUserAttendanceStatus(ctx, userID, now.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attendance status: %w", err)
+	}
+
+	progress := &WorkProgress{Target: s.expected}
+	if !status.HasCheckedIn {
+		return progress, nil
+	}
+
+	progress.CheckedIn = true
+	progress.CheckIn = status.CheckInRecord.Timestamp
+	end := now
+	if status.HasCheckedOut {
+		progress.CheckedOut = true
+		progress.CheckOut = status.CheckOutRecord.Timestamp
+		end = progress.CheckOut
+	}
+
+	progress.Breaks = status.BreakTime(end)
+	progress.OnBreak = status.OnBreak()
+	progress.Elapsed = max(end.Sub(progress.CheckIn)-progress.Breaks, 0)
+	if progress.Target > progress.Elapsed {
+		progress.Remaining = progress.Target - progress.Elapsed
+	}
+
+	return progress, nil
 }
 
 // GetUserAttendanceHistory returns a user's attendance history
-func (s *Service) GetUserAttendanceHistory(userID int64, days int) ([]models.AttendanceRecord, error) {
-	return s.repo.GetUserAttendanceHistory(userID, days)
+func (s *Service) GetUserAttendanceHistory(ctx context.Context, userID int64, days int) ([]models.AttendanceRecord, error) {
+	return s.repo.GetUserAttendanceHistory(ctx, userID, days)
+}
+
+// GetUserAttendanceRange returns a user's attendance from startDate to endDate (YYYY-MM-DD), newest day first
+func (s *Service) GetUserAttendanceRange(ctx context.Context, userID int64, startDate, endDate string) ([]models.AttendanceRecord, error) {
+	start, err := utils.ParseDate(startDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: start date %q", ErrInvalidDateRange, startDate)
+	}
+	end, err := utils.ParseDate(endDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: end date %q", ErrInvalidDateRange, endDate)
+	}
+	if start.After(end) {
+		return nil, fmt.Errorf("%w: %s is after %s", ErrInvalidDateRange, startDate, endDate)
+	}
+
+	return s.repo.GetUserAttendanceRange(ctx, userID, startDate, endDate)
+}
+
+// GenerateAttendanceReport creates today's formatted attendance report in the given language.
+// Reports are reused within the freshness window, and recorded attendance invalidates the day's
+// report immediately.
+func (s *Service) GenerateAttendanceReport(ctx context.Context, lang string) (string, error) {
+	return s.GenerateAttendanceReportFor(ctx, utils.GetTodayDate(), lang)
+}
+
+// GenerateAttendanceReportFor creates the formatted attendance report of a date (YYYY-MM-DD)
+func (s *Service) GenerateAttendanceReportFor(ctx context.Context, date, lang string) (string, error) {
+	return s.GenerateDepartmentReport(ctx, date, "", lang)
 }
 
-// GenerateAttendanceReport creates a formatted daily attendance report
-func (s *Service) GenerateAttendanceReport() (string, error) {
-	today := utils.GetTodayDate()
-	records, err := s.repo.GetDailyReport(today)
+// GenerateDepartmentReport creates the formatted attendance report of a date limited to the users
+// of a department of the employee directory, or of everyone with a summary per department when
+// department is empty
+func (s *Service) GenerateDepartmentReport(ctx context.Context, date, department, lang string) (string, error) {
+	return s.reports.do(ctx, date, department, lang, func() (string, error) {
+		return s.generateAttendanceReport(ctx, date, department, lang)
+	})
+}
+
+// generateAttendanceReport renders the attendance report for the given date and department
+func (s *Service) generateAttendanceReport(ctx context.Context, today, department, lang string) (string, error) {
+	defer metrics.ReportDuration.ObserveSince(time.Now(), "daily")
+
+	records, err := s.repo.GetDailyReport(ctx, today)
 	if err != nil {
 		return "", fmt.Errorf("failed to get daily report: %w", err)
 	}
 
-	if len(records) == 0 {
-		return "📭 Belum ada yang absen hari ini.", nil
+	leaves, err := s.repo.GetApprovedLeaves(ctx, today, today)
+	if err != nil {
+		return "", fmt.Errorf("failed to get approved leaves: %w", err)
+	}
+
+	departments, err := s.departmentsByUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	if department != "" {
+		records = slices.DeleteFunc(records, func(record models.AttendanceRecord) bool {
+			return departments[record.UserID] != department
+		})
+		leaves = slices.DeleteFunc(leaves, func(leave models.Leave) bool {
+			return departments[leave.UserID] != department
+		})
+	}
+
+	shifts, err := s.GetShiftAssignments(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get shift assignments: %w", err)
+	}
+
+	// Check-ins are only judged against the geofences when some are defined
+	checkGeofences := false
+	if s.geofenceMode != GeofenceOff {
+		geofences, err := s.repo.GetGeofences(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get geofences: %w", err)
+		}
+		checkGeofences = len(geofences) > 0
 	}
 
 	// Group records by user to show check-in and check-out together
-	userRecords := make(map[int64]map[string]*models.AttendanceRecord)
-	for _, record := range records {
-		if userRecords[record.UserID] == nil {
-			userRecords[record.UserID] = make(map[string]*models.AttendanceRecord)
+	userRecords := models.GroupByDay(records)
+
+	// Users who came in despite their leave are listed with their attendance only
+	present := make(map[int64]bool, len(userRecords))
+	for _, day := range userRecords {
+		present[day.UserID] = true
+	}
+	absent := leaves[:0]
+	for _, leave := range leaves {
+		if !present[leave.UserID] {
+			absent = append(absent, leave)
 		}
-		userRecords[record.UserID][record.Type] = &record
+	}
+
+	isToday := today == utils.GetTodayDate()
+	if len(records) == 0 && len(absent) == 0 {
+		if department != "" {
+			return i18n.T(lang, "report.empty_department", "Department", department, "Date", today), nil
+		}
+		if !isToday {
+			return i18n.T(lang, "report.empty_date", "Date", today), nil
+		}
+		return i18n.T(lang, "report.empty_today"), nil
+	}
+
+	reportDate, err := utils.ParseDate(today)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse report date: %w", err)
 	}
 
 	// Build report message
 	var message strings.Builder
-	message.WriteString(fmt.Sprintf("📊 **Laporan Absensi Hari Ini**\n📅 %s\n\n",
-		utils.FormatDate(time.Now(), "dd MMMM yyyy")))
+	title := "report.title"
+	if isToday {
+		title = "report.title_today"
+	}
+	message.WriteString(i18n.T(lang, title, "Date", utils.FormatDateIn(reportDate, "EEEE, dd MMMM yyyy", lang)))
+	if department != "" {
+		message.WriteString(i18n.T(lang, "report.department", "Department", department))
+	}
+	message.WriteString("\n")
 
 	checkInCount := 0
 	checkOutCount := 0
+	autoCheckOutCount := 0
+	outsideCount := 0
+	remoteCount := 0
 	userIndex := 1
+	perDepartment := newDepartmentCounts()
 
-	for _, userRecs := range userRecords {
-		checkInRec := userRecs["check_in"]
-		checkOutRec := userRecs["check_out"]
+	for _, day := range userRecords {
+		checkInRec := day.CheckIn
+		checkOutRec := day.CheckOut
+		counts := perDepartment.of(departments[day.UserID])
+		counts.present++
 
 		if checkInRec != nil {
-			name := s.formatUserName(checkInRec)
+			name := s.formatUserName(ctx, checkInRec)
 			checkInTime := utils.FormatTime(checkInRec.Timestamp, "HH:mm")
 
-			message.WriteString(fmt.Sprintf("%d. **%s**\n", userIndex, name))
-			message.WriteString(fmt.Sprintf("   ⏰ Masuk: %s", checkInTime))
+			message.WriteString(fmt.Sprintf("%d. **%s**", userIndex, name))
+			if day.Record().Remote {
+				message.WriteString(" 🏡")
+				remoteCount++
+			}
+			message.WriteString("\n" + i18n.T(lang, "report.check_in", "Time", checkInTime))
 
-			// Add status indicator for late arrival (after 9:00 AM)
-			if checkInRec.Timestamp.Hour() >= 9 {
+			// Add status indicator for late arrival, judged by the user's shift
+			if utils.IsLate(checkInRec.Timestamp, shifts.For(day.UserID)) {
 				message.WriteString(" ⚠️")
+				counts.late++
 			} else {
 				message.WriteString(" ✅")
 			}
+			// Codes entered at the kiosk or by an admin carry no location of the user, and users
+			// working from home are not expected at the office
+			if checkGeofences && checkInRec.Geofence == "" && !checkInRec.Remote &&
+				(checkInRec.Source == models.SourceOTP || checkInRec.Source == models.SourceBypass) {
+				message.WriteString(i18n.T(lang, "report.outside_geofence"))
+				outsideCount++
+			}
 			message.WriteString("\n")
 
 			checkInCount++
@@ -171,47 +887,156 @@ func (s *Service) GenerateAttendanceReport() (string, error) {
 		if checkOutRec != nil {
 			if checkInRec == nil {
 				// Handle edge case where there's check-out but no check-in
-				name := s.formatUserName(checkOutRec)
-				message.WriteString(fmt.Sprintf("%d. **%s**\n", userIndex, name))
-				message.WriteString("   ⏰ Masuk: -\n")
+				name := s.formatUserName(ctx, checkOutRec)
+				message.WriteString(fmt.Sprintf("%d. **%s**", userIndex, name))
+				if checkOutRec.Remote {
+					message.WriteString(" 🏡")
+					remoteCount++
+				}
+				message.WriteString("\n")
+				message.WriteString(i18n.T(lang, "report.check_in", "Time", "-") + "\n")
 			}
 
 			checkOutTime := utils.FormatTime(checkOutRec.Timestamp, "HH:mm")
-			message.WriteString(fmt.Sprintf("   🏠 Pulang: %s\n", checkOutTime))
+			message.WriteString(i18n.T(lang, "report.check_out", "Time", checkOutTime))
+			if checkInRec != nil && utils.LeftEarly(checkInRec.Timestamp, checkOutRec.Timestamp, shifts.For(day.UserID)) {
+				message.WriteString(" ⏪")
+			}
+			// Check-outs the bot recorded for a forgotten one are marked apart from real ones
+			if checkOutRec.Source == models.SourceAuto {
+				message.WriteString(i18n.T(lang, "report.auto_checkout"))
+				autoCheckOutCount++
+			}
+			message.WriteString("\n")
 
 			// Calculate work duration if both check-in and check-out exist
 			if checkInRec != nil {
-				duration := utils.CalculateWorkDuration(checkInRec.Timestamp, checkOutRec.Timestamp)
-				message.WriteString(fmt.Sprintf("   ⌛ Durasi: %s\n", duration))
+				duration := utils.CalculateWorkDuration(checkInRec.Timestamp, checkOutRec.Timestamp, day.BreakTime(), lang)
+				message.WriteString(i18n.T(lang, "report.duration", "Duration", duration) + "\n")
 			}
 
 			checkOutCount++
 		} else if checkInRec != nil {
-			message.WriteString("   🏠 Pulang: -\n")
+			message.WriteString(i18n.T(lang, "report.check_out", "Time", "-") + "\n")
 		}
 
 		message.WriteString("\n")
 		userIndex++
 	}
 
+	if len(absent) > 0 {
+		message.WriteString(i18n.T(lang, "report.leave_heading") + "\n")
+		for i := range absent {
+			message.WriteString(fmt.Sprintf("• %s - %s\n", s.LeaveName(ctx, &absent[i]), i18n.T(lang, "leave.type."+absent[i].Type)))
+			perDepartment.of(departments[absent[i].UserID]).leave++
+		}
+		message.WriteString("\n")
+	}
+
 	// Add summary
-	message.WriteString("**Ringkasan:**\n")
-	message.WriteString(fmt.Sprintf("👥 Total Karyawan: %d\n", len(userRecords)))
-	message.WriteString(fmt.Sprintf("📝 Check-in: %d\n", checkInCount))
-	message.WriteString(fmt.Sprintf("🏠 Check-out: %d", checkOutCount))
+	message.WriteString(i18n.T(lang, "report.summary",
+		"Users", len(userRecords),
+		"CheckIns", checkInCount,
+		"CheckOuts", checkOutCount))
+	if len(absent) > 0 {
+		message.WriteString("\n" + i18n.T(lang, "report.summary_leave", "Count", len(absent)))
+	}
+	if autoCheckOutCount > 0 {
+		message.WriteString("\n" + i18n.T(lang, "report.summary_auto", "Count", autoCheckOutCount))
+	}
+	if outsideCount > 0 {
+		message.WriteString("\n" + i18n.T(lang, "report.summary_geofence", "Count", outsideCount))
+	}
+	if remoteCount > 0 {
+		message.WriteString("\n" + i18n.T(lang, "report.summary_remote", "Count", remoteCount))
+	}
+
+	// Departments are only summarized when someone in the report belongs to one
+	if department == "" && perDepartment.named() {
+		message.WriteString("\n\n" + i18n.T(lang, "report.department_heading"))
+		for _, name := range perDepartment.names() {
+			counts := perDepartment.counts[name]
+			if name == "" {
+				name = i18n.T(lang, "employee.no_department")
+			}
+			message.WriteString("\n" + i18n.T(lang, "report.department_entry",
+				"Department", name, "Present", counts.present, "Late", counts.late, "Leave", counts.leave))
+		}
+	}
+
+	return message.String(), nil
+}
+
+// GenerateOnShiftReport lists the users who checked in on the day of now and have not checked out,
+// with their check-in time and how long they have been on shift
+func (s *Service) GenerateOnShiftReport(ctx context.Context, now time.Time, lang string) (string, error) {
+	records, err := s.repo.GetOnShift(ctx, utils.FormatDate(now, "yyyy-MM-dd"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get on-shift users: %w", err)
+	}
+
+	if len(records) == 0 {
+		return i18n.T(lang, "who.empty"), nil
+	}
+
+	var message strings.Builder
+	message.WriteString(i18n.T(lang, "who.title", "Time", utils.FormatTime(now, "HH:mm")))
+
+	for i := range records {
+		record := &records[i]
+		elapsed := max(now.Sub(record.Timestamp), 0)
+		message.WriteString(fmt.Sprintf("%d. **%s**\n", i+1, s.formatUserName(ctx, record)))
+		message.WriteString(i18n.T(lang, "who.entry",
+			"Time", utils.FormatTime(record.Timestamp, "HH:mm"),
+			"Elapsed", utils.FormatDuration(elapsed, lang)) + "\n")
+	}
+
+	message.WriteString(i18n.T(lang, "who.total", "Count", len(records)))
 
 	return message.String(), nil
 }
 
 // SetUserAlias sets a custom display name for a user
-func (s *Service) SetUserAlias(userID int64, firstName string, lastName *string) error {
-	return s.repo.SetUserAlias(userID, firstName, lastName)
+func (s *Service) SetUserAlias(ctx context.Context, userID int64, firstName string, lastName *string) error {
+	defer s.aliases.invalidate(userID)
+	return s.repo.SetUserAlias(ctx, userID, firstName, lastName)
+}
+
+// DeleteUserAlias removes a user's custom display name, returning false if none existed
+func (s *Service) DeleteUserAlias(ctx context.Context, userID int64) (bool, error) {
+	defer s.aliases.invalidate(userID)
+	return s.repo.DeleteUserAlias(ctx, userID)
+}
+
+// GetUserAlias returns a user's alias, or nil if they have none
+func (s *Service) GetUserAlias(ctx context.Context, userID int64) (*models.UserAlias, error) {
+	return s.getUserAlias(ctx, userID)
+}
+
+// getUserAlias returns a user's alias, or nil if they have none, consulting the cache first
+func (s *Service) getUserAlias(ctx context.Context, userID int64) (*models.UserAlias, error) {
+	if alias, ok := s.aliases.get(userID); ok {
+		return alias, nil
+	}
+
+	alias, err := s.repo.GetUserAlias(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.aliases.set(userID, alias)
+	return alias, nil
+}
+
+// DisplayName returns the name shown for the record's user, preferring their alias
+func (s *Service) DisplayName(ctx context.Context, record *models.AttendanceRecord) string {
+	return s.formatUserName(ctx, record)
 }
 
 // formatUserName returns the display name for a user, preferring alias if available
-func (s *Service) formatUserName(record *models.AttendanceRecord) string {
+func (s *Service) formatUserName(ctx context.Context, record *models.AttendanceRecord) string {
 	// Try to get alias first
-	alias, err := s.repo.GetUserAlias(record.UserID)
+	alias, err := s.getUserAlias(ctx, record.UserID)
 	if err == nil && alias != nil {
 		if alias.LastName != nil && *alias.LastName != "" {
 			return fmt.Sprintf("%s %s", alias.FirstName, *alias.LastName)
@@ -226,7 +1051,109 @@ func (s *Service) formatUserName(record *models.AttendanceRecord) string {
 	return record.FirstName
 }
 
+// updateOffsetKey is the bot_state key holding the last handled Telegram update ID
+const updateOffsetKey = "last_update_id"
+
+// GetLastUpdateID returns the last Telegram update ID handled before the previous shutdown
+func (s *Service) GetLastUpdateID(ctx context.Context) (int64, error) {
+	value, err := s.repo.GetBotState(ctx, updateOffsetKey)
+	if err != nil || value == "" {
+		return 0, err
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid stored update offset %q: %w", value, err)
+	}
+	return id, nil
+}
+
+// SaveLastUpdateID persists the last handled Telegram update ID
+func (s *Service) SaveLastUpdateID(ctx context.Context, id int64) error {
+	return s.repo.SetBotState(ctx, updateOffsetKey, strconv.FormatInt(id, 10))
+}
+
+// GetMissingCheckouts returns the days between startDate and endDate on which a user checked in
+// but never checked out. Today is never included, since its check-outs may still arrive.
+// The end of the range is returned as used, after excluding today.
+func (s *Service) GetMissingCheckouts(ctx context.Context, startDate, endDate string) ([]models.MissingCheckout, string, error) {
+	start, err := utils.ParseDate(startDate)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: start date %q", ErrInvalidDateRange, startDate)
+	}
+	end, err := utils.ParseDate(endDate)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: end date %q", ErrInvalidDateRange, endDate)
+	}
+	if start.After(end) {
+		return nil, "", fmt.Errorf("%w: %s is after %s", ErrInvalidDateRange, startDate, endDate)
+	}
+
+	if yesterday := utils.AddDays(utils.Now(), -1).Format("2006-01-02"); endDate > yesterday {
+		endDate = yesterday
+	}
+	if startDate > endDate {
+		return nil, endDate, nil
+	}
+
+	records, err := s.repo.GetMissingCheckouts(ctx, startDate, endDate)
+	if err != nil {
+		return nil, "", err
+	}
+
+	missing := make([]models.MissingCheckout, 0, len(records))
+	for i := range records {
+		missing = append(missing, models.MissingCheckout{
+			Date:     records[i].Date,
+			UserID:   records[i].UserID,
+			Name:     s.formatUserName(ctx, &records[i]),
+			Username: records[i].Username,
+			CheckIn:  records[i].Timestamp,
+		})
+	}
+
+	return missing, endDate, nil
+}
+
+// liveReportKey is the bot_state key holding the day's pinned report message
+const liveReportKey = "live_report"
+
+// GetLiveReport returns the last pinned report message, or nil if none was posted
+func (s *Service) GetLiveReport(ctx context.Context) (*models.LiveReport, error) {
+	value, err := s.repo.GetBotState(ctx, liveReportKey)
+	if err != nil || value == "" {
+		return nil, err
+	}
+
+	var report models.LiveReport
+	if err := json.Unmarshal([]byte(value), &report); err != nil {
+		return nil, fmt.Errorf("invalid stored live report %q: %w", value, err)
+	}
+	return &report, nil
+}
+
+// SaveLiveReport persists the pinned report message so edits survive restarts
+func (s *Service) SaveLiveReport(ctx context.Context, report *models.LiveReport) error {
+	value, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal live report: %w", err)
+	}
+	return s.repo.SetBotState(ctx, liveReportKey, string(value))
+}
+
 // GetAttendanceReportRange generates a report for a date range
-func (s *Service) GetAttendanceReportRange(startDate, endDate string) ([]models.AttendanceRecord, error) {
-	return s.repo.GetAttendanceReportRange(startDate, endDate)
+func (s *Service) GetAttendanceReportRange(ctx context.Context, startDate, endDate string) ([]models.AttendanceRecord, error) {
+	start, err := utils.ParseDate(startDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: start date %q", ErrInvalidDateRange, startDate)
+	}
+	end, err := utils.ParseDate(endDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: end date %q", ErrInvalidDateRange, endDate)
+	}
+	if start.After(end) {
+		return nil, fmt.Errorf("%w: %s is after %s", ErrInvalidDateRange, startDate, endDate)
+	}
+
+	return s.repo.GetAttendanceReportRange(ctx, startDate, endDate)
 }
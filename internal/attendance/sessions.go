@@ -0,0 +1,28 @@
+package attendance
+
+import (
+	"attendance-bot/pkg/models"
+	"context"
+	"time"
+)
+
+// SaveSession starts or replaces a conversation session
+func (s *Service) SaveSession(ctx context.Context, session *models.Session) error {
+	return s.repo.SaveSession(ctx, session)
+}
+
+// GetSession returns the conversation session of the user or kiosk chat, or nil if there is
+// none or it has expired
+func (s *Service) GetSession(ctx context.Context, userID int64) (*models.Session, error) {
+	return s.repo.GetSession(ctx, userID, time.Now())
+}
+
+// DeleteSession ends the conversation session of the user or kiosk chat
+func (s *Service) DeleteSession(ctx context.Context, userID int64) error {
+	return s.repo.DeleteSession(ctx, userID)
+}
+
+// PruneSessions removes expired conversation sessions, returning how many there were
+func (s *Service) PruneSessions(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpiredSessions(ctx, time.Now())
+}
@@ -0,0 +1,136 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// MaxShiftGraceMinutes bounds the grace period of a shift
+const MaxShiftGraceMinutes = 180
+
+// shiftNamePattern restricts shift names to short lowercase identifiers, without underscores so
+// they can be shown in Markdown messages
+var shiftNamePattern = regexp.MustCompile(`^[a-z0-9-]{1,32}$`)
+
+// Shift errors
+var (
+	ErrInvalidShift  = errors.New("invalid shift")
+	ErrUnknownShift  = errors.New("unknown shift")
+	ErrShiftAssigned = errors.New("shift has assigned users")
+)
+
+// SaveShift validates and stores a shift, replacing the times of an existing shift with the same name
+func (s *Service) SaveShift(ctx context.Context, shift *models.Shift) error {
+	if !shiftNamePattern.MatchString(shift.Name) {
+		return fmt.Errorf("%w: name %q", ErrInvalidShift, shift.Name)
+	}
+	start, err := utils.ParseClock(shift.Start)
+	if err != nil {
+		return fmt.Errorf("%w: start %q", ErrInvalidShift, shift.Start)
+	}
+	end, err := utils.ParseClock(shift.End)
+	if err != nil {
+		return fmt.Errorf("%w: end %q", ErrInvalidShift, shift.End)
+	}
+	if start == end {
+		return fmt.Errorf("%w: start and end are both %s", ErrInvalidShift, shift.Start)
+	}
+	if shift.GraceMinutes < 0 || shift.GraceMinutes > MaxShiftGraceMinutes {
+		return fmt.Errorf("%w: grace period of %d minutes", ErrInvalidShift, shift.GraceMinutes)
+	}
+
+	normalized := *shift
+	normalized.Start, normalized.End = start.String(), end.String()
+	if err := s.repo.SaveShift(ctx, &normalized); err != nil {
+		return err
+	}
+
+	// Late marks in cached reports may have changed
+	s.reports.invalidateAll()
+	return nil
+}
+
+// DeleteShift deletes a shift, returning false if it does not exist. Shifts with assigned users
+// cannot be deleted.
+func (s *Service) DeleteShift(ctx context.Context, name string) (bool, error) {
+	shift, err := s.getShift(ctx, name)
+	if err != nil || shift == nil {
+		return false, err
+	}
+	if shift.Users > 0 {
+		return false, fmt.Errorf("%w: %d users on %s", ErrShiftAssigned, shift.Users, name)
+	}
+
+	deleted, err := s.repo.DeleteShift(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	s.reports.invalidateAll()
+	return deleted, nil
+}
+
+// GetShifts returns all shifts with the number of users assigned to each
+func (s *Service) GetShifts(ctx context.Context) ([]models.Shift, error) {
+	return s.repo.GetShifts(ctx)
+}
+
+// AssignShift assigns a user to an existing shift on behalf of assignedBy
+func (s *Service) AssignShift(ctx context.Context, userID int64, name string, assignedBy int64) error {
+	shift, err := s.getShift(ctx, name)
+	if err != nil {
+		return err
+	}
+	if shift == nil {
+		return fmt.Errorf("%w: %q", ErrUnknownShift, name)
+	}
+
+	if err := s.repo.AssignShift(ctx, userID, name, assignedBy, time.Now()); err != nil {
+		return err
+	}
+	s.reports.invalidateAll()
+	return nil
+}
+
+// UnassignShift returns a user to the default shift, returning false if they had no assigned shift
+func (s *Service) UnassignShift(ctx context.Context, userID int64) (bool, error) {
+	unassigned, err := s.repo.UnassignShift(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	s.reports.invalidateAll()
+	return unassigned, nil
+}
+
+// GetShiftAssignments returns the shift of every user: their assigned shift, else the shift named
+// models.DefaultShiftName, else models.BuiltinShift
+func (s *Service) GetShiftAssignments(ctx context.Context) (*models.ShiftAssignments, error) {
+	return s.repo.GetShiftAssignments(ctx)
+}
+
+// GetUserShift returns the shift that applies to the user
+func (s *Service) GetUserShift(ctx context.Context, userID int64) (models.Shift, error) {
+	assignments, err := s.GetShiftAssignments(ctx)
+	if err != nil {
+		return models.Shift{}, err
+	}
+	return assignments.For(userID), nil
+}
+
+// getShift returns the named shift, or nil if it does not exist
+func (s *Service) getShift(ctx context.Context, name string) (*models.Shift, error) {
+	shifts, err := s.repo.GetShifts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range shifts {
+		if shifts[i].Name == name {
+			return &shifts[i], nil
+		}
+	}
+	return nil, nil
+}
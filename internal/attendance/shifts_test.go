@@ -0,0 +1,90 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSaveShiftValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		shift models.Shift
+		want  error
+	}{
+		{"day shift", models.Shift{Name: "morning", Start: "08:00", End: "16:00", GraceMinutes: 15}, nil},
+		{"overnight", models.Shift{Name: "night-2", Start: "22:00", End: "06:00"}, nil},
+		{"no end", models.Shift{Name: "flex", Start: "10:00", End: ""}, ErrInvalidShift},
+		{"uppercase name", models.Shift{Name: "Morning", Start: "08:00", End: "16:00"}, ErrInvalidShift},
+		{"underscore in name", models.Shift{Name: "early_bird", Start: "06:00", End: "14:00"}, ErrInvalidShift},
+		{"invalid start", models.Shift{Name: "morning", Start: "8am", End: "16:00"}, ErrInvalidShift},
+		{"same start and end", models.Shift{Name: "morning", Start: "08:00", End: "08:00"}, ErrInvalidShift},
+		{"negative grace", models.Shift{Name: "morning", Start: "08:00", End: "16:00", GraceMinutes: -1}, ErrInvalidShift},
+		{"grace too long", models.Shift{Name: "morning", Start: "08:00", End: "16:00", GraceMinutes: MaxShiftGraceMinutes + 1}, ErrInvalidShift},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			if err := service.SaveShift(context.Background(), &tt.shift); !errors.Is(err, tt.want) {
+				t.Errorf("SaveShift(%+v) = %v, want %v", tt.shift, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestLateArrivalsByShift checks in three users at 08:20: only the one on the morning shift,
+// whose grace period ended at 08:15, is late; the default shift starts at 09:00
+func TestLateArrivalsByShift(t *testing.T) {
+	service, repo := newTestService(t)
+	ctx := context.Background()
+
+	for _, shift := range []models.Shift{
+		{Name: "morning", Start: "08:00", End: "16:00", GraceMinutes: 15},
+		{Name: "late", Start: "08:00", End: "16:00", GraceMinutes: 30},
+	} {
+		if err := service.SaveShift(ctx, &shift); err != nil {
+			t.Fatalf("SaveShift: %v", err)
+		}
+	}
+	if err := service.AssignShift(ctx, 1, "morning", 900); err != nil {
+		t.Fatalf("AssignShift: %v", err)
+	}
+	if err := service.AssignShift(ctx, 2, "late", 900); err != nil {
+		t.Fatalf("AssignShift: %v", err)
+	}
+	if err := service.AssignShift(ctx, 3, "missing", 900); !errors.Is(err, ErrUnknownShift) {
+		t.Errorf("AssignShift to a missing shift = %v, want ErrUnknownShift", err)
+	}
+
+	checkIn := time.Date(2024, 3, 4, 8, 20, 0, 0, utils.Location)
+	for userID := int64(1); userID <= 3; userID++ {
+		if _, _, err := repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{{
+			UserID: userID, FirstName: "User", Timestamp: checkIn, Type: "check_in", Date: "2024-03-04",
+		}}); err != nil {
+			t.Fatalf("InsertAttendanceBatch: %v", err)
+		}
+	}
+
+	late, err := service.GetLateArrivals(ctx, "2024-03-04")
+	if err != nil {
+		t.Fatalf("GetLateArrivals: %v", err)
+	}
+	if len(late) != 1 || late[0].Record.UserID != 1 || late[0].Shift.Name != "morning" {
+		t.Errorf("late arrivals = %+v, want user 1 on the morning shift", late)
+	}
+
+	// A shift with users cannot be deleted until they are unassigned
+	if _, err := service.DeleteShift(ctx, "morning"); !errors.Is(err, ErrShiftAssigned) {
+		t.Errorf("DeleteShift of an assigned shift = %v, want ErrShiftAssigned", err)
+	}
+	if _, err := service.UnassignShift(ctx, 1); err != nil {
+		t.Fatalf("UnassignShift: %v", err)
+	}
+	if deleted, err := service.DeleteShift(ctx, "morning"); err != nil || !deleted {
+		t.Errorf("DeleteShift = %v, %v; want it deleted", deleted, err)
+	}
+}
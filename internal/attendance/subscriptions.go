@@ -0,0 +1,70 @@
+package attendance
+
+import (
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Digest names
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// ErrUnknownDigest is returned when subscribing to a digest that does not exist or is not delivered yet
+var ErrUnknownDigest = errors.New("unknown digest")
+
+// Digest describes a scheduled report chats can subscribe to. Its description is the message
+// "digest.<name>" of the i18n catalogs.
+type Digest struct {
+	Name      string
+	Available bool // False while the digest is announced but not delivered yet
+}
+
+// Digests lists the scheduled reports, in display order
+var Digests = []Digest{
+	{Name: DigestDaily, Available: true},
+	{Name: DigestWeekly, Available: false},
+}
+
+// LookupDigest returns the digest with the given name
+func LookupDigest(name string) (Digest, bool) {
+	for _, digest := range Digests {
+		if digest.Name == name {
+			return digest, true
+		}
+	}
+	return Digest{}, false
+}
+
+// Subscribe subscribes a chat to a digest on behalf of userID, returning false if it was already subscribed
+func (s *Service) Subscribe(ctx context.Context, chatID, userID int64, digest string) (bool, error) {
+	if d, ok := LookupDigest(digest); !ok || !d.Available {
+		return false, fmt.Errorf("%w: %q", ErrUnknownDigest, digest)
+	}
+
+	return s.repo.AddSubscription(ctx, &models.Subscription{
+		ChatID:    chatID,
+		UserID:    userID,
+		Digest:    digest,
+		CreatedAt: time.Now(),
+	})
+}
+
+// Unsubscribe removes a chat's subscription to a digest, returning false if it was not subscribed
+func (s *Service) Unsubscribe(ctx context.Context, chatID int64, digest string) (bool, error) {
+	return s.repo.RemoveSubscription(ctx, chatID, digest)
+}
+
+// GetSubscribers returns the chats subscribed to a digest
+func (s *Service) GetSubscribers(ctx context.Context, digest string) ([]models.Subscription, error) {
+	return s.repo.GetSubscriptions(ctx, digest)
+}
+
+// GetChatSubscriptions returns the digests a chat is subscribed to
+func (s *Service) GetChatSubscriptions(ctx context.Context, chatID int64) ([]models.Subscription, error) {
+	return s.repo.GetChatSubscriptions(ctx, chatID)
+}
@@ -0,0 +1,63 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTimezone is returned when a user sets a timezone the timezone database does not know
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// UserLocation returns the timezone the user's attendance is in: the one they set with /timezone,
+// else utils.Location. A stored timezone that no longer loads falls back to utils.Location too.
+func (s *Service) UserLocation(ctx context.Context, userID int64) (*time.Location, error) {
+	stored, err := s.repo.GetUserTimezone(ctx, userID)
+	if err != nil || stored == nil {
+		return utils.Location, err
+	}
+
+	location, err := utils.LoadLocation(stored.Timezone)
+	if err != nil {
+		return utils.Location, nil
+	}
+	return location, nil
+}
+
+// UserNow returns the current time in the timezone the user works in, see UserLocation
+func (s *Service) UserNow(ctx context.Context, userID int64) (time.Time, error) {
+	location, err := s.UserLocation(ctx, userID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get user timezone: %w", err)
+	}
+	return time.Now().In(location), nil
+}
+
+// GetUserTimezone returns the timezone the user set, or nil if they follow TIMEZONE
+func (s *Service) GetUserTimezone(ctx context.Context, userID int64) (*models.UserTimezone, error) {
+	return s.repo.GetUserTimezone(ctx, userID)
+}
+
+// SetUserTimezone stores the IANA timezone the user works in, e.g. Asia/Makassar, returning it as
+// loaded
+func (s *Service) SetUserTimezone(ctx context.Context, userID int64, name string) (*time.Location, error) {
+	location, err := utils.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+
+	timezone := &models.UserTimezone{UserID: userID, Timezone: location.String(), UpdatedAt: time.Now()}
+	if err := s.repo.SaveUserTimezone(ctx, timezone); err != nil {
+		return nil, err
+	}
+	return location, nil
+}
+
+// ResetUserTimezone drops the user's own timezone so TIMEZONE applies again, reporting whether
+// they had set one
+func (s *Service) ResetUserTimezone(ctx context.Context, userID int64) (bool, error) {
+	return s.repo.DeleteUserTimezone(ctx, userID)
+}
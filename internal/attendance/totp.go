@@ -1,75 +1,237 @@
 package attendance
 
 import (
+	"attendance-bot/internal/utils"
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/base32"
 	"encoding/binary"
+	"errors"
 	"fmt"
-	"math/rand"
+	"hash"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// ErrInvalidSecret indicates the configured secret cannot be decoded, which is a configuration error
+// rather than a wrong code
+var ErrInvalidSecret = errors.New("invalid otp secret")
+
+// Algorithm identifies the HMAC hash function used to derive TOTP codes
+type Algorithm string
+
+// Supported TOTP algorithms
+const (
+	AlgorithmSHA1   Algorithm = "SHA1"
+	AlgorithmSHA256 Algorithm = "SHA256"
+	AlgorithmSHA512 Algorithm = "SHA512"
+)
+
+// ParseAlgorithm converts a name such as "SHA256" or "sha-256" into an Algorithm
+func ParseAlgorithm(name string) (Algorithm, error) {
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(name), "-", ""))
+
+	switch Algorithm(normalized) {
+	case AlgorithmSHA1, AlgorithmSHA256, AlgorithmSHA512:
+		return Algorithm(normalized), nil
+	default:
+		return "", fmt.Errorf("unsupported TOTP algorithm: %s", name)
+	}
+}
+
+// hashFunc returns the hash constructor for the algorithm
+func (a Algorithm) hashFunc() func() hash.Hash {
+	switch a {
+	case AlgorithmSHA256:
+		return sha256.New
+	case AlgorithmSHA512:
+		return sha512.New
+	default:
+		return sha1.New
+	}
+}
+
 // TOTPService handles Time-based One-Time Password operations
 type TOTPService struct {
-	secret string
+	secret    string
+	algorithm Algorithm
+	digits    int
+	period    int64
+	skew      int
+}
+
+// TOTPOptions contains optional parameters for the TOTP service
+type TOTPOptions struct {
+	Algorithm Algorithm
+	Digits    int  // Number of digits in a code (default 6)
+	Period    int  // Time step in seconds (default 30)
+	Skew      *int // Time steps accepted before and after the current one (default 1)
+}
+
+// VerifyResult describes the outcome of a TOTP verification
+type VerifyResult struct {
+	Valid          bool
+	Offset         int  // Time step offset of the matching code, relative to the current step
+	PreviousSecret bool // The code matched the previous secret during a rotation grace period
 }
 
 // NewTOTPService creates a new TOTP service with the given secret
 func NewTOTPService(secret string) *TOTPService {
-	return &TOTPService{
-		secret: secret,
+	return NewTOTPServiceWithOptions(secret, nil)
+}
+
+// NewTOTPServiceWithOptions creates a new TOTP service with additional options
+func NewTOTPServiceWithOptions(secret string, options *TOTPOptions) *TOTPService {
+	t := &TOTPService{
+		secret:    utils.NormalizeSecret(secret),
+		algorithm: AlgorithmSHA1,
+		digits:    6,
+		period:    30,
+		skew:      1,
+	}
+
+	if options != nil {
+		if options.Algorithm != "" {
+			t.algorithm = options.Algorithm
+		}
+		if options.Digits > 0 {
+			t.digits = options.Digits
+		}
+		if options.Period > 0 {
+			t.period = int64(options.Period)
+		}
+		if options.Skew != nil && *options.Skew >= 0 {
+			t.skew = *options.Skew
+		}
 	}
+
+	return t
+}
+
+// WithSecret returns a service with the same options generating codes from another secret
+func (t *TOTPService) WithSecret(secret string) *TOTPService {
+	next := *t
+	next.secret = utils.NormalizeSecret(secret)
+	return &next
+}
+
+// Algorithm returns the HMAC algorithm used by the service
+func (t *TOTPService) Algorithm() Algorithm {
+	return t.algorithm
+}
+
+// Digits returns the number of digits in a generated code
+func (t *TOTPService) Digits() int {
+	return t.digits
+}
+
+// Period returns the time step in seconds
+func (t *TOTPService) Period() int {
+	return int(t.period)
 }
 
-// Verify checks if the provided token is valid for the current time
-func (t *TOTPService) Verify(token string) bool {
+// Skew returns the number of time steps tolerated on either side of the current one
+func (t *TOTPService) Skew() int {
+	return t.skew
+}
+
+// Verify checks if the provided token is valid for the current time.
+// An error wrapping ErrInvalidSecret is returned when the service is misconfigured.
+func (t *TOTPService) Verify(token string) (bool, error) {
+	result, err := t.Check(token)
+	return result.Valid, err
+}
+
+// Check verifies the token for the current time and reports which time step matched
+func (t *TOTPService) Check(token string) (VerifyResult, error) {
+	return t.checkAt(token, time.Now().Unix())
+}
+
+// checkAt verifies the token against the configured skew window around the given time
+func (t *TOTPService) checkAt(token string, unixTime int64) (VerifyResult, error) {
 	// Remove any spaces or formatting
 	token = strings.ReplaceAll(token, " ", "")
 
-	if len(token) != 6 {
-		return false
+	if len(token) != t.digits {
+		return VerifyResult{}, nil
 	}
 
-	// Check current time and ±1 time step for clock skew tolerance
-	now := time.Now().Unix()
-	timeStep := int64(30) // 30 seconds
-
-	for i := -1; i <= 1; i++ {
-		testTime := (now/timeStep + int64(i)) * timeStep
-		expectedToken := t.generateTOTPForTime(testTime)
-		if token == expectedToken {
-			return true
+	// Check the current time step first, then widen outwards for clock skew tolerance
+	for distance := 0; distance <= t.skew; distance++ {
+		for _, offset := range []int{-distance, distance} {
+			testTime := (unixTime/t.period + int64(offset)) * t.period
+			expectedToken, err := t.generateTOTPForTime(testTime)
+			if err != nil {
+				return VerifyResult{}, err
+			}
+			if codesEqual(token, expectedToken) {
+				return VerifyResult{Valid: true, Offset: offset}, nil
+			}
+			if distance == 0 {
+				break
+			}
 		}
 	}
 
-	return false
+	return VerifyResult{}, nil
 }
 
 // Generate creates a TOTP token for the current time
-func (t *TOTPService) Generate() string {
+func (t *TOTPService) Generate() (string, error) {
 	now := time.Now().Unix()
 	return t.generateTOTPForTime(now)
 }
 
+// Validate reports why the service cannot generate codes, or nil if it can
+func (t *TOTPService) Validate() error {
+	if !ValidateSecret(t.secret) {
+		return errors.New("secret is not base32 or shorter than 10 bytes")
+	}
+	if t.period <= 0 {
+		return fmt.Errorf("invalid period %d", t.period)
+	}
+	if _, err := t.Generate(); err != nil {
+		return fmt.Errorf("failed to generate code: %w", err)
+	}
+	return nil
+}
+
+// GenerateAt creates a TOTP token for the time step containing t
+func (t *TOTPService) GenerateAt(at time.Time) (string, error) {
+	return t.generateTOTPForTime(at.Unix())
+}
+
 // generateTOTPForTime creates a TOTP token for a specific time
-func (t *TOTPService) generateTOTPForTime(unixTime int64) string {
-	timeStep := int64(30) // 30 seconds
-	counter := unixTime / timeStep
+func (t *TOTPService) generateTOTPForTime(unixTime int64) (string, error) {
+	counter := unixTime / t.period
+	return generateCode(t.secret, uint64(counter), t.algorithm, t.digits)
+}
+
+// codesEqual compares two codes in constant time
+func codesEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
 
+// generateCode computes an RFC 4226 one-time code for the given counter
+func generateCode(encodedSecret string, counter uint64, algorithm Algorithm, digits int) (string, error) {
 	// Convert secret from base32
-	secret, err := base32.StdEncoding.DecodeString(strings.ToUpper(t.secret))
+	secret, err := base32.StdEncoding.DecodeString(utils.NormalizeSecret(encodedSecret))
 	if err != nil {
-		return ""
+		return "", fmt.Errorf("%w: %v", ErrInvalidSecret, err)
 	}
 
-	// Create HMAC-SHA1 hash
-	h := hmac.New(sha1.New, secret)
+	// Create HMAC hash using the configured algorithm
+	h := hmac.New(algorithm.hashFunc(), secret)
 
 	// Convert counter to bytes
 	counterBytes := make([]byte, 8)
-	binary.BigEndian.PutUint64(counterBytes, uint64(counter))
+	binary.BigEndian.PutUint64(counterBytes, counter)
 
 	h.Write(counterBytes)
 	hash := h.Sum(nil)
@@ -80,44 +242,73 @@ func (t *TOTPService) generateTOTPForTime(unixTime int64) string {
 	// Extract 4 bytes starting from offset
 	truncatedHash := binary.BigEndian.Uint32(hash[offset:offset+4]) & 0x7fffffff
 
-	// Generate 6-digit code
-	code := truncatedHash % 1000000
+	// Reduce to the configured number of digits
+	modulus := uint32(1)
+	for i := 0; i < digits; i++ {
+		modulus *= 10
+	}
+	code := truncatedHash % modulus
 
-	return fmt.Sprintf("%06d", code)
+	return fmt.Sprintf("%0*d", digits, code), nil
 }
 
 // GenerateSecret creates a new random base32-encoded secret
-func GenerateSecret() string {
-	// Generate 20 random bytes (160 bits)
+func GenerateSecret() (string, error) {
+	// Generate 20 random bytes (160 bits) from a cryptographically secure source
 	secretBytes := make([]byte, 20)
-	for i := range secretBytes {
-		secretBytes[i] = byte(rand.Intn(256))
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", fmt.Errorf("failed to read random bytes for secret: %w", err)
 	}
 
 	// Encode as base32
-	return base32.StdEncoding.EncodeToString(secretBytes)
+	return base32.StdEncoding.EncodeToString(secretBytes), nil
 }
 
 // GenerateKeyURI creates an otpauth:// URI for use with authenticator apps
 func (t *TOTPService) GenerateKeyURI(accountName, issuer string) string {
-	return fmt.Sprintf("otpauth://totp/%s:%s?secret=%s&issuer=%s&algorithm=SHA1&digits=6&period=30",
-		issuer, accountName, t.secret, issuer)
+	// The label is "issuer:account", with each part escaped on its own so the separator stays literal
+	label := escapeOTPAuth(issuer) + ":" + escapeOTPAuth(accountName)
+
+	params := []string{
+		"secret=" + escapeOTPAuth(strings.TrimRight(t.secret, "=")), // Authenticator apps expect no padding
+		"issuer=" + escapeOTPAuth(issuer),
+		"algorithm=" + escapeOTPAuth(string(t.algorithm)),
+		"digits=" + strconv.Itoa(t.digits),
+		"period=" + strconv.FormatInt(t.period, 10),
+	}
+
+	return "otpauth://totp/" + label + "?" + strings.Join(params, "&")
+}
+
+// escapeOTPAuth percent-encodes a value for an otpauth URI.
+// Spaces become %20 rather than "+", which several authenticator apps do not decode.
+func escapeOTPAuth(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
 }
 
 // GetTimeRemaining returns the number of seconds until the current TOTP expires
 func (t *TOTPService) GetTimeRemaining() int {
 	now := time.Now().Unix()
-	timeStep := int64(30)
-	return int(timeStep - (now % timeStep))
+	return int(t.period - (now % t.period))
 }
 
-// ValidateSecret checks if a secret is properly formatted
+// minSecretBytes is the minimum decoded secret length (80 bits, per RFC 4226)
+const minSecretBytes = 10
+
+// ValidateSecret checks if a secret is properly formatted and long enough once normalized
 func ValidateSecret(secret string) bool {
-	if len(secret) < 16 {
+	secret = utils.NormalizeSecret(secret)
+
+	// Only the base32 alphabet and trailing padding are allowed
+	unpadded := strings.TrimRight(secret, "=")
+	if unpadded == "" || strings.Trim(unpadded, "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567") != "" {
+		return false
+	}
+
+	decoded, err := base32.StdEncoding.DecodeString(secret)
+	if err != nil {
 		return false
 	}
 
-	// Try to decode as base32
-	_, err := base32.StdEncoding.DecodeString(strings.ToUpper(secret))
-	return err == nil
+	return len(decoded) >= minSecretBytes
 }
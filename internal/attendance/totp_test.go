@@ -0,0 +1,354 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"context"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Seeds are the secrets of the RFC 6238 Appendix B test vectors, base32 encoded
+var rfc6238Seeds = map[Algorithm]string{
+	AlgorithmSHA1:   base32.StdEncoding.EncodeToString([]byte("12345678901234567890")),
+	AlgorithmSHA256: base32.StdEncoding.EncodeToString([]byte("12345678901234567890123456789012")),
+	AlgorithmSHA512: base32.StdEncoding.EncodeToString([]byte("1234567890123456789012345678901234567890123456789012345678901234")),
+}
+
+func TestRFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unixTime  int64
+		algorithm Algorithm
+		code      string
+	}{
+		{59, AlgorithmSHA1, "94287082"},
+		{59, AlgorithmSHA256, "46119246"},
+		{59, AlgorithmSHA512, "90693936"},
+		{1111111109, AlgorithmSHA1, "07081804"},
+		{1111111109, AlgorithmSHA256, "68084774"},
+		{1111111109, AlgorithmSHA512, "25091201"},
+		{1111111111, AlgorithmSHA1, "14050471"},
+		{1111111111, AlgorithmSHA256, "67062674"},
+		{1111111111, AlgorithmSHA512, "99943326"},
+		{1234567890, AlgorithmSHA1, "89005924"},
+		{1234567890, AlgorithmSHA256, "91819424"},
+		{1234567890, AlgorithmSHA512, "93441116"},
+		{2000000000, AlgorithmSHA1, "69279037"},
+		{2000000000, AlgorithmSHA256, "90698825"},
+		{2000000000, AlgorithmSHA512, "38618901"},
+		{20000000000, AlgorithmSHA1, "65353130"},
+		{20000000000, AlgorithmSHA256, "77737706"},
+		{20000000000, AlgorithmSHA512, "47863826"},
+	}
+
+	for _, tt := range tests {
+		code, err := generateCode(rfc6238Seeds[tt.algorithm], uint64(tt.unixTime/30), tt.algorithm, 8)
+		if err != nil {
+			t.Fatalf("generateCode(%s, %d): %v", tt.algorithm, tt.unixTime, err)
+		}
+		if code != tt.code {
+			t.Errorf("%s code at %d = %s, want %s", tt.algorithm, tt.unixTime, code, tt.code)
+		}
+
+		totp := NewTOTPServiceWithOptions(rfc6238Seeds[tt.algorithm], &TOTPOptions{Algorithm: tt.algorithm, Digits: 8})
+		result, err := totp.checkAt(tt.code, tt.unixTime)
+		if err != nil {
+			t.Fatalf("checkAt(%s, %d): %v", tt.algorithm, tt.unixTime, err)
+		}
+		if !result.Valid || result.Offset != 0 {
+			t.Errorf("%s code %s at %d checked as %+v, want valid in the current step", tt.algorithm, tt.code, tt.unixTime, result)
+		}
+	}
+}
+
+func TestParseAlgorithm(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm Algorithm
+		valid     bool
+	}{
+		{"SHA1", AlgorithmSHA1, true},
+		{"sha256", AlgorithmSHA256, true},
+		{" SHA-512 ", AlgorithmSHA512, true},
+		{"MD5", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		algorithm, err := ParseAlgorithm(tt.name)
+		if (err == nil) != tt.valid || algorithm != tt.algorithm {
+			t.Errorf("ParseAlgorithm(%q) = %q, %v; want %q, valid %v", tt.name, algorithm, err, tt.algorithm, tt.valid)
+		}
+	}
+}
+
+func TestGenerateSecretIsRandom(t *testing.T) {
+	const count = 100
+
+	seen := make(map[string]bool, count)
+	var ones, bits int
+	for range count {
+		secret, err := GenerateSecret()
+		if err != nil {
+			t.Fatalf("GenerateSecret: %v", err)
+		}
+		if seen[secret] {
+			t.Fatalf("GenerateSecret returned %s twice", secret)
+		}
+		seen[secret] = true
+
+		if !ValidateSecret(secret) {
+			t.Errorf("GenerateSecret returned %s, which ValidateSecret rejects", secret)
+		}
+		decoded, err := base32.StdEncoding.DecodeString(secret)
+		if err != nil {
+			t.Fatalf("secret %s is not base32: %v", secret, err)
+		}
+		if len(decoded) != 20 {
+			t.Errorf("secret %s has %d bytes, want 20", secret, len(decoded))
+		}
+		for _, b := range decoded {
+			for ; b != 0; b &= b - 1 {
+				ones++
+			}
+		}
+		bits += len(decoded) * 8
+	}
+
+	// 16000 uniformly random bits have about 8000 ones, with a standard deviation of about 63
+	if ones < bits/2-500 || ones > bits/2+500 {
+		t.Errorf("%d of %d secret bits set, not random", ones, bits)
+	}
+}
+
+func TestDigitsAndPeriod(t *testing.T) {
+	tests := []struct {
+		digits, period int
+	}{
+		{6, 30},
+		{8, 60},
+	}
+
+	for _, tt := range tests {
+		totp := NewTOTPServiceWithOptions(testSecret, &TOTPOptions{Digits: tt.digits, Period: tt.period})
+		period := time.Duration(tt.period) * time.Second
+
+		code, err := totp.Generate()
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		if len(code) != tt.digits || !utils.ValidateOTP(code, tt.digits) {
+			t.Errorf("%d/%d code %q is not %d digits", tt.digits, tt.period, code, tt.digits)
+		}
+		if valid, err := totp.Verify(code); err != nil || !valid {
+			t.Errorf("%d/%d Verify(current code) = %v, %v; want valid", tt.digits, tt.period, valid, err)
+		}
+		if remaining := totp.GetTimeRemaining(); remaining < 1 || remaining > tt.period {
+			t.Errorf("%d/%d GetTimeRemaining = %d", tt.digits, tt.period, remaining)
+		}
+
+		// One period back is within the default skew of one step, three periods back is not
+		previous, _ := totp.GenerateAt(time.Now().Add(-period))
+		if valid, _ := totp.Verify(previous); !valid {
+			t.Errorf("%d/%d Verify(previous step) rejected", tt.digits, tt.period)
+		}
+		stale, _ := totp.GenerateAt(time.Now().Add(-3 * period))
+		if valid, _ := totp.Verify(stale); valid && stale != code && stale != previous {
+			t.Errorf("%d/%d Verify(three steps back) accepted", tt.digits, tt.period)
+		}
+
+		// A code of the other length never matches
+		if valid, _ := totp.Verify(code[:tt.digits-1]); valid {
+			t.Errorf("%d/%d Verify accepted a %d digit code", tt.digits, tt.period, tt.digits-1)
+		}
+	}
+}
+
+func TestPeriodSetsTimeStep(t *testing.T) {
+	const base = 1_699_999_980 // A multiple of 60 seconds
+	totp := NewTOTPServiceWithOptions(testSecret, &TOTPOptions{Digits: 8, Period: 60, Skew: new(int)})
+
+	start, _ := totp.GenerateAt(time.Unix(base, 0))
+	end, _ := totp.GenerateAt(time.Unix(base+59, 0))
+	next, _ := totp.GenerateAt(time.Unix(base+60, 0))
+	if start != end {
+		t.Errorf("codes within one 60 second step differ: %s and %s", start, end)
+	}
+	if start == next {
+		t.Errorf("codes of consecutive 60 second steps are both %s", start)
+	}
+}
+
+func TestSkewWindow(t *testing.T) {
+	const now = 1_699_999_980 // A multiple of 30 seconds
+
+	for _, skew := range []int{0, 1, 2} {
+		totp := NewTOTPServiceWithOptions(testSecret, &TOTPOptions{Skew: &skew})
+		if totp.Skew() != skew {
+			t.Fatalf("Skew() = %d, want %d", totp.Skew(), skew)
+		}
+
+		for offset := -3; offset <= 3; offset++ {
+			code, err := totp.GenerateAt(time.Unix(now+int64(offset)*30, 0))
+			if err != nil {
+				t.Fatalf("GenerateAt: %v", err)
+			}
+			result, err := totp.checkAt(code, now)
+			if err != nil {
+				t.Fatalf("checkAt: %v", err)
+			}
+
+			want := offset >= -skew && offset <= skew
+			if result.Valid != want {
+				t.Errorf("skew %d: code %d steps away valid = %v, want %v", skew, offset, result.Valid, want)
+			}
+			if want && result.Offset != offset {
+				t.Errorf("skew %d: code %d steps away matched offset %d", skew, offset, result.Offset)
+			}
+		}
+	}
+}
+
+func TestNegativeSkewKeepsDefault(t *testing.T) {
+	skew := -1
+	if got := NewTOTPServiceWithOptions(testSecret, &TOTPOptions{Skew: &skew}).Skew(); got != 1 {
+		t.Errorf("Skew() = %d for a negative skew, want the default 1", got)
+	}
+}
+
+func TestCorruptSecret(t *testing.T) {
+	totp := NewTOTPService("NOT*BASE32!")
+
+	if _, err := totp.Generate(); !errors.Is(err, ErrInvalidSecret) {
+		t.Errorf("Generate error = %v, want ErrInvalidSecret", err)
+	}
+	if valid, err := totp.Verify("123456"); valid || !errors.Is(err, ErrInvalidSecret) {
+		t.Errorf("Verify = %v, %v; want ErrInvalidSecret", valid, err)
+	}
+	if err := totp.Validate(); err == nil {
+		t.Error("Validate accepted a corrupt secret")
+	}
+
+	// A code of the wrong length is rejected before the secret is used
+	if valid, err := totp.Verify("12"); valid || err != nil {
+		t.Errorf("Verify(short code) = %v, %v; want rejected without error", valid, err)
+	}
+}
+
+func TestCodesEqual(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"123456", "123456", true},
+		{"123456", "123457", false},
+		{"123456", "12345", false},
+		{"", "123456", false},
+	}
+
+	for _, tt := range tests {
+		if got := codesEqual(tt.a, tt.b); got != tt.equal {
+			t.Errorf("codesEqual(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.equal)
+		}
+	}
+}
+
+func TestMarkAttendanceSurfacesCorruptSecret(t *testing.T) {
+	repo := newTestRepository(t)
+	service := NewService(repo, NewTOTPService("NOT*BASE32!"))
+
+	result, err := service.MarkAttendance(context.Background(), 1001, "budi", "Budi", nil, "123456")
+	if !errors.Is(err, ErrInvalidSecret) {
+		t.Errorf("MarkAttendance = %+v, %v; want ErrInvalidSecret rather than a rejected code", result, err)
+	}
+}
+
+func TestGenerateKeyURI(t *testing.T) {
+	tests := []struct {
+		issuer, account string
+		options         *TOTPOptions
+		uri             string
+	}{
+		{
+			"Attendance", "budi", nil,
+			"otpauth://totp/Attendance:budi?secret=JBSWY3DPEHPK3PXP&issuer=Attendance&algorithm=SHA1&digits=6&period=30",
+		},
+		{
+			"Attendance Bot", "Budi & Sons", nil,
+			"otpauth://totp/Attendance%20Bot:Budi%20%26%20Sons?secret=JBSWY3DPEHPK3PXP&issuer=Attendance%20Bot&algorithm=SHA1&digits=6&period=30",
+		},
+		{
+			"Kantor Pusat", "Ñoño Müller", &TOTPOptions{Algorithm: AlgorithmSHA256, Digits: 8, Period: 60},
+			"otpauth://totp/Kantor%20Pusat:%C3%91o%C3%B1o%20M%C3%BCller?secret=JBSWY3DPEHPK3PXP&issuer=Kantor%20Pusat&algorithm=SHA256&digits=8&period=60",
+		},
+		{
+			"A+B/C", "x:y?z#1=2%", nil,
+			"otpauth://totp/A%2BB%2FC:x%3Ay%3Fz%231%3D2%25?secret=JBSWY3DPEHPK3PXP&issuer=A%2BB%2FC&algorithm=SHA1&digits=6&period=30",
+		},
+	}
+
+	for _, tt := range tests {
+		uri := NewTOTPServiceWithOptions(testSecret, tt.options).GenerateKeyURI(tt.account, tt.issuer)
+		if uri != tt.uri {
+			t.Errorf("GenerateKeyURI(%q, %q) =\n%s\nwant\n%s", tt.account, tt.issuer, uri, tt.uri)
+		}
+
+		key, err := utils.ParseOTPAuthURI(uri)
+		if err != nil {
+			t.Fatalf("ParseOTPAuthURI(%s): %v", uri, err)
+		}
+		if key.Issuer != tt.issuer || key.Account != tt.account || key.Secret != testSecret {
+			t.Errorf("%s parsed back as %+v", uri, key)
+		}
+	}
+}
+
+func TestGenerateKeyURIDropsPadding(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("0123456789a")) // 11 bytes, padded with "="
+	uri := NewTOTPService(secret).GenerateKeyURI("budi", "Attendance")
+	if strings.Contains(uri, "=&") || strings.Contains(uri, "%3D") {
+		t.Errorf("GenerateKeyURI kept the secret padding: %s", uri)
+	}
+}
+
+func TestValidateSecretNormalizes(t *testing.T) {
+	tests := []struct {
+		secret string
+		valid  bool
+	}{
+		{"JBSWY3DPEHPK3PXP", true},
+		{"jbsw y3dp ehpk 3pxp", true},
+		{"JBSW-Y3DP-EHPK-3PXP", true},
+		{"gezd gnbv gy3t qoi", false}, // 9 bytes, below the minimum of 10
+		{"gezd gnbv gy3t qojq", true}, // 10 bytes
+		{"gezd gnbv gy3t qojq ge", true},
+		{"gezd gnbv gy3t qojq gez", false}, // Not a whole number of bytes
+		{"JBSWY3DPEHPK3PX1", false},        // 1 is not in the base32 alphabet
+		{"JBSWY3DP=EHPK3PXP", false},
+		{"", false},
+		{"   ", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidateSecret(tt.secret); got != tt.valid {
+			t.Errorf("ValidateSecret(%q) = %v, want %v", tt.secret, got, tt.valid)
+		}
+	}
+}
+
+func TestMessySecretGeneratesSameCodes(t *testing.T) {
+	clean := NewTOTPService(testSecret)
+	messy := NewTOTPService("jbsw-y3dp ehpk 3pxp")
+	at := time.Unix(1_699_999_980, 0)
+
+	want, _ := clean.GenerateAt(at)
+	got, err := messy.GenerateAt(at)
+	if err != nil || got != want {
+		t.Errorf("code of the messy secret = %s, %v; want %s", got, err, want)
+	}
+	if err := messy.Validate(); err != nil {
+		t.Errorf("Validate of the messy secret: %v", err)
+	}
+}
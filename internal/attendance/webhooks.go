@@ -0,0 +1,15 @@
+package attendance
+
+import (
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+)
+
+// RecordWebhookFailure keeps an event webhook delivery that failed for good in the dead-letter table
+func (s *Service) RecordWebhookFailure(ctx context.Context, failure *models.WebhookFailure) error {
+	if err := s.repo.InsertWebhookFailure(ctx, failure); err != nil {
+		return fmt.Errorf("failed to record webhook failure: %w", err)
+	}
+	return nil
+}
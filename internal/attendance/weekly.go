@@ -0,0 +1,60 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+// LastWeek returns the Monday and Sunday (YYYY-MM-DD) of the week before the one of now
+func LastWeek(now time.Time) (string, string) {
+	local := now.In(utils.Location)
+	sinceMonday := (int(local.Weekday()) + 6) % 7
+	monday := local.AddDate(0, 0, -sinceMonday-7)
+	return monday.Format("2006-01-02"), monday.AddDate(0, 0, 6).Format("2006-01-02")
+}
+
+// GetWeeklySummaries aggregates last week's attendance, Monday to Sunday before the week of now,
+// per employee who recorded any, leaving out those who opted out of the weekly summary
+func (s *Service) GetWeeklySummaries(ctx context.Context, now time.Time) (*models.MonthlySummary, error) {
+	start, end := LastWeek(now)
+	summary, err := s.GetPeriodSummary(ctx, start, end, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly summary: %w", err)
+	}
+
+	optOuts, err := s.repo.GetWeeklySummaryOptOuts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly summary opt-outs: %w", err)
+	}
+
+	users := summary.Users[:0]
+	for _, user := range summary.Users {
+		if !optOuts[user.UserID] {
+			users = append(users, user)
+		}
+	}
+	summary.Users = users
+	return summary, nil
+}
+
+// WeeklySummaryEnabled reports whether the user gets the weekly summary, which they do unless
+// they opted out
+func (s *Service) WeeklySummaryEnabled(ctx context.Context, userID int64) (bool, error) {
+	optOuts, err := s.repo.GetWeeklySummaryOptOuts(ctx)
+	if err != nil {
+		return false, err
+	}
+	return !optOuts[userID], nil
+}
+
+// SetWeeklySummary opts the user in to or out of the weekly summary
+func (s *Service) SetWeeklySummary(ctx context.Context, userID int64, enabled bool) error {
+	if enabled {
+		_, err := s.repo.DeleteWeeklySummaryOptOut(ctx, userID)
+		return err
+	}
+	return s.repo.SaveWeeklySummaryOptOut(ctx, userID, time.Now())
+}
@@ -0,0 +1,145 @@
+package attendance
+
+import (
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxWFHAheadDays is how far ahead working from home may be requested
+const MaxWFHAheadDays = 30
+
+// Work from home request errors
+var (
+	ErrWFHDate   = errors.New("work from home date out of range")
+	ErrWFHExists = errors.New("work from home already requested")
+)
+
+// SetWFHApproval sets whether requests to work from home wait for an admin's approval
+func (s *Service) SetWFHApproval(required bool) {
+	s.wfhApproval = required
+}
+
+// WFHApprovalRequired reports whether requests to work from home wait for an admin's approval
+func (s *Service) WFHApprovalRequired() bool {
+	return s.wfhApproval
+}
+
+// RequestWFH stores the user's request to work from home on its date, today or up to
+// MaxWFHAheadDays ahead. Without WFH_APPROVAL it is approved at once and the attendance of the
+// day recorded so far is marked remote. If the user already asked for the date, the earlier
+// request is returned with ErrWFHExists.
+func (s *Service) RequestWFH(ctx context.Context, request *models.WFHRequest) (*models.WFHRequest, error) {
+	today := utils.GetTodayDate()
+	last := utils.AddDays(utils.Now(), MaxWFHAheadDays).Format("2006-01-02")
+	if !utils.IsValidDateFormat(request.Date) || request.Date < today || request.Date > last {
+		return nil, fmt.Errorf("%w: %q", ErrWFHDate, request.Date)
+	}
+
+	wfh := *request
+	wfh.Status = models.WFHApproved
+	if s.wfhApproval {
+		wfh.Status = models.WFHPending
+	}
+	wfh.RequestedAt = time.Now()
+
+	saved, err := s.repo.InsertWFHRequest(ctx, &wfh)
+	if errors.Is(err, database.ErrDuplicate) {
+		existing, err := s.repo.GetUserWFHRequest(ctx, wfh.UserID, wfh.Date)
+		if err != nil {
+			return nil, err
+		}
+		return existing, ErrWFHExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if saved.Status == models.WFHApproved {
+		if err := s.markRemote(ctx, saved.UserID, saved.Date, true); err != nil {
+			return nil, err
+		}
+	}
+	return saved, nil
+}
+
+// DecideWFH approves or rejects a pending request to work from home. It returns the request and
+// false if it does not exist or was already decided, so a second press of a button changes nothing.
+func (s *Service) DecideWFH(ctx context.Context, id int64, approve bool, decidedBy int64) (*models.WFHRequest, bool, error) {
+	status := models.WFHRejected
+	if approve {
+		status = models.WFHApproved
+	}
+
+	decided, err := s.repo.DecideWFHRequest(ctx, id, status, decidedBy, time.Now())
+	if err != nil {
+		return nil, false, err
+	}
+
+	request, err := s.repo.GetWFHRequest(ctx, id)
+	if err != nil || request == nil {
+		return nil, false, err
+	}
+
+	// Attendance recorded before the approval is marked too
+	if decided && approve {
+		if err := s.markRemote(ctx, request.UserID, request.Date, true); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return request, decided, nil
+}
+
+// CancelWFH withdraws the user's request to work from home on date, today or later, and unmarks
+// the attendance of the day. It returns false if there was none.
+func (s *Service) CancelWFH(ctx context.Context, userID int64, date string) (bool, error) {
+	if !utils.IsValidDateFormat(date) || date < utils.GetTodayDate() {
+		return false, fmt.Errorf("%w: %q", ErrWFHDate, date)
+	}
+
+	removed, err := s.repo.DeleteWFHRequest(ctx, userID, date)
+	if err != nil || !removed {
+		return false, err
+	}
+	return true, s.markRemote(ctx, userID, date, false)
+}
+
+// GetUpcomingWFH returns the user's requests to work from home from today on
+func (s *Service) GetUpcomingWFH(ctx context.Context, userID int64) ([]models.WFHRequest, error) {
+	return s.repo.GetUserWFHRequests(ctx, userID, utils.GetTodayDate())
+}
+
+// WFHName returns the display name of the request's user, preferring their alias
+func (s *Service) WFHName(ctx context.Context, request *models.WFHRequest) string {
+	return s.formatUserName(ctx, &models.AttendanceRecord{
+		UserID:    request.UserID,
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+	})
+}
+
+// worksRemotely reports whether the user may work from home on date
+func (s *Service) worksRemotely(ctx context.Context, userID int64, date string) (bool, error) {
+	request, err := s.repo.GetUserWFHRequest(ctx, userID, date)
+	if err != nil {
+		return false, fmt.Errorf("failed to get wfh request: %w", err)
+	}
+	return request != nil && request.Status == models.WFHApproved, nil
+}
+
+// markRemote marks the user's attendance of date as remote or not
+func (s *Service) markRemote(ctx context.Context, userID int64, date string, remote bool) error {
+	changed, err := s.repo.SetAttendanceRemote(ctx, userID, date, remote)
+	if err != nil {
+		return fmt.Errorf("failed to mark attendance remote: %w", err)
+	}
+	if changed > 0 {
+		s.reports.invalidate(date)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package attendance
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"testing"
+)
+
+// checkInToday records a check-in of the user now and returns the date of today
+func checkInToday(t *testing.T, service *Service, userID int64) string {
+	t.Helper()
+
+	now := utils.Now()
+	today := utils.GetTodayDate()
+	record := &models.AttendanceRecord{UserID: userID, FirstName: "Sari", Timestamp: now, Type: "check_in", Date: today}
+	if _, err := service.repo.InsertAttendance(context.Background(), record); err != nil {
+		t.Fatalf("InsertAttendance: %v", err)
+	}
+	return today
+}
+
+// checkedInRemotely reports whether the user's check-in of date is marked remote
+func checkedInRemotely(t *testing.T, service *Service, userID int64, date string) bool {
+	t.Helper()
+
+	status, err := service.repo.GetUserAttendanceStatus(context.Background(), userID, date)
+	if err != nil || status.CheckInRecord == nil {
+		t.Fatalf("GetUserAttendanceStatus = %+v, %v; want a check-in", status, err)
+	}
+	return status.CheckInRecord.Remote
+}
+
+// TestRequestWFH asks to work from home on several days by a user who checked in today
+func TestRequestWFH(t *testing.T) {
+	day := func(offset int) string {
+		return utils.AddDays(utils.Now(), offset).Format("2006-01-02")
+	}
+
+	tests := []struct {
+		name     string
+		approval bool
+		date     string
+		status   string
+		remote   bool // Whether today's check-in is marked remote afterwards
+		wantErr  error
+	}{
+		{name: "today", date: day(0), status: models.WFHApproved, remote: true},
+		{name: "today, awaiting approval", approval: true, date: day(0), status: models.WFHPending},
+		{name: "last day ahead", date: day(MaxWFHAheadDays), status: models.WFHApproved},
+		{name: "yesterday", date: day(-1), wantErr: ErrWFHDate},
+		{name: "too far ahead", date: day(MaxWFHAheadDays + 1), wantErr: ErrWFHDate},
+		{name: "malformed", date: "besok", wantErr: ErrWFHDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			service.SetWFHApproval(tt.approval)
+			ctx := context.Background()
+			today := checkInToday(t, service, 1)
+
+			request, err := service.RequestWFH(ctx, &models.WFHRequest{UserID: 1, FirstName: "Sari", Date: tt.date})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RequestWFH error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && request.Status != tt.status {
+				t.Errorf("RequestWFH status = %q, want %q", request.Status, tt.status)
+			}
+			if remote := checkedInRemotely(t, service, 1, today); remote != tt.remote {
+				t.Errorf("check-in remote = %v, want %v", remote, tt.remote)
+			}
+			if err != nil {
+				return
+			}
+
+			again, err := service.RequestWFH(ctx, &models.WFHRequest{UserID: 1, FirstName: "Sari", Date: tt.date})
+			if !errors.Is(err, ErrWFHExists) || again == nil || again.ID != request.ID {
+				t.Errorf("second RequestWFH = %+v, %v; want the first request with ErrWFHExists", again, err)
+			}
+		})
+	}
+}
+
+// TestDecideWFH decides a pending request for today, then decides it again
+func TestDecideWFH(t *testing.T) {
+	tests := []struct {
+		name    string
+		approve bool
+		status  string
+		remote  bool
+	}{
+		{"approve", true, models.WFHApproved, true},
+		{"reject", false, models.WFHRejected, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := newTestService(t)
+			service.SetWFHApproval(true)
+			ctx := context.Background()
+			today := checkInToday(t, service, 1)
+			request, err := service.RequestWFH(ctx, &models.WFHRequest{UserID: 1, FirstName: "Sari", Date: today})
+			if err != nil {
+				t.Fatalf("RequestWFH: %v", err)
+			}
+
+			decided, changed, err := service.DecideWFH(ctx, request.ID, tt.approve, 900)
+			if err != nil || !changed {
+				t.Fatalf("DecideWFH = %v, %v; want a decision", changed, err)
+			}
+			if decided.Status != tt.status || decided.DecidedBy != 900 {
+				t.Errorf("decided request = %+v, want %s by 900", *decided, tt.status)
+			}
+			if remote := checkedInRemotely(t, service, 1, today); remote != tt.remote {
+				t.Errorf("check-in remote = %v, want %v", remote, tt.remote)
+			}
+			if worksRemotely, err := service.worksRemotely(ctx, 1, today); err != nil || worksRemotely != tt.remote {
+				t.Errorf("worksRemotely = %v, %v; want %v", worksRemotely, err, tt.remote)
+			}
+
+			// A second press of a button changes nothing
+			again, changed, err := service.DecideWFH(ctx, request.ID, !tt.approve, 901)
+			if err != nil || changed || again.Status != tt.status {
+				t.Errorf("second DecideWFH = %+v, %v, %v; want %s unchanged", again, changed, err, tt.status)
+			}
+		})
+	}
+}
+
+func TestCancelWFH(t *testing.T) {
+	service, _ := newTestService(t)
+	ctx := context.Background()
+	today := checkInToday(t, service, 1)
+	if _, err := service.RequestWFH(ctx, &models.WFHRequest{UserID: 1, FirstName: "Sari", Date: today}); err != nil {
+		t.Fatalf("RequestWFH: %v", err)
+	}
+
+	for _, want := range []bool{true, false} {
+		if cancelled, err := service.CancelWFH(ctx, 1, today); err != nil || cancelled != want {
+			t.Errorf("CancelWFH = %v, %v; want %v", cancelled, err, want)
+		}
+	}
+	if checkedInRemotely(t, service, 1, today) {
+		t.Errorf("check-in still remote after cancelling")
+	}
+	if _, err := service.CancelWFH(ctx, 1, utils.AddDays(utils.Now(), -1).Format("2006-01-02")); !errors.Is(err, ErrWFHDate) {
+		t.Errorf("CancelWFH of yesterday = %v, want ErrWFHDate", err)
+	}
+}
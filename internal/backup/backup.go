@@ -0,0 +1,184 @@
+// Package backup copies the SQLite database into rotated backup files while the bot keeps running,
+// optionally uploading every copy to S3-compatible storage
+package backup
+
+import (
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backup errors
+var (
+	ErrUnsupported = errors.New("only SQLite databases can be backed up")
+	ErrRunning     = errors.New("a backup is already running")
+)
+
+// Backup file names are attendance-<yyyyMMdd-HHmmss>.db in utils.Location, so they sort by age
+const (
+	filePrefix = "attendance-"
+	fileSuffix = ".db"
+	nameLayout = "20060102-150405"
+)
+
+// Uploader stores a copy of a backup file elsewhere
+type Uploader interface {
+	Upload(ctx context.Context, name, path string) error
+}
+
+// Backup is a backup file
+type Backup struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+	Uploaded  bool      `json:"uploaded"` // Set on new backups copied by the uploader
+	Removed   int       `json:"removed"`  // Old backups deleted to keep the configured number, on new backups
+}
+
+// Manager writes backups of a SQLite database into a directory, keeping the newest ones
+type Manager struct {
+	db       *database.DB
+	dir      string
+	keep     int
+	uploader Uploader
+	running  sync.Mutex
+}
+
+// New creates a manager writing backups of db into dir and keeping the newest keep of them
+func New(db *database.DB, dir string, keep int) *Manager {
+	return &Manager{db: db, dir: dir, keep: keep}
+}
+
+// SetUploader sets where every new backup is copied to; nil keeps backups local only
+func (m *Manager) SetUploader(uploader Uploader) {
+	m.uploader = uploader
+}
+
+// Dir returns the directory backups are written to
+func (m *Manager) Dir() string {
+	return m.dir
+}
+
+// Run writes a consistent copy of the database with VACUUM INTO, which does not block the bot's
+// reads and writes for long, then deletes the oldest backups beyond the number kept and uploads
+// the new one. If only pruning or the upload fails, the backup is returned together with the error.
+func (m *Manager) Run(ctx context.Context) (*Backup, error) {
+	if m.db.Engine() != database.SQLite {
+		return nil, ErrUnsupported
+	}
+	if !m.running.TryLock() {
+		return nil, ErrRunning
+	}
+	defer m.running.Unlock()
+
+	if err := os.MkdirAll(m.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	createdAt := utils.Now()
+	name := filePrefix + createdAt.Format(nameLayout) + fileSuffix
+	path := filepath.Join(m.dir, name)
+
+	// VACUUM INTO refuses to overwrite, and a half-written file must never look like a backup
+	partial := path + ".partial"
+	os.Remove(partial)
+	if _, err := m.db.ExecContext(ctx, "VACUUM INTO ?", partial); err != nil {
+		os.Remove(partial)
+		return nil, fmt.Errorf("failed to copy database: %w", err)
+	}
+	if err := os.Rename(partial, path); err != nil {
+		os.Remove(partial)
+		return nil, fmt.Errorf("failed to move backup into place: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat backup: %w", err)
+	}
+	backup := &Backup{Name: name, Path: path, Size: info.Size(), CreatedAt: createdAt}
+
+	var errs []error
+	backup.Removed, err = m.prune()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("backup written but old backups not removed: %w", err))
+	}
+
+	if m.uploader != nil {
+		if err := m.uploader.Upload(ctx, name, path); err != nil {
+			errs = append(errs, fmt.Errorf("backup written but not uploaded: %w", err))
+		} else {
+			backup.Uploaded = true
+		}
+	}
+
+	return backup, errors.Join(errs...)
+}
+
+// List returns the backups in the directory, newest first
+func (m *Manager) List() ([]Backup, error) {
+	entries, err := os.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		stamp, ok := strings.CutPrefix(entry.Name(), filePrefix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, fileSuffix)
+		if !ok {
+			continue
+		}
+		createdAt, err := time.ParseInLocation(nameLayout, stamp, utils.Location)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed meanwhile
+		}
+		backups = append(backups, Backup{
+			Name:      entry.Name(),
+			Path:      filepath.Join(m.dir, entry.Name()),
+			Size:      info.Size(),
+			CreatedAt: createdAt,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// prune deletes the oldest backups beyond the number kept, returning how many it deleted
+func (m *Manager) prune() (int, error) {
+	backups, err := m.List()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for i := m.keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil {
+			return removed, fmt.Errorf("failed to remove old backup: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,172 @@
+package backup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// uploadTimeout bounds one upload, which may be large
+const uploadTimeout = 10 * time.Minute
+
+// S3Options configures uploads to an S3-compatible bucket
+type S3Options struct {
+	Endpoint  string // e.g. https://s3.ap-southeast-1.amazonaws.com or a MinIO server
+	Bucket    string
+	Region    string
+	Prefix    string // Prepended to the file name to form the object key, e.g. "attendance/"
+	AccessKey string
+	SecretKey string
+}
+
+// S3 uploads backups to an S3-compatible bucket with path-style URLs and Signature Version 4,
+// which AWS S3, MinIO, Cloudflare R2 and most others accept
+type S3 struct {
+	options  S3Options
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3 creates an uploader for the bucket
+func NewS3(options S3Options) (*S3, error) {
+	endpoint, err := url.Parse(strings.TrimRight(options.Endpoint, "/"))
+	if err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", options.Endpoint)
+	}
+	return &S3{
+		options:  options,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: uploadTimeout},
+		now:      time.Now,
+	}, nil
+}
+
+// Upload puts the file at path into the bucket under the prefix and name
+func (s *S3) Upload(ctx context.Context, name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer file.Close()
+
+	// The payload is signed too, so the file is read once to hash it and once to send it
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return fmt.Errorf("failed to hash backup: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind backup: %w", err)
+	}
+
+	target := *s.endpoint
+	target.Path = s.endpoint.Path + "/" + s.options.Bucket + "/" + s.options.Prefix + name
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), file)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/vnd.sqlite3")
+	s.sign(req, hex.EncodeToString(hash.Sum(nil)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload backup: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload of %s refused with status %d: %s", name, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// sign adds the Signature Version 4 Authorization header for the payload's hex SHA-256 hash
+func (s *S3) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.options.Region + "/s3/aws4_request"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Host and the x-amz-* headers are signed; other headers may be changed by proxies
+	signed := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		if lower := strings.ToLower(key); strings.HasPrefix(lower, "x-amz-") {
+			signed[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var headers strings.Builder
+	for _, name := range names {
+		headers.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escapePath(req.URL.Path),
+		req.URL.RawQuery,
+		headers.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex(canonicalRequest)}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.options.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.options.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.options.AccessKey, scope, signedHeaders, signature))
+}
+
+// escapePath encodes every byte of a path except unreserved characters and slashes, as the
+// canonical request of Signature Version 4 requires
+func escapePath(path string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			escaped.WriteByte(c)
+		default:
+			fmt.Fprintf(&escaped, "%%%02X", c)
+		}
+	}
+	return escaped.String()
+}
+
+// sha256Hex returns the hex SHA-256 hash of s
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"strings"
+)
+
+// adminRole returns the user's role: RoleSuperAdmin for SUPER_ADMIN_ID, the stored role for
+// admins added with /admin add, or "" for everyone else
+func (b *Bot) adminRole(ctx context.Context, userID int64) string {
+	if userID == b.config.SuperAdminID {
+		return models.RoleSuperAdmin
+	}
+
+	role, err := b.attendanceService.GetAdminRole(ctx, userID)
+	if err != nil {
+		// Fail closed: a storage error must not grant access
+		logging.FromContext(ctx).Error("Failed to get admin role", "target_user_id", userID, "error", err)
+		return ""
+	}
+	return role
+}
+
+// isAdmin reports whether the user may run admin commands. Commands gated on the caller rather
+// than the chat should check this, so access follows the user into any chat.
+func (b *Bot) isAdmin(ctx context.Context, userID int64) bool {
+	return b.adminRole(ctx, userID) != ""
+}
+
+// handleAdmin handles the /admin command, with which the super-admin manages admins
+func (b *Bot) handleAdmin(ctx context.Context, msg *Message, args []string) error {
+	usage := usageMessage(ctx, "admin.usage")
+	if len(args) == 0 {
+		return b.sendMessage(ctx, msg.Chat.ID, usage)
+	}
+
+	switch args[0] {
+	case "list":
+		return b.handleAdminList(ctx, msg)
+	case "add", "remove":
+		if len(args) != 2 {
+			return b.sendMessage(ctx, msg.Chat.ID, usage)
+		}
+		userID, err := utils.ParseInteger(args[1])
+		if err != nil || !utils.IsValidTelegramUserID(userID) {
+			return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.invalid_user_id"))
+		}
+		if userID == b.config.SuperAdminID {
+			return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "admin.is_super_admin"))
+		}
+		if args[0] == "add" {
+			return b.handleAdminAdd(ctx, msg, userID)
+		}
+		return b.handleAdminRemove(ctx, msg, userID)
+	default:
+		return b.sendMessage(ctx, msg.Chat.ID, usage)
+	}
+}
+
+// handleAdminAdd grants a user admin rights
+func (b *Bot) handleAdminAdd(ctx context.Context, msg *Message, userID int64) error {
+	added, err := b.attendanceService.AddAdmin(ctx, userID, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.add_admin", "Failed to add admin", "target_user_id", userID)
+	}
+	if !added {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "admin.already_admin", "UserID", userID))
+	}
+
+	logging.FromContext(ctx).Warn("Admin added", "audit", models.AuditAdminAdded, "target_user_id", userID, "added_by", msg.From.ID)
+
+	// Users who never started a private chat with the bot cannot be notified
+	if err := b.sendMessage(ctx, userID, i18n.T(b.languageOf(ctx, userID), "admin.added_notice")); err != nil {
+		logging.FromContext(ctx).Info("Failed to notify new admin", "target_user_id", userID, "error", err)
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "admin.added", "UserID", userID))
+}
+
+// handleAdminRemove revokes a user's admin rights
+func (b *Bot) handleAdminRemove(ctx context.Context, msg *Message, userID int64) error {
+	removed, err := b.attendanceService.RemoveAdmin(ctx, userID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.remove_admin", "Failed to remove admin", "target_user_id", userID)
+	}
+	if !removed {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "admin.not_admin", "UserID", userID))
+	}
+
+	logging.FromContext(ctx).Warn("Admin removed", "audit", models.AuditAdminRemoved, "target_user_id", userID, "removed_by", msg.From.ID)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "admin.removed", "UserID", userID))
+}
+
+// handleAdminList lists the super-admin and the admins added with /admin add
+func (b *Bot) handleAdminList(ctx context.Context, msg *Message) error {
+	admins, err := b.attendanceService.GetAdmins(ctx)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_admins", "Failed to get admins")
+	}
+
+	var message strings.Builder
+	message.WriteString(tr(ctx, "admin.list_title") + "\n\n")
+	message.WriteString(tr(ctx, "admin.list_super_admin", "UserID", b.config.SuperAdminID) + "\n")
+	for _, admin := range admins {
+		message.WriteString(tr(ctx, "admin.list_entry",
+			"UserID", admin.UserID, "AddedAt", utils.FormatTime(admin.AddedAt, "2006-01-02 15:04"), "AddedBy", admin.AddedBy) + "\n")
+	}
+	if len(admins) == 0 {
+		message.WriteString("\n" + tr(ctx, "admin.list_empty"))
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, message.String())
+}
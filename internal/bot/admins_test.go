@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/pkg/models"
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestAdminCommand sends the super-admin's /admin commands in order and checks each reply
+func TestAdminCommand(t *testing.T) {
+	tb := newTestBot(t)
+	const userID = 1801
+	reply := func(key string, args ...any) string { return i18n.T(i18n.Default, key, args...) }
+
+	steps := []struct {
+		command string
+		want    string
+	}{
+		{"/admin", reply("admin.usage")},
+		{"/admin list", reply("admin.list_empty")},
+		{"/admin add 1801", reply("admin.added", "UserID", userID)},
+		{"/admin add 1801", reply("admin.already_admin", "UserID", userID)},
+		{"/admin add 900", reply("admin.is_super_admin")},
+		{"/admin add budi", reply("common.invalid_user_id")},
+		{"/admin add", reply("admin.usage")},
+		{"/admin promote 1801", reply("admin.usage")},
+		{"/admin list", "• user ID 1801, ditambahkan "},
+		{"/admin remove 1802", reply("admin.not_admin", "UserID", 1802)},
+		{"/admin remove 1801", reply("admin.removed", "UserID", userID)},
+		{"/admin remove 1801", reply("admin.not_admin", "UserID", userID)},
+	}
+
+	for _, step := range steps {
+		tb.send(t, testAdminID, step.command)
+		if got := tb.telegram.lastMessageTo(t, testAdminID); !strings.Contains(got, step.want) {
+			t.Errorf("%s replied %q, want %q", step.command, got, step.want)
+		}
+	}
+
+	// The new admin was told once, when they were added
+	if got := tb.telegram.messagesTo(userID); len(got) != 1 || got[0] != reply("admin.added_notice") {
+		t.Errorf("messages to the new admin = %q, want the notice once", got)
+	}
+}
+
+func TestAdminRole(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	for _, userID := range []int64{1801, 1802} {
+		if _, err := tb.service.AddAdmin(ctx, userID, testAdminID); err != nil {
+			t.Fatalf("AddAdmin: %v", err)
+		}
+	}
+	if _, err := tb.service.RemoveAdmin(ctx, 1802); err != nil {
+		t.Fatalf("RemoveAdmin: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  int64
+		role    string
+		isAdmin bool
+	}{
+		{"super-admin", testAdminID, models.RoleSuperAdmin, true},
+		{"added admin", 1801, models.RoleAdmin, true},
+		{"removed admin", 1802, "", false},
+		{"employee", 1803, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tb.adminRole(ctx, tt.userID); got != tt.role {
+				t.Errorf("adminRole(%d) = %q, want %q", tt.userID, got, tt.role)
+			}
+			if got := tb.isAdmin(ctx, tt.userID); got != tt.isAdmin {
+				t.Errorf("isAdmin(%d) = %v, want %v", tt.userID, got, tt.isAdmin)
+			}
+		})
+	}
+}
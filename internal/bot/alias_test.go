@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestAliasKeepsUnicodeNames sets aliases in several scripts with /alias: each is stored and
+// confirmed as typed, while emoji are dropped
+func TestAliasKeepsUnicodeNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantFirst string
+		wantLast  string
+	}{
+		{"indonesian", "/alias Nurul À’isyah", "Nurul", "À’isyah"},
+		{"balinese", "/alias Ngurah Putu Éka", "Ngurah", "Putu Éka"},
+		{"arabic", "/alias عائشة الحميراء", "عائشة", "الحميراء"},
+		{"chinese", "/alias 王 小明", "王", "小明"},
+		{"emoji troll", "/alias 🔥Budi🔥 💯", "Budi", ""},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newTestBot(t)
+			userID := int64(1100 + i)
+
+			tb.send(t, userID, tt.text)
+			alias, err := tb.service.GetUserAlias(context.Background(), userID)
+			if err != nil {
+				t.Fatalf("GetUserAlias: %v", err)
+			}
+			if alias == nil {
+				t.Fatalf("no alias stored; reply: %q", tb.telegram.lastMessageTo(t, userID))
+			}
+
+			last := ""
+			if alias.LastName != nil {
+				last = *alias.LastName
+			}
+			if alias.FirstName != tt.wantFirst || last != tt.wantLast {
+				t.Errorf("alias = %q %q, want %q %q", alias.FirstName, last, tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestAliasRefusesNameWithoutLetters(t *testing.T) {
+	tb := newTestBot(t)
+
+	tb.send(t, 1200, "/alias 🔥💯")
+	if got, want := tb.telegram.lastMessageTo(t, 1200), tr(context.Background(), "alias.invalid_first_name"); got != want {
+		t.Errorf("reply = %q, want %q", got, want)
+	}
+	if alias, err := tb.service.GetUserAlias(context.Background(), 1200); err != nil || alias != nil {
+		t.Errorf("GetUserAlias() = %v, %v, want no alias", alias, err)
+	}
+}
+
+// TestAliasConflictNeedsApproval has a second user take an existing alias in other case and
+// spacing: it waits for an admin instead of being set
+func TestAliasConflictNeedsApproval(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"same", "/alias Budi Santoso"},
+		{"lower case", "/alias budi santoso"},
+		{"upper case", "/alias BUDI SANTOSO"},
+		{"extra spaces", "/alias   Budi    Santoso  "},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newTestBot(t)
+			ctx := context.Background()
+			owner, requester := int64(1400+2*i), int64(1401+2*i)
+
+			tb.send(t, owner, "/alias Budi Santoso")
+			tb.send(t, requester, tt.text)
+
+			if alias, err := tb.service.GetUserAlias(ctx, requester); err != nil || alias != nil {
+				t.Fatalf("requester alias = %+v (%v), want none before approval", alias, err)
+			}
+			if got := tb.telegram.lastMessageTo(t, requester); !strings.Contains(got, "perlu persetujuan admin") {
+				t.Errorf("reply = %q, want the approval notice", got)
+			}
+			if got := tb.telegram.lastMessageTo(t, testAdminChatID); !strings.Contains(got, fmt.Sprint(requester)) {
+				t.Errorf("admin chat = %q, want the request of user %d", got, requester)
+			}
+
+			tb.deliver(t, &Message{
+				From: &User{ID: testAdminID, FirstName: "Admin"},
+				Chat: &Chat{ID: testAdminChatID, Type: "supergroup"},
+				Text: fmt.Sprintf("/aliasapprove %d", requester),
+			})
+			alias, err := tb.service.GetUserAlias(ctx, requester)
+			if err != nil || alias == nil {
+				t.Fatalf("requester alias = %+v (%v), want it set after approval", alias, err)
+			}
+		})
+	}
+}
+
+func TestAliasWithoutConflictIsSet(t *testing.T) {
+	tb := newTestBot(t)
+	tb.send(t, 1450, "/alias Budi Santoso")
+	tb.send(t, 1451, "/alias Budi Santosa")
+
+	alias, err := tb.service.GetUserAlias(context.Background(), 1451)
+	if err != nil || alias == nil || alias.FirstName != "Budi" || alias.LastName == nil || *alias.LastName != "Santosa" {
+		t.Errorf("alias = %+v (%v), want Budi Santosa set at once", alias, err)
+	}
+}
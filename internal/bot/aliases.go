@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// requestAliasApproval forwards a conflicting alias to the admin chat for approval
+func (b *Bot) requestAliasApproval(ctx context.Context, msg *Message, firstName string, lastName *string, conflicts []models.NameMatch) error {
+	aliasName := attendance.FullName(firstName, lastName)
+	logger := logging.FromContext(ctx).With("alias", aliasName, "conflicts", len(conflicts))
+
+	// Without an admin chat nobody could approve the request
+	if b.config.AdminChatID == 0 {
+		logger.Info("Conflicting alias refused")
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "alias.taken", "Alias", aliasName))
+	}
+
+	if err := b.attendanceService.RequestAlias(ctx, msg.From.ID, firstName, lastName); err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_alias_request", "Failed to save alias request")
+	}
+
+	name := strings.TrimSpace(msg.From.FirstName + " " + msg.From.LastName)
+	if msg.From.Username != "" {
+		name += " (@" + msg.From.Username + ")"
+	}
+
+	var alert strings.Builder
+	alert.WriteString(i18n.T(i18n.Default, "alias.approval_request", "Name", name, "UserID", msg.From.ID, "Alias", aliasName) + "\n")
+	writeNameMatches(&alert, i18n.Default, conflicts)
+	alert.WriteString("\n" + i18n.T(i18n.Default, "alias.approval_commands", "UserID", msg.From.ID))
+
+	if err := b.sendMessage(ctx, b.config.AdminChatID, alert.String()); err != nil {
+		logger.Error("Failed to send alias approval request", "error", err)
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "alias.request_failed"))
+	}
+
+	logger.Warn("Conflicting alias awaiting approval", "audit", "alias_requested")
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "alias.requested", "Alias", aliasName))
+}
+
+// handleAliasDecision handles the /aliasapprove and /aliasreject commands
+func (b *Bot) handleAliasDecision(ctx context.Context, msg *Message, args []string, approve bool) error {
+	command := "/aliasreject"
+	if approve {
+		command = "/aliasapprove"
+	}
+	if len(args) != 1 {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.invalid_format")+" "+command+" [user_id]")
+	}
+	userID, err := utils.ParseInteger(args[0])
+	if err != nil || !utils.IsValidTelegramUserID(userID) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.invalid_user_id"))
+	}
+
+	var request *models.AliasRequest
+	if approve {
+		request, err = b.attendanceService.ApproveAlias(ctx, userID)
+	} else {
+		request, err = b.attendanceService.RejectAlias(ctx, userID)
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.process_alias_request", "Failed to decide alias request", "target_user_id", userID)
+	}
+	if request == nil {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "alias.no_request", "UserID", userID))
+	}
+
+	aliasName := attendance.FullName(request.FirstName, request.LastName)
+	audit, reply, notice := models.AuditAliasRejected, "alias.rejected", "alias.rejected_notice"
+	if approve {
+		audit, reply, notice = models.AuditAliasApproved, "alias.approved", "alias.approved_notice"
+	}
+	b.attendanceService.Audit(ctx, models.AuditEntry{
+		ActorID:      msg.From.ID,
+		Action:       audit,
+		TargetUserID: userID,
+		Details:      aliasName,
+	})
+
+	logging.FromContext(ctx).Warn("Alias request decided",
+		"audit", audit,
+		"target_user_id", userID,
+		"alias", aliasName,
+		"decided_by", msg.From.ID)
+
+	// Users who never started a private chat with the bot cannot be notified
+	if err := b.sendMessage(ctx, userID, i18n.T(b.languageOf(ctx, userID), notice, "Alias", aliasName)); err != nil {
+		logging.FromContext(ctx).Info("Failed to notify user about alias decision", "target_user_id", userID, "error", err)
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, reply, "Alias", aliasName, "UserID", userID))
+}
+
+// handleAliasConflicts handles the /aliasconflicts command, listing names shared by several
+// users and the alias requests awaiting approval
+func (b *Bot) handleAliasConflicts(ctx context.Context, msg *Message) error {
+	collisions, err := b.attendanceService.GetNameCollisions(ctx)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.check_duplicate_names", "Failed to get name collisions")
+	}
+	requests, err := b.attendanceService.GetAliasRequests(ctx)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_alias_requests", "Failed to get alias requests")
+	}
+
+	var message strings.Builder
+	message.WriteString(tr(ctx, "alias.conflicts_title") + "\n")
+	if len(collisions) == 0 {
+		message.WriteString("\n" + tr(ctx, "alias.conflicts_empty") + "\n")
+	}
+	for i, match := range collisions {
+		if i == 0 || match.Key != collisions[i-1].Key {
+			message.WriteString(fmt.Sprintf("\n%q:\n", match.Key))
+		}
+		writeNameMatches(&message, i18n.FromContext(ctx), collisions[i:i+1])
+	}
+
+	if len(requests) > 0 {
+		message.WriteString("\n" + tr(ctx, "alias.pending") + "\n")
+		for _, request := range requests {
+			message.WriteString(fmt.Sprintf("• user ID %d → %q (%s)\n  /aliasapprove %d · /aliasreject %d\n",
+				request.UserID, attendance.FullName(request.FirstName, request.LastName),
+				utils.FormatTime(request.RequestedAt, "2006-01-02 15:04"), request.UserID, request.UserID))
+		}
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, message.String())
+}
+
+// writeNameMatches writes one line per matched name in lang
+func writeNameMatches(message *strings.Builder, lang string, matches []models.NameMatch) {
+	for _, match := range matches {
+		key := "alias.match_telegram"
+		if match.Kind == models.NameKindAlias {
+			key = "alias.match_alias"
+		}
+		message.WriteString(i18n.T(lang, key, "UserID", match.UserID, "Name", attendance.FullName(match.FirstName, match.LastName)) + "\n")
+	}
+}
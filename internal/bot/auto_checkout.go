@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"time"
+)
+
+// runAutoCheckout handles the check-ins still open at AUTO_CHECKOUT_TIME: in record mode it records
+// an auto check-out for each, in remind mode it asks their users to check out. Either way the users
+// are told in their language.
+func (b *Bot) runAutoCheckout(ctx context.Context) {
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+	logger := b.logger.With("request_id", logging.RequestID(ctx), "mode", b.config.AutoCheckout)
+	ctx = logging.NewContext(ctx, logger)
+
+	now := utils.Now()
+	date := now.Format("2006-01-02")
+
+	var records []models.AttendanceRecord
+	var err error
+	key := "auto_checkout.reminder"
+	if b.config.AutoCheckout == "record" {
+		records, err = b.attendanceService.AutoCheckOut(ctx, date, now)
+		key = "auto_checkout.recorded"
+	} else {
+		records, err = b.attendanceService.GetOpenCheckIns(ctx, date, now)
+	}
+	if err != nil {
+		// Check-outs recorded before the failure are still reported below
+		logger.Error("Failed to handle open check-ins", "date", date, "error", err)
+	}
+
+	for i, record := range records {
+		if b.config.AutoCheckout == "record" {
+			logger.Warn("Auto check-out recorded",
+				"audit", "auto_checkout",
+				"target_user_id", record.UserID,
+				"record_id", record.ID,
+				"time", utils.FormatTime(record.Timestamp, "HH:mm"))
+		}
+
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				logger.Warn("Auto check-out notices interrupted", "remaining", len(records)-i)
+				return
+			case <-time.After(broadcastInterval):
+			}
+		}
+
+		// Users who never started a private chat with the bot cannot be notified
+		text := i18n.T(b.languageOf(ctx, record.UserID), key, "Time", utils.FormatTime(record.Timestamp, "HH:mm"))
+		if err := b.sendBroadcastMessage(ctx, record.UserID, text); err != nil {
+			logger.Info("Failed to notify user about open check-in", "target_user_id", record.UserID, "error", err)
+		}
+	}
+
+	logger.Info("Open check-ins handled", "date", date, "users", len(records))
+}
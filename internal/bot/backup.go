@@ -0,0 +1,135 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/backup"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxListedBackups bounds the backups /backup list shows
+const maxListedBackups = 10
+
+// handleBackup handles the /backup command, with which admins back up the database at once or
+// list the backups kept
+func (b *Bot) handleBackup(ctx context.Context, msg *Message, args []string) error {
+	switch {
+	case len(args) == 1 && strings.EqualFold(args[0], "now"):
+		return b.backupNow(ctx, msg)
+	case len(args) == 0, len(args) == 1 && strings.EqualFold(args[0], "list"):
+		return b.sendBackups(ctx, msg)
+	default:
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "backup.usage"))
+	}
+}
+
+// backupNow backs up the database and reports the new backup
+func (b *Bot) backupNow(ctx context.Context, msg *Message) error {
+	made, err := b.attendanceService.Backup(ctx, msg.From.ID)
+	switch {
+	case errors.Is(err, attendance.ErrBackupsDisabled):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "backup.disabled"))
+	case errors.Is(err, backup.ErrRunning):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "backup.running"))
+	case made == nil:
+		return b.replyError(ctx, msg.Chat.ID, err, "action.backup", "Failed to back up database")
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Warn("Database backed up",
+		"audit", models.AuditBackupCreated,
+		"backup", made.Name,
+		"size", made.Size,
+		"removed", made.Removed,
+		"uploaded", made.Uploaded)
+
+	message := tr(ctx, "backup.done", "Name", made.Name, "Size", formatSize(made.Size), "Removed", made.Removed)
+	switch {
+	case err != nil:
+		logger.Error("Backup incomplete", "backup", made.Name, "error", err)
+		message += "\n" + tr(ctx, "backup.incomplete")
+	case made.Uploaded:
+		message += "\n" + tr(ctx, "backup.uploaded")
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, message)
+}
+
+// sendBackups lists the newest backups kept
+func (b *Bot) sendBackups(ctx context.Context, msg *Message) error {
+	backups, err := b.attendanceService.GetBackups()
+	if errors.Is(err, attendance.ErrBackupsDisabled) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "backup.disabled"))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_backups", "Failed to list backups")
+	}
+
+	var message strings.Builder
+	message.WriteString(tr(ctx, "backup.usage") + "\n\n")
+	if len(backups) == 0 {
+		message.WriteString(tr(ctx, "backup.list_empty"))
+		return b.sendMessage(ctx, msg.Chat.ID, message.String())
+	}
+
+	message.WriteString(tr(ctx, "backup.list_title", "Count", len(backups), "Keep", b.config.BackupKeep) + "\n")
+	for i, kept := range backups {
+		if i == maxListedBackups {
+			message.WriteString(tr(ctx, "backup.list_more", "Count", len(backups)-i) + "\n")
+			break
+		}
+		message.WriteString(tr(ctx, "backup.list_entry",
+			"Name", kept.Name, "Time", utils.FormatTime(kept.CreatedAt, "2006-01-02 15:04"), "Size", formatSize(kept.Size)) + "\n")
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, message.String())
+}
+
+// runScheduledBackup backs up the database on BACKUP_SCHEDULE, alerting the admin chat when it fails
+func (b *Bot) runScheduledBackup(ctx context.Context) {
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+	logger := b.logger.With("request_id", logging.RequestID(ctx))
+	ctx = logging.NewContext(ctx, logger)
+
+	made, err := b.attendanceService.Backup(ctx, 0)
+	if err == nil {
+		logger.Info("Database backed up", "backup", made.Name, "size", made.Size, "removed", made.Removed, "uploaded", made.Uploaded)
+		return
+	}
+	if errors.Is(err, backup.ErrRunning) {
+		logger.Info("Skipping scheduled backup, another backup is running")
+		return
+	}
+
+	key := "backup.failed_alert"
+	if made != nil {
+		key = "backup.incomplete_alert"
+	}
+	logger.Error("Scheduled backup failed", "error", err)
+	if b.config.AdminChatID == 0 {
+		return
+	}
+	if err := b.sendMessage(ctx, b.config.AdminChatID, i18n.T(i18n.Default, key, "Error", err.Error())); err != nil {
+		logger.Error("Failed to send backup alert", "error", err)
+	}
+}
+
+// formatSize formats a number of bytes for people, e.g. 1.5 MB
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value, suffix := float64(bytes)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
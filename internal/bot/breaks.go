@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"strings"
+)
+
+// handleBreak handles the /break command, with which checked-in users start and end breaks that
+// are not counted as work
+func (b *Bot) handleBreak(ctx context.Context, msg *Message, args []string) error {
+	if len(args) != 1 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "break.usage"))
+	}
+
+	username, firstName, lastName := recordedIdentity(msg.From)
+	record := &models.AttendanceRecord{
+		UserID:    msg.From.ID,
+		Username:  username,
+		FirstName: firstName,
+		LastName:  lastName,
+	}
+
+	var saved *models.AttendanceRecord
+	var status *models.AttendanceStatus
+	var err error
+	switch strings.ToLower(args[0]) {
+	case "start":
+		saved, status, err = b.attendanceService.StartBreak(ctx, record)
+	case "end":
+		saved, status, err = b.attendanceService.EndBreak(ctx, record)
+	default:
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "break.usage"))
+	}
+
+	switch {
+	case errors.Is(err, attendance.ErrBreaksDisabled):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "break.disabled"))
+	case errors.Is(err, attendance.ErrBreakNotCheckedIn):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "break.not_checked_in"))
+	case errors.Is(err, attendance.ErrBreakCheckedOut):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "break.checked_out"))
+	case errors.Is(err, attendance.ErrOnBreak):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "break.already_started"))
+	case errors.Is(err, attendance.ErrNotOnBreak):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "break.not_started"))
+	case errors.Is(err, attendance.ErrBreakLimit):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "break.limit", "Max", b.attendanceService.MaxBreaks()))
+	case err != nil:
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_break", "Failed to record break", "type", args[0])
+	}
+
+	logging.FromContext(ctx).Info("Break recorded", "type", saved.Type, "date", saved.Date, "record_id", saved.ID)
+
+	now := utils.FormatTimeIn(saved.Timestamp, "HH:mm", b.userLocation(ctx, msg.From.ID))
+	if saved.Type == "break_start" {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "break.started",
+			"Time", now, "Count", status.BreakCount(), "Max", b.attendanceService.MaxBreaks()))
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "break.ended",
+		"Time", now, "Total", utils.FormatDuration(status.BreakTime(saved.Timestamp), i18n.FromContext(ctx))))
+}
@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"context"
+	"strings"
+)
+
+// handleForgot handles the /forgot command, telling the admins a user lost their authenticator
+func (b *Bot) handleForgot(ctx context.Context, msg *Message) error {
+	if b.config.AdminChatID == 0 {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "forgot.no_admin_chat"))
+	}
+
+	name := strings.TrimSpace(msg.From.FirstName + " " + msg.From.LastName)
+	if msg.From.Username != "" {
+		name += " (@" + msg.From.Username + ")"
+	}
+
+	alert := i18n.T(i18n.Default, "forgot.alert", "Name", name, "UserID", msg.From.ID)
+	if err := b.sendMessage(ctx, b.config.AdminChatID, alert); err != nil {
+		logging.FromContext(ctx).Error("Failed to notify admins about lost authenticator", "error", err)
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "forgot.failed"))
+	}
+
+	logging.FromContext(ctx).Warn("Lost authenticator reported", "audit", "bypass_requested")
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "forgot.sent", "Minutes", int(attendance.BypassCodeTTL.Minutes())))
+}
+
+// handleBypass handles the /bypass command, issuing a single-use code for a user
+func (b *Bot) handleBypass(ctx context.Context, msg *Message, args []string) error {
+	if len(args) != 1 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "bypass.usage"))
+	}
+	userID, err := utils.ParseInteger(args[0])
+	if err != nil || !utils.IsValidTelegramUserID(userID) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.invalid_user_id"))
+	}
+
+	code, expires, err := b.attendanceService.IssueBypassCode(ctx, userID, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.create_bypass", "Failed to issue bypass code", "target_user_id", userID)
+	}
+
+	logging.FromContext(ctx).Warn("Bypass code issued",
+		"audit", "bypass_issued",
+		"target_user_id", userID,
+		"issued_by", msg.From.ID,
+		"expires_at", expires)
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "bypass.issued", "UserID", userID, "Code", code, "Expires", utils.FormatTime(expires, "HH:mm"), "Zone", utils.ZoneName(expires)))
+}
+
+// notifyBypassUsed tells the admin chat that a bypass code was redeemed
+func (b *Bot) notifyBypassUsed(ctx context.Context, msg *Message, username string, result *attendance.AttendanceResult) {
+	if b.config.AdminChatID == 0 || result.Record == nil {
+		return
+	}
+
+	alert := i18n.T(i18n.Default, "bypass.used_alert", "Username", username, "UserID", msg.From.ID,
+		"Type", attendanceTypeLabel(i18n.Default, result.Record.Type), "Time", utils.FormatTime(result.Record.Timestamp, "HH:mm"))
+	if err := b.sendMessage(ctx, b.config.AdminChatID, alert); err != nil {
+		logging.FromContext(ctx).Error("Failed to send bypass usage alert", "error", err)
+	}
+}
+
+// attendanceTypeLabel returns the label of an attendance type in lang
+func attendanceTypeLabel(lang, attendanceType string) string {
+	if attendanceType == "check_out" {
+		return i18n.T(lang, "attendance.type.check_out")
+	}
+	return i18n.T(lang, "attendance.type.check_in")
+}
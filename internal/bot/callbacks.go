@@ -0,0 +1,34 @@
+package bot
+
+import (
+	"context"
+	"strings"
+)
+
+// callbackHandler handles an inline keyboard button press. data is the button's callback data
+// after the registered prefix and its colon.
+type callbackHandler func(b *Bot, ctx context.Context, query *CallbackQuery, data string) error
+
+// callbackHandlers maps the prefix of a button's callback data to its handler. To add buttons to
+// a command, give them callback data "<prefix>:<data>" and register the prefix here. Handlers
+// must answer the query, or Telegram keeps showing a loading indicator on the button.
+var callbackHandlers = map[string]callbackHandler{
+	"kiosk":      (*Bot).handleKioskCallback,
+	"report":     (*Bot).handleReportCallback,
+	"fullreport": (*Bot).handleFullReportCallback,
+	"history":    (*Bot).handleHistoryCallback,
+	"leave":      (*Bot).handleLeaveCallback,
+	"wfh":        (*Bot).handleWFHCallback,
+	"language":   (*Bot).handleLanguageCallback,
+}
+
+// handleCallbackQuery routes an inline keyboard button press by the prefix of its data
+func (b *Bot) handleCallbackQuery(ctx context.Context, query *CallbackQuery) error {
+	prefix, data, _ := strings.Cut(query.Data, ":")
+	if handler, ok := callbackHandlers[prefix]; ok {
+		return handler(b, ctx, query, data)
+	}
+
+	// Buttons of removed features may still be on old messages
+	return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "common.button_expired"))
+}
@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"testing"
+)
+
+// TestHandleCallbackQuery presses buttons with registered and unknown prefixes: each press is
+// answered, and only registered prefixes reach their handler
+func TestHandleCallbackQuery(t *testing.T) {
+	const userID = 1701
+	expired := i18n.T(i18n.Default, "common.button_expired")
+
+	tests := []struct {
+		name   string
+		data   string
+		answer string
+		edits  int
+	}{
+		{"registered prefix", "language:en", "", 1},
+		{"unknown prefix", "removed:1", expired, 0},
+		{"partial prefix", "lang:en", expired, 0},
+		{"empty", "", expired, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newTestBot(t)
+			tb.press(t, userID, userID, tt.data)
+
+			answers := tb.telegram.sent("answerCallbackQuery")
+			if len(answers) != 1 {
+				t.Fatalf("%d answers, want 1", len(answers))
+			}
+			if got, _ := answers[0].Payload["text"].(string); got != tt.answer {
+				t.Errorf("answer = %q, want %q", got, tt.answer)
+			}
+			if got := len(tb.telegram.sent("editMessageText")); got != tt.edits {
+				t.Errorf("%d messages edited, want %d", got, tt.edits)
+			}
+		})
+	}
+}
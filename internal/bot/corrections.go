@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxFixReasonLength bounds the reason given with an attendance correction, in characters
+const maxFixReasonLength = 200
+
+// handleFix handles the /fix command, with which admins add, change or delete a user's check-in
+// or check-out, e.g. after a forgotten check-out. Every correction lands in the audit log.
+func (b *Bot) handleFix(ctx context.Context, msg *Message, args []string) error {
+	if len(args) < 5 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "fix.usage"))
+	}
+
+	userID, err := utils.ParseInteger(args[0])
+	if err != nil || !utils.IsValidTelegramUserID(userID) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.invalid_user_id"))
+	}
+	if !utils.IsValidDateFormat(args[1]) {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "fix.usage"))
+	}
+
+	correction := attendance.Correction{
+		UserID:  userID,
+		Date:    args[1],
+		ActorID: msg.From.ID,
+		Reason:  strings.TrimSpace(strings.Join(args[4:], " ")),
+	}
+	switch strings.ToLower(args[2]) {
+	case "in":
+		correction.Type = "check_in"
+	case "out":
+		correction.Type = "check_out"
+	default:
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "fix.usage"))
+	}
+	if strings.EqualFold(args[3], "delete") {
+		correction.Delete = true
+	} else if correction.Clock, err = utils.ParseClock(args[3]); err != nil {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "fix.usage"))
+	}
+	if utf8.RuneCountInString(correction.Reason) > maxFixReasonLength {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "fix.reason_too_long", "Max", maxFixReasonLength))
+	}
+
+	entry, refusal, err := b.attendanceService.FixAttendance(ctx, correction)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.fix_attendance", "Failed to correct attendance",
+			"target_user_id", userID, "date", correction.Date, "type", correction.Type)
+	}
+	if refusal != "" {
+		return b.sendMessage(ctx, msg.Chat.ID, refusal)
+	}
+
+	logging.FromContext(ctx).Warn("Attendance corrected",
+		"audit", entry.Action,
+		"target_user_id", userID,
+		"record_id", entry.RecordID,
+		"details", entry.Details,
+		"reason", entry.Reason)
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "fix.done", "Entry", describeAuditEntry(ctx, *entry)))
+}
+
+// handleAuditLog handles the /auditlog command, which lists the newest audit log entries, for
+// everyone or for the given user, or with "csv" exports those of a date range
+func (b *Bot) handleAuditLog(ctx context.Context, msg *Message, args []string) error {
+	if len(args) > 0 && strings.EqualFold(args[0], "csv") {
+		return b.sendAuditLogCSV(ctx, msg, args[1:])
+	}
+
+	var userID int64
+	if len(args) > 1 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "auditlog.usage"))
+	}
+	if len(args) == 1 {
+		id, err := utils.ParseInteger(args[0])
+		if err != nil || !utils.IsValidTelegramUserID(id) {
+			return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.invalid_user_id"))
+		}
+		userID = id
+	}
+
+	entries, err := b.attendanceService.GetAuditLog(ctx, userID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_audit_log", "Failed to get audit log", "target_user_id", userID)
+	}
+	if len(entries) == 0 {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "auditlog.empty"))
+	}
+
+	var message strings.Builder
+	message.WriteString(tr(ctx, "auditlog.title", "Count", len(entries)) + "\n\n")
+	for _, entry := range entries {
+		message.WriteString(describeAuditEntry(ctx, entry) + "\n")
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, message.String())
+}
+
+// sendAuditLogCSV sends the audit log entries recorded from one date to another as a CSV
+// document, itself recorded as a report download
+func (b *Bot) sendAuditLogCSV(ctx context.Context, msg *Message, args []string) error {
+	if len(args) != 2 || !utils.IsValidDateFormat(args[0]) || !utils.IsValidDateFormat(args[1]) {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "auditlog.usage"))
+	}
+	startDate, endDate := args[0], args[1]
+	logger := logging.FromContext(ctx)
+
+	entries, err := b.attendanceService.GetAuditLogRange(ctx, startDate, endDate)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_audit_log", "Failed to get audit log",
+			"start_date", startDate, "end_date", endDate)
+	}
+	if len(entries) == 0 {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "auditlog.empty_range", "Start", startDate, "End", endDate))
+	}
+
+	data, err := b.csvGenerator.GenerateAuditReport(entries)
+	if err != nil {
+		logger.Error("Failed to generate audit log CSV", "error", err)
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "file.create_csv_failed"))
+	}
+
+	filename := fmt.Sprintf("audit_log_%s_to_%s.csv", startDate, endDate)
+	caption := tr(ctx, "auditlog.caption", "Start", startDate, "End", endDate, "Count", len(entries))
+	if err := b.api.SendDocumentWithOptions(ctx, msg.Chat.ID, bytes.NewReader(data), filename, &SendDocumentOptions{Caption: caption}); err != nil {
+		logger.Error("Failed to send CSV document", "error", err)
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "file.send_failed"))
+	}
+	b.attendanceService.Audit(ctx, models.AuditEntry{
+		ActorID: msg.From.ID,
+		Action:  models.AuditReportDownloaded,
+		Details: fmt.Sprintf("audit_log %s..%s csv", startDate, endDate),
+	})
+
+	return nil
+}
+
+// describeAuditEntry renders an audit log entry as when, who, what and why
+func describeAuditEntry(ctx context.Context, entry models.AuditEntry) string {
+	key := "auditlog.entry"
+	if entry.TargetUserID == 0 {
+		key = "auditlog.entry_no_user"
+	}
+	description := tr(ctx, key,
+		"Time", utils.FormatDate(entry.CreatedAt, "yyyy-MM-dd")+" "+utils.FormatTime(entry.CreatedAt, "HH:mm"),
+		"Action", tr(ctx, "auditlog.action."+entry.Action),
+		"ActorID", entry.ActorID,
+		"UserID", entry.TargetUserID,
+		"Details", entry.Details)
+	if entry.Reason != "" {
+		description += "\n  " + tr(ctx, "auditlog.reason", "Reason", entry.Reason)
+	}
+	return description
+}
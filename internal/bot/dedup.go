@@ -0,0 +1,45 @@
+package bot
+
+import "sync"
+
+// recentUpdatesSize bounds how many update IDs are remembered for deduplication
+const recentUpdatesSize = 1024
+
+// recentUpdates remembers the most recent update IDs in a fixed-size ring buffer,
+// so a redelivered update can be skipped in constant time and bounded memory
+type recentUpdates struct {
+	mu   sync.Mutex
+	ring []int64
+	next int
+	seen map[int64]struct{}
+}
+
+// newRecentUpdates creates a store remembering up to size update IDs
+func newRecentUpdates(size int) *recentUpdates {
+	return &recentUpdates{
+		ring: make([]int64, 0, size),
+		seen: make(map[int64]struct{}, size),
+	}
+}
+
+// markSeen records the update ID and reports whether it had already been recorded
+func (r *recentUpdates) markSeen(updateID int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.seen[updateID]; ok {
+		return true
+	}
+
+	// Evict the oldest ID once the ring is full
+	if len(r.ring) < cap(r.ring) {
+		r.ring = append(r.ring, updateID)
+	} else {
+		delete(r.seen, r.ring[r.next])
+		r.ring[r.next] = updateID
+		r.next = (r.next + 1) % len(r.ring)
+	}
+	r.seen[updateID] = struct{}{}
+
+	return false
+}
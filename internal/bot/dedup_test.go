@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecentUpdatesEvictsOldest(t *testing.T) {
+	recent := newRecentUpdates(3)
+
+	steps := []struct {
+		updateID int64
+		want     bool
+	}{
+		{1, false},
+		{2, false},
+		{3, false},
+		{1, true},
+		{4, false}, // evicts 1
+		{2, true},
+		{1, false}, // evicts 2
+		{2, false}, // evicts 3
+		{4, true},
+		{3, false},
+	}
+
+	for i, step := range steps {
+		if got := recent.markSeen(step.updateID); got != step.want {
+			t.Errorf("step %d: markSeen(%d) = %v, want %v", i, step.updateID, got, step.want)
+		}
+	}
+}
+
+func TestRecentUpdatesConcurrent(t *testing.T) {
+	recent := newRecentUpdates(recentUpdatesSize)
+
+	const ids = 200
+	var first atomic.Int64
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range int64(ids) {
+				if !recent.markSeen(id) {
+					first.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := first.Load(); got != ids {
+		t.Errorf("updates seen for the first time = %d, want %d", got, ids)
+	}
+}
+
+// TestDuplicateUpdateHandledOnce delivers the same check-in several times at once, as a
+// redelivery racing the original would: one record is stored and one reply is sent
+func TestDuplicateUpdateHandledOnce(t *testing.T) {
+	tb := newTestBot(t)
+	const userID = 301
+
+	code, err := attendance.NewTOTPService(testSecret).Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	update := message(41, userID, code)
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func(update Update) {
+			defer wg.Done()
+			if err := tb.handleUpdate(context.Background(), &update); err != nil {
+				t.Errorf("handleUpdate: %v", err)
+			}
+		}(update)
+	}
+	wg.Wait()
+
+	records, err := tb.repo.ListAttendance(context.Background(), userID, "")
+	if err != nil {
+		t.Fatalf("ListAttendance: %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("records = %d, want 1", len(records))
+	}
+	if replies := tb.telegram.messagesTo(userID); len(replies) != 1 {
+		t.Errorf("replies = %d, want 1: %q", len(replies), replies)
+	}
+}
@@ -0,0 +1,254 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/notify"
+	"attendance-bot/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// broadcastInterval spaces messages of one broadcast, keeping well below Telegram's limit of
+// about 30 messages per second
+const broadcastInterval = 50 * time.Millisecond
+
+// handleSubscribe handles the /subscribe command
+func (b *Bot) handleSubscribe(ctx context.Context, msg *Message, args []string) error {
+	if !b.canSubscribe(msg) {
+		return b.refuseSubscription(ctx, msg)
+	}
+
+	if len(args) == 0 {
+		return b.sendSubscriptions(ctx, msg)
+	}
+
+	digest := strings.ToLower(args[0])
+	added, err := b.attendanceService.Subscribe(ctx, msg.Chat.ID, msg.From.ID, digest)
+	if errors.Is(err, attendance.ErrUnknownDigest) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "digest.unknown", "Digest", digest))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_subscription", "Failed to subscribe", "digest", digest)
+	}
+
+	if !added {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "digest.already_subscribed", "Digest", digest))
+	}
+
+	logging.FromContext(ctx).Info("Chat subscribed", "digest", digest, "chat_id", msg.Chat.ID)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "digest.subscribed", "Digest", digest, "Note", b.deliveryNote(ctx, digest)))
+}
+
+// handleUnsubscribe handles the /unsubscribe command. Anyone may unsubscribe, so a user
+// who lost supervisor rights can still stop their subscription.
+func (b *Bot) handleUnsubscribe(ctx context.Context, msg *Message, args []string) error {
+	if len(args) == 0 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "digest.unsubscribe_usage"))
+	}
+
+	digest := strings.ToLower(args[0])
+	removed, err := b.attendanceService.Unsubscribe(ctx, msg.Chat.ID, digest)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.remove_subscription", "Failed to unsubscribe", "digest", digest)
+	}
+
+	if !removed {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "digest.not_subscribed", "Digest", digest))
+	}
+
+	logging.FromContext(ctx).Info("Chat unsubscribed", "digest", digest, "chat_id", msg.Chat.ID)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "digest.unsubscribed", "Digest", digest))
+}
+
+// canSubscribe reports whether the sender may subscribe this chat to company-wide reports.
+// Reports are delivered personally, so subscriptions are only made in private chats.
+func (b *Bot) canSubscribe(msg *Message) bool {
+	if msg.Chat.Type != "private" {
+		return false
+	}
+	return b.config.IsSupervisor(msg.From.ID) || b.isAdminChat(msg.Chat.ID)
+}
+
+// refuseSubscription explains why the sender cannot subscribe
+func (b *Bot) refuseSubscription(ctx context.Context, msg *Message) error {
+	if msg.Chat.Type != "private" {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "digest.private_only"))
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "digest.refused"))
+}
+
+// sendSubscriptions lists the available digests and the chat's current subscriptions
+func (b *Bot) sendSubscriptions(ctx context.Context, msg *Message) error {
+	subscriptions, err := b.attendanceService.GetChatSubscriptions(ctx, msg.Chat.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_subscription", "Failed to get subscriptions")
+	}
+
+	subscribed := make(map[string]bool, len(subscriptions))
+	for _, subscription := range subscriptions {
+		subscribed[subscription.Digest] = true
+	}
+
+	var message strings.Builder
+	message.WriteString(tr(ctx, "digest.list_title") + "\n")
+	for _, digest := range attendance.Digests {
+		status := ""
+		switch {
+		case subscribed[digest.Name]:
+			status = " " + tr(ctx, "digest.status_subscribed")
+		case !digest.Available:
+			status = " " + tr(ctx, "digest.status_coming_soon")
+		}
+		message.WriteString(fmt.Sprintf("• %s - %s%s\n", digest.Name, tr(ctx, "digest."+digest.Name), status))
+	}
+
+	if len(subscriptions) == 0 {
+		message.WriteString("\n" + tr(ctx, "digest.none") + "\n")
+	}
+
+	message.WriteString("\n" + tr(ctx, "digest.list_usage"))
+	if clock, ok := b.config.DailyReportClock(); ok {
+		message.WriteString("\n" + tr(ctx, "digest.daily_schedule", "Clock", clock, "Zone", utils.ZoneName(time.Now())))
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, message.String())
+}
+
+// deliveryNote tells a new subscriber when the digest is delivered
+func (b *Bot) deliveryNote(ctx context.Context, digest string) string {
+	if digest != attendance.DigestDaily {
+		return ""
+	}
+	clock, ok := b.config.DailyReportClock()
+	if !ok {
+		return tr(ctx, "digest.delivery_disabled")
+	}
+	return tr(ctx, "digest.delivery_daily", "Clock", clock, "Zone", utils.ZoneName(time.Now()))
+}
+
+// sendDailyReport delivers today's report to the admin chat, every office channel and every daily
+// subscriber
+func (b *Bot) sendDailyReport(ctx context.Context) {
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+	logger := b.logger.With("request_id", logging.RequestID(ctx), "digest", attendance.DigestDaily)
+
+	subscriptions, err := b.attendanceService.GetSubscribers(ctx, attendance.DigestDaily)
+	if err != nil {
+		// Still deliver to the admin chat
+		logger.Error("Failed to get subscribers", "error", err)
+	}
+
+	groups, err := b.attendanceService.GetOfficeGroups(ctx)
+	if err != nil {
+		logger.Error("Failed to get office groups", "error", err)
+	}
+
+	// Subscribers receive the report in their language, the admin chat and office channels in the
+	// default one
+	type recipient struct {
+		chatID int64
+		lang   string
+		group  bool
+	}
+	var recipients []recipient
+	if b.config.AdminChatID != 0 {
+		recipients = append(recipients, recipient{b.config.AdminChatID, i18n.Default, false})
+	}
+	for _, group := range groups {
+		if group.ChatID != b.config.AdminChatID {
+			recipients = append(recipients, recipient{group.ChatID, i18n.Default, true})
+		}
+	}
+	for _, subscription := range subscriptions {
+		if subscription.ChatID == b.config.AdminChatID {
+			continue
+		}
+		// Subscriptions outlive supervisor rights; stop delivering once those are revoked
+		if !b.config.IsSupervisor(subscription.UserID) && !b.isAdminChat(subscription.ChatID) {
+			logger.Info("Skipping subscriber without supervisor rights", "chat_id", subscription.ChatID, "user_id", subscription.UserID)
+			continue
+		}
+		recipients = append(recipients, recipient{subscription.ChatID, b.languageOf(ctx, subscription.UserID), false})
+	}
+
+	reports := make(map[string]string)
+	sent := 0
+	for i, to := range recipients {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				logger.Warn("Daily report broadcast interrupted", "sent", sent, "remaining", len(recipients)-i)
+				return
+			case <-time.After(broadcastInterval):
+			}
+		}
+
+		chatID := to.chatID
+		report, ok := reports[to.lang]
+		if !ok {
+			var err error
+			if report, err = b.attendanceService.GenerateAttendanceReport(ctx, to.lang); err != nil {
+				logger.Error("Failed to generate daily report", "language", to.lang, "error", err)
+				continue
+			}
+			reports[to.lang] = report
+		}
+
+		err := b.sendBroadcastMessage(ctx, chatID, report)
+		if isBlocked(err) && to.group {
+			logger.Info("Bot removed from office channel, unregistering it", "chat_id", chatID)
+			if _, err := b.attendanceService.UnregisterGroup(ctx, chatID, 0); err != nil {
+				logger.Error("Failed to unregister office channel", "chat_id", chatID, "error", err)
+			}
+			continue
+		}
+		if isBlocked(err) && chatID != b.config.AdminChatID {
+			logger.Info("Subscriber blocked the bot, removing subscription", "chat_id", chatID)
+			if _, err := b.attendanceService.Unsubscribe(ctx, chatID, attendance.DigestDaily); err != nil {
+				logger.Error("Failed to remove subscription", "chat_id", chatID, "error", err)
+			}
+			continue
+		}
+		if err != nil {
+			logger.Error("Failed to send daily report", "chat_id", chatID, "error", err)
+			continue
+		}
+		sent++
+	}
+
+	logger.Info("Daily report sent", "recipients", len(recipients), "sent", sent)
+
+	if b.config.Mirrors(notify.EventDailyReport) {
+		report, ok := reports[i18n.Default]
+		if !ok {
+			if report, err = b.attendanceService.GenerateAttendanceReport(ctx, i18n.Default); err != nil {
+				logger.Error("Failed to generate daily report", "language", i18n.Default, "error", err)
+				return
+			}
+		}
+		b.mirror.send(ctx, notify.EventDailyReport, notify.Message{Text: report, Markdown: true})
+	}
+}
+
+// sendBroadcastMessage sends a Markdown message, retrying once after the delay Telegram asks for
+// when rate limited for longer than the API client waits by itself
+func (b *Bot) sendBroadcastMessage(ctx context.Context, chatID int64, text string) error {
+	err := b.sendMarkdownMessage(ctx, chatID, text)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter <= 0 {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return err
+	case <-time.After(apiErr.RetryAfter):
+	}
+	return b.sendMarkdownMessage(ctx, chatID, text)
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"context"
+	"sync"
+)
+
+// Worker pool sizing. Each worker owns a bounded queue; when a user's queue is full the
+// polling loop blocks, which slows polling instead of buffering without limit.
+const (
+	defaultWorkers  = 8
+	workerQueueSize = 32
+)
+
+// dispatcher runs work on a fixed pool of workers keyed by user ID, so work for different
+// users runs in parallel while each user's work is done in order
+type dispatcher struct {
+	queues []chan func(context.Context)
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// newDispatcher starts the given number of workers, each with a bounded queue. Work is passed
+// a context derived from ctx, which abort cancels.
+func newDispatcher(ctx context.Context, workers, queueSize int) *dispatcher {
+	d := &dispatcher{
+		queues: make([]chan func(context.Context), workers),
+	}
+	d.ctx, d.cancel = context.WithCancel(ctx)
+
+	for i := range d.queues {
+		d.queues[i] = make(chan func(context.Context), queueSize)
+		d.wg.Add(1)
+		go d.run(d.queues[i])
+	}
+
+	return d
+}
+
+// run executes the work of one queue sequentially
+func (d *dispatcher) run(queue <-chan func(context.Context)) {
+	defer d.wg.Done()
+	for work := range queue {
+		work(d.ctx)
+	}
+}
+
+// dispatch queues work on the key's worker, blocking while that queue is full
+func (d *dispatcher) dispatch(key int64, work func(context.Context)) {
+	d.queues[d.workerFor(key)] <- work
+}
+
+// workerFor maps a key to a fixed worker index
+func (d *dispatcher) workerFor(key int64) int {
+	if key < 0 {
+		key = -key
+	}
+	return int(key % int64(len(d.queues)))
+}
+
+// close stops accepting work; queued work still runs
+func (d *dispatcher) close() {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+}
+
+// abort cancels the context of running and queued work
+func (d *dispatcher) abort() {
+	d.cancel()
+}
+
+// updateKey returns the ID that orders an update: the sender, falling back to the chat
+func updateKey(update *Update) int64 {
+	if query := update.CallbackQuery; query != nil && query.From != nil {
+		return query.From.ID
+	}
+	if update.Message == nil {
+		return 0
+	}
+	if update.Message.From != nil {
+		return update.Message.From.ID
+	}
+	if update.Message.Chat != nil {
+		return update.Message.Chat.ID
+	}
+	return 0
+}
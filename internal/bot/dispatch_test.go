@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDispatcherKeepsPerKeyOrder(t *testing.T) {
+	d := newDispatcher(context.Background(), 4, 2)
+
+	const perKey = 50
+	var mu sync.Mutex
+	seen := make(map[int64][]int)
+	for i := range perKey {
+		for key := int64(1); key <= 6; key++ {
+			d.dispatch(key, func(context.Context) {
+				if i%7 == 0 {
+					time.Sleep(time.Millisecond)
+				}
+				mu.Lock()
+				seen[key] = append(seen[key], i)
+				mu.Unlock()
+			})
+		}
+	}
+	d.close()
+	d.wg.Wait()
+
+	for key := int64(1); key <= 6; key++ {
+		got := seen[key]
+		if len(got) != perKey {
+			t.Fatalf("key %d ran %d times, want %d", key, len(got), perKey)
+		}
+		for i, n := range got {
+			if n != i {
+				t.Fatalf("key %d ran work %d at position %d, want in order: %v", key, n, i, got)
+			}
+		}
+	}
+}
+
+func TestDispatcherRunsKeysInParallel(t *testing.T) {
+	d := newDispatcher(context.Background(), 4, 2)
+	defer func() {
+		d.close()
+		d.wg.Wait()
+	}()
+
+	blocked := make(chan struct{})
+	unblock := make(chan struct{})
+	d.dispatch(1, func(context.Context) {
+		close(blocked)
+		<-unblock
+	})
+	<-blocked
+
+	// Key 5 shares key 1's worker and waits behind it; key 2 does not
+	sameWorker := make(chan struct{})
+	otherWorker := make(chan struct{})
+	d.dispatch(5, func(context.Context) { close(sameWorker) })
+	d.dispatch(2, func(context.Context) { close(otherWorker) })
+
+	select {
+	case <-otherWorker:
+	case <-time.After(5 * time.Second):
+		t.Fatal("work for another key waited for a blocked key")
+	}
+	select {
+	case <-sameWorker:
+		t.Fatal("work for a key ran before earlier work on its worker finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(unblock)
+	select {
+	case <-sameWorker:
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued work did not run after the blocking work finished")
+	}
+}
+
+func TestDispatcherAbortCancelsWork(t *testing.T) {
+	d := newDispatcher(context.Background(), 1, 1)
+
+	started := make(chan struct{})
+	var err error
+	d.dispatch(1, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		err = ctx.Err()
+	})
+	<-started
+	d.close()
+	d.abort()
+	d.wg.Wait()
+
+	if err != context.Canceled {
+		t.Errorf("work context error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestWorkerFor(t *testing.T) {
+	d := newDispatcher(context.Background(), 8, 1)
+	defer d.close()
+
+	for _, key := range []int64{0, 1, 7, 8, -1, -100123, math.MaxInt64, math.MinInt64 + 1} {
+		worker := d.workerFor(key)
+		if worker < 0 || worker >= 8 {
+			t.Errorf("workerFor(%d) = %d, want within [0, 8)", key, worker)
+		}
+		if again := d.workerFor(key); again != worker {
+			t.Errorf("workerFor(%d) = %d then %d, want a fixed worker", key, worker, again)
+		}
+	}
+}
+
+func TestUpdateKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		update Update
+		want   int64
+	}{
+		{"message sender", Update{Message: &Message{From: &User{ID: 7}, Chat: &Chat{ID: -100}}}, 7},
+		{"channel post without sender", Update{Message: &Message{Chat: &Chat{ID: -100}}}, -100},
+		{"callback sender", Update{CallbackQuery: &CallbackQuery{From: &User{ID: 9}, Message: &Message{Chat: &Chat{ID: -100}}}}, 9},
+		{"empty update", Update{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := updateKey(&tt.update); got != tt.want {
+				t.Errorf("updateKey() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDurationReplies sends /duration before check-in, during the day and after check-out; the
+// elapsed and remaining times themselves are covered by the service's fake-clock tests
+func TestDurationReplies(t *testing.T) {
+	tb := newTestBot(t)
+	tb.service.SetExpectedWorkHours(8 * time.Hour)
+	ctx := context.Background()
+	const userID = 1201
+
+	record := func(recordType string, timestamp time.Time) models.AttendanceRecord {
+		return models.AttendanceRecord{
+			UserID: userID, FirstName: "Sari", Timestamp: timestamp, Type: recordType,
+			Date: utils.Now().Format("2006-01-02"),
+		}
+	}
+
+	tb.send(t, userID, "/duration")
+	if got := tb.telegram.lastMessageTo(t, userID); !strings.HasPrefix(got, "❌ Anda belum absen masuk hari ini.") {
+		t.Errorf("before check-in = %q, want the not checked in reply", got)
+	}
+
+	checkIn := utils.Now().Add(-time.Hour)
+	if _, _, err := tb.repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{record("check_in", checkIn)}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+	tb.send(t, userID, "/duration")
+	got := tb.telegram.lastMessageTo(t, userID)
+	if !strings.HasPrefix(got, "⏱️ Sudah bekerja 1 jam") || !strings.Contains(got, "(masuk pukul "+checkIn.Format("15:04")+"), target tersisa 6 jam 5") {
+		t.Errorf("during the day = %q, want about an hour elapsed and 7 hours left", got)
+	}
+
+	checkOut := checkIn.Add(30 * time.Minute)
+	if _, _, err := tb.repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{record("check_out", checkOut)}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+	tb.send(t, userID, "/duration")
+	want := "✅ Anda sudah absen pulang pukul " + checkOut.Format("15:04") + ".\n⌛ Durasi kerja hari ini: 30 menit"
+	if got := tb.telegram.lastMessageTo(t, userID); got != want {
+		t.Errorf("after check-out = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"strings"
+)
+
+// handleRegisterUser handles the /registeruser command, with which admins add a user to the
+// employee directory with their employee ID and department, or change those
+func (b *Bot) handleRegisterUser(ctx context.Context, msg *Message, args []string) error {
+	if len(args) < 2 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "employee.register_usage"))
+	}
+
+	userID, err := utils.ParseInteger(args[0])
+	if err != nil || !utils.IsValidTelegramUserID(userID) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.invalid_user_id"))
+	}
+	employee := models.RegisteredEmployee{
+		UserID:     userID,
+		EmployeeID: args[1],
+		Department: strings.Join(args[2:], " "),
+	}
+
+	added, err := b.attendanceService.RegisterEmployee(ctx, &employee, msg.From.ID)
+	switch {
+	case errors.Is(err, attendance.ErrInvalidEmployeeID):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "employee.invalid_id"))
+	case errors.Is(err, attendance.ErrInvalidDepartment):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "employee.invalid_department"))
+	case errors.Is(err, attendance.ErrEmployeeIDTaken):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "employee.id_taken", "EmployeeID", employee.EmployeeID))
+	case err != nil:
+		return b.replyError(ctx, msg.Chat.ID, err, "action.register_employee", "Failed to register employee",
+			"target_user_id", userID)
+	}
+
+	logging.FromContext(ctx).Warn("Employee registered",
+		"audit", models.AuditEmployeeRegistered,
+		"target_user_id", userID,
+		"employee_id", employee.EmployeeID,
+		"department", employee.Department,
+		"new", added)
+
+	if !added {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "employee.updated", "UserID", userID, "Employee", describeEmployee(ctx, employee)))
+	}
+
+	// Users who never started a private chat with the bot cannot be notified
+	notice := i18n.T(b.languageOf(ctx, userID), "employee.registered_notice", "EmployeeID", employee.EmployeeID)
+	if err := b.sendMessage(ctx, userID, notice); err != nil {
+		logging.FromContext(ctx).Info("Failed to notify registered employee", "target_user_id", userID, "error", err)
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "employee.registered", "UserID", userID, "Employee", describeEmployee(ctx, employee)))
+}
+
+// handleUnregisterUser handles the /unregisteruser command, which removes a user from the
+// employee directory
+func (b *Bot) handleUnregisterUser(ctx context.Context, msg *Message, args []string) error {
+	if len(args) != 1 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "employee.unregister_usage"))
+	}
+
+	userID, err := utils.ParseInteger(args[0])
+	if err != nil || !utils.IsValidTelegramUserID(userID) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.invalid_user_id"))
+	}
+
+	removed, err := b.attendanceService.UnregisterEmployee(ctx, userID, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.unregister_employee", "Failed to unregister employee",
+			"target_user_id", userID)
+	}
+	if !removed {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "employee.not_found", "UserID", userID))
+	}
+
+	logging.FromContext(ctx).Warn("Employee unregistered",
+		"audit", models.AuditEmployeeUnregistered,
+		"target_user_id", userID)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "employee.unregistered", "UserID", userID))
+}
+
+// handleEmployees handles the /employees command, which lists the employee directory by department
+func (b *Bot) handleEmployees(ctx context.Context, msg *Message) error {
+	employees, err := b.attendanceService.GetRegisteredEmployees(ctx)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_employees", "Failed to get employees")
+	}
+	if len(employees) == 0 {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "employee.list_empty"))
+	}
+
+	var message strings.Builder
+	message.WriteString(tr(ctx, "employee.list_title", "Count", len(employees)))
+	department := "\x00"
+	for _, employee := range employees {
+		if employee.Department != department {
+			department = employee.Department
+			name := department
+			if name == "" {
+				name = tr(ctx, "employee.no_department")
+			}
+			message.WriteString("\n\n🏢 " + name)
+		}
+		message.WriteString("\n" + tr(ctx, "employee.list_entry", "EmployeeID", employee.EmployeeID, "UserID", employee.UserID))
+	}
+	if !b.attendanceService.RequiresRegistration() {
+		message.WriteString("\n\n" + tr(ctx, "employee.not_required"))
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, message.String())
+}
+
+// resolveDepartment returns the department of the employee directory named name, replying with
+// the known departments and returning false when there is none such
+func (b *Bot) resolveDepartment(ctx context.Context, chatID int64, name string) (string, bool, error) {
+	department, err := b.attendanceService.ResolveDepartment(ctx, name)
+	if err == nil {
+		return department, true, nil
+	}
+	if !errors.Is(err, attendance.ErrUnknownDepartment) {
+		return "", false, b.replyError(ctx, chatID, err, "action.get_departments", "Failed to get departments")
+	}
+
+	departments, err := b.attendanceService.GetDepartments(ctx)
+	if err != nil {
+		return "", false, b.replyError(ctx, chatID, err, "action.get_departments", "Failed to get departments")
+	}
+	if len(departments) == 0 {
+		return "", false, b.sendMessage(ctx, chatID, tr(ctx, "employee.no_departments"))
+	}
+	return "", false, b.sendMessage(ctx, chatID, tr(ctx, "employee.unknown_department", "Department", name, "Departments", strings.Join(departments, ", ")))
+}
+
+// describeEmployee renders an employee ID with the department, if any
+func describeEmployee(ctx context.Context, employee models.RegisteredEmployee) string {
+	if employee.Department == "" {
+		return employee.EmployeeID
+	}
+	return tr(ctx, "employee.with_department", "EmployeeID", employee.EmployeeID, "Department", employee.Department)
+}
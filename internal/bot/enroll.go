@@ -0,0 +1,47 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+)
+
+// handleEnroll handles the /enroll command, which sends a user the QR code of the shared secret
+// in their private chat with the bot, to scan with their authenticator app. The QR code is the
+// secret itself, so it is never posted in the admin chat.
+func (b *Bot) handleEnroll(ctx context.Context, msg *Message, args []string) error {
+	if len(args) != 1 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "enroll.usage"))
+	}
+	userID, err := utils.ParseInteger(args[0])
+	if err != nil || !utils.IsValidTelegramUserID(userID) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.invalid_user_id"))
+	}
+
+	png, err := b.attendanceService.EnrollmentQRCode(ctx)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.create_qr_code", "Failed to generate QR code", "target_user_id", userID)
+	}
+
+	// A private chat has the ID of its user, and exists once the user started the bot
+	caption := i18n.T(b.languageOf(ctx, userID), "enroll.caption", "Digits", b.attendanceService.OTPDigits())
+	if err := b.api.SendPhoto(ctx, userID, bytes.NewReader(png), "attendance_qr.png", caption); err != nil {
+		logging.FromContext(ctx).Warn("Failed to send enrollment QR code", "target_user_id", userID, "error", err)
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "enroll.send_failed", "UserID", userID))
+	}
+
+	logging.FromContext(ctx).Warn("Enrollment QR code sent",
+		"audit", models.AuditTOTPEnrolled,
+		"target_user_id", userID)
+	b.attendanceService.Audit(ctx, models.AuditEntry{
+		ActorID:      msg.From.ID,
+		Action:       models.AuditTOTPEnrolled,
+		TargetUserID: userID,
+		Details:      "QR code sent in private chat",
+	})
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "enroll.sent", "UserID", userID))
+}
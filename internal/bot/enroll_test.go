@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// TestEnrollCommand sends the QR code of the shared secret to a user's private chat: the admin is
+// told whether it arrived, and only a QR code that arrived is recorded in the audit log
+func TestEnrollCommand(t *testing.T) {
+	const userID = 2002
+
+	tests := []struct {
+		name     string
+		args     string
+		failWith int // Status Telegram answers sendPhoto with, 0 if it succeeds
+		reply    string
+		enrolled bool
+	}{
+		{name: "sent", args: fmt.Sprint(userID), reply: i18n.T(i18n.Default, "enroll.sent", "UserID", userID), enrolled: true},
+		{name: "user has not started the bot", args: fmt.Sprint(userID), failWith: http.StatusForbidden,
+			reply: i18n.T(i18n.Default, "enroll.send_failed", "UserID", userID)},
+		{name: "no user", reply: i18n.T(i18n.Default, "common.invalid_format") + "\n" + i18n.T(i18n.Default, "enroll.usage")},
+		{name: "malformed user", args: "budi", reply: i18n.T(i18n.Default, "common.invalid_user_id")},
+		{name: "several users", args: "2002 2003", reply: i18n.T(i18n.Default, "common.invalid_format") + "\n" + i18n.T(i18n.Default, "enroll.usage")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newTestBot(t)
+			if tt.failWith != 0 {
+				tb.telegram.fail("sendPhoto", tt.failWith)
+			}
+
+			command := "/enroll"
+			if tt.args != "" {
+				command += " " + tt.args
+			}
+			tb.send(t, testAdminID, command)
+
+			if reply := tb.telegram.lastMessageTo(t, testAdminID); reply != tt.reply {
+				t.Errorf("%s replied %q, want %q", command, reply, tt.reply)
+			}
+
+			photos := tb.telegram.sent("sendPhoto")
+			if enrolled := len(photos) > 0; enrolled != tt.enrolled {
+				t.Fatalf("QR code sent = %v, want %v", enrolled, tt.enrolled)
+			}
+			if tt.enrolled {
+				photo := photos[0]
+				if photo.Fields["chat_id"] != fmt.Sprint(userID) {
+					t.Errorf("QR code sent to chat %s, want %d", photo.Fields["chat_id"], userID)
+				}
+				if !bytes.HasPrefix(photo.File, []byte("\x89PNG")) {
+					t.Errorf("QR code is not a PNG image")
+				}
+				if caption := i18n.T(i18n.Default, "enroll.caption", "Digits", 6); photo.Fields["caption"] != caption {
+					t.Errorf("caption = %q, want %q", photo.Fields["caption"], caption)
+				}
+			}
+
+			entries, err := tb.service.GetAuditLog(context.Background(), userID)
+			if err != nil {
+				t.Fatalf("GetAuditLog: %v", err)
+			}
+			recorded := len(entries) == 1 && entries[0].Action == models.AuditTOTPEnrolled && entries[0].ActorID == testAdminID
+			if recorded != tt.enrolled || len(entries) > 1 {
+				t.Errorf("audit log = %+v, want the enrollment recorded: %v", entries, tt.enrolled)
+			}
+		})
+	}
+}
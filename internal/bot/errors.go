@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// errorReply describes how a failed operation is logged and reported to the user
+type errorReply struct {
+	level   slog.Level
+	message string
+}
+
+// translateError maps an error from the service layer to a log level and a user-facing message in
+// lang. action is the catalog key describing what was being done, e.g. "action.create_report", and
+// is used in generic replies.
+func translateError(err error, lang, action string) errorReply {
+	var storageErr *database.StorageError
+
+	switch {
+	case errors.Is(err, attendance.ErrInvalidDateRange):
+		return errorReply{slog.LevelInfo, i18n.T(lang, "error.invalid_date_range")}
+	case errors.Is(err, database.ErrNotFound):
+		return errorReply{slog.LevelInfo, i18n.T(lang, "error.not_found")}
+	case errors.Is(err, attendance.ErrInvalidSecret):
+		return errorReply{slog.LevelError, i18n.T(lang, "error.invalid_secret")}
+	case errors.Is(err, attendance.ErrEncryptionKeyMissing):
+		return errorReply{slog.LevelError, i18n.T(lang, "error.encryption_key_missing")}
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorReply{slog.LevelError, i18n.T(lang, "error.timeout", "Action", i18n.T(lang, action))}
+	case errors.As(err, &storageErr):
+		return errorReply{slog.LevelError, i18n.T(lang, "error.storage", "Action", i18n.T(lang, action))}
+	default:
+		return errorReply{slog.LevelError, i18n.T(lang, "error.generic", "Action", i18n.T(lang, action))}
+	}
+}
+
+// replyError logs err at the level chosen by translateError and sends the matching message to the
+// chat, in the language of the update. Unexpected failures carry the request ID so a user's report
+// can be matched to the logs.
+func (b *Bot) replyError(ctx context.Context, chatID int64, err error, action, logMsg string, attrs ...any) error {
+	lang := i18n.FromContext(ctx)
+	reply := translateError(err, lang, action)
+	logging.FromContext(ctx).Log(ctx, reply.level, logMsg, append([]any{"error", err}, attrs...)...)
+
+	message := reply.message
+	if requestID := logging.RequestID(ctx); requestID != "" && reply.level >= slog.LevelError {
+		message += i18n.T(lang, "error.reference", "RequestID", requestID)
+	}
+	return b.sendMessage(ctx, chatID, message)
+}
@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestTranslateError(t *testing.T) {
+	storage := &database.StorageError{Op: "get attendance", Err: errors.New("database is locked")}
+
+	tests := []struct {
+		name      string
+		err       error
+		wantLevel slog.Level
+		wantReply string
+	}{
+		{
+			name:      "invalid date range",
+			err:       fmt.Errorf("report: %w", attendance.ErrInvalidDateRange),
+			wantLevel: slog.LevelInfo,
+			wantReply: "❌ Rentang tanggal tidak valid. Gunakan format YYYY-MM-DD dan pastikan tanggal mulai tidak melebihi tanggal akhir.",
+		},
+		{
+			name:      "not found",
+			err:       fmt.Errorf("get user: %w", database.ErrNotFound),
+			wantLevel: slog.LevelInfo,
+			wantReply: "❌ Data tidak ditemukan.",
+		},
+		{
+			name:      "invalid secret",
+			err:       fmt.Errorf("failed to verify totp: %w", attendance.ErrInvalidSecret),
+			wantLevel: slog.LevelError,
+			wantReply: "⚠️ Bot salah konfigurasi (secret OTP tidak valid). Silakan hubungi admin.",
+		},
+		{
+			name:      "encryption key missing",
+			err:       attendance.ErrEncryptionKeyMissing,
+			wantLevel: slog.LevelError,
+			wantReply: "⚠️ Bot salah konfigurasi (kunci enkripsi belum diatur). Silakan hubungi admin.",
+		},
+		{
+			name:      "timeout",
+			err:       fmt.Errorf("query: %w", context.DeadlineExceeded),
+			wantLevel: slog.LevelError,
+			wantReply: "⏱️ Waktu habis saat mengambil data absensi. Silakan coba lagi beberapa saat lagi.",
+		},
+		{
+			name:      "storage",
+			err:       fmt.Errorf("service: %w", storage),
+			wantLevel: slog.LevelError,
+			wantReply: "❌ Database sedang bermasalah saat mengambil data absensi. Silakan coba lagi beberapa saat lagi.",
+		},
+		{
+			name:      "storage duplicate",
+			err:       &database.StorageError{Op: "insert attendance", Err: database.ErrDuplicate},
+			wantLevel: slog.LevelError,
+			wantReply: "❌ Database sedang bermasalah saat mengambil data absensi. Silakan coba lagi beberapa saat lagi.",
+		},
+		{
+			name:      "other",
+			err:       errors.New("boom"),
+			wantLevel: slog.LevelError,
+			wantReply: "❌ Terjadi kesalahan saat mengambil data absensi. Silakan coba lagi.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := translateError(tt.err, i18n.Default, "action.get_attendance")
+			if reply.level != tt.wantLevel {
+				t.Errorf("level = %v, want %v", reply.level, tt.wantLevel)
+			}
+			if reply.message != tt.wantReply {
+				t.Errorf("message = %q, want %q", reply.message, tt.wantReply)
+			}
+		})
+	}
+}
+
+func TestTranslateErrorInEnglish(t *testing.T) {
+	reply := translateError(errors.New("boom"), "en", "action.get_attendance")
+	if want := "❌ Something went wrong while getting attendance data. Please try again."; reply.message != want {
+		t.Errorf("message = %q, want %q", reply.message, want)
+	}
+}
+
+// TestReplyError checks that only unexpected failures carry the request ID for matching the logs
+func TestReplyError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantReference bool
+	}{
+		{"user error", attendance.ErrInvalidDateRange, false},
+		{"storage error", &database.StorageError{Op: "get attendance", Err: errors.New("disk I/O error")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newTestBot(t)
+			ctx := logging.WithRequestID(context.Background(), "req-1234")
+
+			if err := tb.replyError(ctx, 801, tt.err, "action.get_attendance", "Failed"); err != nil {
+				t.Fatalf("replyError: %v", err)
+			}
+			reply := tb.telegram.lastMessageTo(t, 801)
+			if !strings.HasPrefix(reply, translateError(tt.err, i18n.Default, "action.get_attendance").message) {
+				t.Errorf("reply = %q, want the translated error", reply)
+			}
+			if got := strings.Contains(reply, "Kode referensi: req-1234"); got != tt.wantReference {
+				t.Errorf("reply has reference = %v, want %v: %q", got, tt.wantReference, reply)
+			}
+		})
+	}
+}
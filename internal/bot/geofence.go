@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/logging"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// handleLocation keeps a location the user shared for their next attendance and tells them
+// whether it lies inside a geofence. Forwarded locations may be someone else's, so they are
+// refused like forwarded codes.
+func (b *Bot) handleLocation(ctx context.Context, msg *Message) error {
+	if msg.IsForwarded() {
+		logging.FromContext(ctx).Warn("Rejected forwarded location")
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "geofence.forwarded"))
+	}
+
+	location := msg.Location
+	check, err := b.attendanceService.ShareLocation(ctx, msg.From.ID, location.Latitude, location.Longitude, time.Unix(msg.Date, 0))
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.share_location", "Failed to share location")
+	}
+
+	logging.FromContext(ctx).Info("Location shared", "geofence", check.Inside, "nearest", check.Nearest)
+	minutes := int(attendance.LocationFreshness.Minutes())
+	switch {
+	case check.Inside != "":
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "geofence.shared_inside", "Geofence", check.Inside, "Minutes", minutes))
+	case check.Nearest != "":
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "geofence.shared_outside",
+			"Geofence", check.Nearest,
+			"Distance", int(check.Distance),
+			"Minutes", minutes))
+	default:
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "geofence.shared", "Minutes", minutes))
+	}
+}
+
+// handleGeofence handles the /geofence command, with which admins manage the places check-ins are
+// expected from
+func (b *Bot) handleGeofence(ctx context.Context, msg *Message, args []string) error {
+	if len(args) == 0 || args[0] == "list" {
+		return b.handleGeofenceList(ctx, msg)
+	}
+
+	switch {
+	case args[0] == "set" && len(args) == 5:
+		latitude, latErr := strconv.ParseFloat(args[2], 64)
+		longitude, lonErr := strconv.ParseFloat(args[3], 64)
+		radius, radiusErr := strconv.Atoi(args[4])
+		if latErr != nil || lonErr != nil || radiusErr != nil {
+			return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "geofence.invalid", "MaxRadius", attendance.MaxGeofenceRadius))
+		}
+		return b.handleGeofenceSet(ctx, msg, &models.Geofence{
+			Name:      strings.ToLower(args[1]),
+			Latitude:  latitude,
+			Longitude: longitude,
+			Radius:    radius,
+		})
+	case args[0] == "delete" && len(args) == 2:
+		return b.handleGeofenceDelete(ctx, msg, strings.ToLower(args[1]))
+	default:
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "geofence.usage"))
+	}
+}
+
+// handleGeofenceList lists the geofences and the mode check-ins are judged in
+func (b *Bot) handleGeofenceList(ctx context.Context, msg *Message) error {
+	geofences, err := b.attendanceService.GetGeofences(ctx)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_geofences", "Failed to get geofences")
+	}
+
+	var message strings.Builder
+	message.WriteString(tr(ctx, "geofence.list_title", "Mode", b.attendanceService.GeofenceMode()) + "\n\n")
+	if len(geofences) == 0 {
+		message.WriteString(tr(ctx, "geofence.list_empty") + "\n")
+	}
+	for _, geofence := range geofences {
+		message.WriteString(describeGeofence(ctx, geofence) + "\n")
+	}
+	message.WriteString("\n" + tr(ctx, "geofence.usage"))
+
+	return b.sendMessage(ctx, msg.Chat.ID, message.String())
+}
+
+// handleGeofenceSet creates or updates a geofence
+func (b *Bot) handleGeofenceSet(ctx context.Context, msg *Message, geofence *models.Geofence) error {
+	err := b.attendanceService.SaveGeofence(ctx, geofence)
+	if errors.Is(err, attendance.ErrInvalidGeofence) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "geofence.invalid", "MaxRadius", attendance.MaxGeofenceRadius))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_geofence", "Failed to save geofence", "geofence", geofence.Name)
+	}
+
+	logging.FromContext(ctx).Warn("Geofence saved",
+		"audit", "geofence_saved",
+		"geofence", geofence.Name,
+		"latitude", geofence.Latitude,
+		"longitude", geofence.Longitude,
+		"radius_m", geofence.Radius)
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "geofence.saved", "Geofence", describeGeofence(ctx, *geofence)))
+}
+
+// handleGeofenceDelete deletes a geofence
+func (b *Bot) handleGeofenceDelete(ctx context.Context, msg *Message, name string) error {
+	deleted, err := b.attendanceService.DeleteGeofence(ctx, name)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.delete_geofence", "Failed to delete geofence", "geofence", name)
+	}
+	if !deleted {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "geofence.not_found", "Name", name))
+	}
+
+	logging.FromContext(ctx).Warn("Geofence deleted", "audit", "geofence_deleted", "geofence", name)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "geofence.deleted", "Name", name))
+}
+
+// describeGeofence renders a geofence as its name, center and radius
+func describeGeofence(ctx context.Context, geofence models.Geofence) string {
+	return tr(ctx, "geofence.entry",
+		"Name", geofence.Name,
+		"Latitude", strconv.FormatFloat(geofence.Latitude, 'f', 6, 64),
+		"Longitude", strconv.FormatFloat(geofence.Longitude, 'f', 6, 64),
+		"Radius", geofence.Radius)
+}
@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// isGroupChat reports whether the chat is a group or supergroup
+func isGroupChat(chat *Chat) bool {
+	return chat.Type == "group" || chat.Type == "supergroup"
+}
+
+// handleRegister handles the /register command, which makes the group an office channel: the
+// daily report is posted there and members may send their OTPs, deleted once processed
+func (b *Bot) handleRegister(ctx context.Context, msg *Message) error {
+	if !isGroupChat(msg.Chat) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "group.register_in_group"))
+	}
+
+	added, err := b.attendanceService.RegisterGroup(ctx, msg.Chat.ID, msg.Chat.Title, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.register_group", "Failed to register group")
+	}
+	if !added {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "group.already_registered"))
+	}
+
+	logging.FromContext(ctx).Warn("Group registered as office channel",
+		"audit", models.AuditGroupRegistered,
+		"title", msg.Chat.Title)
+
+	note := tr(ctx, "group.report_disabled")
+	if clock, ok := b.config.DailyReportClock(); ok {
+		note = tr(ctx, "group.report_daily", "Clock", clock, "Zone", utils.ZoneName(time.Now()))
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "group.registered", "Note", note, "Digits", b.attendanceService.OTPDigits()))
+}
+
+// handleUnregister handles the /unregister command, which stops the group being an office channel
+func (b *Bot) handleUnregister(ctx context.Context, msg *Message) error {
+	if !isGroupChat(msg.Chat) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "group.register_in_group"))
+	}
+
+	removed, err := b.attendanceService.UnregisterGroup(ctx, msg.Chat.ID, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.unregister_group", "Failed to unregister group")
+	}
+	if !removed {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "group.not_registered"))
+	}
+
+	logging.FromContext(ctx).Warn("Group no longer an office channel",
+		"audit", models.AuditGroupUnregistered,
+		"title", msg.Chat.Title)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "group.unregistered"))
+}
+
+// handleGroupMessage handles a message other than a command in a group. In an office channel an
+// OTP marks the sender's attendance and is then deleted, so nobody else in the group can use the
+// code; everything else is the group's own conversation and ignored.
+func (b *Bot) handleGroupMessage(ctx context.Context, msg *Message) error {
+	if !utils.ValidateOTP(msg.Text, b.attendanceService.OTPDigits()) {
+		return nil
+	}
+
+	registered, err := b.attendanceService.IsOfficeGroup(ctx, msg.Chat.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check office group: %w", err)
+	}
+	if !registered {
+		return nil
+	}
+
+	err = b.handleOTP(ctx, msg)
+	if deleteErr := b.api.DeleteMessage(ctx, msg.Chat.ID, msg.MessageID); deleteErr != nil {
+		logging.FromContext(ctx).Warn("Failed to delete OTP message, the bot needs the right to delete messages",
+			"error", deleteErr)
+	}
+	return err
+}
+
+// sendOTPReply answers an OTP message. In a group the reply names the sender, whose message is
+// deleted.
+func (b *Bot) sendOTPReply(ctx context.Context, msg *Message, text string, markdown bool) error {
+	if isGroupChat(msg.Chat) {
+		name := strings.TrimSpace(msg.From.FirstName + " " + msg.From.LastName)
+		if markdown {
+			name = escapeMarkdown(name)
+		}
+		text = fmt.Sprintf("👤 %s\n%s", name, text)
+	}
+
+	if markdown {
+		return b.sendMarkdownMessage(ctx, msg.Chat.ID, text)
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, text)
+}
@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestGroupChat sends /register, /unregister and an OTP to groups that are or are not office
+// channels: only admins change the registration, and only an office channel takes OTPs, deleting
+// them once handled
+func TestGroupChat(t *testing.T) {
+	const (
+		groupID    = -2001
+		employeeID = 2002
+	)
+	code, err := attendance.NewTOTPService(testSecret).Generate()
+	if err != nil {
+		t.Fatalf("failed to generate OTP: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		registered bool // Whether the group is an office channel beforehand
+		private    bool // Sent in the sender's private chat instead of the group
+		userID     int64
+		text       string
+		reply      string // Expected start of the last reply, empty if there is none
+		office     bool   // Whether the group is an office channel afterwards
+		deleted    bool   // Whether the message is deleted
+	}{
+		{name: "register", userID: testAdminID, text: "/register",
+			reply: i18n.T(i18n.Default, "group.registered", "Note", i18n.T(i18n.Default, "group.report_disabled"), "Digits", 6), office: true},
+		{name: "register again", registered: true, userID: testAdminID, text: "/register",
+			reply: i18n.T(i18n.Default, "group.already_registered"), office: true},
+		{name: "register in private", private: true, userID: testAdminID, text: "/register",
+			reply: i18n.T(i18n.Default, "group.register_in_group")},
+		{name: "register by employee", userID: employeeID, text: "/register",
+			reply: i18n.T(i18n.Default, "common.admin_only")},
+		{name: "unregister", registered: true, userID: testAdminID, text: "/unregister",
+			reply: i18n.T(i18n.Default, "group.unregistered")},
+		{name: "unregister unregistered", userID: testAdminID, text: "/unregister",
+			reply: i18n.T(i18n.Default, "group.not_registered")},
+		{name: "unregister by employee", registered: true, userID: employeeID, text: "/unregister",
+			reply: i18n.T(i18n.Default, "common.admin_only"), office: true},
+		{name: "private command in group", userID: employeeID, text: "/history",
+			reply: i18n.T(i18n.Default, "group.private_only", "Command", "/history")},
+		{name: "OTP in office channel", registered: true, userID: employeeID, text: code,
+			reply: "👤 User2002\n", office: true, deleted: true},
+		{name: "OTP in other group", userID: employeeID, text: code},
+		{name: "chatter in office channel", registered: true, userID: employeeID, text: "selamat pagi", office: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newTestBot(t)
+			ctx := context.Background()
+			if tt.registered {
+				if _, err := tb.service.RegisterGroup(ctx, groupID, "Kantor", testAdminID); err != nil {
+					t.Fatalf("RegisterGroup: %v", err)
+				}
+			}
+
+			chat := &Chat{ID: groupID, Type: "supergroup", Title: "Kantor"}
+			if tt.private {
+				chat = &Chat{ID: tt.userID, Type: "private"}
+			}
+			tb.deliver(t, &Message{From: &User{ID: tt.userID, FirstName: "User2002"}, Chat: chat, Text: tt.text})
+
+			replies := tb.telegram.messagesTo(chat.ID)
+			switch {
+			case tt.reply == "" && len(replies) > 0:
+				t.Errorf("%q got replies %q, want none", tt.text, replies)
+			case tt.reply == "":
+			case len(replies) == 0:
+				t.Errorf("%q got no reply, want %q", tt.text, tt.reply)
+			case !strings.HasPrefix(replies[len(replies)-1], tt.reply):
+				t.Errorf("%q replied %q, want it to start with %q", tt.text, replies[len(replies)-1], tt.reply)
+			}
+
+			office, err := tb.service.IsOfficeGroup(ctx, groupID)
+			if err != nil {
+				t.Fatalf("IsOfficeGroup: %v", err)
+			}
+			if office != tt.office {
+				t.Errorf("office channel = %v, want %v", office, tt.office)
+			}
+			if deleted := len(tb.telegram.sent("deleteMessage")) > 0; deleted != tt.deleted {
+				t.Errorf("message deleted = %v, want %v", deleted, tt.deleted)
+			}
+		})
+	}
+}
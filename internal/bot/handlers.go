@@ -3,240 +3,809 @@ package bot
 import (
 	"attendance-bot/internal/attendance"
 	"attendance-bot/internal/config"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/metrics"
+	"attendance-bot/internal/notify"
 	"attendance-bot/internal/reports"
 	"attendance-bot/internal/utils"
 	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// SessionData represents user session state
-type SessionData struct {
-	AwaitingDateRange bool
-}
+// pollTimeout is the long-polling timeout passed to getUpdates
+const pollTimeout = 60 * time.Second
+
+// shutdownTimeout bounds how long Start waits for in-flight handlers after cancellation
+const shutdownTimeout = 30 * time.Second
+
+// updateTimeout bounds the handling of one update, so a slow query cannot hold a worker forever
+const updateTimeout = 2 * time.Minute
 
 // Bot represents the main bot instance
 type Bot struct {
 	api               *TelegramAPI
 	attendanceService *attendance.Service
 	csvGenerator      *reports.CSVGenerator
+	xlsxGenerator     *reports.XLSXGenerator
+	pdfGenerator      *reports.PDFGenerator
 	config            *config.Config
 	logger            *slog.Logger
+	username          string // The bot's own username, set by Start
 	lastUpdateID      int64
-	sessions          map[int64]*SessionData // Simple in-memory session storage
+	lastPoll          atomic.Int64    // Unix nanoseconds of the last successful getUpdates or webhook check
+	inFlight          sync.WaitGroup  // Handlers and background tasks still running
+	panics            panicAlerts     // Rate limits admin alerts about recovered panics
+	recent            *recentUpdates  // Recently handled update IDs, to skip redeliveries
+	sessions          *sessionManager // Multi-step conversation state per user
+	mirror            *mirror         // Copies messages to Slack and Discord, nil when neither is configured
+	commands          *commandRouter  // Command handlers and their middleware
+	commandLimiter    *rateLimiter    // Commands per user, for the rate limiting middleware
 }
 
 // NewBot creates a new bot instance
-func NewBot(token string, attendanceService *attendance.Service, csvGenerator *reports.CSVGenerator, cfg *config.Config, logger *slog.Logger) *Bot {
-	return &Bot{
-		api:               NewTelegramAPI(token),
+func NewBot(token string, attendanceService *attendance.Service, csvGenerator *reports.CSVGenerator, xlsxGenerator *reports.XLSXGenerator, pdfGenerator *reports.PDFGenerator, cfg *config.Config, logger *slog.Logger) *Bot {
+	b := &Bot{
+		api:               NewTelegramAPIWithOptions(token, &TelegramAPIOptions{APIURL: cfg.TelegramAPIURL}),
 		attendanceService: attendanceService,
 		csvGenerator:      csvGenerator,
+		xlsxGenerator:     xlsxGenerator,
+		pdfGenerator:      pdfGenerator,
 		config:            cfg,
 		logger:            logger,
-		sessions:          make(map[int64]*SessionData),
+		sessions:          newSessionManager(attendanceService, sessionTTL),
+		recent:            newRecentUpdates(recentUpdatesSize),
+		commandLimiter:    newRateLimiter(commandRateLimit, commandRateWindow),
 	}
+	b.commands = newCommandRouter(logCommand, recoverCommand, restrictGroups, limitCommands, authorize, endConversation)
+	b.commands.register(commandRoutes...)
+	b.mirror = newMirror(b)
+	return b
 }
 
-// Start begins the bot polling loop
-func (b *Bot) Start() error {
+// Start receives updates, by long polling or by webhook, until ctx is cancelled. It then waits for in-flight handlers,
+// persists the update offset and returns, after which the database may be closed.
+func (b *Bot) Start(ctx context.Context) error {
 	b.logger.Info("Starting bot...")
 
 	// Get bot info
-	botInfo, err := b.api.GetMe()
+	botInfo, err := b.api.GetMe(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get bot info: %w", err)
 	}
 
 	b.logger.Info("Bot started successfully", "bot_username", botInfo.Username, "bot_id", botInfo.ID)
+	b.username = botInfo.Username
+
+	// Register the webhook before anything else starts, so a bad URL or a busy port fails fast.
+	// Polling needs any previously registered webhook removed, or getUpdates is refused.
+	var webhook *webhookServer
+	if b.config.UseWebhook() {
+		if webhook, err = b.listenWebhook(ctx); err != nil {
+			return err
+		}
+	} else if err := b.api.DeleteWebhook(ctx); err != nil {
+		b.logger.Warn("Failed to remove webhook", "error", err)
+	}
+
+	// Run scheduled reports; the scheduler returns once running jobs see the cancellation
+	if jobs := b.newScheduler(); jobs.Len() > 0 {
+		b.inFlight.Add(1)
+		go func() {
+			defer b.inFlight.Done()
+			jobs.Run(ctx)
+		}()
+	}
+
+	// Keep the pinned live report up to date as attendance is recorded
+	if open, closing, ok := b.config.LiveReportHours(); ok {
+		live := newLiveReport(b, b.config.LiveReportChatID, open, closing)
+		b.attendanceService.AddAttendanceHook(live.touch)
+		b.inFlight.Add(1)
+		go live.run(ctx)
+	}
+
+	// Alert the late alert chat of each late arrival as it comes; the digest is a scheduled job
+	if chatID, ok := b.config.LateAlertChat(); ok && b.config.LateAlerts == "instant" {
+		alerts := newLateAlerts(b, chatID)
+		b.attendanceService.AddAttendanceHook(alerts.recorded)
+		b.inFlight.Add(1)
+		go alerts.run(ctx)
+	}
+
+	// Mirror late arrivals to Slack and Discord
+	if b.mirror != nil && b.config.Mirrors(notify.EventLateArrival) {
+		b.attendanceService.AddAttendanceHook(b.mirror.recorded)
+		b.inFlight.Add(1)
+		go b.mirror.run(ctx)
+	}
+
+	// Resume after the last update handled before the previous shutdown
+	if b.lastUpdateID, err = b.attendanceService.GetLastUpdateID(ctx); err != nil {
+		b.logger.Error("Failed to load update offset", "error", err)
+	}
+
+	// Updates are handled by a worker pool keyed by user. Handlers outlive the cancellation of
+	// ctx so that updates already received are answered; only a shutdown timeout aborts them.
+	workers := newDispatcher(context.WithoutCancel(ctx), defaultWorkers, workerQueueSize)
+
+	if webhook != nil {
+		webhook.serve(ctx, workers)
+	} else {
+		b.poll(ctx, workers)
+	}
+
+	b.logger.Info("Update delivery stopped, waiting for in-flight handlers")
+	workers.close()
+	if !waitTimeout(&b.inFlight, shutdownTimeout) {
+		b.logger.Warn("Timed out waiting for in-flight handlers", "timeout", shutdownTimeout)
+	}
+	workers.abort()
+
+	if b.lastUpdateID > 0 {
+		if err := b.attendanceService.SaveLastUpdateID(context.WithoutCancel(ctx), b.lastUpdateID); err != nil {
+			return fmt.Errorf("failed to persist update offset: %w", err)
+		}
+	}
+
+	b.logger.Info("Bot stopped", "last_update_id", b.lastUpdateID)
+	return nil
+}
 
-	// Start polling loop
-	for {
-		updates, err := b.api.GetUpdates(b.lastUpdateID+1, 60)
+// poll fetches updates with long polling and dispatches them until ctx is cancelled
+func (b *Bot) poll(ctx context.Context, workers *dispatcher) {
+	for ctx.Err() == nil {
+		updates, err := b.api.GetUpdates(ctx, b.lastUpdateID+1, int(pollTimeout.Seconds()))
 		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
 			b.logger.Error("Failed to get updates", "error", err)
-			time.Sleep(5 * time.Second)
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
 			continue
 		}
-
-		for _, update := range updates {
+		b.lastPoll.Store(time.Now().UnixNano())
+		metrics.UpdatesReceived.Add(float64(len(updates)))
+
+		// The whole batch is dispatched even if ctx is cancelled meanwhile, so no update is dropped
+		now := time.Now()
+		dropped := 0
+		for i := range updates {
+			update := &updates[i]
 			b.lastUpdateID = update.UpdateID
-			if err := b.handleUpdate(&update); err != nil {
-				b.logger.Error("Failed to handle update", "error", err, "update_id", update.UpdateID)
+			if !b.enqueue(workers, update, now) {
+				dropped++
 			}
 		}
+		if dropped > 0 {
+			b.logger.Info("Ignored stale updates", "count", dropped, "cutoff_minutes", b.config.StaleUpdateCutoff)
+			metrics.UpdatesHandled.Add(float64(dropped), "stale")
+		}
 	}
 }
 
+// enqueue dispatches an update to the workers and reports whether it was accepted. Messages
+// queued while the bot was down must never act at the wrong moment, in particular old OTPs
+// must not create attendance records, so stale updates are dropped.
+func (b *Bot) enqueue(workers *dispatcher, update *Update, now time.Time) bool {
+	if b.isStale(update, now) {
+		if b.config.StaleCommandReply && strings.HasPrefix(update.Message.Text, "/") {
+			b.inFlight.Add(1)
+			workers.dispatch(updateKey(update), func(ctx context.Context) { b.replyStale(ctx, update) })
+		}
+		return false
+	}
+
+	b.inFlight.Add(1)
+	workers.dispatch(updateKey(update), func(ctx context.Context) { b.processUpdate(ctx, update) })
+	return true
+}
+
+// processUpdate handles one dispatched update within updateTimeout and records the outcome
+func (b *Bot) processUpdate(ctx context.Context, update *Update) {
+	defer b.inFlight.Done()
+
+	ctx, cancel := context.WithTimeout(b.updateContext(ctx, update), updateTimeout)
+	defer cancel()
+	logger := logging.FromContext(ctx)
+
+	err := b.safeHandleUpdate(ctx, update)
+	if errors.Is(err, errPanicRecovered) {
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		logger.Error("Update handling aborted", "error", err, "timeout", updateTimeout)
+		metrics.UpdatesHandled.Inc("error")
+		return
+	}
+	if isTransient(err) {
+		logger.Warn("Telegram unavailable, reply not delivered", "error", err)
+		metrics.UpdatesHandled.Inc("error")
+		return
+	}
+	if errors.Is(err, ErrTelegramAPI) {
+		logger.Error("Failed to reply via Telegram", "error", err)
+		metrics.UpdatesHandled.Inc("error")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to handle update", "error", err)
+		metrics.UpdatesHandled.Inc("error")
+		return
+	}
+	metrics.UpdatesHandled.Inc("ok")
+}
+
+// updateContext returns ctx carrying a fresh request ID and a logger tagged with it,
+// so every log line for one update can be correlated
+func (b *Bot) updateContext(ctx context.Context, update *Update) context.Context {
+	requestID := logging.NewRequestID()
+	logger := b.logger.With("request_id", requestID, "update_id", update.UpdateID)
+	if update.Message != nil && update.Message.From != nil {
+		logger = logger.With("user_id", update.Message.From.ID)
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.From != nil {
+		logger = logger.With("user_id", update.CallbackQuery.From.ID)
+	}
+
+	ctx = logging.WithRequestID(ctx, requestID)
+	return logging.NewContext(ctx, logger)
+}
+
+// isStale reports whether the update's message is older than the configured cutoff
+func (b *Bot) isStale(update *Update, now time.Time) bool {
+	if b.config.StaleUpdateCutoff <= 0 || update.Message == nil || update.Message.Date == 0 {
+		return false
+	}
+
+	cutoff := time.Duration(b.config.StaleUpdateCutoff) * time.Minute
+	return now.Sub(time.Unix(update.Message.Date, 0)) > cutoff
+}
+
+// replyStale tells the user a command sent while the bot was down was ignored
+func (b *Bot) replyStale(ctx context.Context, update *Update) {
+	defer b.inFlight.Done()
+
+	msg := update.Message
+	if msg.Chat == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+	ctx = b.withLanguage(ctx, update)
+	if err := b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.stale_command")); err != nil {
+		b.logger.Error("Failed to reply to stale command", "error", err, "update_id", update.UpdateID)
+	}
+}
+
+// waitTimeout waits for wg, returning false if the timeout elapsed first
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// LastPoll returns when getUpdates last succeeded, or in webhook mode when Telegram last
+// confirmed the webhook, or the zero time if neither has happened yet
+func (b *Bot) LastPoll() time.Time {
+	nanos := b.lastPoll.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// PollTimeout returns the long-polling timeout used for getUpdates
+func (b *Bot) PollTimeout() time.Duration {
+	return pollTimeout
+}
+
 // handleUpdate processes a single update
-func (b *Bot) handleUpdate(update *Update) error {
+func (b *Bot) handleUpdate(ctx context.Context, update *Update) error {
+	logger := logging.FromContext(ctx)
+
+	// Telegram may redeliver an update; side effects must happen only once
+	if b.recent.markSeen(update.UpdateID) {
+		logger.Debug("Skipping duplicate update")
+		metrics.UpdatesHandled.Inc("duplicate")
+		return nil
+	}
+
+	// Replies are written in the sender's language
+	ctx = b.withLanguage(ctx, update)
+
+	if update.CallbackQuery != nil {
+		return b.handleCallbackQuery(ctx, update.CallbackQuery)
+	}
+
 	if update.Message == nil {
 		return nil
 	}
 
 	msg := update.Message
-	b.logger.Debug("Received message",
-		"user_id", msg.From.ID,
+	logger.Debug("Received message",
 		"username", msg.From.Username,
 		"text", msg.Text)
 
+	// A file may carry a command in its caption, e.g. a CSV file sent with /import
+	if msg.Text == "" && msg.Document != nil && strings.HasPrefix(msg.Caption, "/") {
+		msg.Text = msg.Caption
+	}
+
 	// Handle commands
 	if strings.HasPrefix(msg.Text, "/") {
-		return b.handleCommand(msg)
+		return b.handleCommand(ctx, msg)
+	}
+
+	// On the kiosk, codes are entered on behalf of the employee picked from the roster
+	if b.isKioskChat(msg.Chat.ID) {
+		return b.handleKioskInput(ctx, msg)
+	}
+
+	// Groups only see OTPs handled, and only in office channels
+	if isGroupChat(msg.Chat) {
+		return b.handleGroupMessage(ctx, msg)
 	}
 
-	// Handle OTP (6-digit numbers)
-	if utils.ValidateOTP(msg.Text) {
-		return b.handleOTP(msg)
+	// A selfie sent after checking in is attached to the check-in as proof of presence
+	if len(msg.Photo) > 0 && msg.Chat.Type == "private" && b.attendanceService.PhotoWindow() > 0 {
+		return b.handlePhoto(ctx, msg)
+	}
+
+	// A location shared in private is applied to the user's next attendance
+	if msg.Location != nil && msg.Chat.Type == "private" {
+		return b.handleLocation(ctx, msg)
+	}
+
+	// Handle OTP (numeric codes of the configured length)
+	if utils.ValidateOTP(msg.Text, b.attendanceService.OTPDigits()) {
+		return b.handleOTP(ctx, msg)
 	}
 
 	// Handle other text messages
-	return b.handleTextMessage(msg)
+	return b.handleTextMessage(ctx, msg)
 }
 
-// handleCommand processes bot commands
-func (b *Bot) handleCommand(msg *Message) error {
+// handleCommand parses a command and routes it to its handler
+func (b *Bot) handleCommand(ctx context.Context, msg *Message) error {
 	parts := strings.Fields(msg.Text)
 	if len(parts) == 0 {
 		return nil
 	}
 
-	command := parts[0]
-	args := parts[1:]
-
-	switch command {
-	case "/start":
-		return b.handleStart(msg)
-	case "/help":
-		return b.handleHelp(msg)
-	case "/report":
-		return b.handleReport(msg)
-	case "/history":
-		return b.handleHistory(msg)
-	case "/status":
-		return b.handleStatus(msg)
-	case "/alias":
-		return b.handleAlias(msg, args)
-	case "/fullreport":
-		return b.handleFullReport(msg, args)
-	default:
-		return b.sendMessage(msg.Chat.ID, "❓ Perintah tidak dikenal. Ketik /help untuk melihat daftar perintah.")
+	// In groups a command may name the bot it is meant for, e.g. /report@attendance_bot
+	command, addressee, _ := strings.Cut(parts[0], "@")
+	if addressee != "" && b.username != "" && !strings.EqualFold(addressee, b.username) {
+		return nil
 	}
+	return b.commands.route(b, ctx, msg, command, parts[1:])
 }
 
 // handleStart handles the /start command
-func (b *Bot) handleStart(msg *Message) error {
-	welcomeMessage := `🎯 *Selamat datang di Attendance Bot!*
+func (b *Bot) handleStart(ctx context.Context, msg *Message) error {
+	welcomeMessage := tr(ctx, "start.welcome", "Digits", b.attendanceService.OTPDigits())
 
-Untuk absen, kirimkan kode OTP 6 digit Anda.
+	// New users learn right away that an admin has to register them first
+	if b.attendanceService.RequiresRegistration() && msg.Chat.Type == "private" {
+		employee, err := b.attendanceService.GetRegisteredEmployee(ctx, msg.From.ID)
+		if err != nil {
+			logging.FromContext(ctx).Error("Failed to get registered employee", "error", err)
+		} else if employee == nil {
+			welcomeMessage += "\n\n" + tr(ctx, "employee.not_registered", "UserID", msg.From.ID)
+		}
+	}
 
-*Perintah yang Tersedia:*
-📝 Kirim OTP - Absen (masuk/pulang)
-📊 /report - Lihat laporan absensi hari ini
-📈 /history - Lihat riwayat absensi Anda
-🏷️ /alias - Absen dengan nama lain
-🔄 /status - Cek status absensi hari ini
-📋 /fullreport - Download laporan lengkap (CSV)
-❓ /help - Tampilkan pesan bantuan ini
+	return b.sendMarkdownMessage(ctx, msg.Chat.ID, welcomeMessage)
+}
 
-*Sistem Absensi:*
-• Absen pertama = Masuk (check-in)
-• Absen kedua = Pulang (check-out)`
+// handleHelp handles the /help command
+func (b *Bot) handleHelp(ctx context.Context, msg *Message) error {
+	helpMessage := tr(ctx, "help.message", "Digits", b.attendanceService.OTPDigits())
 
-	return b.sendMarkdownMessage(msg.Chat.ID, welcomeMessage)
+	return b.sendMarkdownMessage(ctx, msg.Chat.ID, helpMessage)
 }
 
-// handleHelp handles the /help command
-func (b *Bot) handleHelp(msg *Message) error {
-	helpMessage := `❓ *Bantuan Attendance Bot*
+// handleReport handles the /report command, optionally limited to a department
+func (b *Bot) handleReport(ctx context.Context, msg *Message, args []string) error {
+	department := ""
+	if len(args) > 0 {
+		resolved, ok, err := b.resolveDepartment(ctx, msg.Chat.ID, strings.Join(args, " "))
+		if !ok {
+			return err
+		}
+		department = resolved
+	}
 
-*Cara menggunakan:*
-1. Dapatkan OTP dari aplikasi autentikator Anda
-2. Kirimkan kode 6 digit ke bot ini
-3. Sistem akan otomatis menentukan check-in atau check-out
+	report, err := b.attendanceService.GenerateDepartmentReport(ctx, utils.GetTodayDate(), department, i18n.FromContext(ctx))
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.create_report", "Failed to generate report", "department", department)
+	}
 
-*Sistem Absensi:*
-• Absen pertama dalam hari = *Check-in* (Masuk)
-• Absen kedua dalam hari = *Check-out* (Pulang)
+	pages := paginate(report)
+	text, _ := pageOf(ctx, pages, 0)
+	return b.api.SendMessageWithOptions(ctx, msg.Chat.ID, text, &SendMessageOptions{
+		ParseMode:   "Markdown",
+		ReplyMarkup: reportKeyboard(ctx, utils.GetTodayDate(), department, 0, len(pages)),
+	})
+}
 
-*Perintah:*
-📊 /report - Lihat laporan absensi hari ini
-📈 /history - Lihat riwayat absensi Anda (30 hari terakhir)
-🔄 /status - Cek status absensi hari ini (masuk/pulang)
-🏷️ /alias - Gunakan nama panggilan/alias untuk absensi
-   Format: /alias [Nama Depan] [Nama Belakang]
-   Contoh: /alias John Doe
-📋 /fullreport - Download laporan lengkap dalam format CSV
-   Format: Masukkan rentang tanggal (YYYY-MM-DD YYYY-MM-DD)`
+// handleReportCallback shows the report of the date in the button's data, "<date>" or
+// "<date>:<department>", in place of the message, letting users page through previous days. A
+// long report shows the page given as "<date>#<page>".
+func (b *Bot) handleReportCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	if query.Message == nil {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, "")
+	}
+	date, department, _ := strings.Cut(data, ":")
+	date, page, _ := strings.Cut(date, "#")
+	if _, err := utils.ParseDate(date); err != nil || date > utils.GetTodayDate() {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "report.invalid_date"))
+	}
 
-	return b.sendMarkdownMessage(msg.Chat.ID, helpMessage)
+	report, err := b.attendanceService.GenerateDepartmentReport(ctx, date, department, i18n.FromContext(ctx))
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to generate report", "date", date, "department", department, "error", err)
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "report.failed"))
+	}
+	if err := b.api.AnswerCallbackQuery(ctx, query.ID, ""); err != nil {
+		return err
+	}
+
+	pages := paginate(report)
+	text, shown := pageOf(ctx, pages, parsePage(page))
+	return b.api.EditMessageText(ctx, query.Message.Chat.ID, query.Message.MessageID, text, &SendMessageOptions{
+		ParseMode:   "Markdown",
+		ReplyMarkup: reportKeyboard(ctx, date, department, shown, len(pages)),
+	})
 }
 
-// handleReport handles the /report command
-func (b *Bot) handleReport(msg *Message) error {
-	report, err := b.attendanceService.GenerateAttendanceReport()
+// reportKeyboard returns the buttons under a page of a daily report: the previous day, a refresh
+// and, before today, the next day, all of the same department, and below them the previous and
+// next page of a long report
+func reportKeyboard(ctx context.Context, date, department string, page, pages int) *InlineKeyboardMarkup {
+	day, err := utils.ParseDate(date)
+	if err != nil {
+		return nil
+	}
+
+	data := func(date string) string {
+		if department == "" {
+			return "report:" + date
+		}
+		return "report:" + date + ":" + department
+	}
+
+	previous := utils.AddDays(day, -1)
+	refresh := date
+	if page > 0 {
+		refresh = fmt.Sprintf("%s#%d", date, page)
+	}
+	row := []InlineKeyboardButton{
+		{Text: "◀️ " + utils.FormatDate(previous, "02/01"), CallbackData: data(previous.Format("2006-01-02"))},
+		{Text: tr(ctx, "report.refresh_button"), CallbackData: data(refresh)},
+	}
+	if date < utils.GetTodayDate() {
+		next := utils.AddDays(day, 1)
+		row = append(row, InlineKeyboardButton{Text: utils.FormatDate(next, "02/01") + " ▶️", CallbackData: data(next.Format("2006-01-02"))})
+	}
+
+	keyboard := [][]InlineKeyboardButton{row}
+	if buttons := pageButtons(ctx, page, pages, func(page int) string {
+		return data(fmt.Sprintf("%s#%d", date, page))
+	}); len(buttons) > 0 {
+		keyboard = append(keyboard, buttons)
+	}
+	return &InlineKeyboardMarkup{InlineKeyboard: keyboard}
+}
+
+// historyDays is how far back /history looks without a month or date range
+const historyDays = 30
+
+// maxHistoryDays bounds the date range of /history
+const maxHistoryDays = 366
+
+// handleHistory handles the /history command: the last 30 days, a month given as YYYY-MM, or the
+// range between two YYYY-MM-DD dates
+func (b *Bot) handleHistory(ctx context.Context, msg *Message, args []string) error {
+	startDate, endDate, problem := parseHistoryRange(args)
+	if problem == "history.usage" {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, problem))
+	}
+	if problem != "" {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, problem, "Days", maxHistoryDays))
+	}
+
+	text, keyboard, err := b.historyPage(ctx, msg.From.ID, startDate, endDate, 0)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_history", "Failed to get attendance history",
+			"start_date", startDate, "end_date", endDate)
+	}
+	return b.api.SendMessageWithOptions(ctx, msg.Chat.ID, text, &SendMessageOptions{ParseMode: "Markdown", ReplyMarkup: keyboard})
+}
+
+// parseHistoryRange reads the optional month or date range of /history. Empty dates stand for the
+// last historyDays days. Invalid arguments return the key of the message explaining why.
+func parseHistoryRange(args []string) (string, string, string) {
+	switch {
+	case len(args) == 0:
+		return "", "", ""
+	case len(args) == 1 && looksLikeMonth(args[0]):
+		first, err := time.ParseInLocation("2006-01", args[0], utils.Location)
+		if err != nil {
+			return "", "", "history.usage"
+		}
+		return first.Format("2006-01-02"), first.AddDate(0, 1, -1).Format("2006-01-02"), ""
+	case len(args) == 2:
+		start, startErr := utils.ParseDate(args[0])
+		end, endErr := utils.ParseDate(args[1])
+		if startErr != nil || endErr != nil || !utils.IsValidDateFormat(args[0]) || !utils.IsValidDateFormat(args[1]) {
+			return "", "", "history.usage"
+		}
+		if start.After(end) {
+			return "", "", "history.start_after_end"
+		}
+		if end.Sub(start) >= maxHistoryDays*24*time.Hour {
+			return "", "", "history.range_too_long"
+		}
+		return args[0], args[1], ""
+	default:
+		return "", "", "history.usage"
+	}
+}
+
+// userHistory returns the user's attendance in the date range, or in the last historyDays days
+// for empty dates
+func (b *Bot) userHistory(ctx context.Context, userID int64, startDate, endDate string) ([]models.AttendanceRecord, error) {
+	if startDate == "" {
+		return b.attendanceService.GetUserAttendanceHistory(ctx, userID, historyDays)
+	}
+	return b.attendanceService.GetUserAttendanceRange(ctx, userID, startDate, endDate)
+}
+
+// historyPage returns page number page of the user's history in the date range, with its buttons
+func (b *Bot) historyPage(ctx context.Context, userID int64, startDate, endDate string, page int) (string, *InlineKeyboardMarkup, error) {
+	records, err := b.userHistory(ctx, userID, startDate, endDate)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(records) == 0 {
+		if startDate == "" {
+			return tr(ctx, "history.empty"), nil, nil
+		}
+		return tr(ctx, "history.empty_range", "Start", startDate, "End", endDate), nil, nil
+	}
+
+	shift, err := b.attendanceService.GetUserShift(ctx, userID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get user shift: %w", err)
+	}
+	location, err := b.attendanceService.UserLocation(ctx, userID)
 	if err != nil {
-		b.logger.Error("Failed to generate report", "error", err)
-		return b.sendMessage(msg.Chat.ID, "❌ Terjadi kesalahan saat membuat laporan. Silakan coba lagi.")
+		return "", nil, fmt.Errorf("failed to get user timezone: %w", err)
 	}
 
-	return b.sendMarkdownMessage(msg.Chat.ID, report)
+	title := tr(ctx, "history.title")
+	if startDate != "" {
+		title = tr(ctx, "history.title_range", "Start", startDate, "End", endDate)
+	}
+	pages := paginate(b.formatHistoryMessage(ctx, title, records, shift, location))
+	text, shown := pageOf(ctx, pages, page)
+	return text, historyKeyboard(ctx, userID, startDate, endDate, shown, len(pages)), nil
 }
 
-// handleHistory handles the /history command
-func (b *Bot) handleHistory(msg *Message) error {
-	records, err := b.attendanceService.GetUserAttendanceHistory(msg.From.ID, 30)
+// handleHistoryCallback handles the buttons under a history, whose data is
+// "<user_id>:<page>[:<start>:<end>]": a page number shows that page of a long history in place of
+// the message, and "csv" sends the history as a CSV file. Only the user whose history it is may
+// press them.
+func (b *Bot) handleHistoryCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	if query.Message == nil {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, "")
+	}
+	parts := strings.Split(data, ":")
+	if parts[0] != strconv.FormatInt(query.From.ID, 10) {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "history.not_yours"))
+	}
+	action := ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+	startDate, endDate, problem := parseHistoryRange(parts[min(len(parts), 2):])
+	if problem != "" {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "common.button_expired"))
+	}
+
+	if action == "csv" {
+		if err := b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "history.exporting")); err != nil {
+			return err
+		}
+		return b.sendHistoryCSV(ctx, query.Message.Chat.ID, query.From.ID, startDate, endDate)
+	}
+
+	text, keyboard, err := b.historyPage(ctx, query.From.ID, startDate, endDate, parsePage(action))
 	if err != nil {
-		b.logger.Error("Failed to get attendance history", "error", err, "user_id", msg.From.ID)
-		return b.sendMessage(msg.Chat.ID, "❌ Terjadi kesalahan saat mengambil riwayat. Silakan coba lagi.")
+		logging.FromContext(ctx).Error("Failed to get attendance history", "start_date", startDate, "end_date", endDate, "error", err)
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "history.failed"))
 	}
+	if err := b.api.AnswerCallbackQuery(ctx, query.ID, ""); err != nil {
+		return err
+	}
+	return b.api.EditMessageText(ctx, query.Message.Chat.ID, query.Message.MessageID, text, &SendMessageOptions{
+		ParseMode:   "Markdown",
+		ReplyMarkup: keyboard,
+	})
+}
 
+// sendHistoryCSV sends the user their attendance in the date range, or in the last historyDays
+// days for empty dates, as a CSV file with lateness judged by their shift
+func (b *Bot) sendHistoryCSV(ctx context.Context, chatID, userID int64, startDate, endDate string) error {
+	records, err := b.userHistory(ctx, userID, startDate, endDate)
+	if err != nil {
+		return b.replyError(ctx, chatID, err, "action.get_history", "Failed to get attendance history",
+			"start_date", startDate, "end_date", endDate)
+	}
+	if len(records) == 0 && startDate == "" {
+		return b.sendMessage(ctx, chatID, tr(ctx, "history.empty"))
+	}
 	if len(records) == 0 {
-		return b.sendMessage(msg.Chat.ID, "📭 Tidak ada riwayat absensi dalam 30 hari terakhir.")
+		return b.sendMessage(ctx, chatID, tr(ctx, "history.empty_range", "Start", startDate, "End", endDate))
+	}
+	shift, err := b.attendanceService.GetUserShift(ctx, userID)
+	if err != nil {
+		return b.replyError(ctx, chatID, err, "action.get_history", "Failed to get user shift")
+	}
+
+	data, err := b.csvGenerator.GenerateUserReport(records, userID, shift)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to generate report", "format", "csv", "error", err)
+		return b.sendMessage(ctx, chatID, tr(ctx, "file.create_failed", "Format", formatName("csv")))
 	}
 
-	message := b.formatHistoryMessage(records)
-	return b.sendMarkdownMessage(msg.Chat.ID, message)
+	// Records come newest day first
+	if startDate == "" {
+		startDate, endDate = records[len(records)-1].Date, records[0].Date
+	}
+	caption := tr(ctx, "history.export_caption", "Start", startDate, "End", endDate,
+		"Days", len(models.GroupByDay(records)))
+	filename := fmt.Sprintf("attendance_history_%s_to_%s.csv", startDate, endDate)
+	return b.sendReport(ctx, chatID, userID, bytes.NewReader(data), filename, caption,
+		fmt.Sprintf("history %s..%s csv", startDate, endDate))
+}
+
+// historyKeyboard returns the buttons under a page of a history: the previous and next page of a
+// long history, then the CSV export, all of the same date range
+func historyKeyboard(ctx context.Context, userID int64, startDate, endDate string, page, pages int) *InlineKeyboardMarkup {
+	data := func(action string) string {
+		if startDate == "" {
+			return fmt.Sprintf("history:%d:%s", userID, action)
+		}
+		return fmt.Sprintf("history:%d:%s:%s:%s", userID, action, startDate, endDate)
+	}
+
+	var keyboard [][]InlineKeyboardButton
+	if buttons := pageButtons(ctx, page, pages, func(page int) string {
+		return data(strconv.Itoa(page))
+	}); len(buttons) > 0 {
+		keyboard = append(keyboard, buttons)
+	}
+	keyboard = append(keyboard, []InlineKeyboardButton{{Text: tr(ctx, "history.export_button"), CallbackData: data("csv")}})
+	return &InlineKeyboardMarkup{InlineKeyboard: keyboard}
 }
 
 // handleStatus handles the /status command
-func (b *Bot) handleStatus(msg *Message) error {
-	today := utils.GetTodayDate()
-	status, err := b.attendanceService.GetUserAttendanceStatus(msg.From.ID, today)
+func (b *Bot) handleStatus(ctx context.Context, msg *Message) error {
+	location := b.userLocation(ctx, msg.From.ID)
+	today := time.Now().In(location).Format("2006-01-02")
+	status, err := b.attendanceService.GetUserAttendanceStatus(ctx, msg.From.ID, today)
 	if err != nil {
-		b.logger.Error("Failed to get attendance status", "error", err, "user_id", msg.From.ID)
-		return b.sendMessage(msg.Chat.ID, "❌ Terjadi kesalahan saat mengecek status. Silakan coba lagi.")
+		return b.replyError(ctx, msg.Chat.ID, err, "action.check_status", "Failed to get attendance status")
 	}
 
 	var message string
 	if !status.HasCheckedIn && !status.HasCheckedOut {
-		message = "❌ *Status Absensi*\n\nAnda belum absen hari ini.\nKirim OTP Anda untuk *check-in*."
+		message = tr(ctx, "status.none")
 	} else if status.HasCheckedIn && !status.HasCheckedOut {
-		checkInTime := utils.FormatTime(status.CheckInRecord.Timestamp, "HH:mm")
-		message = fmt.Sprintf("🟡 *Status Absensi*\n\n✅ Check-in: %s\n❌ Check-out: Belum\n\nKirim OTP Anda untuk *check-out*.", checkInTime)
+		checkInTime := utils.FormatTimeIn(status.CheckInRecord.Timestamp, "HH:mm", location)
+		message = tr(ctx, "status.checked_in", "CheckIn", checkInTime)
 	} else {
-		checkInTime := utils.FormatTime(status.CheckInRecord.Timestamp, "HH:mm")
-		checkOutTime := utils.FormatTime(status.CheckOutRecord.Timestamp, "HH:mm")
-		duration := utils.CalculateWorkDuration(status.CheckInRecord.Timestamp, status.CheckOutRecord.Timestamp)
-		message = fmt.Sprintf("✅ *Status Absensi*\n\n✅ Check-in: %s\n✅ Check-out: %s\n⌛ Durasi kerja: %s\n\nAbsensi hari ini sudah lengkap.", checkInTime, checkOutTime, duration)
+		checkInTime := utils.FormatTimeIn(status.CheckInRecord.Timestamp, "HH:mm", location)
+		checkOutTime := utils.FormatTimeIn(status.CheckOutRecord.Timestamp, "HH:mm", location)
+		duration := utils.CalculateWorkDuration(status.CheckInRecord.Timestamp, status.CheckOutRecord.Timestamp,
+			status.BreakTime(status.CheckOutRecord.Timestamp), i18n.FromContext(ctx))
+		message = tr(ctx, "status.complete", "CheckIn", checkInTime, "CheckOut", checkOutTime, "Duration", duration)
+	}
+
+	if count := status.BreakCount(); count > 0 {
+		breakTime := utils.FormatDuration(status.BreakTime(utils.Now()), i18n.FromContext(ctx))
+		message += "\n\n" + tr(ctx, "status.breaks", "Count", count, "Duration", breakTime)
+		if status.OnBreak() {
+			message += "\n" + tr(ctx, "status.on_break")
+		}
+	}
+
+	return b.sendMarkdownMessage(ctx, msg.Chat.ID, message)
+}
+
+// handleDuration handles the /duration command
+func (b *Bot) handleDuration(ctx context.Context, msg *Message) error {
+	location := b.userLocation(ctx, msg.From.ID)
+	progress, err := b.attendanceService.GetWorkProgress(ctx, msg.From.ID, time.Now().In(location))
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.calculate_duration", "Failed to get work progress")
+	}
+
+	lang := i18n.FromContext(ctx)
+	switch {
+	case !progress.CheckedIn:
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "duration.not_checked_in"))
+	case progress.CheckedOut:
+		duration := utils.CalculateWorkDuration(progress.CheckIn, progress.CheckOut, progress.Breaks, lang)
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "duration.checked_out",
+			"CheckOut", utils.FormatTimeIn(progress.CheckOut, "HH:mm", location), "Duration", duration))
+	}
+
+	message := tr(ctx, "duration.elapsed",
+		"Elapsed", utils.FormatDuration(progress.Elapsed, lang), "CheckIn", utils.FormatTimeIn(progress.CheckIn, "HH:mm", location))
+	if progress.Target > 0 {
+		if progress.Remaining > 0 {
+			message += tr(ctx, "duration.remaining", "Remaining", utils.FormatDuration(progress.Remaining, lang))
+		} else {
+			message += tr(ctx, "duration.target_reached")
+		}
+	}
+	if progress.Breaks > 0 {
+		message += "\n" + tr(ctx, "duration.breaks", "Breaks", utils.FormatDuration(progress.Breaks, lang))
+	}
+	if progress.OnBreak {
+		message += "\n" + tr(ctx, "status.on_break")
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, message)
+}
+
+// handleWho handles the /who command
+func (b *Bot) handleWho(ctx context.Context, msg *Message) error {
+	report, err := b.attendanceService.GenerateOnShiftReport(ctx, utils.Now(), i18n.FromContext(ctx))
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_on_shift", "Failed to get on-shift users")
 	}
 
-	return b.sendMarkdownMessage(msg.Chat.ID, message)
+	return b.sendMarkdownMessage(ctx, msg.Chat.ID, report)
 }
 
 // handleAlias handles the /alias command
-func (b *Bot) handleAlias(msg *Message, args []string) error {
+func (b *Bot) handleAlias(ctx context.Context, msg *Message, args []string) error {
 	if len(args) == 0 {
-		return b.sendMessage(msg.Chat.ID, "❌ Format tidak valid. Gunakan: /alias [Nama Depan] [Nama Belakang]")
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "alias.usage"))
 	}
 
 	firstName := utils.SanitizeName(args[0])
 	if firstName == "" {
-		return b.sendMessage(msg.Chat.ID, "❌ Nama depan tidak valid.")
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "alias.invalid_first_name"))
 	}
 
 	var lastName *string
@@ -247,57 +816,169 @@ func (b *Bot) handleAlias(msg *Message, args []string) error {
 		}
 	}
 
-	err := b.attendanceService.SetUserAlias(msg.From.ID, firstName, lastName)
+	// An alias that reads like another user's name needs admin approval, so nobody can
+	// record attendance that shows up under someone else's name
+	conflicts, err := b.attendanceService.FindAliasConflicts(ctx, msg.From.ID, firstName, lastName)
 	if err != nil {
-		b.logger.Error("Failed to set user alias", "error", err, "user_id", msg.From.ID)
-		return b.sendMessage(msg.Chat.ID, "❌ Gagal menyimpan alias. Silakan coba lagi.")
+		return b.replyError(ctx, msg.Chat.ID, err, "action.check_alias", "Failed to check alias conflicts")
 	}
-
-	var aliasName string
-	if lastName != nil {
-		aliasName = fmt.Sprintf("%s %s", firstName, *lastName)
-	} else {
-		aliasName = firstName
+	if len(conflicts) > 0 {
+		return b.requestAliasApproval(ctx, msg, firstName, lastName, conflicts)
 	}
 
-	return b.sendMessage(msg.Chat.ID, fmt.Sprintf("✅ Alias berhasil diatur: %s", aliasName))
+	err = b.attendanceService.SetUserAlias(ctx, msg.From.ID, firstName, lastName)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_alias", "Failed to set user alias")
+	}
+	if err := b.attendanceService.CancelAliasRequest(ctx, msg.From.ID); err != nil {
+		logging.FromContext(ctx).Warn("Failed to discard pending alias request", "error", err)
+	}
+	b.attendanceService.Audit(ctx, models.AuditEntry{
+		ActorID:      msg.From.ID,
+		Action:       models.AuditAliasSet,
+		TargetUserID: msg.From.ID,
+		Details:      attendance.FullName(firstName, lastName),
+	})
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "alias.set", "Alias", attendance.FullName(firstName, lastName)))
 }
 
 // handleFullReport handles the /fullreport command
-func (b *Bot) handleFullReport(msg *Message, args []string) error {
-	response := `📊 *Laporan Lengkap Absensi*
-
-Silakan masukkan password admin dan rentang tanggal dalam format:
-` + "`[password] YYYY-MM-DD YYYY-MM-DD`" + `
-
-*Contoh:*
-` + "`admin123 2025-01-01 2025-01-31`" + `
+func (b *Bot) handleFullReport(ctx context.Context, msg *Message, args []string) error {
+	if len(args) >= 2 {
+		format := ""
+		if len(args) > 2 {
+			format = strings.ToLower(args[2])
+		}
+		department := ""
+		if len(args) > 3 {
+			resolved, ok, err := b.resolveDepartment(ctx, msg.Chat.ID, strings.Join(args[3:], " "))
+			if !ok {
+				return err
+			}
+			department = resolved
+		}
+		return b.sendFullReport(ctx, msg.Chat.ID, msg.From.ID, args[0], args[1], format, department)
+	}
 
-*Catatan:* Laporan akan dikirim dalam format CSV.`
+	response := tr(ctx, "fullreport.prompt")
 
 	// Set user session to await date range input
-	b.sessions[msg.From.ID] = &SessionData{
-		AwaitingDateRange: true,
+	if err := b.sessions.Set(ctx, msg.From.ID, stateAwaitingDateRange, nil); err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.start_conversation", "Failed to save session")
+	}
+
+	keyboard := &InlineKeyboardMarkup{}
+	for _, preset := range fullReportPresets {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []InlineKeyboardButton{{
+			Text:         tr(ctx, "fullreport.preset."+preset.key),
+			CallbackData: "fullreport:" + preset.key,
+		}})
 	}
 
-	return b.sendMarkdownMessage(msg.Chat.ID, response)
+	return b.api.SendMessageWithOptions(ctx, msg.Chat.ID, response, &SendMessageOptions{ParseMode: "Markdown", ReplyMarkup: keyboard})
 }
 
-// handleOTP handles OTP verification and attendance marking
-func (b *Bot) handleOTP(msg *Message) error {
-	username := msg.From.Username
-	if username == "" {
-		username = fmt.Sprintf("user_%d", msg.From.ID)
+// fullReportPresets are the periods offered as buttons by /fullreport, ending today at the latest.
+// Their labels are the messages "fullreport.preset.<key>".
+var fullReportPresets = []struct {
+	key   string
+	dates func(today time.Time) (start, end time.Time)
+}{
+	{"7d", func(today time.Time) (time.Time, time.Time) {
+		return utils.AddDays(today, -6), today
+	}},
+	{"month", func(today time.Time) (time.Time, time.Time) {
+		return today.AddDate(0, 0, 1-today.Day()), today
+	}},
+	{"lastmonth", func(today time.Time) (time.Time, time.Time) {
+		firstOfMonth := today.AddDate(0, 0, 1-today.Day())
+		return firstOfMonth.AddDate(0, -1, 0), utils.AddDays(firstOfMonth, -1)
+	}},
+}
+
+// fullReportFormats are the file formats offered by /fullreport
+var fullReportFormats = []struct {
+	key   string
+	label string
+}{
+	{"csv", "📄 CSV"},
+	{"xlsx", "📊 Excel"},
+	{"pdf", "📑 PDF"},
+}
+
+// isFullReportFormat reports whether format is one of fullReportFormats
+func isFullReportFormat(format string) bool {
+	for _, f := range fullReportFormats {
+		if f.key == format {
+			return true
+		}
 	}
+	return false
+}
 
-	firstName := utils.SanitizeName(msg.From.FirstName)
-	var lastName *string
-	if msg.From.LastName != "" {
-		lastNameVal := utils.SanitizeName(msg.From.LastName)
-		lastName = &lastNameVal
+// handleFullReportCallback handles the /fullreport buttons: a period button, with data
+// "<preset>", asks for the format, and a format button, with data "<format>:<start>:<end>",
+// sends the report
+func (b *Bot) handleFullReportCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	if query.Message == nil {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, "")
+	}
+	// The buttons may be pressed by anyone who can see the message
+	if !b.isAdmin(ctx, query.From.ID) {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "fullreport.admin_only_toast"))
+	}
+
+	if format, period, ok := strings.Cut(data, ":"); ok {
+		startDate, endDate, _ := strings.Cut(period, ":")
+		if !isFullReportFormat(format) || !utils.IsValidDateFormat(startDate) || !utils.IsValidDateFormat(endDate) {
+			return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "common.button_expired"))
+		}
+		if err := b.api.AnswerCallbackQuery(ctx, query.ID, ""); err != nil {
+			return err
+		}
+
+		// Replace the format buttons with the progress message so the report is requested once
+		progress := tr(ctx, "fullreport.progress", "Format", formatName(format), "Start", startDate, "End", endDate)
+		if err := b.api.EditMessageText(ctx, query.Message.Chat.ID, query.Message.MessageID, progress, nil); err != nil {
+			logging.FromContext(ctx).Warn("Failed to update full report message", "error", err)
+		}
+		return b.generateAndSendReport(ctx, query.Message.Chat.ID, query.From.ID, startDate, endDate, format, "")
+	}
+
+	for _, preset := range fullReportPresets {
+		if preset.key != data {
+			continue
+		}
+
+		b.sessions.Clear(ctx, query.From.ID)
+		if err := b.api.AnswerCallbackQuery(ctx, query.ID, ""); err != nil {
+			return err
+		}
+
+		start, end := preset.dates(utils.Now())
+		return b.sendFullReport(ctx, query.Message.Chat.ID, query.From.ID, start.Format("2006-01-02"), end.Format("2006-01-02"), "", "")
+	}
+
+	return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "common.button_expired"))
+}
+
+// handleOTP handles OTP verification and attendance marking
+func (b *Bot) handleOTP(ctx context.Context, msg *Message) error {
+	username, firstName, lastName := recordedIdentity(msg.From)
+
+	// A forwarded or delayed code may belong to someone else, so it never records attendance
+	switch suspiciousOTP(msg, time.Now(), b.attendanceService.OTPPeriod()) {
+	case otpForwarded:
+		logging.FromContext(ctx).Warn("Rejected forwarded OTP message", "username", username)
+		return b.sendOTPReply(ctx, msg, tr(ctx, "otp.forwarded"), false)
+	case otpStale:
+		logging.FromContext(ctx).Warn("Rejected stale OTP message", "username", username, "message_date", time.Unix(msg.Date, 0))
+		return b.sendOTPReply(ctx, msg, tr(ctx, "otp.stale"), false)
 	}
 
 	result, err := b.attendanceService.MarkAttendance(
+		ctx,
 		msg.From.ID,
 		username,
 		firstName,
@@ -305,194 +986,586 @@ func (b *Bot) handleOTP(msg *Message) error {
 		msg.Text,
 	)
 	if err != nil {
-		b.logger.Error("Failed to mark attendance", "error", err, "user_id", msg.From.ID)
-		return b.sendMessage(msg.Chat.ID, "❌ Terjadi kesalahan saat memproses absensi. Silakan coba lagi.")
+		return b.replyError(ctx, msg.Chat.ID, err, "action.process_attendance", "Failed to mark attendance")
+	}
+
+	if result.SkewOffset != 0 {
+		logging.FromContext(ctx).Info("OTP matched outside the current time step", "offset", result.SkewOffset)
+	}
+	if result.PreviousSecret {
+		logging.FromContext(ctx).Warn("OTP matched the previous secret", "secret", "previous")
+	}
+	if result.Bypass {
+		b.notifyBypassUsed(ctx, msg, username, result)
+	}
+	if result.OTPRejected {
+		// Record in the background so the user-facing reply is never delayed; the update's
+		// context is cancelled once it has been handled
+		ctx := context.WithoutCancel(ctx)
+		b.inFlight.Add(1)
+		go func() {
+			defer b.inFlight.Done()
+			b.recordFailedOTP(ctx, msg, username)
+		}()
+	}
+	if result.Lockout != nil {
+		b.notifyOTPLockout(ctx, msg.From.ID, username, result.Lockout)
 	}
 
 	if result.Success {
-		return b.sendMarkdownMessage(msg.Chat.ID, result.Message)
+		// Photos are only taken in private
+		if window := b.attendanceService.PhotoWindow(); window > 0 && result.Record.Type == "check_in" && msg.Chat.Type == "private" {
+			result.Message += "\n\n" + tr(ctx, "photo.prompt", "Minutes", int(window.Minutes()))
+		}
+		return b.sendOTPReply(ctx, msg, result.Message, true)
 	} else {
-		return b.sendMessage(msg.Chat.ID, result.Message)
+		return b.sendOTPReply(ctx, msg, result.Message, false)
 	}
 }
 
-// handleTextMessage handles non-command text messages
-func (b *Bot) handleTextMessage(msg *Message) error {
-	// Check if user is awaiting date range input for full report
-	session := b.sessions[msg.From.ID]
-	if session != nil && session.AwaitingDateRange {
-		return b.handleFullReportInput(msg)
+// recordFailedOTP stores a rejected OTP and alerts the admin chat once the user crosses the threshold
+func (b *Bot) recordFailedOTP(ctx context.Context, msg *Message, username string) {
+	logger := logging.FromContext(ctx)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Panic while recording failed OTP", "panic", r)
+		}
+	}()
+
+	window := time.Duration(b.config.OTPFailureWindow) * time.Minute
+	count, err := b.attendanceService.RecordFailedOTP(ctx, msg.From.ID, username, msg.Chat.Type, msg.Text, window)
+	if err != nil {
+		logger.Error("Failed to record failed OTP", "error", err)
+		return
 	}
 
-	return b.sendMessage(msg.Chat.ID, "📝 Kirimkan kode OTP 6 digit Anda untuk absen, atau ketik /help untuk bantuan.")
+	logger.Warn("OTP verification failed",
+		"username", username,
+		"chat_type", msg.Chat.Type,
+		"failures_in_window", count)
+
+	// Alert exactly once when the threshold is reached within the window
+	if count != b.config.OTPFailureLimit || b.config.AdminChatID == 0 {
+		return
+	}
+
+	alert := i18n.T(i18n.Default, "otp.failure_alert",
+		"Username", username, "UserID", msg.From.ID, "Count", count, "Minutes", b.config.OTPFailureWindow)
+	if err := b.sendMessage(ctx, b.config.AdminChatID, alert); err != nil {
+		logger.Error("Failed to send OTP failure alert", "error", err)
+	}
 }
 
-// formatHistoryMessage formats attendance history into a readable message
-func (b *Bot) formatHistoryMessage(records []models.AttendanceRecord) string {
+// notifyOTPLockout alerts the admin chat that a user was locked out after too many wrong OTPs
+func (b *Bot) notifyOTPLockout(ctx context.Context, userID int64, username string, lockout *models.OTPLockout) {
+	if b.config.AdminChatID == 0 {
+		return
+	}
+
+	alert := i18n.T(i18n.Default, "otp.lockout_alert", "Username", username, "UserID", userID, "Count", lockout.Failures,
+		"Since", utils.FormatTime(lockout.WindowStart, "HH:mm"), "Until", utils.FormatTime(lockout.LockedUntil, "HH:mm"))
+	if err := b.sendMessage(ctx, b.config.AdminChatID, alert); err != nil {
+		logging.FromContext(ctx).Error("Failed to send OTP lockout alert", "error", err)
+	}
+}
+
+// Reasons an OTP message is refused before verification
+const (
+	otpForwarded = "forwarded"
+	otpStale     = "stale"
+)
+
+// suspiciousOTP returns why an OTP message must not be used, or "" if it may be.
+// Messages older than maxAge (the TOTP period) are refused, since the code was valid
+// when written but the message may have been held back or replayed.
+func suspiciousOTP(msg *Message, now time.Time, maxAge time.Duration) string {
+	switch {
+	case msg.IsForwarded():
+		return otpForwarded
+	case msg.Date != 0 && now.Sub(time.Unix(msg.Date, 0)) > maxAge:
+		return otpStale
+	default:
+		return ""
+	}
+}
+
+// handleOTPFailures handles the /otpfailures command
+func (b *Bot) handleOTPFailures(ctx context.Context, msg *Message, args []string) error {
+	hours := 24
+	if len(args) > 0 {
+		parsed, err := utils.ParseInteger(args[0])
+		if err != nil || parsed <= 0 || parsed > 24*30 {
+			return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "otpfailures.usage"))
+		}
+		hours = int(parsed)
+	}
+
+	failures, err := b.attendanceService.GetRecentFailedOTPs(ctx, time.Duration(hours)*time.Hour)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_data", "Failed to get failed OTPs")
+	}
+	lockouts, err := b.attendanceService.GetOTPLockouts(ctx)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_data", "Failed to get OTP lockouts")
+	}
+
 	var message strings.Builder
-	message.WriteString("📈 *Riwayat Absensi Anda (30 hari terakhir)*\n\n")
+	if len(lockouts) > 0 {
+		message.WriteString(tr(ctx, "otpfailures.locked") + "\n")
+		for _, lockout := range lockouts {
+			message.WriteString(tr(ctx, "otpfailures.lockout", "UserID", lockout.UserID, "Count", lockout.Failures,
+				"Until", utils.FormatTime(lockout.LockedUntil, "HH:mm")) + "\n")
+		}
+		message.WriteString("\n")
+	}
 
-	// Group by date
-	dailyRecords := make(map[string]map[string]*models.AttendanceRecord)
-	dates := []string{}
+	if len(failures) == 0 {
+		return b.sendMessage(ctx, msg.Chat.ID, message.String()+tr(ctx, "otpfailures.none", "Hours", hours))
+	}
 
-	for _, record := range records {
-		if dailyRecords[record.Date] == nil {
-			dailyRecords[record.Date] = make(map[string]*models.AttendanceRecord)
-			dates = append(dates, record.Date)
+	message.WriteString(tr(ctx, "otpfailures.title", "Hours", hours, "Count", len(failures)) + "\n\n")
+	for i, failure := range failures {
+		if i == 50 {
+			message.WriteString(tr(ctx, "common.and_more", "Count", len(failures)-i) + "\n")
+			break
 		}
-		dailyRecords[record.Date][record.Type] = &record
+		message.WriteString(fmt.Sprintf("%s  %s (ID %d)  %s  [%s]\n",
+			utils.FormatTime(failure.Timestamp, "HH:mm:ss"),
+			failure.Username,
+			failure.UserID,
+			failure.Code,
+			failure.ChatType))
 	}
 
-	// Sort dates in reverse order (newest first)
-	for i := len(dates) - 1; i >= 0; i-- {
-		date := dates[i]
-		dayRecord := dailyRecords[date]
+	return b.sendMessage(ctx, msg.Chat.ID, message.String())
+}
 
-		// Parse and format date
-		dateTime, err := utils.ParseDate(date)
-		if err != nil {
-			continue
+// handleOTPUnlock handles the /otpunlock command, which lifts a user's OTP lockout early
+func (b *Bot) handleOTPUnlock(ctx context.Context, msg *Message, args []string) error {
+	if b.config.OTPLockoutLimit == 0 {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "otpunlock.disabled"))
+	}
+
+	if len(args) != 1 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "otpunlock.usage"))
+	}
+	userID, err := utils.ParseInteger(args[0])
+	if err != nil || !utils.IsValidTelegramUserID(userID) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.invalid_user_id"))
+	}
+
+	unlocked, err := b.attendanceService.UnlockOTP(ctx, userID, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.unlock_otp", "Failed to unlock user", "target_user_id", userID)
+	}
+	if !unlocked {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "otpunlock.not_locked", "UserID", userID))
+	}
+
+	logging.FromContext(ctx).Warn("OTP lockout lifted", "audit", models.AuditOTPUnlocked, "target_user_id", userID)
+
+	// Users who never started a private chat with the bot cannot be notified
+	if err := b.sendMessage(ctx, userID, i18n.T(b.languageOf(ctx, userID), "otpunlock.notice")); err != nil {
+		logging.FromContext(ctx).Info("Failed to notify user about unlock", "target_user_id", userID, "error", err)
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "otpunlock.done", "UserID", userID))
+}
+
+// handleArchive handles the /archive command, moving a past year's records into the archive table
+func (b *Bot) handleArchive(ctx context.Context, msg *Message, args []string) error {
+	if len(args) != 1 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "archive.usage"))
+	}
+	year, err := utils.ParseInteger(args[0])
+	if err != nil {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "archive.invalid_year"))
+	}
+
+	if err := b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "archive.progress", "Year", year)); err != nil {
+		return err
+	}
+
+	logger := logging.FromContext(ctx)
+	result, err := b.attendanceService.ArchiveYear(ctx, int(year), func(moved int) {
+		logger.Debug("Archive progress", "moved", moved)
+	})
+	if errors.Is(err, attendance.ErrInvalidArchiveYear) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "archive.future_year"))
+	}
+	if err != nil {
+		if result != nil && result.Moved > 0 {
+			logger.Error("Archive interrupted", "moved", result.Moved, "batches", result.Batches, "error", err)
+			return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "archive.interrupted", "Moved", result.Moved, "Year", year))
+		}
+		return b.replyError(ctx, msg.Chat.ID, err, "action.archive", "Failed to archive attendance", "year", year)
+	}
+
+	logger.Info("Attendance archived", "before", result.Before, "moved", result.Moved, "batches", result.Batches, "archived", result.Archived)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "archive.done",
+		"Moved", result.Moved, "Batches", result.Batches, "Before", result.Before, "Archived", result.Archived))
+}
+
+// handleAnomalies handles the /anomalies command, listing days with a check-in but no check-out
+func (b *Bot) handleAnomalies(ctx context.Context, msg *Message, args []string) error {
+	if len(args) != 2 || !utils.IsValidDateFormat(args[0]) || !utils.IsValidDateFormat(args[1]) {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "anomalies.usage"))
+	}
+	startDate, endDate := args[0], args[1]
+
+	missing, usedEnd, err := b.attendanceService.GetMissingCheckouts(ctx, startDate, endDate)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.find_missing_checkouts", "Failed to get missing checkouts")
+	}
+
+	var summary strings.Builder
+	summary.WriteString(tr(ctx, "anomalies.title") + "\n\n")
+	summary.WriteString(tr(ctx, "anomalies.period", "Start", startDate, "End", usedEnd) + "\n")
+	if usedEnd != endDate {
+		summary.WriteString(tr(ctx, "anomalies.today_skipped") + "\n")
+	}
+
+	if len(missing) == 0 {
+		summary.WriteString("\n" + tr(ctx, "anomalies.none"))
+		return b.sendMessage(ctx, msg.Chat.ID, summary.String())
+	}
+
+	// Count days per user, most affected first
+	type userCount struct {
+		name  string
+		count int
+	}
+	counts := make(map[int64]*userCount)
+	var users []*userCount
+	for _, day := range missing {
+		if counts[day.UserID] == nil {
+			counts[day.UserID] = &userCount{name: day.Name}
+			users = append(users, counts[day.UserID])
+		}
+		counts[day.UserID].count++
+	}
+	sort.SliceStable(users, func(i, j int) bool { return users[i].count > users[j].count })
+
+	summary.WriteString(tr(ctx, "anomalies.total", "Days", len(missing), "Users", len(users)) + "\n\n")
+	for i, user := range users {
+		if i == 20 {
+			summary.WriteString(tr(ctx, "anomalies.more_users", "Count", len(users)-i) + "\n")
+			break
+		}
+		summary.WriteString(tr(ctx, "anomalies.user", "Name", user.name, "Days", user.count) + "\n")
+	}
+	summary.WriteString("\n" + tr(ctx, "anomalies.details"))
+
+	if err := b.sendMessage(ctx, msg.Chat.ID, summary.String()); err != nil {
+		return err
+	}
+
+	return b.sendMissingCheckoutsCSV(ctx, msg.Chat.ID, msg.From.ID, missing, startDate, usedEnd)
+}
+
+// sendMissingCheckoutsCSV generates the missing check-outs CSV and sends it as a document
+// requested by actorID
+func (b *Bot) sendMissingCheckoutsCSV(ctx context.Context, chatID, actorID int64, missing []models.MissingCheckout, startDate, endDate string) error {
+	logger := logging.FromContext(ctx)
+
+	data, err := b.csvGenerator.GenerateMissingCheckoutsReport(missing)
+	if err != nil {
+		logger.Error("Failed to generate missing checkouts CSV", "error", err)
+		return b.sendMessage(ctx, chatID, tr(ctx, "file.create_csv_failed"))
+	}
+
+	filename := fmt.Sprintf("missing_checkouts_%s_to_%s.csv", startDate, endDate)
+	if err := b.api.SendDocument(ctx, chatID, bytes.NewReader(data), filename); err != nil {
+		logger.Error("Failed to send CSV document", "error", err)
+		return b.sendMessage(ctx, chatID, tr(ctx, "file.send_failed"))
+	}
+	b.attendanceService.Audit(ctx, models.AuditEntry{
+		ActorID: actorID,
+		Action:  models.AuditReportDownloaded,
+		Details: fmt.Sprintf("missing_checkouts %s..%s csv", startDate, endDate),
+	})
+
+	return nil
+}
+
+// isAdminChat reports whether the chat is the configured admin chat
+func (b *Bot) isAdminChat(chatID int64) bool {
+	return b.config.AdminChatID != 0 && chatID == b.config.AdminChatID
+}
+
+// isKioskChat reports whether the chat is the configured office kiosk
+func (b *Bot) isKioskChat(chatID int64) bool {
+	return b.config.KioskChatID != 0 && chatID == b.config.KioskChatID
+}
+
+// handleTextMessage handles non-command text messages
+func (b *Bot) handleTextMessage(ctx context.Context, msg *Message) error {
+	// Continue the user's multi-step conversation, if any
+	if ok, err := b.continueConversation(ctx, msg.From.ID, msg); ok {
+		return err
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.send_otp", "Digits", b.attendanceService.OTPDigits()))
+}
+
+// formatHistoryMessage formats attendance history into a readable message, marking late check-ins
+// and early check-outs by the user's shift, with times in location, the timezone the user works in
+func (b *Bot) formatHistoryMessage(ctx context.Context, title string, records []models.AttendanceRecord, shift models.Shift, location *time.Location) string {
+	// Lateness is judged in the timezone the times are shown in
+	shift.Location = location
+
+	var message strings.Builder
+	message.WriteString(title + "\n\n")
+
+	// Group by date, newest first
+	days := models.GroupByDay(records)
+	models.SortDaysNewestFirst(days)
+
+	lateDays, earlyDays := 0, 0
+	for i, day := range days {
+		// Parse and format date, showing it as stored if it cannot be parsed so numbering stays continuous
+		displayDate := day.Date
+		if dateTime, err := utils.ParseDate(day.Date); err == nil {
+			displayDate = utils.FormatDateIn(dateTime, "dd MMMM yyyy", i18n.FromContext(ctx))
 		}
-		displayDate := utils.FormatDate(dateTime, "dd MMMM yyyy")
 
-		message.WriteString(fmt.Sprintf("%d. *%s*\n", len(dates)-i, displayDate))
+		message.WriteString(fmt.Sprintf("%d. *%s*\n", i+1, displayDate))
 
-		if checkIn := dayRecord["check_in"]; checkIn != nil {
-			checkInTime := utils.FormatTime(checkIn.Timestamp, "HH:mm")
+		if checkIn := day.CheckIn; checkIn != nil {
+			checkInTime := utils.FormatTimeIn(checkIn.Timestamp, "HH:mm", location)
 			status := " 🟢"
-			if checkIn.Timestamp.Hour() >= 9 {
+			if utils.IsLate(checkIn.Timestamp, shift) {
 				status = " ⚠️"
+				lateDays++
 			}
-			message.WriteString(fmt.Sprintf("   ⏰ Masuk: %s%s\n", checkInTime, status))
+			message.WriteString(tr(ctx, "history.check_in", "Time", checkInTime+status) + "\n")
 		} else {
-			message.WriteString("   ⏰ Masuk: -\n")
+			message.WriteString(tr(ctx, "history.check_in", "Time", "-") + "\n")
 		}
 
-		if checkOut := dayRecord["check_out"]; checkOut != nil {
-			checkOutTime := utils.FormatTime(checkOut.Timestamp, "HH:mm")
-			message.WriteString(fmt.Sprintf("   🏠 Pulang: %s\n", checkOutTime))
+		if checkOut := day.CheckOut; checkOut != nil {
+			checkOutTime := utils.FormatTimeIn(checkOut.Timestamp, "HH:mm", location)
+			status := ""
+			if day.CheckIn != nil && utils.LeftEarly(day.CheckIn.Timestamp, checkOut.Timestamp, shift) {
+				status = " ⏪"
+				earlyDays++
+			}
+			message.WriteString(tr(ctx, "history.check_out", "Time", checkOutTime+status) + "\n")
 		} else {
-			message.WriteString("   🏠 Pulang: -\n")
+			message.WriteString(tr(ctx, "history.check_out", "Time", "-") + "\n")
 		}
 
 		message.WriteString("\n")
 	}
 
-	uniqueDays := len(dates)
-	totalRecords := len(records)
+	uniqueDays := len(days)
+	totalRecords := len(models.WithoutBreaks(records))
 
-	message.WriteString("*Ringkasan:*\n")
-	message.WriteString(fmt.Sprintf("📊 Total Hari: %d\n", uniqueDays))
-	message.WriteString(fmt.Sprintf("📝 Total Absensi: %d", totalRecords))
+	message.WriteString(tr(ctx, "history.summary", "Days", uniqueDays, "Records", totalRecords, "Late", lateDays) + "\n")
+	if shift.End != "" {
+		message.WriteString(tr(ctx, "history.left_early", "Days", earlyDays) + "\n")
+	}
+	message.WriteString(tr(ctx, "history.shift", "Shift", describeShift(ctx, shift)))
 
 	return message.String()
 }
 
 // handleFullReportInput processes user input for full report generation
-func (b *Bot) handleFullReportInput(msg *Message) error {
-	// Clear the session state
-	delete(b.sessions, msg.From.ID)
-
-	text := strings.TrimSpace(msg.Text)
-
-	// Validate password and date range format
-	dateRangeRegex := regexp.MustCompile(`^(\S+)\s+(\d{4}-\d{2}-\d{2})\s+(\d{4}-\d{2}-\d{2})$`)
-	matches := dateRangeRegex.FindStringSubmatch(text)
-
-	if len(matches) != 4 {
-		return b.sendMessage(msg.Chat.ID, "❌ Format input tidak valid. Gunakan format: [password] YYYY-MM-DD YYYY-MM-DD\n\nContoh: admin123 2025-01-01 2025-01-31")
+func (b *Bot) handleFullReportInput(ctx context.Context, msg *Message, session *Session) error {
+	// The question stays open until a range in the right format is entered
+	fields := strings.Fields(msg.Text)
+	if len(fields) != 2 {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "fullreport.invalid_input"))
 	}
+	b.sessions.Clear(ctx, msg.From.ID)
 
-	password := matches[1]
-	startDate := matches[2]
-	endDate := matches[3]
-
-	// Check password
-	if password != b.config.AdminPassword {
-		return b.sendMessage(msg.Chat.ID, "❌ Password admin salah. Akses ditolak.")
-	}
+	return b.sendFullReport(ctx, msg.Chat.ID, msg.From.ID, fields[0], fields[1], "", "")
+}
 
-	// Validate dates
+// sendFullReport validates a date range and sends its report in format to actorID, asking for the
+// format with buttons when it is empty. A department, which needs a format, limits the report to
+// its users.
+func (b *Bot) sendFullReport(ctx context.Context, chatID, actorID int64, startDate, endDate, format, department string) error {
 	start, err := time.Parse("2006-01-02", startDate)
 	if err != nil {
-		return b.sendMessage(msg.Chat.ID, "❌ Tanggal mulai tidak valid. Pastikan format tanggal benar (YYYY-MM-DD).")
+		return b.sendMessage(ctx, chatID, tr(ctx, "fullreport.invalid_start"))
 	}
 
 	end, err := time.Parse("2006-01-02", endDate)
 	if err != nil {
-		return b.sendMessage(msg.Chat.ID, "❌ Tanggal akhir tidak valid. Pastikan format tanggal benar (YYYY-MM-DD).")
+		return b.sendMessage(ctx, chatID, tr(ctx, "fullreport.invalid_end"))
 	}
 
 	if start.After(end) {
-		return b.sendMessage(msg.Chat.ID, "❌ Tanggal mulai tidak boleh lebih besar dari tanggal akhir.")
+		return b.sendMessage(ctx, chatID, tr(ctx, "fullreport.start_after_end"))
 	}
 
-	// Generate and send CSV report
-	if err := b.sendMessage(msg.Chat.ID, "⏳ Membuat laporan CSV... Mohon tunggu."); err != nil {
+	if format == "" {
+		keyboard := &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{}}}
+		for _, f := range fullReportFormats {
+			keyboard.InlineKeyboard[0] = append(keyboard.InlineKeyboard[0], InlineKeyboardButton{
+				Text:         f.label,
+				CallbackData: fmt.Sprintf("fullreport:%s:%s:%s", f.key, startDate, endDate),
+			})
+		}
+		return b.api.SendMessageWithOptions(ctx, chatID, tr(ctx, "fullreport.pick_format", "Start", startDate, "End", endDate),
+			&SendMessageOptions{ReplyMarkup: keyboard})
+	}
+	if !isFullReportFormat(format) {
+		return b.sendMessage(ctx, chatID, tr(ctx, "fullreport.unknown_format"))
+	}
+
+	if err := b.sendMessage(ctx, chatID, tr(ctx, "fullreport.creating", "Format", formatName(format))); err != nil {
 		return err
 	}
 
-	return b.generateAndSendCSVReport(msg.Chat.ID, startDate, endDate)
+	return b.generateAndSendReport(ctx, chatID, actorID, startDate, endDate, format, department)
+}
+
+// formatName returns the name of a report format shown to users
+func formatName(format string) string {
+	if format == "xlsx" {
+		return "Excel"
+	}
+	return strings.ToUpper(format)
 }
 
-// generateAndSendCSVReport generates a CSV report and sends it as a document
-func (b *Bot) generateAndSendCSVReport(chatID int64, startDate, endDate string) error {
+// generateAndSendReport generates a CSV or XLSX report, of everyone or of a department, and sends
+// it as a document requested by actorID. The PDF format is the summary for sign-off instead.
+func (b *Bot) generateAndSendReport(ctx context.Context, chatID, actorID int64, startDate, endDate, format, department string) error {
+	if format == "pdf" {
+		return b.generateAndSendSummary(ctx, chatID, actorID, startDate, endDate, department)
+	}
+	logger := logging.FromContext(ctx)
+
 	// Get attendance records for the date range
-	records, err := b.attendanceService.GetAttendanceReportRange(startDate, endDate)
+	records, err := b.attendanceService.GetAttendanceReportRange(ctx, startDate, endDate)
+	if err != nil {
+		return b.replyError(ctx, chatID, err, "action.get_attendance", "Failed to get attendance records")
+	}
+	leave, err := b.attendanceService.GetLeaveDays(ctx, startDate, endDate)
 	if err != nil {
-		b.logger.Error("Failed to get attendance records", "error", err)
-		return b.sendMessage(chatID, "❌ Terjadi kesalahan saat mengambil data absensi.")
+		return b.replyError(ctx, chatID, err, "action.get_leave", "Failed to get leave days")
+	}
+	if department != "" {
+		records, leave = reports.FilterDepartment(records, leave, department)
 	}
 
-	if len(records) == 0 {
-		return b.sendMessage(chatID, "📭 Tidak ada data absensi dalam rentang tanggal yang ditentukan.")
+	if len(records) == 0 && len(leave) == 0 {
+		return b.sendMessage(ctx, chatID, tr(ctx, "fullreport.empty"))
 	}
 
-	// Generate CSV file
-	filePath, err := b.csvGenerator.GenerateAttendanceReport(records, startDate, endDate)
+	// Generate the report, a CSV in memory and a workbook as a file
+	start := time.Now()
+	var filePath string
+	var data []byte
+	if format == "xlsx" {
+		var shifts *models.ShiftAssignments
+		if shifts, err = b.attendanceService.GetShiftAssignments(ctx); err == nil {
+			filePath, err = b.xlsxGenerator.GenerateAttendanceReport(records, leave, shifts, startDate, endDate)
+		}
+	} else {
+		data, err = b.csvGenerator.GenerateAttendanceReport(records, leave)
+	}
+	metrics.ReportDuration.ObserveSince(start, format)
 	if err != nil {
-		b.logger.Error("Failed to generate CSV report", "error", err)
-		return b.sendMessage(chatID, "❌ Terjadi kesalahan saat membuat laporan CSV.")
+		logger.Error("Failed to generate report", "format", format, "error", err)
+		return b.sendMessage(ctx, chatID, tr(ctx, "file.create_failed", "Format", formatName(format)))
 	}
 
-	// Send CSV file
-	file, err := os.Open(filePath)
+	caption := tr(ctx, "fullreport.caption", "Start", startDate, "End", endDate, "Records", len(records))
+	if len(leave) > 0 {
+		caption += "\n" + tr(ctx, "fullreport.caption_leave", "Days", len(leave))
+	}
+	if department != "" {
+		caption += "\n" + tr(ctx, "fullreport.caption_department", "Department", department)
+	}
+
+	filename := fmt.Sprintf("attendance_%s_to_%s.%s", startDate, endDate, format)
+	details := strings.TrimSpace(fmt.Sprintf("attendance %s..%s %s %s", startDate, endDate, format, department))
+	if filePath == "" {
+		return b.sendReport(ctx, chatID, actorID, bytes.NewReader(data), filename, caption, details)
+	}
+	defer os.Remove(filePath)
+	return b.sendReportFile(ctx, chatID, actorID, filePath, filename, caption, details)
+}
+
+// generateAndSendSummary generates the PDF summary of a period, of everyone or of a department,
+// with one row per employee and signature lines for HR sign-off, and sends it to actorID
+func (b *Bot) generateAndSendSummary(ctx context.Context, chatID, actorID int64, startDate, endDate, department string) error {
+	summary, err := b.attendanceService.GetPeriodSummary(ctx, startDate, endDate, department)
 	if err != nil {
-		b.logger.Error("Failed to open CSV file", "error", err)
-		return b.sendMessage(chatID, "❌ Terjadi kesalahan saat membuka file laporan.")
+		return b.replyError(ctx, chatID, err, "action.get_summary", "Failed to get attendance summary")
+	}
+	if len(summary.Users) == 0 {
+		return b.sendMessage(ctx, chatID, tr(ctx, "fullreport.empty"))
+	}
+
+	start := time.Now()
+	filePath, err := b.pdfGenerator.GenerateSummaryReport(summary, i18n.FromContext(ctx))
+	metrics.ReportDuration.ObserveSince(start, "pdf")
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to generate report", "format", "pdf", "error", err)
+		return b.sendMessage(ctx, chatID, tr(ctx, "file.create_failed", "Format", formatName("pdf")))
+	}
+	defer os.Remove(filePath)
+
+	caption := tr(ctx, "fullreport.caption_summary", "Start", startDate, "End", endDate, "Employees", len(summary.Users))
+	if department != "" {
+		caption += "\n" + tr(ctx, "fullreport.caption_department", "Department", department)
 	}
-	defer file.Close()
 
-	filename := fmt.Sprintf("attendance_%s_to_%s.csv", startDate, endDate)
+	filename := fmt.Sprintf("attendance_summary_%s_to_%s.pdf", startDate, endDate)
+	return b.sendReportFile(ctx, chatID, actorID, filePath, filename, caption,
+		strings.TrimSpace(fmt.Sprintf("summary %s..%s pdf %s", startDate, endDate, department)))
+}
 
-	// Send the file
-	if err := b.api.SendDocument(chatID, file, filename); err != nil {
-		b.logger.Error("Failed to send CSV document", "error", err)
-		return b.sendMessage(chatID, "❌ Terjadi kesalahan saat mengirim laporan.")
+// sendReportFile sends a report generated as a file, as sendReport does
+func (b *Bot) sendReportFile(ctx context.Context, chatID, actorID int64, filePath, filename, caption, details string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to open report file", "error", err)
+		return b.sendMessage(ctx, chatID, tr(ctx, "file.open_failed"))
 	}
+	defer file.Close()
 
-	// Send confirmation message with statistics
-	caption := fmt.Sprintf("📊 *Laporan Absensi*\n\n📅 Periode: %s s/d %s\n📈 Total Records: %d",
-		startDate, endDate, len(records))
+	return b.sendReport(ctx, chatID, actorID, file, filename, caption, details)
+}
 
-	// Clean up temp file
-	if err := os.Remove(filePath); err != nil {
-		b.logger.Warn("Failed to clean up temp file", "file", filePath, "error", err)
+// sendReport sends a generated report as a document with its statistics as the caption,
+// auditing the download with details
+func (b *Bot) sendReport(ctx context.Context, chatID, actorID int64, document io.Reader, filename, caption, details string) error {
+	if err := b.api.SendDocumentWithOptions(ctx, chatID, document, filename, &SendDocumentOptions{Caption: caption, ParseMode: "Markdown"}); err != nil {
+		logging.FromContext(ctx).Error("Failed to send report document", "filename", filename, "error", err)
+		return b.sendMessage(ctx, chatID, tr(ctx, "file.send_failed"))
 	}
+	b.attendanceService.Audit(ctx, models.AuditEntry{
+		ActorID: actorID,
+		Action:  models.AuditReportDownloaded,
+		Details: details,
+	})
 
-	return b.sendMarkdownMessage(chatID, caption)
+	return nil
 }
 
 // sendMessage sends a plain text message
-func (b *Bot) sendMessage(chatID int64, text string) error {
-	return b.api.SendMessage(chatID, text)
+func (b *Bot) sendMessage(ctx context.Context, chatID int64, text string) error {
+	return b.api.SendMessage(ctx, chatID, text)
 }
 
 // sendMarkdownMessage sends a message with Markdown formatting
-func (b *Bot) sendMarkdownMessage(chatID int64, text string) error {
+func (b *Bot) sendMarkdownMessage(ctx context.Context, chatID int64, text string) error {
 	options := &SendMessageOptions{
 		ParseMode: "Markdown",
 	}
-	return b.api.SendMessageWithOptions(chatID, text, options)
+	return b.api.SendMessageWithOptions(ctx, chatID, text, options)
+}
+
+// markdownEscaper escapes the characters with a meaning in Telegram's legacy Markdown
+var markdownEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+
+// escapeMarkdown escapes user-provided text for a Markdown message
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
 }
@@ -0,0 +1,360 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/config"
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/reports"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Identities used by the bot tests
+const (
+	testSecret      = "JBSWY3DPEHPK3PXP"
+	testAdminID     = 900
+	testAdminChatID = -1000
+)
+
+// telegramCall is a Bot API request received by fakeTelegram
+type telegramCall struct {
+	Method   string
+	Payload  map[string]any    // Body of a JSON request
+	Fields   map[string]string // Fields of a multipart request
+	File     []byte            // File of a multipart request
+	Filename string
+}
+
+// text returns the message text of a call, or its caption
+func (c telegramCall) text() string {
+	if text, ok := c.Payload["text"].(string); ok {
+		return text
+	}
+	if caption, ok := c.Payload["caption"].(string); ok {
+		return caption
+	}
+	return c.Fields["caption"]
+}
+
+// chatID returns the chat a call is sent to
+func (c telegramCall) chatID() int64 {
+	if id, ok := c.Payload["chat_id"].(float64); ok {
+		return int64(id)
+	}
+	var id int64
+	fmt.Sscan(c.Fields["chat_id"], &id)
+	return id
+}
+
+// fakeTelegram is a Bot API server recording the requests it gets. Failures queued for a method
+// are answered first, in order. getUpdates returns the pushed updates from the requested offset.
+type fakeTelegram struct {
+	server    *httptest.Server
+	mu        sync.Mutex
+	calls     []telegramCall
+	failures  map[string][]int
+	attempts  map[string]int
+	holds     map[string]chan struct{}
+	updates   []Update
+	polled    atomic.Int64 // Offset of the latest getUpdates request
+	messageID atomic.Int64
+}
+
+// newFakeTelegram starts a fake Bot API server, closed when the test ends
+func newFakeTelegram(t *testing.T) *fakeTelegram {
+	t.Helper()
+
+	f := &fakeTelegram{failures: make(map[string][]int), attempts: make(map[string]int), holds: make(map[string]chan struct{})}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// fail queues HTTP error statuses answered to the next requests of method
+func (f *fakeTelegram) fail(method string, statuses ...int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[method] = append(f.failures[method], statuses...)
+}
+
+// hold keeps requests of method waiting for their response until the returned release is called,
+// which the test's cleanup also does
+func (f *fakeTelegram) hold(t *testing.T, method string) (release func()) {
+	t.Helper()
+
+	gate := make(chan struct{})
+	f.mu.Lock()
+	f.holds[method] = gate
+	f.mu.Unlock()
+
+	var once sync.Once
+	release = func() { once.Do(func() { close(gate) }) }
+	t.Cleanup(release)
+	return release
+}
+
+// push queues updates for getUpdates
+func (f *fakeTelegram) push(updates ...Update) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, updates...)
+}
+
+// pendingUpdates returns the pushed updates from offset on, waiting briefly for some to arrive
+// like a long poll would
+func (f *fakeTelegram) pendingUpdates(r *http.Request) []Update {
+	offset, _ := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	f.polled.Store(offset)
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for {
+		f.mu.Lock()
+		var pending []Update
+		for _, update := range f.updates {
+			if update.UpdateID >= offset {
+				pending = append(pending, update)
+			}
+		}
+		f.mu.Unlock()
+
+		if len(pending) > 0 || time.Now().After(deadline) || r.Context().Err() != nil {
+			return pending
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// serve answers a Bot API request
+func (f *fakeTelegram) serve(w http.ResponseWriter, r *http.Request) {
+	method := path.Base(r.URL.Path)
+	call := telegramCall{Method: method}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		call.Fields = make(map[string]string)
+		for name, values := range r.MultipartForm.Value {
+			call.Fields[name] = values[0]
+		}
+		for _, headers := range r.MultipartForm.File {
+			file, err := headers[0].Open()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			call.File, _ = io.ReadAll(file)
+			call.Filename = headers[0].Filename
+			file.Close()
+		}
+	} else if r.Body != nil {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &call.Payload)
+	}
+
+	f.mu.Lock()
+	f.attempts[method]++
+	if queued := f.failures[method]; len(queued) > 0 {
+		status := queued[0]
+		f.failures[method] = queued[1:]
+		f.mu.Unlock()
+		writeTelegramError(w, status)
+		return
+	}
+	f.calls = append(f.calls, call)
+	gate := f.holds[method]
+	f.mu.Unlock()
+
+	if gate != nil {
+		select {
+		case <-gate:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	var result any = true
+	switch method {
+	case "getMe":
+		result = User{ID: 1, IsBot: true, FirstName: "Attendance", Username: "attendance_test_bot"}
+	case "sendMessage", "sendDocument", "sendPhoto":
+		result = Message{MessageID: f.messageID.Add(1), Chat: &Chat{ID: call.chatID()}, Text: call.text()}
+	case "getUpdates":
+		result = f.pendingUpdates(r)
+	}
+	json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
+
+// writeTelegramError answers with a Bot API error; 429 asks to retry after a second
+func writeTelegramError(w http.ResponseWriter, status int) {
+	response := map[string]any{"ok": false, "error_code": status, "description": http.StatusText(status)}
+	if status == http.StatusTooManyRequests {
+		response["description"] = "Too Many Requests: retry after 1"
+		response["parameters"] = map[string]any{"retry_after": 1}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// sent returns the successful calls of method, in order
+func (f *fakeTelegram) sent(method string) []telegramCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var calls []telegramCall
+	for _, call := range f.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// attemptsOf returns how many requests of method arrived, failed ones included
+func (f *fakeTelegram) attemptsOf(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attempts[method]
+}
+
+// messagesTo returns the texts of the messages and captions of files sent to a chat, in order
+func (f *fakeTelegram) messagesTo(chatID int64) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var texts []string
+	for _, call := range f.calls {
+		if call.chatID() == chatID && call.text() != "" {
+			texts = append(texts, call.text())
+		}
+	}
+	return texts
+}
+
+// lastMessageTo returns the text of the last message sent to a chat, failing the test if there is none
+func (f *fakeTelegram) lastMessageTo(t *testing.T, chatID int64) string {
+	t.Helper()
+
+	texts := f.messagesTo(chatID)
+	if len(texts) == 0 {
+		t.Fatalf("no message sent to chat %d", chatID)
+	}
+	return texts[len(texts)-1]
+}
+
+// testBot is a bot talking to a fake Telegram, over a fresh SQLite database
+type testBot struct {
+	*Bot
+	telegram *fakeTelegram
+	service  *attendance.Service
+	repo     database.Repository
+	updates  atomic.Int64
+}
+
+// newTestBot creates a bot with an admin and an admin chat; configure may adjust its configuration
+func newTestBot(t *testing.T, configure ...func(cfg *config.Config)) *testBot {
+	t.Helper()
+
+	dir := t.TempDir()
+	db, err := database.NewSQLiteDB(filepath.Join(dir, "attendance.db"))
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	repo := database.NewRepository(db)
+	service := attendance.NewService(repo, attendance.NewTOTPService(testSecret))
+
+	telegram := newFakeTelegram(t)
+	cfg := &config.Config{
+		BotToken:       "test-token",
+		TOTPSecret:     testSecret,
+		SuperAdminID:   testAdminID,
+		AdminChatID:    testAdminChatID,
+		TelegramAPIURL: telegram.server.URL,
+	}
+	for _, apply := range configure {
+		apply(cfg)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	b := NewBot(cfg.BotToken, service, reports.NewCSVGenerator(), reports.NewXLSXGenerator(dir), reports.NewPDFGenerator(dir), cfg, logger)
+	return &testBot{Bot: b, telegram: telegram, service: service, repo: repo}
+}
+
+// send delivers a text message from a user in their private chat and waits until it is handled
+func (tb *testBot) send(t *testing.T, userID int64, text string) {
+	t.Helper()
+	tb.deliver(t, &Message{
+		From: &User{ID: userID, FirstName: fmt.Sprintf("User%d", userID)},
+		Chat: &Chat{ID: userID, Type: "private"},
+		Text: text,
+	})
+}
+
+// deliver hands a message to the bot as a new update, filling in its ID and date
+func (tb *testBot) deliver(t *testing.T, msg *Message) {
+	t.Helper()
+
+	id := tb.updates.Add(1)
+	msg.MessageID = id
+	if msg.Date == 0 {
+		msg.Date = time.Now().Unix()
+	}
+	if err := tb.handleUpdate(context.Background(), &Update{UpdateID: id, Message: msg}); err != nil {
+		t.Fatalf("handleUpdate(%q): %v", msg.Text, err)
+	}
+}
+
+// press delivers a press of an inline keyboard button under a message the bot sent to chatID
+func (tb *testBot) press(t *testing.T, userID, chatID int64, data string) {
+	t.Helper()
+
+	id := tb.updates.Add(1)
+	query := &CallbackQuery{
+		ID:      fmt.Sprint(id),
+		From:    &User{ID: userID, FirstName: fmt.Sprintf("User%d", userID)},
+		Message: &Message{MessageID: id, Chat: &Chat{ID: chatID, Type: "private"}},
+		Data:    data,
+	}
+	if err := tb.handleUpdate(context.Background(), &Update{UpdateID: id, CallbackQuery: query}); err != nil {
+		t.Fatalf("handleUpdate(callback %q): %v", data, err)
+	}
+}
+
+// waitFor polls condition until it holds, failing the test after a few seconds
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// message returns an update with a text message from a user in their private chat
+func message(updateID, userID int64, text string) Update {
+	return Update{UpdateID: updateID, Message: &Message{
+		MessageID: updateID,
+		From:      &User{ID: userID, FirstName: fmt.Sprintf("User%d", userID)},
+		Chat:      &Chat{ID: userID, Type: "private"},
+		Date:      time.Now().Unix(),
+		Text:      text,
+	}}
+}
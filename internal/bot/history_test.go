@@ -0,0 +1,185 @@
+package bot
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryShownInUserTimezone(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	const userID = 1001
+
+	if _, err := tb.service.SetUserTimezone(ctx, userID, "Asia/Tokyo"); err != nil {
+		t.Fatalf("SetUserTimezone: %v", err)
+	}
+
+	// 08:30 and 17:05 in Tokyo, stored in the bot's timezone
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	day := time.Now().In(tokyo).AddDate(0, 0, -1)
+	checkIn := time.Date(day.Year(), day.Month(), day.Day(), 8, 30, 0, 0, tokyo)
+	checkOut := time.Date(day.Year(), day.Month(), day.Day(), 17, 5, 0, 0, tokyo)
+	date := checkIn.Format("2006-01-02")
+	if _, _, err := tb.repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{
+		{UserID: userID, FirstName: "Budi", Timestamp: checkIn.In(utils.Location), Type: "check_in", Date: date},
+		{UserID: userID, FirstName: "Budi", Timestamp: checkOut.In(utils.Location), Type: "check_out", Date: date},
+	}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+
+	tb.send(t, userID, "/history")
+	history := tb.telegram.lastMessageTo(t, userID)
+	if !strings.Contains(history, "08:30") || !strings.Contains(history, "17:05") {
+		t.Errorf("history does not show the times in Asia/Tokyo:\n%s", history)
+	}
+	// The built-in shift starts at 09:00 in the user's timezone, so 08:30 is on time
+	if !strings.Contains(history, "08:30 🟢") {
+		t.Errorf("check-in at 08:30 Tokyo time marked late:\n%s", history)
+	}
+
+	tb.press(t, userID, userID, "history:1001:csv")
+	documents := tb.telegram.sent("sendDocument")
+	if len(documents) != 1 {
+		t.Fatalf("%d documents sent, want the history CSV", len(documents))
+	}
+	if csv := string(documents[0].File); !strings.Contains(csv, "08:30:00") || !strings.Contains(csv, "17:05:00") {
+		t.Errorf("history CSV does not show the times in Asia/Tokyo:\n%s", csv)
+	}
+}
+
+func TestHistoryCountsOnlyCheckInsAndCheckOuts(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	const userID = 1002
+
+	day := time.Now().In(utils.Location).AddDate(0, 0, -1)
+	at := func(hour, minute int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, utils.Location)
+	}
+	date := day.Format("2006-01-02")
+	if _, _, err := tb.repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{
+		{UserID: userID, FirstName: "Sari", Timestamp: at(8, 50), Type: "check_in", Date: date},
+		{UserID: userID, FirstName: "Sari", Timestamp: at(12, 0), Type: "break_start", Date: date},
+		{UserID: userID, FirstName: "Sari", Timestamp: at(12, 45), Type: "break_end", Date: date},
+		{UserID: userID, FirstName: "Sari", Timestamp: at(17, 10), Type: "check_out", Date: date},
+	}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+
+	tb.send(t, userID, "/history")
+	history := tb.telegram.lastMessageTo(t, userID)
+	if !strings.Contains(history, "Total Absensi: 2\n") {
+		t.Errorf("history counts break records as entries:\n%s", history)
+	}
+}
+
+// TestHistoryShowsEachDaysTimes stores several days of records, interleaved, and checks that the
+// history and its CSV show each day with its own times
+func TestHistoryShowsEachDaysTimes(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	const userID = 1003
+
+	today := time.Now().In(utils.Location)
+	dayAt := func(daysAgo, hour, minute int) (time.Time, string) {
+		day := today.AddDate(0, 0, -daysAgo)
+		at := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, utils.Location)
+		return at, at.Format("2006-01-02")
+	}
+	record := func(daysAgo, hour, minute int, recordType string) models.AttendanceRecord {
+		at, date := dayAt(daysAgo, hour, minute)
+		return models.AttendanceRecord{UserID: userID, FirstName: "Dewi", Timestamp: at, Type: recordType, Date: date}
+	}
+	if _, _, err := tb.repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{
+		record(3, 8, 1, "check_in"),
+		record(2, 8, 2, "check_in"),
+		record(3, 12, 0, "break_start"),
+		record(1, 8, 3, "check_in"),
+		record(3, 17, 1, "check_out"),
+		record(1, 17, 3, "check_out"),
+		record(2, 17, 2, "check_out"),
+	}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+
+	tb.send(t, userID, "/history")
+	history := tb.telegram.lastMessageTo(t, userID)
+	var times []string
+	for _, line := range strings.Split(history, "\n") {
+		if strings.Contains(line, "Masuk:") || strings.Contains(line, "Pulang:") {
+			times = append(times, strings.Fields(strings.SplitN(line, ":", 2)[1])[0])
+		}
+	}
+	if want := []string{"08:03", "17:03", "08:02", "17:02", "08:01", "17:01"}; strings.Join(times, " ") != strings.Join(want, " ") {
+		t.Errorf("history times newest first = %v, want %v\n%s", times, want, history)
+	}
+
+	tb.press(t, userID, userID, "history:1003:csv")
+	documents := tb.telegram.sent("sendDocument")
+	if len(documents) != 1 {
+		t.Fatalf("%d documents sent, want the history CSV", len(documents))
+	}
+	rows := strings.Split(strings.TrimSpace(string(documents[0].File)), "\n")
+	if len(rows) != 4 {
+		t.Fatalf("history CSV has %d rows, want a header and 3 days:\n%s", len(rows), documents[0].File)
+	}
+	for i, daysAgo := range []int{1, 2, 3} {
+		_, date := dayAt(daysAgo, 0, 0)
+		checkIn, checkOut := fmt.Sprintf("08:0%d:00", 4-daysAgo), fmt.Sprintf("17:0%d:00", 4-daysAgo)
+		if row := rows[i+1]; !strings.Contains(row, date) || !strings.Contains(row, checkIn) || !strings.Contains(row, checkOut) {
+			t.Errorf("CSV row %d = %q, want %s with %s and %s", i+1, row, date, checkIn, checkOut)
+		}
+	}
+}
+
+// TestFormatHistoryOrdersInterleavedRecords formats records whose timestamps interleave across
+// dates, including a night shift checking out on the next day: days are listed newest first,
+// numbered 1..N in that order, each with its own times
+func TestFormatHistoryOrdersInterleavedRecords(t *testing.T) {
+	tb := newTestBot(t)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, utils.Location)
+	}
+	record := func(date string, timestamp time.Time, recordType string) models.AttendanceRecord {
+		return models.AttendanceRecord{UserID: 1, FirstName: "Sari", Date: date, Timestamp: timestamp, Type: recordType}
+	}
+	records := []models.AttendanceRecord{
+		record("2024-03-05", at(5, 17, 5), "check_out"),
+		record("2024-03-04", at(4, 8, 10), "check_in"),
+		record("2024-03-06", at(6, 21, 0), "check_in"),
+		record("2024-03-04", at(4, 16, 40), "check_out"),
+		record("2024-03-06", at(7, 5, 30), "check_out"),
+		record("2024-03-05", at(5, 9, 20), "check_in"),
+	}
+
+	history := tb.formatHistoryMessage(context.Background(), "Riwayat", records, models.BuiltinShift, utils.Location)
+
+	want := []string{
+		"1. *06 Maret 2024*",
+		"   ⏰ Masuk: 21:00 ⚠️",
+		"   🏠 Pulang: 05:30",
+		"2. *05 Maret 2024*",
+		"   ⏰ Masuk: 09:20 ⚠️",
+		"   🏠 Pulang: 17:05",
+		"3. *04 Maret 2024*",
+		"   ⏰ Masuk: 08:10 🟢",
+		"   🏠 Pulang: 16:40",
+	}
+	var got []string
+	for _, line := range strings.Split(history, "\n") {
+		if strings.Contains(line, "Masuk:") || strings.Contains(line, "Pulang:") || strings.Contains(line, ". *") {
+			got = append(got, line)
+		}
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("history days =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !strings.Contains(history, "Total Absensi: 6\n") {
+		t.Errorf("history summary does not count 6 records:\n%s", history)
+	}
+}
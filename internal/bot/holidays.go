@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/reports"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// holidayLookupTimeout bounds the database lookup of the scheduler's holiday calendar
+const holidayLookupTimeout = 5 * time.Second
+
+// handleHoliday handles the /holiday command, with which admins keep the holidays that are not
+// working days
+func (b *Bot) handleHoliday(ctx context.Context, msg *Message, args []string) error {
+	if len(args) == 0 {
+		return b.handleHolidayList(ctx, msg, utils.Now().Year())
+	}
+
+	switch {
+	case args[0] == "list" && len(args) <= 2:
+		year, ok := parseHolidayYear(args[1:])
+		if !ok {
+			return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "holiday.usage"))
+		}
+		return b.handleHolidayList(ctx, msg, year)
+	case args[0] == "add" && len(args) >= 3:
+		return b.handleHolidayAdd(ctx, msg, args[1], strings.Join(args[2:], " "))
+	case args[0] == "remove" && len(args) == 2:
+		return b.handleHolidayRemove(ctx, msg, args[1])
+	case args[0] == "import" && len(args) <= 2:
+		year, ok := parseHolidayYear(args[1:])
+		if !ok {
+			return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "holiday.usage"))
+		}
+		return b.handleHolidayImport(ctx, msg, year)
+	default:
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "holiday.usage"))
+	}
+}
+
+// parseHolidayYear reads an optional year argument, the current year by default
+func parseHolidayYear(args []string) (int, bool) {
+	if len(args) == 0 {
+		return utils.Now().Year(), true
+	}
+	year, err := strconv.Atoi(args[0])
+	return year, err == nil && year >= 2000 && year <= 2100
+}
+
+// handleHolidayList lists the holidays of a year
+func (b *Bot) handleHolidayList(ctx context.Context, msg *Message, year int) error {
+	holidays, err := b.attendanceService.GetHolidays(ctx, strconv.Itoa(year)+"-01-01", strconv.Itoa(year)+"-12-31")
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_holidays", "Failed to get holidays", "year", year)
+	}
+
+	var message strings.Builder
+	message.WriteString(tr(ctx, "holiday.list_title", "Year", year) + "\n\n")
+	if len(holidays) == 0 {
+		message.WriteString(tr(ctx, "holiday.list_empty") + "\n")
+	}
+	for _, holiday := range holidays {
+		message.WriteString("• " + reports.FormatHoliday(holiday, i18n.FromContext(ctx)))
+		if holiday.Source != models.HolidaySourceAdmin {
+			message.WriteString(" " + tr(ctx, "holiday.source."+holiday.Source))
+		}
+		message.WriteString("\n")
+	}
+	message.WriteString("\n" + tr(ctx, "holiday.usage"))
+
+	return b.sendMessage(ctx, msg.Chat.ID, message.String())
+}
+
+// handleHolidayAdd adds a holiday, or renames the one on its date
+func (b *Bot) handleHolidayAdd(ctx context.Context, msg *Message, date, name string) error {
+	added, err := b.attendanceService.AddHoliday(ctx, date, name, msg.From.ID)
+	if errors.Is(err, attendance.ErrInvalidHoliday) {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "holiday.usage"))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_holiday", "Failed to save holiday", "date", date)
+	}
+
+	logging.FromContext(ctx).Warn("Holiday saved",
+		"audit", models.AuditHolidayAdded,
+		"date", date,
+		"new", added)
+
+	holidays, err := b.attendanceService.GetHolidays(ctx, date, date)
+	if err != nil || len(holidays) == 0 {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_holidays", "Failed to get holidays", "date", date)
+	}
+	key := "holiday.added"
+	if !added {
+		key = "holiday.renamed"
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, key, "Holiday", reports.FormatHoliday(holidays[0], i18n.FromContext(ctx))))
+}
+
+// handleHolidayRemove removes the holiday on a date
+func (b *Bot) handleHolidayRemove(ctx context.Context, msg *Message, date string) error {
+	removed, err := b.attendanceService.RemoveHoliday(ctx, date, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.delete_holiday", "Failed to delete holiday", "date", date)
+	}
+	if !removed {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "holiday.not_found", "Date", date))
+	}
+
+	logging.FromContext(ctx).Warn("Holiday removed",
+		"audit", models.AuditHolidayRemoved,
+		"date", date)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "holiday.removed", "Date", date))
+}
+
+// handleHolidayImport imports the national holidays of a year
+func (b *Bot) handleHolidayImport(ctx context.Context, msg *Message, year int) error {
+	added, listed, err := b.attendanceService.ImportHolidays(ctx, year, msg.From.ID)
+	if errors.Is(err, attendance.ErrHolidayImportDisabled) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "holiday.import_disabled"))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.import_holidays", "Failed to import holidays", "year", year)
+	}
+
+	logging.FromContext(ctx).Warn("Holidays imported",
+		"audit", models.AuditHolidaysImported,
+		"year", year,
+		"added", added)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "holiday.imported", "Year", year, "Added", added, "Skipped", listed-added))
+}
+
+// holidayCalendar tells the scheduler which days are holidays, those kept with /holiday and those
+// of the HOLIDAYS setting
+type holidayCalendar struct {
+	bot *Bot
+}
+
+// IsHoliday reports whether the day is a holiday. When the lookup fails, only the HOLIDAYS
+// setting is consulted.
+func (c holidayCalendar) IsHoliday(day time.Time) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), holidayLookupTimeout)
+	defer cancel()
+
+	holiday, err := c.bot.attendanceService.IsHoliday(ctx, day)
+	if err != nil {
+		c.bot.logger.Error("Failed to look up holiday", "date", day.Format("2006-01-02"), "error", err)
+		return c.bot.config.Holidays.IsHoliday(day)
+	}
+	return holiday
+}
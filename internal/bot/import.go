@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"attendance-bot/internal/importer"
+	"attendance-bot/internal/logging"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+)
+
+// maxImportFileSize bounds the CSV files /import downloads, in bytes
+const maxImportFileSize = 5 << 20
+
+// handleImport handles the /import command, with which admins load historical attendance from a
+// CSV file sent with the command as its caption, or replied to with the command
+func (b *Bot) handleImport(ctx context.Context, msg *Message, args []string) error {
+	dryRun := false
+	switch {
+	case len(args) == 0:
+	case len(args) == 1 && (strings.EqualFold(args[0], "dryrun") || strings.EqualFold(args[0], "dry-run")):
+		dryRun = true
+	default:
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "import.usage"))
+	}
+
+	document := msg.Document
+	if document == nil && msg.ReplyToMessage != nil {
+		document = msg.ReplyToMessage.Document
+	}
+	if document == nil {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "import.usage"))
+	}
+	if document.FileSize > maxImportFileSize {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "import.too_large", "MB", maxImportFileSize>>20))
+	}
+
+	if document.FileName == "" {
+		document.FileName = "import.csv"
+	}
+
+	file, err := b.api.GetFile(ctx, document.FileID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.import_attendance", "Failed to get import file", "file", document.FileName)
+	}
+	data, err := b.api.DownloadFile(ctx, file, maxImportFileSize)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.import_attendance", "Failed to download import file", "file", document.FileName)
+	}
+
+	result, err := b.attendanceService.ImportAttendance(ctx, bytes.NewReader(data), document.FileName, dryRun, msg.From.ID)
+	if errors.Is(err, importer.ErrInvalidFile) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "import.invalid_file", "Columns", strings.Join(importer.Columns, ",")))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.import_attendance", "Failed to import attendance", "file", document.FileName)
+	}
+
+	var summary string
+	if result.DryRun {
+		summary = tr(ctx, "import.dry_run",
+			"Rows", result.Rows, "Valid", result.Valid, "Resolved", result.Resolved, "Errors", result.Errors)
+	} else {
+		logging.FromContext(ctx).Warn("Attendance imported",
+			"audit", models.AuditAttendanceImported,
+			"file", document.FileName,
+			"rows", result.Rows,
+			"inserted", result.Inserted,
+			"duplicates", result.Duplicates,
+			"errors", result.Errors)
+		summary = tr(ctx, "import.done",
+			"Rows", result.Rows, "Inserted", result.Inserted, "Duplicates", result.Duplicates, "Errors", result.Errors)
+	}
+	if len(result.Rejects) == 0 {
+		return b.sendMessage(ctx, msg.Chat.ID, summary)
+	}
+
+	// The rejected rows come back as a CSV file the admin can correct and import again
+	var rejects bytes.Buffer
+	if err := importer.WriteRejects(&rejects, result.Rejects); err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.import_attendance", "Failed to write rejected rows")
+	}
+	filename := strings.TrimSuffix(document.FileName, ".csv") + ".rejects.csv"
+	return b.api.SendDocumentWithOptions(ctx, msg.Chat.ID, &rejects, filename, &SendDocumentOptions{Caption: summary + "\n\n" + tr(ctx, "import.rejects_caption")})
+}
@@ -0,0 +1,270 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kiosk roster layout and code entry limits
+const (
+	kioskPageSize    = 8  // Employees per roster page
+	kioskMaxAttempts = 3  // Wrong codes before the employee must be picked again
+	kioskSheetCodes  = 30 // Codes printed on a new personal code sheet
+)
+
+// kioskSelection is the employee picked on the kiosk, awaiting their code
+type kioskSelection struct {
+	Employee models.Employee
+	Attempts int // Wrong codes entered so far
+}
+
+// handleKiosk handles the /kiosk command, showing the roster on the kiosk
+func (b *Bot) handleKiosk(ctx context.Context, msg *Message) error {
+	if !b.isKioskChat(msg.Chat.ID) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "kiosk.chat_only"))
+	}
+	b.sessions.Clear(ctx, msg.Chat.ID)
+	return b.sendKioskRoster(ctx, msg.Chat.ID)
+}
+
+// handleKioskInput handles a text message on the kiosk: the picked employee's code, or
+// anything else, which shows the roster again
+func (b *Bot) handleKioskInput(ctx context.Context, msg *Message) error {
+	if ok, err := b.continueConversation(ctx, msg.Chat.ID, msg); ok {
+		return err
+	}
+	return b.sendKioskRoster(ctx, msg.Chat.ID)
+}
+
+// handleKioskCode verifies the code entered for the picked employee and records their attendance
+func (b *Bot) handleKioskCode(ctx context.Context, msg *Message, session *Session) error {
+	var selection kioskSelection
+	if err := session.Decode(&selection); err != nil || !b.isKioskChat(msg.Chat.ID) {
+		b.sessions.Clear(ctx, msg.Chat.ID)
+		return b.sendKioskRoster(ctx, msg.Chat.ID)
+	}
+
+	employee := selection.Employee
+	name := attendance.FullName(employee.FirstName, employee.LastName)
+	logger := logging.FromContext(ctx).With("kiosk_chat_id", msg.Chat.ID, "operator_id", msg.From.ID, "employee_id", employee.UserID)
+
+	if !utils.ValidateOTP(msg.Text, b.attendanceService.OTPDigits()) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "kiosk.enter_code", "Digits", b.attendanceService.OTPDigits(), "Name", name))
+	}
+
+	result, err := b.attendanceService.MarkKioskAttendance(ctx, employee, msg.Text)
+	if err != nil {
+		b.sessions.Clear(ctx, msg.Chat.ID)
+		if errors.Is(err, attendance.ErrNoPersonalCode) {
+			logger.Warn("Kiosk employee no longer enrolled")
+			return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "kiosk.not_enrolled", "Name", name))
+		}
+		return b.replyError(ctx, msg.Chat.ID, err, "action.process_attendance", "Failed to mark kiosk attendance")
+	}
+
+	if result.LockedOut || result.Lockout != nil {
+		b.sessions.Clear(ctx, msg.Chat.ID)
+		if result.Lockout != nil {
+			b.notifyOTPLockout(ctx, employee.UserID, employee.Username, result.Lockout)
+		}
+		if err := b.sendMessage(ctx, msg.Chat.ID, result.Message); err != nil {
+			return err
+		}
+		return b.sendKioskRoster(ctx, msg.Chat.ID)
+	}
+	if result.OTPRejected {
+		selection.Attempts++
+		logger.Warn("Kiosk code rejected", "audit", "kiosk_code_rejected", "attempt", selection.Attempts)
+		if selection.Attempts < kioskMaxAttempts {
+			if err := b.sessions.Set(ctx, msg.Chat.ID, stateKioskAwaitingCode, selection); err != nil {
+				return b.replyError(ctx, msg.Chat.ID, err, "action.start_conversation", "Failed to save kiosk session")
+			}
+			return b.sendMessage(ctx, msg.Chat.ID, result.Message+"\n"+tr(ctx, "kiosk.attempts_left", "Attempts", kioskMaxAttempts-selection.Attempts))
+		}
+
+		b.sessions.Clear(ctx, msg.Chat.ID)
+		if err := b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "kiosk.too_many_attempts")); err != nil {
+			return err
+		}
+		return b.sendKioskRoster(ctx, msg.Chat.ID)
+	}
+
+	b.sessions.Clear(ctx, msg.Chat.ID)
+	reply := fmt.Sprintf("👤 %s\n%s", escapeMarkdown(name), result.Message)
+	if result.Success {
+		logger.Info("Kiosk attendance recorded",
+			"audit", "kiosk_attendance",
+			"record_id", result.Record.ID,
+			"type", result.Record.Type,
+			"date", result.Record.Date)
+	}
+
+	if err := b.sendMarkdownMessage(ctx, msg.Chat.ID, reply); err != nil {
+		return err
+	}
+	return b.sendKioskRoster(ctx, msg.Chat.ID)
+}
+
+// handleKioskCallback handles a press of a kiosk roster button. Data is "page:N", "pick:USER_ID" or "cancel".
+func (b *Bot) handleKioskCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	if query.Message == nil || !b.isKioskChat(query.Message.Chat.ID) {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "kiosk.button_elsewhere"))
+	}
+	chatID := query.Message.Chat.ID
+
+	action, value, _ := strings.Cut(data, ":")
+	switch action {
+	case "page":
+		page, _ := strconv.Atoi(value)
+		text, keyboard, err := b.kioskRoster(ctx, page)
+		if err != nil {
+			logging.FromContext(ctx).Error("Failed to get kiosk roster", "error", err)
+			return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "kiosk.roster_failed"))
+		}
+		if err := b.api.AnswerCallbackQuery(ctx, query.ID, ""); err != nil {
+			return err
+		}
+		return b.api.EditMessageText(ctx, chatID, query.Message.MessageID, text, &SendMessageOptions{ParseMode: "Markdown", ReplyMarkup: keyboard})
+
+	case "pick":
+		userID, _ := strconv.ParseInt(value, 10, 64)
+		employee, err := b.findKioskEmployee(ctx, userID)
+		if err != nil {
+			logging.FromContext(ctx).Error("Failed to get kiosk roster", "error", err)
+			return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "kiosk.roster_failed"))
+		}
+		if employee == nil {
+			return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "kiosk.employee_not_found"))
+		}
+
+		if err := b.sessions.Set(ctx, chatID, stateKioskAwaitingCode, kioskSelection{Employee: *employee}); err != nil {
+			logging.FromContext(ctx).Error("Failed to save kiosk session", "error", err)
+			return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "kiosk.pick_failed"))
+		}
+		if err := b.api.AnswerCallbackQuery(ctx, query.ID, ""); err != nil {
+			return err
+		}
+
+		prompt := tr(ctx, "kiosk.prompt", "Name", escapeMarkdown(attendance.FullName(employee.FirstName, employee.LastName)))
+		return b.api.SendMessageWithOptions(ctx, chatID, prompt, &SendMessageOptions{
+			ParseMode: "Markdown",
+			ReplyMarkup: &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{
+				{{Text: tr(ctx, "kiosk.cancel_button"), CallbackData: "kiosk:cancel"}},
+			}},
+		})
+
+	case "cancel":
+		b.sessions.Clear(ctx, chatID)
+		if err := b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "kiosk.cancelled_toast")); err != nil {
+			return err
+		}
+		return b.api.EditMessageText(ctx, chatID, query.Message.MessageID, tr(ctx, "kiosk.cancelled"), nil)
+
+	default:
+		return b.api.AnswerCallbackQuery(ctx, query.ID, "")
+	}
+}
+
+// sendKioskRoster posts the first roster page, so the kiosk always ends with a fresh keyboard
+func (b *Bot) sendKioskRoster(ctx context.Context, chatID int64) error {
+	text, keyboard, err := b.kioskRoster(ctx, 0)
+	if err != nil {
+		return b.replyError(ctx, chatID, err, "action.load_roster", "Failed to get kiosk roster")
+	}
+	return b.api.SendMessageWithOptions(ctx, chatID, text, &SendMessageOptions{ParseMode: "Markdown", ReplyMarkup: keyboard})
+}
+
+// kioskRoster renders a roster page with one button per employee and page navigation
+func (b *Bot) kioskRoster(ctx context.Context, page int) (string, *InlineKeyboardMarkup, error) {
+	employees, err := b.attendanceService.GetKioskEmployees(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(employees) == 0 {
+		return tr(ctx, "kiosk.roster_empty"), nil, nil
+	}
+
+	pages := (len(employees) + kioskPageSize - 1) / kioskPageSize
+	page = max(0, min(page, pages-1))
+
+	keyboard := &InlineKeyboardMarkup{}
+	for _, employee := range employees[page*kioskPageSize : min((page+1)*kioskPageSize, len(employees))] {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []InlineKeyboardButton{{
+			Text:         attendance.FullName(employee.FirstName, employee.LastName),
+			CallbackData: fmt.Sprintf("kiosk:pick:%d", employee.UserID),
+		}})
+	}
+
+	if pages > 1 {
+		var navigation []InlineKeyboardButton
+		if page > 0 {
+			navigation = append(navigation, InlineKeyboardButton{Text: tr(ctx, "common.previous_button"), CallbackData: fmt.Sprintf("kiosk:page:%d", page-1)})
+		}
+		if page < pages-1 {
+			navigation = append(navigation, InlineKeyboardButton{Text: tr(ctx, "common.next_button"), CallbackData: fmt.Sprintf("kiosk:page:%d", page+1)})
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, navigation)
+	}
+
+	text := tr(ctx, "kiosk.roster", "Page", page+1, "Pages", pages)
+	return text, keyboard, nil
+}
+
+// findKioskEmployee returns the roster entry of a user, or nil if they are not on the roster
+func (b *Bot) findKioskEmployee(ctx context.Context, userID int64) (*models.Employee, error) {
+	employees, err := b.attendanceService.GetKioskEmployees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range employees {
+		if employees[i].UserID == userID {
+			return &employees[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// handleKioskEnroll handles the /kioskenroll command, giving a user a personal code sheet for
+// the kiosk. Enrolling again replaces the previous sheet.
+func (b *Bot) handleKioskEnroll(ctx context.Context, msg *Message, args []string) error {
+	if len(args) != 1 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "kiosk.enroll_usage"))
+	}
+	userID, err := utils.ParseInteger(args[0])
+	if err != nil || !utils.IsValidTelegramUserID(userID) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.invalid_user_id"))
+	}
+
+	if _, err := b.attendanceService.EnrollHOTP(ctx, userID); err != nil {
+		if errors.Is(err, attendance.ErrEncryptionKeyMissing) {
+			return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "kiosk.encryption_key_missing"))
+		}
+		return b.replyError(ctx, msg.Chat.ID, err, "action.enroll_employee", "Failed to enroll hotp", "target_user_id", userID)
+	}
+
+	codes, err := b.attendanceService.GenerateHOTPSheet(ctx, userID, kioskSheetCodes)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.create_code_sheet", "Failed to generate hotp sheet", "target_user_id", userID)
+	}
+
+	logging.FromContext(ctx).Warn("Employee enrolled for the kiosk",
+		"audit", "kiosk_enrolled",
+		"target_user_id", userID,
+		"enrolled_by", msg.From.ID)
+
+	var sheet strings.Builder
+	sheet.WriteString(tr(ctx, "kiosk.sheet_title", "UserID", userID) + "\n\n")
+	for i, code := range codes {
+		sheet.WriteString(fmt.Sprintf("%2d. %s\n", i+1, code))
+	}
+	sheet.WriteString("\n" + tr(ctx, "kiosk.sheet_instructions"))
+	return b.sendMessage(ctx, msg.Chat.ID, sheet.String())
+}
@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"context"
+	"errors"
+	"strings"
+)
+
+// withLanguage returns ctx carrying the language of the user who sent the update, so replies to
+// them are written in it
+func (b *Bot) withLanguage(ctx context.Context, update *Update) context.Context {
+	var from *User
+	switch {
+	case update.CallbackQuery != nil:
+		from = update.CallbackQuery.From
+	case update.Message != nil:
+		from = update.Message.From
+	}
+	if from == nil {
+		return ctx
+	}
+
+	lang, err := b.attendanceService.ResolveLanguage(ctx, from.ID, from.LanguageCode)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to resolve user language", "error", err)
+		lang = i18n.Normalize(from.LanguageCode)
+	}
+	return i18n.NewContext(ctx, lang)
+}
+
+// tr renders a message in the language of the update being handled
+func tr(ctx context.Context, key string, args ...any) string {
+	return i18n.T(i18n.FromContext(ctx), key, args...)
+}
+
+// languageOf returns the language of a user other than the sender, for notifications
+func (b *Bot) languageOf(ctx context.Context, userID int64) string {
+	lang, err := b.attendanceService.GetUserLanguage(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to get user language", "target_user_id", userID, "error", err)
+	}
+	return lang
+}
+
+// handleLanguage handles the /language command. Without arguments it shows the current language
+// with a button per supported language; "auto" returns to the Telegram app's language.
+func (b *Bot) handleLanguage(ctx context.Context, msg *Message, args []string) error {
+	if len(args) == 0 {
+		return b.api.SendMessageWithOptions(ctx, msg.Chat.ID, tr(ctx, "language.current", "Language", i18n.Name(i18n.FromContext(ctx))),
+			&SendMessageOptions{ReplyMarkup: languageKeyboard()})
+	}
+
+	text, err := b.setLanguage(ctx, msg.From, strings.ToLower(args[0]))
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.set_language", "Failed to set language")
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, text)
+}
+
+// handleLanguageCallback handles the language buttons of /language
+func (b *Bot) handleLanguageCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	text, err := b.setLanguage(ctx, query.From, data)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to set language", "error", err)
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "language.failed"))
+	}
+	if err := b.api.AnswerCallbackQuery(ctx, query.ID, ""); err != nil {
+		return err
+	}
+
+	if query.Message != nil {
+		return b.api.EditMessageText(ctx, query.Message.Chat.ID, query.Message.MessageID, text, nil)
+	}
+	return nil
+}
+
+// setLanguage stores the language a user picked, or drops their choice for "auto", and returns
+// the confirmation in the new language. Unsupported codes return the list of supported ones.
+func (b *Bot) setLanguage(ctx context.Context, from *User, code string) (string, error) {
+	if code == "auto" {
+		code = ""
+	}
+
+	err := b.attendanceService.SetUserLanguage(ctx, from.ID, code, from.LanguageCode)
+	if errors.Is(err, attendance.ErrUnsupportedLanguage) {
+		return tr(ctx, "language.unsupported", "Languages", strings.Join(i18n.Supported(), ", ")), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if code == "" {
+		lang := i18n.Normalize(from.LanguageCode)
+		logging.FromContext(ctx).Info("Language set", "language", lang, "chosen", false)
+		return i18n.T(lang, "language.auto", "Language", i18n.Name(lang)), nil
+	}
+	logging.FromContext(ctx).Info("Language set", "language", code, "chosen", true)
+	return i18n.T(code, "language.set", "Language", i18n.Name(code)), nil
+}
+
+// languageKeyboard offers a button per supported language and one to follow the Telegram app
+func languageKeyboard() *InlineKeyboardMarkup {
+	row := make([]InlineKeyboardButton, 0, len(i18n.Supported()))
+	for _, lang := range i18n.Supported() {
+		row = append(row, InlineKeyboardButton{Text: i18n.Name(lang), CallbackData: "language:" + lang})
+	}
+	return &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{row, {{Text: "🔄 Auto", CallbackData: "language:auto"}}}}
+}
+
+// usageMessage renders the reply to a malformed command: the invalid format notice followed by
+// the command's usage from the catalog
+func usageMessage(ctx context.Context, key string) string {
+	return tr(ctx, "common.invalid_format") + "\n" + tr(ctx, key)
+}
@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// lateAlertQueueSize bounds the check-ins waiting to be judged for an instant late arrival alert
+const lateAlertQueueSize = 64
+
+// lateAlerts tells the late alert chat of each late check-in as it is recorded
+type lateAlerts struct {
+	bot      *Bot
+	chatID   int64
+	checkIns chan models.AttendanceRecord
+	logger   *slog.Logger
+}
+
+// newLateAlerts creates the instant late arrival alerts sent to a chat
+func newLateAlerts(b *Bot, chatID int64) *lateAlerts {
+	return &lateAlerts{
+		bot:      b,
+		chatID:   chatID,
+		checkIns: make(chan models.AttendanceRecord, lateAlertQueueSize),
+		logger:   b.logger.With("component", "late_alerts", "chat_id", chatID),
+	}
+}
+
+// recorded queues a new check-in to be judged for lateness without blocking
+func (a *lateAlerts) recorded(record *models.AttendanceRecord) {
+	if record.Type != "check_in" {
+		return
+	}
+
+	select {
+	case a.checkIns <- *record:
+	default:
+		a.logger.Warn("Late alert queue full, dropping late arrival check", "record_id", record.ID)
+	}
+}
+
+// run sends late arrival alerts for queued check-ins until ctx is cancelled
+func (a *lateAlerts) run(ctx context.Context) {
+	defer a.bot.inFlight.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case record := <-a.checkIns:
+			a.alertIfLate(ctx, &record)
+		}
+	}
+}
+
+// alertIfLate sends a late arrival alert when the check-in is late for the user's shift
+func (a *lateAlerts) alertIfLate(ctx context.Context, record *models.AttendanceRecord) {
+	shift, err := a.bot.attendanceService.GetUserShift(ctx, record.UserID)
+	if err != nil {
+		a.logger.Error("Failed to get shift", "user_id", record.UserID, "error", err)
+		return
+	}
+	if !utils.IsLate(record.Timestamp, shift) {
+		return
+	}
+
+	text := i18n.T(i18n.Default, "late_alert.instant", a.bot.lateArrivalArgs(ctx, models.LateArrival{Record: *record, Shift: shift})...)
+	if err := a.bot.sendBroadcastMessage(ctx, a.chatID, text); err != nil {
+		a.logger.Error("Failed to send late arrival alert", "user_id", record.UserID, "error", err)
+		return
+	}
+	a.logger.Info("Late arrival alert sent", "user_id", record.UserID, "record_id", record.ID)
+}
+
+// sendLateDigest sends the late alert chat today's late arrivals in one message, or nothing when
+// nobody was late
+func (b *Bot) sendLateDigest(ctx context.Context) {
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+	chatID, _ := b.config.LateAlertChat()
+	logger := b.logger.With("request_id", logging.RequestID(ctx), "chat_id", chatID)
+
+	now := utils.Now()
+	late, err := b.attendanceService.GetLateArrivals(ctx, now.Format("2006-01-02"))
+	if err != nil {
+		logger.Error("Failed to get late arrivals", "error", err)
+		return
+	}
+	if len(late) == 0 {
+		logger.Info("No late arrivals, digest not sent")
+		return
+	}
+
+	var message strings.Builder
+	message.WriteString(i18n.T(i18n.Default, "late_alert.digest_title", "Date", utils.FormatDateIn(now, "dd MMMM yyyy", i18n.Default), "Count", len(late)) + "\n")
+	for i, arrival := range late {
+		message.WriteString(fmt.Sprintf("%d. %s\n", i+1, i18n.T(i18n.Default, "late_alert.digest_line", b.lateArrivalArgs(ctx, arrival)...)))
+	}
+
+	if err := b.sendBroadcastMessage(ctx, chatID, message.String()); err != nil {
+		logger.Error("Failed to send late arrival digest", "error", err)
+		return
+	}
+	logger.Info("Late arrival digest sent", "late", len(late))
+}
+
+// lateArrivalArgs returns the template arguments describing a late arrival. The shift starts in
+// the user's own timezone, so the check-in is shown in it too.
+func (b *Bot) lateArrivalArgs(ctx context.Context, arrival models.LateArrival) []any {
+	location := utils.ShiftLocation(arrival.Shift)
+	return []any{
+		"Name", markdownEscaper.Replace(b.attendanceService.DisplayName(ctx, &arrival.Record)),
+		"Time", utils.FormatTimeIn(arrival.Record.Timestamp, "HH:mm", location),
+		"Zone", arrival.Record.Timestamp.In(location).Format("MST"),
+		"Shift", markdownEscaper.Replace(arrival.Shift.Name),
+		"Start", arrival.Shift.Start,
+	}
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"attendance-bot/internal/config"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testLateAlertChatID is the chat late arrivals are reported to in these tests
+const testLateAlertChatID = -3000
+
+// TestLateAlertsInstant records check-ins under the built-in shift, late from 09:00: only late
+// check-ins are reported to the late alert chat as they are recorded
+func TestLateAlertsInstant(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 3, 4, hour, minute, 0, 0, utils.Location)
+	}
+
+	tests := []struct {
+		name   string
+		record models.AttendanceRecord
+		want   string // Expected alert, empty if none is sent
+	}{
+		{name: "on time", record: models.AttendanceRecord{UserID: 1, FirstName: "Sari", Type: "check_in", Timestamp: at(8, 30)}},
+		{name: "just before the start", record: models.AttendanceRecord{UserID: 1, FirstName: "Sari", Type: "check_in", Timestamp: at(8, 59)}},
+		{name: "late", record: models.AttendanceRecord{UserID: 1, FirstName: "Sari", Type: "check_in", Timestamp: at(10, 15)},
+			want: i18n.T(i18n.Default, "late_alert.instant", "Name", "Sari", "Time", "10:15", "Zone", "WIB", "Shift", models.DefaultShiftName, "Start", "09:00")},
+		{name: "late, name with Markdown", record: models.AttendanceRecord{UserID: 1, FirstName: "Sari_K", Type: "check_in", Timestamp: at(9, 30)},
+			want: i18n.T(i18n.Default, "late_alert.instant", "Name", `Sari\_K`, "Time", "09:30", "Zone", "WIB", "Shift", models.DefaultShiftName, "Start", "09:00")},
+		{name: "check-out", record: models.AttendanceRecord{UserID: 1, FirstName: "Sari", Type: "check_out", Timestamp: at(17, 0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newTestBot(t)
+			alerts := newLateAlerts(tb.Bot, testLateAlertChatID)
+
+			alerts.recorded(&tt.record)
+			select {
+			case record := <-alerts.checkIns:
+				alerts.alertIfLate(context.Background(), &record)
+			default:
+			}
+
+			messages := tb.telegram.messagesTo(testLateAlertChatID)
+			switch {
+			case tt.want == "" && len(messages) > 0:
+				t.Errorf("alerts = %q, want none", messages)
+			case tt.want != "" && (len(messages) != 1 || messages[0] != tt.want):
+				t.Errorf("alerts = %q, want %q", messages, tt.want)
+			}
+		})
+	}
+}
+
+// TestSendLateDigest sends today's late arrivals in one message, or nothing when nobody was late
+func TestSendLateDigest(t *testing.T) {
+	today := utils.Now()
+	checkIn := func(userID int64, name string, hour int) models.AttendanceRecord {
+		timestamp := time.Date(today.Year(), today.Month(), today.Day(), hour, 0, 0, 0, utils.Location)
+		return models.AttendanceRecord{UserID: userID, FirstName: name, Type: "check_in", Timestamp: timestamp, Date: timestamp.Format("2006-01-02")}
+	}
+
+	tests := []struct {
+		name    string
+		records []models.AttendanceRecord
+		want    []string // Lines the digest contains, nil if none is sent
+	}{
+		{name: "nobody checked in"},
+		{name: "nobody late", records: []models.AttendanceRecord{checkIn(1, "Sari", 8)}},
+		{
+			name:    "late arrivals",
+			records: []models.AttendanceRecord{checkIn(1, "Sari", 8), checkIn(2, "Budi", 10), checkIn(3, "Dewi", 11)},
+			want: []string{
+				i18n.T(i18n.Default, "late_alert.digest_title", "Date", utils.FormatDateIn(today, "dd MMMM yyyy", i18n.Default), "Count", 2),
+				"1. " + i18n.T(i18n.Default, "late_alert.digest_line", "Name", "Budi", "Time", "10:00", "Zone", "WIB", "Shift", models.DefaultShiftName, "Start", "09:00"),
+				"2. " + i18n.T(i18n.Default, "late_alert.digest_line", "Name", "Dewi", "Time", "11:00", "Zone", "WIB", "Shift", models.DefaultShiftName, "Start", "09:00"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newTestBot(t, func(cfg *config.Config) {
+				cfg.LateAlerts = "digest"
+				cfg.LateAlertChatID = testLateAlertChatID
+			})
+			if len(tt.records) > 0 {
+				if _, _, err := tb.repo.InsertAttendanceBatch(context.Background(), tt.records); err != nil {
+					t.Fatalf("InsertAttendanceBatch: %v", err)
+				}
+			}
+
+			tb.sendLateDigest(context.Background())
+
+			messages := tb.telegram.messagesTo(testLateAlertChatID)
+			if tt.want == nil {
+				if len(messages) > 0 {
+					t.Errorf("digest = %q, want none", messages)
+				}
+				return
+			}
+			if len(messages) != 1 {
+				t.Fatalf("got %d digests, want 1", len(messages))
+			}
+			if got := strings.TrimSpace(messages[0]); got != strings.Join(tt.want, "\n") {
+				t.Errorf("digest =\n%s\nwant\n%s", got, strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
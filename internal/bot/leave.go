@@ -0,0 +1,275 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLeaveReasonLength bounds the reason given with a leave request, in characters
+const maxLeaveReasonLength = 200
+
+// leaveDraft is a leave request entered step by step after /leave new
+type leaveDraft struct {
+	Type      string `json:"type"`
+	StartDate string `json:"start_date,omitempty"`
+	EndDate   string `json:"end_date,omitempty"`
+}
+
+// handleLeave handles the /leave command, submitting a leave request for admin approval
+func (b *Bot) handleLeave(ctx context.Context, msg *Message, args []string) error {
+	if len(args) == 0 {
+		return b.sendLeaves(ctx, msg)
+	}
+	if len(args) == 1 && strings.EqualFold(args[0], "new") {
+		if err := b.sessions.Set(ctx, msg.From.ID, stateLeaveAwaitingType, nil); err != nil {
+			return b.replyError(ctx, msg.Chat.ID, err, "action.start_conversation", "Failed to save session")
+		}
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.ask_type"))
+	}
+	if len(args) < 3 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "leave.usage"))
+	}
+
+	leaveType := strings.ToLower(args[0])
+	startDate := args[1]
+	if !utils.IsValidDateFormat(startDate) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.invalid_start"))
+	}
+	endDate, rest := startDate, args[2:]
+	if utils.IsValidDateFormat(args[2]) {
+		endDate, rest = args[2], args[3:]
+	}
+
+	reason := strings.TrimSpace(strings.Join(rest, " "))
+	if reason == "" {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "leave.usage"))
+	}
+	if utf8.RuneCountInString(reason) > maxLeaveReasonLength {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.reason_too_long", "Max", maxLeaveReasonLength))
+	}
+
+	return b.submitLeave(ctx, msg, leaveDraft{Type: leaveType, StartDate: startDate, EndDate: endDate}, reason)
+}
+
+// handleLeaveTypeInput handles the leave type entered after /leave new, asking for the dates next
+func (b *Bot) handleLeaveTypeInput(ctx context.Context, msg *Message, _ *Session) error {
+	draft := leaveDraft{Type: strings.ToLower(strings.TrimSpace(msg.Text))}
+	if _, ok := models.LeaveTypeLabels[draft.Type]; !ok {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.unknown_type"))
+	}
+
+	if err := b.sessions.Set(ctx, msg.From.ID, stateLeaveAwaitingDates, draft); err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.start_conversation", "Failed to save session")
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.ask_dates"))
+}
+
+// handleLeaveDatesInput handles the first and, optionally, last day of the leave entered after
+// its type, asking for the reason next
+func (b *Bot) handleLeaveDatesInput(ctx context.Context, msg *Message, session *Session) error {
+	var draft leaveDraft
+	if err := session.Decode(&draft); err != nil {
+		b.sessions.Clear(ctx, msg.From.ID)
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_leave_request", "Failed to read leave draft")
+	}
+
+	fields := strings.Fields(msg.Text)
+	if len(fields) == 0 || len(fields) > 2 || !utils.IsValidDateFormat(fields[0]) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.invalid_start"))
+	}
+	draft.StartDate, draft.EndDate = fields[0], fields[0]
+	if len(fields) == 2 {
+		if !utils.IsValidDateFormat(fields[1]) || fields[1] < fields[0] {
+			return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.invalid_range"))
+		}
+		draft.EndDate = fields[1]
+	}
+
+	if err := b.sessions.Set(ctx, msg.From.ID, stateLeaveAwaitingReason, draft); err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.start_conversation", "Failed to save session")
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.ask_reason", "Max", maxLeaveReasonLength))
+}
+
+// handleLeaveReasonInput handles the reason entered last and submits the leave request
+func (b *Bot) handleLeaveReasonInput(ctx context.Context, msg *Message, session *Session) error {
+	var draft leaveDraft
+	if err := session.Decode(&draft); err != nil {
+		b.sessions.Clear(ctx, msg.From.ID)
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_leave_request", "Failed to read leave draft")
+	}
+
+	reason := strings.TrimSpace(msg.Text)
+	if reason == "" {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.ask_reason", "Max", maxLeaveReasonLength))
+	}
+	if utf8.RuneCountInString(reason) > maxLeaveReasonLength {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.reason_too_long", "Max", maxLeaveReasonLength))
+	}
+
+	b.sessions.Clear(ctx, msg.From.ID)
+	return b.submitLeave(ctx, msg, draft, reason)
+}
+
+// submitLeave stores the sender's leave request and asks the admins to decide on it
+func (b *Bot) submitLeave(ctx context.Context, msg *Message, draft leaveDraft, reason string) error {
+	username, firstName, lastName := recordedIdentity(msg.From)
+	leave, err := b.attendanceService.RequestLeave(ctx, &models.Leave{
+		UserID:    msg.From.ID,
+		Username:  username,
+		FirstName: firstName,
+		LastName:  lastName,
+		Type:      draft.Type,
+		StartDate: draft.StartDate,
+		EndDate:   draft.EndDate,
+		Reason:    reason,
+	})
+	switch {
+	case errors.Is(err, attendance.ErrUnknownLeaveType):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.unknown_type"))
+	case errors.Is(err, attendance.ErrInvalidDateRange):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.invalid_range"))
+	case errors.Is(err, attendance.ErrLeaveTooLong):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.too_long", "Days", attendance.MaxLeaveDays))
+	case errors.Is(err, attendance.ErrLeaveTooOld):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.too_old", "Days", attendance.MaxLeaveBackdateDays))
+	case errors.Is(err, attendance.ErrLeaveOverlap):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.overlap"))
+	case err != nil:
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_leave_request", "Failed to request leave")
+	}
+
+	logger := logging.FromContext(ctx).With("leave_id", leave.ID, "leave_type", leave.Type)
+	logger.Info("Leave requested", "audit", "leave_requested", "start_date", leave.StartDate, "end_date", leave.EndDate)
+
+	if err := b.requestLeaveApproval(ctx, leave); err != nil {
+		logger.Error("Failed to send leave approval request", "error", err)
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.admin_unreachable", "ID", leave.ID))
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "leave.requested",
+		"Type", leaveLabel(i18n.FromContext(ctx), leave), "ID", leave.ID, "Period", leavePeriod(i18n.FromContext(ctx), leave)))
+}
+
+// sendLeaves shows the /leave syntax and the sender's leave requests that have not ended yet
+func (b *Bot) sendLeaves(ctx context.Context, msg *Message) error {
+	leaves, err := b.attendanceService.GetUpcomingLeaves(ctx, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_leave_requests", "Failed to get leaves")
+	}
+
+	var message strings.Builder
+	lang := i18n.FromContext(ctx)
+	message.WriteString(tr(ctx, "leave.usage"))
+	if len(leaves) > 0 {
+		message.WriteString("\n\n" + tr(ctx, "leave.list_title") + "\n")
+		for i := range leaves {
+			message.WriteString(tr(ctx, "leave.list_entry", "ID", leaves[i].ID, "Type", leaveLabel(lang, &leaves[i]),
+				"Period", leavePeriod(lang, &leaves[i]), "Status", tr(ctx, "leave.status."+leaves[i].Status)) + "\n")
+		}
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, message.String())
+}
+
+// requestLeaveApproval sends a leave request with approve and reject buttons to the admin chat,
+// or to the super-admin when no admin chat is configured
+func (b *Bot) requestLeaveApproval(ctx context.Context, leave *models.Leave) error {
+	chatID := b.config.AdminChatID
+	if chatID == 0 {
+		chatID = b.config.SuperAdminID
+	}
+
+	text := i18n.T(i18n.Default, "leave.approval_request",
+		"Type", leaveLabel(i18n.Default, leave), "ID", leave.ID, "Name", b.attendanceService.LeaveName(ctx, leave),
+		"Username", leave.Username, "UserID", leave.UserID, "Period", leavePeriod(i18n.Default, leave), "Reason", leave.Reason)
+
+	return b.api.SendMessageWithOptions(ctx, chatID, text, &SendMessageOptions{
+		ReplyMarkup: &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{
+			{Text: i18n.T(i18n.Default, "leave.approve_button"), CallbackData: fmt.Sprintf("leave:approve:%d", leave.ID)},
+			{Text: i18n.T(i18n.Default, "leave.reject_button"), CallbackData: fmt.Sprintf("leave:reject:%d", leave.ID)},
+		}}},
+	})
+}
+
+// handleLeaveCallback handles the approve and reject buttons of a leave request
+func (b *Bot) handleLeaveCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	if query.Message == nil {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, "")
+	}
+	if !b.isAdminChat(query.Message.Chat.ID) && !b.isAdmin(ctx, query.From.ID) {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "leave.admin_only"))
+	}
+
+	action, value, _ := strings.Cut(data, ":")
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || (action != "approve" && action != "reject") {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "common.button_expired"))
+	}
+	approve := action == "approve"
+
+	leave, decided, err := b.attendanceService.DecideLeave(ctx, id, approve, query.From.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to decide leave", "leave_id", id, "error", err)
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "leave.decide_failed"))
+	}
+	if leave == nil {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "leave.not_found"))
+	}
+	if !decided {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "leave.already_"+leave.Status))
+	}
+
+	audit, outcome := "leave_rejected", "leave.outcome_rejected"
+	if approve {
+		audit, outcome = "leave_approved", "leave.outcome_approved"
+	}
+	logging.FromContext(ctx).Warn("Leave request decided",
+		"audit", audit,
+		"leave_id", leave.ID,
+		"target_user_id", leave.UserID,
+		"decided_by", query.From.ID)
+
+	if err := b.api.AnswerCallbackQuery(ctx, query.ID, ""); err != nil {
+		return err
+	}
+
+	// Keep the request in the admin chat, replacing the buttons with the decision
+	decision := query.Message.Text + "\n\n" + i18n.T(i18n.Default, "leave.decided_by",
+		"Outcome", i18n.T(i18n.Default, outcome), "Name", strings.TrimSpace(query.From.FirstName+" "+query.From.LastName))
+	if err := b.api.EditMessageText(ctx, query.Message.Chat.ID, query.Message.MessageID, decision, nil); err != nil {
+		logging.FromContext(ctx).Warn("Failed to update leave request message", "leave_id", leave.ID, "error", err)
+	}
+
+	// Users who never started a private chat with the bot cannot be notified
+	lang := b.languageOf(ctx, leave.UserID)
+	notice := i18n.T(lang, "leave.decided_notice",
+		"Outcome", i18n.T(lang, outcome), "Type", leaveLabel(lang, leave), "ID", leave.ID, "Period", leavePeriod(lang, leave))
+	if err := b.sendMessage(ctx, leave.UserID, notice); err != nil {
+		logging.FromContext(ctx).Info("Failed to notify user about leave decision", "target_user_id", leave.UserID, "error", err)
+	}
+
+	return nil
+}
+
+// leavePeriod renders the dates a leave covers in lang
+func leavePeriod(lang string, leave *models.Leave) string {
+	if leave.StartDate == leave.EndDate {
+		return leave.StartDate
+	}
+	return i18n.T(lang, "leave.period", "Start", leave.StartDate, "End", leave.EndDate)
+}
+
+// leaveLabel returns the name of a leave's type in lang
+func leaveLabel(lang string, leave *models.Leave) string {
+	return i18n.T(lang, "leave.type."+leave.Type)
+}
@@ -0,0 +1,193 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// liveReportEditInterval is the minimum time between two edits of the pinned report
+const liveReportEditInterval = time.Minute
+
+// liveReport keeps one pinned report message per day up to date in a chat
+type liveReport struct {
+	bot     *Bot
+	chatID  int64
+	open    utils.Clock
+	closing utils.Clock
+	refresh chan struct{} // Buffered, so refresh requests made while an edit is pending coalesce
+	logger  *slog.Logger
+
+	current  *models.LiveReport // Last posted report, nil if none
+	lastEdit time.Time
+}
+
+// newLiveReport creates the live report of a chat, posted at open and closed at closing
+func newLiveReport(b *Bot, chatID int64, open, closing utils.Clock) *liveReport {
+	return &liveReport{
+		bot:     b,
+		chatID:  chatID,
+		open:    open,
+		closing: closing,
+		refresh: make(chan struct{}, 1),
+		logger:  b.logger.With("component", "live_report", "chat_id", chatID),
+	}
+}
+
+// touch schedules a refresh of the pinned report without blocking
+func (l *liveReport) touch(*models.AttendanceRecord) {
+	select {
+	case l.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// run posts, edits and closes the pinned report until ctx is cancelled
+func (l *liveReport) run(ctx context.Context) {
+	defer l.bot.inFlight.Done()
+
+	current, err := l.bot.attendanceService.GetLiveReport(ctx)
+	if err != nil {
+		l.logger.Error("Failed to load live report", "error", err)
+	}
+	if current != nil && current.ChatID == l.chatID {
+		l.current = current
+	}
+
+	for {
+		now := utils.Now()
+		l.advance(ctx, now)
+
+		timer := time.NewTimer(l.nextTransition(now).Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		case <-l.refresh:
+			timer.Stop()
+			l.edit(ctx)
+		}
+	}
+}
+
+// advance posts the day's report once it opens and closes it at the end of the day
+func (l *liveReport) advance(ctx context.Context, now time.Time) {
+	today := now.Format("2006-01-02")
+	isOpen := !now.Before(l.open.On(now)) && now.Before(l.closing.On(now))
+
+	switch {
+	case isOpen && (l.current == nil || l.current.Date != today):
+		l.post(ctx, today)
+	case l.current != nil && !l.current.Closed && (l.current.Date != today || !now.Before(l.closing.On(now))):
+		l.close(ctx)
+	}
+}
+
+// nextTransition returns when the report is next posted or closed
+func (l *liveReport) nextTransition(now time.Time) time.Time {
+	next := l.open.Next(now)
+	if closing := l.closing.Next(now); closing.Before(next) {
+		next = closing
+	}
+	return next
+}
+
+// post sends and pins a new report for today, closing and unpinning the previous one
+func (l *liveReport) post(ctx context.Context, today string) {
+	if l.current != nil {
+		if !l.current.Closed {
+			l.close(ctx)
+		}
+		if err := l.bot.api.UnpinChatMessage(ctx, l.current.ChatID, l.current.MessageID); err != nil {
+			l.logger.Warn("Failed to unpin previous live report", "message_id", l.current.MessageID, "error", err)
+		}
+	}
+
+	text, err := l.render(ctx, today, false)
+	if err != nil {
+		l.logger.Error("Failed to generate live report", "error", err)
+		return
+	}
+
+	message, err := l.bot.api.PostMessage(ctx, l.chatID, text, &SendMessageOptions{ParseMode: "Markdown"})
+	if err != nil {
+		l.logger.Error("Failed to post live report", "error", err)
+		return
+	}
+	l.lastEdit = time.Now()
+
+	// Pinning needs admin rights in the chat; the report is still updated without them
+	if err := l.bot.api.PinChatMessage(ctx, l.chatID, message.MessageID, true); err != nil {
+		l.logger.Warn("Failed to pin live report", "message_id", message.MessageID, "error", err)
+	}
+
+	l.save(ctx, &models.LiveReport{Date: today, ChatID: l.chatID, MessageID: message.MessageID})
+	l.logger.Info("Live report posted", "message_id", message.MessageID, "date", today)
+}
+
+// edit refreshes today's open report, at most once per liveReportEditInterval
+func (l *liveReport) edit(ctx context.Context) {
+	if l.current == nil || l.current.Closed || l.current.Date != utils.GetTodayDate() {
+		return
+	}
+
+	if wait := time.Until(l.lastEdit.Add(liveReportEditInterval)); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+
+	text, err := l.render(ctx, l.current.Date, false)
+	if err != nil {
+		l.logger.Error("Failed to generate live report", "error", err)
+		return
+	}
+
+	l.lastEdit = time.Now()
+	if err := l.bot.api.EditMessageText(ctx, l.current.ChatID, l.current.MessageID, text, &SendMessageOptions{ParseMode: "Markdown"}); err != nil {
+		l.logger.Error("Failed to update live report", "message_id", l.current.MessageID, "error", err)
+	}
+}
+
+// close makes the final edit of the current report, marking it as closed
+func (l *liveReport) close(ctx context.Context) {
+	text, err := l.render(ctx, l.current.Date, true)
+	if err != nil {
+		l.logger.Error("Failed to generate live report", "error", err)
+	} else if err := l.bot.api.EditMessageText(ctx, l.current.ChatID, l.current.MessageID, text, &SendMessageOptions{ParseMode: "Markdown"}); err != nil {
+		l.logger.Error("Failed to close live report", "message_id", l.current.MessageID, "error", err)
+	}
+
+	closed := *l.current
+	closed.Closed = true
+	l.save(ctx, &closed)
+	l.logger.Info("Live report closed", "message_id", closed.MessageID, "date", closed.Date)
+}
+
+// render returns the date's report with a footer showing when it was last updated or closed
+func (l *liveReport) render(ctx context.Context, date string, closed bool) (string, error) {
+	report, err := l.bot.attendanceService.GenerateAttendanceReportFor(ctx, date, i18n.Default)
+	if err != nil {
+		return "", err
+	}
+
+	if closed {
+		return report + "\n\n" + i18n.T(i18n.Default, "live_report.closed", "Clock", l.closing, "Zone", utils.ZoneName(time.Now())), nil
+	}
+	now := utils.FormatTime(utils.Now(), "HH:mm")
+	return report + "\n\n" + i18n.T(i18n.Default, "live_report.updated", "Clock", now, "Zone", utils.ZoneName(time.Now())), nil
+}
+
+// save remembers report as current and persists it, so edits survive restarts
+func (l *liveReport) save(ctx context.Context, report *models.LiveReport) {
+	l.current = report
+	if err := l.bot.attendanceService.SaveLiveReport(ctx, report); err != nil {
+		l.logger.Error("Failed to save live report", "error", err)
+	}
+}
@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/notify"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"log/slog"
+)
+
+// mirrorQueueSize bounds the check-ins waiting to be judged for a late arrival alert
+const mirrorQueueSize = 64
+
+// mirror copies the daily report and late arrival alerts to Slack and Discord
+type mirror struct {
+	bot       *Bot
+	notifiers []notify.Notifier
+	checkIns  chan models.AttendanceRecord
+	logger    *slog.Logger
+}
+
+// newMirror creates the mirror of the configured platforms, or returns nil if none is configured
+func newMirror(b *Bot) *mirror {
+	var notifiers []notify.Notifier
+	if b.config.SlackWebhookURL != "" {
+		notifiers = append(notifiers, notify.NewSlack(b.config.SlackWebhookURL))
+	}
+	if b.config.DiscordWebhookURL != "" {
+		notifiers = append(notifiers, notify.NewDiscord(b.config.DiscordWebhookURL))
+	}
+	if len(notifiers) == 0 {
+		return nil
+	}
+
+	return &mirror{
+		bot:       b,
+		notifiers: notifiers,
+		checkIns:  make(chan models.AttendanceRecord, mirrorQueueSize),
+		logger:    b.logger.With("component", "mirror"),
+	}
+}
+
+// send posts a message of the event to every platform, unless the event is not mirrored. Failures
+// are logged; the chat platforms are a copy, not the record.
+func (m *mirror) send(ctx context.Context, event string, msg notify.Message) {
+	if m == nil || !m.bot.config.Mirrors(event) {
+		return
+	}
+
+	for _, notifier := range m.notifiers {
+		if err := notifier.Send(ctx, msg); err != nil {
+			m.logger.Error("Failed to mirror message", "platform", notifier.Name(), "event", event, "error", err)
+			continue
+		}
+		m.logger.Info("Message mirrored", "platform", notifier.Name(), "event", event)
+	}
+}
+
+// recorded queues a new check-in to be judged for lateness without blocking
+func (m *mirror) recorded(record *models.AttendanceRecord) {
+	if record.Type != "check_in" {
+		return
+	}
+
+	select {
+	case m.checkIns <- *record:
+	default:
+		m.logger.Warn("Mirror queue full, dropping late arrival check", "record_id", record.ID)
+	}
+}
+
+// run sends late arrival alerts for queued check-ins until ctx is cancelled
+func (m *mirror) run(ctx context.Context) {
+	defer m.bot.inFlight.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case record := <-m.checkIns:
+			m.alertIfLate(ctx, &record)
+		}
+	}
+}
+
+// alertIfLate mirrors a late arrival alert when the check-in is late for the user's shift
+func (m *mirror) alertIfLate(ctx context.Context, record *models.AttendanceRecord) {
+	shift, err := m.bot.attendanceService.GetUserShift(ctx, record.UserID)
+	if err != nil {
+		m.logger.Error("Failed to get shift", "user_id", record.UserID, "error", err)
+		return
+	}
+	if !utils.IsLate(record.Timestamp, shift) {
+		return
+	}
+
+	// The shift starts in the user's own timezone, so the check-in is shown in it too
+	location := utils.ShiftLocation(shift)
+	text := i18n.T(i18n.Default, "mirror.late_arrival",
+		"Name", m.bot.attendanceService.DisplayName(ctx, record),
+		"Time", utils.FormatTimeIn(record.Timestamp, "HH:mm", location),
+		"Zone", record.Timestamp.In(location).Format("MST"),
+		"Shift", shift.Name,
+		"Start", shift.Start)
+	m.send(ctx, notify.EventLateArrival, notify.Message{Text: text})
+}
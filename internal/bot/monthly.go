@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/reports"
+	"attendance-bot/internal/utils"
+	"context"
+	"strings"
+	"time"
+)
+
+// handleMonthly handles the /monthly command, sending per-employee attendance totals for a month,
+// the current one by default, of everyone or of one department
+func (b *Bot) handleMonthly(ctx context.Context, msg *Message, args []string) error {
+	month, department, ok, err := b.parseMonthArgs(ctx, msg, args, "monthly.usage")
+	if !ok {
+		return err
+	}
+
+	summary, err := b.attendanceService.GetMonthlySummary(ctx, month, department)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.create_monthly", "Failed to get monthly summary",
+			"month", month, "department", department)
+	}
+
+	return b.sendMessages(ctx, msg.Chat.ID, reports.FormatMonthlySummary(summary, i18n.FromContext(ctx)))
+}
+
+// handleOvertime handles the /overtime command, sending each employee's overtime for a month,
+// the current one by default, of everyone or of one department, for payroll
+func (b *Bot) handleOvertime(ctx context.Context, msg *Message, args []string) error {
+	month, department, ok, err := b.parseMonthArgs(ctx, msg, args, "overtime.usage")
+	if !ok {
+		return err
+	}
+
+	summary, err := b.attendanceService.GetOvertimeSummary(ctx, month, department)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_overtime", "Failed to get overtime summary",
+			"month", month, "department", department)
+	}
+
+	return b.sendMessages(ctx, msg.Chat.ID,
+		reports.FormatOvertimeSummary(summary, b.attendanceService.OvertimeThreshold(), i18n.FromContext(ctx)))
+}
+
+// parseMonthArgs reads the optional YYYY-MM month, the current one by default, and department
+// name of /monthly and /overtime. It replies with the usage of usageKey or the known departments
+// and returns false when the arguments are invalid.
+func (b *Bot) parseMonthArgs(ctx context.Context, msg *Message, args []string, usageKey string) (string, string, bool, error) {
+	currentMonth := utils.Now().Format("2006-01")
+	month := currentMonth
+	if len(args) > 0 && looksLikeMonth(args[0]) {
+		month = args[0]
+		args = args[1:]
+	}
+	if _, err := time.Parse("2006-01", month); err != nil {
+		return "", "", false, b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, usageKey))
+	}
+	if month > currentMonth {
+		return "", "", false, b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "monthly.future"))
+	}
+
+	department := ""
+	if len(args) > 0 {
+		resolved, ok, err := b.resolveDepartment(ctx, msg.Chat.ID, strings.Join(args, " "))
+		if !ok {
+			return "", "", false, err
+		}
+		department = resolved
+	}
+	return month, department, true, nil
+}
+
+// sendMessages sends messages in order, stopping at the first that fails
+func (b *Bot) sendMessages(ctx context.Context, chatID int64, messages []string) error {
+	for _, message := range messages {
+		if err := b.sendMessage(ctx, chatID, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// looksLikeMonth reports whether an argument is meant as a YYYY-MM month rather than a department,
+// so a mistyped month is reported as such
+func looksLikeMonth(arg string) bool {
+	return len(arg) == len("2006-01") && arg[4] == '-' && strings.Trim(arg[:4]+arg[5:], "0123456789") == ""
+}
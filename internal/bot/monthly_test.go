@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"strings"
+	"testing"
+)
+
+func TestLooksLikeMonth(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want bool
+	}{
+		{"2024-03", true},
+		{"2024-13", true}, // Mistyped months are still months, reported as invalid
+		{"2024-3", false},
+		{"2024/03", false},
+		{"sales", false},
+		{"20x4-03", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := looksLikeMonth(tt.arg); got != tt.want {
+			t.Errorf("looksLikeMonth(%q) = %v, want %v", tt.arg, got, tt.want)
+		}
+	}
+}
+
+// TestMonthlyCommand sends /monthly with several arguments and checks the start of each reply
+func TestMonthlyCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"/monthly 2024-03", "📆 Rekap Bulanan Maret 2024"},
+		{"/monthly", "📆 Rekap Bulanan "},
+		{"/monthly 2024-13", i18n.T(i18n.Default, "common.invalid_format")},
+		{"/monthly 2999-01", i18n.T(i18n.Default, "monthly.future")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			tb := newTestBot(t)
+			tb.send(t, testAdminID, tt.command)
+			if got := tb.telegram.lastMessageTo(t, testAdminID); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("%s replied %q, want it to start with %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSuspiciousOTP(t *testing.T) {
+	now := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	period := 30 * time.Second
+	sent := func(ago time.Duration) int64 { return now.Add(-ago).Unix() }
+
+	tests := []struct {
+		name string
+		msg  Message
+		want string
+	}{
+		{"typed", Message{Date: sent(2 * time.Second)}, ""},
+		{"exactly one period old", Message{Date: sent(period)}, ""},
+		{"older than a period", Message{Date: sent(period + time.Second)}, otpStale},
+		{"hours old", Message{Date: sent(3 * time.Hour)}, otpStale},
+		{"dated ahead", Message{Date: now.Add(time.Minute).Unix()}, ""},
+		{"no date", Message{}, ""},
+		{"forwarded", Message{Date: sent(time.Second), ForwardOrigin: &MessageOrigin{Type: "user", Date: sent(time.Second)}}, otpForwarded},
+		{"forwarded from hidden user", Message{Date: sent(time.Second), ForwardOrigin: &MessageOrigin{Type: "hidden_user"}}, otpForwarded},
+		{"forwarded by older servers", Message{Date: sent(time.Second), ForwardDate: sent(time.Second)}, otpForwarded},
+		{"forwarded and old", Message{Date: sent(time.Hour), ForwardDate: sent(time.Hour)}, otpForwarded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suspiciousOTP(&tt.msg, now, period); got != tt.want {
+				t.Errorf("suspiciousOTP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageIsForwarded(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want bool
+	}{
+		{"typed", `{"message_id":1,"date":1709539200,"text":"123456"}`, false},
+		{"forward origin", `{"message_id":1,"date":1709539200,"text":"123456","forward_origin":{"type":"user","date":1709539190,"sender_user":{"id":9}}}`, true},
+		{"forward date", `{"message_id":1,"date":1709539200,"text":"123456","forward_date":1709539190}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg Message
+			if err := json.Unmarshal([]byte(tt.json), &msg); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got := msg.IsForwarded(); got != tt.want {
+				t.Errorf("IsForwarded = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOTPMessages sends a valid code forwarded, in an old message and typed: only the typed code
+// records attendance
+func TestOTPMessages(t *testing.T) {
+	code, err := attendance.NewTOTPService(testSecret).Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		msg      func(msg *Message)
+		wantText string
+		records  int
+	}{
+		{
+			name:     "forwarded",
+			msg:      func(msg *Message) { msg.ForwardOrigin = &MessageOrigin{Type: "user", Date: time.Now().Unix()} },
+			wantText: i18n.T(i18n.Default, "otp.forwarded"),
+		},
+		{
+			name:     "forwarded by older servers",
+			msg:      func(msg *Message) { msg.ForwardDate = time.Now().Unix() },
+			wantText: i18n.T(i18n.Default, "otp.forwarded"),
+		},
+		{
+			name:     "old",
+			msg:      func(msg *Message) { msg.Date = time.Now().Add(-5 * time.Minute).Unix() },
+			wantText: i18n.T(i18n.Default, "otp.stale"),
+		},
+		{
+			name:     "typed",
+			msg:      func(msg *Message) {},
+			wantText: "**Absen Masuk** tercatat!",
+			records:  1,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newTestBot(t)
+			userID := int64(1300 + i)
+			msg := &Message{
+				From: &User{ID: userID, FirstName: "Sari"},
+				Chat: &Chat{ID: userID, Type: "private"},
+				Text: code,
+			}
+			tt.msg(msg)
+			tb.deliver(t, msg)
+
+			if got := tb.telegram.lastMessageTo(t, userID); !strings.Contains(got, tt.wantText) {
+				t.Errorf("reply = %q, want it to contain %q", got, tt.wantText)
+			}
+			records, err := tb.repo.ListAttendance(context.Background(), userID, "")
+			if err != nil {
+				t.Fatalf("ListAttendance: %v", err)
+			}
+			if len(records) != tt.records {
+				t.Errorf("records = %d, want %d", len(records), tt.records)
+			}
+		})
+	}
+}
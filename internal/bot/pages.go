@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// maxPageLength is the length of a page, in the UTF-16 code units Telegram counts, leaving room
+// below its limit of 4096 for the page footer
+const maxPageLength = 3800
+
+// paginate cuts a message too long for Telegram into pages, at blank lines where possible and
+// else at line breaks. A message that fits is a single page.
+func paginate(text string) []string {
+	var pages []string
+	for messageLength(text) > maxPageLength {
+		end := prefixLength(text, maxPageLength)
+		cut := strings.LastIndex(text[:end], "\n\n")
+		if cut < end/2 {
+			cut = strings.LastIndex(text[:end], "\n")
+		}
+		if cut <= 0 {
+			cut = end
+		}
+		pages = append(pages, strings.TrimRight(text[:cut], "\n"))
+		text = strings.TrimLeft(text[cut:], "\n")
+	}
+	if text != "" || len(pages) == 0 {
+		pages = append(pages, text)
+	}
+	return pages
+}
+
+// messageLength returns the length of text as Telegram counts it
+func messageLength(text string) int {
+	length := 0
+	for _, r := range text {
+		length += utf16Length(r)
+	}
+	return length
+}
+
+// prefixLength returns the byte length of the longest prefix of text of at most limit UTF-16
+// code units
+func prefixLength(text string, limit int) int {
+	length := 0
+	for i, r := range text {
+		length += utf16Length(r)
+		if length > limit {
+			return i
+		}
+	}
+	return len(text)
+}
+
+// pageOf returns page number page of pages with a footer telling which one it is, clamping page to
+// the pages there are, for buttons pressed after the message got shorter. A single page is
+// returned as is.
+func pageOf(ctx context.Context, pages []string, page int) (string, int) {
+	page = min(max(page, 0), len(pages)-1)
+	if len(pages) == 1 {
+		return pages[0], page
+	}
+	return pages[page] + "\n\n" + tr(ctx, "common.page", "Page", page+1, "Pages", len(pages)), page
+}
+
+// pageButtons returns the previous and next page buttons of a paginated message, whose callback
+// data data returns for a page; nil for a single page
+func pageButtons(ctx context.Context, page, pages int, data func(page int) string) []InlineKeyboardButton {
+	var row []InlineKeyboardButton
+	if page > 0 {
+		row = append(row, InlineKeyboardButton{Text: tr(ctx, "common.page_previous"), CallbackData: data(page - 1)})
+	}
+	if page < pages-1 {
+		row = append(row, InlineKeyboardButton{Text: tr(ctx, "common.page_next"), CallbackData: data(page + 1)})
+	}
+	return row
+}
+
+// parsePage parses the page number of callback data, treating a missing or invalid one as the first page
+func parsePage(value string) int {
+	page, err := strconv.Atoi(value)
+	if err != nil || page < 0 {
+		return 0
+	}
+	return page
+}
+
+// utf16Length returns the UTF-16 code units of r: two for runes beyond the Basic Multilingual
+// Plane, such as most emoji
+func utf16Length(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	// A day of history is a few lines followed by a blank line
+	day := strings.Repeat("x", 90) + "\n" + strings.Repeat("y", 90) + "\n\n"
+
+	tests := []struct {
+		name  string
+		text  string
+		pages int
+	}{
+		{"empty", "", 1},
+		{"short", "hello", 1},
+		{"exactly the limit", strings.Repeat("x", maxPageLength), 1},
+		{"days", strings.Repeat(day, 50), 3},
+		{"lines without blank lines", strings.Repeat(strings.Repeat("z", 99)+"\n", 100), 3},
+		{"one long line", strings.Repeat("x", 2*maxPageLength+1), 3},
+		{"emoji count twice", strings.Repeat("⏰", maxPageLength/2) + strings.Repeat("🏡", maxPageLength/2), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := paginate(tt.text)
+			if len(pages) != tt.pages {
+				t.Errorf("paginate made %d pages, want %d", len(pages), tt.pages)
+			}
+			for i, page := range pages {
+				if length := messageLength(page); length > maxPageLength {
+					t.Errorf("page %d is %d long, want at most %d", i+1, length, maxPageLength)
+				}
+			}
+			// Pages are only cut at line breaks, the text itself is kept
+			if got, want := strings.ReplaceAll(strings.Join(pages, ""), "\n", ""), strings.ReplaceAll(tt.text, "\n", ""); got != want {
+				t.Errorf("pages lost text: %d characters, want %d", len(got), len(want))
+			}
+			if len(pages) > 1 && strings.Contains(tt.text, "\n\n") && !strings.HasSuffix(pages[0], "y") {
+				t.Errorf("first page ends with %q, want it cut at a blank line", pages[0][len(pages[0])-10:])
+			}
+		})
+	}
+}
+
+func TestPageOf(t *testing.T) {
+	ctx := i18n.NewContext(context.Background(), "en")
+	pages := []string{"first", "second", "third"}
+
+	tests := []struct {
+		name     string
+		pages    []string
+		page     int
+		want     string
+		wantPage int
+	}{
+		{"single page", []string{"only"}, 0, "only", 0},
+		{"single page, later page asked", []string{"only"}, 2, "only", 0},
+		{"first", pages, 0, "first\n\n" + i18n.T("en", "common.page", "Page", 1, "Pages", 3), 0},
+		{"last", pages, 2, "third\n\n" + i18n.T("en", "common.page", "Page", 3, "Pages", 3), 2},
+		{"past the end", pages, 5, "third\n\n" + i18n.T("en", "common.page", "Page", 3, "Pages", 3), 2},
+		{"negative", pages, -1, "first\n\n" + i18n.T("en", "common.page", "Page", 1, "Pages", 3), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, page := pageOf(ctx, tt.pages, tt.page)
+			if text != tt.want || page != tt.wantPage {
+				t.Errorf("pageOf(%d) = %q, %d; want %q, %d", tt.page, text, page, tt.want, tt.wantPage)
+			}
+		})
+	}
+}
+
+func TestPageButtons(t *testing.T) {
+	ctx := i18n.NewContext(context.Background(), "en")
+	data := func(page int) string { return "history:" + strconv.Itoa(page) }
+	previous, next := i18n.T("en", "common.page_previous"), i18n.T("en", "common.page_next")
+
+	tests := []struct {
+		name  string
+		page  int
+		pages int
+		want  []InlineKeyboardButton
+	}{
+		{"single page", 0, 1, nil},
+		{"first", 0, 3, []InlineKeyboardButton{{Text: next, CallbackData: "history:1"}}},
+		{"middle", 1, 3, []InlineKeyboardButton{{Text: previous, CallbackData: "history:0"}, {Text: next, CallbackData: "history:2"}}},
+		{"last", 2, 3, []InlineKeyboardButton{{Text: previous, CallbackData: "history:1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pageButtons(ctx, tt.page, tt.pages, data)
+			if len(got) != len(tt.want) {
+				t.Fatalf("pageButtons = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Text != tt.want[i].Text || got[i].CallbackData != tt.want[i].CallbackData {
+					t.Errorf("button %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"0", 0},
+		{"3", 3},
+		{"", 0},
+		{"-1", 0},
+		{"next", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parsePage(tt.value); got != tt.want {
+			t.Errorf("parsePage(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
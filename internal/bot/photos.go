@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"context"
+	"errors"
+	"time"
+)
+
+// handlePhoto attaches a selfie to the sender's check-in as proof of presence. Forwarded photos
+// may have been taken by someone else, so they are refused like forwarded codes.
+func (b *Bot) handlePhoto(ctx context.Context, msg *Message) error {
+	if msg.IsForwarded() {
+		logging.FromContext(ctx).Warn("Rejected forwarded photo")
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "photo.forwarded"))
+	}
+
+	// The last size is the largest
+	photo := msg.Photo[len(msg.Photo)-1]
+	record, err := b.attendanceService.AttachPhoto(ctx, msg.From.ID, photo.FileID, time.Unix(msg.Date, 0))
+	switch {
+	case errors.Is(err, attendance.ErrNoRecentCheckIn):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "photo.no_check_in", "Minutes", int(b.attendanceService.PhotoWindow().Minutes())))
+	case errors.Is(err, attendance.ErrPhotoAttached):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "photo.already_attached"))
+	case err != nil:
+		return b.replyError(ctx, msg.Chat.ID, err, "action.attach_photo", "Failed to attach photo")
+	}
+
+	logging.FromContext(ctx).Info("Photo attached", "record_id", record.ID)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "photo.attached", "Time", utils.FormatTime(record.Timestamp, "HH:mm")))
+}
+
+// handlePhotoCommand handles the /photo command, which shows the selfie attached to a record so
+// admins can audit presence. Record IDs are the ID column of the CSV report.
+func (b *Bot) handlePhotoCommand(ctx context.Context, msg *Message, args []string) error {
+	if len(args) != 1 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "photo.usage"))
+	}
+	id, err := utils.ParseInteger(args[0])
+	if err != nil || id <= 0 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "photo.usage"))
+	}
+
+	record, err := b.attendanceService.GetAttendanceRecord(ctx, id)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_photo", "Failed to get attendance record", "record_id", id)
+	}
+	if record == nil {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "photo.not_found", "ID", id))
+	}
+	if record.PhotoFileID == "" {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "photo.none", "ID", id))
+	}
+
+	logging.FromContext(ctx).Info("Photo viewed", "record_id", id, "target_user_id", record.UserID)
+	return b.api.SendPhotoByID(ctx, msg.Chat.ID, record.PhotoFileID, tr(ctx, "photo.caption",
+		"Name", b.attendanceService.DisplayName(ctx, record),
+		"Type", attendanceTypeLabel(i18n.FromContext(ctx), record.Type),
+		"Date", record.Date,
+		"Time", utils.FormatTime(record.Timestamp, "HH:mm")))
+}
@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/metrics"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errPanicRecovered is returned by safeHandleUpdate after recovering from a panic
+var errPanicRecovered = errors.New("panic recovered while handling update")
+
+// panicAlertInterval is the minimum time between admin alerts for the same panic site
+const panicAlertInterval = time.Hour
+
+// panicAlerts remembers when each panic site was last reported to the admin chat
+type panicAlerts struct {
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// shouldAlert reports whether site has not been alerted within panicAlertInterval, and records it
+func (p *panicAlerts) shouldAlert(site string, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sent == nil {
+		p.sent = make(map[string]time.Time)
+	}
+	if last, ok := p.sent[site]; ok && now.Sub(last) < panicAlertInterval {
+		return false
+	}
+	p.sent[site] = now
+	return true
+}
+
+// safeHandleUpdate handles an update, turning a panic into an error so the polling loop survives.
+// The update offset has already advanced, so a poisoned update is not redelivered.
+func (b *Bot) safeHandleUpdate(ctx context.Context, update *Update) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		b.reportPanic(ctx, r, panicSite(), debug.Stack())
+		err = errPanicRecovered
+	}()
+
+	return b.handleUpdate(ctx, update)
+}
+
+// reportPanic logs and counts a recovered panic and alerts the admin chat about it
+func (b *Bot) reportPanic(ctx context.Context, value interface{}, site string, stack []byte) {
+	logging.FromContext(ctx).Error("Panic while handling update",
+		"panic", value,
+		"site", site,
+		"stack", string(stack))
+	metrics.UpdatesHandled.Inc("panic")
+
+	b.alertPanic(ctx, site, value)
+}
+
+// alertPanic notifies the admin chat about a panic, at most once per site per panicAlertInterval
+func (b *Bot) alertPanic(ctx context.Context, site string, value interface{}) {
+	if b.config == nil || b.config.AdminChatID == 0 {
+		return
+	}
+	if !b.panics.shouldAlert(site, time.Now()) {
+		return
+	}
+
+	alert := i18n.T(i18n.Default, "panic.alert", "Site", site, "Value", fmt.Sprint(value), "RequestID", logging.RequestID(ctx))
+	if err := b.sendMessage(ctx, b.config.AdminChatID, alert); err != nil {
+		logging.FromContext(ctx).Error("Failed to send panic alert", "error", err)
+	}
+}
+
+// panicSite returns file:line of the function that panicked, called from a deferred recover.
+// It must be called directly in the deferred function, as it skips a fixed number of frames.
+func panicSite() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	// Skip the runtime's panic machinery to reach the first application frame
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/pkg/models"
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestStartSurvivesHandlerPanic makes attendance handling panic for two users: the bot keeps
+// answering others, alerts the admin chat once for the panic site and still saves the offset
+func TestStartSurvivesHandlerPanic(t *testing.T) {
+	tb := newTestBot(t)
+	tb.service.AddAttendanceHook(func(record *models.AttendanceRecord) {
+		panic("hook failed")
+	})
+
+	code, err := attendance.NewTOTPService(testSecret).Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	tb.telegram.push(message(31, 201, code), message(32, 202, code), message(33, 203, "/start"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tb.Start(ctx) }()
+
+	waitFor(t, "the updates to be handled", func() bool {
+		return len(tb.telegram.messagesTo(203)) == 1 && tb.telegram.polled.Load() == 34
+	})
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Start() = %v, want nil", err)
+	}
+
+	alerts := tb.telegram.messagesTo(testAdminChatID)
+	if len(alerts) != 1 {
+		t.Fatalf("admin alerts = %d, want 1: %q", len(alerts), alerts)
+	}
+	if !strings.Contains(alerts[0], "hook failed") || !strings.Contains(alerts[0], "recover_test.go") {
+		t.Errorf("alert does not name the panic and its site:\n%s", alerts[0])
+	}
+
+	lastUpdateID, err := tb.service.GetLastUpdateID(context.Background())
+	if err != nil {
+		t.Fatalf("GetLastUpdateID: %v", err)
+	}
+	if lastUpdateID != 33 {
+		t.Errorf("saved update offset = %d, want 33", lastUpdateID)
+	}
+}
@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// handleRemind handles the /remind command, with which users opt in to reminders before their
+// shift starts and when it ends. Without arguments it shows whether reminders are on.
+func (b *Bot) handleRemind(ctx context.Context, msg *Message, args []string) error {
+	if len(args) == 0 {
+		return b.sendReminderStatus(ctx, msg)
+	}
+
+	switch {
+	case strings.ToLower(args[0]) == "on" && len(args) <= 2:
+		lead := attendance.DefaultReminderLead
+		if len(args) == 2 {
+			var err error
+			if lead, err = strconv.Atoi(args[1]); err != nil {
+				return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "remind.invalid_lead", "Max", attendance.MaxReminderLead))
+			}
+		}
+		return b.handleRemindOn(ctx, msg, lead)
+	case strings.ToLower(args[0]) == "off" && len(args) == 1:
+		return b.handleRemindOff(ctx, msg)
+	default:
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "remind.usage"))
+	}
+}
+
+// sendReminderStatus tells the user whether their reminders are on and when they are sent
+func (b *Bot) sendReminderStatus(ctx context.Context, msg *Message) error {
+	preference, err := b.attendanceService.GetReminderPreference(ctx, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_reminder", "Failed to get reminder preference")
+	}
+	if preference == nil {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "remind.status_off")+"\n\n"+tr(ctx, "remind.usage"))
+	}
+
+	shift, err := b.attendanceService.GetUserShift(ctx, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_reminder", "Failed to get shift")
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "remind.status_on",
+		"Lead", preference.LeadMinutes,
+		"Shift", describeShift(ctx, shift))+"\n\n"+tr(ctx, "remind.usage"))
+}
+
+// handleRemindOn turns reminders on, or changes their lead time
+func (b *Bot) handleRemindOn(ctx context.Context, msg *Message, lead int) error {
+	err := b.attendanceService.EnableReminders(ctx, msg.From.ID, lead)
+	if errors.Is(err, attendance.ErrInvalidReminderLead) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "remind.invalid_lead", "Max", attendance.MaxReminderLead))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_reminder", "Failed to enable reminders", "lead_minutes", lead)
+	}
+
+	logging.FromContext(ctx).Info("Reminders enabled", "lead_minutes", lead)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "remind.enabled", "Lead", lead))
+}
+
+// handleRemindOff turns reminders off
+func (b *Bot) handleRemindOff(ctx context.Context, msg *Message) error {
+	removed, err := b.attendanceService.DisableReminders(ctx, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_reminder", "Failed to disable reminders")
+	}
+	if !removed {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "remind.not_enabled"))
+	}
+
+	logging.FromContext(ctx).Info("Reminders disabled")
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "remind.disabled"))
+}
+
+// sendReminders sends the reminders due this minute, each in its user's language
+func (b *Bot) sendReminders(ctx context.Context) {
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+	logger := b.logger.With("request_id", logging.RequestID(ctx))
+
+	reminders, err := b.attendanceService.DueReminders(ctx, utils.Now().Truncate(time.Minute))
+	if err != nil {
+		logger.Error("Failed to get due reminders", "error", err)
+		return
+	}
+	if len(reminders) == 0 {
+		return
+	}
+
+	sent := 0
+	for i, reminder := range reminders {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				logger.Warn("Reminders interrupted", "sent", sent, "remaining", len(reminders)-i)
+				return
+			case <-time.After(broadcastInterval):
+			}
+		}
+
+		text := i18n.T(b.languageOf(ctx, reminder.UserID), "reminder."+reminder.Type,
+			"Shift", reminder.Shift.Name,
+			"Start", reminder.Shift.Start,
+			"End", reminder.Shift.End)
+		// Users who never started a private chat with the bot cannot be reminded
+		if err := b.sendBroadcastMessage(ctx, reminder.UserID, text); err != nil {
+			logger.Info("Failed to send reminder", "target_user_id", reminder.UserID, "type", reminder.Type, "error", err)
+			continue
+		}
+		sent++
+	}
+
+	logger.Info("Reminders sent", "due", len(reminders), "sent", sent)
+}
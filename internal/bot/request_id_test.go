@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of handler goroutines
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+// entries returns the JSON log lines written so far
+func (s *syncBuffer) entries(t *testing.T) []map[string]any {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(s.buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("bad log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// TestRequestIDOnServiceLogs handles updates from two users at once and checks the service's
+// log lines carry the request ID of their update, with the update and user IDs
+func TestRequestIDOnServiceLogs(t *testing.T) {
+	tb := newTestBot(t)
+	var logs syncBuffer
+	tb.logger = slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	code, err := attendance.NewTOTPService(testSecret).Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	stop := tb.run(t)
+	tb.telegram.push(message(91, 901, code), message(92, 902, code), message(93, 903, "/start"))
+	waitFor(t, "the replies", func() bool {
+		return len(tb.telegram.messagesTo(901)) == 1 && len(tb.telegram.messagesTo(902)) == 1 && len(tb.telegram.messagesTo(903)) == 1
+	})
+	stop()
+
+	wantUser := map[float64]float64{91: 901, 92: 902, 93: 903}
+	requestIDs := make(map[float64]string)
+	validID := regexp.MustCompile(`^[0-9a-f]{8}$`)
+	recorded := 0
+	for _, entry := range logs.entries(t) {
+		updateID, ok := entry["update_id"].(float64)
+		if !ok {
+			continue
+		}
+		requestID, _ := entry["request_id"].(string)
+		if !validID.MatchString(requestID) {
+			t.Errorf("line %q has request_id %q, want 8 hex digits", entry["msg"], requestID)
+		}
+		if entry["user_id"] != wantUser[updateID] {
+			t.Errorf("line %q of update %v has user_id %v, want %v", entry["msg"], updateID, entry["user_id"], wantUser[updateID])
+		}
+		if first, ok := requestIDs[updateID]; ok && first != requestID {
+			t.Errorf("update %v logged request IDs %q and %q, want one", updateID, first, requestID)
+		}
+		requestIDs[updateID] = requestID
+
+		switch entry["msg"] {
+		case "Attendance recorded":
+			recorded++
+		case "Command handled":
+			if entry["command"] != "/start" {
+				t.Errorf("command line has command %v, want /start", entry["command"])
+			}
+		}
+	}
+
+	if recorded != 2 {
+		t.Errorf("service lines with a request ID = %d, want 2 \"Attendance recorded\"", recorded)
+	}
+	if len(requestIDs) != 3 {
+		t.Fatalf("updates with a request ID = %d, want 3", len(requestIDs))
+	}
+	if requestIDs[91] == requestIDs[92] || requestIDs[92] == requestIDs[93] || requestIDs[91] == requestIDs[93] {
+		t.Errorf("request IDs are not unique per update: %v", requestIDs)
+	}
+}
@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// handleRotateSecret handles the /rotatesecret command, which replaces the shared TOTP secret and
+// sends the new QR code to the users who recorded attendance recently. Codes of the old secret
+// keep working during the grace period, so users can re-scan at their own pace.
+func (b *Bot) handleRotateSecret(ctx context.Context, msg *Message, args []string) error {
+	if len(args) != 1 || !strings.EqualFold(args[0], "confirm") {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "rotate.explain", "Days", b.config.TOTPRotationGrace))
+	}
+
+	grace := time.Duration(b.config.TOTPRotationGrace) * 24 * time.Hour
+	graceUntil, err := b.attendanceService.RotateSecret(ctx, msg.From.ID, grace)
+	if errors.Is(err, attendance.ErrEncryptionKeyMissing) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "rotate.encryption_key_missing"))
+	}
+	if errors.Is(err, attendance.ErrRotationInProgress) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "rotate.in_progress", "Until", formatRotationTime(graceUntil)))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.rotate_secret", "Failed to rotate TOTP secret")
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Warn("TOTP secret rotated",
+		"audit", models.AuditTOTPRotated,
+		"grace_until", graceUntil)
+
+	userIDs, err := b.attendanceService.GetRotationRecipients(ctx)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_rotation_recipients", "Failed to get users to notify")
+	}
+	if err := b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "rotate.done", "Until", formatRotationTime(graceUntil), "Count", len(userIDs))); err != nil {
+		logger.Error("Failed to confirm secret rotation", "error", err)
+	}
+
+	sent, failed := b.sendRotationNotices(ctx, userIDs, graceUntil)
+	logger.Info("New TOTP secret sent", "sent", sent, "failed", len(failed))
+	if len(failed) == 0 {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "rotate.notified", "Sent", sent))
+	}
+
+	ids := make([]string, len(failed))
+	for i, userID := range failed {
+		ids[i] = strconv.FormatInt(userID, 10)
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "rotate.notified_failed", "Sent", sent, "Failed", len(failed),
+		"UserIDs", strings.Join(ids, ", ")))
+}
+
+// sendRotationNotices sends each user the QR code of the new secret with re-enrollment
+// instructions in their language, returning how many were sent and who could not be reached
+func (b *Bot) sendRotationNotices(ctx context.Context, userIDs []int64, graceUntil time.Time) (int, []int64) {
+	logger := logging.FromContext(ctx)
+
+	png, err := b.attendanceService.EnrollmentQRCode(ctx)
+	if err != nil {
+		logger.Error("Failed to generate QR code of the new secret", "error", err)
+		return 0, userIDs
+	}
+
+	sent := 0
+	var failed []int64
+	for i, userID := range userIDs {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				logger.Warn("Rotation notices interrupted", "sent", sent, "remaining", len(userIDs)-i)
+				return sent, append(failed, userIDs[i:]...)
+			case <-time.After(broadcastInterval):
+			}
+		}
+
+		lang := b.languageOf(ctx, userID)
+		caption := i18n.T(lang, "rotate.notice", "Until", utils.FormatDateIn(graceUntil, "dd MMMM yyyy", lang),
+			"Digits", b.attendanceService.OTPDigits())
+		if err := b.api.SendPhoto(ctx, userID, bytes.NewReader(png), "attendance_qr.png", caption); err != nil {
+			logger.Info("Failed to send new TOTP secret", "target_user_id", userID, "error", err)
+			failed = append(failed, userID)
+			continue
+		}
+		sent++
+	}
+	return sent, failed
+}
+
+// formatRotationTime renders the end of a rotation grace period for the replies to /rotatesecret
+func formatRotationTime(t time.Time) string {
+	return utils.FormatDate(t, "yyyy-MM-dd") + " " + utils.FormatTime(t, "HH:mm")
+}
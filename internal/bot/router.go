@@ -0,0 +1,325 @@
+package bot
+
+import (
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/metrics"
+	"attendance-bot/pkg/models"
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Command rate limit per user. Replies stop once a user exceeds it, until the window ends.
+const (
+	commandRateLimit  = 20
+	commandRateWindow = time.Minute
+)
+
+// commandHandler handles a command. args are the words after the command.
+type commandHandler func(b *Bot, ctx context.Context, msg *Message, args []string) error
+
+// commandAccess says who may run a command
+type commandAccess int
+
+const (
+	accessAnyone     commandAccess = iota
+	accessAdmin                    // Admins, in any chat
+	accessSuperAdmin               // SUPER_ADMIN_ID only
+	accessAdminChat                // Admins, in the admin chat only
+)
+
+// commandRoute describes a command and the checks made before its handler runs
+type commandRoute struct {
+	command  string
+	handler  commandHandler
+	access   commandAccess
+	inGroups bool // Answered in group chats other than the admin chat
+	unknown  bool // Set on the fallback route of commands that are not registered
+}
+
+// commandMiddleware wraps the handler of a route with a concern shared by all commands
+type commandMiddleware func(route commandRoute, next commandHandler) commandHandler
+
+// commandRoutes lists the bot's commands. To add a command, write its handler and register it
+// here; access and group chat checks are done by the middleware, not by the handler. Commands
+// in groups concern the sender alone or reveal more than a group should see, so only those
+// marked inGroups are answered there.
+var commandRoutes = []commandRoute{
+	{command: "/start", handler: noArgs((*Bot).handleStart), inGroups: true},
+	{command: "/help", handler: noArgs((*Bot).handleHelp), inGroups: true},
+	{command: "/report", handler: (*Bot).handleReport, inGroups: true},
+	{command: "/history", handler: (*Bot).handleHistory},
+	{command: "/status", handler: noArgs((*Bot).handleStatus)},
+	{command: "/duration", handler: noArgs((*Bot).handleDuration)},
+	{command: "/break", handler: (*Bot).handleBreak},
+	{command: "/who", handler: noArgs((*Bot).handleWho), inGroups: true},
+	{command: "/leave", handler: (*Bot).handleLeave},
+	{command: "/wfh", handler: (*Bot).handleWFH},
+	{command: "/shift", handler: (*Bot).handleShift, access: accessAdmin},
+	{command: "/alias", handler: (*Bot).handleAlias},
+	{command: "/aliasapprove", handler: aliasDecision(true), access: accessAdminChat},
+	{command: "/aliasreject", handler: aliasDecision(false), access: accessAdminChat},
+	{command: "/aliasconflicts", handler: noArgs((*Bot).handleAliasConflicts), access: accessAdminChat},
+	{command: "/fullreport", handler: (*Bot).handleFullReport, access: accessAdmin},
+	{command: "/monthly", handler: (*Bot).handleMonthly, access: accessAdmin},
+	{command: "/overtime", handler: (*Bot).handleOvertime, access: accessAdmin},
+	{command: "/admin", handler: (*Bot).handleAdmin, access: accessSuperAdmin},
+	{command: "/otpfailures", handler: (*Bot).handleOTPFailures, access: accessAdminChat},
+	{command: "/otpunlock", handler: (*Bot).handleOTPUnlock, access: accessAdminChat},
+	{command: "/whoami", handler: noArgs((*Bot).handleWhoami)},
+	{command: "/kiosk", handler: noArgs((*Bot).handleKiosk)},
+	{command: "/enroll", handler: (*Bot).handleEnroll, access: accessAdmin},
+	{command: "/rotatesecret", handler: (*Bot).handleRotateSecret, access: accessSuperAdmin},
+	{command: "/kioskenroll", handler: (*Bot).handleKioskEnroll, access: accessAdminChat},
+	{command: "/forgot", handler: noArgs((*Bot).handleForgot)},
+	{command: "/bypass", handler: (*Bot).handleBypass, access: accessAdminChat},
+	{command: "/archive", handler: (*Bot).handleArchive, access: accessAdminChat},
+	{command: "/anomalies", handler: (*Bot).handleAnomalies, access: accessAdminChat},
+	{command: "/subscribe", handler: (*Bot).handleSubscribe},
+	{command: "/unsubscribe", handler: (*Bot).handleUnsubscribe},
+	{command: "/language", handler: (*Bot).handleLanguage},
+	{command: "/timezone", handler: (*Bot).handleTimezone},
+	{command: "/remind", handler: (*Bot).handleRemind},
+	{command: "/weekly", handler: (*Bot).handleWeekly},
+	{command: "/photo", handler: (*Bot).handlePhotoCommand, access: accessAdmin},
+	{command: "/geofence", handler: (*Bot).handleGeofence, access: accessAdmin},
+	{command: "/fix", handler: (*Bot).handleFix, access: accessAdmin},
+	{command: "/auditlog", handler: (*Bot).handleAuditLog, access: accessAdmin},
+	{command: "/register", handler: noArgs((*Bot).handleRegister), access: accessAdmin, inGroups: true},
+	{command: "/unregister", handler: noArgs((*Bot).handleUnregister), access: accessAdmin, inGroups: true},
+	{command: "/registeruser", handler: (*Bot).handleRegisterUser, access: accessAdmin},
+	{command: "/unregisteruser", handler: (*Bot).handleUnregisterUser, access: accessAdmin},
+	{command: "/employees", handler: noArgs((*Bot).handleEmployees), access: accessAdmin},
+	{command: "/holiday", handler: (*Bot).handleHoliday, access: accessAdmin},
+	{command: "/import", handler: (*Bot).handleImport, access: accessAdmin},
+	{command: "/backup", handler: (*Bot).handleBackup, access: accessAdmin},
+}
+
+// commandRouter runs the handler registered for a command through a middleware chain
+type commandRouter struct {
+	routes     map[string]commandHandler
+	middleware []commandMiddleware
+}
+
+// newCommandRouter creates a router whose handlers run through middleware, the first outermost
+func newCommandRouter(middleware ...commandMiddleware) *commandRouter {
+	return &commandRouter{
+		routes:     make(map[string]commandHandler),
+		middleware: middleware,
+	}
+}
+
+// register adds a route, wrapping its handler in the router's middleware
+func (r *commandRouter) register(routes ...commandRoute) {
+	for _, route := range routes {
+		r.routes[route.command] = r.chain(route)
+	}
+}
+
+// chain wraps the route's handler in the middleware
+func (r *commandRouter) chain(route commandRoute) commandHandler {
+	handler := route.handler
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](route, handler)
+	}
+	return handler
+}
+
+// route runs the command's handler. Unknown commands go through the same middleware, so they
+// are counted, rate limited and refused in groups like the others.
+func (r *commandRouter) route(b *Bot, ctx context.Context, msg *Message, command string, args []string) error {
+	handler, ok := r.routes[command]
+	if !ok {
+		handler = r.chain(commandRoute{command: command, handler: (*Bot).handleUnknownCommand, unknown: true})
+	}
+	return handler(b, ctx, msg, args)
+}
+
+// noArgs adapts a handler that takes no arguments
+func noArgs(handler func(b *Bot, ctx context.Context, msg *Message) error) commandHandler {
+	return func(b *Bot, ctx context.Context, msg *Message, _ []string) error {
+		return handler(b, ctx, msg)
+	}
+}
+
+// aliasDecision adapts handleAliasDecision to approve or reject
+func aliasDecision(approve bool) commandHandler {
+	return func(b *Bot, ctx context.Context, msg *Message, args []string) error {
+		return b.handleAliasDecision(ctx, msg, args, approve)
+	}
+}
+
+// handleUnknownCommand answers commands that are not registered
+func (b *Bot) handleUnknownCommand(ctx context.Context, msg *Message, _ []string) error {
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.unknown_command"))
+}
+
+// recoverCommand turns a panic in a command into an error reply, so the user learns the command
+// failed instead of waiting for an answer
+func recoverCommand(route commandRoute, next commandHandler) commandHandler {
+	return func(b *Bot, ctx context.Context, msg *Message, args []string) (err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			b.reportPanic(ctx, r, panicSite(), debug.Stack())
+
+			reply := tr(ctx, "common.command_failed", "Command", route.command)
+			if requestID := logging.RequestID(ctx); requestID != "" {
+				reply += tr(ctx, "error.reference", "RequestID", requestID)
+			}
+			if sendErr := b.sendMessage(ctx, msg.Chat.ID, reply); sendErr != nil {
+				logging.FromContext(ctx).Error("Failed to reply after panic", "error", sendErr)
+			}
+			err = errPanicRecovered
+		}()
+		return next(b, ctx, msg, args)
+	}
+}
+
+// logCommand tags the logs of a command with its name, counts it and logs how long it took
+func logCommand(route commandRoute, next commandHandler) commandHandler {
+	// Unknown commands share one label to keep the metric's cardinality bounded
+	label := route.command
+	if route.unknown {
+		label = "unknown"
+	}
+
+	return func(b *Bot, ctx context.Context, msg *Message, args []string) error {
+		logger := logging.FromContext(ctx).With("command", route.command)
+		ctx = logging.NewContext(ctx, logger)
+		metrics.Commands.Inc(label)
+
+		start := time.Now()
+		err := next(b, ctx, msg, args)
+		logger.Debug("Command handled", "duration", time.Since(start))
+		return err
+	}
+}
+
+// restrictGroups refuses, in group chats other than the admin chat, commands not meant for groups
+func restrictGroups(route commandRoute, next commandHandler) commandHandler {
+	if route.inGroups {
+		return next
+	}
+	return func(b *Bot, ctx context.Context, msg *Message, args []string) error {
+		if isGroupChat(msg.Chat) && !b.isAdminChat(msg.Chat.ID) {
+			return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "group.private_only", "Command", route.command))
+		}
+		return next(b, ctx, msg, args)
+	}
+}
+
+// limitCommands ignores the commands of users who send more than commandRateLimit per
+// commandRateWindow, telling them once per window
+func limitCommands(route commandRoute, next commandHandler) commandHandler {
+	return func(b *Bot, ctx context.Context, msg *Message, args []string) error {
+		allowed, first := b.commandLimiter.allow(msg.From.ID, time.Now())
+		if allowed {
+			return next(b, ctx, msg, args)
+		}
+
+		if !first {
+			return nil
+		}
+		logging.FromContext(ctx).Warn("Command rate limit exceeded", "limit", commandRateLimit, "window", commandRateWindow)
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.rate_limited"))
+	}
+}
+
+// authorize refuses the command unless the sender or chat has the route's access
+func authorize(route commandRoute, next commandHandler) commandHandler {
+	if route.access == accessAnyone {
+		return next
+	}
+	return func(b *Bot, ctx context.Context, msg *Message, args []string) error {
+		var refusal string
+		switch route.access {
+		case accessAdmin:
+			if !b.isAdmin(ctx, msg.From.ID) {
+				refusal = "common.admin_only"
+			}
+		case accessSuperAdmin:
+			if b.adminRole(ctx, msg.From.ID) != models.RoleSuperAdmin {
+				refusal = "common.super_admin_only"
+			}
+		case accessAdminChat:
+			if !b.isAdminChat(msg.Chat.ID) {
+				refusal = "common.admin_chat_only"
+			} else if !b.isAdmin(ctx, msg.From.ID) {
+				refusal = "common.admin_only"
+			}
+		}
+		if refusal != "" {
+			logging.FromContext(ctx).Info("Command refused", "access", refusal)
+			return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, refusal))
+		}
+		return next(b, ctx, msg, args)
+	}
+}
+
+// endConversation forgets the sender's pending conversation, so text sent after a command is
+// not taken as the answer to an earlier question. The command may start a new one.
+func endConversation(route commandRoute, next commandHandler) commandHandler {
+	return func(b *Bot, ctx context.Context, msg *Message, args []string) error {
+		session, err := b.sessions.Get(ctx, msg.From.ID)
+		if err != nil {
+			logging.FromContext(ctx).Error("Failed to get session", "error", err)
+		}
+		if session != nil {
+			logging.FromContext(ctx).Debug("Conversation abandoned", "state", session.State)
+			b.sessions.Clear(ctx, msg.From.ID)
+		}
+		return next(b, ctx, msg, args)
+	}
+}
+
+// rateLimiter counts events per key in fixed windows. It is safe for concurrent use.
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[int64]*rateWindow
+}
+
+// rateWindow is the count of one key's events since start
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// newRateLimiter creates a limiter allowing limit events per window and key
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[int64]*rateWindow),
+	}
+}
+
+// allow records an event of key and reports whether it is within the limit, and if not whether
+// it is the first event of the window over the limit
+func (l *rateLimiter) allow(key int64, now time.Time) (allowed, first bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		l.pruneLocked(now)
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	w.count++
+	return w.count <= l.limit, w.count == l.limit+1
+}
+
+// pruneLocked drops the windows that have ended
+func (l *rateLimiter) pruneLocked(now time.Time) {
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"context"
+	"fmt"
+	"testing"
+)
+
+// TestCommandAccess sends commands of each access level from the super-admin, an admin added with
+// /admin and an employee, in their private chat and in the admin chat, and checks who is refused
+func TestCommandAccess(t *testing.T) {
+	const (
+		adminID    = 901
+		employeeID = 902
+	)
+	refusals := map[string]string{
+		"common.admin_only":       i18n.T(i18n.Default, "common.admin_only"),
+		"common.super_admin_only": i18n.T(i18n.Default, "common.super_admin_only"),
+		"common.admin_chat_only":  i18n.T(i18n.Default, "common.admin_chat_only"),
+	}
+
+	tests := []struct {
+		name      string
+		command   string
+		userID    int64
+		adminChat bool
+		refusal   string // Catalog key of the expected refusal, empty if the command runs
+	}{
+		{name: "anyone, employee", command: "/help", userID: employeeID},
+
+		{name: "photo, employee", command: "/photo", userID: employeeID, refusal: "common.admin_only"},
+		{name: "photo, admin", command: "/photo", userID: adminID},
+		{name: "enroll, employee", command: "/enroll", userID: employeeID, refusal: "common.admin_only"},
+		{name: "enroll, admin", command: "/enroll", userID: adminID},
+		{name: "enroll, super-admin", command: "/enroll", userID: testAdminID},
+
+		{name: "rotatesecret, employee", command: "/rotatesecret", userID: employeeID, refusal: "common.super_admin_only"},
+		{name: "rotatesecret, admin", command: "/rotatesecret", userID: adminID, refusal: "common.super_admin_only"},
+		{name: "rotatesecret, super-admin", command: "/rotatesecret", userID: testAdminID},
+		{name: "admin, admin", command: "/admin list", userID: adminID, refusal: "common.super_admin_only"},
+
+		{name: "bypass, admin in private", command: "/bypass", userID: adminID, refusal: "common.admin_chat_only"},
+		{name: "bypass, employee in admin chat", command: "/bypass", userID: employeeID, adminChat: true, refusal: "common.admin_only"},
+		{name: "bypass, admin in admin chat", command: "/bypass", userID: adminID, adminChat: true},
+		{name: "archive, employee in admin chat", command: "/archive", userID: employeeID, adminChat: true, refusal: "common.admin_only"},
+		{name: "archive, super-admin in admin chat", command: "/archive", userID: testAdminID, adminChat: true},
+		{name: "otpunlock, employee in admin chat", command: "/otpunlock", userID: employeeID, adminChat: true, refusal: "common.admin_only"},
+		{name: "kioskenroll, employee in admin chat", command: "/kioskenroll", userID: employeeID, adminChat: true, refusal: "common.admin_only"},
+		{name: "aliasconflicts, employee in admin chat", command: "/aliasconflicts", userID: employeeID, adminChat: true, refusal: "common.admin_only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newTestBot(t)
+			if _, err := tb.service.AddAdmin(context.Background(), adminID, testAdminID); err != nil {
+				t.Fatalf("AddAdmin: %v", err)
+			}
+
+			chat := &Chat{ID: tt.userID, Type: "private"}
+			if tt.adminChat {
+				chat = &Chat{ID: testAdminChatID, Type: "supergroup"}
+			}
+			tb.deliver(t, &Message{
+				From: &User{ID: tt.userID, FirstName: fmt.Sprintf("User%d", tt.userID)},
+				Chat: chat,
+				Text: tt.command,
+			})
+
+			reply := tb.telegram.lastMessageTo(t, chat.ID)
+			if tt.refusal != "" {
+				if reply != refusals[tt.refusal] {
+					t.Errorf("%s replied %q, want the %s refusal", tt.command, reply, tt.refusal)
+				}
+				return
+			}
+			for key, refusal := range refusals {
+				if reply == refusal {
+					t.Errorf("%s was refused with %s, want it to run", tt.command, key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// These scenarios run the bot with Start against the fake Telegram, so updates arrive by
+// getUpdates and replies leave through the real TelegramAPI client.
+
+// run starts the bot polling the fake Telegram; the returned stop cancels it, waits for it to
+// return and fails the test on an error
+func (tb *testBot) run(t *testing.T) (stop func()) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tb.Start(ctx) }()
+
+	stopped := false
+	stop = func() {
+		t.Helper()
+		if stopped {
+			return
+		}
+		stopped = true
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Start() = %v, want nil", err)
+		}
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+// awaitMessages waits until n messages or captions were sent to a chat and returns them
+func (tb *testBot) awaitMessages(t *testing.T, chatID int64, n int) []string {
+	t.Helper()
+	waitFor(t, "replies", func() bool { return len(tb.telegram.messagesTo(chatID)) >= n })
+	return tb.telegram.messagesTo(chatID)
+}
+
+// callbackData returns the callback data of the buttons of a sent message, row by row
+func callbackData(call telegramCall) []string {
+	markup, _ := call.Payload["reply_markup"].(map[string]any)
+	rows, _ := markup["inline_keyboard"].([]any)
+
+	var data []string
+	for _, row := range rows {
+		buttons, _ := row.([]any)
+		for _, button := range buttons {
+			fields, _ := button.(map[string]any)
+			if value, ok := fields["callback_data"].(string); ok {
+				data = append(data, value)
+			}
+		}
+	}
+	return data
+}
+
+// TestScenarioAttendanceDay checks a user in and out with their authenticator code; a third code
+// is refused as the day is complete
+func TestScenarioAttendanceDay(t *testing.T) {
+	tb := newTestBot(t)
+	stop := tb.run(t)
+	const userID = 501
+
+	code, err := attendance.NewTOTPService(testSecret).Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	tb.telegram.push(message(61, userID, code), message(62, userID, code), message(63, userID, code))
+
+	replies := tb.awaitMessages(t, userID, 3)
+	stop()
+
+	wants := []string{"**Absen Masuk** tercatat!", "**Absen Pulang** tercatat!", i18n.T(i18n.Default, "attendance.complete")}
+	for i, want := range wants {
+		if !strings.Contains(replies[i], want) {
+			t.Errorf("reply %d = %q, want it to contain %q", i+1, replies[i], want)
+		}
+	}
+	for _, call := range tb.telegram.sent("sendMessage") {
+		if call.chatID() != userID {
+			t.Errorf("message sent to chat %d, want %d: %q", call.chatID(), userID, call.text())
+		}
+	}
+
+	records, err := tb.repo.ListAttendance(context.Background(), userID, "")
+	if err != nil {
+		t.Fatalf("ListAttendance: %v", err)
+	}
+	today := utils.Now().Format("2006-01-02")
+	if len(records) != 2 {
+		t.Fatalf("records = %d, want 2", len(records))
+	}
+	for i, wantType := range []string{"check_in", "check_out"} {
+		record := records[i]
+		if record.Type != wantType || record.Date != today || record.Source != models.SourceOTP {
+			t.Errorf("record %d = %s on %s from %s, want %s on %s from %s",
+				i+1, record.Type, record.Date, record.Source, wantType, today, models.SourceOTP)
+		}
+	}
+	if records[1].Timestamp.Before(records[0].Timestamp) {
+		t.Errorf("check-out at %v is before check-in at %v", records[1].Timestamp, records[0].Timestamp)
+	}
+
+	lastUpdateID, err := tb.service.GetLastUpdateID(context.Background())
+	if err != nil {
+		t.Fatalf("GetLastUpdateID: %v", err)
+	}
+	if lastUpdateID != 63 {
+		t.Errorf("saved update offset = %d, want 63", lastUpdateID)
+	}
+}
+
+// TestScenarioFullReport walks an admin through /fullreport: the typed date range, the CSV format
+// button and the report uploaded as a multipart document
+func TestScenarioFullReport(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, utils.Location)
+	}
+	if _, _, err := tb.repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{
+		{UserID: 601, FirstName: "Sari", Timestamp: at(4, 8, 50), Type: "check_in", Date: "2024-03-04"},
+		{UserID: 601, FirstName: "Sari", Timestamp: at(4, 17, 5), Type: "check_out", Date: "2024-03-04"},
+		{UserID: 602, FirstName: "Budi", Timestamp: at(5, 9, 15), Type: "check_in", Date: "2024-03-05"},
+		{UserID: 602, FirstName: "Budi", Timestamp: at(6, 8, 0), Type: "check_in", Date: "2024-03-06"},
+	}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+	tb.run(t)
+
+	tb.telegram.push(message(71, testAdminID, "/fullreport"))
+	tb.awaitMessages(t, testAdminID, 1)
+	tb.telegram.push(message(72, testAdminID, "2024-03-04 2024-03-05"))
+	tb.awaitMessages(t, testAdminID, 2)
+
+	prompts := tb.telegram.sent("sendMessage")
+	pickFormat := prompts[len(prompts)-1]
+	if want := i18n.T(i18n.Default, "fullreport.pick_format", "Start", "2024-03-04", "End", "2024-03-05"); pickFormat.text() != want {
+		t.Fatalf("reply to the date range = %q, want %q", pickFormat.text(), want)
+	}
+	const csvButton = "fullreport:csv:2024-03-04:2024-03-05"
+	found := false
+	for _, data := range callbackData(pickFormat) {
+		found = found || data == csvButton
+	}
+	if !found {
+		t.Fatalf("format buttons = %q, want one with %q", callbackData(pickFormat), csvButton)
+	}
+
+	tb.telegram.push(Update{UpdateID: 73, CallbackQuery: &CallbackQuery{
+		ID:      "73",
+		From:    &User{ID: testAdminID, FirstName: "Admin"},
+		Message: &Message{MessageID: 2, Chat: &Chat{ID: testAdminID, Type: "private"}},
+		Data:    csvButton,
+	}})
+	waitFor(t, "the report", func() bool { return len(tb.telegram.sent("sendDocument")) == 1 })
+
+	if got := tb.telegram.attemptsOf("answerCallbackQuery"); got != 1 {
+		t.Errorf("answerCallbackQuery calls = %d, want 1", got)
+	}
+	edits := tb.telegram.sent("editMessageText")
+	if len(edits) != 1 || edits[0].Payload["message_id"] != float64(2) {
+		t.Errorf("editMessageText calls = %v, want the format message replaced by the progress", edits)
+	}
+
+	document := tb.telegram.sent("sendDocument")[0]
+	if document.chatID() != testAdminID {
+		t.Errorf("report sent to chat %d, want %d", document.chatID(), testAdminID)
+	}
+	if want := "attendance_2024-03-04_to_2024-03-05.csv"; document.Filename != want {
+		t.Errorf("report filename = %q, want %q", document.Filename, want)
+	}
+	if caption := document.text(); !strings.Contains(caption, "2024-03-04") || !strings.Contains(caption, "2024-03-05") {
+		t.Errorf("report caption does not name the period:\n%s", caption)
+	}
+
+	records, err := tb.service.GetAttendanceReportRange(ctx, "2024-03-04", "2024-03-05")
+	if err != nil {
+		t.Fatalf("GetAttendanceReportRange: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("records in range = %d, want 3", len(records))
+	}
+	want, err := tb.csvGenerator.GenerateAttendanceReport(records, nil)
+	if err != nil {
+		t.Fatalf("GenerateAttendanceReport: %v", err)
+	}
+	if !bytes.Equal(document.File, want) {
+		t.Errorf("uploaded report differs from the generated CSV\ngot:\n%s\nwant:\n%s", document.File, want)
+	}
+	if bytes.Contains(document.File, []byte("2024-03-06")) {
+		t.Errorf("report includes a record after the period:\n%s", document.File)
+	}
+}
+
+// TestScenarioRateLimited answers a check-in reply with 429: the client waits retry_after and
+// resends it, and the check-in is recorded once
+func TestScenarioRateLimited(t *testing.T) {
+	tb := newTestBot(t)
+	stop := tb.run(t)
+	const userID = 701
+
+	code, err := attendance.NewTOTPService(testSecret).Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	tb.telegram.fail("sendMessage", 429)
+	start := time.Now()
+	tb.telegram.push(message(81, userID, code))
+
+	replies := tb.awaitMessages(t, userID, 1)
+	elapsed := time.Since(start)
+	stop()
+
+	if got := tb.telegram.attemptsOf("sendMessage"); got != 2 {
+		t.Errorf("sendMessage attempts = %d, want 2", got)
+	}
+	if len(replies) != 1 || !strings.Contains(replies[0], "**Absen Masuk** tercatat!") {
+		t.Errorf("replies = %q, want one check-in confirmation", replies)
+	}
+	if elapsed < time.Second {
+		t.Errorf("reply resent after %v, want at least the 1s retry_after", elapsed)
+	}
+
+	records, err := tb.repo.ListAttendance(context.Background(), userID, "")
+	if err != nil {
+		t.Fatalf("ListAttendance: %v", err)
+	}
+	if len(records) != 1 || records[0].Type != "check_in" {
+		t.Errorf("records = %+v, want one check-in", records)
+	}
+}
@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/scheduler"
+	"attendance-bot/internal/utils"
+	"context"
+)
+
+// newScheduler registers the configured scheduled reports, evaluated in utils.Location
+func (b *Bot) newScheduler() *scheduler.Scheduler {
+	jobs := scheduler.New(utils.Location, b.logger)
+	jobs.SetCalendar(holidayCalendar{bot: b})
+
+	// Deliver the daily report to the admin chat and subscribers
+	if at, ok := b.config.DailyReportClock(); ok {
+		jobs.Add(scheduler.Job{
+			Name:     "daily_report",
+			Schedule: scheduler.Daily(at.Hour, at.Minute),
+			Run:      b.sendDailyReport,
+		})
+	}
+
+	// Close or remind about check-ins nobody checked out of
+	if at, ok := b.config.AutoCheckoutClock(); ok {
+		jobs.Add(scheduler.Job{
+			Name:     "auto_checkout",
+			Schedule: scheduler.Daily(at.Hour, at.Minute),
+			Run:      b.runAutoCheckout,
+		})
+	}
+
+	// Send the day's late arrivals in one message
+	if at, ok := b.config.LateAlertDigestClock(); ok {
+		jobs.Add(scheduler.Job{
+			Name:         "late_digest",
+			Schedule:     scheduler.Daily(at.Hour, at.Minute),
+			Run:          b.sendLateDigest,
+			SkipHolidays: true,
+		})
+	}
+
+	// Remind users who opted in to check in and out; their shifts decide when
+	jobs.Add(scheduler.Job{
+		Name:         "reminders",
+		Schedule:     scheduler.EveryMinute(),
+		Run:          b.sendReminders,
+		SkipHolidays: true,
+	})
+
+	// Send employees the summary of their past week
+	if schedule, ok := b.config.WeeklySummaryScheduled(); ok {
+		jobs.Add(scheduler.Job{
+			Name:     "weekly_summary",
+			Schedule: schedule,
+			Run:      b.sendWeeklySummaries,
+		})
+	}
+
+	// Forget conversations nobody finished
+	jobs.Add(scheduler.Job{
+		Name:     "session_cleanup",
+		Schedule: scheduler.Hourly(),
+		Run:      b.cleanupSessions,
+	})
+
+	// Post the daily report to the configured group chat
+	if schedule, ok := b.config.ReportBroadcast(); ok {
+		jobs.Add(scheduler.Job{
+			Name:         "report_broadcast",
+			Schedule:     schedule,
+			Run:          b.sendGroupReport,
+			SkipHolidays: b.config.ReportSkipHolidays,
+		})
+	}
+
+	// Back up the SQLite database
+	if schedule, ok := b.config.BackupScheduled(); ok && b.attendanceService.BackupsEnabled() {
+		jobs.Add(scheduler.Job{
+			Name:     "backup",
+			Schedule: schedule,
+			Run:      b.runScheduledBackup,
+		})
+	}
+
+	return jobs
+}
+
+// sendGroupReport posts today's report to REPORT_CHAT_ID
+func (b *Bot) sendGroupReport(ctx context.Context) {
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+	logger := b.logger.With("request_id", logging.RequestID(ctx), "chat_id", b.config.ReportChatID)
+
+	report, err := b.attendanceService.GenerateAttendanceReport(ctx, i18n.Default)
+	if err != nil {
+		logger.Error("Failed to generate scheduled report", "error", err)
+		return
+	}
+
+	if err := b.sendBroadcastMessage(ctx, b.config.ReportChatID, report); err != nil {
+		logger.Error("Failed to send scheduled report", "error", err)
+		return
+	}
+
+	logger.Info("Scheduled report sent")
+}
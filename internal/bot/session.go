@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"attendance-bot/internal/logging"
+	"attendance-bot/pkg/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// sessionTTL is how long a conversation waits for the user's next input before it is forgotten
+const sessionTTL = 10 * time.Minute
+
+// Conversation states. To add a multi-step flow, define a state for each step here, start it
+// with b.sessions.Set from the command that opens it, and register the handler for the input of
+// each step in sessionHandlers. The handler receives the session and decodes any payload set
+// with it; it should Set the next step's state, carrying what was entered so far, or Clear the
+// session to end the flow.
+const (
+	stateAwaitingDateRange   = "awaiting_date_range"   // /fullreport: date range
+	stateKioskAwaitingCode   = "kiosk_awaiting_code"   // Kiosk: the picked employee's code, keyed by the kiosk chat
+	stateLeaveAwaitingType   = "leave_awaiting_type"   // /leave new: leave type
+	stateLeaveAwaitingDates  = "leave_awaiting_dates"  // /leave new: first and last day
+	stateLeaveAwaitingReason = "leave_awaiting_reason" // /leave new: reason, which submits the request
+)
+
+// sessionHandler handles a text message from a user whose session is in a given state
+type sessionHandler func(b *Bot, ctx context.Context, msg *Message, session *Session) error
+
+// sessionHandlers maps each conversation state to the handler for the user's next message
+var sessionHandlers = map[string]sessionHandler{
+	stateAwaitingDateRange:   (*Bot).handleFullReportInput,
+	stateKioskAwaitingCode:   (*Bot).handleKioskCode,
+	stateLeaveAwaitingType:   (*Bot).handleLeaveTypeInput,
+	stateLeaveAwaitingDates:  (*Bot).handleLeaveDatesInput,
+	stateLeaveAwaitingReason: (*Bot).handleLeaveReasonInput,
+}
+
+// Session is a user's position in a multi-step conversation
+type Session struct {
+	State   string          // Conversation state awaiting input
+	Payload json.RawMessage // Flow-specific data carried between steps, nil if none
+	Expires time.Time
+}
+
+// Decode unmarshals the session's payload into v
+func (s *Session) Decode(v interface{}) error {
+	if len(s.Payload) == 0 {
+		return fmt.Errorf("session %s has no payload", s.State)
+	}
+	if err := json.Unmarshal(s.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s session payload: %w", s.State, err)
+	}
+	return nil
+}
+
+// sessionStore persists sessions; attendance.Service implements it
+type sessionStore interface {
+	SaveSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, userID int64) (*models.Session, error)
+	DeleteSession(ctx context.Context, userID int64) error
+}
+
+// sessionManager keeps one conversation session per user in the database, so conversations
+// survive restarts and are shared by every instance of the bot. Expired sessions are ignored
+// and removed by the session_cleanup job.
+type sessionManager struct {
+	store sessionStore
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// newSessionManager creates a session store whose sessions expire after ttl
+func newSessionManager(store sessionStore, ttl time.Duration) *sessionManager {
+	return &sessionManager{
+		store: store,
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+// Get returns the user's session, or nil if there is none or it has expired
+func (m *sessionManager) Get(ctx context.Context, userID int64) (*Session, error) {
+	stored, err := m.store.GetSession(ctx, userID)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+
+	session := &Session{State: stored.State, Expires: stored.ExpiresAt}
+	if stored.Payload != "" {
+		session.Payload = json.RawMessage(stored.Payload)
+	}
+	return session, nil
+}
+
+// Set starts or advances the user's conversation, resetting its expiry. payload, if not nil, is
+// stored as JSON and read back with Session.Decode.
+func (m *sessionManager) Set(ctx context.Context, userID int64, state string, payload interface{}) error {
+	session := &models.Session{
+		UserID:    userID,
+		State:     state,
+		ExpiresAt: m.now().Add(m.ttl),
+	}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s session payload: %w", state, err)
+		}
+		session.Payload = string(data)
+	}
+
+	return m.store.SaveSession(ctx, session)
+}
+
+// Clear ends the user's conversation. A failure is only logged: the session expires anyway.
+func (m *sessionManager) Clear(ctx context.Context, userID int64) {
+	if err := m.store.DeleteSession(ctx, userID); err != nil {
+		logging.FromContext(ctx).Error("Failed to clear session", "session_user_id", userID, "error", err)
+	}
+}
+
+// continueConversation passes msg to the handler of the pending conversation of key, the user or
+// kiosk chat, and reports whether there was one. Sessions in an unknown state, e.g. of a flow
+// removed since, are cleared.
+func (b *Bot) continueConversation(ctx context.Context, key int64, msg *Message) (bool, error) {
+	session, err := b.sessions.Get(ctx, key)
+	if err != nil {
+		return true, b.replyError(ctx, msg.Chat.ID, err, "action.continue_conversation", "Failed to get session")
+	}
+	if session == nil {
+		return false, nil
+	}
+
+	handler, ok := sessionHandlers[session.State]
+	if !ok {
+		b.sessions.Clear(ctx, key)
+		return false, nil
+	}
+	return true, handler(b, ctx, msg, session)
+}
+
+// cleanupSessions removes expired sessions, run by the session_cleanup job
+func (b *Bot) cleanupSessions(ctx context.Context) {
+	removed, err := b.attendanceService.PruneSessions(ctx)
+	if err != nil {
+		b.logger.Error("Failed to remove expired sessions", "error", err)
+		return
+	}
+	if removed > 0 {
+		b.logger.Info("Expired sessions removed", "count", removed)
+	}
+}
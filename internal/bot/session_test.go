@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSessionManager(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	sessions := newSessionManager(tb.service, time.Minute)
+
+	if session, err := sessions.Get(ctx, 1); err != nil || session != nil {
+		t.Fatalf("Get() without a session = %v, %v, want nil", session, err)
+	}
+
+	draft := leaveDraft{Type: "cuti", StartDate: "2024-03-04"}
+	if err := sessions.Set(ctx, 1, stateLeaveAwaitingDates, draft); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	session, err := sessions.Get(ctx, 1)
+	if err != nil || session == nil {
+		t.Fatalf("Get() = %v, %v, want the session", session, err)
+	}
+	var decoded leaveDraft
+	if err := session.Decode(&decoded); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if session.State != stateLeaveAwaitingDates || decoded != draft {
+		t.Errorf("session = %s %+v, want %s %+v", session.State, decoded, stateLeaveAwaitingDates, draft)
+	}
+
+	// Setting the next step replaces the state and payload
+	if err := sessions.Set(ctx, 1, stateAwaitingDateRange, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	session, err = sessions.Get(ctx, 1)
+	if err != nil || session == nil {
+		t.Fatalf("Get() = %v, %v, want the session", session, err)
+	}
+	if session.State != stateAwaitingDateRange || session.Payload != nil {
+		t.Errorf("session = %s %s, want %s without payload", session.State, session.Payload, stateAwaitingDateRange)
+	}
+	if err := session.Decode(&decoded); err == nil {
+		t.Error("Decode() of a session without payload succeeded, want an error")
+	}
+
+	sessions.Clear(ctx, 1)
+	if session, err := sessions.Get(ctx, 1); err != nil || session != nil {
+		t.Errorf("Get() after Clear = %v, %v, want nil", session, err)
+	}
+}
+
+func TestSessionManagerExpiry(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := context.Background()
+	sessions := newSessionManager(tb.service, time.Minute)
+
+	sessions.now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+	if err := sessions.Set(ctx, 1, stateAwaitingDateRange, nil); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if session, err := sessions.Get(ctx, 1); err != nil || session != nil {
+		t.Errorf("Get() of an expired session = %v, %v, want nil", session, err)
+	}
+}
+
+// TestConcurrentConversations runs the /leave new flow for many users at once, as the workers do
+// for different users: each user's steps stay in their own session and submit their own request
+func TestConcurrentConversations(t *testing.T) {
+	tb := newTestBot(t)
+	ctx := logging.NewContext(context.Background(), tb.logger)
+	const users = 12
+
+	var wg sync.WaitGroup
+	for i := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			userID := int64(2000 + i)
+			date := utils.Now().AddDate(0, 0, 10+i).Format("2006-01-02")
+
+			for step, text := range []string{"/leave new", "cuti", date, fmt.Sprintf("reason %d", i)} {
+				update := message(int64(100*i+step+1)+10_000, userID, text)
+				if err := tb.handleUpdate(ctx, &update); err != nil {
+					t.Errorf("user %d step %d: %v", userID, step, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range users {
+		userID := int64(2000 + i)
+		leaves, err := tb.service.GetUpcomingLeaves(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetUpcomingLeaves: %v", err)
+		}
+		date := utils.Now().AddDate(0, 0, 10+i).Format("2006-01-02")
+		if len(leaves) != 1 {
+			t.Errorf("user %d has %d leave requests, want 1; replies %q", userID, len(leaves), tb.telegram.messagesTo(userID))
+			continue
+		}
+		leave := leaves[0]
+		if leave.StartDate != date || leave.Reason != fmt.Sprintf("reason %d", i) || leave.Type != "cuti" {
+			t.Errorf("user %d leave = %s %s %q, want cuti %s %q", userID, leave.Type, leave.StartDate, leave.Reason, date, fmt.Sprintf("reason %d", i))
+		}
+		if session, err := tb.sessions.Get(ctx, userID); err != nil || session != nil {
+			t.Errorf("user %d session after submitting = %v, %v, want none", userID, session, err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// handleShift handles the /shift command, with which admins manage shifts and assign users to them
+func (b *Bot) handleShift(ctx context.Context, msg *Message, args []string) error {
+	if len(args) == 0 || args[0] == "list" {
+		return b.handleShiftList(ctx, msg)
+	}
+
+	switch {
+	case args[0] == "set" && (len(args) == 4 || len(args) == 5):
+		grace := 0
+		if len(args) == 5 {
+			var err error
+			if grace, err = strconv.Atoi(args[4]); err != nil {
+				return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "shift.invalid_grace"))
+			}
+		}
+		return b.handleShiftSet(ctx, msg, &models.Shift{
+			Name:         strings.ToLower(args[1]),
+			Start:        args[2],
+			End:          args[3],
+			GraceMinutes: grace,
+		})
+	case args[0] == "delete" && len(args) == 2:
+		return b.handleShiftDelete(ctx, msg, strings.ToLower(args[1]))
+	case (args[0] == "assign" && len(args) == 3) || (args[0] == "unassign" && len(args) == 2):
+		userID, err := utils.ParseInteger(args[1])
+		if err != nil || !utils.IsValidTelegramUserID(userID) {
+			return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "common.invalid_user_id"))
+		}
+		if args[0] == "assign" {
+			return b.handleShiftAssign(ctx, msg, userID, strings.ToLower(args[2]))
+		}
+		return b.handleShiftUnassign(ctx, msg, userID)
+	default:
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "shift.usage"))
+	}
+}
+
+// handleShiftList lists the shifts with the number of users on each
+func (b *Bot) handleShiftList(ctx context.Context, msg *Message) error {
+	shifts, err := b.attendanceService.GetShifts(ctx)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_shifts", "Failed to get shifts")
+	}
+
+	var message strings.Builder
+	message.WriteString(tr(ctx, "shift.list_title") + "\n\n")
+	hasDefault := false
+	for _, shift := range shifts {
+		message.WriteString(tr(ctx, "shift.list_entry", "Shift", describeShift(ctx, shift), "Users", shift.Users) + "\n")
+		hasDefault = hasDefault || shift.Name == models.DefaultShiftName
+	}
+	if !hasDefault {
+		message.WriteString(tr(ctx, "shift.list_builtin", "Shift", describeShift(ctx, models.BuiltinShift)) + "\n")
+	}
+	message.WriteString("\n" + tr(ctx, "shift.usage"))
+
+	return b.sendMessage(ctx, msg.Chat.ID, message.String())
+}
+
+// handleShiftSet creates or updates a shift
+func (b *Bot) handleShiftSet(ctx context.Context, msg *Message, shift *models.Shift) error {
+	err := b.attendanceService.SaveShift(ctx, shift)
+	if errors.Is(err, attendance.ErrInvalidShift) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "shift.invalid", "MaxGrace", attendance.MaxShiftGraceMinutes))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_shift", "Failed to save shift", "shift", shift.Name)
+	}
+
+	logging.FromContext(ctx).Warn("Shift saved",
+		"audit", "shift_saved",
+		"shift", shift.Name,
+		"start", shift.Start,
+		"end", shift.End,
+		"grace_minutes", shift.GraceMinutes)
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "shift.saved", "Shift", describeShift(ctx, *shift)))
+}
+
+// handleShiftDelete deletes a shift without assigned users
+func (b *Bot) handleShiftDelete(ctx context.Context, msg *Message, name string) error {
+	deleted, err := b.attendanceService.DeleteShift(ctx, name)
+	if errors.Is(err, attendance.ErrShiftAssigned) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "shift.in_use", "Name", name))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.delete_shift", "Failed to delete shift", "shift", name)
+	}
+	if !deleted {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "shift.not_found", "Name", name))
+	}
+
+	logging.FromContext(ctx).Warn("Shift deleted", "audit", "shift_deleted", "shift", name)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "shift.deleted", "Name", name))
+}
+
+// handleShiftAssign assigns a user to a shift
+func (b *Bot) handleShiftAssign(ctx context.Context, msg *Message, userID int64, name string) error {
+	err := b.attendanceService.AssignShift(ctx, userID, name, msg.From.ID)
+	if errors.Is(err, attendance.ErrUnknownShift) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "shift.unknown", "Name", name))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.assign_shift", "Failed to assign shift", "target_user_id", userID, "shift", name)
+	}
+
+	logging.FromContext(ctx).Warn("Shift assigned", "audit", "shift_assigned", "target_user_id", userID, "shift", name)
+
+	shift, err := b.attendanceService.GetUserShift(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to get assigned shift", "target_user_id", userID, "error", err)
+		shift = models.Shift{Name: name}
+	}
+
+	// Users who never started a private chat with the bot cannot be notified
+	userCtx := i18n.NewContext(ctx, b.languageOf(ctx, userID))
+	if err := b.sendMessage(ctx, userID, tr(userCtx, "shift.assigned_notice", "Shift", describeShift(userCtx, shift))); err != nil {
+		logging.FromContext(ctx).Info("Failed to notify user about shift", "target_user_id", userID, "error", err)
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "shift.assigned", "UserID", userID, "Name", name))
+}
+
+// handleShiftUnassign returns a user to the default shift
+func (b *Bot) handleShiftUnassign(ctx context.Context, msg *Message, userID int64) error {
+	unassigned, err := b.attendanceService.UnassignShift(ctx, userID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.assign_shift", "Failed to unassign shift", "target_user_id", userID)
+	}
+	if !unassigned {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "shift.not_assigned", "UserID", userID))
+	}
+
+	logging.FromContext(ctx).Warn("Shift unassigned", "audit", "shift_unassigned", "target_user_id", userID)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "shift.unassigned", "UserID", userID))
+}
+
+// describeShift renders a shift's name, hours and grace period
+func describeShift(ctx context.Context, shift models.Shift) string {
+	hours := tr(ctx, "shift.starts_at", "Start", shift.Start)
+	if shift.End != "" {
+		hours = fmt.Sprintf("%s-%s", shift.Start, shift.End)
+	}
+	if shift.GraceMinutes > 0 {
+		hours += tr(ctx, "shift.grace", "Minutes", shift.GraceMinutes)
+	}
+	return fmt.Sprintf("%s (%s)", shift.Name, hours)
+}
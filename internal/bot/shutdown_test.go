@@ -0,0 +1,52 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestStartFinishesBatchOnCancel cancels Start while a batch of updates is being answered: the
+// replies still go out and the offset of the last update is saved for the next run
+func TestStartFinishesBatchOnCancel(t *testing.T) {
+	tb := newTestBot(t)
+	release := tb.telegram.hold(t, "sendMessage")
+	tb.telegram.push(message(11, 101, "/start"), message(12, 102, "/start"), message(13, 103, "/start"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tb.Start(ctx) }()
+
+	waitFor(t, "the batch to be answered", func() bool { return tb.telegram.attemptsOf("sendMessage") == 3 })
+	cancel()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Start returned %v before the batch was answered", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Start() = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return after the batch was answered")
+	}
+
+	for _, userID := range []int64{101, 102, 103} {
+		if got := tb.telegram.messagesTo(userID); len(got) != 1 {
+			t.Errorf("messages to %d = %d, want 1", userID, len(got))
+		}
+	}
+
+	lastUpdateID, err := tb.service.GetLastUpdateID(context.Background())
+	if err != nil {
+		t.Fatalf("GetLastUpdateID: %v", err)
+	}
+	if lastUpdateID != 13 {
+		t.Errorf("saved update offset = %d, want 13", lastUpdateID)
+	}
+}
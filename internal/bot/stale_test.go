@@ -0,0 +1,134 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/config"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/metrics"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsStale(t *testing.T) {
+	now := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	sentAgo := func(age time.Duration) *Update {
+		return &Update{Message: &Message{Date: now.Add(-age).Unix()}}
+	}
+
+	tests := []struct {
+		name   string
+		cutoff int
+		update *Update
+		want   bool
+	}{
+		{"within cutoff", 5, sentAgo(4 * time.Minute), false},
+		{"exactly at cutoff", 5, sentAgo(5 * time.Minute), false},
+		{"a second past cutoff", 5, sentAgo(5*time.Minute + time.Second), true},
+		{"long past cutoff", 5, sentAgo(24 * time.Hour), true},
+		{"disabled", 0, sentAgo(24 * time.Hour), false},
+		{"no date", 5, &Update{Message: &Message{}}, false},
+		{"callback query", 5, &Update{CallbackQuery: &CallbackQuery{}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Bot{config: &config.Config{StaleUpdateCutoff: tt.cutoff}}
+			if got := b.isStale(tt.update, now); got != tt.want {
+				t.Errorf("isStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStartDropsStaleUpdates polls a batch queued while the bot was down: stale messages are
+// dropped and counted, stale commands get the stale reply and fresh messages are handled
+func TestStartDropsStaleUpdates(t *testing.T) {
+	tb := newTestBot(t, func(cfg *config.Config) {
+		cfg.StaleUpdateCutoff = 5
+		cfg.StaleCommandReply = true
+	})
+	var logs bytes.Buffer
+	tb.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	code, err := attendance.NewTOTPService(testSecret).Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	sentAgo := func(update Update, age time.Duration) Update {
+		update.Message.Date = time.Now().Add(-age).Unix()
+		return update
+	}
+	tb.telegram.push(
+		sentAgo(message(51, 401, code), 10*time.Minute),
+		sentAgo(message(52, 402, "/start"), 6*time.Minute),
+		sentAgo(message(53, 403, "/start"), 4*time.Minute),
+	)
+	staleBefore := handledCount(t, "stale")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tb.Start(ctx) }()
+
+	waitFor(t, "the batch to be handled", func() bool {
+		return tb.telegram.polled.Load() == 54 && len(tb.telegram.messagesTo(402)) == 1 && len(tb.telegram.messagesTo(403)) == 1
+	})
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Start() = %v, want nil", err)
+	}
+
+	records, err := tb.repo.ListAttendance(context.Background(), 401, "")
+	if err != nil {
+		t.Fatalf("ListAttendance: %v", err)
+	}
+	if len(records) != 0 || len(tb.telegram.messagesTo(401)) != 0 {
+		t.Errorf("stale OTP was handled: %d records, replies %q", len(records), tb.telegram.messagesTo(401))
+	}
+
+	staleReply := i18n.T(i18n.Default, "common.stale_command")
+	if got := tb.telegram.lastMessageTo(t, 402); got != staleReply {
+		t.Errorf("reply to stale command = %q, want %q", got, staleReply)
+	}
+	if got := tb.telegram.lastMessageTo(t, 403); got == staleReply {
+		t.Errorf("fresh command got the stale reply")
+	}
+
+	if got := handledCount(t, "stale") - staleBefore; got != 2 {
+		t.Errorf("stale updates counted = %v, want 2", got)
+	}
+	if !strings.Contains(logs.String(), `msg="Ignored stale updates" count=2 cutoff_minutes=5`) {
+		t.Errorf("dropped updates not logged:\n%s", logs.String())
+	}
+
+	lastUpdateID, err := tb.service.GetLastUpdateID(context.Background())
+	if err != nil {
+		t.Fatalf("GetLastUpdateID: %v", err)
+	}
+	if lastUpdateID != 53 {
+		t.Errorf("saved update offset = %d, want 53", lastUpdateID)
+	}
+}
+
+// handledCount returns the updates handled counter for a result, as scraped
+func handledCount(t *testing.T, result string) float64 {
+	t.Helper()
+
+	var buf bytes.Buffer
+	metrics.WriteText(&buf)
+	prefix := fmt.Sprintf(`attendance_bot_updates_handled_total{result=%q} `, result)
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, prefix); ok {
+			var count float64
+			if _, err := fmt.Sscan(value, &count); err != nil {
+				t.Fatalf("bad metric line %q: %v", line, err)
+			}
+			return count
+		}
+	}
+	return 0
+}
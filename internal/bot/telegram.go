@@ -1,37 +1,205 @@
 package bot
 
 import (
+	"attendance-bot/internal/metrics"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
+	"path"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// ErrTelegramAPI is returned when the Telegram Bot API rejects a request
+var ErrTelegramAPI = errors.New("telegram API error")
+
+// APIError describes a request rejected by the Telegram Bot API. It matches ErrTelegramAPI
+// with errors.Is.
+type APIError struct {
+	Method      string        // Bot API method, e.g. "sendMessage"
+	Code        int           // error_code reported by Telegram, mirrors the HTTP status
+	Description string        // Human-readable reason, or the raw body if it could not be parsed
+	RetryAfter  time.Duration // Set when Telegram asks to slow down (429 Too Many Requests)
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v: %s: %d %s", ErrTelegramAPI, e.Method, e.Code, e.Description)
+}
+
+// Is reports whether target is ErrTelegramAPI
+func (e *APIError) Is(target error) bool {
+	return target == ErrTelegramAPI
+}
+
+// Transient reports whether the request failed because Telegram was rate limiting or failing,
+// so it may succeed later, rather than because Telegram rejected the request itself
+func (e *APIError) Transient() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code >= http.StatusInternalServerError
+}
+
+// isTransient reports whether err is a failure that may pass when the call is repeated: a
+// transient APIError or a network error. Requests Telegram rejected, such as a message to a
+// chat that blocked the bot, are not.
+func isTransient(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Transient()
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// newAPIError builds an APIError from an unsuccessful Bot API response, taking the HTTP status
+// as the error code when the body is not a Bot API error, e.g. a proxy's error page
+func newAPIError(method string, status int, body []byte) *APIError {
+	var response struct {
+		ErrorCode   int    `json:"error_code"`
+		Description string `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil || response.ErrorCode == 0 {
+		return &APIError{Method: method, Code: status, Description: strings.TrimSpace(string(body))}
+	}
+
+	return &APIError{
+		Method:      method,
+		Code:        response.ErrorCode,
+		Description: response.Description,
+		RetryAfter:  time.Duration(response.Parameters.RetryAfter) * time.Second,
+	}
+}
+
+// checkResponse returns an APIError for an unsuccessful Bot API response, including one whose body
+// is not JSON, e.g. a proxy's error page
+func checkResponse(method string, status int, body []byte) error {
+	var response struct {
+		OK bool `json:"ok"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		if status != http.StatusOK {
+			return newAPIError(method, status, body)
+		}
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if !response.OK {
+		return newAPIError(method, status, body)
+	}
+	return nil
+}
+
+// isBlocked reports whether err means the bot may no longer message the chat,
+// e.g. because the user blocked the bot or deleted their account
+func isBlocked(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden
+}
+
 // TelegramAPI handles all Telegram Bot API interactions
 type TelegramAPI struct {
 	token      string
 	baseURL    string
+	fileURL    string // Where files returned by getFile are downloaded from
 	httpClient *http.Client
 }
 
 // Update represents a Telegram update
 type Update struct {
-	UpdateID int64    `json:"update_id"`
-	Message  *Message `json:"message,omitempty"`
+	UpdateID      int64          `json:"update_id"`
+	Message       *Message       `json:"message,omitempty"`
+	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
+}
+
+// CallbackQuery represents a press of an inline keyboard button
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    *User    `json:"from"`
+	Message *Message `json:"message,omitempty"` // The message carrying the keyboard
+	Data    string   `json:"data,omitempty"`
+}
+
+// InlineKeyboardMarkup is a keyboard shown below a message
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// InlineKeyboardButton is one button of an inline keyboard, sending CallbackData when pressed
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
 }
 
 // Message represents a Telegram message
 type Message struct {
-	MessageID int64  `json:"message_id"`
-	From      *User  `json:"from,omitempty"`
-	Chat      *Chat  `json:"chat"`
-	Text      string `json:"text,omitempty"`
-	Date      int64  `json:"date"`
+	MessageID      int64          `json:"message_id"`
+	From           *User          `json:"from,omitempty"`
+	Chat           *Chat          `json:"chat"`
+	Text           string         `json:"text,omitempty"`
+	Date           int64          `json:"date"`
+	ForwardOrigin  *MessageOrigin `json:"forward_origin,omitempty"` // Set on forwarded messages (Bot API 7.0+)
+	ForwardDate    int64          `json:"forward_date,omitempty"`   // Set on forwarded messages by older Bot API servers
+	Photo          []PhotoSize    `json:"photo,omitempty"`          // Sizes of a photo message, smallest first
+	Caption        string         `json:"caption,omitempty"`
+	Location       *Location      `json:"location,omitempty"`         // Set when the user shares a location
+	Document       *Document      `json:"document,omitempty"`         // Set when the user sends a file
+	ReplyToMessage *Message       `json:"reply_to_message,omitempty"` // The message replied to
+}
+
+// Document is a general file sent to the bot
+type Document struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+// File is a file ready to be downloaded with DownloadFile
+type File struct {
+	FileID   string `json:"file_id"`
+	FileSize int64  `json:"file_size,omitempty"`
+	FilePath string `json:"file_path,omitempty"`
+}
+
+// Location is a point shared by a user; live locations also carry their live period
+type Location struct {
+	Latitude           float64 `json:"latitude"`
+	Longitude          float64 `json:"longitude"`
+	HorizontalAccuracy float64 `json:"horizontal_accuracy,omitempty"` // Radius of uncertainty in meters
+	LivePeriod         int     `json:"live_period,omitempty"`         // Seconds a live location is updated for
+}
+
+// PhotoSize is one size of a photo; all sizes of a photo share the image but not the file ID
+type PhotoSize struct {
+	FileID       string `json:"file_id"`
+	FileUniqueID string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+// MessageOrigin describes where a forwarded message originally came from
+type MessageOrigin struct {
+	Type string `json:"type"` // user, hidden_user, chat or channel
+	Date int64  `json:"date"` // Unix time the original message was sent
+}
+
+// IsForwarded reports whether the message was forwarded rather than written by the sender
+func (m *Message) IsForwarded() bool {
+	return m.ForwardOrigin != nil || m.ForwardDate != 0
 }
 
 // User represents a Telegram user
@@ -66,19 +234,47 @@ type SendMessageResponse struct {
 	Result Message `json:"result"`
 }
 
+// DefaultAPIURL is the public Telegram Bot API endpoint
+const DefaultAPIURL = "https://api.telegram.org"
+
+// TelegramAPIOptions contains optional parameters for the Telegram API client
+type TelegramAPIOptions struct {
+	APIURL    string            // Bot API server, e.g. a local server or a test stub (default DefaultAPIURL)
+	Transport http.RoundTripper // HTTP transport (default http.DefaultTransport)
+}
+
 // NewTelegramAPI creates a new Telegram API client
 func NewTelegramAPI(token string) *TelegramAPI {
+	return NewTelegramAPIWithOptions(token, nil)
+}
+
+// NewTelegramAPIWithOptions creates a new Telegram API client with additional options
+func NewTelegramAPIWithOptions(token string, options *TelegramAPIOptions) *TelegramAPI {
+	apiURL := DefaultAPIURL
+	var transport http.RoundTripper = http.DefaultTransport
+
+	if options != nil {
+		if options.APIURL != "" {
+			apiURL = strings.TrimRight(options.APIURL, "/")
+		}
+		if options.Transport != nil {
+			transport = options.Transport
+		}
+	}
+
 	return &TelegramAPI{
 		token:   token,
-		baseURL: "https://api.telegram.org/bot" + token,
+		baseURL: apiURL + "/bot" + token,
+		fileURL: apiURL + "/file/bot" + token,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   90 * time.Second, // Longer than the getUpdates long-poll timeout, and than retries take
+			Transport: &retryTransport{base: &metricsTransport{base: transport}},
 		},
 	}
 }
 
-// GetUpdates retrieves updates from Telegram
-func (api *TelegramAPI) GetUpdates(offset int64, timeout int) ([]Update, error) {
+// GetUpdates retrieves updates from Telegram, returning early when ctx is cancelled
+func (api *TelegramAPI) GetUpdates(ctx context.Context, offset int64, timeout int) ([]Update, error) {
 	params := url.Values{}
 	if offset > 0 {
 		params.Set("offset", strconv.FormatInt(offset, 10))
@@ -92,7 +288,12 @@ func (api *TelegramAPI) GetUpdates(offset int64, timeout int) ([]Update, error)
 		url += "?" + params.Encode()
 	}
 
-	resp, err := api.httpClient.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := api.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get updates: %w", err)
 	}
@@ -103,21 +304,21 @@ func (api *TelegramAPI) GetUpdates(offset int64, timeout int) ([]Update, error)
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if err := checkResponse("getUpdates", resp.StatusCode, body); err != nil {
+		return nil, err
+	}
+
 	var response GetUpdatesResponse
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	if !response.OK {
-		return nil, fmt.Errorf("telegram API error: %s", string(body))
-	}
-
 	return response.Result, nil
 }
 
 // SendMessage sends a message to a chat
-func (api *TelegramAPI) SendMessage(chatID int64, text string) error {
-	return api.SendMessageWithOptions(chatID, text, nil)
+func (api *TelegramAPI) SendMessage(ctx context.Context, chatID int64, text string) error {
+	return api.SendMessageWithOptions(ctx, chatID, text, nil)
 }
 
 // SendMessageOptions contains optional parameters for sending messages
@@ -126,10 +327,19 @@ type SendMessageOptions struct {
 	DisableWebPagePreview bool   `json:"disable_web_page_preview,omitempty"`
 	DisableNotification   bool   `json:"disable_notification,omitempty"`
 	ReplyToMessageID      int64  `json:"reply_to_message_id,omitempty"`
+
+	ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
 }
 
 // SendMessageWithOptions sends a message with additional options
-func (api *TelegramAPI) SendMessageWithOptions(chatID int64, text string, options *SendMessageOptions) error {
+func (api *TelegramAPI) SendMessageWithOptions(ctx context.Context, chatID int64, text string, options *SendMessageOptions) error {
+	_, err := api.PostMessage(ctx, chatID, text, options)
+	return err
+}
+
+// PostMessage sends a message with additional options and returns the sent message,
+// whose ID is needed to edit or pin it later
+func (api *TelegramAPI) PostMessage(ctx context.Context, chatID int64, text string, options *SendMessageOptions) (*Message, error) {
 	payload := map[string]interface{}{
 		"chat_id": chatID,
 		"text":    text,
@@ -148,20 +358,150 @@ func (api *TelegramAPI) SendMessageWithOptions(chatID int64, text string, option
 		if options.ReplyToMessageID > 0 {
 			payload["reply_to_message_id"] = options.ReplyToMessageID
 		}
+		if options.ReplyMarkup != nil {
+			payload["reply_markup"] = options.ReplyMarkup
+		}
 	}
 
+	var response SendMessageResponse
+	if err := api.postJSON(ctx, "sendMessage", payload, &response); err != nil {
+		return nil, err
+	}
+
+	return &response.Result, nil
+}
+
+// EditMessageText replaces the text of a message sent by the bot. Editing a message to
+// its current text is not an error.
+func (api *TelegramAPI) EditMessageText(ctx context.Context, chatID, messageID int64, text string, options *SendMessageOptions) error {
+	payload := map[string]interface{}{
+		"chat_id":    chatID,
+		"message_id": messageID,
+		"text":       text,
+	}
+
+	if options != nil {
+		if options.ParseMode != "" {
+			payload["parse_mode"] = options.ParseMode
+		}
+		if options.DisableWebPagePreview {
+			payload["disable_web_page_preview"] = true
+		}
+		if options.ReplyMarkup != nil {
+			payload["reply_markup"] = options.ReplyMarkup
+		}
+	}
+
+	err := api.postJSON(ctx, "editMessageText", payload, nil)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && strings.Contains(apiErr.Description, "message is not modified") {
+		return nil
+	}
+	return err
+}
+
+// AnswerCallbackQuery acknowledges a button press, optionally showing text to the user.
+// Telegram shows a loading indicator on the button until the query is answered.
+func (api *TelegramAPI) AnswerCallbackQuery(ctx context.Context, queryID, text string) error {
+	payload := map[string]interface{}{
+		"callback_query_id": queryID,
+	}
+	if text != "" {
+		payload["text"] = text
+	}
+
+	return api.postJSON(ctx, "answerCallbackQuery", payload, nil)
+}
+
+// allowedUpdates lists the update types the bot handles
+var allowedUpdates = []string{"message", "callback_query"}
+
+// SetWebhook makes Telegram deliver updates to url, sending secretToken in the
+// X-Telegram-Bot-Api-Secret-Token header of every request
+func (api *TelegramAPI) SetWebhook(ctx context.Context, url, secretToken string) error {
+	payload := map[string]interface{}{
+		"url":             url,
+		"secret_token":    secretToken,
+		"allowed_updates": allowedUpdates,
+	}
+
+	return api.postJSON(ctx, "setWebhook", payload, nil)
+}
+
+// DeleteWebhook removes the webhook so updates can be fetched with getUpdates again.
+// Pending updates are kept.
+func (api *TelegramAPI) DeleteWebhook(ctx context.Context) error {
+	return api.postJSON(ctx, "deleteWebhook", map[string]interface{}{}, nil)
+}
+
+// WebhookInfo describes the current webhook as reported by Telegram
+type WebhookInfo struct {
+	URL                string `json:"url"`
+	PendingUpdateCount int    `json:"pending_update_count"`
+	LastErrorDate      int64  `json:"last_error_date,omitempty"`
+	LastErrorMessage   string `json:"last_error_message,omitempty"`
+}
+
+// GetWebhookInfo returns the current webhook status
+func (api *TelegramAPI) GetWebhookInfo(ctx context.Context) (*WebhookInfo, error) {
+	var response struct {
+		Result WebhookInfo `json:"result"`
+	}
+	if err := api.postJSON(ctx, "getWebhookInfo", map[string]interface{}{}, &response); err != nil {
+		return nil, err
+	}
+	return &response.Result, nil
+}
+
+// PinChatMessage pins a message in a chat, optionally without notifying members
+func (api *TelegramAPI) PinChatMessage(ctx context.Context, chatID, messageID int64, disableNotification bool) error {
+	payload := map[string]interface{}{
+		"chat_id":              chatID,
+		"message_id":           messageID,
+		"disable_notification": disableNotification,
+	}
+
+	return api.postJSON(ctx, "pinChatMessage", payload, nil)
+}
+
+// UnpinChatMessage unpins a message in a chat
+func (api *TelegramAPI) UnpinChatMessage(ctx context.Context, chatID, messageID int64) error {
+	payload := map[string]interface{}{
+		"chat_id":    chatID,
+		"message_id": messageID,
+	}
+
+	return api.postJSON(ctx, "unpinChatMessage", payload, nil)
+}
+
+// DeleteMessage deletes a message; in groups the bot needs the right to delete messages of others
+func (api *TelegramAPI) DeleteMessage(ctx context.Context, chatID, messageID int64) error {
+	payload := map[string]interface{}{
+		"chat_id":    chatID,
+		"message_id": messageID,
+	}
+
+	return api.postJSON(ctx, "deleteMessage", payload, nil)
+}
+
+// postJSON calls a Bot API method with a JSON payload and decodes a successful response
+// into result, unless result is nil
+func (api *TelegramAPI) postJSON(ctx context.Context, method string, payload interface{}, result interface{}) error {
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := api.httpClient.Post(
-		api.baseURL+"/sendMessage",
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, api.baseURL+"/"+method, bytes.NewReader(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := api.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
 	}
 	defer resp.Body.Close()
 
@@ -170,83 +510,198 @@ func (api *TelegramAPI) SendMessageWithOptions(chatID int64, text string, option
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	var response SendMessageResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+	if err := checkResponse(method, resp.StatusCode, body); err != nil {
+		return err
 	}
 
-	if !response.OK {
-		return fmt.Errorf("telegram API error: %s", string(body))
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	return nil
 }
 
+// Retry policy of Bot API calls. Rate-limited calls are repeated after the retry_after Telegram asks
+// for, and calls failing with a 5xx response or a network error after a jittered exponential
+// backoff. Other rejections would be rejected again. Calls that send a new message are not
+// repeated after a 5xx response, nor after a network error unless it happened before the request
+// was written, since Telegram may have delivered the message before failing. A 429 means the
+// message was not sent, so they are still repeated after retry_after.
+const (
+	retryAttempts  = 4
+	retryBaseDelay = 500 * time.Millisecond // Doubled on every attempt
+	retryMaxDelay  = 8 * time.Second
+	maxRetryAfter  = 20 * time.Second // Longer rate limits are returned as an APIError rather than waited out
+)
+
+// nonIdempotentMethods are the Bot API methods that send a new message every time they are called
+var nonIdempotentMethods = map[string]bool{
+	"sendMessage":  true,
+	"sendDocument": true,
+	"sendPhoto":    true,
+}
+
+// beforeWrite reports whether a failed request never reached the server, because resolving or
+// connecting to it failed
+func beforeWrite(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// SendDocumentOptions contains optional parameters for sending documents
+type SendDocumentOptions struct {
+	Caption   string
+	ParseMode string // Applies to the caption
+}
+
 // SendDocument sends a document to a chat
-func (api *TelegramAPI) SendDocument(chatID int64, document io.Reader, filename string) error {
-	// Create a multipart writer
+func (api *TelegramAPI) SendDocument(ctx context.Context, chatID int64, document io.Reader, filename string) error {
+	return api.SendDocumentWithOptions(ctx, chatID, document, filename, nil)
+}
+
+// SendDocumentWithOptions sends a document to a chat with an optional caption
+func (api *TelegramAPI) SendDocumentWithOptions(ctx context.Context, chatID int64, document io.Reader, filename string, options *SendDocumentOptions) error {
+	fields := map[string]string{}
+	if options != nil {
+		if options.Caption != "" {
+			fields["caption"] = options.Caption
+		}
+		if options.ParseMode != "" {
+			fields["parse_mode"] = options.ParseMode
+		}
+	}
+
+	return api.postMultipart(ctx, "sendDocument", chatID, fields, "document", filename, document)
+}
+
+// SendPhoto sends an image to a chat with an optional caption
+func (api *TelegramAPI) SendPhoto(ctx context.Context, chatID int64, photo io.Reader, filename, caption string) error {
+	fields := map[string]string{}
+	if caption != "" {
+		fields["caption"] = caption
+	}
+
+	return api.postMultipart(ctx, "sendPhoto", chatID, fields, "photo", filename, photo)
+}
+
+// SendPhotoByID sends a photo already stored by Telegram, identified by its file ID
+func (api *TelegramAPI) SendPhotoByID(ctx context.Context, chatID int64, fileID, caption string) error {
+	payload := map[string]interface{}{
+		"chat_id": chatID,
+		"photo":   fileID,
+	}
+	if caption != "" {
+		payload["caption"] = caption
+	}
+
+	return api.postJSON(ctx, "sendPhoto", payload, nil)
+}
+
+// postMultipart uploads a file to a Bot API method as multipart/form-data together with
+// chat_id and the given fields. The body is built in memory so it can be resent on retries.
+func (api *TelegramAPI) postMultipart(ctx context.Context, method string, chatID int64, fields map[string]string, fileField, filename string, file io.Reader) error {
 	var buf bytes.Buffer
 	writer := multipart.NewWriter(&buf)
 
-	// Add chat_id field
 	if err := writer.WriteField("chat_id", strconv.FormatInt(chatID, 10)); err != nil {
 		return fmt.Errorf("failed to write chat_id field: %w", err)
 	}
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return fmt.Errorf("failed to write %s field: %w", name, err)
+		}
+	}
 
-	// Add document field
-	part, err := writer.CreateFormFile("document", filename)
+	part, err := writer.CreateFormFile(fileField, filename)
 	if err != nil {
 		return fmt.Errorf("failed to create form file: %w", err)
 	}
-
-	// Copy document content to the form field
-	if _, err := io.Copy(part, document); err != nil {
-		return fmt.Errorf("failed to copy document content: %w", err)
+	if _, err := io.Copy(part, file); err != nil {
+		return fmt.Errorf("failed to copy %s content: %w", fileField, err)
 	}
 
-	// Close the multipart writer
 	if err := writer.Close(); err != nil {
 		return fmt.Errorf("failed to close multipart writer: %w", err)
 	}
 
-	// Create the request
-	req, err := http.NewRequest("POST", api.baseURL+"/sendDocument", &buf)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, api.baseURL+"/"+method, &buf)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
-
-	// Set content type header
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	// Send the request
 	resp, err := api.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send document: %w", err)
+		return fmt.Errorf("failed to call %s: %w", method, err)
 	}
 	defer resp.Body.Close()
 
-	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("telegram API returned status %d: %s", resp.StatusCode, string(body))
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	// Parse response
-	var response SendMessageResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+	return checkResponse(method, resp.StatusCode, respBody)
+}
+
+// GetFile prepares a file sent to the bot for download
+func (api *TelegramAPI) GetFile(ctx context.Context, fileID string) (*File, error) {
+	var response struct {
+		Result File `json:"result"`
+	}
+	if err := api.postJSON(ctx, "getFile", map[string]interface{}{"file_id": fileID}, &response); err != nil {
+		return nil, err
 	}
+	return &response.Result, nil
+}
 
-	if !response.OK {
-		return fmt.Errorf("telegram API error")
+// DownloadFile returns the contents of a file prepared with GetFile, failing if it is larger than
+// maxBytes
+func (api *TelegramAPI) DownloadFile(ctx context.Context, file *File, maxBytes int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.fileURL+"/"+file.FilePath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	return nil
+	resp, err := api.httpClient.Do(req)
+	if err != nil {
+		// The URL carries the bot token, which must not end up in logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{Method: downloadFileMethod, Code: resp.StatusCode, Description: http.StatusText(resp.StatusCode)}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxBytes)
+	}
+	return data, nil
 }
 
+// downloadFileMethod names file downloads in metrics and errors, which are not Bot API methods
+const downloadFileMethod = "downloadFile"
+
 // GetMe returns basic information about the bot
-func (api *TelegramAPI) GetMe() (*User, error) {
-	resp, err := api.httpClient.Get(api.baseURL + "/getMe")
+func (api *TelegramAPI) GetMe(ctx context.Context) (*User, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.baseURL+"/getMe", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := api.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get bot info: %w", err)
 	}
@@ -257,18 +712,118 @@ func (api *TelegramAPI) GetMe() (*User, error) {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if err := checkResponse("getMe", resp.StatusCode, body); err != nil {
+		return nil, err
+	}
+
 	var response struct {
-		OK     bool `json:"ok"`
 		Result User `json:"result"`
 	}
-
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	if !response.OK {
-		return nil, fmt.Errorf("telegram API error: %s", string(body))
+	return &response.Result, nil
+}
+
+// metricsTransport counts outgoing Bot API calls by method and HTTP status
+type metricsTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip performs the request and records its outcome
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := path.Base(req.URL.Path)
+	if strings.Contains(req.URL.Path, "/file/bot") {
+		method = downloadFileMethod
 	}
 
-	return &response.Result, nil
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		metrics.TelegramRequests.Inc(method, "error")
+		return nil, err
+	}
+
+	metrics.TelegramRequests.Inc(method, strconv.Itoa(resp.StatusCode))
+	return resp, nil
+}
+
+// retryTransport repeats Bot API calls that failed transiently, as the retry policy describes.
+// getUpdates is passed through, since the poll loop calls it again anyway. Requests are resent
+// with GetBody, which http.NewRequestWithContext sets for the in-memory bodies the client sends,
+// and waiting between attempts stops when the request's context is done.
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip performs the request, repeating it while it fails transiently
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if path.Base(req.URL.Path) == "getUpdates" || (req.Body != nil && req.GetBody == nil) {
+		return t.base.RoundTrip(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if nonIdempotentMethods[path.Base(req.URL.Path)] {
+			if err != nil && !beforeWrite(err) {
+				return nil, err
+			}
+			if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+				return resp, nil
+			}
+		}
+		delay, retry := retryDelay(attempt, resp, err)
+		if !retry || attempt == retryAttempts {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryDelay returns how long to wait before repeating a call that got resp or err, and whether
+// to repeat it at all. A 429 response's body is read to find retry_after and then restored.
+func retryDelay(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	backoff := min(retryBaseDelay<<(attempt-1), retryMaxDelay)
+	backoff = backoff/2 + rand.N(backoff/2+1)
+
+	switch {
+	case err != nil:
+		return backoff, true
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return backoff, true
+	case resp.StatusCode != http.StatusTooManyRequests:
+		return 0, false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return 0, false
+	}
+	retryAfter := newAPIError("", resp.StatusCode, body).RetryAfter
+	if retryAfter <= 0 {
+		return backoff, true
+	}
+	return retryAfter, retryAfter <= maxRetryAfter
 }
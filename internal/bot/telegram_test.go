@@ -0,0 +1,200 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTelegramAPIStopsRetryingWhenContextDone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 5","parameters":{"retry_after":5}}`)
+	}))
+	defer srv.Close()
+
+	api := NewTelegramAPIWithOptions("t", &TelegramAPIOptions{APIURL: srv.URL})
+	tests := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{"sendMessage", func(ctx context.Context) error { return api.SendMessage(ctx, 1, "hello") }},
+		{"sendDocument", func(ctx context.Context) error {
+			return api.SendDocument(ctx, 1, bytes.NewReader([]byte("a,b\n")), "report.csv")
+		}},
+		{"getMe", func(ctx context.Context) error { _, err := api.GetMe(ctx); return err }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			err := tc.call(ctx)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("error = %v, want %v", err, context.DeadlineExceeded)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("returned after %v, want soon after the context ended", elapsed)
+			}
+		})
+	}
+}
+
+func TestTelegramAPIDoesNotResendMessagesAfterWrite(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		// Drop the connection after the request arrived, as when a response is lost
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer srv.Close()
+
+	api := NewTelegramAPIWithOptions("t", &TelegramAPIOptions{APIURL: srv.URL})
+	if err := api.SendMessage(context.Background(), 1, "hello"); err == nil {
+		t.Fatal("SendMessage succeeded over a dropped connection")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("sendMessage sent %d times, want 1", n)
+	}
+
+	calls.Store(0)
+	if err := api.SendDocument(context.Background(), 1, bytes.NewReader([]byte("a,b\n")), "report.csv"); err == nil {
+		t.Fatal("SendDocument succeeded over a dropped connection")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("sendDocument sent %d times, want 1", n)
+	}
+}
+
+// TestTelegramAPIRetryPolicy answers calls with error statuses and counts how often each is sent:
+// calls that send a message are only repeated after a 429, other calls after 5xx responses too
+func TestTelegramAPIRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		statuses []int
+		call     func(ctx context.Context, api *TelegramAPI) error
+		attempts int
+		wantErr  bool
+	}{
+		{"sendMessage 502", "sendMessage", []int{502}, sendHello, 1, true},
+		{"sendMessage 500", "sendMessage", []int{500}, sendHello, 1, true},
+		{"sendMessage 429", "sendMessage", []int{429}, sendHello, 2, false},
+		{"sendMessage 400", "sendMessage", []int{400}, sendHello, 1, true},
+		{"sendDocument 503", "sendDocument", []int{503}, func(ctx context.Context, api *TelegramAPI) error {
+			return api.SendDocument(ctx, 1, bytes.NewReader([]byte("a,b\n")), "report.csv")
+		}, 1, true},
+		{"sendPhoto 502", "sendPhoto", []int{502}, func(ctx context.Context, api *TelegramAPI) error {
+			return api.SendPhoto(ctx, 1, bytes.NewReader([]byte("png")), "photo.png", "")
+		}, 1, true},
+		{"getMe 502", "getMe", []int{502}, func(ctx context.Context, api *TelegramAPI) error {
+			_, err := api.GetMe(ctx)
+			return err
+		}, 2, false},
+		{"answerCallbackQuery 500 twice", "answerCallbackQuery", []int{500, 500}, func(ctx context.Context, api *TelegramAPI) error {
+			return api.AnswerCallbackQuery(ctx, "1", "")
+		}, 3, false},
+		{"answerCallbackQuery 403", "answerCallbackQuery", []int{403}, func(ctx context.Context, api *TelegramAPI) error {
+			return api.AnswerCallbackQuery(ctx, "1", "")
+		}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			telegram := newFakeTelegram(t)
+			telegram.fail(tt.method, tt.statuses...)
+			api := NewTelegramAPIWithOptions("t", &TelegramAPIOptions{APIURL: telegram.server.URL})
+
+			err := tt.call(context.Background(), api)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := telegram.attemptsOf(tt.method); got != tt.attempts {
+				t.Errorf("%s sent %d times, want %d", tt.method, got, tt.attempts)
+			}
+		})
+	}
+}
+
+// sendHello sends a text message to chat 1
+func sendHello(ctx context.Context, api *TelegramAPI) error {
+	return api.SendMessage(ctx, 1, "hello")
+}
+
+func TestBeforeWrite(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	_, err = http.DefaultTransport.RoundTrip(httptest.NewRequest(http.MethodPost, "http://"+addr+"/botT/sendMessage", nil))
+	if err == nil {
+		t.Fatal("request to a closed port succeeded")
+	}
+	if !beforeWrite(err) {
+		t.Errorf("beforeWrite(%v) = false for a refused connection", err)
+	}
+	if beforeWrite(io.ErrUnexpectedEOF) {
+		t.Error("beforeWrite(io.ErrUnexpectedEOF) = true")
+	}
+}
+
+// TestSendDocumentWithOptions uploads a document with and without a caption, once after a 429,
+// and checks the multipart fields and file Telegram received
+func TestSendDocumentWithOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *SendDocumentOptions
+		statuses []int
+		want     map[string]string
+	}{
+		{"no options", nil, nil, map[string]string{"chat_id": "42"}},
+		{"caption", &SendDocumentOptions{Caption: "March report"}, nil,
+			map[string]string{"chat_id": "42", "caption": "March report"}},
+		{"caption with parse mode", &SendDocumentOptions{Caption: "*March*", ParseMode: "Markdown"}, nil,
+			map[string]string{"chat_id": "42", "caption": "*March*", "parse_mode": "Markdown"}},
+		{"resent after 429", &SendDocumentOptions{Caption: "March report"}, []int{429},
+			map[string]string{"chat_id": "42", "caption": "March report"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			telegram := newFakeTelegram(t)
+			telegram.fail("sendDocument", tt.statuses...)
+			api := NewTelegramAPIWithOptions("t", &TelegramAPIOptions{APIURL: telegram.server.URL})
+
+			content := []byte("user_id,name\n1001,Budi\n")
+			if err := api.SendDocumentWithOptions(context.Background(), 42, bytes.NewReader(content), "report.csv", tt.options); err != nil {
+				t.Fatalf("SendDocumentWithOptions: %v", err)
+			}
+
+			calls := telegram.sent("sendDocument")
+			if len(calls) != 1 {
+				t.Fatalf("%d documents delivered, want 1", len(calls))
+			}
+			call := calls[0]
+			if !maps.Equal(call.Fields, tt.want) {
+				t.Errorf("fields = %v, want %v", call.Fields, tt.want)
+			}
+			if call.Filename != "report.csv" || !bytes.Equal(call.File, content) {
+				t.Errorf("file = %s %q, want report.csv %q", call.Filename, call.File, content)
+			}
+		})
+	}
+}
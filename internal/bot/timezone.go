@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// userLocation returns the timezone a user works in, for the times shown to them
+func (b *Bot) userLocation(ctx context.Context, userID int64) *time.Location {
+	location, err := b.attendanceService.UserLocation(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to get user timezone", "target_user_id", userID, "error", err)
+	}
+	return location
+}
+
+// handleTimezone handles the /timezone command. Without arguments it shows the sender's timezone;
+// an IANA name such as Asia/Makassar sets it and "reset" returns to the office timezone.
+func (b *Bot) handleTimezone(ctx context.Context, msg *Message, args []string) error {
+	office := utils.Location.String()
+	if len(args) == 0 {
+		timezone, err := b.attendanceService.GetUserTimezone(ctx, msg.From.ID)
+		if err != nil {
+			return b.replyError(ctx, msg.Chat.ID, err, "action.get_timezone", "Failed to get timezone")
+		}
+		if timezone == nil {
+			return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "timezone.office", "Timezone", office)+"\n\n"+tr(ctx, "timezone.usage"))
+		}
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "timezone.current", "Timezone", timezone.Timezone, "Office", office)+"\n\n"+tr(ctx, "timezone.usage"))
+	}
+	if len(args) > 1 {
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "timezone.usage"))
+	}
+
+	if strings.EqualFold(args[0], "reset") {
+		if _, err := b.attendanceService.ResetUserTimezone(ctx, msg.From.ID); err != nil {
+			return b.replyError(ctx, msg.Chat.ID, err, "action.set_timezone", "Failed to reset timezone")
+		}
+		logging.FromContext(ctx).Info("Timezone reset", "timezone", office)
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "timezone.reset", "Timezone", office))
+	}
+
+	location, err := b.attendanceService.SetUserTimezone(ctx, msg.From.ID, args[0])
+	if errors.Is(err, attendance.ErrInvalidTimezone) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "timezone.invalid", "Timezone", args[0])+"\n\n"+tr(ctx, "timezone.usage"))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.set_timezone", "Failed to set timezone", "timezone", args[0])
+	}
+
+	logging.FromContext(ctx).Info("Timezone set", "timezone", location.String())
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "timezone.set",
+		"Timezone", location.String(), "Time", utils.FormatTimeIn(time.Now(), "HH:mm", location)))
+}
@@ -0,0 +1,134 @@
+package bot
+
+import (
+	"attendance-bot/internal/metrics"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// webhookSecretHeader carries the secret token Telegram sends with every webhook request
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// webhookBodyLimit bounds the size of one webhook request; updates are a few kilobytes
+const webhookBodyLimit = 1 << 20
+
+// webhookServer receives the updates Telegram pushes to the webhook
+type webhookServer struct {
+	bot      *Bot
+	listener net.Listener
+	server   *http.Server
+	workers  *dispatcher
+}
+
+// listenWebhook opens the webhook port and registers the webhook with Telegram.
+// Requests are only accepted once serve is called.
+func (b *Bot) listenWebhook(ctx context.Context) (*webhookServer, error) {
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(b.config.WebhookPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for webhook: %w", err)
+	}
+
+	if err := b.api.SetWebhook(ctx, b.config.WebhookURL, b.config.WebhookSecret); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set webhook: %w", err)
+	}
+	b.lastPoll.Store(time.Now().UnixNano())
+	b.logger.Info("Webhook registered", "url", b.config.WebhookURL, "port", b.config.WebhookPort)
+
+	w := &webhookServer{bot: b, listener: listener}
+	w.server = &http.Server{
+		Handler:           w,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return w, nil
+}
+
+// serve dispatches webhook updates to workers until ctx is cancelled, then waits for the
+// requests being handled. The webhook stays registered, so Telegram keeps updates sent while
+// the bot is down and delivers them after the next start.
+func (w *webhookServer) serve(ctx context.Context, workers *dispatcher) {
+	w.workers = workers
+
+	go func() {
+		if err := w.server.Serve(w.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			w.bot.logger.Error("Webhook server error", "error", err)
+		}
+	}()
+
+	ticker := time.NewTicker(pollTimeout)
+	defer ticker.Stop()
+	for done := false; !done; {
+		select {
+		case <-ctx.Done():
+			done = true
+		case <-ticker.C:
+			w.check(ctx)
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := w.server.Shutdown(shutdownCtx); err != nil {
+		w.bot.logger.Error("Failed to stop webhook server", "error", err)
+	}
+}
+
+// check asks Telegram whether the webhook is still in place, which readiness relies on
+// instead of polling, and logs delivery errors Telegram reports
+func (w *webhookServer) check(ctx context.Context) {
+	info, err := w.bot.api.GetWebhookInfo(ctx)
+	if err != nil {
+		w.bot.logger.Error("Failed to get webhook info", "error", err)
+		return
+	}
+
+	if info.URL != w.bot.config.WebhookURL {
+		w.bot.logger.Error("Webhook was changed outside the bot", "url", info.URL)
+		return
+	}
+	w.bot.lastPoll.Store(time.Now().UnixNano())
+
+	if info.LastErrorDate != 0 && time.Since(time.Unix(info.LastErrorDate, 0)) < pollTimeout {
+		w.bot.logger.Warn("Telegram reported a webhook delivery error",
+			"error", info.LastErrorMessage,
+			"pending_updates", info.PendingUpdateCount)
+	}
+}
+
+// ServeHTTP accepts one update from Telegram and dispatches it like a polled update
+func (w *webhookServer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	secret := r.Header.Get(webhookSecretHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(w.bot.config.WebhookSecret)) != 1 {
+		w.bot.logger.Warn("Rejected webhook request with an invalid secret token", "remote_addr", r.RemoteAddr)
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var update Update
+	if err := json.NewDecoder(io.LimitReader(r.Body, webhookBodyLimit)).Decode(&update); err != nil {
+		w.bot.logger.Warn("Rejected malformed webhook update", "error", err)
+		http.Error(rw, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	metrics.UpdatesReceived.Inc()
+	if !w.bot.enqueue(w.workers, &update, time.Now()) {
+		w.bot.logger.Info("Ignored stale update", "update_id", update.UpdateID, "cutoff_minutes", w.bot.config.StaleUpdateCutoff)
+		metrics.UpdatesHandled.Inc("stale")
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"attendance-bot/internal/config"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWebhookServeHTTP posts requests to the webhook handler: only POSTs carrying the secret token
+// and a well-formed update are accepted and handled
+func TestWebhookServeHTTP(t *testing.T) {
+	const secret = "webhook-secret"
+	update, err := json.Marshal(Update{UpdateID: 1, Message: &Message{
+		MessageID: 1,
+		From:      &User{ID: 1601, FirstName: "Sari"},
+		Chat:      &Chat{ID: 1601, Type: "private"},
+		Date:      time.Now().Unix(),
+		Text:      "/help",
+	}})
+	if err != nil {
+		t.Fatalf("failed to encode update: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		secret  string
+		body    string
+		status  int
+		handled bool
+	}{
+		{"update", http.MethodPost, secret, string(update), http.StatusOK, true},
+		{"GET", http.MethodGet, secret, "", http.StatusMethodNotAllowed, false},
+		{"missing secret", http.MethodPost, "", string(update), http.StatusUnauthorized, false},
+		{"wrong secret", http.MethodPost, "webhook-secreT", string(update), http.StatusUnauthorized, false},
+		{"malformed update", http.MethodPost, secret, `{"update_id":`, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newTestBot(t, func(cfg *config.Config) { cfg.WebhookSecret = secret })
+			workers := newDispatcher(context.Background(), 1, 1)
+			w := &webhookServer{bot: tb.Bot, workers: workers}
+
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			if tt.secret != "" {
+				req.Header.Set(webhookSecretHeader, tt.secret)
+			}
+			rec := httptest.NewRecorder()
+			w.ServeHTTP(rec, req)
+			workers.close()
+			workers.wg.Wait()
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if handled := len(tb.telegram.messagesTo(1601)) > 0; handled != tt.handled {
+				t.Errorf("update handled = %v, want %v", handled, tt.handled)
+			}
+		})
+	}
+}
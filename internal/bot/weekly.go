@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/reports"
+	"context"
+	"strings"
+	"time"
+)
+
+// handleWeekly handles the /weekly command, with which employees opt out of and back in to the
+// summary of their past week. Without arguments it shows whether they get it.
+func (b *Bot) handleWeekly(ctx context.Context, msg *Message, args []string) error {
+	if _, ok := b.config.WeeklySummaryScheduled(); !ok {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "weekly.unavailable"))
+	}
+
+	if len(args) == 0 {
+		enabled, err := b.attendanceService.WeeklySummaryEnabled(ctx, msg.From.ID)
+		if err != nil {
+			return b.replyError(ctx, msg.Chat.ID, err, "action.get_weekly", "Failed to get weekly summary preference")
+		}
+		status := "weekly.status_on"
+		if !enabled {
+			status = "weekly.status_off"
+		}
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, status)+"\n\n"+tr(ctx, "weekly.usage"))
+	}
+
+	var enabled bool
+	switch {
+	case len(args) == 1 && strings.ToLower(args[0]) == "on":
+		enabled = true
+	case len(args) == 1 && strings.ToLower(args[0]) == "off":
+	default:
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "weekly.usage"))
+	}
+
+	if err := b.attendanceService.SetWeeklySummary(ctx, msg.From.ID, enabled); err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.set_weekly", "Failed to set weekly summary preference", "enabled", enabled)
+	}
+
+	logging.FromContext(ctx).Info("Weekly summary preference set", "enabled", enabled)
+	if enabled {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "weekly.enabled"))
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "weekly.disabled"))
+}
+
+// sendWeeklySummaries sends every employee who recorded attendance last week, and did not opt
+// out, their own totals of that week in their language
+func (b *Bot) sendWeeklySummaries(ctx context.Context) {
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+	logger := b.logger.With("request_id", logging.RequestID(ctx))
+
+	summary, err := b.attendanceService.GetWeeklySummaries(ctx, time.Now())
+	if err != nil {
+		logger.Error("Failed to get weekly summaries", "error", err)
+		return
+	}
+
+	sent := 0
+	for i := range summary.Users {
+		user := &summary.Users[i]
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				logger.Warn("Weekly summaries interrupted", "sent", sent, "remaining", len(summary.Users)-i)
+				return
+			case <-time.After(broadcastInterval):
+			}
+		}
+
+		lang := b.languageOf(ctx, user.UserID)
+		text := reports.FormatWeeklySummary(user, summary.StartDate, summary.EndDate, summary.WorkingDays, lang)
+		// Users who never started a private chat with the bot cannot be sent their summary
+		if err := b.sendBroadcastMessage(ctx, user.UserID, text); err != nil {
+			logger.Info("Failed to send weekly summary", "target_user_id", user.UserID, "error", err)
+			continue
+		}
+		sent++
+	}
+
+	logger.Info("Weekly summaries sent", "start_date", summary.StartDate, "end_date", summary.EndDate,
+		"users", len(summary.Users), "sent", sent)
+}
@@ -0,0 +1,184 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// handleWFH handles the /wfh command, with which users work from home today or on a later date
+func (b *Bot) handleWFH(ctx context.Context, msg *Message, args []string) error {
+	switch {
+	case len(args) == 0:
+		return b.requestWFH(ctx, msg, utils.GetTodayDate())
+	case strings.EqualFold(args[0], "cancel") && len(args) <= 2:
+		date := utils.GetTodayDate()
+		if len(args) == 2 {
+			date = args[1]
+		}
+		return b.cancelWFH(ctx, msg, date)
+	case strings.EqualFold(args[0], "list") && len(args) == 1:
+		return b.sendWFHRequests(ctx, msg)
+	case len(args) == 1 && utils.IsValidDateFormat(args[0]):
+		return b.requestWFH(ctx, msg, args[0])
+	default:
+		return b.sendMessage(ctx, msg.Chat.ID, usageMessage(ctx, "wfh.usage"))
+	}
+}
+
+// requestWFH asks to work from home on date, which is approved at once or sent to the admins
+func (b *Bot) requestWFH(ctx context.Context, msg *Message, date string) error {
+	username, firstName, lastName := recordedIdentity(msg.From)
+	request, err := b.attendanceService.RequestWFH(ctx, &models.WFHRequest{
+		UserID:    msg.From.ID,
+		Username:  username,
+		FirstName: firstName,
+		LastName:  lastName,
+		Date:      date,
+	})
+	switch {
+	case errors.Is(err, attendance.ErrWFHDate):
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "wfh.invalid_date", "Days", attendance.MaxWFHAheadDays))
+	case errors.Is(err, attendance.ErrWFHExists) && request != nil:
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "wfh.exists", "Date", date, "Status", tr(ctx, "wfh.status."+request.Status)))
+	case err != nil:
+		return b.replyError(ctx, msg.Chat.ID, err, "action.save_wfh_request", "Failed to request working from home", "date", date)
+	}
+
+	logger := logging.FromContext(ctx).With("wfh_id", request.ID)
+	logger.Info("Working from home requested", "audit", "wfh_requested", "date", request.Date, "status", request.Status)
+
+	if request.Status == models.WFHApproved {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "wfh.approved_now", "Date", request.Date))
+	}
+
+	if err := b.requestWFHApproval(ctx, request); err != nil {
+		logger.Error("Failed to send wfh approval request", "error", err)
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "wfh.admin_unreachable", "ID", request.ID))
+	}
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "wfh.requested", "ID", request.ID, "Date", request.Date))
+}
+
+// cancelWFH withdraws the sender's request to work from home on date
+func (b *Bot) cancelWFH(ctx context.Context, msg *Message, date string) error {
+	cancelled, err := b.attendanceService.CancelWFH(ctx, msg.From.ID, date)
+	if errors.Is(err, attendance.ErrWFHDate) {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "wfh.invalid_date", "Days", attendance.MaxWFHAheadDays))
+	}
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.cancel_wfh_request", "Failed to cancel working from home", "date", date)
+	}
+	if !cancelled {
+		return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "wfh.not_found_date", "Date", date))
+	}
+
+	logging.FromContext(ctx).Info("Working from home cancelled", "audit", "wfh_cancelled", "date", date)
+	return b.sendMessage(ctx, msg.Chat.ID, tr(ctx, "wfh.cancelled", "Date", date))
+}
+
+// sendWFHRequests shows the /wfh syntax and the sender's requests from today on
+func (b *Bot) sendWFHRequests(ctx context.Context, msg *Message) error {
+	requests, err := b.attendanceService.GetUpcomingWFH(ctx, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_wfh_requests", "Failed to get wfh requests")
+	}
+
+	var message strings.Builder
+	message.WriteString(tr(ctx, "wfh.usage"))
+	if len(requests) == 0 {
+		message.WriteString("\n\n" + tr(ctx, "wfh.list_empty"))
+	} else {
+		message.WriteString("\n\n" + tr(ctx, "wfh.list_title") + "\n")
+		for _, request := range requests {
+			message.WriteString(tr(ctx, "wfh.list_entry", "Date", request.Date, "Status", tr(ctx, "wfh.status."+request.Status)) + "\n")
+		}
+	}
+
+	return b.sendMessage(ctx, msg.Chat.ID, message.String())
+}
+
+// requestWFHApproval sends a request to work from home with approve and reject buttons to the
+// admin chat, or to the super-admin when no admin chat is configured
+func (b *Bot) requestWFHApproval(ctx context.Context, request *models.WFHRequest) error {
+	chatID := b.config.AdminChatID
+	if chatID == 0 {
+		chatID = b.config.SuperAdminID
+	}
+
+	text := i18n.T(i18n.Default, "wfh.approval_request",
+		"ID", request.ID, "Name", b.attendanceService.WFHName(ctx, request),
+		"Username", request.Username, "UserID", request.UserID, "Date", request.Date)
+
+	return b.api.SendMessageWithOptions(ctx, chatID, text, &SendMessageOptions{
+		ReplyMarkup: &InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{
+			{Text: i18n.T(i18n.Default, "wfh.approve_button"), CallbackData: fmt.Sprintf("wfh:approve:%d", request.ID)},
+			{Text: i18n.T(i18n.Default, "wfh.reject_button"), CallbackData: fmt.Sprintf("wfh:reject:%d", request.ID)},
+		}}},
+	})
+}
+
+// handleWFHCallback handles the approve and reject buttons of a request to work from home
+func (b *Bot) handleWFHCallback(ctx context.Context, query *CallbackQuery, data string) error {
+	if query.Message == nil {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, "")
+	}
+	if !b.isAdminChat(query.Message.Chat.ID) && !b.isAdmin(ctx, query.From.ID) {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "wfh.admin_only"))
+	}
+
+	action, value, _ := strings.Cut(data, ":")
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || (action != "approve" && action != "reject") {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "common.button_expired"))
+	}
+	approve := action == "approve"
+
+	request, decided, err := b.attendanceService.DecideWFH(ctx, id, approve, query.From.ID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to decide wfh request", "wfh_id", id, "error", err)
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "wfh.decide_failed"))
+	}
+	if request == nil {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "wfh.not_found"))
+	}
+	if !decided {
+		return b.api.AnswerCallbackQuery(ctx, query.ID, tr(ctx, "wfh.already_"+request.Status))
+	}
+
+	audit, outcome := "wfh_rejected", "wfh.outcome_rejected"
+	if approve {
+		audit, outcome = "wfh_approved", "wfh.outcome_approved"
+	}
+	logging.FromContext(ctx).Warn("WFH request decided",
+		"audit", audit,
+		"wfh_id", request.ID,
+		"target_user_id", request.UserID,
+		"decided_by", query.From.ID)
+
+	if err := b.api.AnswerCallbackQuery(ctx, query.ID, ""); err != nil {
+		return err
+	}
+
+	// Keep the request in the admin chat, replacing the buttons with the decision
+	decision := query.Message.Text + "\n\n" + i18n.T(i18n.Default, "wfh.decided_by",
+		"Outcome", i18n.T(i18n.Default, outcome), "Name", strings.TrimSpace(query.From.FirstName+" "+query.From.LastName))
+	if err := b.api.EditMessageText(ctx, query.Message.Chat.ID, query.Message.MessageID, decision, nil); err != nil {
+		logging.FromContext(ctx).Warn("Failed to update wfh request message", "wfh_id", request.ID, "error", err)
+	}
+
+	// Users who never started a private chat with the bot cannot be notified
+	lang := b.languageOf(ctx, request.UserID)
+	notice := i18n.T(lang, "wfh.decided_notice", "Outcome", i18n.T(lang, outcome), "ID", request.ID, "Date", request.Date)
+	if err := b.sendMessage(ctx, request.UserID, notice); err != nil {
+		logging.FromContext(ctx).Info("Failed to notify user about wfh decision", "target_user_id", request.UserID, "error", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"attendance-bot/internal/attendance"
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// recordedIdentity returns the username and names stored with the sender's attendance records
+func recordedIdentity(from *User) (username, firstName string, lastName *string) {
+	username = from.Username
+	if username == "" {
+		username = fmt.Sprintf("user_%d", from.ID)
+	}
+
+	firstName = utils.SanitizeName(from.FirstName)
+	if from.LastName != "" {
+		lastNameVal := utils.SanitizeName(from.LastName)
+		lastName = &lastNameVal
+	}
+
+	return username, firstName, lastName
+}
+
+// handleWhoami handles the /whoami command, showing the identity data the bot records for the sender
+func (b *Bot) handleWhoami(ctx context.Context, msg *Message) error {
+	username, firstName, lastName := recordedIdentity(msg.From)
+
+	alias, err := b.attendanceService.GetUserAlias(ctx, msg.From.ID)
+	if err != nil {
+		return b.replyError(ctx, msg.Chat.ID, err, "action.get_alias", "Failed to get user alias")
+	}
+
+	var message strings.Builder
+	message.WriteString(tr(ctx, "whoami.title") + "\n\n")
+	message.WriteString(tr(ctx, "whoami.recorded") + "\n")
+	message.WriteString(tr(ctx, "whoami.telegram_id", "UserID", msg.From.ID) + "\n")
+
+	if msg.From.Username != "" {
+		message.WriteString(tr(ctx, "whoami.username", "Username", escapeMarkdown(username)) + "\n")
+	} else {
+		message.WriteString(tr(ctx, "whoami.username_missing", "Username", escapeMarkdown(username)) + "\n")
+	}
+
+	message.WriteString(tr(ctx, "whoami.first_name", "Name", storedName(ctx, firstName, msg.From.FirstName)) + "\n")
+	if lastName != nil {
+		message.WriteString(tr(ctx, "whoami.last_name", "Name", storedName(ctx, *lastName, msg.From.LastName)) + "\n")
+	} else {
+		message.WriteString(tr(ctx, "whoami.last_name", "Name", tr(ctx, "whoami.none")) + "\n")
+	}
+
+	if alias != nil {
+		message.WriteString(tr(ctx, "whoami.alias", "Alias", escapeMarkdown(attendance.FullName(alias.FirstName, alias.LastName))) + "\n")
+	} else {
+		message.WriteString(tr(ctx, "whoami.alias", "Alias", tr(ctx, "whoami.none")) + "\n")
+	}
+	message.WriteString(tr(ctx, "whoami.language", "Language", i18n.Name(i18n.FromContext(ctx))) + "\n")
+
+	message.WriteString("\n" + tr(ctx, "whoami.not_recorded") + "\n")
+	message.WriteString(tr(ctx, "whoami.chat_id", "ChatID", msg.Chat.ID) + "\n")
+	if msg.From.LanguageCode != "" {
+		message.WriteString(tr(ctx, "whoami.language_code", "Code", escapeMarkdown(msg.From.LanguageCode)) + "\n")
+	} else {
+		message.WriteString(tr(ctx, "whoami.language_code", "Code", tr(ctx, "whoami.not_detected")) + "\n")
+	}
+
+	return b.sendMarkdownMessage(ctx, msg.Chat.ID, message.String())
+}
+
+// storedName formats a sanitized name, adding the Telegram original when sanitizing changed it
+func storedName(ctx context.Context, stored, original string) string {
+	if stored == "" {
+		stored = tr(ctx, "whoami.empty")
+	}
+	if stored == original {
+		return escapeMarkdown(stored)
+	}
+	return tr(ctx, "whoami.sanitized", "Name", escapeMarkdown(stored), "Original", escapeMarkdown(original))
+}
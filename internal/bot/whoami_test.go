@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestWhoami sends /whoami from users with and without a username, a last name, an alias and a
+// language code, before any attendance is recorded
+func TestWhoami(t *testing.T) {
+	tests := []struct {
+		name  string
+		user  User
+		alias string
+		want  []string
+	}{
+		{
+			name: "full profile",
+			user: User{ID: 1501, Username: "sari_dewi", FirstName: "Sari", LastName: "Dewi", LanguageCode: "id"},
+			want: []string{
+				"• ID Telegram: 1501",
+				"• Username: @sari\\_dewi",
+				"• Nama depan: Sari",
+				"• Nama belakang: Dewi",
+				"• Alias: (tidak ada)",
+				"• Chat ID: 1501",
+				"• Kode bahasa: id",
+			},
+		},
+		{
+			name: "no username",
+			user: User{ID: 1502, FirstName: "Sari", LastName: "Dewi", LanguageCode: "id"},
+			want: []string{
+				"• Username: (tidak ada, dicatat sebagai user\\_1502)",
+				"• Nama depan: Sari",
+				"• Nama belakang: Dewi",
+			},
+		},
+		{
+			name: "no last name",
+			user: User{ID: 1503, Username: "budi", FirstName: "Budi"},
+			want: []string{
+				"• Username: @budi",
+				"• Nama depan: Budi",
+				"• Nama belakang: (tidak ada)",
+				"• Kode bahasa: (tidak terdeteksi)",
+			},
+		},
+		{
+			name: "neither",
+			user: User{ID: 1504, FirstName: "Budi"},
+			want: []string{
+				"• Username: (tidak ada, dicatat sebagai user\\_1504)",
+				"• Nama depan: Budi",
+				"• Nama belakang: (tidak ada)",
+			},
+		},
+		{
+			name: "sanitized names",
+			user: User{ID: 1505, Username: "troll", FirstName: "🔥Budi🔥", LastName: "💯"},
+			want: []string{
+				"• Nama depan: Budi (di Telegram: 🔥Budi🔥)",
+				"• Nama belakang: (kosong) (di Telegram: 💯)",
+			},
+		},
+		{
+			name:  "alias",
+			user:  User{ID: 1506, FirstName: "Nurul"},
+			alias: "Nurul_Ain",
+			want:  []string{"• Alias: Nurul\\_Ain"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := newTestBot(t)
+			if tt.alias != "" {
+				if err := tb.service.SetUserAlias(context.Background(), tt.user.ID, tt.alias, nil); err != nil {
+					t.Fatalf("SetUserAlias: %v", err)
+				}
+			}
+
+			user := tt.user
+			tb.deliver(t, &Message{From: &user, Chat: &Chat{ID: user.ID, Type: "private"}, Text: "/whoami"})
+
+			calls := tb.telegram.sent("sendMessage")
+			if mode := calls[len(calls)-1].Payload["parse_mode"]; mode != "Markdown" {
+				t.Errorf("parse_mode = %v, want Markdown", mode)
+			}
+			lines := strings.Split(tb.telegram.lastMessageTo(t, user.ID), "\n")
+			for _, want := range tt.want {
+				found := false
+				for _, line := range lines {
+					found = found || line == want
+				}
+				if !found {
+					t.Errorf("reply has no line %q:\n%s", want, strings.Join(lines, "\n"))
+				}
+			}
+		})
+	}
+}
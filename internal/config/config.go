@@ -1,30 +1,401 @@
 package config
 
 import (
+	"attendance-bot/internal/notify"
+	"attendance-bot/internal/scheduler"
+	"attendance-bot/internal/utils"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// defaultAPIKeyName names the API key given as API_TOKEN
+const defaultAPIKeyName = "default"
+
+// defaultHolidayFeedURL lists Indonesia's public holidays of {year}, from the Nager.Date API
+const defaultHolidayFeedURL = "https://date.nager.at/api/v3/PublicHolidays/{year}/ID"
+
 // Config holds all application configuration
 type Config struct {
-	BotToken      string
-	TOTPSecret    string
-	AdminPassword string
-	Environment   string
-	DatabasePath  string
+	BotToken            string
+	TOTPSecret          string
+	TOTPSecretPrevious  string
+	TOTPRotatedAt       time.Time
+	TOTPRotationGrace   int // Days the previous secret stays valid after TOTPRotatedAt
+	TOTPAlgorithm       string
+	TOTPDigits          int
+	TOTPPeriod          int
+	TOTPSkew            int
+	SecretsKey          string // Base64 32-byte key encrypting per-user secrets at rest
+	SecretsKeyPrevious  string // Previous key, set while re-encrypting after a key rotation
+	SuperAdminID        int64  // User who manages admins with /admin; never stored in the database
+	AdminChatID         int64  // Chat receiving security alerts and allowed to run admin commands
+	OTPFailureLimit     int    // Failed OTP attempts within OTPFailureWindow that trigger an alert
+	OTPFailureWindow    int    // Minutes
+	OTPLockoutLimit     int    // Failed OTP attempts within OTPFailureWindow that lock a user out, 0 disables
+	OTPLockoutMinutes   int    // How long a lockout lasts
+	Environment         string
+	DatabasePath        string
+	DatabaseURL         string             // postgres:// URL or sqlite:PATH, used instead of DatabasePath when set
+	AutoMigrate         bool               // Apply pending schema migrations at start-up
+	LogLevel            string             // debug, info, warn or error
+	LogFormat           string             // text or json
+	LogFile             string             // Log file path, logs go to stdout when empty
+	LogMaxSizeMB        int                // Size at which the log file is rotated
+	LogMaxBackups       int                // Rotated log files to keep
+	LogMaxAgeDays       int                // Days rotated log files are kept, 0 keeps them regardless of age
+	LogStdoutLevel      string             // Minimum level also written to stdout when LogFile is set, or off
+	HealthAddr          string             // Listen address of the health endpoints, disabled when empty
+	APIAddr             string             // Listen address of the read-only HTTP API, disabled when empty
+	APIToken            string             // Bearer token required by the HTTP API
+	APIKeys             map[string]string  // API keys by client name, including APIToken as "default"
+	TelegramAPIURL      string             // Bot API server, defaults to the public endpoint
+	StaleUpdateCutoff   int                // Minutes after which queued messages are ignored, 0 disables
+	StaleCommandReply   bool               // Tell users their stale commands were ignored
+	ReportCacheSeconds  int                // Seconds a rendered daily report is reused, 0 disables
+	ExpectedWorkHours   int                // Working hours expected per day, 0 disables the target in /duration
+	PhotoWindow         int                // Minutes after a check-in a selfie may be attached, 0 disables photos
+	OvertimeThreshold   int                // Minutes past the end of the shift a check-out must be to count as overtime
+	MaxBreaks           int                // Breaks a user may take a day with /break, 0 turns breaks off
+	GeofenceMode        string             // How check-ins are judged against the geofences: off, flag or require
+	SupervisorIDs       []int64            // Users allowed to subscribe to company-wide reports
+	Timezone            string             // IANA timezone dates, shifts, schedules and reports are in, unless a user sets their own
+	DailyReportTime     string             // HH:MM (TIMEZONE) the daily report is sent to subscribers, or off
+	AutoCheckout        string             // What happens to check-ins still open at AutoCheckoutTime: off, record or remind
+	AutoCheckoutTime    string             // HH:MM (TIMEZONE) open check-ins are closed or their users reminded
+	LiveReportChatID    int64              // Chat where the pinned live report is kept, disabled when 0
+	LiveReportOpen      string             // HH:MM (TIMEZONE) the day's live report is posted
+	LiveReportClose     string             // HH:MM (TIMEZONE) the live report is closed
+	KioskChatID         int64              // Chat of the shared office kiosk account, disabled when 0
+	ReportChatID        int64              // Group chat the daily report is posted to on ReportSchedule, disabled when 0
+	ReportSchedule      string             // Cron expression (TIMEZONE) for posting to ReportChatID
+	ReportSkipHolidays  bool               // Do not post to ReportChatID on Holidays
+	WeeklySummary       string             // Cron expression (TIMEZONE) employees get last week's summary on, or off
+	LateAlerts          string             // How late arrivals are reported to LateAlertChatID: off, instant or digest
+	LateAlertChatID     int64              // Chat late arrival alerts are sent to, AdminChatID when unset
+	LateAlertDigestTime string             // HH:MM (TIMEZONE) the day's late arrivals are sent when LateAlerts is digest
+	Holidays            scheduler.Holidays // Dates skipped by holiday-aware scheduled jobs
+	HolidayFeedURL      string             // Where /holiday import gets national holidays, {year} replaced; disabled when empty
+	WebhookURL          string             // Public HTTPS URL Telegram delivers updates to; long polling is used when empty
+	WebhookPort         int                // Local port of the webhook server, usually behind a TLS-terminating proxy
+	WebhookSecret       string             // Secret Telegram sends in every webhook request
+	EventWebhookURLs    []string           // URLs attendance events are POSTed to, disabled when empty
+	EventWebhookSecret  string             // Key signing event webhook requests, unsigned when empty
+	EventWebhookTries   int                // Attempts per event webhook delivery before it is recorded as failed
+	SlackWebhookURL     string             // Slack incoming webhook messages are mirrored to, disabled when empty
+	DiscordWebhookURL   string             // Discord webhook messages are mirrored to, disabled when empty
+	NotifyEvents        []string           // Messages mirrored to Slack and Discord, see notify.Events
+	RequireRegistration bool               // Only users registered with /registeruser may mark attendance
+	WFHApproval         bool               // Requests to work from home wait for an admin's approval
+	BackupSchedule      string             // Cron expression (TIMEZONE) SQLite backups are made on, or off
+	BackupDir           string             // Directory backups are written to
+	BackupKeep          int                // Backups kept in BackupDir, older ones are deleted
+	BackupS3Endpoint    string             // S3-compatible endpoint backups are uploaded to
+	BackupS3Bucket      string             // Bucket backups are uploaded to, uploads disabled when empty
+	BackupS3Region      string             // Region the uploads are signed for
+	BackupS3Prefix      string             // Prepended to the backup file names to form object keys
+	BackupS3AccessKey   string
+	BackupS3SecretKey   string
 }
 
 // Load reads configuration from environment variables
 func Load() (*Config, error) {
+	return load(os.Getenv, nil)
+}
+
+// load reads configuration through getenv, applies flag overrides and validates the result
+func load(getenv lookupFunc, flags *Flags) (*Config, error) {
+	totpDigits, err := getenv.intWithDefault("TOTP_DIGITS", 6)
+	if err != nil {
+		return nil, err
+	}
+
+	totpPeriod, err := getenv.intWithDefault("TOTP_PERIOD", 30)
+	if err != nil {
+		return nil, err
+	}
+
+	totpSkew, err := getenv.intWithDefault("TOTP_SKEW", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	totpRotationGrace, err := getenv.intWithDefault("TOTP_ROTATION_GRACE_DAYS", 7)
+	if err != nil {
+		return nil, err
+	}
+
+	var totpRotatedAt time.Time
+	if value := getenv("TOTP_ROTATED_AT"); value != "" {
+		totpRotatedAt, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for TOTP_ROTATED_AT (expected YYYY-MM-DD): %w", err)
+		}
+	}
+
+	otpFailureLimit, err := getenv.intWithDefault("OTP_FAILURE_THRESHOLD", 5)
+	if err != nil {
+		return nil, err
+	}
+
+	otpFailureWindow, err := getenv.intWithDefault("OTP_FAILURE_WINDOW_MINUTES", 15)
+	if err != nil {
+		return nil, err
+	}
+
+	otpLockoutLimit, err := getenv.intWithDefault("OTP_LOCKOUT_THRESHOLD", 10)
+	if err != nil {
+		return nil, err
+	}
+
+	otpLockoutMinutes, err := getenv.intWithDefault("OTP_LOCKOUT_MINUTES", 30)
+	if err != nil {
+		return nil, err
+	}
+
+	staleUpdateCutoff, err := getenv.intWithDefault("STALE_UPDATE_MINUTES", 5)
+	if err != nil {
+		return nil, err
+	}
+
+	reportCacheSeconds, err := getenv.intWithDefault("REPORT_CACHE_SECONDS", 30)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedWorkHours, err := getenv.intWithDefault("EXPECTED_WORK_HOURS", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	photoWindow, err := getenv.intWithDefault("PHOTO_WINDOW_MINUTES", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	overtimeThreshold, err := getenv.intWithDefault("OVERTIME_THRESHOLD_MINUTES", 30)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBreaks, err := getenv.intWithDefault("MAX_BREAKS_PER_DAY", 3)
+	if err != nil {
+		return nil, err
+	}
+
+	logMaxSize, err := getenv.intWithDefault("LOG_MAX_SIZE_MB", 100)
+	if err != nil {
+		return nil, err
+	}
+
+	logMaxBackups, err := getenv.intWithDefault("LOG_MAX_BACKUPS", 5)
+	if err != nil {
+		return nil, err
+	}
+
+	logMaxAge, err := getenv.intWithDefault("LOG_MAX_AGE_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookPort, err := getenv.intWithDefault("WEBHOOK_PORT", 8443)
+	if err != nil {
+		return nil, err
+	}
+
+	eventWebhookTries, err := getenv.intWithDefault("EVENT_WEBHOOK_MAX_ATTEMPTS", 5)
+	if err != nil {
+		return nil, err
+	}
+
+	supervisorIDs, err := getenv.int64List("SUPERVISOR_IDS")
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := getenv.namedList("API_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	if token := getenv("API_TOKEN"); token != "" {
+		if _, ok := apiKeys[defaultAPIKeyName]; ok {
+			return nil, fmt.Errorf("invalid value for API_KEYS: %q is reserved for API_TOKEN", defaultAPIKeyName)
+		}
+		apiKeys[defaultAPIKeyName] = token
+	}
+
+	var superAdminID int64
+	if value := getenv("SUPER_ADMIN_ID"); value != "" {
+		superAdminID, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for SUPER_ADMIN_ID: %w", err)
+		}
+	}
+
+	var adminChatID int64
+	if value := getenv("ADMIN_CHAT_ID"); value != "" {
+		adminChatID, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for ADMIN_CHAT_ID: %w", err)
+		}
+	}
+
+	var liveReportChatID int64
+	if value := getenv("LIVE_REPORT_CHAT_ID"); value != "" {
+		liveReportChatID, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for LIVE_REPORT_CHAT_ID: %w", err)
+		}
+	}
+
+	var kioskChatID int64
+	if value := getenv("KIOSK_CHAT_ID"); value != "" {
+		kioskChatID, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for KIOSK_CHAT_ID: %w", err)
+		}
+	}
+
+	var reportChatID int64
+	if value := getenv("REPORT_CHAT_ID"); value != "" {
+		reportChatID, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for REPORT_CHAT_ID: %w", err)
+		}
+	}
+
+	lateAlertChatID := adminChatID
+	if value := getenv("LATE_ALERT_CHAT_ID"); value != "" {
+		lateAlertChatID, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for LATE_ALERT_CHAT_ID: %w", err)
+		}
+	}
+
+	holidayFeedURL := getenv.withDefault("HOLIDAY_FEED_URL", defaultHolidayFeedURL)
+	if strings.ToLower(holidayFeedURL) == "off" {
+		holidayFeedURL = ""
+	}
+
+	backupKeep, err := getenv.intWithDefault("BACKUP_KEEP", 7)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := scheduler.ParseHolidays(strings.Split(getenv("HOLIDAYS"), ","))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for HOLIDAYS: %w", err)
+	}
+
 	cfg := &Config{
-		BotToken:      os.Getenv("BOT_TOKEN"),
-		TOTPSecret:    os.Getenv("TOTP_SECRET"),
-		AdminPassword: os.Getenv("ADMIN_PASSWORD"),
-		Environment:   getEnvWithDefault("NODE_ENV", "development"),
-		DatabasePath:  getEnvWithDefault("DATABASE_PATH", "data/attendance.db"),
+		BotToken:            getenv("BOT_TOKEN"),
+		TOTPSecret:          normalizeOptionalSecret(getenv("TOTP_SECRET")),
+		TOTPSecretPrevious:  normalizeOptionalSecret(getenv("TOTP_SECRET_PREVIOUS")),
+		TOTPRotatedAt:       totpRotatedAt,
+		TOTPRotationGrace:   totpRotationGrace,
+		TOTPAlgorithm:       strings.ToUpper(getenv.withDefault("TOTP_ALGORITHM", "SHA1")),
+		TOTPDigits:          totpDigits,
+		TOTPPeriod:          totpPeriod,
+		TOTPSkew:            totpSkew,
+		SecretsKey:          getenv("SECRETS_ENCRYPTION_KEY"),
+		SecretsKeyPrevious:  getenv("SECRETS_ENCRYPTION_KEY_PREVIOUS"),
+		SuperAdminID:        superAdminID,
+		AdminChatID:         adminChatID,
+		OTPFailureLimit:     otpFailureLimit,
+		OTPFailureWindow:    otpFailureWindow,
+		OTPLockoutLimit:     otpLockoutLimit,
+		OTPLockoutMinutes:   otpLockoutMinutes,
+		Environment:         getenv.withDefault("NODE_ENV", "development"),
+		DatabasePath:        getenv.withDefault("DATABASE_PATH", "data/attendance.db"),
+		DatabaseURL:         getenv("DATABASE_URL"),
+		AutoMigrate:         getenv.withDefault("AUTO_MIGRATE", "true") != "false",
+		LogLevel:            strings.ToLower(getenv.withDefault("LOG_LEVEL", "info")),
+		LogFormat:           strings.ToLower(getenv.withDefault("LOG_FORMAT", "text")),
+		LogFile:             getenv("LOG_FILE"),
+		LogMaxSizeMB:        logMaxSize,
+		LogMaxBackups:       logMaxBackups,
+		LogMaxAgeDays:       logMaxAge,
+		LogStdoutLevel:      strings.ToLower(getenv.withDefault("LOG_STDOUT_LEVEL", "warn")),
+		HealthAddr:          getenv("HEALTH_ADDR"),
+		APIAddr:             getenv("API_ADDR"),
+		APIToken:            getenv("API_TOKEN"),
+		APIKeys:             apiKeys,
+		TelegramAPIURL:      getenv("TELEGRAM_API_URL"),
+		StaleUpdateCutoff:   staleUpdateCutoff,
+		StaleCommandReply:   getenv("STALE_COMMAND_REPLY") == "true",
+		ReportCacheSeconds:  reportCacheSeconds,
+		ExpectedWorkHours:   expectedWorkHours,
+		PhotoWindow:         photoWindow,
+		OvertimeThreshold:   overtimeThreshold,
+		MaxBreaks:           maxBreaks,
+		GeofenceMode:        strings.ToLower(getenv.withDefault("GEOFENCE_MODE", "off")),
+		SupervisorIDs:       supervisorIDs,
+		Timezone:            getenv.withDefault("TIMEZONE", utils.DefaultTimezone),
+		DailyReportTime:     strings.ToLower(getenv.withDefault("DAILY_REPORT_TIME", "17:30")),
+		AutoCheckout:        strings.ToLower(getenv.withDefault("AUTO_CHECKOUT", "off")),
+		AutoCheckoutTime:    getenv.withDefault("AUTO_CHECKOUT_TIME", "23:55"),
+		LiveReportChatID:    liveReportChatID,
+		LiveReportOpen:      getenv.withDefault("LIVE_REPORT_OPEN", "07:00"),
+		LiveReportClose:     getenv.withDefault("LIVE_REPORT_CLOSE", "20:00"),
+		KioskChatID:         kioskChatID,
+		ReportChatID:        reportChatID,
+		ReportSchedule:      getenv.withDefault("REPORT_SCHEDULE", "0 18 * * *"),
+		WeeklySummary:       strings.ToLower(getenv.withDefault("WEEKLY_SUMMARY_SCHEDULE", "0 8 * * 1")),
+		LateAlerts:          strings.ToLower(getenv.withDefault("LATE_ALERTS", "off")),
+		LateAlertChatID:     lateAlertChatID,
+		LateAlertDigestTime: getenv.withDefault("LATE_ALERT_DIGEST_TIME", "12:00"),
+		ReportSkipHolidays:  getenv.withDefault("REPORT_SKIP_HOLIDAYS", "true") != "false",
+		Holidays:            holidays,
+		HolidayFeedURL:      holidayFeedURL,
+		WebhookURL:          getenv("WEBHOOK_URL"),
+		WebhookPort:         webhookPort,
+		WebhookSecret:       getenv("WEBHOOK_SECRET"),
+		EventWebhookURLs:    getenv.stringList("EVENT_WEBHOOK_URLS"),
+		EventWebhookSecret:  getenv("EVENT_WEBHOOK_SECRET"),
+		EventWebhookTries:   eventWebhookTries,
+		SlackWebhookURL:     getenv("SLACK_WEBHOOK_URL"),
+		DiscordWebhookURL:   getenv("DISCORD_WEBHOOK_URL"),
+		NotifyEvents:        getenv.stringList("NOTIFY_EVENTS"),
+		RequireRegistration: getenv("REQUIRE_REGISTRATION") == "true",
+		WFHApproval:         getenv("WFH_APPROVAL") == "true",
+		BackupSchedule:      getenv.withDefault("BACKUP_SCHEDULE", "off"),
+		BackupDir:           getenv.withDefault("BACKUP_DIR", "data/backups"),
+		BackupKeep:          backupKeep,
+		BackupS3Endpoint:    getenv("BACKUP_S3_ENDPOINT"),
+		BackupS3Bucket:      getenv("BACKUP_S3_BUCKET"),
+		BackupS3Region:      getenv.withDefault("BACKUP_S3_REGION", "us-east-1"),
+		BackupS3Prefix:      getenv("BACKUP_S3_PREFIX"),
+		BackupS3AccessKey:   getenv("BACKUP_S3_ACCESS_KEY"),
+		BackupS3SecretKey:   getenv("BACKUP_S3_SECRET_KEY"),
+	}
+	if cfg.NotifyEvents == nil {
+		cfg.NotifyEvents = notify.Events
 	}
 
+	// A key URI, e.g. from the provisioning sheet of hardware tokens, sets the secret together with
+	// its algorithm, digits and period
+	if uri := getenv("TOTP_URI"); uri != "" {
+		if cfg.TOTPSecret != "" {
+			return nil, fmt.Errorf("TOTP_URI and TOTP_SECRET are both set, use only one")
+		}
+		key, err := utils.ParseOTPAuthURI(uri)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for TOTP_URI: %w", err)
+		}
+		cfg.TOTPSecret = key.Secret
+		cfg.TOTPAlgorithm = key.Algorithm
+		cfg.TOTPDigits = key.Digits
+		cfg.TOTPPeriod = key.Period
+	}
+
+	flags.apply(cfg)
+
 	// Validate required fields
 	if err := cfg.validate(); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -45,17 +416,263 @@ func (c *Config) validate() error {
 	}
 
 	if c.TOTPSecret == "" {
-		missing = append(missing, "TOTP_SECRET")
+		missing = append(missing, "TOTP_SECRET (or TOTP_URI)")
 	}
 	if len(c.TOTPSecret) < 16 {
 		missing = append(missing, "TOTP_SECRET (must be at least 16 characters)")
 	}
 
-	if c.AdminPassword == "" {
-		missing = append(missing, "ADMIN_PASSWORD")
+	switch c.TOTPAlgorithm {
+	case "SHA1", "SHA256", "SHA512":
+	default:
+		missing = append(missing, "TOTP_ALGORITHM (must be SHA1, SHA256 or SHA512)")
+	}
+
+	if c.TOTPDigits < 6 || c.TOTPDigits > 8 {
+		missing = append(missing, "TOTP_DIGITS (must be between 6 and 8)")
+	}
+
+	if c.TOTPPeriod <= 0 {
+		missing = append(missing, "TOTP_PERIOD (must be a positive number of seconds)")
+	}
+
+	if c.TOTPSecretPrevious != "" && c.TOTPRotatedAt.IsZero() {
+		missing = append(missing, "TOTP_ROTATED_AT (required when TOTP_SECRET_PREVIOUS is set)")
+	}
+
+	if c.TOTPRotationGrace < 0 {
+		missing = append(missing, "TOTP_ROTATION_GRACE_DAYS (must not be negative)")
+	}
+
+	if c.TOTPSkew < 0 || c.TOTPSkew > 10 {
+		missing = append(missing, "TOTP_SKEW (must be between 0 and 10)")
+	}
+
+	if c.SecretsKeyPrevious != "" && c.SecretsKey == "" {
+		missing = append(missing, "SECRETS_ENCRYPTION_KEY (required when SECRETS_ENCRYPTION_KEY_PREVIOUS is set)")
+	}
+
+	if c.DatabaseURL != "" && !isDatabaseURL(c.DatabaseURL) {
+		missing = append(missing, "DATABASE_URL (must be a postgres:// URL or sqlite:PATH)")
+	}
+
+	if c.SuperAdminID <= 0 {
+		missing = append(missing, "SUPER_ADMIN_ID (must be the super-admin's Telegram user ID)")
+	}
+
+	if c.OTPFailureLimit <= 0 {
+		missing = append(missing, "OTP_FAILURE_THRESHOLD (must be positive)")
+	}
+
+	if c.OTPFailureWindow <= 0 {
+		missing = append(missing, "OTP_FAILURE_WINDOW_MINUTES (must be positive)")
+	}
+
+	if c.OTPLockoutLimit < 0 {
+		missing = append(missing, "OTP_LOCKOUT_THRESHOLD (must not be negative)")
+	}
+
+	if c.OTPLockoutLimit > 0 && c.OTPLockoutMinutes <= 0 {
+		missing = append(missing, "OTP_LOCKOUT_MINUTES (must be positive)")
+	}
+
+	if c.StaleUpdateCutoff < 0 {
+		missing = append(missing, "STALE_UPDATE_MINUTES (must not be negative)")
+	}
+
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		missing = append(missing, "LOG_LEVEL (must be debug, info, warn or error)")
+	}
+
+	switch c.LogFormat {
+	case "text", "json":
+	default:
+		missing = append(missing, "LOG_FORMAT (must be text or json)")
+	}
+
+	if c.APIAddr != "" && len(c.APIKeys) == 0 {
+		missing = append(missing, "API_KEYS or API_TOKEN (required when API_ADDR is set)")
+	}
+	for _, name := range c.APIKeyNames() {
+		switch {
+		case len(c.APIKeys[name]) >= 16:
+		case name == defaultAPIKeyName:
+			missing = append(missing, "API_TOKEN (at least 16 characters)")
+		default:
+			missing = append(missing, fmt.Sprintf("API_KEYS key %s (at least 16 characters)", name))
+		}
+	}
+
+	if c.ExpectedWorkHours < 0 || c.ExpectedWorkHours > 24 {
+		missing = append(missing, "EXPECTED_WORK_HOURS (must be between 0 and 24)")
+	}
+
+	if c.PhotoWindow < 0 || c.PhotoWindow > 24*60 {
+		missing = append(missing, "PHOTO_WINDOW_MINUTES (must be between 0 and 1440)")
+	}
+
+	if c.OvertimeThreshold < 0 || c.OvertimeThreshold > 24*60 {
+		missing = append(missing, "OVERTIME_THRESHOLD_MINUTES (must be between 0 and 1440)")
+	}
+
+	if c.MaxBreaks < 0 || c.MaxBreaks > 20 {
+		missing = append(missing, "MAX_BREAKS_PER_DAY (must be between 0 and 20)")
+	}
+
+	switch c.GeofenceMode {
+	case "off", "flag", "require":
+	default:
+		missing = append(missing, "GEOFENCE_MODE (must be off, flag or require)")
 	}
-	if len(c.AdminPassword) < 8 {
-		missing = append(missing, "ADMIN_PASSWORD (must be at least 8 characters)")
+
+	if _, err := utils.LoadLocation(c.Timezone); err != nil {
+		missing = append(missing, "TIMEZONE (must be an IANA timezone such as Asia/Jakarta)")
+	}
+
+	if c.DailyReportTime != "off" {
+		if _, err := utils.ParseClock(c.DailyReportTime); err != nil {
+			missing = append(missing, "DAILY_REPORT_TIME (must be HH:MM or off)")
+		}
+	}
+
+	switch c.AutoCheckout {
+	case "off":
+	case "record", "remind":
+		if _, err := utils.ParseClock(c.AutoCheckoutTime); err != nil {
+			missing = append(missing, "AUTO_CHECKOUT_TIME (must be HH:MM)")
+		}
+	default:
+		missing = append(missing, "AUTO_CHECKOUT (must be off, record or remind)")
+	}
+
+	if c.LiveReportChatID != 0 {
+		open, openErr := utils.ParseClock(c.LiveReportOpen)
+		closing, closeErr := utils.ParseClock(c.LiveReportClose)
+		switch {
+		case openErr != nil:
+			missing = append(missing, "LIVE_REPORT_OPEN (must be HH:MM)")
+		case closeErr != nil:
+			missing = append(missing, "LIVE_REPORT_CLOSE (must be HH:MM)")
+		case closing.Hour*60+closing.Minute <= open.Hour*60+open.Minute:
+			missing = append(missing, "LIVE_REPORT_CLOSE (must be later than LIVE_REPORT_OPEN)")
+		}
+	}
+
+	if c.ReportChatID != 0 {
+		if _, err := scheduler.Parse(c.ReportSchedule); err != nil {
+			missing = append(missing, "REPORT_SCHEDULE (must be a cron expression, e.g. 0 18 * * 1-5)")
+		}
+	}
+
+	if c.WebhookURL != "" {
+		if parsed, err := url.Parse(c.WebhookURL); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			missing = append(missing, "WEBHOOK_URL (must be an https:// URL)")
+		}
+		if c.WebhookPort <= 0 || c.WebhookPort > 65535 {
+			missing = append(missing, "WEBHOOK_PORT (must be between 1 and 65535)")
+		}
+		if !webhookSecretPattern.MatchString(c.WebhookSecret) {
+			missing = append(missing, "WEBHOOK_SECRET (16-256 characters of A-Z, a-z, 0-9, _ and -, required when WEBHOOK_URL is set)")
+		}
+	}
+
+	for _, target := range c.EventWebhookURLs {
+		if parsed, err := url.Parse(target); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			missing = append(missing, "EVENT_WEBHOOK_URLS (must be comma-separated http:// or https:// URLs)")
+			break
+		}
+	}
+	if c.EventWebhookSecret != "" && len(c.EventWebhookSecret) < 16 {
+		missing = append(missing, "EVENT_WEBHOOK_SECRET (must be at least 16 characters)")
+	}
+	if c.EventWebhookTries < 1 || c.EventWebhookTries > 20 {
+		missing = append(missing, "EVENT_WEBHOOK_MAX_ATTEMPTS (must be between 1 and 20)")
+	}
+
+	if c.HolidayFeedURL != "" {
+		if parsed, err := url.Parse(c.HolidayFeedURL); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			missing = append(missing, "HOLIDAY_FEED_URL (must be an http:// or https:// URL, or off)")
+		}
+	}
+
+	if c.SlackWebhookURL != "" {
+		if parsed, err := url.Parse(c.SlackWebhookURL); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			missing = append(missing, "SLACK_WEBHOOK_URL (must be an https:// URL)")
+		}
+	}
+	if c.DiscordWebhookURL != "" {
+		if parsed, err := url.Parse(c.DiscordWebhookURL); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+			missing = append(missing, "DISCORD_WEBHOOK_URL (must be an https:// URL)")
+		}
+	}
+	for _, event := range c.NotifyEvents {
+		if !slices.Contains(notify.Events, event) {
+			missing = append(missing, "NOTIFY_EVENTS (must be a comma-separated list of "+strings.Join(notify.Events, ", ")+")")
+			break
+		}
+	}
+
+	if c.WeeklySummary != "off" {
+		if _, err := scheduler.Parse(c.WeeklySummary); err != nil {
+			missing = append(missing, "WEEKLY_SUMMARY_SCHEDULE (must be a cron expression, e.g. 0 8 * * 1, or off)")
+		}
+	}
+
+	switch c.LateAlerts {
+	case "off":
+	case "instant", "digest":
+		if c.LateAlertChatID == 0 {
+			missing = append(missing, "LATE_ALERT_CHAT_ID (or ADMIN_CHAT_ID, required when LATE_ALERTS is on)")
+		}
+		if _, err := utils.ParseClock(c.LateAlertDigestTime); err != nil && c.LateAlerts == "digest" {
+			missing = append(missing, "LATE_ALERT_DIGEST_TIME (must be HH:MM)")
+		}
+	default:
+		missing = append(missing, "LATE_ALERTS (must be off, instant or digest)")
+	}
+
+	if c.BackupSchedule != "off" {
+		if _, err := scheduler.Parse(c.BackupSchedule); err != nil {
+			missing = append(missing, "BACKUP_SCHEDULE (must be a cron expression, e.g. 0 2 * * *, or off)")
+		}
+		if strings.HasPrefix(c.DatabaseURL, "postgres") {
+			missing = append(missing, "BACKUP_SCHEDULE (only SQLite databases are backed up, use pg_dump for PostgreSQL)")
+		}
+	}
+	if c.BackupKeep < 1 || c.BackupKeep > 1000 {
+		missing = append(missing, "BACKUP_KEEP (must be between 1 and 1000)")
+	}
+	if c.BackupS3Bucket != "" {
+		if parsed, err := url.Parse(c.BackupS3Endpoint); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			missing = append(missing, "BACKUP_S3_ENDPOINT (must be an http:// or https:// URL when BACKUP_S3_BUCKET is set)")
+		}
+		if c.BackupS3AccessKey == "" || c.BackupS3SecretKey == "" {
+			missing = append(missing, "BACKUP_S3_ACCESS_KEY and BACKUP_S3_SECRET_KEY (required when BACKUP_S3_BUCKET is set)")
+		}
+	}
+
+	if c.ReportCacheSeconds < 0 {
+		missing = append(missing, "REPORT_CACHE_SECONDS (must not be negative)")
+	}
+
+	if c.LogMaxSizeMB <= 0 {
+		missing = append(missing, "LOG_MAX_SIZE_MB (must be positive)")
+	}
+
+	if c.LogMaxBackups < 0 {
+		missing = append(missing, "LOG_MAX_BACKUPS (must not be negative)")
+	}
+
+	if c.LogMaxAgeDays < 0 {
+		missing = append(missing, "LOG_MAX_AGE_DAYS (must not be negative)")
+	}
+
+	switch c.LogStdoutLevel {
+	case "debug", "info", "warn", "error", "off":
+	default:
+		missing = append(missing, "LOG_STDOUT_LEVEL (must be debug, info, warn, error or off)")
 	}
 
 	if len(missing) > 0 {
@@ -65,6 +682,151 @@ func (c *Config) validate() error {
 	return nil
 }
 
+// IsSupervisor reports whether the user may subscribe to company-wide reports
+func (c *Config) IsSupervisor(userID int64) bool {
+	for _, id := range c.SupervisorIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// DailyReportClock returns when the daily report is sent, and false if it is disabled
+func (c *Config) DailyReportClock() (utils.Clock, bool) {
+	if c.DailyReportTime == "off" {
+		return utils.Clock{}, false
+	}
+
+	clock, err := utils.ParseClock(c.DailyReportTime)
+	return clock, err == nil
+}
+
+// Mirrors reports whether messages of the event are mirrored to Slack or Discord
+func (c *Config) Mirrors(event string) bool {
+	if c.SlackWebhookURL == "" && c.DiscordWebhookURL == "" {
+		return false
+	}
+	return slices.Contains(c.NotifyEvents, event)
+}
+
+// AutoCheckoutClock returns when open check-ins are handled, and false if AUTO_CHECKOUT is off
+func (c *Config) AutoCheckoutClock() (utils.Clock, bool) {
+	if c.AutoCheckout == "off" {
+		return utils.Clock{}, false
+	}
+
+	clock, err := utils.ParseClock(c.AutoCheckoutTime)
+	return clock, err == nil
+}
+
+// ReportBroadcast returns when the daily report is posted to ReportChatID, and false if it is disabled
+func (c *Config) ReportBroadcast() (*scheduler.Schedule, bool) {
+	if c.ReportChatID == 0 {
+		return nil, false
+	}
+
+	schedule, err := scheduler.Parse(c.ReportSchedule)
+	return schedule, err == nil
+}
+
+// WeeklySummaryScheduled returns when employees get last week's summary, and false if the weekly
+// summary is off
+func (c *Config) WeeklySummaryScheduled() (*scheduler.Schedule, bool) {
+	if c.WeeklySummary == "off" {
+		return nil, false
+	}
+
+	schedule, err := scheduler.Parse(c.WeeklySummary)
+	return schedule, err == nil
+}
+
+// LateAlertChat returns the chat late arrivals are reported to, and false if late alerts are off
+func (c *Config) LateAlertChat() (int64, bool) {
+	if c.LateAlerts == "off" || c.LateAlertChatID == 0 {
+		return 0, false
+	}
+	return c.LateAlertChatID, true
+}
+
+// LateAlertDigestClock returns when the day's late arrivals are sent, and false unless LATE_ALERTS
+// is digest
+func (c *Config) LateAlertDigestClock() (utils.Clock, bool) {
+	if _, ok := c.LateAlertChat(); !ok || c.LateAlerts != "digest" {
+		return utils.Clock{}, false
+	}
+
+	clock, err := utils.ParseClock(c.LateAlertDigestTime)
+	return clock, err == nil
+}
+
+// BackupScheduled returns when backups are made, and false if scheduled backups are off
+func (c *Config) BackupScheduled() (*scheduler.Schedule, bool) {
+	if c.BackupSchedule == "off" {
+		return nil, false
+	}
+
+	schedule, err := scheduler.Parse(c.BackupSchedule)
+	return schedule, err == nil
+}
+
+// webhookSecretPattern matches the secret tokens Telegram accepts, with a minimum length
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{16,256}$`)
+
+// APIKeyNames returns the names of the configured API keys in sorted order
+func (c *Config) APIKeyNames() []string {
+	names := make([]string, 0, len(c.APIKeys))
+	for name := range c.APIKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseWebhook reports whether updates are received by webhook instead of long polling
+func (c *Config) UseWebhook() bool {
+	return c.WebhookURL != ""
+}
+
+// LiveReportHours returns when the live report is posted and closed, and false if it is disabled
+func (c *Config) LiveReportHours() (open, closing utils.Clock, ok bool) {
+	if c.LiveReportChatID == 0 {
+		return utils.Clock{}, utils.Clock{}, false
+	}
+
+	open, openErr := utils.ParseClock(c.LiveReportOpen)
+	closing, closeErr := utils.ParseClock(c.LiveReportClose)
+	return open, closing, openErr == nil && closeErr == nil
+}
+
+// DatabaseTarget returns the database to open: DATABASE_URL when set, otherwise the SQLite
+// database at DATABASE_PATH
+func (c *Config) DatabaseTarget() string {
+	if c.DatabaseURL != "" {
+		return c.DatabaseURL
+	}
+	return c.DatabasePath
+}
+
+// isDatabaseURL reports whether value names a PostgreSQL or SQLite database
+func isDatabaseURL(value string) bool {
+	for _, prefix := range []string{"postgres://", "postgresql://", "sqlite:"} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactDatabaseURL masks the password of a database URL, for logging
+func redactDatabaseURL(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	return parsed.Redacted()
+}
+
 // IsDevelopment returns true if running in development mode
 func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development"
@@ -75,10 +837,89 @@ func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
 }
 
-// getEnvWithDefault returns the environment variable value or a default if not set
-func getEnvWithDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+// normalizeOptionalSecret normalizes a base32 secret, leaving an unset value empty
+func normalizeOptionalSecret(secret string) string {
+	if strings.TrimSpace(secret) == "" {
+		return ""
+	}
+	return utils.NormalizeSecret(secret)
+}
+
+// lookupFunc returns the value of a configuration key, or "" if it is not set
+type lookupFunc func(key string) string
+
+// withDefault returns the value for key or a default if not set
+func (getenv lookupFunc) withDefault(key, defaultValue string) string {
+	if value := getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+// intWithDefault returns the value for key parsed as an integer or a default if not set
+func (getenv lookupFunc) intWithDefault(key string, defaultValue int) (int, error) {
+	value := getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	return parsed, nil
+}
+
+// namedList returns the comma-separated name:value pairs for key as a map, which is empty if not set
+func (getenv lookupFunc) namedList(key string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, field := range strings.Split(getenv(key), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(field, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid value for %s: expected name:value pairs", key)
+		}
+		if _, ok := values[name]; ok {
+			return nil, fmt.Errorf("invalid value for %s: duplicate name %q", key, name)
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// stringList returns the comma-separated value for key, or nil if not set
+func (getenv lookupFunc) stringList(key string) []string {
+	var values []string
+	for _, field := range strings.Split(getenv(key), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			values = append(values, field)
+		}
+	}
+	return values
+}
+
+// int64List returns the comma-separated value for key parsed as integers, or nil if not set
+func (getenv lookupFunc) int64List(key string) ([]int64, error) {
+	var values []int64
+	for _, field := range strings.Split(getenv(key), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		parsed, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		values = append(values, parsed)
+	}
+
+	return values, nil
+}
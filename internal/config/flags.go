@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Flags holds the command-line flags of the bot. Empty values leave the configuration unchanged.
+type Flags struct {
+	ConfigPath   string // KEY=VALUE file read for settings not present in the environment
+	DatabasePath string
+	LogLevel     string
+	LogFormat    string
+	DryRun       bool // Validate configuration, connect to the database and Telegram, then exit
+}
+
+// ParseFlags parses the bot's command-line arguments
+func ParseFlags(args []string, output io.Writer) (*Flags, error) {
+	flags := &Flags{}
+
+	fs := flag.NewFlagSet("bot", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.StringVar(&flags.ConfigPath, "config", "", "path to a KEY=VALUE config file (environment variables take precedence)")
+	fs.StringVar(&flags.DatabasePath, "db", "", "SQLite database path or PostgreSQL URL (overrides DATABASE_PATH and DATABASE_URL)")
+	fs.StringVar(&flags.LogLevel, "log-level", "", "debug, info, warn or error (overrides LOG_LEVEL)")
+	fs.StringVar(&flags.LogFormat, "log-format", "", "text or json (overrides LOG_FORMAT)")
+	fs.BoolVar(&flags.DryRun, "dry-run", false, "validate configuration, database and bot token, then exit")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	return flags, nil
+}
+
+// LoadWithFlags reads configuration with precedence flags > environment > config file
+func LoadWithFlags(flags *Flags) (*Config, error) {
+	return loadWithSources(flags, os.LookupEnv)
+}
+
+// loadWithSources layers the environment over the optional config file and loads the result
+func loadWithSources(flags *Flags, lookupEnv func(string) (string, bool)) (*Config, error) {
+	var file map[string]string
+	if flags != nil && flags.ConfigPath != "" {
+		var err error
+		file, err = readConfigFile(flags.ConfigPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	getenv := func(key string) string {
+		if value, ok := lookupEnv(key); ok && value != "" {
+			return value
+		}
+		return file[key]
+	}
+
+	return load(getenv, flags)
+}
+
+// apply overrides configuration values with the flags that were set
+func (f *Flags) apply(cfg *Config) {
+	if f == nil {
+		return
+	}
+	if f.DatabasePath != "" {
+		// A URL replaces DATABASE_URL; a path replaces DATABASE_PATH and wins over DATABASE_URL
+		cfg.DatabaseURL = ""
+		if isDatabaseURL(f.DatabasePath) {
+			cfg.DatabaseURL = f.DatabasePath
+		} else {
+			cfg.DatabasePath = f.DatabasePath
+		}
+	}
+	if f.LogLevel != "" {
+		cfg.LogLevel = strings.ToLower(f.LogLevel)
+	}
+	if f.LogFormat != "" {
+		cfg.LogFormat = strings.ToLower(f.LogFormat)
+	}
+}
+
+// readConfigFile parses a KEY=VALUE file in the same format as .env
+func readConfigFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, line)
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return values, nil
+}
+
+// LogValue reports the effective configuration without secrets, for logging at start-up
+func (c *Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("environment", c.Environment),
+		slog.String("database_path", c.DatabasePath),
+		slog.String("database_url", redactDatabaseURL(c.DatabaseURL)),
+		slog.Bool("auto_migrate", c.AutoMigrate),
+		slog.String("log_level", c.LogLevel),
+		slog.String("log_format", c.LogFormat),
+		slog.String("log_file", c.LogFile),
+		slog.String("health_addr", c.HealthAddr),
+		slog.String("api_addr", c.APIAddr),
+		slog.Any("api_keys", c.APIKeyNames()),
+		slog.String("telegram_api_url", c.TelegramAPIURL),
+		slog.String("totp_algorithm", c.TOTPAlgorithm),
+		slog.Int("totp_digits", c.TOTPDigits),
+		slog.Int("totp_period", c.TOTPPeriod),
+		slog.Int("totp_skew", c.TOTPSkew),
+		slog.Bool("totp_rotation", c.TOTPSecretPrevious != ""),
+		slog.Bool("secrets_encryption", c.SecretsKey != ""),
+		slog.Int64("super_admin_id", c.SuperAdminID),
+		slog.Int64("admin_chat_id", c.AdminChatID),
+		slog.Int("otp_failure_limit", c.OTPFailureLimit),
+		slog.Int("otp_failure_window_minutes", c.OTPFailureWindow),
+		slog.Int("otp_lockout_limit", c.OTPLockoutLimit),
+		slog.Int("otp_lockout_minutes", c.OTPLockoutMinutes),
+		slog.Int("stale_update_minutes", c.StaleUpdateCutoff),
+		slog.Bool("stale_command_reply", c.StaleCommandReply),
+		slog.Int("report_cache_seconds", c.ReportCacheSeconds),
+		slog.Int("expected_work_hours", c.ExpectedWorkHours),
+		slog.Int("photo_window_minutes", c.PhotoWindow),
+		slog.Int("overtime_threshold_minutes", c.OvertimeThreshold),
+		slog.Int("max_breaks_per_day", c.MaxBreaks),
+		slog.String("geofence_mode", c.GeofenceMode),
+		slog.Int("supervisors", len(c.SupervisorIDs)),
+		slog.String("timezone", c.Timezone),
+		slog.String("daily_report_time", c.DailyReportTime),
+		slog.String("auto_checkout", c.AutoCheckout),
+		slog.String("auto_checkout_time", c.AutoCheckoutTime),
+		slog.Int64("live_report_chat_id", c.LiveReportChatID),
+		slog.Int64("report_chat_id", c.ReportChatID),
+		slog.String("report_schedule", c.ReportSchedule),
+		slog.String("weekly_summary_schedule", c.WeeklySummary),
+		slog.String("late_alerts", c.LateAlerts),
+		slog.Int64("late_alert_chat_id", c.LateAlertChatID),
+		slog.String("late_alert_digest_time", c.LateAlertDigestTime),
+		slog.Int("holidays", len(c.Holidays)),
+		slog.Bool("holiday_feed", c.HolidayFeedURL != ""),
+		slog.String("webhook_url", c.WebhookURL),
+		slog.Int("webhook_port", c.WebhookPort),
+		slog.Int("event_webhooks", len(c.EventWebhookURLs)),
+		slog.Bool("event_webhooks_signed", c.EventWebhookSecret != ""),
+		slog.Int("event_webhook_max_attempts", c.EventWebhookTries),
+		slog.Bool("slack", c.SlackWebhookURL != ""),
+		slog.Bool("discord", c.DiscordWebhookURL != ""),
+		slog.String("notify_events", strings.Join(c.NotifyEvents, ",")),
+		slog.Bool("require_registration", c.RequireRegistration),
+		slog.Bool("wfh_approval", c.WFHApproval),
+		slog.String("backup_schedule", c.BackupSchedule),
+		slog.String("backup_dir", c.BackupDir),
+		slog.Int("backup_keep", c.BackupKeep),
+		slog.String("backup_s3_bucket", c.BackupS3Bucket),
+	)
+}
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Engine identifies the SQL database behind a DB
+type Engine string
+
+// Supported engines
+const (
+	SQLite   Engine = "sqlite"
+	Postgres Engine = "postgres"
+)
+
+// DB wraps the sql.DB connection of either engine. Queries are written with ? placeholders,
+// which Exec, Query and QueryRow rewrite into the engine's own.
+type DB struct {
+	*sql.DB
+	engine Engine
+}
+
+// NewDB opens the database named by target and applies all pending migrations, see OpenDB
+func NewDB(target string) (*DB, error) {
+	if isPostgresURL(target) {
+		return NewPostgresDB(target)
+	}
+	return NewSQLiteDB(sqlitePath(target))
+}
+
+// OpenDB opens the database named by target without touching the schema: postgres:// and
+// postgresql:// URLs open PostgreSQL, anything else is a SQLite path with an optional sqlite: prefix
+func OpenDB(target string) (*DB, error) {
+	if isPostgresURL(target) {
+		return OpenPostgresDB(target)
+	}
+	return OpenSQLiteDB(sqlitePath(target))
+}
+
+// OpenDBReadOnly opens the database named by target for reading only, see OpenDB
+func OpenDBReadOnly(target string) (*DB, error) {
+	if isPostgresURL(target) {
+		return OpenPostgresDBReadOnly(target)
+	}
+	return OpenSQLiteDBReadOnly(sqlitePath(target))
+}
+
+// isPostgresURL reports whether target is a PostgreSQL connection URL
+func isPostgresURL(target string) bool {
+	return strings.HasPrefix(target, "postgres://") || strings.HasPrefix(target, "postgresql://")
+}
+
+// sqlitePath strips the optional sqlite: or sqlite:// prefix from a SQLite target
+func sqlitePath(target string) string {
+	if path, ok := strings.CutPrefix(target, "sqlite://"); ok {
+		return path
+	}
+	return strings.TrimPrefix(target, "sqlite:")
+}
+
+// RedactTarget returns target with the password of a connection URL masked, for logging
+func RedactTarget(target string) string {
+	if !isPostgresURL(target) {
+		return target
+	}
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return "postgres://(invalid URL)"
+	}
+	return parsed.Redacted()
+}
+
+// Engine returns the engine behind the connection
+func (db *DB) Engine() Engine {
+	return db.engine
+}
+
+// Close closes the database connection
+func (db *DB) Close() error {
+	return db.DB.Close()
+}
+
+// Exec executes a query without returning rows
+func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.DB.Exec(rebind(db.engine, query), args...)
+}
+
+// ExecContext executes a query without returning rows, giving up when ctx is done
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return db.DB.ExecContext(ctx, rebind(db.engine, query), args...)
+}
+
+// Query executes a query returning rows
+func (db *DB) Query(query string, args ...any) (*sql.Rows, error) {
+	return db.DB.Query(rebind(db.engine, query), args...)
+}
+
+// QueryContext executes a query returning rows, giving up when ctx is done
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return db.DB.QueryContext(ctx, rebind(db.engine, query), args...)
+}
+
+// QueryRow executes a query returning at most one row
+func (db *DB) QueryRow(query string, args ...any) *sql.Row {
+	return db.DB.QueryRow(rebind(db.engine, query), args...)
+}
+
+// QueryRowContext executes a query returning at most one row, giving up when ctx is done
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return db.DB.QueryRowContext(ctx, rebind(db.engine, query), args...)
+}
+
+// BeginTx starts a new transaction, which is rolled back if ctx is done before it is committed
+func (db *DB) BeginTx(ctx context.Context) (*Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, engine: db.engine}, nil
+}
+
+// Tx wraps a transaction, rewriting ? placeholders like DB
+type Tx struct {
+	*sql.Tx
+	engine Engine
+}
+
+// Exec executes a query without returning rows
+func (tx *Tx) Exec(query string, args ...any) (sql.Result, error) {
+	return tx.Tx.Exec(rebind(tx.engine, query), args...)
+}
+
+// ExecContext executes a query without returning rows, giving up when ctx is done
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tx.Tx.ExecContext(ctx, rebind(tx.engine, query), args...)
+}
+
+// Query executes a query returning rows
+func (tx *Tx) Query(query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.Query(rebind(tx.engine, query), args...)
+}
+
+// QueryContext executes a query returning rows, giving up when ctx is done
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.QueryContext(ctx, rebind(tx.engine, query), args...)
+}
+
+// QueryRow executes a query returning at most one row
+func (tx *Tx) QueryRow(query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRow(rebind(tx.engine, query), args...)
+}
+
+// QueryRowContext executes a query returning at most one row, giving up when ctx is done
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRowContext(ctx, rebind(tx.engine, query), args...)
+}
+
+// Prepare creates a prepared statement for use within the transaction
+func (tx *Tx) Prepare(query string) (*sql.Stmt, error) {
+	return tx.Tx.Prepare(rebind(tx.engine, query))
+}
+
+// PrepareContext creates a prepared statement for use within the transaction
+func (tx *Tx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return tx.Tx.PrepareContext(ctx, rebind(tx.engine, query))
+}
+
+// rebind rewrites the ? placeholders of query into PostgreSQL's $1, $2, ... Question marks in
+// quoted strings are left alone.
+func rebind(engine Engine, query string) string {
+	if engine != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var rebound strings.Builder
+	rebound.Grow(len(query) + 8)
+	n := 0
+	quoted := false
+	for _, char := range query {
+		switch {
+		case char == '\'':
+			quoted = !quoted
+		case char == '?' && !quoted:
+			n++
+			rebound.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		rebound.WriteRune(char)
+	}
+	return rebound.String()
+}
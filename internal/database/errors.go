@@ -0,0 +1,59 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// ErrNotFound indicates the requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// ErrDuplicate indicates a write violated a UNIQUE constraint
+var ErrDuplicate = errors.New("duplicate record")
+
+// ErrArchived indicates a write to a day before the archive boundary, whose records are read-only
+var ErrArchived = errors.New("date is archived")
+
+// StorageError wraps a failed database operation, so callers can tell storage failures
+// apart from business errors with errors.As
+type StorageError struct {
+	Op  string // Operation that failed, e.g. "insert attendance"
+	Err error
+}
+
+// Error implements the error interface
+func (e *StorageError) Error() string {
+	return fmt.Sprintf("failed to %s: %v", e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *StorageError) Unwrap() error {
+	return e.Err
+}
+
+// storageError wraps err from op in a StorageError, marking UNIQUE violations with ErrDuplicate
+func storageError(op string, err error) error {
+	if isUniqueViolation(err) {
+		err = fmt.Errorf("%w: %w", ErrDuplicate, err)
+	}
+	return &StorageError{Op: op, Err: err}
+}
+
+// isUniqueViolation reports whether err is a UNIQUE or PRIMARY KEY constraint failure of either engine
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505" // unique_violation, which primary keys raise too
+	}
+
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
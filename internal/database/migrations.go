@@ -0,0 +1,341 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration is a versioned schema change, written once per engine as SQL scripts embedded from
+// migrations/<engine>/NNNN_name.up.sql, with a matching .down.sql reverting it
+type Migration struct {
+	Version int
+	Name    string
+	up      map[Engine]string // Script applying the change, per engine
+	down    map[Engine]string // Script reverting the change, per engine
+}
+
+// Reversible reports whether the migration has a script reverting it on every engine
+func (m Migration) Reversible() bool {
+	return m.down[SQLite] != "" && m.down[Postgres] != ""
+}
+
+// MigrationStatus describes whether a migration has been applied
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// migrationFiles holds the SQL scripts of every migration. Never edit an applied migration; add a
+// new one. The first migrations use IF NOT EXISTS so databases created before versioning was
+// introduced are brought up to date without errors. PostgreSQL stores Telegram IDs as BIGINT,
+// since chat IDs overflow its 32-bit INTEGER, and keeps most timestamps as RFC 3339 text like SQLite.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationFilePattern matches a migration script name: version, name and direction
+var migrationFilePattern = regexp.MustCompile(`^(\d{4})_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// migrations lists every schema change in order. The scripts are compiled into the binary, so a
+// malformed set is a programming error caught on start-up.
+var migrations = mustLoadMigrations(migrationFiles)
+
+// mustLoadMigrations is like loadMigrations but panics on error
+func mustLoadMigrations(fsys fs.FS) []Migration {
+	loaded, err := loadMigrations(fsys)
+	if err != nil {
+		panic(err)
+	}
+	return loaded
+}
+
+// loadMigrations reads the migration scripts of both engines. Versions must run from 1 without
+// gaps, and every version needs an up script with the same name on both engines.
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	byVersion := make(map[int]*Migration)
+	for _, engine := range []Engine{SQLite, Postgres} {
+		dir := path.Join("migrations", string(engine))
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+
+		for _, entry := range entries {
+			match := migrationFilePattern.FindStringSubmatch(entry.Name())
+			if match == nil {
+				return nil, fmt.Errorf("unexpected migration file %s/%s", dir, entry.Name())
+			}
+			version, _ := strconv.Atoi(match[1])
+			name := strings.ReplaceAll(match[2], "_", " ")
+
+			script, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read migration %s/%s: %w", dir, entry.Name(), err)
+			}
+
+			m, ok := byVersion[version]
+			if !ok {
+				m = &Migration{Version: version, Name: name, up: make(map[Engine]string), down: make(map[Engine]string)}
+				byVersion[version] = m
+			}
+			if m.Name != name {
+				return nil, fmt.Errorf("migration %d is named %q and %q", version, m.Name, name)
+			}
+			if match[3] == "up" {
+				m.up[engine] = string(script)
+			} else {
+				m.down[engine] = string(script)
+			}
+		}
+	}
+
+	loaded := make([]Migration, 0, len(byVersion))
+	for version := 1; version <= len(byVersion); version++ {
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("migration %d is missing", version)
+		}
+		if m.up[SQLite] == "" || m.up[Postgres] == "" {
+			return nil, fmt.Errorf("migration %d lacks an up script for every engine", version)
+		}
+		loaded = append(loaded, *m)
+	}
+	if len(loaded) == 0 {
+		return nil, fmt.Errorf("no migrations found")
+	}
+
+	return loaded, nil
+}
+
+// migrationLockID identifies the PostgreSQL advisory lock held while applying a migration
+const migrationLockID = 7_262_637_001
+
+// LatestVersion returns the version of the newest known migration
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// ensureMigrationsTable creates the table tracking applied migrations
+func (db *DB) ensureMigrationsTable() error {
+	_, err := db.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	);`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// MigrationStatus lists every known migration and whether it has been applied
+func (db *DB) MigrationStatus() ([]MigrationStatus, error) {
+	if err := db.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAtStr string
+		if err := rows.Scan(&version, &appliedAtStr); err != nil {
+			return nil, fmt.Errorf("failed to scan migration: %w", err)
+		}
+		appliedAt, err := time.Parse(time.RFC3339, appliedAtStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse applied_at: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		statuses = append(statuses, MigrationStatus{Migration: m, Applied: ok, AppliedAt: appliedAt})
+	}
+
+	return statuses, nil
+}
+
+// PendingMigrations returns the migrations not yet applied, up to target (0 means latest)
+func (db *DB) PendingMigrations(target int) ([]Migration, error) {
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, status := range statuses {
+		if status.Applied || (target > 0 && status.Version > target) {
+			continue
+		}
+		pending = append(pending, status.Migration)
+	}
+
+	return pending, nil
+}
+
+// Migrate applies pending migrations up to target (0 means latest), each in its own transaction.
+// onApplied, if not nil, is called after every migration with how long it took.
+func (db *DB) Migrate(target int, onApplied func(m Migration, took time.Duration)) ([]Migration, error) {
+	if target < 0 || target > LatestVersion() {
+		return nil, fmt.Errorf("unknown migration version %d (latest is %d)", target, LatestVersion())
+	}
+
+	pending, err := db.PendingMigrations(target)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range pending {
+		start := time.Now()
+		if err := db.applyMigration(m); err != nil {
+			return nil, err
+		}
+		if onApplied != nil {
+			onApplied(m, time.Since(start))
+		}
+	}
+
+	return pending, nil
+}
+
+// applyMigration runs a single migration and records it atomically
+func (db *DB) applyMigration(m Migration) error {
+	tx, err := db.BeginTx(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Replicas starting together on PostgreSQL take turns; the later ones find it applied
+	if db.engine == Postgres {
+		if _, err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID); err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		var applied bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = ?)", m.Version).Scan(&applied); err != nil {
+			return fmt.Errorf("failed to check migration %d: %w", m.Version, err)
+		}
+		if applied {
+			return nil
+		}
+	}
+
+	if _, err := tx.Exec(m.up[db.engine]); err != nil {
+		return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+	}
+
+	_, err = tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		m.Version, m.Name, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.Version, err)
+	}
+
+	return nil
+}
+
+// AppliedMigrations returns the applied migrations newer than target, newest first: the ones
+// Revert would undo
+func (db *DB) AppliedMigrations(target int) ([]Migration, error) {
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if statuses[i].Applied && statuses[i].Version > target {
+			applied = append(applied, statuses[i].Migration)
+		}
+	}
+
+	return applied, nil
+}
+
+// Revert undoes applied migrations newer than target (0 reverts everything), newest first and
+// each in its own transaction. Reverting drops the tables and columns the migrations added, along
+// with their data. onReverted, if not nil, is called after every migration with how long it took.
+func (db *DB) Revert(target int, onReverted func(m Migration, took time.Duration)) ([]Migration, error) {
+	if target < 0 || target > LatestVersion() {
+		return nil, fmt.Errorf("unknown migration version %d (latest is %d)", target, LatestVersion())
+	}
+
+	applied, err := db.AppliedMigrations(target)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range applied {
+		if !m.Reversible() {
+			return nil, fmt.Errorf("migration %d (%s) cannot be reverted", m.Version, m.Name)
+		}
+	}
+
+	for _, m := range applied {
+		start := time.Now()
+		if err := db.revertMigration(m); err != nil {
+			return nil, err
+		}
+		if onReverted != nil {
+			onReverted(m, time.Since(start))
+		}
+	}
+
+	return applied, nil
+}
+
+// revertMigration undoes a single migration and forgets it atomically
+func (db *DB) revertMigration(m Migration) error {
+	tx, err := db.BeginTx(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if db.engine == Postgres {
+		if _, err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID); err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+	}
+
+	result, err := tx.Exec("DELETE FROM schema_migrations WHERE version = ?", m.Version)
+	if err != nil {
+		return fmt.Errorf("failed to forget migration %d: %w", m.Version, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		// Another process reverted it meanwhile
+		return nil
+	}
+
+	if _, err := tx.Exec(m.down[db.engine]); err != nil {
+		return fmt.Errorf("reverting migration %d (%s) failed: %w", m.Version, m.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reverting migration %d: %w", m.Version, err)
+	}
+
+	return nil
+}
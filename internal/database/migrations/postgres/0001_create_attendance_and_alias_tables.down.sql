@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS alias;
+DROP TABLE IF EXISTS attendance;
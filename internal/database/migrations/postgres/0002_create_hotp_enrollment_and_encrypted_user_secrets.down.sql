@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_secrets;
+DROP TABLE IF EXISTS hotp_enrollment;
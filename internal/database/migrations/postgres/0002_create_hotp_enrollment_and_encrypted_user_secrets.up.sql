@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS hotp_enrollment (
+	user_id BIGINT PRIMARY KEY,
+	counter BIGINT NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS user_secrets (
+	user_id BIGINT PRIMARY KEY,
+	ciphertext BYTEA NOT NULL,
+	nonce BYTEA NOT NULL
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS failed_otps (
+	id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	username TEXT NOT NULL,
+	chat_type TEXT NOT NULL,
+	code TEXT NOT NULL,
+	timestamp TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_failed_otps_user_time ON failed_otps(user_id, timestamp);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS subscriptions (
+	chat_id BIGINT NOT NULL,
+	user_id BIGINT NOT NULL,
+	digest TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (chat_id, digest)
+);
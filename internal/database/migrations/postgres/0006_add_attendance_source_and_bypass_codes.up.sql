@@ -0,0 +1,12 @@
+ALTER TABLE attendance ADD COLUMN source TEXT NOT NULL DEFAULT 'otp';
+CREATE TABLE IF NOT EXISTS bypass_codes (
+	id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	code_hash TEXT NOT NULL,
+	issued_by BIGINT NOT NULL,
+	issued_at TEXT NOT NULL,
+	expires_at TEXT NOT NULL,
+	used_at TEXT,
+	attendance_id BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_bypass_codes_user_hash ON bypass_codes(user_id, code_hash);
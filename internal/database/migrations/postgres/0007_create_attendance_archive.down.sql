@@ -0,0 +1 @@
+DROP TABLE IF EXISTS attendance_archive;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS alias_requests (
+	user_id BIGINT PRIMARY KEY,
+	first_name TEXT NOT NULL,
+	last_name TEXT,
+	requested_at TEXT NOT NULL
+);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS leave_requests (
+	id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	username TEXT NOT NULL DEFAULT '',
+	first_name TEXT NOT NULL,
+	last_name TEXT,
+	type TEXT NOT NULL,
+	start_date TEXT NOT NULL,
+	end_date TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	requested_at TEXT NOT NULL,
+	decided_by BIGINT,
+	decided_at TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_leave_requests_dates ON leave_requests(status, start_date, end_date);
+CREATE INDEX IF NOT EXISTS idx_leave_requests_user ON leave_requests(user_id, end_date);
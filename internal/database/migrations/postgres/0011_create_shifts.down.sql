@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_shifts;
+DROP TABLE IF EXISTS shifts;
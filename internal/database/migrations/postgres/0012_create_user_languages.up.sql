@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS user_languages (
+	user_id BIGINT PRIMARY KEY,
+	language TEXT NOT NULL,
+	chosen BOOLEAN NOT NULL DEFAULT FALSE,
+	updated_at TEXT NOT NULL
+);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS reminders (
+	user_id BIGINT PRIMARY KEY,
+	lead_minutes INTEGER NOT NULL,
+	created_at TEXT NOT NULL
+);
@@ -0,0 +1,2 @@
+ALTER TABLE attendance_archive DROP COLUMN photo_file_id;
+ALTER TABLE attendance DROP COLUMN photo_file_id;
@@ -0,0 +1,12 @@
+ALTER TABLE attendance ADD COLUMN latitude DOUBLE PRECISION;
+ALTER TABLE attendance ADD COLUMN longitude DOUBLE PRECISION;
+ALTER TABLE attendance ADD COLUMN geofence TEXT;
+ALTER TABLE attendance_archive ADD COLUMN latitude DOUBLE PRECISION;
+ALTER TABLE attendance_archive ADD COLUMN longitude DOUBLE PRECISION;
+ALTER TABLE attendance_archive ADD COLUMN geofence TEXT;
+CREATE TABLE IF NOT EXISTS geofences (
+	name TEXT PRIMARY KEY,
+	latitude DOUBLE PRECISION NOT NULL,
+	longitude DOUBLE PRECISION NOT NULL,
+	radius_m INTEGER NOT NULL
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS otp_lockouts (
+	user_id BIGINT PRIMARY KEY,
+	failures INTEGER NOT NULL,
+	window_start TEXT NOT NULL,
+	locked_until TEXT NOT NULL DEFAULT ''
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS office_groups (
+	chat_id BIGINT PRIMARY KEY,
+	title TEXT NOT NULL DEFAULT '',
+	registered_by BIGINT NOT NULL,
+	registered_at TEXT NOT NULL
+);
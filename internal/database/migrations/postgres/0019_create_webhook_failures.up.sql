@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS webhook_failures (
+	id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	created_at TEXT NOT NULL,
+	url TEXT NOT NULL,
+	event TEXT NOT NULL,
+	event_id TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	attempts INTEGER NOT NULL,
+	last_error TEXT NOT NULL DEFAULT ''
+);
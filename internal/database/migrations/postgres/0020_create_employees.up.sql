@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS employees (
+	user_id BIGINT PRIMARY KEY,
+	employee_id TEXT NOT NULL UNIQUE,
+	department TEXT NOT NULL DEFAULT '',
+	registered_by BIGINT NOT NULL,
+	registered_at TEXT NOT NULL
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS sessions (
+	user_id BIGINT PRIMARY KEY,
+	state TEXT NOT NULL,
+	payload TEXT NOT NULL DEFAULT '',
+	expires_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
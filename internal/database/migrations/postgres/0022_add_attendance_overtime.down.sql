@@ -0,0 +1,2 @@
+ALTER TABLE attendance_archive DROP COLUMN overtime_minutes;
+ALTER TABLE attendance DROP COLUMN overtime_minutes;
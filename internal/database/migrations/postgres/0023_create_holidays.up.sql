@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS holidays (
+	date TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	source TEXT NOT NULL,
+	added_by BIGINT NOT NULL,
+	added_at TEXT NOT NULL
+);
@@ -0,0 +1,16 @@
+ALTER TABLE attendance ADD COLUMN remote BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE attendance_archive ADD COLUMN remote BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS wfh_requests (
+	id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	username TEXT NOT NULL DEFAULT '',
+	first_name TEXT NOT NULL,
+	last_name TEXT,
+	date TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	requested_at TEXT NOT NULL,
+	decided_by BIGINT,
+	decided_at TEXT,
+	UNIQUE (user_id, date)
+);
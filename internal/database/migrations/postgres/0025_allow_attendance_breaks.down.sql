@@ -0,0 +1,11 @@
+DELETE FROM attendance WHERE type NOT IN ('check_in', 'check_out');
+DROP INDEX IF EXISTS idx_attendance_once;
+ALTER TABLE attendance DROP CONSTRAINT IF EXISTS attendance_type_check;
+ALTER TABLE attendance ADD CONSTRAINT attendance_type_check CHECK (type IN ('check_in', 'check_out'));
+ALTER TABLE attendance ADD CONSTRAINT attendance_user_id_date_type_key UNIQUE (user_id, date, type);
+
+DELETE FROM attendance_archive WHERE type NOT IN ('check_in', 'check_out');
+DROP INDEX IF EXISTS idx_archive_once;
+ALTER TABLE attendance_archive DROP CONSTRAINT IF EXISTS attendance_archive_type_check;
+ALTER TABLE attendance_archive ADD CONSTRAINT attendance_archive_type_check CHECK (type IN ('check_in', 'check_out'));
+ALTER TABLE attendance_archive ADD CONSTRAINT attendance_archive_user_id_date_type_key UNIQUE (user_id, date, type);
@@ -0,0 +1,9 @@
+ALTER TABLE attendance DROP CONSTRAINT IF EXISTS attendance_type_check;
+ALTER TABLE attendance ADD CONSTRAINT attendance_type_check CHECK (type IN ('check_in', 'check_out', 'break_start', 'break_end'));
+ALTER TABLE attendance DROP CONSTRAINT IF EXISTS attendance_user_id_date_type_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attendance_once ON attendance(user_id, date, type) WHERE type IN ('check_in', 'check_out');
+
+ALTER TABLE attendance_archive DROP CONSTRAINT IF EXISTS attendance_archive_type_check;
+ALTER TABLE attendance_archive ADD CONSTRAINT attendance_archive_type_check CHECK (type IN ('check_in', 'check_out', 'break_start', 'break_end'));
+ALTER TABLE attendance_archive DROP CONSTRAINT IF EXISTS attendance_archive_user_id_date_type_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_archive_once ON attendance_archive(user_id, date, type) WHERE type IN ('check_in', 'check_out');
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS user_timezones (
+	user_id BIGINT PRIMARY KEY,
+	timezone TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
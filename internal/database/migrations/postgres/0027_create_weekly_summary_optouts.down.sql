@@ -0,0 +1 @@
+DROP TABLE IF EXISTS weekly_summary_optouts;
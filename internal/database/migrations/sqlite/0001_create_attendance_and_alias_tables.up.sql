@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS attendance (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	username TEXT NOT NULL,
+	first_name TEXT NOT NULL,
+	last_name TEXT,
+	timestamp TEXT NOT NULL,
+	type TEXT NOT NULL CHECK (type IN ('check_in', 'check_out')),
+	date TEXT NOT NULL,
+	UNIQUE(user_id, date, type)
+);
+CREATE INDEX IF NOT EXISTS idx_user_date ON attendance(user_id, date);
+CREATE INDEX IF NOT EXISTS idx_date ON attendance(date);
+CREATE INDEX IF NOT EXISTS idx_user_id ON attendance(user_id);
+CREATE INDEX IF NOT EXISTS idx_type ON attendance(type);
+CREATE TABLE IF NOT EXISTS alias (
+	user_id INTEGER PRIMARY KEY,
+	first_name TEXT NOT NULL,
+	last_name TEXT
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS hotp_enrollment (
+	user_id INTEGER PRIMARY KEY,
+	counter INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS user_secrets (
+	user_id INTEGER PRIMARY KEY,
+	ciphertext BLOB NOT NULL,
+	nonce BLOB NOT NULL
+);
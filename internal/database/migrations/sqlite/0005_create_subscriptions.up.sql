@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS subscriptions (
+	chat_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	digest TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY (chat_id, digest)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bypass_codes;
+ALTER TABLE attendance DROP COLUMN source;
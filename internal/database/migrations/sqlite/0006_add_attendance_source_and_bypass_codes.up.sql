@@ -0,0 +1,12 @@
+ALTER TABLE attendance ADD COLUMN source TEXT NOT NULL DEFAULT 'otp';
+CREATE TABLE IF NOT EXISTS bypass_codes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	code_hash TEXT NOT NULL,
+	issued_by INTEGER NOT NULL,
+	issued_at TEXT NOT NULL,
+	expires_at TEXT NOT NULL,
+	used_at TEXT,
+	attendance_id INTEGER
+);
+CREATE INDEX IF NOT EXISTS idx_bypass_codes_user_hash ON bypass_codes(user_id, code_hash);
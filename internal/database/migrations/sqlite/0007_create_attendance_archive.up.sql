@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS attendance_archive (
+	id INTEGER PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	username TEXT NOT NULL,
+	first_name TEXT NOT NULL,
+	last_name TEXT,
+	timestamp TEXT NOT NULL,
+	type TEXT NOT NULL CHECK (type IN ('check_in', 'check_out')),
+	date TEXT NOT NULL,
+	source TEXT NOT NULL DEFAULT 'otp',
+	UNIQUE(user_id, date, type)
+);
+CREATE INDEX IF NOT EXISTS idx_archive_user_date ON attendance_archive(user_id, date);
+CREATE INDEX IF NOT EXISTS idx_archive_date ON attendance_archive(date);
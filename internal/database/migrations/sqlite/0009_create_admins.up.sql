@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS admins (
+	user_id INTEGER PRIMARY KEY,
+	role TEXT NOT NULL DEFAULT 'admin',
+	added_by INTEGER NOT NULL,
+	added_at TEXT NOT NULL
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS shifts (
+	name TEXT PRIMARY KEY,
+	start_time TEXT NOT NULL,
+	end_time TEXT NOT NULL,
+	grace_minutes INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS user_shifts (
+	user_id INTEGER PRIMARY KEY,
+	shift_name TEXT NOT NULL REFERENCES shifts(name),
+	assigned_by INTEGER NOT NULL,
+	assigned_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_user_shifts_shift ON user_shifts(shift_name);
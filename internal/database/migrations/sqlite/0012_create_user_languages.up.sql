@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS user_languages (
+	user_id INTEGER PRIMARY KEY,
+	language TEXT NOT NULL,
+	chosen INTEGER NOT NULL DEFAULT 0,
+	updated_at TEXT NOT NULL
+);
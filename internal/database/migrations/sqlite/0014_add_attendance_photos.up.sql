@@ -0,0 +1,2 @@
+ALTER TABLE attendance ADD COLUMN photo_file_id TEXT;
+ALTER TABLE attendance_archive ADD COLUMN photo_file_id TEXT;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS geofences;
+ALTER TABLE attendance_archive DROP COLUMN geofence;
+ALTER TABLE attendance_archive DROP COLUMN longitude;
+ALTER TABLE attendance_archive DROP COLUMN latitude;
+ALTER TABLE attendance DROP COLUMN geofence;
+ALTER TABLE attendance DROP COLUMN longitude;
+ALTER TABLE attendance DROP COLUMN latitude;
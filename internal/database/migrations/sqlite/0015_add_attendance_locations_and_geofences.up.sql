@@ -0,0 +1,12 @@
+ALTER TABLE attendance ADD COLUMN latitude REAL;
+ALTER TABLE attendance ADD COLUMN longitude REAL;
+ALTER TABLE attendance ADD COLUMN geofence TEXT;
+ALTER TABLE attendance_archive ADD COLUMN latitude REAL;
+ALTER TABLE attendance_archive ADD COLUMN longitude REAL;
+ALTER TABLE attendance_archive ADD COLUMN geofence TEXT;
+CREATE TABLE IF NOT EXISTS geofences (
+	name TEXT PRIMARY KEY,
+	latitude REAL NOT NULL,
+	longitude REAL NOT NULL,
+	radius_m INTEGER NOT NULL
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	created_at TEXT NOT NULL,
+	actor_id INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	target_user_id INTEGER,
+	record_id INTEGER,
+	details TEXT NOT NULL DEFAULT '',
+	reason TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_user_id, created_at);
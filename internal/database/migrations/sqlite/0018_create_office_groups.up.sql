@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS office_groups (
+	chat_id INTEGER PRIMARY KEY,
+	title TEXT NOT NULL DEFAULT '',
+	registered_by INTEGER NOT NULL,
+	registered_at TEXT NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS wfh_requests;
+ALTER TABLE attendance_archive DROP COLUMN remote;
+ALTER TABLE attendance DROP COLUMN remote;
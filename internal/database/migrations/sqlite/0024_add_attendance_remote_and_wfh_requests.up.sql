@@ -0,0 +1,16 @@
+ALTER TABLE attendance ADD COLUMN remote INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE attendance_archive ADD COLUMN remote INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS wfh_requests (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	username TEXT NOT NULL DEFAULT '',
+	first_name TEXT NOT NULL,
+	last_name TEXT,
+	date TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending',
+	requested_at TEXT NOT NULL,
+	decided_by INTEGER,
+	decided_at TEXT,
+	UNIQUE (user_id, date)
+);
@@ -0,0 +1,56 @@
+CREATE TABLE attendance_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	username TEXT NOT NULL,
+	first_name TEXT NOT NULL,
+	last_name TEXT,
+	timestamp TEXT NOT NULL,
+	type TEXT NOT NULL CHECK (type IN ('check_in', 'check_out', 'break_start', 'break_end')),
+	date TEXT NOT NULL,
+	source TEXT NOT NULL DEFAULT 'otp',
+	photo_file_id TEXT,
+	latitude REAL,
+	longitude REAL,
+	geofence TEXT,
+	overtime_minutes INTEGER NOT NULL DEFAULT 0,
+	remote INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO attendance_new (id, user_id, username, first_name, last_name, timestamp, type, date, source, photo_file_id, latitude, longitude, geofence, overtime_minutes, remote)
+	SELECT id, user_id, username, first_name, last_name, timestamp, type, date, source, photo_file_id, latitude, longitude, geofence, overtime_minutes, remote
+	FROM attendance;
+
+DELETE FROM sqlite_sequence WHERE name = 'attendance_new';
+UPDATE sqlite_sequence SET name = 'attendance_new' WHERE name = 'attendance';
+DROP TABLE attendance;
+ALTER TABLE attendance_new RENAME TO attendance;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attendance_once ON attendance(user_id, date, type) WHERE type IN ('check_in', 'check_out');
+CREATE INDEX IF NOT EXISTS idx_user_date ON attendance(user_id, date);
+CREATE INDEX IF NOT EXISTS idx_date ON attendance(date);
+CREATE INDEX IF NOT EXISTS idx_user_id ON attendance(user_id);
+CREATE INDEX IF NOT EXISTS idx_type ON attendance(type);
+
+CREATE TABLE attendance_archive_new (
+	id INTEGER PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	username TEXT NOT NULL,
+	first_name TEXT NOT NULL,
+	last_name TEXT,
+	timestamp TEXT NOT NULL,
+	type TEXT NOT NULL CHECK (type IN ('check_in', 'check_out', 'break_start', 'break_end')),
+	date TEXT NOT NULL,
+	source TEXT NOT NULL DEFAULT 'otp',
+	photo_file_id TEXT,
+	latitude REAL,
+	longitude REAL,
+	geofence TEXT,
+	overtime_minutes INTEGER NOT NULL DEFAULT 0,
+	remote INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO attendance_archive_new (id, user_id, username, first_name, last_name, timestamp, type, date, source, photo_file_id, latitude, longitude, geofence, overtime_minutes, remote)
+	SELECT id, user_id, username, first_name, last_name, timestamp, type, date, source, photo_file_id, latitude, longitude, geofence, overtime_minutes, remote
+	FROM attendance_archive;
+DROP TABLE attendance_archive;
+ALTER TABLE attendance_archive_new RENAME TO attendance_archive;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_archive_once ON attendance_archive(user_id, date, type) WHERE type IN ('check_in', 'check_out');
+CREATE INDEX IF NOT EXISTS idx_archive_user_date ON attendance_archive(user_id, date);
+CREATE INDEX IF NOT EXISTS idx_archive_date ON attendance_archive(date);
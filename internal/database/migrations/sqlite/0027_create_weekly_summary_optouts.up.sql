@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS weekly_summary_optouts (
+	user_id INTEGER PRIMARY KEY,
+	opted_out_at TEXT NOT NULL
+);
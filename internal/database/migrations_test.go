@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrationsRejectsMalformedSets(t *testing.T) {
+	script := &fstest.MapFile{Data: []byte("SELECT 1;")}
+	both := func(files ...string) fstest.MapFS {
+		fsys := fstest.MapFS{}
+		for _, file := range files {
+			fsys["migrations/sqlite/"+file] = script
+			fsys["migrations/postgres/"+file] = script
+		}
+		return fsys
+	}
+
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{"no engine directories", fstest.MapFS{}, "failed to read migrations/sqlite"},
+		{"unexpected file", both("0001_create_users.up.sql", "README.md"), "unexpected migration file"},
+		{"gap", both("0001_create_users.up.sql", "0003_create_admins.up.sql"), "migration 2 is missing"},
+		{"names differ", fstest.MapFS{
+			"migrations/sqlite/0001_create_users.up.sql":      script,
+			"migrations/postgres/0001_create_people.up.sql":   script,
+			"migrations/postgres/0001_create_people.down.sql": script,
+		}, `migration 1 is named "create users" and "create people"`},
+		{"up script on one engine", fstest.MapFS{
+			"migrations/sqlite/0001_create_users.up.sql":     script,
+			"migrations/postgres/0001_create_users.down.sql": script,
+		}, "migration 1 lacks an up script for every engine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadMigrations(tt.fsys); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("loadMigrations = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+
+	loaded, err := loadMigrations(both("0001_create_users.up.sql", "0001_create_users.down.sql", "0002_add_email.up.sql"))
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0].Name != "create users" || !loaded[0].Reversible() || loaded[1].Reversible() {
+		t.Errorf("loaded = %+v, want a reversible 1 and an irreversible 2", loaded)
+	}
+}
+
+// TestMigrationsRevertAndReapply reverts every shipped migration of a fresh SQLite database and
+// applies them again
+func TestMigrationsRevertAndReapply(t *testing.T) {
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "attendance.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteDB: %v", err)
+	}
+	defer db.Close()
+
+	for _, m := range migrations {
+		if !m.Reversible() {
+			t.Errorf("migration %d (%s) has no down script on every engine", m.Version, m.Name)
+		}
+	}
+
+	reverted, err := db.Revert(0, nil)
+	if err != nil {
+		t.Fatalf("Revert(0): %v", err)
+	}
+	if len(reverted) != LatestVersion() || reverted[0].Version != LatestVersion() {
+		t.Errorf("reverted %d migrations starting at %d, want all %d newest first", len(reverted), reverted[0].Version, LatestVersion())
+	}
+	if pending, err := db.PendingMigrations(0); err != nil || len(pending) != LatestVersion() {
+		t.Errorf("PendingMigrations after reverting = %d, %v; want all %d", len(pending), err, LatestVersion())
+	}
+
+	applied, err := db.Migrate(0, nil)
+	if err != nil {
+		t.Fatalf("Migrate(0) after reverting: %v", err)
+	}
+	if len(applied) != LatestVersion() {
+		t.Errorf("applied %d migrations, want %d", len(applied), LatestVersion())
+	}
+	if _, err := NewRepository(db).ListAttendance(context.Background(), 0, ""); err != nil {
+		t.Errorf("ListAttendance on the reapplied schema: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	_ "github.com/lib/pq"
+)
+
+// NewPostgresDB connects to a PostgreSQL database and applies all pending migrations
+func NewPostgresDB(databaseURL string) (*DB, error) {
+	postgresDB, err := OpenPostgresDB(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize schema
+	if _, err := postgresDB.Migrate(0, nil); err != nil {
+		postgresDB.Close()
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+
+	return postgresDB, nil
+}
+
+// OpenPostgresDB connects to a PostgreSQL database without touching the schema. The URL takes
+// the parameters of lib/pq, e.g. postgres://bot:secret@db:5432/attendance?sslmode=require.
+func OpenPostgresDB(databaseURL string) (*DB, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &DB{DB: db, engine: Postgres}, nil
+}
+
+// OpenPostgresDBReadOnly connects to a PostgreSQL database whose sessions refuse writes
+func OpenPostgresDBReadOnly(databaseURL string) (*DB, error) {
+	parsed, err := url.Parse(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+
+	// Parameters lib/pq does not know are sent to the server as run-time parameters
+	query := parsed.Query()
+	query.Set("default_transaction_read_only", "on")
+	parsed.RawQuery = query.Encode()
+
+	return OpenPostgresDB(parsed.String())
+}
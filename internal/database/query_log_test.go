@@ -0,0 +1,58 @@
+package database
+
+import (
+	"attendance-bot/internal/logging"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// TestQueryLogsRequestID runs a query with a request logger in its context: at debug level the
+// query is logged with the request ID of the logger, at info level nothing is logged
+func TestQueryLogsRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		level  slog.Level
+		logged bool
+	}{
+		{"debug", slog.LevelDebug, true},
+		{"info", slog.LevelInfo, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepository(t)
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: tt.level})).With("request_id", "proxy-id.42")
+			ctx := logging.NewContext(context.Background(), logger)
+
+			if _, err := repo.GetUserAttendanceStatus(ctx, 1, "2024-03-04"); err != nil {
+				t.Fatalf("GetUserAttendanceStatus: %v", err)
+			}
+
+			var found bool
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				if line == "" {
+					continue
+				}
+				var entry map[string]any
+				if err := json.Unmarshal([]byte(line), &entry); err != nil {
+					t.Fatalf("bad log line %q: %v", line, err)
+				}
+				if entry["query"] != "get_user_attendance_status" {
+					continue
+				}
+				found = true
+				if entry["msg"] != "Query" || entry["request_id"] != "proxy-id.42" {
+					t.Errorf("log line = %v, want a Query line with request_id proxy-id.42", entry)
+				}
+			}
+			if found != tt.logged {
+				t.Errorf("query logged = %v, want %v", found, tt.logged)
+			}
+		})
+	}
+}
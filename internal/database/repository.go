@@ -1,63 +1,144 @@
 package database
 
 import (
+	"attendance-bot/internal/logging"
+	"attendance-bot/internal/metrics"
+	"attendance-bot/internal/utils"
 	"attendance-bot/pkg/models"
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"sort"
 	"time"
 )
 
-// Repository handles all database operations
-type Repository struct {
-	db *SQLiteDB
+// sqlRepository implements Repository on either engine. Queries use SQL both engines accept,
+// with ? placeholders that DB rewrites for PostgreSQL.
+type sqlRepository struct {
+	db *DB
 }
 
-// NewRepository creates a new repository instance
-func NewRepository(db *SQLiteDB) *Repository {
-	return &Repository{db: db}
+// NewRepository creates a repository on the database's engine
+func NewRepository(db *DB) Repository {
+	return &sqlRepository{db: db}
 }
 
-// InsertAttendance adds a new attendance record
-func (r *Repository) InsertAttendance(record *models.AttendanceRecord) (*models.AttendanceRecord, error) {
-	query := `
-		INSERT INTO attendance (user_id, username, first_name, last_name, timestamp, type, date)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`
+// InsertAttendance adds a new attendance record. Records dated before the archive boundary are
+// refused with ErrArchived.
+func (r *sqlRepository) InsertAttendance(ctx context.Context, record *models.AttendanceRecord) (*models.AttendanceRecord, error) {
+	defer observeQuery(ctx, "insert_attendance", time.Now())
 
-	result, err := r.db.Exec(query,
-		record.UserID,
-		record.Username,
-		record.FirstName,
-		record.LastName,
-		record.Timestamp.Format(time.RFC3339),
-		record.Type,
-		record.Date,
-	)
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return nil, storageError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	archivedBefore, err := archivedBeforeTx(ctx, tx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to insert attendance: %w", err)
+		return nil, err
+	}
+	if archivedBefore != "" && record.Date < archivedBefore {
+		return nil, fmt.Errorf("%w: %s is before %s", ErrArchived, record.Date, archivedBefore)
 	}
 
-	id, err := result.LastInsertId()
+	id, err := insertAttendanceTx(ctx, tx, record)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get last insert ID: %w", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, storageError("commit attendance", err)
 	}
 
 	record.ID = id
 	return record, nil
 }
 
+// InsertAttendanceBatch inserts records in a single transaction. Check-ins and check-outs that
+// collide with an existing (user_id, date, type) entry are skipped and their indexes returned as duplicates.
+// The whole batch is refused with ErrArchived when a record is dated before the archive boundary.
+func (r *sqlRepository) InsertAttendanceBatch(ctx context.Context, records []models.AttendanceRecord) (int, []int, error) {
+	defer observeQuery(ctx, "insert_attendance_batch", time.Now())
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return 0, nil, storageError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	archivedBefore, err := archivedBeforeTx(ctx, tx)
+	if err != nil {
+		return 0, nil, err
+	}
+	for i, record := range records {
+		if archivedBefore != "" && record.Date < archivedBefore {
+			return 0, nil, fmt.Errorf("%w: row %d dated %s is before %s", ErrArchived, i, record.Date, archivedBefore)
+		}
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO attendance (user_id, username, first_name, last_name, timestamp, type, date, source, overtime_minutes, remote)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, date, type) WHERE type IN ('check_in', 'check_out') DO NOTHING
+	`)
+	if err != nil {
+		return 0, nil, storageError("prepare batch insert", err)
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	var duplicates []int
+	for i, record := range records {
+		result, err := stmt.ExecContext(ctx,
+			record.UserID,
+			record.Username,
+			record.FirstName,
+			record.LastName,
+			record.Timestamp.Format(time.RFC3339),
+			record.Type,
+			record.Date,
+			recordSource(&record),
+			record.OvertimeMinutes,
+			record.Remote,
+		)
+		if err != nil {
+			return 0, nil, storageError(fmt.Sprintf("insert attendance at row %d", i), err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, nil, storageError("get affected rows", err)
+		}
+		if affected == 0 {
+			duplicates = append(duplicates, i)
+			continue
+		}
+		inserted++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, nil, storageError("commit batch insert", err)
+	}
+
+	return inserted, duplicates, nil
+}
+
 // GetUserAttendanceToday retrieves today's attendance records for a user
-func (r *Repository) GetUserAttendanceToday(userID int64, date string) ([]models.AttendanceRecord, error) {
+func (r *sqlRepository) GetUserAttendanceToday(ctx context.Context, userID int64, date string) ([]models.AttendanceRecord, error) {
+	defer observeQuery(ctx, "get_user_attendance_today", time.Now())
+
 	query := `
-		SELECT id, user_id, username, first_name, last_name, timestamp, type, date
+		SELECT id, user_id, username, first_name, last_name, timestamp, type, date, source, photo_file_id, latitude, longitude, geofence, overtime_minutes, remote
 		FROM attendance
 		WHERE user_id = ? AND date = ?
 		ORDER BY timestamp ASC
 	`
 
-	rows, err := r.db.Query(query, userID, date)
+	rows, err := r.db.QueryContext(ctx, query, userID, date)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query attendance: %w", err)
+		return nil, storageError("query attendance", err)
 	}
 	defer rows.Close()
 
@@ -74,8 +155,10 @@ func (r *Repository) GetUserAttendanceToday(userID int64, date string) ([]models
 }
 
 // GetUserAttendanceStatus returns the attendance status for a user on a specific date
-func (r *Repository) GetUserAttendanceStatus(userID int64, date string) (*models.AttendanceStatus, error) {
-	records, err := r.GetUserAttendanceToday(userID, date)
+func (r *sqlRepository) GetUserAttendanceStatus(ctx context.Context, userID int64, date string) (*models.AttendanceStatus, error) {
+	defer observeQuery(ctx, "get_user_attendance_status", time.Now())
+
+	records, err := r.GetUserAttendanceToday(ctx, userID, date)
 	if err != nil {
 		return nil, err
 	}
@@ -85,13 +168,16 @@ func (r *Repository) GetUserAttendanceStatus(userID int64, date string) (*models
 		HasCheckedOut: false,
 	}
 
-	for _, record := range records {
+	for i := range records {
+		record := &records[i]
 		if record.Type == "check_in" {
 			status.HasCheckedIn = true
-			status.CheckInRecord = &record
+			status.CheckInRecord = record
 		} else if record.Type == "check_out" {
 			status.HasCheckedOut = true
-			status.CheckOutRecord = &record
+			status.CheckOutRecord = record
+		} else {
+			status.Breaks = append(status.Breaks, record)
 		}
 	}
 
@@ -99,17 +185,59 @@ func (r *Repository) GetUserAttendanceStatus(userID int64, date string) (*models
 }
 
 // GetUserAttendanceHistory retrieves attendance history for a user
-func (r *Repository) GetUserAttendanceHistory(userID int64, days int) ([]models.AttendanceRecord, error) {
-	query := `
-		SELECT id, user_id, username, first_name, last_name, timestamp, type, date
-		FROM attendance
-		WHERE user_id = ? AND date >= date('now', '-' || ? || ' days')
-		ORDER BY date DESC, timestamp ASC
-	`
+func (r *sqlRepository) GetUserAttendanceHistory(ctx context.Context, userID int64, days int) ([]models.AttendanceRecord, error) {
+	defer observeQuery(ctx, "get_user_attendance_history", time.Now())
+
+	since := time.Now().UTC().AddDate(0, 0, -days).Format("2006-01-02")
+	table, err := r.attendanceTable(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, username, first_name, last_name, timestamp, type, date, source, photo_file_id, latitude, longitude, geofence, overtime_minutes, remote
+		FROM %s a
+		WHERE user_id = ? AND date >= ?
+		ORDER BY date DESC, timestamp ASC, id ASC
+	`, table)
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, storageError("query attendance history", err)
+	}
+	defer rows.Close()
+
+	var records []models.AttendanceRecord
+	for rows.Next() {
+		record, err := r.scanAttendanceRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+
+	return records, nil
+}
+
+// GetUserAttendanceRange retrieves a user's attendance records within a date range, newest day first
+func (r *sqlRepository) GetUserAttendanceRange(ctx context.Context, userID int64, startDate, endDate string) ([]models.AttendanceRecord, error) {
+	defer observeQuery(ctx, "get_user_attendance_range", time.Now())
+
+	table, err := r.attendanceTable(ctx, startDate)
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := r.db.Query(query, userID, days)
+	query := fmt.Sprintf(`
+		SELECT id, user_id, username, first_name, last_name, timestamp, type, date, source, photo_file_id, latitude, longitude, geofence, overtime_minutes, remote
+		FROM %s a
+		WHERE user_id = ? AND date BETWEEN ? AND ?
+		ORDER BY date DESC, timestamp ASC, id ASC
+	`, table)
+
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query attendance history: %w", err)
+		return nil, storageError("query user attendance range", err)
 	}
 	defer rows.Close()
 
@@ -126,18 +254,25 @@ func (r *Repository) GetUserAttendanceHistory(userID int64, days int) ([]models.
 }
 
 // GetDailyReport retrieves all attendance records for a specific date
-func (r *Repository) GetDailyReport(date string) ([]models.AttendanceRecord, error) {
-	query := `
-		SELECT a.id, a.user_id, a.username, a.first_name, a.last_name, a.timestamp, a.type, a.date
-		FROM attendance a
+func (r *sqlRepository) GetDailyReport(ctx context.Context, date string) ([]models.AttendanceRecord, error) {
+	defer observeQuery(ctx, "get_daily_report", time.Now())
+
+	table, err := r.attendanceTable(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT a.id, a.user_id, a.username, a.first_name, a.last_name, a.timestamp, a.type, a.date, a.source, a.photo_file_id, a.latitude, a.longitude, a.geofence, a.overtime_minutes, a.remote
+		FROM %s a
 		LEFT JOIN alias al ON a.user_id = al.user_id
 		WHERE a.date = ?
-		ORDER BY a.timestamp ASC
-	`
+		ORDER BY a.timestamp ASC, a.id ASC
+	`, table)
 
-	rows, err := r.db.Query(query, date)
+	rows, err := r.db.QueryContext(ctx, query, date)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query daily report: %w", err)
+		return nil, storageError("query daily report", err)
 	}
 	defer rows.Close()
 
@@ -154,18 +289,77 @@ func (r *Repository) GetDailyReport(date string) ([]models.AttendanceRecord, err
 }
 
 // GetAttendanceReportRange retrieves attendance records within a date range
-func (r *Repository) GetAttendanceReportRange(startDate, endDate string) ([]models.AttendanceRecord, error) {
-	query := `
-		SELECT a.id, a.user_id, a.username, a.first_name, a.last_name, a.timestamp, a.type, a.date
-		FROM attendance a
+func (r *sqlRepository) GetAttendanceReportRange(ctx context.Context, startDate, endDate string) ([]models.AttendanceRecord, error) {
+	defer observeQuery(ctx, "get_attendance_report_range", time.Now())
+
+	table, err := r.attendanceTable(ctx, startDate)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT a.id, a.user_id, a.username, a.first_name, a.last_name, a.timestamp, a.type, a.date, a.source, a.photo_file_id, a.latitude, a.longitude, a.geofence, a.overtime_minutes, a.remote,
+			COALESCE(e.employee_id, ''), COALESCE(e.department, '')
+		FROM %s a
 		LEFT JOIN alias al ON a.user_id = al.user_id
+		LEFT JOIN employees e ON a.user_id = e.user_id
 		WHERE a.date BETWEEN ? AND ?
-		ORDER BY a.date ASC, a.timestamp ASC
-	`
+		ORDER BY a.date ASC, a.timestamp ASC, a.id ASC
+	`, table)
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
+	if err != nil {
+		return nil, storageError("query attendance report range", err)
+	}
+	defer rows.Close()
+
+	var records []models.AttendanceRecord
+	for rows.Next() {
+		var employeeID, department string
+		record, err := r.scanAttendanceRecord(rows, &employeeID, &department)
+		if err != nil {
+			return nil, err
+		}
+		record.EmployeeID, record.Department = employeeID, department
+		records = append(records, *record)
+	}
+
+	return records, nil
+}
+
+// ListAttendance retrieves attendance records filtered by user and/or date.
+// A zero userID or empty date disables that filter.
+func (r *sqlRepository) ListAttendance(ctx context.Context, userID int64, date string) ([]models.AttendanceRecord, error) {
+	defer observeQuery(ctx, "list_attendance", time.Now())
+
+	// Without a date filter every record is listed, including archived ones
+	table, err := r.attendanceTable(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filters are added only when set, so PostgreSQL never has to guess the type of an unused one
+	where := "1 = 1"
+	var args []any
+	if userID != 0 {
+		where += " AND user_id = ?"
+		args = append(args, userID)
+	}
+	if date != "" {
+		where += " AND date = ?"
+		args = append(args, date)
+	}
 
-	rows, err := r.db.Query(query, startDate, endDate)
+	query := fmt.Sprintf(`
+		SELECT id, user_id, username, first_name, last_name, timestamp, type, date, source, photo_file_id, latitude, longitude, geofence, overtime_minutes, remote
+		FROM %s a
+		WHERE %s
+		ORDER BY date ASC, timestamp ASC, id ASC
+	`, table, where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query attendance report range: %w", err)
+		return nil, storageError("query attendance", err)
 	}
 	defer rows.Close()
 
@@ -181,13 +375,32 @@ func (r *Repository) GetAttendanceReportRange(startDate, endDate string) ([]mode
 	return records, nil
 }
 
+// DeleteAttendance removes an attendance record by ID, returning false if it did not exist
+func (r *sqlRepository) DeleteAttendance(ctx context.Context, id int64) (bool, error) {
+	defer observeQuery(ctx, "delete_attendance", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM attendance WHERE id = ?", id)
+	if err != nil {
+		return false, storageError("delete attendance", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
 // SetUserAlias sets or updates a user's alias
-func (r *Repository) SetUserAlias(userID int64, firstName string, lastName *string) error {
+func (r *sqlRepository) SetUserAlias(ctx context.Context, userID int64, firstName string, lastName *string) error {
+	defer observeQuery(ctx, "set_user_alias", time.Now())
+
 	// Check if alias already exists
 	var exists bool
-	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM alias WHERE user_id = ?)", userID).Scan(&exists)
+	err := r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM alias WHERE user_id = ?)", userID).Scan(&exists)
 	if err != nil {
-		return fmt.Errorf("failed to check existing alias: %w", err)
+		return storageError("check existing alias", err)
 	}
 
 	var query string
@@ -201,27 +414,46 @@ func (r *Repository) SetUserAlias(userID int64, firstName string, lastName *stri
 		args = []interface{}{userID, firstName, lastName}
 	}
 
-	_, err = r.db.Exec(query, args...)
+	_, err = r.db.ExecContext(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to set user alias: %w", err)
+		return storageError("set user alias", err)
 	}
 
 	return nil
 }
 
+// DeleteUserAlias removes a user's alias, returning false if none existed
+func (r *sqlRepository) DeleteUserAlias(ctx context.Context, userID int64) (bool, error) {
+	defer observeQuery(ctx, "delete_user_alias", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM alias WHERE user_id = ?", userID)
+	if err != nil {
+		return false, storageError("delete user alias", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
 // GetUserAlias retrieves a user's alias
-func (r *Repository) GetUserAlias(userID int64) (*models.UserAlias, error) {
+func (r *sqlRepository) GetUserAlias(ctx context.Context, userID int64) (*models.UserAlias, error) {
+	defer observeQuery(ctx, "get_user_alias", time.Now())
+
 	query := "SELECT user_id, first_name, last_name FROM alias WHERE user_id = ?"
 
 	var alias models.UserAlias
 	var lastName sql.NullString
 
-	err := r.db.QueryRow(query, userID).Scan(&alias.UserID, &alias.FirstName, &lastName)
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&alias.UserID, &alias.FirstName, &lastName)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // No alias found
 		}
-		return nil, fmt.Errorf("failed to get user alias: %w", err)
+		return nil, storageError("get user alias", err)
 	}
 
 	if lastName.Valid {
@@ -231,50 +463,2794 @@ func (r *Repository) GetUserAlias(userID int64) (*models.UserAlias, error) {
 	return &alias, nil
 }
 
-// scanAttendanceRecord scans a database row into an AttendanceRecord
-func (r *Repository) scanAttendanceRecord(rows *sql.Rows) (*models.AttendanceRecord, error) {
-	var record models.AttendanceRecord
-	var lastName sql.NullString
-	var timestampStr string
+// userNames lists every user's alias and Telegram names. Telegram names come from attendance
+// records, so only users who have recorded attendance are known.
+const userNames = `
+	SELECT user_id, 'alias' AS kind, first_name, last_name FROM alias
+	UNION
+	SELECT DISTINCT user_id, 'telegram', first_name, last_name FROM attendance
+`
 
-	err := rows.Scan(
-		&record.ID,
-		&record.UserID,
-		&record.Username,
-		&record.FirstName,
-		&lastName,
-		&timestampStr,
-		&record.Type,
-		&record.Date,
+// FindNameMatches returns the alias and Telegram names of users other than userID whose
+// normalized name equals key
+func (r *sqlRepository) FindNameMatches(ctx context.Context, userID int64, key string) ([]models.NameMatch, error) {
+	defer observeQuery(ctx, "find_name_matches", time.Now())
+
+	names, err := r.queryNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []models.NameMatch
+	for _, name := range names {
+		if name.Key == key && name.UserID != userID {
+			matches = append(matches, name)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].UserID != matches[j].UserID {
+			return matches[i].UserID < matches[j].UserID
+		}
+		return matches[i].Kind < matches[j].Kind
+	})
+
+	return matches, nil
+}
+
+// GetNameCollisions returns every name shared by more than one user, ordered by normalized name
+func (r *sqlRepository) GetNameCollisions(ctx context.Context) ([]models.NameMatch, error) {
+	defer observeQuery(ctx, "get_name_collisions", time.Now())
+
+	names, err := r.queryNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string]map[int64]bool)
+	for _, name := range names {
+		if users[name.Key] == nil {
+			users[name.Key] = make(map[int64]bool)
+		}
+		users[name.Key][name.UserID] = true
+	}
+
+	var collisions []models.NameMatch
+	for _, name := range names {
+		if len(users[name.Key]) > 1 {
+			collisions = append(collisions, name)
+		}
+	}
+	sort.SliceStable(collisions, func(i, j int) bool {
+		a, b := collisions[i], collisions[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return a.Kind < b.Kind
+	})
+
+	return collisions, nil
+}
+
+// queryNames returns every user's names with their key. Keys are computed with utils.NameKey
+// rather than in SQL, so names compare by the same Unicode-aware rules on every engine.
+func (r *sqlRepository) queryNames(ctx context.Context) ([]models.NameMatch, error) {
+	rows, err := r.db.QueryContext(ctx, userNames)
+	if err != nil {
+		return nil, storageError("query name matches", err)
+	}
+	defer rows.Close()
+
+	var names []models.NameMatch
+	for rows.Next() {
+		var name models.NameMatch
+		var lastName sql.NullString
+		if err := rows.Scan(&name.UserID, &name.Kind, &name.FirstName, &lastName); err != nil {
+			return nil, storageError("scan name match", err)
+		}
+		if lastName.Valid {
+			name.LastName = &lastName.String
+		}
+		name.Key = utils.NameKey(name.FirstName, lastName.String)
+		names = append(names, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate name matches", err)
+	}
+
+	return names, nil
+}
+
+// SaveAliasRequest stores an alias awaiting approval, replacing the user's previous request
+func (r *sqlRepository) SaveAliasRequest(ctx context.Context, request *models.AliasRequest) error {
+	defer observeQuery(ctx, "save_alias_request", time.Now())
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO alias_requests (user_id, first_name, last_name, requested_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET
+			first_name = excluded.first_name,
+			last_name = excluded.last_name,
+			requested_at = excluded.requested_at
+	`,
+		request.UserID,
+		request.FirstName,
+		request.LastName,
+		request.RequestedAt.UTC().Format(time.RFC3339),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan attendance record: %w", err)
+		return storageError("save alias request", err)
 	}
 
-	// Parse timestamp
-	timestamp, err := time.Parse(time.RFC3339, timestampStr)
+	return nil
+}
+
+// GetAliasRequest returns the user's pending alias request, or nil if they have none
+func (r *sqlRepository) GetAliasRequest(ctx context.Context, userID int64) (*models.AliasRequest, error) {
+	defer observeQuery(ctx, "get_alias_request", time.Now())
+
+	requests, err := r.queryAliasRequests(ctx, "WHERE user_id = ?", userID)
+	if err != nil || len(requests) == 0 {
+		return nil, err
+	}
+	return &requests[0], nil
+}
+
+// GetAliasRequests returns all pending alias requests, oldest first
+func (r *sqlRepository) GetAliasRequests(ctx context.Context) ([]models.AliasRequest, error) {
+	defer observeQuery(ctx, "get_alias_requests", time.Now())
+	return r.queryAliasRequests(ctx, "")
+}
+
+// queryAliasRequests selects alias requests matching the where clause, oldest first
+func (r *sqlRepository) queryAliasRequests(ctx context.Context, where string, args ...any) ([]models.AliasRequest, error) {
+	query := fmt.Sprintf(`
+		SELECT user_id, first_name, last_name, requested_at
+		FROM alias_requests
+		%s
+		ORDER BY requested_at, user_id
+	`, where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+		return nil, storageError("query alias requests", err)
 	}
-	record.Timestamp = timestamp
+	defer rows.Close()
 
-	// Handle nullable last name
-	if lastName.Valid {
-		record.LastName = &lastName.String
+	var requests []models.AliasRequest
+	for rows.Next() {
+		var request models.AliasRequest
+		var lastName sql.NullString
+		var requestedAt string
+		if err := rows.Scan(&request.UserID, &request.FirstName, &lastName, &requestedAt); err != nil {
+			return nil, storageError("scan alias request", err)
+		}
+		if lastName.Valid {
+			request.LastName = &lastName.String
+		}
+		if request.RequestedAt, err = time.Parse(time.RFC3339, requestedAt); err != nil {
+			return nil, storageError("parse requested_at", err)
+		}
+		requests = append(requests, request)
 	}
 
-	return &record, nil
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate alias requests", err)
+	}
+
+	return requests, nil
 }
 
-// CheckUserAttendanceExists checks if a user has any attendance record for a specific date and type
-func (r *Repository) CheckUserAttendanceExists(userID int64, date, attendanceType string) (bool, error) {
-	query := "SELECT EXISTS(SELECT 1 FROM attendance WHERE user_id = ? AND date = ? AND type = ?)"
+// DeleteAliasRequest removes the user's pending alias request, returning false if none existed
+func (r *sqlRepository) DeleteAliasRequest(ctx context.Context, userID int64) (bool, error) {
+	defer observeQuery(ctx, "delete_alias_request", time.Now())
 
-	var exists bool
-	err := r.db.QueryRow(query, userID, date, attendanceType).Scan(&exists)
+	result, err := r.db.ExecContext(ctx, "DELETE FROM alias_requests WHERE user_id = ?", userID)
 	if err != nil {
-		return false, fmt.Errorf("failed to check attendance existence: %w", err)
+		return false, storageError("delete alias request", err)
 	}
 
-	return exists, nil
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// AddAdmin grants a user admin rights, returning false if they already had them
+func (r *sqlRepository) AddAdmin(ctx context.Context, admin *models.Admin) (bool, error) {
+	defer observeQuery(ctx, "add_admin", time.Now())
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO admins (user_id, role, added_by, added_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id) DO NOTHING
+	`,
+		admin.UserID,
+		admin.Role,
+		admin.AddedBy,
+		admin.AddedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return false, storageError("add admin", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// RemoveAdmin revokes a user's admin rights, returning false if they had none
+func (r *sqlRepository) RemoveAdmin(ctx context.Context, userID int64) (bool, error) {
+	defer observeQuery(ctx, "remove_admin", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM admins WHERE user_id = ?", userID)
+	if err != nil {
+		return false, storageError("remove admin", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetAdmin returns the user's admin entry, or nil if they are not an admin
+func (r *sqlRepository) GetAdmin(ctx context.Context, userID int64) (*models.Admin, error) {
+	defer observeQuery(ctx, "get_admin", time.Now())
+
+	admins, err := r.queryAdmins(ctx, "WHERE user_id = ?", userID)
+	if err != nil || len(admins) == 0 {
+		return nil, err
+	}
+	return &admins[0], nil
+}
+
+// GetAdmins returns all admins in the order they were added
+func (r *sqlRepository) GetAdmins(ctx context.Context) ([]models.Admin, error) {
+	defer observeQuery(ctx, "get_admins", time.Now())
+	return r.queryAdmins(ctx, "")
+}
+
+// queryAdmins selects admins matching the where clause, in the order they were added
+func (r *sqlRepository) queryAdmins(ctx context.Context, where string, args ...any) ([]models.Admin, error) {
+	query := fmt.Sprintf(`
+		SELECT user_id, role, added_by, added_at
+		FROM admins
+		%s
+		ORDER BY added_at, user_id
+	`, where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, storageError("query admins", err)
+	}
+	defer rows.Close()
+
+	var admins []models.Admin
+	for rows.Next() {
+		var admin models.Admin
+		var addedAt string
+		if err := rows.Scan(&admin.UserID, &admin.Role, &admin.AddedBy, &addedAt); err != nil {
+			return nil, storageError("scan admin", err)
+		}
+		if admin.AddedAt, err = time.Parse(time.RFC3339, addedAt); err != nil {
+			return nil, storageError("parse added_at", err)
+		}
+		admins = append(admins, admin)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate admins", err)
+	}
+
+	return admins, nil
+}
+
+// InsertLeave stores a new leave request
+func (r *sqlRepository) InsertLeave(ctx context.Context, leave *models.Leave) (*models.Leave, error) {
+	defer observeQuery(ctx, "insert_leave", time.Now())
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO leave_requests (user_id, username, first_name, last_name, type, start_date, end_date, reason, status, requested_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`,
+		leave.UserID,
+		leave.Username,
+		leave.FirstName,
+		leave.LastName,
+		leave.Type,
+		leave.StartDate,
+		leave.EndDate,
+		leave.Reason,
+		leave.Status,
+		leave.RequestedAt.UTC().Format(time.RFC3339),
+	).Scan(&id)
+	if err != nil {
+		return nil, storageError("insert leave request", err)
+	}
+
+	inserted := *leave
+	inserted.ID = id
+	return &inserted, nil
+}
+
+// GetLeave returns a leave request by ID, or nil if it does not exist
+func (r *sqlRepository) GetLeave(ctx context.Context, id int64) (*models.Leave, error) {
+	defer observeQuery(ctx, "get_leave", time.Now())
+
+	leaves, err := r.queryLeaves(ctx, "WHERE id = ?", id)
+	if err != nil || len(leaves) == 0 {
+		return nil, err
+	}
+	return &leaves[0], nil
+}
+
+// DecideLeave approves or rejects a pending leave request, returning false if it was not pending
+func (r *sqlRepository) DecideLeave(ctx context.Context, id int64, status string, decidedBy int64, decidedAt time.Time) (bool, error) {
+	defer observeQuery(ctx, "decide_leave", time.Now())
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE leave_requests SET status = ?, decided_by = ?, decided_at = ?
+		WHERE id = ? AND status = 'pending'
+	`, status, decidedBy, decidedAt.UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return false, storageError("decide leave request", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetApprovedLeaves returns the approved leaves overlapping startDate to endDate (inclusive)
+func (r *sqlRepository) GetApprovedLeaves(ctx context.Context, startDate, endDate string) ([]models.Leave, error) {
+	defer observeQuery(ctx, "get_approved_leaves", time.Now())
+	return r.queryLeaves(ctx, "WHERE status = 'approved' AND start_date <= ? AND end_date >= ?", endDate, startDate)
+}
+
+// GetUserLeaves returns the user's leave requests that end on or after fromDate
+func (r *sqlRepository) GetUserLeaves(ctx context.Context, userID int64, fromDate string) ([]models.Leave, error) {
+	defer observeQuery(ctx, "get_user_leaves", time.Now())
+	return r.queryLeaves(ctx, "WHERE user_id = ? AND end_date >= ?", userID, fromDate)
+}
+
+// HasOverlappingLeave reports whether the user has a pending or approved leave overlapping
+// startDate to endDate (inclusive)
+func (r *sqlRepository) HasOverlappingLeave(ctx context.Context, userID int64, startDate, endDate string) (bool, error) {
+	defer observeQuery(ctx, "has_overlapping_leave", time.Now())
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM leave_requests
+			WHERE user_id = ? AND status IN ('pending', 'approved') AND start_date <= ? AND end_date >= ?
+		)
+	`, userID, endDate, startDate).Scan(&exists)
+	if err != nil {
+		return false, storageError("check overlapping leave", err)
+	}
+
+	return exists, nil
+}
+
+// queryLeaves selects leave requests matching the where clause, ordered by start date and user
+func (r *sqlRepository) queryLeaves(ctx context.Context, where string, args ...any) ([]models.Leave, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, username, first_name, last_name, type, start_date, end_date, reason,
+			status, requested_at, decided_by, decided_at,
+			COALESCE((SELECT e.employee_id FROM employees e WHERE e.user_id = l.user_id), ''),
+			COALESCE((SELECT e.department FROM employees e WHERE e.user_id = l.user_id), '')
+		FROM leave_requests l
+		%s
+		ORDER BY start_date, user_id, id
+	`, where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, storageError("query leave requests", err)
+	}
+	defer rows.Close()
+
+	var leaves []models.Leave
+	for rows.Next() {
+		var leave models.Leave
+		var lastName, decidedAt sql.NullString
+		var decidedBy sql.NullInt64
+		var requestedAt string
+		if err := rows.Scan(&leave.ID, &leave.UserID, &leave.Username, &leave.FirstName, &lastName,
+			&leave.Type, &leave.StartDate, &leave.EndDate, &leave.Reason,
+			&leave.Status, &requestedAt, &decidedBy, &decidedAt, &leave.EmployeeID, &leave.Department); err != nil {
+			return nil, storageError("scan leave request", err)
+		}
+		if lastName.Valid {
+			leave.LastName = &lastName.String
+		}
+		if leave.RequestedAt, err = time.Parse(time.RFC3339, requestedAt); err != nil {
+			return nil, storageError("parse requested_at", err)
+		}
+		leave.DecidedBy = decidedBy.Int64
+		if decidedAt.Valid {
+			decided, err := time.Parse(time.RFC3339, decidedAt.String)
+			if err != nil {
+				return nil, storageError("parse decided_at", err)
+			}
+			leave.DecidedAt = &decided
+		}
+		leaves = append(leaves, leave)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate leave requests", err)
+	}
+
+	return leaves, nil
+}
+
+// SaveShift creates the shift or replaces the times of the shift with the same name
+func (r *sqlRepository) SaveShift(ctx context.Context, shift *models.Shift) error {
+	defer observeQuery(ctx, "save_shift", time.Now())
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO shifts (name, start_time, end_time, grace_minutes) VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			start_time = excluded.start_time,
+			end_time = excluded.end_time,
+			grace_minutes = excluded.grace_minutes
+	`, shift.Name, shift.Start, shift.End, shift.GraceMinutes)
+	if err != nil {
+		return storageError("save shift", err)
+	}
+
+	return nil
+}
+
+// DeleteShift deletes a shift that no user is assigned to, returning false if it does not exist
+func (r *sqlRepository) DeleteShift(ctx context.Context, name string) (bool, error) {
+	defer observeQuery(ctx, "delete_shift", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM shifts WHERE name = ?", name)
+	if err != nil {
+		return false, storageError("delete shift", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetShifts returns all shifts with the number of users assigned to each, ordered by start time
+func (r *sqlRepository) GetShifts(ctx context.Context) ([]models.Shift, error) {
+	defer observeQuery(ctx, "get_shifts", time.Now())
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.name, s.start_time, s.end_time, s.grace_minutes, COUNT(u.user_id)
+		FROM shifts s
+		LEFT JOIN user_shifts u ON u.shift_name = s.name
+		GROUP BY s.name
+		ORDER BY s.start_time, s.name
+	`)
+	if err != nil {
+		return nil, storageError("query shifts", err)
+	}
+	defer rows.Close()
+
+	var shifts []models.Shift
+	for rows.Next() {
+		var shift models.Shift
+		if err := rows.Scan(&shift.Name, &shift.Start, &shift.End, &shift.GraceMinutes, &shift.Users); err != nil {
+			return nil, storageError("scan shift", err)
+		}
+		shifts = append(shifts, shift)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate shifts", err)
+	}
+
+	return shifts, nil
+}
+
+// AssignShift assigns a user to a shift, replacing any previous assignment
+func (r *sqlRepository) AssignShift(ctx context.Context, userID int64, shiftName string, assignedBy int64, assignedAt time.Time) error {
+	defer observeQuery(ctx, "assign_shift", time.Now())
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_shifts (user_id, shift_name, assigned_by, assigned_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET
+			shift_name = excluded.shift_name,
+			assigned_by = excluded.assigned_by,
+			assigned_at = excluded.assigned_at
+	`, userID, shiftName, assignedBy, assignedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return storageError("assign shift", err)
+	}
+
+	return nil
+}
+
+// UnassignShift removes a user's shift assignment, returning false if they had none
+func (r *sqlRepository) UnassignShift(ctx context.Context, userID int64) (bool, error) {
+	defer observeQuery(ctx, "unassign_shift", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM user_shifts WHERE user_id = ?", userID)
+	if err != nil {
+		return false, storageError("unassign shift", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetShiftAssignments returns the shift of every user: their assigned shift, else the shift named
+// models.DefaultShiftName, else models.BuiltinShift, along with the timezones users set
+func (r *sqlRepository) GetShiftAssignments(ctx context.Context) (*models.ShiftAssignments, error) {
+	defer observeQuery(ctx, "get_shift_assignments", time.Now())
+
+	assignments := &models.ShiftAssignments{
+		Default:   models.BuiltinShift,
+		Users:     make(map[int64]models.Shift),
+		Locations: make(map[int64]*time.Location),
+	}
+
+	err := r.db.QueryRowContext(ctx, "SELECT name, start_time, end_time, grace_minutes FROM shifts WHERE name = ?", models.DefaultShiftName).
+		Scan(&assignments.Default.Name, &assignments.Default.Start, &assignments.Default.End, &assignments.Default.GraceMinutes)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, storageError("query default shift", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.user_id, s.name, s.start_time, s.end_time, s.grace_minutes
+		FROM user_shifts u
+		JOIN shifts s ON s.name = u.shift_name
+	`)
+	if err != nil {
+		return nil, storageError("query shift assignments", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int64
+		var shift models.Shift
+		if err := rows.Scan(&userID, &shift.Name, &shift.Start, &shift.End, &shift.GraceMinutes); err != nil {
+			return nil, storageError("scan shift assignment", err)
+		}
+		assignments.Users[userID] = shift
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate shift assignments", err)
+	}
+
+	if err := r.loadUserLocations(ctx, assignments.Locations); err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// loadUserLocations adds the timezones users set to locations. A timezone the host's timezone
+// database no longer knows is skipped, leaving the user in TIMEZONE.
+func (r *sqlRepository) loadUserLocations(ctx context.Context, locations map[int64]*time.Location) error {
+	rows, err := r.db.QueryContext(ctx, "SELECT user_id, timezone FROM user_timezones")
+	if err != nil {
+		return storageError("query user timezones", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int64
+		var name string
+		if err := rows.Scan(&userID, &name); err != nil {
+			return storageError("scan user timezone", err)
+		}
+		if location, err := utils.LoadLocation(name); err == nil {
+			locations[userID] = location
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return storageError("iterate user timezones", err)
+	}
+	return nil
+}
+
+// InsertFailedOTP records a rejected OTP attempt
+func (r *sqlRepository) InsertFailedOTP(ctx context.Context, failure *models.FailedOTP) error {
+	defer observeQuery(ctx, "insert_failed_otp", time.Now())
+
+	query := `
+		INSERT INTO failed_otps (user_id, username, chat_type, code, timestamp)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		failure.UserID,
+		failure.Username,
+		failure.ChatType,
+		failure.Code,
+		failure.Timestamp.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return storageError("insert failed otp", err)
+	}
+
+	return nil
+}
+
+// CountFailedOTPsSince returns how many failed attempts a user made since the given time
+func (r *sqlRepository) CountFailedOTPsSince(ctx context.Context, userID int64, since time.Time) (int, error) {
+	defer observeQuery(ctx, "count_failed_otps_since", time.Now())
+
+	query := "SELECT COUNT(*) FROM failed_otps WHERE user_id = ? AND timestamp >= ?"
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, userID, since.UTC().Format(time.RFC3339)).Scan(&count)
+	if err != nil {
+		return 0, storageError("count failed otps", err)
+	}
+
+	return count, nil
+}
+
+// GetFailedOTPsSince retrieves all failed attempts since the given time, newest first
+func (r *sqlRepository) GetFailedOTPsSince(ctx context.Context, since time.Time) ([]models.FailedOTP, error) {
+	defer observeQuery(ctx, "get_failed_otps_since", time.Now())
+
+	query := `
+		SELECT id, user_id, username, chat_type, code, timestamp
+		FROM failed_otps
+		WHERE timestamp >= ?
+		ORDER BY timestamp DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, storageError("query failed otps", err)
+	}
+	defer rows.Close()
+
+	var failures []models.FailedOTP
+	for rows.Next() {
+		var failure models.FailedOTP
+		var timestampStr string
+
+		if err := rows.Scan(&failure.ID, &failure.UserID, &failure.Username, &failure.ChatType, &failure.Code, &timestampStr); err != nil {
+			return nil, storageError("scan failed otp", err)
+		}
+
+		timestamp, err := time.Parse(time.RFC3339, timestampStr)
+		if err != nil {
+			return nil, storageError("parse timestamp", err)
+		}
+		failure.Timestamp = timestamp
+
+		failures = append(failures, failure)
+	}
+
+	return failures, nil
+}
+
+// GetOTPLockout returns the user's failed OTP counter, or nil if they have none
+func (r *sqlRepository) GetOTPLockout(ctx context.Context, userID int64) (*models.OTPLockout, error) {
+	defer observeQuery(ctx, "get_otp_lockout", time.Now())
+
+	lockouts, err := r.queryOTPLockouts(ctx, "SELECT user_id, failures, window_start, locked_until FROM otp_lockouts WHERE user_id = ?", userID)
+	if err != nil || len(lockouts) == 0 {
+		return nil, err
+	}
+	return &lockouts[0], nil
+}
+
+// CountOTPFailure atomically counts a failed OTP made at now and returns the user's counter. It is
+// a single statement, so replicas sharing the database count every failure exactly once.
+func (r *sqlRepository) CountOTPFailure(ctx context.Context, userID int64, now, windowAfter time.Time, limit int, lockedUntil time.Time) (*models.OTPLockout, error) {
+	defer observeQuery(ctx, "count_otp_failure", time.Now())
+
+	// The timestamps are stored as UTC RFC 3339 strings, which sort like the times they hold
+	query := `
+		INSERT INTO otp_lockouts (user_id, failures, window_start, locked_until)
+		VALUES (?, 1, ?, CASE WHEN 1 >= ? THEN ? ELSE '' END)
+		ON CONFLICT(user_id) DO UPDATE SET
+			failures = CASE WHEN otp_lockouts.locked_until = '' AND otp_lockouts.window_start > ?
+				THEN otp_lockouts.failures + 1 ELSE 1 END,
+			window_start = CASE WHEN otp_lockouts.locked_until = '' AND otp_lockouts.window_start > ?
+				THEN otp_lockouts.window_start ELSE excluded.window_start END,
+			locked_until = CASE WHEN (CASE WHEN otp_lockouts.locked_until = '' AND otp_lockouts.window_start > ?
+				THEN otp_lockouts.failures + 1 ELSE 1 END) >= ? THEN ? ELSE '' END
+		RETURNING user_id, failures, window_start, locked_until
+	`
+	after := windowAfter.UTC().Format(time.RFC3339)
+	until := lockedUntil.UTC().Format(time.RFC3339)
+	lockouts, err := r.queryOTPLockouts(ctx, query,
+		userID, now.UTC().Format(time.RFC3339), limit, until,
+		after, after, after, limit, until)
+	if err != nil {
+		return nil, err
+	}
+	if len(lockouts) == 0 {
+		return nil, storageError("count otp failure", sql.ErrNoRows)
+	}
+	return &lockouts[0], nil
+}
+
+// DeleteOTPLockout removes the user's failed OTP counter, returning false if they had none
+func (r *sqlRepository) DeleteOTPLockout(ctx context.Context, userID int64) (bool, error) {
+	defer observeQuery(ctx, "delete_otp_lockout", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM otp_lockouts WHERE user_id = ?", userID)
+	if err != nil {
+		return false, storageError("delete otp lockout", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetOTPLockoutsUntil returns the counters of users locked out after the given time, those locked
+// out longest first
+func (r *sqlRepository) GetOTPLockoutsUntil(ctx context.Context, after time.Time) ([]models.OTPLockout, error) {
+	defer observeQuery(ctx, "get_otp_lockouts_until", time.Now())
+
+	query := `
+		SELECT user_id, failures, window_start, locked_until
+		FROM otp_lockouts
+		WHERE locked_until > ?
+		ORDER BY locked_until DESC
+	`
+	return r.queryOTPLockouts(ctx, query, after.UTC().Format(time.RFC3339))
+}
+
+// queryOTPLockouts runs an otp_lockouts query and scans the rows
+func (r *sqlRepository) queryOTPLockouts(ctx context.Context, query string, args ...interface{}) ([]models.OTPLockout, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, storageError("query otp lockouts", err)
+	}
+	defer rows.Close()
+
+	var lockouts []models.OTPLockout
+	for rows.Next() {
+		var lockout models.OTPLockout
+		var windowStart, lockedUntil string
+		if err := rows.Scan(&lockout.UserID, &lockout.Failures, &windowStart, &lockedUntil); err != nil {
+			return nil, storageError("scan otp lockout", err)
+		}
+		if lockout.WindowStart, err = time.Parse(time.RFC3339, windowStart); err != nil {
+			return nil, storageError("parse window_start", err)
+		}
+		if lockedUntil != "" {
+			if lockout.LockedUntil, err = time.Parse(time.RFC3339, lockedUntil); err != nil {
+				return nil, storageError("parse locked_until", err)
+			}
+		}
+		lockouts = append(lockouts, lockout)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate otp lockouts", err)
+	}
+
+	return lockouts, nil
+}
+
+// GetHOTPEnrollment retrieves a user's HOTP enrollment, or nil if the user uses TOTP.
+// The secret is stored separately in user_secrets and is not populated.
+func (r *sqlRepository) GetHOTPEnrollment(ctx context.Context, userID int64) (*models.HOTPEnrollment, error) {
+	defer observeQuery(ctx, "get_hotp_enrollment", time.Now())
+
+	query := "SELECT user_id, counter FROM hotp_enrollment WHERE user_id = ?"
+
+	var enrollment models.HOTPEnrollment
+	var counter int64
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&enrollment.UserID, &counter)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not enrolled
+		}
+		return nil, storageError("get hotp enrollment", err)
+	}
+
+	enrollment.Counter = uint64(counter)
+	return &enrollment, nil
+}
+
+// GetHOTPEmployees returns the users enrolled in HOTP, named by their alias or else by the
+// Telegram name of their latest attendance record. Users with neither have an empty first name.
+func (r *sqlRepository) GetHOTPEmployees(ctx context.Context) ([]models.Employee, error) {
+	defer observeQuery(ctx, "get_hotp_employees", time.Now())
+
+	query := `
+		SELECT
+			h.user_id,
+			COALESCE(latest.username, ''),
+			COALESCE(al.first_name, latest.first_name, ''),
+			CASE WHEN al.user_id IS NOT NULL THEN al.last_name ELSE latest.last_name END
+		FROM hotp_enrollment h
+		LEFT JOIN alias al ON al.user_id = h.user_id
+		LEFT JOIN attendance latest ON latest.id = (
+			SELECT MAX(id) FROM attendance WHERE user_id = h.user_id
+		)
+		ORDER BY h.user_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, storageError("query hotp employees", err)
+	}
+	defer rows.Close()
+
+	var employees []models.Employee
+	for rows.Next() {
+		var employee models.Employee
+		var lastName sql.NullString
+		if err := rows.Scan(&employee.UserID, &employee.Username, &employee.FirstName, &lastName); err != nil {
+			return nil, storageError("scan hotp employee", err)
+		}
+		if lastName.Valid {
+			employee.LastName = &lastName.String
+		}
+		employees = append(employees, employee)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate hotp employees", err)
+	}
+
+	return employees, nil
+}
+
+// SetHOTPEnrollment creates or replaces a user's HOTP enrollment together with its encrypted secret
+func (r *sqlRepository) SetHOTPEnrollment(ctx context.Context, enrollment *models.HOTPEnrollment, secret *models.UserSecret) error {
+	defer observeQuery(ctx, "set_hotp_enrollment", time.Now())
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return storageError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO hotp_enrollment (user_id, counter)
+		VALUES (?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET counter = excluded.counter
+	`, enrollment.UserID, int64(enrollment.Counter))
+	if err != nil {
+		return storageError("set hotp enrollment", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO user_secrets (user_id, ciphertext, nonce)
+		VALUES (?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET ciphertext = excluded.ciphertext, nonce = excluded.nonce
+	`, secret.UserID, secret.Ciphertext, secret.Nonce)
+	if err != nil {
+		return storageError("set user secret", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return storageError("commit hotp enrollment", err)
+	}
+
+	return nil
+}
+
+// DeleteHOTPEnrollment removes a user's HOTP enrollment and secret, returning them to TOTP
+func (r *sqlRepository) DeleteHOTPEnrollment(ctx context.Context, userID int64) error {
+	defer observeQuery(ctx, "delete_hotp_enrollment", time.Now())
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return storageError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM hotp_enrollment WHERE user_id = ?", userID); err != nil {
+		return storageError("delete hotp enrollment", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_secrets WHERE user_id = ?", userID); err != nil {
+		return storageError("delete user secret", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return storageError("commit hotp enrollment removal", err)
+	}
+
+	return nil
+}
+
+// GetUserSecret retrieves a user's encrypted secret
+func (r *sqlRepository) GetUserSecret(ctx context.Context, userID int64) (*models.UserSecret, error) {
+	defer observeQuery(ctx, "get_user_secret", time.Now())
+
+	query := "SELECT user_id, ciphertext, nonce FROM user_secrets WHERE user_id = ?"
+
+	var secret models.UserSecret
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&secret.UserID, &secret.Ciphertext, &secret.Nonce)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // No secret stored
+		}
+		return nil, storageError("get user secret", err)
+	}
+
+	return &secret, nil
+}
+
+// ListUserSecrets retrieves all encrypted user secrets
+func (r *sqlRepository) ListUserSecrets(ctx context.Context) ([]models.UserSecret, error) {
+	defer observeQuery(ctx, "list_user_secrets", time.Now())
+
+	rows, err := r.db.QueryContext(ctx, "SELECT user_id, ciphertext, nonce FROM user_secrets ORDER BY user_id")
+	if err != nil {
+		return nil, storageError("query user secrets", err)
+	}
+	defer rows.Close()
+
+	var secrets []models.UserSecret
+	for rows.Next() {
+		var secret models.UserSecret
+		if err := rows.Scan(&secret.UserID, &secret.Ciphertext, &secret.Nonce); err != nil {
+			return nil, storageError("scan user secret", err)
+		}
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, rows.Err()
+}
+
+// GetSharedSecretUserIDs returns the users who recorded attendance on or after sinceDate and have
+// no HOTP enrollment, i.e. verify with the shared TOTP secret, ordered by ID
+func (r *sqlRepository) GetSharedSecretUserIDs(ctx context.Context, sinceDate string) ([]int64, error) {
+	defer observeQuery(ctx, "get_shared_secret_user_ids", time.Now())
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT user_id FROM attendance
+		WHERE date >= ? AND user_id NOT IN (SELECT user_id FROM hotp_enrollment)
+		ORDER BY user_id`, sinceDate)
+	if err != nil {
+		return nil, storageError("query shared secret users", err)
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, storageError("scan shared secret user", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+// UpdateUserSecrets replaces the ciphertext of several secrets in a single transaction
+func (r *sqlRepository) UpdateUserSecrets(ctx context.Context, secrets []models.UserSecret) error {
+	defer observeQuery(ctx, "update_user_secrets", time.Now())
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return storageError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	for _, secret := range secrets {
+		_, err := tx.ExecContext(ctx, "UPDATE user_secrets SET ciphertext = ?, nonce = ? WHERE user_id = ?",
+			secret.Ciphertext, secret.Nonce, secret.UserID)
+		if err != nil {
+			return storageError("update user secret", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return storageError("commit user secrets", err)
+	}
+
+	return nil
+}
+
+// CountUserSecrets returns the number of stored user secrets
+func (r *sqlRepository) CountUserSecrets(ctx context.Context) (int, error) {
+	defer observeQuery(ctx, "count_user_secrets", time.Now())
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM user_secrets").Scan(&count); err != nil {
+		return 0, storageError("count user secrets", err)
+	}
+	return count, nil
+}
+
+// InsertAttendanceAdvancingHOTP moves a user's HOTP counter from expected to next and inserts the
+// attendance the matched code verifies in one transaction, so a record that fails to save leaves
+// the code unused
+func (r *sqlRepository) InsertAttendanceAdvancingHOTP(ctx context.Context, record *models.AttendanceRecord, expected, next uint64) (*models.AttendanceRecord, error) {
+	defer observeQuery(ctx, "insert_attendance_advancing_hotp", time.Now())
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return nil, storageError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE hotp_enrollment SET counter = ? WHERE user_id = ? AND counter = ?",
+		int64(next), record.UserID, int64(expected))
+	if err != nil {
+		return nil, storageError("advance hotp counter", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, storageError("get affected rows", err)
+	}
+	if affected == 0 {
+		return nil, fmt.Errorf("hotp counter %d of user %d: %w", expected, record.UserID, ErrNotFound)
+	}
+
+	id, err := insertAttendanceTx(ctx, tx, record)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, storageError("commit hotp attendance", err)
+	}
+
+	record.ID = id
+	return record, nil
+}
+
+// insertAttendanceTx inserts an attendance record within a transaction, returning its ID
+func insertAttendanceTx(ctx context.Context, tx *Tx, record *models.AttendanceRecord) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO attendance (user_id, username, first_name, last_name, timestamp, type, date, source, latitude, longitude, geofence, overtime_minutes, remote)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`,
+		record.UserID,
+		record.Username,
+		record.FirstName,
+		record.LastName,
+		record.Timestamp.Format(time.RFC3339),
+		record.Type,
+		record.Date,
+		recordSource(record),
+		record.Latitude,
+		record.Longitude,
+		nullableString(record.Geofence),
+		record.OvertimeMinutes,
+		record.Remote,
+	).Scan(&id)
+	if err != nil {
+		return 0, storageError("insert attendance", err)
+	}
+	return id, nil
+}
+
+// AdvanceHOTPCounter moves a user's counter forward only if it still holds the expected value.
+// It returns false when another request already advanced the counter.
+func (r *sqlRepository) AdvanceHOTPCounter(ctx context.Context, userID int64, expected, next uint64) (bool, error) {
+	defer observeQuery(ctx, "advance_hotp_counter", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "UPDATE hotp_enrollment SET counter = ? WHERE user_id = ? AND counter = ?",
+		int64(next), userID, int64(expected))
+	if err != nil {
+		return false, storageError("advance hotp counter", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected == 1, nil
+}
+
+// archivedBeforeKey is the bot_state key holding the date (exclusive) before which records may be archived
+const archivedBeforeKey = "archived_before"
+
+// attendanceColumns lists the attendance columns, in the same order in both tables
+const attendanceColumns = "id, user_id, username, first_name, last_name, timestamp, type, date, source, photo_file_id, latitude, longitude, geofence, overtime_minutes, remote"
+
+// attendanceTable returns the table expression to read records dated from startDate on: the
+// attendance table, or its union with the archive when the range reaches archived dates.
+// An empty startDate means no lower bound. PostgreSQL needs the expression aliased, as in FROM %s a.
+func (r *sqlRepository) attendanceTable(ctx context.Context, startDate string) (string, error) {
+	archivedBefore, err := r.GetBotState(ctx, archivedBeforeKey)
+	if err != nil {
+		return "", err
+	}
+
+	if archivedBefore == "" || (startDate != "" && startDate >= archivedBefore) {
+		return "attendance", nil
+	}
+	return fmt.Sprintf("(SELECT %[1]s FROM attendance UNION ALL SELECT %[1]s FROM attendance_archive)", attendanceColumns), nil
+}
+
+// GetArchivedBefore returns the date (exclusive) before which records may be archived, or "" if none are
+func (r *sqlRepository) GetArchivedBefore(ctx context.Context) (string, error) {
+	return r.GetBotState(ctx, archivedBeforeKey)
+}
+
+// archivedBeforeTx reads the archive boundary within a transaction, so an insert checked against
+// it cannot slip past an archive run starting at the same time
+func archivedBeforeTx(ctx context.Context, tx *Tx) (string, error) {
+	var value string
+	err := tx.QueryRowContext(ctx, "SELECT value FROM bot_state WHERE key = ?", archivedBeforeKey).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", storageError("get archive boundary", err)
+	}
+	return value, nil
+}
+
+// SetArchivedBefore records that records dated before the given date may be in the archive.
+// It must be set before records are moved, so reads include the archive during the move.
+func (r *sqlRepository) SetArchivedBefore(ctx context.Context, date string) error {
+	return r.SetBotState(ctx, archivedBeforeKey, date)
+}
+
+// ArchiveAttendanceBatch moves up to limit records dated before the given date into
+// attendance_archive in one transaction and returns how many were moved. The transaction
+// is rolled back unless the rows copied equal the rows deleted.
+func (r *sqlRepository) ArchiveAttendanceBatch(ctx context.Context, before string, limit int) (int, error) {
+	defer observeQuery(ctx, "archive_attendance_batch", time.Now())
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return 0, storageError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	selection := "SELECT id FROM attendance WHERE date < ? ORDER BY id LIMIT ?"
+
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(
+		"INSERT INTO attendance_archive (%[1]s) SELECT %[1]s FROM attendance WHERE id IN (%[2]s)",
+		attendanceColumns, selection,
+	), before, limit)
+	if err != nil {
+		return 0, storageError("copy attendance to archive", err)
+	}
+	copied, err := result.RowsAffected()
+	if err != nil {
+		return 0, storageError("get affected rows", err)
+	}
+
+	result, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM attendance WHERE id IN (%s)", selection), before, limit)
+	if err != nil {
+		return 0, storageError("delete archived attendance", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, storageError("get affected rows", err)
+	}
+
+	if copied != deleted {
+		return 0, fmt.Errorf("archive batch mismatch: copied %d rows but deleted %d", copied, deleted)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, storageError("commit archive batch", err)
+	}
+
+	return int(copied), nil
+}
+
+// CountAttendanceBefore counts records dated before the given date in the attendance table and the archive
+func (r *sqlRepository) CountAttendanceBefore(ctx context.Context, before string) (hot, archived int, err error) {
+	defer observeQuery(ctx, "count_attendance_before", time.Now())
+
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM attendance WHERE date < ?", before).Scan(&hot); err != nil {
+		return 0, 0, storageError("count attendance", err)
+	}
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM attendance_archive WHERE date < ?", before).Scan(&archived); err != nil {
+		return 0, 0, storageError("count archived attendance", err)
+	}
+
+	return hot, archived, nil
+}
+
+// recordSource returns the record's source, defaulting to OTP verification
+func recordSource(record *models.AttendanceRecord) string {
+	if record.Source == "" {
+		return models.SourceOTP
+	}
+	return record.Source
+}
+
+// nullableString stores an empty string as NULL
+func nullableString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// scanAttendanceRecord scans a database row into an AttendanceRecord. Columns selected after the
+// attendance columns are scanned into extra.
+func (r *sqlRepository) scanAttendanceRecord(rows *sql.Rows, extra ...any) (*models.AttendanceRecord, error) {
+	var record models.AttendanceRecord
+	var lastName, photoFileID, geofence sql.NullString
+	var latitude, longitude sql.NullFloat64
+	var timestampStr string
+
+	destinations := []any{
+		&record.ID,
+		&record.UserID,
+		&record.Username,
+		&record.FirstName,
+		&lastName,
+		&timestampStr,
+		&record.Type,
+		&record.Date,
+		&record.Source,
+		&photoFileID,
+		&latitude,
+		&longitude,
+		&geofence,
+		&record.OvertimeMinutes,
+		&record.Remote,
+	}
+	err := rows.Scan(append(destinations, extra...)...)
+	if err != nil {
+		return nil, storageError("scan attendance record", err)
+	}
+	record.PhotoFileID = photoFileID.String
+	record.Geofence = geofence.String
+	if latitude.Valid && longitude.Valid {
+		record.Latitude, record.Longitude = &latitude.Float64, &longitude.Float64
+	}
+
+	// Parse timestamp
+	timestamp, err := time.Parse(time.RFC3339, timestampStr)
+	if err != nil {
+		return nil, storageError("parse timestamp", err)
+	}
+	record.Timestamp = timestamp
+
+	// Handle nullable last name
+	if lastName.Valid {
+		record.LastName = &lastName.String
+	}
+
+	return &record, nil
+}
+
+// GetAttendanceRecord returns the record with the given ID from the attendance table or the
+// archive, or nil if there is none
+func (r *sqlRepository) GetAttendanceRecord(ctx context.Context, id int64) (*models.AttendanceRecord, error) {
+	defer observeQuery(ctx, "get_attendance_record", time.Now())
+
+	table, err := r.attendanceTable(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s a WHERE id = ?", attendanceColumns, table), id)
+	if err != nil {
+		return nil, storageError("query attendance record", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, storageError("iterate attendance record", err)
+		}
+		return nil, nil
+	}
+	return r.scanAttendanceRecord(rows)
+}
+
+// SetAttendancePhoto attaches a photo to a record, returning false if it already has one
+func (r *sqlRepository) SetAttendancePhoto(ctx context.Context, id int64, fileID string) (bool, error) {
+	defer observeQuery(ctx, "set_attendance_photo", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "UPDATE attendance SET photo_file_id = ? WHERE id = ? AND photo_file_id IS NULL", fileID, id)
+	if err != nil {
+		return false, storageError("set attendance photo", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// CheckUserAttendanceExists checks if a user has any attendance record for a specific date and type
+func (r *sqlRepository) CheckUserAttendanceExists(ctx context.Context, userID int64, date, attendanceType string) (bool, error) {
+	defer observeQuery(ctx, "check_user_attendance_exists", time.Now())
+
+	query := "SELECT EXISTS(SELECT 1 FROM attendance WHERE user_id = ? AND date = ? AND type = ?)"
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, userID, date, attendanceType).Scan(&exists)
+	if err != nil {
+		return false, storageError("check attendance existence", err)
+	}
+
+	return exists, nil
+}
+
+// GetUserLanguage returns the user's stored language, or nil if none is stored
+func (r *sqlRepository) GetUserLanguage(ctx context.Context, userID int64) (*models.UserLanguage, error) {
+	defer observeQuery(ctx, "get_user_language", time.Now())
+
+	language := models.UserLanguage{UserID: userID}
+	var updatedAt string
+	err := r.db.QueryRowContext(ctx, "SELECT language, chosen, updated_at FROM user_languages WHERE user_id = ?", userID).
+		Scan(&language.Language, &language.Chosen, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, storageError("get user language", err)
+	}
+	if language.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+		return nil, storageError("parse updated_at", err)
+	}
+
+	return &language, nil
+}
+
+// SaveUserLanguage stores the user's language, replacing any stored one
+func (r *sqlRepository) SaveUserLanguage(ctx context.Context, language *models.UserLanguage) error {
+	defer observeQuery(ctx, "save_user_language", time.Now())
+
+	query := `
+		INSERT INTO user_languages (user_id, language, chosen, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET language = excluded.language, chosen = excluded.chosen, updated_at = excluded.updated_at
+	`
+	if _, err := r.db.ExecContext(ctx, query, language.UserID, language.Language, language.Chosen, language.UpdatedAt.UTC().Format(time.RFC3339)); err != nil {
+		return storageError("save user language", err)
+	}
+
+	return nil
+}
+
+// GetUserTimezone returns the user's own timezone, or nil if they did not set one
+func (r *sqlRepository) GetUserTimezone(ctx context.Context, userID int64) (*models.UserTimezone, error) {
+	defer observeQuery(ctx, "get_user_timezone", time.Now())
+
+	timezone := models.UserTimezone{UserID: userID}
+	var updatedAt string
+	err := r.db.QueryRowContext(ctx, "SELECT timezone, updated_at FROM user_timezones WHERE user_id = ?", userID).
+		Scan(&timezone.Timezone, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, storageError("get user timezone", err)
+	}
+	if timezone.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
+		return nil, storageError("parse updated_at", err)
+	}
+
+	return &timezone, nil
+}
+
+// SaveUserTimezone stores the user's own timezone, replacing any stored one
+func (r *sqlRepository) SaveUserTimezone(ctx context.Context, timezone *models.UserTimezone) error {
+	defer observeQuery(ctx, "save_user_timezone", time.Now())
+
+	query := `
+		INSERT INTO user_timezones (user_id, timezone, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET timezone = excluded.timezone, updated_at = excluded.updated_at
+	`
+	if _, err := r.db.ExecContext(ctx, query, timezone.UserID, timezone.Timezone, timezone.UpdatedAt.UTC().Format(time.RFC3339)); err != nil {
+		return storageError("save user timezone", err)
+	}
+
+	return nil
+}
+
+// DeleteUserTimezone removes the user's own timezone, reporting whether one was set
+func (r *sqlRepository) DeleteUserTimezone(ctx context.Context, userID int64) (bool, error) {
+	defer observeQuery(ctx, "delete_user_timezone", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM user_timezones WHERE user_id = ?", userID)
+	if err != nil {
+		return false, storageError("delete user timezone", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetBotState returns a persisted bot state value, or "" if it is not set
+func (r *sqlRepository) GetBotState(ctx context.Context, key string) (string, error) {
+	defer observeQuery(ctx, "get_bot_state", time.Now())
+
+	var value string
+	err := r.db.QueryRowContext(ctx, "SELECT value FROM bot_state WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", storageError(fmt.Sprintf("get bot state %s", key), err)
+	}
+
+	return value, nil
+}
+
+// SetBotState persists a bot state value
+func (r *sqlRepository) SetBotState(ctx context.Context, key, value string) error {
+	defer observeQuery(ctx, "set_bot_state", time.Now())
+
+	query := `
+		INSERT INTO bot_state (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, key, value); err != nil {
+		return storageError(fmt.Sprintf("set bot state %s", key), err)
+	}
+
+	return nil
+}
+
+// GetMissingCheckouts returns the check-ins between startDate and endDate (inclusive) that have
+// no check-out for the same user and day, ordered by date and user
+func (r *sqlRepository) GetMissingCheckouts(ctx context.Context, startDate, endDate string) ([]models.AttendanceRecord, error) {
+	defer observeQuery(ctx, "get_missing_checkouts", time.Now())
+
+	table, err := r.attendanceTable(ctx, startDate)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT a.id, a.user_id, a.username, a.first_name, a.last_name, a.timestamp, a.type, a.date, a.source, a.photo_file_id, a.latitude, a.longitude, a.geofence, a.overtime_minutes, a.remote
+		FROM %[1]s a
+		LEFT JOIN %[1]s co
+			ON co.user_id = a.user_id AND co.date = a.date AND co.type = 'check_out'
+		WHERE a.type = 'check_in' AND a.date BETWEEN ? AND ? AND co.id IS NULL
+		ORDER BY a.date ASC, a.user_id ASC
+	`, table)
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
+	if err != nil {
+		return nil, storageError("query missing checkouts", err)
+	}
+	defer rows.Close()
+
+	var records []models.AttendanceRecord
+	for rows.Next() {
+		record, err := r.scanAttendanceRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate missing checkouts", err)
+	}
+
+	return records, nil
+}
+
+// GetAttendanceDays returns, per user and date between startDate and endDate (inclusive), the
+// earliest check-in and latest check-out, ordered by user and date. The identity columns are those
+// of one of the day's records.
+func (r *sqlRepository) GetAttendanceDays(ctx context.Context, startDate, endDate string) ([]models.AttendanceDay, error) {
+	defer observeQuery(ctx, "get_attendance_days", time.Now())
+
+	table, err := r.attendanceTable(ctx, startDate)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT user_id, date, MAX(username), MAX(first_name), MAX(last_name),
+			MIN(CASE WHEN type = 'check_in' THEN timestamp END),
+			MAX(CASE WHEN type = 'check_out' THEN timestamp END),
+			SUM(overtime_minutes)
+		FROM %s a
+		WHERE date BETWEEN ? AND ?
+		GROUP BY user_id, date
+		ORDER BY user_id ASC, date ASC
+	`, table)
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
+	if err != nil {
+		return nil, storageError("query attendance days", err)
+	}
+	defer rows.Close()
+
+	var days []models.AttendanceDay
+	for rows.Next() {
+		var day models.AttendanceDay
+		var lastName, checkIn, checkOut sql.NullString
+		var overtimeMinutes int
+		if err := rows.Scan(&day.UserID, &day.Date, &day.Username, &day.FirstName, &lastName, &checkIn, &checkOut, &overtimeMinutes); err != nil {
+			return nil, storageError("scan attendance day", err)
+		}
+		if lastName.Valid {
+			day.LastName = &lastName.String
+		}
+		if checkIn.Valid {
+			timestamp, err := time.Parse(time.RFC3339, checkIn.String)
+			if err != nil {
+				return nil, storageError("parse check-in timestamp", err)
+			}
+			day.CheckIn = &timestamp
+		}
+		if checkOut.Valid {
+			timestamp, err := time.Parse(time.RFC3339, checkOut.String)
+			if err != nil {
+				return nil, storageError("parse check-out timestamp", err)
+			}
+			day.CheckOut = &timestamp
+		}
+		day.Overtime = time.Duration(overtimeMinutes) * time.Minute
+		days = append(days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate attendance days", err)
+	}
+
+	if err := r.addBreakTimes(ctx, table, startDate, endDate, days); err != nil {
+		return nil, err
+	}
+
+	return days, nil
+}
+
+// addBreakTimes sets the time on breaks of the days read from table, a break not ended counting
+// until the day's check-out
+func (r *sqlRepository) addBreakTimes(ctx context.Context, table, startDate, endDate string, days []models.AttendanceDay) error {
+	query := fmt.Sprintf(`
+		SELECT user_id, date, type, timestamp
+		FROM %s a
+		WHERE type IN ('break_start', 'break_end') AND date BETWEEN ? AND ?
+		ORDER BY user_id ASC, date ASC, timestamp ASC, id ASC
+	`, table)
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
+	if err != nil {
+		return storageError("query breaks", err)
+	}
+	defer rows.Close()
+
+	type dayKey struct {
+		userID int64
+		date   string
+	}
+	breaks := make(map[dayKey][]*models.AttendanceRecord)
+	for rows.Next() {
+		var record models.AttendanceRecord
+		var timestamp string
+		if err := rows.Scan(&record.UserID, &record.Date, &record.Type, &timestamp); err != nil {
+			return storageError("scan break", err)
+		}
+		if record.Timestamp, err = time.Parse(time.RFC3339, timestamp); err != nil {
+			return storageError("parse break timestamp", err)
+		}
+		key := dayKey{record.UserID, record.Date}
+		breaks[key] = append(breaks[key], &record)
+	}
+	if err := rows.Err(); err != nil {
+		return storageError("iterate breaks", err)
+	}
+
+	for i := range days {
+		day := &days[i]
+		var until time.Time
+		if day.CheckOut != nil {
+			until = *day.CheckOut
+		}
+		day.Breaks = models.BreakTime(breaks[dayKey{day.UserID, day.Date}], until)
+	}
+	return nil
+}
+
+// GetOnShift returns the check-ins of date that have no check-out yet, earliest first
+func (r *sqlRepository) GetOnShift(ctx context.Context, date string) ([]models.AttendanceRecord, error) {
+	defer observeQuery(ctx, "get_on_shift", time.Now())
+
+	table, err := r.attendanceTable(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT a.id, a.user_id, a.username, a.first_name, a.last_name, a.timestamp, a.type, a.date, a.source, a.photo_file_id, a.latitude, a.longitude, a.geofence, a.overtime_minutes, a.remote
+		FROM %[1]s a
+		WHERE a.type = 'check_in' AND a.date = ?
+			AND NOT EXISTS (
+				SELECT 1 FROM %[1]s co
+				WHERE co.user_id = a.user_id AND co.date = a.date AND co.type = 'check_out'
+			)
+		ORDER BY a.timestamp ASC, a.id ASC
+	`, table)
+
+	rows, err := r.db.QueryContext(ctx, query, date)
+	if err != nil {
+		return nil, storageError("query on-shift users", err)
+	}
+	defer rows.Close()
+
+	var records []models.AttendanceRecord
+	for rows.Next() {
+		record, err := r.scanAttendanceRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate on-shift users", err)
+	}
+
+	return records, nil
+}
+
+// InsertBypassCode stores a new bypass code, revoking the user's unused codes so only the latest is valid
+func (r *sqlRepository) InsertBypassCode(ctx context.Context, code *models.BypassCode) (*models.BypassCode, error) {
+	defer observeQuery(ctx, "insert_bypass_code", time.Now())
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return nil, storageError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	issuedAt := code.IssuedAt.UTC().Format(time.RFC3339)
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE bypass_codes SET expires_at = ? WHERE user_id = ? AND used_at IS NULL AND expires_at > ?",
+		issuedAt, code.UserID, issuedAt,
+	); err != nil {
+		return nil, storageError("revoke bypass codes", err)
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO bypass_codes (user_id, code_hash, issued_by, issued_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`,
+		code.UserID,
+		code.CodeHash,
+		code.IssuedBy,
+		issuedAt,
+		code.ExpiresAt.UTC().Format(time.RFC3339),
+	).Scan(&id)
+	if err != nil {
+		return nil, storageError("insert bypass code", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, storageError("commit bypass code", err)
+	}
+
+	code.ID = id
+	return code, nil
+}
+
+// FindBypassCode returns the user's most recent bypass code with the given hash, or nil if there is none
+func (r *sqlRepository) FindBypassCode(ctx context.Context, userID int64, codeHash string) (*models.BypassCode, error) {
+	defer observeQuery(ctx, "find_bypass_code", time.Now())
+
+	query := `
+		SELECT id, user_id, code_hash, issued_by, issued_at, expires_at, used_at, attendance_id
+		FROM bypass_codes
+		WHERE user_id = ? AND code_hash = ?
+		ORDER BY id DESC
+		LIMIT 1
+	`
+
+	var code models.BypassCode
+	var issuedAt, expiresAt string
+	var usedAt sql.NullString
+	var attendanceID sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, userID, codeHash).Scan(
+		&code.ID,
+		&code.UserID,
+		&code.CodeHash,
+		&code.IssuedBy,
+		&issuedAt,
+		&expiresAt,
+		&usedAt,
+		&attendanceID,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, storageError("get bypass code", err)
+	}
+
+	if code.IssuedAt, err = time.Parse(time.RFC3339, issuedAt); err != nil {
+		return nil, storageError("parse issued_at", err)
+	}
+	if code.ExpiresAt, err = time.Parse(time.RFC3339, expiresAt); err != nil {
+		return nil, storageError("parse expires_at", err)
+	}
+	if usedAt.Valid {
+		used, err := time.Parse(time.RFC3339, usedAt.String)
+		if err != nil {
+			return nil, storageError("parse used_at", err)
+		}
+		code.UsedAt = &used
+	}
+	if attendanceID.Valid {
+		code.AttendanceID = &attendanceID.Int64
+	}
+
+	return &code, nil
+}
+
+// RedeemBypassCode marks a bypass code used and inserts the attendance it verifies in one transaction.
+// It returns ErrNotFound if the code was used or expired in the meantime; the record is then not saved.
+func (r *sqlRepository) RedeemBypassCode(ctx context.Context, codeID int64, record *models.AttendanceRecord, now time.Time) (*models.AttendanceRecord, error) {
+	defer observeQuery(ctx, "redeem_bypass_code", time.Now())
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return nil, storageError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	usedAt := now.UTC().Format(time.RFC3339)
+	result, err := tx.ExecContext(ctx,
+		"UPDATE bypass_codes SET used_at = ? WHERE id = ? AND used_at IS NULL AND expires_at > ?",
+		usedAt, codeID, usedAt,
+	)
+	if err != nil {
+		return nil, storageError("use bypass code", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, storageError("get affected rows", err)
+	}
+	if affected == 0 {
+		return nil, fmt.Errorf("bypass code %d: %w", codeID, ErrNotFound)
+	}
+
+	id, err := insertAttendanceTx(ctx, tx, record)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE bypass_codes SET attendance_id = ? WHERE id = ?", id, codeID); err != nil {
+		return nil, storageError("link bypass code", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, storageError("commit bypass redemption", err)
+	}
+
+	record.ID = id
+	return record, nil
+}
+
+// AddSubscription subscribes a chat to a digest, returning false if it was already subscribed
+func (r *sqlRepository) AddSubscription(ctx context.Context, subscription *models.Subscription) (bool, error) {
+	defer observeQuery(ctx, "add_subscription", time.Now())
+
+	query := `
+		INSERT INTO subscriptions (chat_id, user_id, digest, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(chat_id, digest) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, subscription.ChatID, subscription.UserID, subscription.Digest, subscription.CreatedAt)
+	if err != nil {
+		return false, storageError("add subscription", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// RemoveSubscription unsubscribes a chat from a digest, returning false if it was not subscribed
+func (r *sqlRepository) RemoveSubscription(ctx context.Context, chatID int64, digest string) (bool, error) {
+	defer observeQuery(ctx, "remove_subscription", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM subscriptions WHERE chat_id = ? AND digest = ?", chatID, digest)
+	if err != nil {
+		return false, storageError("remove subscription", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetSubscriptions returns the subscriptions to a digest, oldest first
+func (r *sqlRepository) GetSubscriptions(ctx context.Context, digest string) ([]models.Subscription, error) {
+	defer observeQuery(ctx, "get_subscriptions", time.Now())
+
+	return r.querySubscriptions(ctx, "SELECT chat_id, user_id, digest, created_at FROM subscriptions WHERE digest = ? ORDER BY created_at", digest)
+}
+
+// GetChatSubscriptions returns the digests a chat is subscribed to
+func (r *sqlRepository) GetChatSubscriptions(ctx context.Context, chatID int64) ([]models.Subscription, error) {
+	defer observeQuery(ctx, "get_chat_subscriptions", time.Now())
+
+	return r.querySubscriptions(ctx, "SELECT chat_id, user_id, digest, created_at FROM subscriptions WHERE chat_id = ? ORDER BY digest", chatID)
+}
+
+// querySubscriptions runs a subscriptions query and scans the rows
+func (r *sqlRepository) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]models.Subscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, storageError("query subscriptions", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []models.Subscription
+	for rows.Next() {
+		var subscription models.Subscription
+		if err := rows.Scan(&subscription.ChatID, &subscription.UserID, &subscription.Digest, &subscription.CreatedAt); err != nil {
+			return nil, storageError("scan subscription", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate subscriptions", err)
+	}
+
+	return subscriptions, nil
+}
+
+// AddOfficeGroup registers a group chat as an office channel, returning false if it already was
+func (r *sqlRepository) AddOfficeGroup(ctx context.Context, group *models.OfficeGroup) (bool, error) {
+	defer observeQuery(ctx, "add_office_group", time.Now())
+
+	query := `
+		INSERT INTO office_groups (chat_id, title, registered_by, registered_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(chat_id) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, group.ChatID, group.Title, group.RegisteredBy, group.RegisteredAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return false, storageError("add office group", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// RemoveOfficeGroup unregisters a group chat, returning false if it was not registered
+func (r *sqlRepository) RemoveOfficeGroup(ctx context.Context, chatID int64) (bool, error) {
+	defer observeQuery(ctx, "remove_office_group", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM office_groups WHERE chat_id = ?", chatID)
+	if err != nil {
+		return false, storageError("remove office group", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetOfficeGroup returns the registered group chat, or nil if the chat is not registered
+func (r *sqlRepository) GetOfficeGroup(ctx context.Context, chatID int64) (*models.OfficeGroup, error) {
+	defer observeQuery(ctx, "get_office_group", time.Now())
+
+	groups, err := r.queryOfficeGroups(ctx, "SELECT chat_id, title, registered_by, registered_at FROM office_groups WHERE chat_id = ?", chatID)
+	if err != nil || len(groups) == 0 {
+		return nil, err
+	}
+	return &groups[0], nil
+}
+
+// GetOfficeGroups returns the registered group chats, oldest registration first
+func (r *sqlRepository) GetOfficeGroups(ctx context.Context) ([]models.OfficeGroup, error) {
+	defer observeQuery(ctx, "get_office_groups", time.Now())
+
+	return r.queryOfficeGroups(ctx, "SELECT chat_id, title, registered_by, registered_at FROM office_groups ORDER BY registered_at, chat_id")
+}
+
+// queryOfficeGroups runs an office_groups query and scans the rows
+func (r *sqlRepository) queryOfficeGroups(ctx context.Context, query string, args ...interface{}) ([]models.OfficeGroup, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, storageError("query office groups", err)
+	}
+	defer rows.Close()
+
+	var groups []models.OfficeGroup
+	for rows.Next() {
+		var group models.OfficeGroup
+		var registeredAt string
+		if err := rows.Scan(&group.ChatID, &group.Title, &group.RegisteredBy, &registeredAt); err != nil {
+			return nil, storageError("scan office group", err)
+		}
+		if group.RegisteredAt, err = time.Parse(time.RFC3339, registeredAt); err != nil {
+			return nil, storageError("parse registered_at", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate office groups", err)
+	}
+
+	return groups, nil
+}
+
+// SaveRegisteredEmployee adds the user to the employee directory or updates their entry, keeping
+// who registered them first and when
+func (r *sqlRepository) SaveRegisteredEmployee(ctx context.Context, employee *models.RegisteredEmployee) error {
+	defer observeQuery(ctx, "save_registered_employee", time.Now())
+
+	query := `
+		INSERT INTO employees (user_id, employee_id, department, registered_by, registered_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET employee_id = excluded.employee_id, department = excluded.department
+	`
+	_, err := r.db.ExecContext(ctx, query, employee.UserID, employee.EmployeeID, employee.Department,
+		employee.RegisteredBy, employee.RegisteredAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return storageError("save registered employee", err)
+	}
+
+	return nil
+}
+
+// DeleteRegisteredEmployee removes the user from the employee directory, returning false if they
+// were not in it
+func (r *sqlRepository) DeleteRegisteredEmployee(ctx context.Context, userID int64) (bool, error) {
+	defer observeQuery(ctx, "delete_registered_employee", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM employees WHERE user_id = ?", userID)
+	if err != nil {
+		return false, storageError("delete registered employee", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetRegisteredEmployee returns the user's directory entry, or nil if they are not registered
+func (r *sqlRepository) GetRegisteredEmployee(ctx context.Context, userID int64) (*models.RegisteredEmployee, error) {
+	defer observeQuery(ctx, "get_registered_employee", time.Now())
+
+	employees, err := r.queryRegisteredEmployees(ctx, "WHERE user_id = ?", userID)
+	if err != nil || len(employees) == 0 {
+		return nil, err
+	}
+	return &employees[0], nil
+}
+
+// FindRegisteredEmployee returns the directory entry with the employee ID, or nil if there is none
+func (r *sqlRepository) FindRegisteredEmployee(ctx context.Context, employeeID string) (*models.RegisteredEmployee, error) {
+	defer observeQuery(ctx, "find_registered_employee", time.Now())
+
+	employees, err := r.queryRegisteredEmployees(ctx, "WHERE employee_id = ?", employeeID)
+	if err != nil || len(employees) == 0 {
+		return nil, err
+	}
+	return &employees[0], nil
+}
+
+// GetRegisteredEmployees returns the employee directory ordered by department and employee ID
+func (r *sqlRepository) GetRegisteredEmployees(ctx context.Context) ([]models.RegisteredEmployee, error) {
+	defer observeQuery(ctx, "get_registered_employees", time.Now())
+
+	return r.queryRegisteredEmployees(ctx, "")
+}
+
+// queryRegisteredEmployees selects the directory entries matching the where clause, ordered by
+// department and employee ID
+func (r *sqlRepository) queryRegisteredEmployees(ctx context.Context, where string, args ...interface{}) ([]models.RegisteredEmployee, error) {
+	query := fmt.Sprintf(`
+		SELECT user_id, employee_id, department, registered_by, registered_at
+		FROM employees
+		%s
+		ORDER BY department, employee_id
+	`, where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, storageError("query registered employees", err)
+	}
+	defer rows.Close()
+
+	var employees []models.RegisteredEmployee
+	for rows.Next() {
+		var employee models.RegisteredEmployee
+		var registeredAt string
+		if err := rows.Scan(&employee.UserID, &employee.EmployeeID, &employee.Department,
+			&employee.RegisteredBy, &registeredAt); err != nil {
+			return nil, storageError("scan registered employee", err)
+		}
+		if employee.RegisteredAt, err = time.Parse(time.RFC3339, registeredAt); err != nil {
+			return nil, storageError("parse registered_at", err)
+		}
+		employees = append(employees, employee)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate registered employees", err)
+	}
+
+	return employees, nil
+}
+
+// SaveGeofence creates or replaces a geofence
+func (r *sqlRepository) SaveGeofence(ctx context.Context, geofence *models.Geofence) error {
+	defer observeQuery(ctx, "save_geofence", time.Now())
+
+	query := `
+		INSERT INTO geofences (name, latitude, longitude, radius_m) VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET latitude = excluded.latitude, longitude = excluded.longitude, radius_m = excluded.radius_m
+	`
+	if _, err := r.db.ExecContext(ctx, query, geofence.Name, geofence.Latitude, geofence.Longitude, geofence.Radius); err != nil {
+		return storageError("save geofence", err)
+	}
+
+	return nil
+}
+
+// DeleteGeofence deletes a geofence, returning false if it did not exist
+func (r *sqlRepository) DeleteGeofence(ctx context.Context, name string) (bool, error) {
+	defer observeQuery(ctx, "delete_geofence", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM geofences WHERE name = ?", name)
+	if err != nil {
+		return false, storageError("delete geofence", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetGeofences returns the geofences ordered by name
+func (r *sqlRepository) GetGeofences(ctx context.Context) ([]models.Geofence, error) {
+	defer observeQuery(ctx, "get_geofences", time.Now())
+
+	rows, err := r.db.QueryContext(ctx, "SELECT name, latitude, longitude, radius_m FROM geofences ORDER BY name")
+	if err != nil {
+		return nil, storageError("query geofences", err)
+	}
+	defer rows.Close()
+
+	var geofences []models.Geofence
+	for rows.Next() {
+		var geofence models.Geofence
+		if err := rows.Scan(&geofence.Name, &geofence.Latitude, &geofence.Longitude, &geofence.Radius); err != nil {
+			return nil, storageError("scan geofence", err)
+		}
+		geofences = append(geofences, geofence)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate geofences", err)
+	}
+
+	return geofences, nil
+}
+
+// SaveReminder enables reminders for the user, replacing their previous lead time
+func (r *sqlRepository) SaveReminder(ctx context.Context, reminder *models.ReminderPreference) error {
+	defer observeQuery(ctx, "save_reminder", time.Now())
+
+	query := `
+		INSERT INTO reminders (user_id, lead_minutes, created_at) VALUES (?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET lead_minutes = excluded.lead_minutes
+	`
+	if _, err := r.db.ExecContext(ctx, query, reminder.UserID, reminder.LeadMinutes, reminder.CreatedAt.UTC().Format(time.RFC3339)); err != nil {
+		return storageError("save reminder", err)
+	}
+
+	return nil
+}
+
+// DeleteReminder disables reminders for the user, returning false if they were not enabled
+func (r *sqlRepository) DeleteReminder(ctx context.Context, userID int64) (bool, error) {
+	defer observeQuery(ctx, "delete_reminder", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM reminders WHERE user_id = ?", userID)
+	if err != nil {
+		return false, storageError("delete reminder", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetReminder returns the user's reminder preference, or nil if reminders are not enabled
+func (r *sqlRepository) GetReminder(ctx context.Context, userID int64) (*models.ReminderPreference, error) {
+	defer observeQuery(ctx, "get_reminder", time.Now())
+
+	reminders, err := r.queryReminders(ctx, "SELECT user_id, lead_minutes, created_at FROM reminders WHERE user_id = ?", userID)
+	if err != nil || len(reminders) == 0 {
+		return nil, err
+	}
+	return &reminders[0], nil
+}
+
+// GetReminders returns the reminder preferences of all users who enabled reminders
+func (r *sqlRepository) GetReminders(ctx context.Context) ([]models.ReminderPreference, error) {
+	defer observeQuery(ctx, "get_reminders", time.Now())
+
+	return r.queryReminders(ctx, "SELECT user_id, lead_minutes, created_at FROM reminders ORDER BY user_id")
+}
+
+// SaveWeeklySummaryOptOut stops the weekly summary for the user, keeping an earlier opt-out
+func (r *sqlRepository) SaveWeeklySummaryOptOut(ctx context.Context, userID int64, at time.Time) error {
+	defer observeQuery(ctx, "save_weekly_summary_optout", time.Now())
+
+	query := `
+		INSERT INTO weekly_summary_optouts (user_id, opted_out_at) VALUES (?, ?)
+		ON CONFLICT(user_id) DO NOTHING
+	`
+	if _, err := r.db.ExecContext(ctx, query, userID, at.UTC().Format(time.RFC3339)); err != nil {
+		return storageError("save weekly summary opt-out", err)
+	}
+
+	return nil
+}
+
+// DeleteWeeklySummaryOptOut resumes the weekly summary for the user, returning false if they had
+// not opted out
+func (r *sqlRepository) DeleteWeeklySummaryOptOut(ctx context.Context, userID int64) (bool, error) {
+	defer observeQuery(ctx, "delete_weekly_summary_optout", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM weekly_summary_optouts WHERE user_id = ?", userID)
+	if err != nil {
+		return false, storageError("delete weekly summary opt-out", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetWeeklySummaryOptOuts returns the users who opted out of the weekly summary
+func (r *sqlRepository) GetWeeklySummaryOptOuts(ctx context.Context) (map[int64]bool, error) {
+	defer observeQuery(ctx, "get_weekly_summary_optouts", time.Now())
+
+	rows, err := r.db.QueryContext(ctx, "SELECT user_id FROM weekly_summary_optouts")
+	if err != nil {
+		return nil, storageError("query weekly summary opt-outs", err)
+	}
+	defer rows.Close()
+
+	optOuts := make(map[int64]bool)
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, storageError("scan weekly summary opt-out", err)
+		}
+		optOuts[userID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate weekly summary opt-outs", err)
+	}
+
+	return optOuts, nil
+}
+
+// queryReminders runs a reminders query and scans the rows
+func (r *sqlRepository) queryReminders(ctx context.Context, query string, args ...interface{}) ([]models.ReminderPreference, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, storageError("query reminders", err)
+	}
+	defer rows.Close()
+
+	var reminders []models.ReminderPreference
+	for rows.Next() {
+		var reminder models.ReminderPreference
+		var createdAt string
+		if err := rows.Scan(&reminder.UserID, &reminder.LeadMinutes, &createdAt); err != nil {
+			return nil, storageError("scan reminder", err)
+		}
+		if reminder.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+			return nil, storageError("parse created_at", err)
+		}
+		reminders = append(reminders, reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate reminders", err)
+	}
+
+	return reminders, nil
+}
+
+// slowQueryThreshold is how long a repository query may take before it is logged as slow
+const slowQueryThreshold = 500 * time.Millisecond
+
+// observeQuery records the duration of a repository query and logs it with the logger of ctx, so
+// the query carries the request ID of the update, job or API request that ran it
+func observeQuery(ctx context.Context, name string, start time.Time) {
+	elapsed := time.Since(start)
+	metrics.DBQueryDuration.Observe(elapsed.Seconds(), name)
+
+	logger := logging.FromContext(ctx)
+	if elapsed >= slowQueryThreshold {
+		logger.Warn("Slow query", "query", name, "duration_ms", elapsed.Milliseconds())
+	} else if logger.Enabled(ctx, slog.LevelDebug) {
+		logger.Debug("Query", "query", name, "duration_ms", elapsed.Milliseconds())
+	}
+}
+
+// CorrectAttendance applies an admin's correction and records it in the audit log in one
+// transaction. With before nil, after is inserted; with after nil, before is deleted; otherwise
+// before takes the timestamp and source of after. It returns ErrNotFound if before no longer exists.
+func (r *sqlRepository) CorrectAttendance(ctx context.Context, before, after *models.AttendanceRecord, entry *models.AuditEntry) (*models.AuditEntry, error) {
+	defer observeQuery(ctx, "correct_attendance", time.Now())
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return nil, storageError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	switch {
+	case before == nil:
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO attendance (user_id, username, first_name, last_name, timestamp, type, date, source, latitude, longitude, geofence, overtime_minutes, remote)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			RETURNING id
+		`,
+			after.UserID,
+			after.Username,
+			after.FirstName,
+			after.LastName,
+			after.Timestamp.Format(time.RFC3339),
+			after.Type,
+			after.Date,
+			recordSource(after),
+			after.Latitude,
+			after.Longitude,
+			nullableString(after.Geofence),
+			after.OvertimeMinutes,
+			after.Remote,
+		).Scan(&after.ID)
+		if err != nil {
+			return nil, storageError("insert attendance", err)
+		}
+		entry.RecordID = after.ID
+	default:
+		var result sql.Result
+		if after == nil {
+			result, err = tx.ExecContext(ctx, "DELETE FROM attendance WHERE id = ?", before.ID)
+		} else {
+			result, err = tx.ExecContext(ctx, "UPDATE attendance SET timestamp = ?, source = ?, overtime_minutes = ? WHERE id = ?",
+				after.Timestamp.Format(time.RFC3339), recordSource(after), after.OvertimeMinutes, before.ID)
+		}
+		if err != nil {
+			return nil, storageError("correct attendance", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, storageError("get affected rows", err)
+		}
+		if affected == 0 {
+			return nil, fmt.Errorf("attendance record %d: %w", before.ID, ErrNotFound)
+		}
+		entry.RecordID = before.ID
+	}
+
+	if err := insertAuditEntry(ctx, tx, entry); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, storageError("commit transaction", err)
+	}
+
+	return entry, nil
+}
+
+// InsertAuditEntry appends an entry to the audit log
+func (r *sqlRepository) InsertAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	defer observeQuery(ctx, "insert_audit_entry", time.Now())
+
+	return insertAuditEntry(ctx, r.db, entry)
+}
+
+// InsertWebhookFailure records an event webhook delivery that failed for good
+func (r *sqlRepository) InsertWebhookFailure(ctx context.Context, failure *models.WebhookFailure) error {
+	defer observeQuery(ctx, "insert_webhook_failure", time.Now())
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO webhook_failures (created_at, url, event, event_id, payload, attempts, last_error)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`,
+		failure.CreatedAt.UTC().Format(time.RFC3339),
+		failure.URL,
+		failure.Event,
+		failure.EventID,
+		failure.Payload,
+		failure.Attempts,
+		failure.LastError,
+	).Scan(&failure.ID)
+	if err != nil {
+		return storageError("insert webhook failure", err)
+	}
+	return nil
+}
+
+// rowQuerier runs a query returning at most one row, on a DB or within a Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// insertAuditEntry appends an entry to the audit log through q
+func insertAuditEntry(ctx context.Context, q rowQuerier, entry *models.AuditEntry) error {
+	err := q.QueryRowContext(ctx, `
+		INSERT INTO audit_log (created_at, actor_id, action, target_user_id, record_id, details, reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`,
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		entry.ActorID,
+		entry.Action,
+		sql.NullInt64{Int64: entry.TargetUserID, Valid: entry.TargetUserID != 0},
+		sql.NullInt64{Int64: entry.RecordID, Valid: entry.RecordID != 0},
+		entry.Details,
+		entry.Reason,
+	).Scan(&entry.ID)
+	if err != nil {
+		return storageError("insert audit entry", err)
+	}
+	return nil
+}
+
+// GetAuditLog returns the newest audit log entries, at most limit, concerning the user or, with
+// userID 0, anyone
+func (r *sqlRepository) GetAuditLog(ctx context.Context, userID int64, limit int) ([]models.AuditEntry, error) {
+	defer observeQuery(ctx, "get_audit_log", time.Now())
+
+	query := `
+		SELECT id, created_at, actor_id, action, target_user_id, record_id, details, reason
+		FROM audit_log
+	`
+	var args []any
+	if userID != 0 {
+		query += " WHERE target_user_id = ?"
+		args = append(args, userID)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	return r.queryAuditLog(ctx, query, args...)
+}
+
+// GetAuditLogRange returns the audit log entries recorded from since up to until, oldest first
+func (r *sqlRepository) GetAuditLogRange(ctx context.Context, since, until time.Time) ([]models.AuditEntry, error) {
+	defer observeQuery(ctx, "get_audit_log_range", time.Now())
+
+	query := `
+		SELECT id, created_at, actor_id, action, target_user_id, record_id, details, reason
+		FROM audit_log
+		WHERE created_at >= ? AND created_at < ?
+		ORDER BY id ASC
+	`
+	return r.queryAuditLog(ctx, query, since.UTC().Format(time.RFC3339), until.UTC().Format(time.RFC3339))
+}
+
+// queryAuditLog runs a query selecting audit log entries
+func (r *sqlRepository) queryAuditLog(ctx context.Context, query string, args ...any) ([]models.AuditEntry, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, storageError("query audit log", err)
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var entry models.AuditEntry
+		var createdAtStr string
+		var targetUserID, recordID sql.NullInt64
+		if err := rows.Scan(&entry.ID, &createdAtStr, &entry.ActorID, &entry.Action, &targetUserID, &recordID, &entry.Details, &entry.Reason); err != nil {
+			return nil, storageError("scan audit entry", err)
+		}
+
+		createdAt, err := time.Parse(time.RFC3339, createdAtStr)
+		if err != nil {
+			return nil, storageError("parse created_at", err)
+		}
+		entry.CreatedAt = createdAt
+		entry.TargetUserID = targetUserID.Int64
+		entry.RecordID = recordID.Int64
+
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate audit log", err)
+	}
+
+	return entries, nil
+}
+
+// SaveSession starts or replaces the user's conversation session
+func (r *sqlRepository) SaveSession(ctx context.Context, session *models.Session) error {
+	defer observeQuery(ctx, "save_session", time.Now())
+
+	query := `
+		INSERT INTO sessions (user_id, state, payload, expires_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET state = excluded.state, payload = excluded.payload, expires_at = excluded.expires_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, session.UserID, session.State, session.Payload, session.ExpiresAt.UTC().Format(time.RFC3339)); err != nil {
+		return storageError("save session", err)
+	}
+
+	return nil
+}
+
+// GetSession returns the user's session, or nil if there is none or it expired before now
+func (r *sqlRepository) GetSession(ctx context.Context, userID int64, now time.Time) (*models.Session, error) {
+	defer observeQuery(ctx, "get_session", time.Now())
+
+	query := "SELECT user_id, state, payload, expires_at FROM sessions WHERE user_id = ? AND expires_at > ?"
+
+	var session models.Session
+	var expiresAt string
+	err := r.db.QueryRowContext(ctx, query, userID, now.UTC().Format(time.RFC3339)).
+		Scan(&session.UserID, &session.State, &session.Payload, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, storageError("get session", err)
+	}
+	if session.ExpiresAt, err = time.Parse(time.RFC3339, expiresAt); err != nil {
+		return nil, storageError("parse expires_at", err)
+	}
+
+	return &session, nil
+}
+
+// DeleteSession ends the user's session, if any
+func (r *sqlRepository) DeleteSession(ctx context.Context, userID int64) error {
+	defer observeQuery(ctx, "delete_session", time.Now())
+
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM sessions WHERE user_id = ?", userID); err != nil {
+		return storageError("delete session", err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredSessions removes the sessions that expired before now, returning how many
+func (r *sqlRepository) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	defer observeQuery(ctx, "delete_expired_sessions", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM sessions WHERE expires_at <= ?", now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, storageError("delete expired sessions", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, storageError("get affected rows", err)
+	}
+
+	return affected, nil
+}
+
+// SaveHoliday adds a holiday or renames the one on its date
+func (r *sqlRepository) SaveHoliday(ctx context.Context, holiday *models.Holiday) error {
+	defer observeQuery(ctx, "save_holiday", time.Now())
+
+	query := `
+		INSERT INTO holidays (date, name, source, added_by, added_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(date) DO UPDATE SET name = excluded.name, source = excluded.source,
+			added_by = excluded.added_by, added_at = excluded.added_at
+	`
+	_, err := r.db.ExecContext(ctx, query, holiday.Date, holiday.Name, holiday.Source,
+		holiday.AddedBy, holiday.AddedAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return storageError("save holiday", err)
+	}
+
+	return nil
+}
+
+// InsertHolidays adds holidays in a single transaction, skipping those on dates that already have
+// one, and returns how many were added
+func (r *sqlRepository) InsertHolidays(ctx context.Context, holidays []models.Holiday) (int, error) {
+	defer observeQuery(ctx, "insert_holidays", time.Now())
+
+	tx, err := r.db.BeginTx(ctx)
+	if err != nil {
+		return 0, storageError("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO holidays (date, name, source, added_by, added_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(date) DO NOTHING
+	`)
+	if err != nil {
+		return 0, storageError("prepare holiday insert", err)
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	for _, holiday := range holidays {
+		result, err := stmt.ExecContext(ctx, holiday.Date, holiday.Name, holiday.Source,
+			holiday.AddedBy, holiday.AddedAt.UTC().Format(time.RFC3339))
+		if err != nil {
+			return 0, storageError("insert holiday "+holiday.Date, err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, storageError("get affected rows", err)
+		}
+		inserted += int(affected)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, storageError("commit holiday insert", err)
+	}
+
+	return inserted, nil
+}
+
+// DeleteHoliday removes the holiday on date, returning false if there was none
+func (r *sqlRepository) DeleteHoliday(ctx context.Context, date string) (bool, error) {
+	defer observeQuery(ctx, "delete_holiday", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM holidays WHERE date = ?", date)
+	if err != nil {
+		return false, storageError("delete holiday", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// GetHolidays returns the holidays from startDate to endDate (inclusive), ordered by date
+func (r *sqlRepository) GetHolidays(ctx context.Context, startDate, endDate string) ([]models.Holiday, error) {
+	defer observeQuery(ctx, "get_holidays", time.Now())
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT date, name, source, added_by, added_at
+		FROM holidays
+		WHERE date BETWEEN ? AND ?
+		ORDER BY date
+	`, startDate, endDate)
+	if err != nil {
+		return nil, storageError("query holidays", err)
+	}
+	defer rows.Close()
+
+	var holidays []models.Holiday
+	for rows.Next() {
+		var holiday models.Holiday
+		var addedAt string
+		if err := rows.Scan(&holiday.Date, &holiday.Name, &holiday.Source, &holiday.AddedBy, &addedAt); err != nil {
+			return nil, storageError("scan holiday", err)
+		}
+		if holiday.AddedAt, err = time.Parse(time.RFC3339, addedAt); err != nil {
+			return nil, storageError("parse added_at", err)
+		}
+		holidays = append(holidays, holiday)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate holidays", err)
+	}
+
+	return holidays, nil
+}
+
+// InsertWFHRequest stores a new request to work from home. A second request of the user for the
+// same date fails with ErrDuplicate.
+func (r *sqlRepository) InsertWFHRequest(ctx context.Context, request *models.WFHRequest) (*models.WFHRequest, error) {
+	defer observeQuery(ctx, "insert_wfh_request", time.Now())
+
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO wfh_requests (user_id, username, first_name, last_name, date, status, requested_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`,
+		request.UserID,
+		request.Username,
+		request.FirstName,
+		request.LastName,
+		request.Date,
+		request.Status,
+		request.RequestedAt.UTC().Format(time.RFC3339),
+	).Scan(&id)
+	if err != nil {
+		return nil, storageError("insert wfh request", err)
+	}
+
+	inserted := *request
+	inserted.ID = id
+	return &inserted, nil
+}
+
+// GetWFHRequest returns a request to work from home by ID, or nil if it does not exist
+func (r *sqlRepository) GetWFHRequest(ctx context.Context, id int64) (*models.WFHRequest, error) {
+	defer observeQuery(ctx, "get_wfh_request", time.Now())
+
+	requests, err := r.queryWFHRequests(ctx, "WHERE id = ?", id)
+	if err != nil || len(requests) == 0 {
+		return nil, err
+	}
+	return &requests[0], nil
+}
+
+// GetUserWFHRequest returns the user's request to work from home on date, or nil if there is none
+func (r *sqlRepository) GetUserWFHRequest(ctx context.Context, userID int64, date string) (*models.WFHRequest, error) {
+	defer observeQuery(ctx, "get_user_wfh_request", time.Now())
+
+	requests, err := r.queryWFHRequests(ctx, "WHERE user_id = ? AND date = ?", userID, date)
+	if err != nil || len(requests) == 0 {
+		return nil, err
+	}
+	return &requests[0], nil
+}
+
+// GetUserWFHRequests returns the user's requests to work from home on or after fromDate
+func (r *sqlRepository) GetUserWFHRequests(ctx context.Context, userID int64, fromDate string) ([]models.WFHRequest, error) {
+	defer observeQuery(ctx, "get_user_wfh_requests", time.Now())
+	return r.queryWFHRequests(ctx, "WHERE user_id = ? AND date >= ?", userID, fromDate)
+}
+
+// DecideWFHRequest approves or rejects a pending request to work from home, returning false if it
+// was not pending
+func (r *sqlRepository) DecideWFHRequest(ctx context.Context, id int64, status string, decidedBy int64, decidedAt time.Time) (bool, error) {
+	defer observeQuery(ctx, "decide_wfh_request", time.Now())
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE wfh_requests SET status = ?, decided_by = ?, decided_at = ?
+		WHERE id = ? AND status = 'pending'
+	`, status, decidedBy, decidedAt.UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return false, storageError("decide wfh request", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// DeleteWFHRequest removes the user's request to work from home on date, returning false if there
+// was none
+func (r *sqlRepository) DeleteWFHRequest(ctx context.Context, userID int64, date string) (bool, error) {
+	defer observeQuery(ctx, "delete_wfh_request", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM wfh_requests WHERE user_id = ? AND date = ?", userID, date)
+	if err != nil {
+		return false, storageError("delete wfh request", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, storageError("get affected rows", err)
+	}
+
+	return affected > 0, nil
+}
+
+// SetAttendanceRemote marks the user's attendance records of date as remote or not, returning how
+// many were changed
+func (r *sqlRepository) SetAttendanceRemote(ctx context.Context, userID int64, date string, remote bool) (int64, error) {
+	defer observeQuery(ctx, "set_attendance_remote", time.Now())
+
+	result, err := r.db.ExecContext(ctx, "UPDATE attendance SET remote = ? WHERE user_id = ? AND date = ? AND remote <> ?",
+		remote, userID, date, remote)
+	if err != nil {
+		return 0, storageError("update attendance remote", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, storageError("get affected rows", err)
+	}
+
+	return affected, nil
+}
+
+// queryWFHRequests selects requests to work from home matching the where clause, ordered by date
+func (r *sqlRepository) queryWFHRequests(ctx context.Context, where string, args ...any) ([]models.WFHRequest, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, username, first_name, last_name, date, status, requested_at, decided_by, decided_at
+		FROM wfh_requests
+		%s
+		ORDER BY date, id
+	`, where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, storageError("query wfh requests", err)
+	}
+	defer rows.Close()
+
+	var requests []models.WFHRequest
+	for rows.Next() {
+		var request models.WFHRequest
+		var lastName, decidedAt sql.NullString
+		var decidedBy sql.NullInt64
+		var requestedAt string
+		if err := rows.Scan(&request.ID, &request.UserID, &request.Username, &request.FirstName, &lastName,
+			&request.Date, &request.Status, &requestedAt, &decidedBy, &decidedAt); err != nil {
+			return nil, storageError("scan wfh request", err)
+		}
+		if lastName.Valid {
+			request.LastName = &lastName.String
+		}
+		if request.RequestedAt, err = time.Parse(time.RFC3339, requestedAt); err != nil {
+			return nil, storageError("parse requested_at", err)
+		}
+		request.DecidedBy = decidedBy.Int64
+		if decidedAt.Valid {
+			decided, err := time.Parse(time.RFC3339, decidedAt.String)
+			if err != nil {
+				return nil, storageError("parse decided_at", err)
+			}
+			request.DecidedAt = &decided
+		}
+		requests = append(requests, request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, storageError("iterate wfh requests", err)
+	}
+
+	return requests, nil
 }
@@ -0,0 +1,246 @@
+package database
+
+import (
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestRepository opens a fresh, migrated SQLite database in a temporary directory
+func newTestRepository(t *testing.T) Repository {
+	t.Helper()
+
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "attendance.db"))
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(db)
+}
+
+// TestGetMissingCheckouts seeds complete and incomplete days inside and just outside
+// 2024-03-01..2024-03-10: only the incomplete days within the range, edges included, are returned
+func TestGetMissingCheckouts(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	record := func(userID int64, date string, hour int, recordType string) models.AttendanceRecord {
+		day, err := utils.ParseDate(date)
+		if err != nil {
+			t.Fatalf("ParseDate(%q): %v", date, err)
+		}
+		return models.AttendanceRecord{
+			UserID: userID, FirstName: fmt.Sprintf("User%d", userID), Type: recordType, Date: date,
+			Timestamp: day.Add(time.Duration(hour) * time.Hour),
+		}
+	}
+	if _, _, err := repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{
+		record(1, "2024-02-29", 8, "check_in"), // Day before the range
+		record(1, "2024-03-01", 8, "check_in"), // First day of the range
+		record(2, "2024-03-01", 8, "check_in"),
+		record(2, "2024-03-01", 17, "check_out"),
+		record(3, "2024-03-04", 17, "check_out"), // Check-out only
+		record(4, "2024-03-05", 8, "check_in"),
+		record(4, "2024-03-05", 12, "break_start"), // Breaks are not check-outs
+		record(4, "2024-03-05", 13, "break_end"),
+		record(3, "2024-03-05", 9, "check_in"),
+		record(3, "2024-03-06", 2, "check_out"), // Check-out of the next day
+		record(2, "2024-03-10", 8, "check_in"),  // Last day of the range
+		record(1, "2024-03-10", 8, "check_in"),
+		record(1, "2024-03-10", 18, "check_out"),
+		record(1, "2024-03-11", 8, "check_in"), // Day after the range
+	}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+
+	records, err := repo.GetMissingCheckouts(ctx, "2024-03-01", "2024-03-10")
+	if err != nil {
+		t.Fatalf("GetMissingCheckouts: %v", err)
+	}
+
+	want := []struct {
+		userID int64
+		date   string
+	}{
+		{1, "2024-03-01"},
+		{3, "2024-03-05"},
+		{4, "2024-03-05"},
+		{2, "2024-03-10"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("records = %d, want %d: %+v", len(records), len(want), records)
+	}
+	for i, w := range want {
+		if records[i].UserID != w.userID || records[i].Date != w.date || records[i].Type != "check_in" {
+			t.Errorf("record %d = user %d %s on %s, want user %d check_in on %s",
+				i+1, records[i].UserID, records[i].Type, records[i].Date, w.userID, w.date)
+		}
+	}
+
+	single, err := repo.GetMissingCheckouts(ctx, "2024-03-10", "2024-03-10")
+	if err != nil {
+		t.Fatalf("GetMissingCheckouts: %v", err)
+	}
+	if len(single) != 1 || single[0].UserID != 2 {
+		t.Errorf("single day = %+v, want user 2 only", single)
+	}
+
+	none, err := repo.GetMissingCheckouts(ctx, "2024-03-06", "2024-03-09")
+	if err != nil {
+		t.Fatalf("GetMissingCheckouts: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("range without check-ins = %+v, want none", none)
+	}
+}
+
+// seedNames gives users 1 and 2 the alias "Budi Santoso" spelled differently, user 3 the Telegram
+// name "BUDI SANTOSO" and user 4 an unrelated name
+func seedNames(t *testing.T, repo Repository) {
+	t.Helper()
+	ctx := context.Background()
+
+	santoso, spaced := "Santoso", "  santoso "
+	if err := repo.SetUserAlias(ctx, 1, "Budi", &santoso); err != nil {
+		t.Fatalf("SetUserAlias: %v", err)
+	}
+	if err := repo.SetUserAlias(ctx, 2, "budi", &spaced); err != nil {
+		t.Fatalf("SetUserAlias: %v", err)
+	}
+	upper := "SANTOSO"
+	if _, _, err := repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{
+		{UserID: 3, FirstName: "BUDI", LastName: &upper, Timestamp: time.Now(), Type: "check_in", Date: "2024-03-04"},
+		{UserID: 3, FirstName: "BUDI", LastName: &upper, Timestamp: time.Now(), Type: "check_out", Date: "2024-03-04"},
+		{UserID: 4, FirstName: "Budi Santosa", Timestamp: time.Now(), Type: "check_in", Date: "2024-03-04"},
+	}); err != nil {
+		t.Fatalf("InsertAttendanceBatch: %v", err)
+	}
+}
+
+func TestFindNameMatches(t *testing.T) {
+	repo := newTestRepository(t)
+	seedNames(t, repo)
+
+	type match struct {
+		userID int64
+		kind   string
+	}
+	all := []match{{1, models.NameKindAlias}, {2, models.NameKindAlias}, {3, models.NameKindTelegram}}
+
+	tests := []struct {
+		name   string
+		userID int64
+		key    string
+		want   []match
+	}{
+		{"new user", 9, utils.NameKey("budi", "santoso"), all},
+		{"spacing and case", 9, utils.NameKey(" BUDI\t", "SanToso  "), all},
+		{"split differently", 9, utils.NameKey("Budi Santoso", ""), all},
+		{"own names excluded", 1, utils.NameKey("Budi", "Santoso"), all[1:]},
+		{"telegram user", 3, utils.NameKey("Budi", "Santoso"), all[:2]},
+		{"one letter apart", 9, utils.NameKey("Budi", "Santosa"), []match{{4, models.NameKindTelegram}}},
+		{"first name only", 9, utils.NameKey("Budi", ""), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := repo.FindNameMatches(context.Background(), tt.userID, tt.key)
+			if err != nil {
+				t.Fatalf("FindNameMatches: %v", err)
+			}
+			if len(matches) != len(tt.want) {
+				t.Fatalf("FindNameMatches(%q) = %+v, want %v", tt.key, matches, tt.want)
+			}
+			for i, want := range tt.want {
+				if matches[i].UserID != want.userID || matches[i].Kind != want.kind || matches[i].Key != tt.key {
+					t.Errorf("match %d = %+v, want user %d's %s name", i+1, matches[i], want.userID, want.kind)
+				}
+			}
+		})
+	}
+}
+
+func TestGetNameCollisions(t *testing.T) {
+	repo := newTestRepository(t)
+	seedNames(t, repo)
+
+	collisions, err := repo.GetNameCollisions(context.Background())
+	if err != nil {
+		t.Fatalf("GetNameCollisions: %v", err)
+	}
+
+	// User 4's name is unique and users 1 and 2 have no Telegram name recorded
+	want := []int64{1, 2, 3}
+	if len(collisions) != len(want) {
+		t.Fatalf("collisions = %+v, want users %v", collisions, want)
+	}
+	for i, userID := range want {
+		if collisions[i].UserID != userID || collisions[i].Key != "budi santoso" {
+			t.Errorf("collision %d = %+v, want user %d as budi santoso", i+1, collisions[i], userID)
+		}
+	}
+}
+
+// TestInsertRefusesArchivedDates checks both insert paths against the archive boundary: records
+// before it are refused, records on or after it are stored
+func TestInsertRefusesArchivedDates(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	if err := repo.SetArchivedBefore(ctx, "2023-01-01"); err != nil {
+		t.Fatalf("SetArchivedBefore: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		date    string
+		archive bool
+		stored  int // Records of the date and of the batch's later day stored after both inserts
+	}{
+		{name: "last archived day", date: "2022-12-31", archive: true, stored: 0},
+		{name: "long archived", date: "2020-06-15", archive: true, stored: 0},
+		{name: "boundary", date: "2023-01-01", stored: 3},
+		{name: "after boundary", date: "2023-03-04", stored: 3},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day, err := utils.ParseDate(tt.date)
+			if err != nil {
+				t.Fatalf("ParseDate(%q): %v", tt.date, err)
+			}
+			checkIn := models.AttendanceRecord{
+				UserID: int64(10 + i), FirstName: "Budi", Type: "check_in", Date: tt.date, Timestamp: day.Add(8 * time.Hour),
+			}
+			checkOut := checkIn
+			checkOut.Type, checkOut.Timestamp = "check_out", day.Add(17*time.Hour)
+
+			if _, err := repo.InsertAttendance(ctx, &checkIn); errors.Is(err, ErrArchived) != tt.archive {
+				t.Errorf("InsertAttendance(%s) error = %v, want archived %v", tt.date, err, tt.archive)
+			}
+			// A batch with one archived record is refused as a whole
+			upcoming := models.AttendanceRecord{
+				UserID: checkIn.UserID, FirstName: "Budi", Type: "check_in", Date: "2023-06-01", Timestamp: time.Date(2023, 6, 1, 1, 0, 0, 0, time.UTC),
+			}
+			if _, _, err := repo.InsertAttendanceBatch(ctx, []models.AttendanceRecord{upcoming, checkOut}); errors.Is(err, ErrArchived) != tt.archive {
+				t.Errorf("InsertAttendanceBatch(%s) error = %v, want archived %v", tt.date, err, tt.archive)
+			}
+
+			stored := 0
+			for _, date := range []string{tt.date, upcoming.Date} {
+				records, err := repo.GetUserAttendanceToday(ctx, checkIn.UserID, date)
+				if err != nil {
+					t.Fatalf("GetUserAttendanceToday: %v", err)
+				}
+				stored += len(records)
+			}
+			if stored != tt.stored {
+				t.Errorf("%d records stored, want %d", stored, tt.stored)
+			}
+		})
+	}
+}
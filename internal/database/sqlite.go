@@ -3,107 +3,114 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
 
-// SQLiteDB wraps the sql.DB connection
-type SQLiteDB struct {
-	*sql.DB
+// busyTimeoutMillis is how long a SQLite connection waits for a lock held by another one
+const busyTimeoutMillis = 5000
+
+// NewSQLiteDB creates a new SQLite database connection and applies all pending migrations
+func NewSQLiteDB(dbPath string) (*DB, error) {
+	sqliteDB, err := OpenSQLiteDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize schema
+	if _, err := sqliteDB.Migrate(0, nil); err != nil {
+		sqliteDB.Close()
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+
+	return sqliteDB, nil
 }
 
-// NewSQLiteDB creates a new SQLite database connection
-func NewSQLiteDB(dbPath string) (*SQLiteDB, error) {
+// OpenSQLiteDB opens a SQLite database connection without touching the schema
+func OpenSQLiteDB(dbPath string) (*DB, error) {
 	// Ensure the directory exists
-	dir := filepath.Dir(dbPath)
+	file, _, _ := strings.Cut(dbPath, "?")
+	dir := filepath.Dir(file)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	// Open database connection
-	db, err := sql.Open("sqlite", dbPath)
+	// Concurrent updates wait for each other's locks instead of failing with SQLITE_BUSY, and
+	// transactions take the write lock up front so one that read first cannot be refused when it
+	// writes
+	dsn, err := sqliteDSN(dbPath, url.Values{
+		"_pragma": {"busy_timeout(" + strconv.Itoa(busyTimeoutMillis) + ")"},
+		"_txlock": {"immediate"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	sqliteDB := &SQLiteDB{DB: db}
-
-	// Initialize schema
-	if err := sqliteDB.initSchema(); err != nil {
+	// Enable foreign keys
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
 	}
 
-	return sqliteDB, nil
+	return &DB{DB: db, engine: SQLite}, nil
 }
 
-// initSchema creates the necessary tables and indexes
-func (db *SQLiteDB) initSchema() error {
-	// Enable foreign keys
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		return fmt.Errorf("failed to enable foreign keys: %w", err)
-	}
-
-	// Create attendance table
-	attendanceTableSQL := `
-	CREATE TABLE IF NOT EXISTS attendance (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		user_id INTEGER NOT NULL,
-		username TEXT NOT NULL,
-		first_name TEXT NOT NULL,
-		last_name TEXT,
-		timestamp TEXT NOT NULL,
-		type TEXT NOT NULL CHECK (type IN ('check_in', 'check_out')),
-		date TEXT NOT NULL,
-		UNIQUE(user_id, date, type)
-	);`
-
-	if _, err := db.Exec(attendanceTableSQL); err != nil {
-		return fmt.Errorf("failed to create attendance table: %w", err)
+// OpenSQLiteDBReadOnly opens an existing SQLite database for reading only
+func OpenSQLiteDBReadOnly(dbPath string) (*DB, error) {
+	file, _, _ := strings.Cut(dbPath, "?")
+	if _, err := os.Stat(file); err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Create indexes for attendance table
-	indexes := []string{
-		"CREATE INDEX IF NOT EXISTS idx_user_date ON attendance(user_id, date);",
-		"CREATE INDEX IF NOT EXISTS idx_date ON attendance(date);",
-		"CREATE INDEX IF NOT EXISTS idx_user_id ON attendance(user_id);",
-		"CREATE INDEX IF NOT EXISTS idx_type ON attendance(type);",
+	dsn, err := sqliteDSN("file:"+filepath.ToSlash(dbPath), url.Values{"mode": {"ro"}})
+	if err != nil {
+		return nil, err
 	}
-
-	for _, indexSQL := range indexes {
-		if _, err := db.Exec(indexSQL); err != nil {
-			return fmt.Errorf("failed to create index: %w", err)
-		}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Create alias table
-	aliasTableSQL := `
-	CREATE TABLE IF NOT EXISTS alias (
-		user_id INTEGER PRIMARY KEY,
-		first_name TEXT NOT NULL,
-		last_name TEXT
-	);`
-
-	if _, err := db.Exec(aliasTableSQL); err != nil {
-		return fmt.Errorf("failed to create alias table: %w", err)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return nil
+	return &DB{DB: db, engine: SQLite}, nil
 }
 
-// Close closes the database connection
-func (db *SQLiteDB) Close() error {
-	return db.DB.Close()
-}
+// sqliteDSN adds params to the query of a SQLite path, which may already have one, e.g.
+// "data/attendance.db?_pragma=journal_mode(WAL)". _pragma values are added to those of the path,
+// and other params set unless the path sets them.
+func sqliteDSN(dbPath string, params url.Values) (string, error) {
+	file, rawQuery, _ := strings.Cut(dbPath, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("invalid database query %q: %w", rawQuery, err)
+	}
 
-// BeginTx starts a new transaction
-func (db *SQLiteDB) BeginTx() (*sql.Tx, error) {
-	return db.DB.Begin()
+	for key, values := range params {
+		switch {
+		case key == "_pragma":
+			query[key] = append(values, query[key]...)
+		case !query.Has(key):
+			query[key] = values
+		}
+	}
+	return file + "?" + query.Encode(), nil
 }
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSQLiteDSN(t *testing.T) {
+	params := url.Values{
+		"_pragma": {"busy_timeout(5000)"},
+		"_txlock": {"immediate"},
+	}
+
+	tests := []struct {
+		name   string
+		dbPath string
+		want   url.Values
+	}{
+		{
+			name:   "plain path",
+			dbPath: "data/attendance.db",
+			want:   url.Values{"_pragma": {"busy_timeout(5000)"}, "_txlock": {"immediate"}},
+		},
+		{
+			name:   "path with a pragma",
+			dbPath: "data/attendance.db?_pragma=journal_mode(WAL)",
+			want:   url.Values{"_pragma": {"busy_timeout(5000)", "journal_mode(WAL)"}, "_txlock": {"immediate"}},
+		},
+		{
+			name:   "path setting a param",
+			dbPath: "data/attendance.db?_txlock=deferred&cache=shared",
+			want:   url.Values{"_pragma": {"busy_timeout(5000)"}, "_txlock": {"deferred"}, "cache": {"shared"}},
+		},
+		{
+			name:   "empty query",
+			dbPath: "data/attendance.db?",
+			want:   url.Values{"_pragma": {"busy_timeout(5000)"}, "_txlock": {"immediate"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dsn, err := sqliteDSN(tc.dbPath, params)
+			if err != nil {
+				t.Fatalf("sqliteDSN: %v", err)
+			}
+
+			file, rawQuery, _ := strings.Cut(dsn, "?")
+			if strings.Count(dsn, "?") != 1 || file != "data/attendance.db" {
+				t.Fatalf("sqliteDSN(%q) = %q, want a single query on data/attendance.db", tc.dbPath, dsn)
+			}
+			query, err := url.ParseQuery(rawQuery)
+			if err != nil {
+				t.Fatalf("sqliteDSN(%q) = %q: %v", tc.dbPath, dsn, err)
+			}
+			if query.Encode() != tc.want.Encode() {
+				t.Errorf("sqliteDSN(%q) query = %v, want %v", tc.dbPath, query, tc.want)
+			}
+		})
+	}
+
+	if _, err := sqliteDSN("data/attendance.db?bad=%zz", params); err == nil {
+		t.Error("sqliteDSN accepted a malformed query")
+	}
+}
+
+func TestOpenSQLiteDBWithQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "attendance.db")
+
+	db, err := NewSQLiteDB(path + "?_pragma=journal_mode(WAL)")
+	if err != nil {
+		t.Fatalf("NewSQLiteDB: %v", err)
+	}
+	defer db.Close()
+
+	var journalMode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil {
+		t.Fatalf("journal_mode: %v", err)
+	}
+	if journalMode != "wal" {
+		t.Errorf("journal_mode = %q, want wal from the path's query", journalMode)
+	}
+
+	var busyTimeout int
+	if err := db.QueryRow("PRAGMA busy_timeout").Scan(&busyTimeout); err != nil {
+		t.Fatalf("busy_timeout: %v", err)
+	}
+	if busyTimeout != busyTimeoutMillis {
+		t.Errorf("busy_timeout = %d, want %d", busyTimeout, busyTimeoutMillis)
+	}
+
+	readOnly, err := OpenSQLiteDBReadOnly(path + "?_pragma=journal_mode(WAL)")
+	if err != nil {
+		t.Fatalf("OpenSQLiteDBReadOnly: %v", err)
+	}
+	defer readOnly.Close()
+	if _, err := readOnly.Exec("CREATE TABLE scratch (id INTEGER)"); err == nil {
+		t.Error("read-only database accepted a write")
+	}
+}
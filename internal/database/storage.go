@@ -0,0 +1,393 @@
+package database
+
+import (
+	"attendance-bot/pkg/models"
+	"context"
+	"time"
+)
+
+// Repository handles all database operations. NewRepository returns the implementation for the
+// engine of a DB, so callers work the same on SQLite and PostgreSQL.
+type Repository interface {
+	// InsertAttendance adds a new attendance record. Records dated before the archive boundary
+	// are refused with ErrArchived.
+	InsertAttendance(ctx context.Context, record *models.AttendanceRecord) (*models.AttendanceRecord, error)
+
+	// InsertAttendanceBatch inserts records in a single transaction. Records that collide with an
+	// existing (user_id, date, type) entry are skipped and their indexes returned as duplicates.
+	// The whole batch is refused with ErrArchived when a record is dated before the archive boundary.
+	InsertAttendanceBatch(ctx context.Context, records []models.AttendanceRecord) (int, []int, error)
+
+	// GetUserAttendanceToday retrieves today's attendance records for a user
+	GetUserAttendanceToday(ctx context.Context, userID int64, date string) ([]models.AttendanceRecord, error)
+
+	// GetUserAttendanceStatus returns the attendance status for a user on a specific date
+	GetUserAttendanceStatus(ctx context.Context, userID int64, date string) (*models.AttendanceStatus, error)
+
+	// GetUserAttendanceHistory retrieves attendance history for a user
+	GetUserAttendanceHistory(ctx context.Context, userID int64, days int) ([]models.AttendanceRecord, error)
+
+	// GetUserAttendanceRange retrieves a user's attendance records within a date range, newest day first
+	GetUserAttendanceRange(ctx context.Context, userID int64, startDate, endDate string) ([]models.AttendanceRecord, error)
+
+	// GetDailyReport retrieves all attendance records for a specific date
+	GetDailyReport(ctx context.Context, date string) ([]models.AttendanceRecord, error)
+
+	// GetAttendanceReportRange retrieves attendance records within a date range
+	GetAttendanceReportRange(ctx context.Context, startDate, endDate string) ([]models.AttendanceRecord, error)
+
+	// ListAttendance retrieves attendance records filtered by user and/or date.
+	// A zero userID or empty date disables that filter.
+	ListAttendance(ctx context.Context, userID int64, date string) ([]models.AttendanceRecord, error)
+
+	// DeleteAttendance removes an attendance record by ID, returning false if it did not exist
+	DeleteAttendance(ctx context.Context, id int64) (bool, error)
+
+	// SetUserAlias sets or updates a user's alias
+	SetUserAlias(ctx context.Context, userID int64, firstName string, lastName *string) error
+
+	// DeleteUserAlias removes a user's alias, returning false if none existed
+	DeleteUserAlias(ctx context.Context, userID int64) (bool, error)
+
+	// GetUserAlias retrieves a user's alias
+	GetUserAlias(ctx context.Context, userID int64) (*models.UserAlias, error)
+
+	// FindNameMatches returns the alias and Telegram names of users other than userID whose
+	// normalized name equals key
+	FindNameMatches(ctx context.Context, userID int64, key string) ([]models.NameMatch, error)
+
+	// GetNameCollisions returns every name shared by more than one user, ordered by normalized name
+	GetNameCollisions(ctx context.Context) ([]models.NameMatch, error)
+
+	// SaveAliasRequest stores an alias awaiting approval, replacing the user's previous request
+	SaveAliasRequest(ctx context.Context, request *models.AliasRequest) error
+
+	// GetAliasRequest returns the user's pending alias request, or nil if they have none
+	GetAliasRequest(ctx context.Context, userID int64) (*models.AliasRequest, error)
+
+	// GetAliasRequests returns all pending alias requests, oldest first
+	GetAliasRequests(ctx context.Context) ([]models.AliasRequest, error)
+
+	// DeleteAliasRequest removes the user's pending alias request, returning false if none existed
+	DeleteAliasRequest(ctx context.Context, userID int64) (bool, error)
+
+	// AddAdmin grants a user admin rights, returning false if they already had them
+	AddAdmin(ctx context.Context, admin *models.Admin) (bool, error)
+
+	// RemoveAdmin revokes a user's admin rights, returning false if they had none
+	RemoveAdmin(ctx context.Context, userID int64) (bool, error)
+
+	// GetAdmin returns the user's admin entry, or nil if they are not an admin
+	GetAdmin(ctx context.Context, userID int64) (*models.Admin, error)
+
+	// GetAdmins returns all admins in the order they were added
+	GetAdmins(ctx context.Context) ([]models.Admin, error)
+
+	// InsertLeave stores a new leave request
+	InsertLeave(ctx context.Context, leave *models.Leave) (*models.Leave, error)
+
+	// GetLeave returns a leave request by ID, or nil if it does not exist
+	GetLeave(ctx context.Context, id int64) (*models.Leave, error)
+
+	// DecideLeave approves or rejects a pending leave request, returning false if it was not pending
+	DecideLeave(ctx context.Context, id int64, status string, decidedBy int64, decidedAt time.Time) (bool, error)
+
+	// GetApprovedLeaves returns the approved leaves overlapping startDate to endDate (inclusive)
+	GetApprovedLeaves(ctx context.Context, startDate, endDate string) ([]models.Leave, error)
+
+	// GetUserLeaves returns the user's leave requests that end on or after fromDate
+	GetUserLeaves(ctx context.Context, userID int64, fromDate string) ([]models.Leave, error)
+
+	// HasOverlappingLeave reports whether the user has a pending or approved leave overlapping
+	// startDate to endDate (inclusive)
+	HasOverlappingLeave(ctx context.Context, userID int64, startDate, endDate string) (bool, error)
+
+	// SaveShift creates the shift or replaces the times of the shift with the same name
+	SaveShift(ctx context.Context, shift *models.Shift) error
+
+	// DeleteShift deletes a shift that no user is assigned to, returning false if it does not exist
+	DeleteShift(ctx context.Context, name string) (bool, error)
+
+	// GetShifts returns all shifts with the number of users assigned to each, ordered by start time
+	GetShifts(ctx context.Context) ([]models.Shift, error)
+
+	// AssignShift assigns a user to a shift, replacing any previous assignment
+	AssignShift(ctx context.Context, userID int64, shiftName string, assignedBy int64, assignedAt time.Time) error
+
+	// UnassignShift removes a user's shift assignment, returning false if they had none
+	UnassignShift(ctx context.Context, userID int64) (bool, error)
+
+	// GetShiftAssignments returns the shift of every user: their assigned shift, else the shift named
+	// models.DefaultShiftName, else models.BuiltinShift
+	GetShiftAssignments(ctx context.Context) (*models.ShiftAssignments, error)
+
+	// InsertFailedOTP records a rejected OTP attempt
+	InsertFailedOTP(ctx context.Context, failure *models.FailedOTP) error
+
+	// CountFailedOTPsSince returns how many failed attempts a user made since the given time
+	CountFailedOTPsSince(ctx context.Context, userID int64, since time.Time) (int, error)
+
+	// GetFailedOTPsSince retrieves all failed attempts since the given time, newest first
+	GetFailedOTPsSince(ctx context.Context, since time.Time) ([]models.FailedOTP, error)
+
+	// GetOTPLockout returns the user's failed OTP counter, or nil if they have none
+	GetOTPLockout(ctx context.Context, userID int64) (*models.OTPLockout, error)
+
+	// CountOTPFailure atomically counts a failed OTP made at now and returns the user's counter. The
+	// failure adds to a window started after windowAfter and not ended by a lockout, or else starts a
+	// new window at now. Reaching limit failures locks the user out until lockedUntil.
+	CountOTPFailure(ctx context.Context, userID int64, now, windowAfter time.Time, limit int, lockedUntil time.Time) (*models.OTPLockout, error)
+
+	// DeleteOTPLockout removes the user's failed OTP counter, returning false if they had none
+	DeleteOTPLockout(ctx context.Context, userID int64) (bool, error)
+
+	// GetOTPLockoutsUntil returns the counters of users locked out after the given time
+	GetOTPLockoutsUntil(ctx context.Context, after time.Time) ([]models.OTPLockout, error)
+
+	// GetHOTPEnrollment retrieves a user's HOTP enrollment, or nil if the user uses TOTP.
+	// The secret is stored separately in user_secrets and is not populated.
+	GetHOTPEnrollment(ctx context.Context, userID int64) (*models.HOTPEnrollment, error)
+
+	// GetHOTPEmployees returns the users enrolled in HOTP, named by their alias or else by the
+	// Telegram name of their latest attendance record. Users with neither have an empty first name.
+	GetHOTPEmployees(ctx context.Context) ([]models.Employee, error)
+
+	// SetHOTPEnrollment creates or replaces a user's HOTP enrollment together with its encrypted secret
+	SetHOTPEnrollment(ctx context.Context, enrollment *models.HOTPEnrollment, secret *models.UserSecret) error
+
+	// DeleteHOTPEnrollment removes a user's HOTP enrollment and secret, returning them to TOTP
+	DeleteHOTPEnrollment(ctx context.Context, userID int64) error
+
+	// GetUserSecret retrieves a user's encrypted secret
+	GetUserSecret(ctx context.Context, userID int64) (*models.UserSecret, error)
+
+	// ListUserSecrets retrieves all encrypted user secrets
+	ListUserSecrets(ctx context.Context) ([]models.UserSecret, error)
+
+	// UpdateUserSecrets replaces the ciphertext of several secrets in a single transaction
+	UpdateUserSecrets(ctx context.Context, secrets []models.UserSecret) error
+
+	// CountUserSecrets returns the number of stored user secrets
+	CountUserSecrets(ctx context.Context) (int, error)
+
+	// GetSharedSecretUserIDs returns the users who recorded attendance on or after sinceDate and
+	// verify with the shared TOTP secret
+	GetSharedSecretUserIDs(ctx context.Context, sinceDate string) ([]int64, error)
+
+	// InsertAttendanceAdvancingHOTP moves a user's HOTP counter from expected to next and inserts the
+	// attendance the matched code verifies in one transaction. It returns ErrNotFound if another
+	// request moved the counter in the meantime; the record is then not saved.
+	InsertAttendanceAdvancingHOTP(ctx context.Context, record *models.AttendanceRecord, expected, next uint64) (*models.AttendanceRecord, error)
+
+	// AdvanceHOTPCounter moves a user's counter forward only if it still holds the expected value.
+	// It returns false when another request already advanced the counter.
+	AdvanceHOTPCounter(ctx context.Context, userID int64, expected, next uint64) (bool, error)
+
+	// GetArchivedBefore returns the date (exclusive) before which records may be archived, or "" if none are
+	GetArchivedBefore(ctx context.Context) (string, error)
+
+	// SetArchivedBefore records that records dated before the given date may be in the archive.
+	// It must be set before records are moved, so reads include the archive during the move.
+	SetArchivedBefore(ctx context.Context, date string) error
+
+	// ArchiveAttendanceBatch moves up to limit records dated before the given date into
+	// attendance_archive in one transaction and returns how many were moved. The transaction
+	// is rolled back unless the rows copied equal the rows deleted.
+	ArchiveAttendanceBatch(ctx context.Context, before string, limit int) (int, error)
+
+	// CountAttendanceBefore counts records dated before the given date in the attendance table and the archive
+	CountAttendanceBefore(ctx context.Context, before string) (hot, archived int, err error)
+
+	// GetAttendanceRecord returns the record with the given ID from the attendance table or the
+	// archive, or nil if there is none
+	GetAttendanceRecord(ctx context.Context, id int64) (*models.AttendanceRecord, error)
+
+	// SetAttendancePhoto attaches a photo to a record, returning false if it already has one
+	SetAttendancePhoto(ctx context.Context, id int64, fileID string) (bool, error)
+
+	// CheckUserAttendanceExists checks if a user has any attendance record for a specific date and type
+	CheckUserAttendanceExists(ctx context.Context, userID int64, date, attendanceType string) (bool, error)
+
+	// GetUserLanguage returns the user's stored language, or nil if none is stored
+	GetUserLanguage(ctx context.Context, userID int64) (*models.UserLanguage, error)
+
+	// SaveUserLanguage stores the user's language, replacing any stored one
+	SaveUserLanguage(ctx context.Context, language *models.UserLanguage) error
+
+	// GetUserTimezone returns the user's own timezone, or nil if they did not set one
+	GetUserTimezone(ctx context.Context, userID int64) (*models.UserTimezone, error)
+
+	// SaveUserTimezone stores the user's own timezone, replacing any stored one
+	SaveUserTimezone(ctx context.Context, timezone *models.UserTimezone) error
+
+	// DeleteUserTimezone removes the user's own timezone, reporting whether one was set
+	DeleteUserTimezone(ctx context.Context, userID int64) (bool, error)
+
+	// GetBotState returns a persisted bot state value, or "" if it is not set
+	GetBotState(ctx context.Context, key string) (string, error)
+
+	// SetBotState persists a bot state value
+	SetBotState(ctx context.Context, key, value string) error
+
+	// GetMissingCheckouts returns the check-ins between startDate and endDate (inclusive) that have
+	// no check-out for the same user and day, ordered by date and user
+	GetMissingCheckouts(ctx context.Context, startDate, endDate string) ([]models.AttendanceRecord, error)
+
+	// GetAttendanceDays returns, per user and date between startDate and endDate (inclusive), the
+	// earliest check-in and latest check-out and the time on breaks, ordered by user and date. The
+	// identity columns are those of one of the day's records.
+	GetAttendanceDays(ctx context.Context, startDate, endDate string) ([]models.AttendanceDay, error)
+
+	// GetOnShift returns the check-ins of date that have no check-out yet, earliest first
+	GetOnShift(ctx context.Context, date string) ([]models.AttendanceRecord, error)
+
+	// InsertBypassCode stores a new bypass code, revoking the user's unused codes so only the latest is valid
+	InsertBypassCode(ctx context.Context, code *models.BypassCode) (*models.BypassCode, error)
+
+	// FindBypassCode returns the user's most recent bypass code with the given hash, or nil if there is none
+	FindBypassCode(ctx context.Context, userID int64, codeHash string) (*models.BypassCode, error)
+
+	// RedeemBypassCode marks a bypass code used and inserts the attendance it verifies in one transaction.
+	// It returns ErrNotFound if the code was used or expired in the meantime; the record is then not saved.
+	RedeemBypassCode(ctx context.Context, codeID int64, record *models.AttendanceRecord, now time.Time) (*models.AttendanceRecord, error)
+
+	// AddSubscription subscribes a chat to a digest, returning false if it was already subscribed
+	AddSubscription(ctx context.Context, subscription *models.Subscription) (bool, error)
+
+	// RemoveSubscription unsubscribes a chat from a digest, returning false if it was not subscribed
+	RemoveSubscription(ctx context.Context, chatID int64, digest string) (bool, error)
+
+	// GetSubscriptions returns the subscriptions to a digest, oldest first
+	GetSubscriptions(ctx context.Context, digest string) ([]models.Subscription, error)
+
+	// GetChatSubscriptions returns the digests a chat is subscribed to
+	GetChatSubscriptions(ctx context.Context, chatID int64) ([]models.Subscription, error)
+
+	// AddOfficeGroup registers a group chat as an office channel, returning false if it already was
+	AddOfficeGroup(ctx context.Context, group *models.OfficeGroup) (bool, error)
+
+	// RemoveOfficeGroup unregisters a group chat, returning false if it was not registered
+	RemoveOfficeGroup(ctx context.Context, chatID int64) (bool, error)
+
+	// GetOfficeGroup returns the registered group chat, or nil if the chat is not registered
+	GetOfficeGroup(ctx context.Context, chatID int64) (*models.OfficeGroup, error)
+
+	// GetOfficeGroups returns the registered group chats, oldest registration first
+	GetOfficeGroups(ctx context.Context) ([]models.OfficeGroup, error)
+
+	// SaveGeofence creates or replaces a geofence
+	SaveGeofence(ctx context.Context, geofence *models.Geofence) error
+
+	// DeleteGeofence deletes a geofence, returning false if it did not exist
+	DeleteGeofence(ctx context.Context, name string) (bool, error)
+
+	// GetGeofences returns the geofences ordered by name
+	GetGeofences(ctx context.Context) ([]models.Geofence, error)
+
+	// SaveReminder enables reminders for the user, replacing their previous lead time
+	SaveReminder(ctx context.Context, reminder *models.ReminderPreference) error
+
+	// DeleteReminder disables reminders for the user, returning false if they were not enabled
+	DeleteReminder(ctx context.Context, userID int64) (bool, error)
+
+	// GetReminder returns the user's reminder preference, or nil if reminders are not enabled
+	GetReminder(ctx context.Context, userID int64) (*models.ReminderPreference, error)
+
+	// GetReminders returns the reminder preferences of all users who enabled reminders
+	GetReminders(ctx context.Context) ([]models.ReminderPreference, error)
+
+	// SaveWeeklySummaryOptOut stops the weekly summary for the user, keeping an earlier opt-out
+	SaveWeeklySummaryOptOut(ctx context.Context, userID int64, at time.Time) error
+
+	// DeleteWeeklySummaryOptOut resumes the weekly summary for the user, returning false if they
+	// had not opted out
+	DeleteWeeklySummaryOptOut(ctx context.Context, userID int64) (bool, error)
+
+	// GetWeeklySummaryOptOuts returns the users who opted out of the weekly summary
+	GetWeeklySummaryOptOuts(ctx context.Context) (map[int64]bool, error)
+
+	// CorrectAttendance applies an admin's correction and records it in the audit log in one
+	// transaction. With before nil, after is inserted; with after nil, before is deleted; otherwise
+	// before takes the timestamp and source of after. It returns ErrNotFound if before no longer exists.
+	CorrectAttendance(ctx context.Context, before, after *models.AttendanceRecord, entry *models.AuditEntry) (*models.AuditEntry, error)
+
+	// InsertAuditEntry appends an entry to the audit log
+	InsertAuditEntry(ctx context.Context, entry *models.AuditEntry) error
+
+	// GetAuditLogRange returns the audit log entries recorded from since up to until, oldest first
+	GetAuditLogRange(ctx context.Context, since, until time.Time) ([]models.AuditEntry, error)
+
+	// GetAuditLog returns the newest audit log entries, at most limit, concerning the user or, with
+	// userID 0, anyone
+	GetAuditLog(ctx context.Context, userID int64, limit int) ([]models.AuditEntry, error)
+
+	// InsertWebhookFailure records an event webhook delivery that failed for good
+	InsertWebhookFailure(ctx context.Context, failure *models.WebhookFailure) error
+
+	// SaveRegisteredEmployee adds the user to the employee directory or updates their entry,
+	// keeping who registered them first and when
+	SaveRegisteredEmployee(ctx context.Context, employee *models.RegisteredEmployee) error
+
+	// DeleteRegisteredEmployee removes the user from the employee directory, returning false if
+	// they were not in it
+	DeleteRegisteredEmployee(ctx context.Context, userID int64) (bool, error)
+
+	// GetRegisteredEmployee returns the user's directory entry, or nil if they are not registered
+	GetRegisteredEmployee(ctx context.Context, userID int64) (*models.RegisteredEmployee, error)
+
+	// FindRegisteredEmployee returns the directory entry with the employee ID, or nil if there is none
+	FindRegisteredEmployee(ctx context.Context, employeeID string) (*models.RegisteredEmployee, error)
+
+	// GetRegisteredEmployees returns the employee directory ordered by department and employee ID
+	GetRegisteredEmployees(ctx context.Context) ([]models.RegisteredEmployee, error)
+
+	// SaveSession starts or replaces the user's conversation session
+	SaveSession(ctx context.Context, session *models.Session) error
+
+	// GetSession returns the user's session, or nil if there is none or it expired before now
+	GetSession(ctx context.Context, userID int64, now time.Time) (*models.Session, error)
+
+	// DeleteSession ends the user's session, if any
+	DeleteSession(ctx context.Context, userID int64) error
+
+	// DeleteExpiredSessions removes the sessions that expired before now, returning how many
+	DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error)
+
+	// SaveHoliday adds a holiday or renames the one on its date
+	SaveHoliday(ctx context.Context, holiday *models.Holiday) error
+
+	// InsertHolidays adds holidays in a single transaction, skipping those on dates that already
+	// have one, and returns how many were added
+	InsertHolidays(ctx context.Context, holidays []models.Holiday) (int, error)
+
+	// DeleteHoliday removes the holiday on date, returning false if there was none
+	DeleteHoliday(ctx context.Context, date string) (bool, error)
+
+	// GetHolidays returns the holidays from startDate to endDate (inclusive), ordered by date
+	GetHolidays(ctx context.Context, startDate, endDate string) ([]models.Holiday, error)
+
+	// InsertWFHRequest stores a new request to work from home. A second request of the user for
+	// the same date fails with ErrDuplicate.
+	InsertWFHRequest(ctx context.Context, request *models.WFHRequest) (*models.WFHRequest, error)
+
+	// GetWFHRequest returns a request to work from home by ID, or nil if it does not exist
+	GetWFHRequest(ctx context.Context, id int64) (*models.WFHRequest, error)
+
+	// GetUserWFHRequest returns the user's request to work from home on date, or nil if there is none
+	GetUserWFHRequest(ctx context.Context, userID int64, date string) (*models.WFHRequest, error)
+
+	// GetUserWFHRequests returns the user's requests to work from home on or after fromDate
+	GetUserWFHRequests(ctx context.Context, userID int64, fromDate string) ([]models.WFHRequest, error)
+
+	// DecideWFHRequest approves or rejects a pending request to work from home, returning false if
+	// it was not pending
+	DecideWFHRequest(ctx context.Context, id int64, status string, decidedBy int64, decidedAt time.Time) (bool, error)
+
+	// DeleteWFHRequest removes the user's request to work from home on date, returning false if
+	// there was none
+	DeleteWFHRequest(ctx context.Context, userID int64, date string) (bool, error)
+
+	// SetAttendanceRemote marks the user's attendance records of date as remote or not, returning
+	// how many were changed
+	SetAttendanceRemote(ctx context.Context, userID int64, date string, remote bool) (int64, error)
+}
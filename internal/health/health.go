@@ -0,0 +1,200 @@
+package health
+
+import (
+	"attendance-bot/internal/metrics"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Component statuses reported in the JSON body
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// Pinger reports whether the database is reachable
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Poller reports when the bot last fetched updates successfully
+type Poller interface {
+	LastPoll() time.Time
+	PollTimeout() time.Duration
+}
+
+// TOTPChecker reports whether attendance codes can be checked
+type TOTPChecker interface {
+	CheckTOTP() error
+}
+
+// livenessPolls is how many poll timeouts may pass without a successful poll before /healthz
+// reports the bot as wedged, allowing for Telegram outages that a restart would not fix
+const livenessPolls = 5
+
+// ComponentStatus describes the health of one dependency
+type ComponentStatus struct {
+	Status   string     `json:"status"`
+	Error    string     `json:"error,omitempty"`
+	LastPoll *time.Time `json:"last_poll,omitempty"`
+}
+
+// Response is the JSON body returned by the health endpoints
+type Response struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
+
+// Server exposes liveness and readiness probes and Prometheus metrics over HTTP
+type Server struct {
+	db      Pinger
+	poller  Poller
+	totp    TOTPChecker
+	logger  *slog.Logger
+	server  *http.Server
+	now     func() time.Time
+	started time.Time
+}
+
+// NewServer creates a health server listening on addr
+func NewServer(addr string, db Pinger, poller Poller, totp TOTPChecker, logger *slog.Logger) *Server {
+	s := &Server{
+		db:      db,
+		poller:  poller,
+		totp:    totp,
+		logger:  logger,
+		now:     time.Now,
+		started: time.Now(),
+	}
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	return s
+}
+
+// Handler returns the HTTP handler serving /healthz, /readyz and /metrics
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	mux.Handle("/metrics", metrics.Handler())
+	return mux
+}
+
+// Start serves requests until Shutdown is called
+func (s *Server) Start() error {
+	s.logger.Info("Health server listening", "addr", s.server.Addr)
+
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the server, waiting for in-flight probes to finish
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// handleHealthz reports whether the bot is alive: its update loop has not been stuck for
+// livenessPolls poll timeouts, the database answers and codes can be checked. Orchestrators
+// restart the bot when it fails.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	s.writeComponents(w, map[string]ComponentStatus{
+		"database": s.checkDatabase(r.Context()),
+		"telegram": s.checkLiveness(),
+		"totp":     s.checkTOTP(),
+	})
+}
+
+// handleReadyz reports whether the database, the Telegram polling loop and TOTP are healthy
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	s.writeComponents(w, map[string]ComponentStatus{
+		"database": s.checkDatabase(r.Context()),
+		"telegram": s.checkPolling(),
+		"totp":     s.checkTOTP(),
+	})
+}
+
+// writeComponents responds 200 when every component is healthy and 503 otherwise
+func (s *Server) writeComponents(w http.ResponseWriter, components map[string]ComponentStatus) {
+	response := Response{Status: StatusOK, Components: components}
+
+	code := http.StatusOK
+	for _, component := range response.Components {
+		if component.Status != StatusOK {
+			response.Status = StatusUnavailable
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	writeJSON(w, code, response)
+}
+
+// checkDatabase pings the database with a short timeout
+func (s *Server) checkDatabase(ctx context.Context) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return ComponentStatus{Status: StatusUnavailable, Error: err.Error()}
+	}
+	return ComponentStatus{Status: StatusOK}
+}
+
+// checkPolling requires a successful getUpdates within twice the long-poll timeout
+func (s *Server) checkPolling() ComponentStatus {
+	lastPoll := s.poller.LastPoll()
+	if lastPoll.IsZero() {
+		return ComponentStatus{Status: StatusUnavailable, Error: "no successful poll yet"}
+	}
+
+	status := ComponentStatus{Status: StatusOK, LastPoll: &lastPoll}
+	if s.now().Sub(lastPoll) > 2*s.poller.PollTimeout() {
+		status.Status = StatusUnavailable
+		status.Error = "last successful poll is too old"
+	}
+	return status
+}
+
+// checkLiveness requires a successful getUpdates, or the start of the server before the first one,
+// within livenessPolls poll timeouts
+func (s *Server) checkLiveness() ComponentStatus {
+	lastPoll := s.poller.LastPoll()
+	status := ComponentStatus{Status: StatusOK}
+	since := s.started
+	if !lastPoll.IsZero() {
+		status.LastPoll = &lastPoll
+		since = lastPoll
+	}
+
+	if s.now().Sub(since) > livenessPolls*s.poller.PollTimeout() {
+		status.Status = StatusUnavailable
+		status.Error = "no successful poll for too long"
+	}
+	return status
+}
+
+// checkTOTP reports whether the current secret can generate and check codes
+func (s *Server) checkTOTP() ComponentStatus {
+	if err := s.totp.CheckTOTP(); err != nil {
+		return ComponentStatus{Status: StatusUnavailable, Error: err.Error()}
+	}
+	return ComponentStatus{Status: StatusOK}
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
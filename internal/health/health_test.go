@@ -0,0 +1,171 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubPinger is a database whose ping fails with err
+type stubPinger struct {
+	err error
+}
+
+func (p stubPinger) PingContext(context.Context) error { return p.err }
+
+// stubPoller is a polling loop that last polled at a time the test sets concurrently
+type stubPoller struct {
+	mu       sync.Mutex
+	lastPoll time.Time
+}
+
+func (p *stubPoller) LastPoll() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastPoll
+}
+
+func (p *stubPoller) PollTimeout() time.Duration { return 30 * time.Second }
+
+func (p *stubPoller) set(lastPoll time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastPoll = lastPoll
+}
+
+// stubTOTP is a TOTP service whose check fails with err
+type stubTOTP struct {
+	err error
+}
+
+func (s stubTOTP) CheckTOTP() error { return s.err }
+
+// probe requests path from the server and decodes its JSON response
+func probe(t *testing.T, s *Server, path string) (int, Response) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var response Response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s returned invalid JSON %q: %v", path, recorder.Body.String(), err)
+	}
+	if recorder.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("%s Content-Type = %q", path, recorder.Header().Get("Content-Type"))
+	}
+	return recorder.Code, response
+}
+
+func TestProbes(t *testing.T) {
+	now := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name       string
+		db         error
+		totp       error
+		lastPoll   time.Time
+		started    time.Time
+		ready      int
+		alive      int
+		unhealthy  string
+		liveFailed string
+	}{
+		{"healthy", nil, nil, now.Add(-10 * time.Second), now.Add(-time.Hour), http.StatusOK, http.StatusOK, "", ""},
+		{"database down", errors.New("database is locked"), nil, now.Add(-10 * time.Second), now.Add(-time.Hour), http.StatusServiceUnavailable, http.StatusServiceUnavailable, "database", "database"},
+		{"bad secret", nil, errors.New("secret is not base32"), now.Add(-10 * time.Second), now.Add(-time.Hour), http.StatusServiceUnavailable, http.StatusServiceUnavailable, "totp", "totp"},
+		{"not polled yet", nil, nil, time.Time{}, now.Add(-time.Minute), http.StatusServiceUnavailable, http.StatusOK, "telegram", ""},
+		{"poll at the readiness limit", nil, nil, now.Add(-time.Minute), now.Add(-time.Hour), http.StatusOK, http.StatusOK, "", ""},
+		{"poll past the readiness limit", nil, nil, now.Add(-time.Minute - time.Second), now.Add(-time.Hour), http.StatusServiceUnavailable, http.StatusOK, "telegram", ""},
+		{"poll past the liveness limit", nil, nil, now.Add(-151 * time.Second), now.Add(-time.Hour), http.StatusServiceUnavailable, http.StatusServiceUnavailable, "telegram", "telegram"},
+		{"never polled since long ago", nil, nil, time.Time{}, now.Add(-time.Hour), http.StatusServiceUnavailable, http.StatusServiceUnavailable, "telegram", "telegram"},
+	}
+
+	for _, tt := range tests {
+		poller := &stubPoller{lastPoll: tt.lastPoll}
+		s := NewServer("", stubPinger{tt.db}, poller, stubTOTP{tt.totp}, logger)
+		s.now = func() time.Time { return now }
+		s.started = tt.started
+
+		code, response := probe(t, s, "/readyz")
+		checkProbe(t, tt.name+" /readyz", code, response, tt.ready, tt.unhealthy)
+		code, response = probe(t, s, "/healthz")
+		checkProbe(t, tt.name+" /healthz", code, response, tt.alive, tt.liveFailed)
+	}
+}
+
+// checkProbe checks a probe's status code and that only the failed component, if any, is unavailable
+func checkProbe(t *testing.T, name string, code int, response Response, wantCode int, failed string) {
+	t.Helper()
+
+	if code != wantCode {
+		t.Errorf("%s = %d, want %d", name, code, wantCode)
+	}
+	wantStatus := StatusOK
+	if wantCode != http.StatusOK {
+		wantStatus = StatusUnavailable
+	}
+	if response.Status != wantStatus {
+		t.Errorf("%s status %q, want %q", name, response.Status, wantStatus)
+	}
+	for _, component := range []string{"database", "telegram", "totp"} {
+		status, ok := response.Components[component]
+		if !ok {
+			t.Errorf("%s lacks the %s component", name, component)
+			continue
+		}
+		if unhealthy := component == failed; unhealthy != (status.Status == StatusUnavailable) {
+			t.Errorf("%s %s = %+v", name, component, status)
+		}
+		if status.Status == StatusUnavailable && status.Error == "" {
+			t.Errorf("%s %s is unavailable without an error", name, component)
+		}
+	}
+}
+
+func TestReadinessFollowsPolling(t *testing.T) {
+	poller := &stubPoller{}
+	s := NewServer("", stubPinger{}, poller, stubTOTP{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if code, _ := probe(t, s, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz before the first poll = %d, want 503", code)
+	}
+
+	// The polling loop records its polls while probes read them
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			poller.set(time.Now())
+		}
+	}()
+	for range 10 {
+		probe(t, s, "/readyz")
+	}
+	wg.Wait()
+
+	code, response := probe(t, s, "/readyz")
+	if code != http.StatusOK || response.Components["telegram"].LastPoll == nil {
+		t.Errorf("/readyz after a poll = %d, %+v", code, response)
+	}
+}
+
+func TestMetricsServedWithProbes(t *testing.T) {
+	s := NewServer("", stubPinger{}, &stubPoller{}, stubTOTP{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	recorder := httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "# TYPE attendance_bot_updates_received_total counter") {
+		t.Errorf("/metrics = %d:\n%s", recorder.Code, recorder.Body.String())
+	}
+}
@@ -0,0 +1,967 @@
+package i18n
+
+// english translates the Indonesian catalog
+var english = map[string]string{
+
+	// Durations
+	"duration.hours_minutes": `{{.Hours}} h {{.Minutes}} min`,
+	"duration.minutes":       `{{.Minutes}} min`,
+	"duration.invalid":       `⚠️ invalid`,
+	"duration.implausible":   `> 24 h ⚠️`,
+
+	// Marking attendance
+	"otp.invalid_format":  `❌ Invalid OTP format. Please enter {{.Digits}} digits.`,
+	"otp.rejected":        `❌ The OTP is invalid or has expired. Please try again with a new code.`,
+	"otp.previous_secret": `⚠️ Your code still uses the old secret. Please scan the new QR code before {{.Until}}.`,
+	"otp.locked_out":      `🔒 Too many wrong codes. Please try again in {{.Minutes}} minutes (at {{.Until}}) or ask an admin to unlock you.`,
+	"bypass.used":         `❌ This bypass code has already been used. Each code works only once.`,
+	"bypass.expired":      `❌ The bypass code has expired. Please ask an admin for a new one.`,
+	"bypass.recorded":     `🔑 Recorded with a bypass code. Please contact an admin soon to set up your authenticator again.`,
+	"attendance.checked_in": `✅ **Check-in** recorded!
+⏰ Time: {{.Time}}`,
+	"attendance.checked_out": `🏠 **Check-out** recorded!
+⏰ Time: {{.Time}}
+⌛ Work duration: {{.Duration}}`,
+	"attendance.overtime":  `⏱️ Overtime: {{.Overtime}}`,
+	"attendance.complete":  `❌ You have already checked in and out today!`,
+	"attendance.duplicate": `⚠️ Your {{.Type}} was already recorded at {{.Time}}. Check it with /status.`,
+	"kiosk.invalid_format": `❌ Invalid code format. Please enter {{.Digits}} digits.`,
+	"kiosk.rejected":       `❌ Invalid code. Use the next code that is not yet crossed out on your code sheet.`,
+
+	// Daily report
+	"report.empty_date":  `📭 Nobody recorded attendance on {{.Date}}.`,
+	"report.empty_today": `📭 Nobody has recorded attendance today yet.`,
+	"report.title": `📊 **Attendance Report**
+📅 {{.Date}}
+`,
+	"report.title_today": `📊 **Today's Attendance Report**
+📅 {{.Date}}
+`,
+	"report.check_in":      `   ⏰ In: {{.Time}}`,
+	"report.check_out":     `   🏠 Out: {{.Time}}`,
+	"report.duration":      `   ⌛ Duration: {{.Duration}}`,
+	"report.leave_heading": `🏖️ **Leave/Permission/Sick:**`,
+	"report.summary": `**Summary:**
+👥 Employees: {{.Users}}
+📝 Check-ins: {{.CheckIns}}
+🏠 Check-outs: {{.CheckOuts}}`,
+	"report.summary_leave":    `🏖️ Leave/Permission/Sick: {{.Count}}`,
+	"report.auto_checkout":    ` 🤖`,
+	"report.summary_auto":     `🤖 Automatic check-outs: {{.Count}}`,
+	"report.outside_geofence": ` 🚩`,
+	"report.summary_geofence": `🚩 Check-ins outside the office areas: {{.Count}}`,
+	"report.summary_remote":   `🏡 Working from home: {{.Count}}`,
+	"leave.type.cuti":         `Annual leave`,
+	"leave.type.izin":         `Permission`,
+	"leave.type.sakit":        `Sick leave`,
+	"report.department": `🏢 {{.Department}}
+`,
+	"report.empty_department":   `📭 Nobody from {{.Department}} recorded attendance on {{.Date}}.`,
+	"report.department_heading": `**Per department:**`,
+	"report.department_entry":   `🏢 {{.Department}}: {{.Present}} present, {{.Late}} late, {{.Leave}} on leave`,
+
+	// Who is working
+	"who.empty": `📭 Nobody is working right now.`,
+	"who.title": `👷 **Working Now**
+🕒 As of {{.Time}}
+
+`,
+	"who.entry": `   ⏰ In: {{.Time}} · ⏱️ {{.Elapsed}}`,
+	"who.total": `
+👥 Total: {{.Count}}`,
+
+	// Monthly summary
+	"monthly.title": `📆 Monthly Summary {{.Month}}
+📅 Period: {{.Start}} to {{.End}}
+`,
+	"monthly.working_days": `🗓️ Working days: {{.Days}} (without weekends and holidays)
+`,
+	"monthly.holidays": `🎌 Holidays:`,
+	"monthly.empty": `
+ℹ️ There is no attendance data for this month.`,
+	"monthly.users": `👥 Employees: {{.Count}}
+`,
+	"monthly.user": `👤 {{.Name}}
+   ✅ Present: {{.Present}} days
+   ⏰ Late: {{.Late}} days
+   🕘 Average check-in: {{.AverageCheckIn}}
+   ⏱️ Total work: {{.WorkDuration}}
+   ⚠️ No check-out: {{.MissingCheckouts}} days
+   ❌ Absent: {{.Absent}} days
+`,
+	"monthly.department": `🏢 Department: {{.Department}}
+`,
+	"monthly.departments":      `🏢 Per department:`,
+	"monthly.department_entry": `• {{.Department}}: {{.Users}} employees, {{.Present}} days present, {{.Late}} days late, {{.WorkDuration}} worked, {{.Absent}} days absent`,
+
+	// Overtime summary
+	"overtime.title": `⏱️ Overtime {{.Month}}
+📅 Period: {{.Start}} to {{.End}}
+`,
+	"overtime.threshold": `ℹ️ Counted from {{.Threshold}} past the end of the shift.
+`,
+	"overtime.empty": `
+ℹ️ No overtime in this period.`,
+	"overtime.total": `👥 Employees with overtime: {{.Count}}
+⏱️ Total overtime: {{.Overtime}}
+`,
+	"overtime.department": `🏢 {{.Department}}: {{.Overtime}}`,
+	"overtime.user":       `• {{.Name}}: {{.Overtime}} on {{.Days}} days`,
+
+	// Actions in error messages, e.g. "Terjadi kesalahan saat {{action}}"
+	"action.create_bypass":           `creating a bypass code`,
+	"action.create_report":           `creating the report`,
+	"action.create_code_sheet":       `creating the code sheet`,
+	"action.create_monthly":          `creating the monthly summary`,
+	"action.check_alias":             `checking the alias`,
+	"action.check_duplicate_names":   `checking for duplicate names`,
+	"action.process_attendance":      `processing attendance`,
+	"action.process_alias_request":   `processing the alias request`,
+	"action.load_roster":             `loading the employee list`,
+	"action.add_admin":               `adding the admin`,
+	"action.find_missing_checkouts":  `looking for missing check-outs`,
+	"action.create_qr_code":          `creating the QR code`,
+	"action.rotate_secret":           `rotating the secret`,
+	"action.get_rotation_recipients": `looking up the users to notify`,
+	"action.enroll_employee":         `enrolling the employee`,
+	"action.get_alias":               `getting the alias`,
+	"action.get_admins":              `getting the admin list`,
+	"action.get_on_shift":            `getting the employees at work`,
+	"action.get_shifts":              `getting the shift list`,
+	"action.get_attendance":          `getting attendance data`,
+	"action.get_leave":               `getting leave data`,
+	"action.get_summary":             `getting the attendance summary`,
+	"action.get_data":                `getting the data`,
+	"action.get_subscription":        `getting the subscription`,
+	"action.get_leave_requests":      `getting the requests`,
+	"action.get_alias_requests":      `getting alias requests`,
+	"action.get_history":             `getting the history`,
+	"action.archive":                 `archiving data`,
+	"action.assign_shift":            `assigning the shift`,
+	"action.check_status":            `checking the status`,
+	"action.remove_admin":            `removing the admin`,
+	"action.remove_subscription":     `removing the subscription`,
+	"action.delete_shift":            `deleting the shift`,
+	"action.calculate_duration":      `calculating work duration`,
+	"action.save_alias":              `saving the alias`,
+	"action.save_subscription":       `saving the subscription`,
+	"action.save_reminder":           `saving your reminders`,
+	"action.get_reminder":            `getting your reminder settings`,
+	"action.get_weekly":              `getting your weekly summary setting`,
+	"action.set_weekly":              `changing your weekly summary setting`,
+	"action.attach_photo":            `attaching the photo`,
+	"action.get_photo":               `getting the photo`,
+	"action.share_location":          `sharing the location`,
+	"action.save_geofence":           `saving the area`,
+	"action.get_geofences":           `getting the areas`,
+	"action.delete_geofence":         `deleting the area`,
+	"action.fix_attendance":          `correcting the attendance`,
+	"action.get_audit_log":           `getting the audit log`,
+	"action.unlock_otp":              `unlocking the user`,
+	"action.register_group":          `registering the group`,
+	"action.unregister_group":        `unregistering the group`,
+	"action.register_employee":       `registering the employee`,
+	"action.unregister_employee":     `unregistering the employee`,
+	"action.get_employees":           `getting the employee directory`,
+	"action.get_overtime":            `getting the overtime summary`,
+	"action.get_holidays":            `getting the holidays`,
+	"action.save_holiday":            `saving the holiday`,
+	"action.delete_holiday":          `removing the holiday`,
+	"action.import_holidays":         `importing the national holidays`,
+	"action.import_attendance":       `importing the attendance`,
+	"action.backup":                  `backing up the database`,
+	"action.get_backups":             `listing the backups`,
+	"action.get_departments":         `getting the departments`,
+	"action.start_conversation":      `starting the conversation`,
+	"action.save_leave_request":      `saving the request`,
+	"action.save_wfh_request":        `saving the work from home request`,
+	"action.save_break":              `recording your break`,
+	"action.cancel_wfh_request":      `cancelling working from home`,
+	"action.get_wfh_requests":        `getting the work from home requests`,
+	"action.save_alias_request":      `saving the alias request`,
+	"action.save_shift":              `saving the shift`,
+	"action.set_language":            `setting the language`,
+	"action.get_timezone":            `getting your timezone`,
+	"action.set_timezone":            `setting your timezone`,
+
+	// Error replies
+	"error.invalid_date_range":     `❌ Invalid date range. Use the YYYY-MM-DD format and make sure the start date is not after the end date.`,
+	"error.not_found":              `❌ Data not found.`,
+	"error.invalid_secret":         `⚠️ The bot is misconfigured (invalid OTP secret). Please contact the admin.`,
+	"error.encryption_key_missing": `⚠️ The bot is misconfigured (encryption key not set). Please contact the admin.`,
+	"error.storage":                `❌ The database had a problem while {{.Action}}. Please try again in a moment.`,
+	"error.timeout":                `⏱️ Timed out while {{.Action}}. Please try again in a moment.`,
+	"error.generic":                `❌ Something went wrong while {{.Action}}. Please try again.`,
+	"error.reference": `
+
+Reference code: {{.RequestID}}`,
+
+	// Shared messages
+	"common.button_expired":  `This button is no longer valid.`,
+	"common.page":            `📄 Page {{.Page}} of {{.Pages}}`,
+	"common.page_previous":   `◀️ Previous`,
+	"common.page_next":       `Next ▶️`,
+	"common.admin_only":      `❌ This command is only available to admins.`,
+	"common.invalid_user_id": `❌ Invalid user ID.`,
+	"common.invalid_format":  `❌ Invalid format. Use:`,
+
+	// /timezone
+	"timezone.office":  `🕐 Your times follow the office timezone, {{.Timezone}}.`,
+	"timezone.current": `🕐 Your timezone: {{.Timezone}} (office: {{.Office}})`,
+	"timezone.usage": `/timezone [Area/City] - Work in another timezone, e.g. /timezone Asia/Makassar or /timezone Europe/Berlin
+/timezone reset - Follow the office timezone again
+Your check-ins, check-outs, shift hours and reminders use your timezone; reports stay in the office timezone.`,
+	"timezone.set":     `✅ Timezone changed to {{.Timezone}}. It is {{.Time}} there now.`,
+	"timezone.reset":   `✅ Your times follow the office timezone, {{.Timezone}}, again.`,
+	"timezone.invalid": `❌ Unknown timezone "{{.Timezone}}". Use an IANA name such as Asia/Jayapura or America/New_York.`,
+
+	// /language
+	"language.current": `🌐 Your language: {{.Language}}
+
+Pick a language, or Auto to follow the language of your Telegram app.`,
+	"language.set":         `✅ Language changed to {{.Language}}.`,
+	"language.auto":        `✅ The language follows your Telegram app ({{.Language}}).`,
+	"language.unsupported": `❌ Unsupported language. Pick one of: {{.Languages}}, or auto.`,
+	"language.failed":      `Failed to change the language. Please try again.`,
+
+	// /shift
+	"shift.usage": `/shift list
+/shift set [name] [start HH:MM] [end HH:MM] [grace minutes]
+/shift delete [name]
+/shift assign [user_id] [name]
+/shift unassign [user_id]
+
+The shift named "default" applies to employees without a shift.`,
+	"shift.invalid_grace":     `❌ The grace period must be a number of minutes.`,
+	"shift.list_title":        `🕘 Shifts`,
+	"shift.list_entry":        `• {{.Shift}}, {{.Users}} employees`,
+	"shift.list_builtin":      `• {{.Shift}}, built in for employees without a shift`,
+	"shift.invalid":           `❌ Invalid shift. Names may only contain lowercase letters, digits and hyphens; times use the HH:MM format with the start different from the end; the grace period is 0-{{.MaxGrace}} minutes.`,
+	"shift.saved":             `✅ Shift saved: {{.Shift}}`,
+	"shift.in_use":            `❌ Shift {{.Name}} still has employees. Move them with /shift assign or /shift unassign first.`,
+	"shift.not_found":         `ℹ️ Shift {{.Name}} not found.`,
+	"shift.deleted":           `🗑️ Shift {{.Name}} deleted.`,
+	"shift.unknown":           `❌ Shift {{.Name}} not found. See the shifts with /shift list.`,
+	"shift.assigned_notice":   `🕘 Your shift is now: {{.Shift}}`,
+	"shift.assigned":          `✅ User ID {{.UserID}} is now on shift {{.Name}}.`,
+	"shift.not_assigned":      `ℹ️ User ID {{.UserID}} has no assigned shift.`,
+	"shift.unassigned":        `✅ User ID {{.UserID}} is back on the default shift.`,
+	"shift.starts_at":         `starts {{.Start}}`,
+	"shift.grace":             `, {{.Minutes}} minutes grace`,
+	"common.super_admin_only": `❌ This command is only available to the super-admin.`,
+	"common.admin_chat_only":  `❌ This command is only available in the admin chat.`,
+
+	// /admin
+	"admin.usage":            `/admin add [user_id], /admin remove [user_id] or /admin list`,
+	"admin.is_super_admin":   `ℹ️ This user is the super-admin; their rights are set with SUPER_ADMIN_ID.`,
+	"admin.already_admin":    `ℹ️ User ID {{.UserID}} is already an admin.`,
+	"admin.added_notice":     `🛡️ You are now an Attendance Bot admin and can use /fullreport.`,
+	"admin.added":            `✅ User ID {{.UserID}} is now an admin.`,
+	"admin.not_admin":        `ℹ️ User ID {{.UserID}} is not an admin.`,
+	"admin.removed":          `🗑️ User ID {{.UserID}} is no longer an admin.`,
+	"admin.list_title":       `🛡️ Admins`,
+	"admin.list_super_admin": `• user ID {{.UserID}} (super-admin)`,
+	"admin.list_entry":       `• user ID {{.UserID}}, added {{.AddedAt}} by {{.AddedBy}}`,
+	"admin.list_empty":       `There are no other admins yet. Add one with /admin add [user_id].`,
+
+	// Employee directory
+	"employee.register_usage": `Format: /registeruser [user_id] [employee ID] [department]
+Example: /registeruser 123456789 EMP-0042 Finance
+Registering a user again changes their employee ID and department.`,
+	"employee.unregister_usage":   `Format: /unregisteruser [user_id]`,
+	"employee.invalid_id":         `❌ Invalid employee ID. Use up to 32 letters, digits, ".", "_", "/" or "-", starting with a letter or digit.`,
+	"employee.invalid_department": `❌ Invalid department name. Use at most 40 letters, digits, spaces or & . , ' / ( ) -, starting with a letter or digit.`,
+	"employee.id_taken":           `❌ Employee ID {{.EmployeeID}} is already registered to another user.`,
+	"employee.registered":         `✅ User ID {{.UserID}} registered as {{.Employee}}.`,
+	"employee.updated":            `✅ Entry of user ID {{.UserID}} changed to {{.Employee}}.`,
+	"employee.registered_notice":  `✅ You are registered as employee {{.EmployeeID}}. You can now send your OTP to record attendance.`,
+	"employee.unregistered":       `🗑️ User ID {{.UserID}} removed from the employee directory.`,
+	"employee.not_found":          `User ID {{.UserID}} is not in the employee directory.`,
+	"employee.with_department":    `{{.EmployeeID}} ({{.Department}})`,
+	"employee.list_title":         `👥 Employee directory ({{.Count}})`,
+	"employee.list_entry":         `• {{.EmployeeID}}: user ID {{.UserID}}`,
+	"employee.list_empty":         `The employee directory is empty. Add employees with /registeruser.`,
+	"employee.no_department":      `No department`,
+	"employee.not_required":       `ℹ️ Registration is not required yet, everyone may record attendance (REQUIRE_REGISTRATION).`,
+	"employee.not_registered": `👋 You are not registered as an employee yet, so your attendance cannot be recorded.
+Send your Telegram ID {{.UserID}} to an admin to be registered.`,
+	"employee.unknown_department": `❌ There is no department {{.Department}}. Departments: {{.Departments}}`,
+	"employee.no_departments":     `ℹ️ No employee has a department yet. Admins set one with /registeruser.`,
+
+	// /holiday
+	"holiday.usage": `/holiday [list [YYYY]]
+/holiday add [YYYY-MM-DD] [name]
+/holiday remove [YYYY-MM-DD]
+/holiday import [YYYY]
+
+Example: /holiday add 2025-01-01 "New Year"
+Holidays and weekends are not working days: nobody is counted absent on them.`,
+	"holiday.list_title":      `📅 Holidays {{.Year}}`,
+	"holiday.list_empty":      `No holidays yet.`,
+	"holiday.source.import":   `(national)`,
+	"holiday.source.config":   `(HOLIDAYS)`,
+	"holiday.added":           `✅ Holiday added: {{.Holiday}}`,
+	"holiday.renamed":         `✅ Holiday renamed: {{.Holiday}}`,
+	"holiday.removed":         `🗑️ Holiday on {{.Date}} removed.`,
+	"holiday.not_found":       `There is no holiday on {{.Date}} to remove. Holidays of the HOLIDAYS setting can only be removed there.`,
+	"holiday.imported":        `✅ National holidays of {{.Year}} imported: {{.Added}} added, {{.Skipped}} already on the calendar.`,
+	"holiday.import_disabled": `ℹ️ Importing national holidays is turned off (HOLIDAY_FEED_URL). Add holidays with /holiday add.`,
+
+	// /import
+	"import.usage": `Send a CSV file with /import as its caption, or reply /import to one. Add dryrun to only check it.
+Columns: user_id,name,date,type,time
+user_id is a Telegram user ID or an employee ID; leave it empty to find the registered employee by name.
+Example row: ,Budi Santoso,2025-01-06,check_in,08:02`,
+	"import.too_large":       `❌ The file is too large to import, the limit is {{.MB}} MB. Split it or use the import CLI.`,
+	"import.invalid_file":    `❌ This is not an attendance CSV file. The first row must be the header {{.Columns}}.`,
+	"import.dry_run":         `🔍 Dry run, nothing was saved: {{.Rows}} rows, {{.Valid}} valid ({{.Resolved}} matched by name or employee ID), {{.Errors}} invalid.`,
+	"import.done":            `✅ Imported {{.Rows}} rows: {{.Inserted}} added, {{.Duplicates}} already recorded, {{.Errors}} invalid.`,
+	"import.rejects_caption": `The rows that were not imported are attached with the reason. Correct them and import the file again.`,
+
+	// /backup
+	"backup.usage": `/backup now - Back up the database now
+/backup list - List the backups kept`,
+	"backup.disabled":         `ℹ️ Only SQLite databases are backed up by the bot. Back up PostgreSQL with pg_dump.`,
+	"backup.running":          `⏳ A backup is already running. Please try again in a moment.`,
+	"backup.done":             `✅ Backup {{.Name}} written ({{.Size}}). Old backups removed: {{.Removed}}.`,
+	"backup.uploaded":         `☁️ The backup was uploaded to the backup bucket.`,
+	"backup.incomplete":       `⚠️ Removing old backups or uploading this one failed; see the bot's logs.`,
+	"backup.list_title":       `💾 Backups ({{.Count}}, the newest {{.Keep}} are kept):`,
+	"backup.list_entry":       `• {{.Time}} - {{.Name}} ({{.Size}})`,
+	"backup.list_more":        `… and {{.Count}} older`,
+	"backup.list_empty":       `No backups yet. Make one with /backup now.`,
+	"backup.failed_alert":     `❌ The scheduled database backup failed: {{.Error}}`,
+	"backup.incomplete_alert": `⚠️ The scheduled database backup was written, but: {{.Error}}`,
+
+	// /forgot and /bypass
+	"forgot.no_admin_chat": `ℹ️ Please contact an admin directly to get a temporary attendance code.`,
+	"forgot.alert": `🆘 {{.Name}} (ID {{.UserID}}) reported losing their authenticator app.
+
+After verifying their identity, issue a temporary code with:
+/bypass {{.UserID}}
+Or send them the QR code for their new phone with:
+/enroll {{.UserID}}`,
+	"forgot.failed": `❌ Could not reach the admins. Please contact an admin directly.`,
+	"forgot.sent":   `📨 The admins have been told. An admin will give you a temporary code in person; send it to this bot like a normal OTP. The code is valid for {{.Minutes}} minutes and works only once.`,
+	"bypass.usage":  `/bypass [user_id]`,
+	"bypass.issued": `🔑 Bypass code for user ID {{.UserID}}: {{.Code}}
+
+⏰ Valid until {{.Expires}} {{.Zone}}, for a single check-in or check-out.
+Tell the code to the employee in person. Any earlier unused code no longer works.`,
+	"bypass.used_alert":         `🔑 {{.Username}} (ID {{.UserID}}) recorded {{.Type}} at {{.Time}} with a bypass code.`,
+	"attendance.type.check_in":  `check-in`,
+	"attendance.type.check_out": `check-out`,
+
+	// Aliases
+	"alias.taken":            `❌ The name {{printf "%q" .Alias}} is already used by another employee. Please pick another name so attendance reports are not mixed up.`,
+	"alias.approval_request": `⚠️ {{.Name}} (ID {{.UserID}}) wants to use the alias {{printf "%q" .Alias}}, which matches:`,
+	"alias.approval_commands": `Approve: /aliasapprove {{.UserID}}
+Reject: /aliasreject {{.UserID}}`,
+	"alias.request_failed":  `❌ Could not reach the admins. Please try again later or use another name.`,
+	"alias.requested":       `⚠️ The name {{printf "%q" .Alias}} is similar to another employee's, so it needs an admin's approval. Your request has been forwarded; your old alias stays in use until it is approved.`,
+	"alias.no_request":      `ℹ️ There is no alias request from user ID {{.UserID}}.`,
+	"alias.rejected":        `🚫 Alias {{printf "%q" .Alias}} for user ID {{.UserID}} rejected.`,
+	"alias.rejected_notice": `🚫 Your alias request {{printf "%q" .Alias}} was rejected by an admin. Please use another name.`,
+	"alias.approved":        `✅ Alias {{printf "%q" .Alias}} for user ID {{.UserID}} approved.`,
+	"alias.approved_notice": `✅ Your alias {{printf "%q" .Alias}} was approved by an admin and is now in use.`,
+	"alias.conflicts_title": `🏷️ Duplicate Names`,
+	"alias.conflicts_empty": `No name is used by more than one employee.`,
+	"alias.pending":         `⏳ Awaiting approval:`,
+	"alias.match_telegram":  `• Telegram name of user ID {{.UserID}}: {{.Name}}`,
+	"alias.match_alias":     `• alias of user ID {{.UserID}}: {{.Name}}`,
+
+	// /whoami
+	"whoami.title":            `🪪 *Your Identity Data*`,
+	"whoami.recorded":         `The following data is recorded with each of your check-ins and check-outs:`,
+	"whoami.telegram_id":      `• Telegram ID: {{.UserID}}`,
+	"whoami.username":         `• Username: @{{.Username}}`,
+	"whoami.username_missing": `• Username: (none, recorded as {{.Username}})`,
+	"whoami.first_name":       `• First name: {{.Name}}`,
+	"whoami.last_name":        `• Last name: {{.Name}}`,
+	"whoami.alias":            `• Alias: {{.Alias}}`,
+	"whoami.language":         `• Language: {{.Language}} (change it with /language)`,
+	"whoami.not_recorded":     `Other information from this message (not recorded):`,
+	"whoami.chat_id":          `• Chat ID: {{.ChatID}}`,
+	"whoami.language_code":    `• Language code: {{.Code}}`,
+	"whoami.none":             `(none)`,
+	"whoami.not_detected":     `(not detected)`,
+	"whoami.empty":            `(empty)`,
+	"whoami.sanitized":        `{{.Name}} (on Telegram: {{.Original}})`,
+	"common.previous_button":  `◀️ Previous`,
+	"common.next_button":      `Next ▶️`,
+
+	// Kiosk
+	"kiosk.chat_only":          `❌ This command is only available in the kiosk chat.`,
+	"kiosk.enter_code":         `🔐 Enter the {{.Digits}}-digit code for {{.Name}}, or press Cancel.`,
+	"kiosk.not_enrolled":       `❌ {{.Name}} has no personal code sheet yet. Please contact an admin.`,
+	"kiosk.attempts_left":      `Attempts left: {{.Attempts}}.`,
+	"kiosk.too_many_attempts":  `⛔ Too many wrong codes. Please pick your name again, or contact an admin if you lost your code sheet.`,
+	"kiosk.button_elsewhere":   `This button only works on the kiosk.`,
+	"kiosk.roster_failed":      `Failed to load the employee list.`,
+	"kiosk.pick_failed":        `Failed to pick the employee. Please try again.`,
+	"kiosk.employee_not_found": `Employee not found on the kiosk roster.`,
+	"kiosk.prompt": `🔐 *{{.Name}}*
+Enter the next code from your personal code sheet.`,
+	"kiosk.cancel_button":   `❎ Cancel`,
+	"kiosk.cancelled_toast": `Cancelled`,
+	"kiosk.cancelled":       `❎ Cancelled.`,
+	"kiosk.roster_empty": `🏢 *Kiosk Attendance*
+
+No employees are enrolled for the kiosk yet. Admins can enroll employees with /kioskenroll.`,
+	"kiosk.roster": `🏢 *Kiosk Attendance*
+
+Pick your name, then enter the next code from your personal code sheet.
+Page {{.Page}} of {{.Pages}}`,
+	"kiosk.enroll_usage":           `/kioskenroll [user_id]`,
+	"kiosk.encryption_key_missing": `❌ SECRETS_ENCRYPTION_KEY is not set, so personal codes cannot be created.`,
+	"kiosk.sheet_title":            `📄 Personal code sheet for user ID {{.UserID}}`,
+	"kiosk.sheet_instructions":     `Print it and hand it to the employee in person. Codes are used in order, each once; the previous sheet no longer works. Set the name shown on the kiosk with an alias if the employee does not use Telegram.`,
+
+	// /leave
+	"leave.usage": `/leave [cuti|izin|sakit] [start date] [end date] [reason]
+
+cuti is annual leave, izin is permitted absence and sakit is sick leave. The end date may be left out for a single day.
+Example: /leave cuti 2025-03-10 2025-03-12 Family event
+Or type /leave new to be asked step by step.`,
+	"leave.ask_type": `🏖️ New leave request. Which type is it? Reply cuti (annual leave), izin (permitted absence) or sakit (sick leave).
+
+Send any command to cancel.`,
+	"leave.ask_dates":         `📅 Reply with the first and last day in the format YYYY-MM-DD YYYY-MM-DD, or with one date for a single day.`,
+	"leave.ask_reason":        `✏️ Finally, reply with the reason (at most {{.Max}} characters).`,
+	"leave.invalid_start":     `❌ Invalid start date. Make sure the date format is correct (YYYY-MM-DD).`,
+	"leave.reason_too_long":   `❌ The reason is too long (at most {{.Max}} characters).`,
+	"leave.unknown_type":      `❌ Unknown request type. Pick one of: cuti, izin or sakit.`,
+	"leave.invalid_range":     `❌ Invalid date range. The start date may not be after the end date.`,
+	"leave.too_long":          `❌ A single request covers at most {{.Days}} days.`,
+	"leave.too_old":           `❌ The start date may be at most {{.Days}} days ago.`,
+	"leave.overlap":           `❌ You already have a leave request on those dates. Type /leave to see it.`,
+	"leave.admin_unreachable": `⚠️ Request #{{.ID}} was saved, but the admins could not be reached. Please tell an admin directly.`,
+	"leave.requested":         `📝 {{.Type}} request #{{.ID}} ({{.Period}}) was sent to the admins. You will be notified once it is decided.`,
+	"leave.list_title":        `🏖️ Your requests:`,
+	"leave.list_entry":        `• #{{.ID}} {{.Type}}, {{.Period}}: {{.Status}}`,
+	"leave.status.pending":    `⏳ awaiting approval`,
+	"leave.status.approved":   `✅ approved`,
+	"leave.status.rejected":   `🚫 rejected`,
+	"leave.approval_request": `🏖️ {{.Type}} request #{{.ID}}
+
+Name: {{.Name}} (@{{.Username}}, ID {{.UserID}})
+Dates: {{.Period}}
+Reason: {{.Reason}}`,
+	"leave.approve_button":   `✅ Approve`,
+	"leave.reject_button":    `🚫 Reject`,
+	"leave.admin_only":       `Only admins can decide on this request.`,
+	"leave.decide_failed":    `Failed to process the request. Please try again.`,
+	"leave.not_found":        `Request not found.`,
+	"leave.already_approved": `This request was already approved.`,
+	"leave.already_rejected": `This request was already rejected.`,
+	"leave.outcome_approved": `✅ Approved`,
+	"leave.outcome_rejected": `🚫 Rejected`,
+	"leave.decided_by":       `{{.Outcome}} by {{.Name}}`,
+	"leave.decided_notice":   `{{.Outcome}}: {{.Type}} request #{{.ID}} ({{.Period}}).`,
+	"leave.period":           `{{.Start}} to {{.End}}`,
+
+	// /break
+	"break.usage": `/break start
+/break end
+
+Breaks are not counted as work. Start one after checking in and end it before checking out.`,
+	"break.disabled":        `❌ Breaks are not recorded in this company.`,
+	"break.not_checked_in":  `❌ You have not checked in today. Send your OTP to check in first.`,
+	"break.checked_out":     `❌ You already checked out today.`,
+	"break.already_started": `☕ You are already on a break. Type /break end when you are back.`,
+	"break.not_started":     `❌ You are not on a break. Type /break start to start one.`,
+	"break.limit":           `❌ You already took {{.Max}} breaks today, the most allowed.`,
+	"break.started":         `☕ Break {{.Count}} of {{.Max}} started at {{.Time}}. Type /break end when you are back.`,
+	"break.ended":           `✅ Break ended at {{.Time}}. Breaks today: {{.Total}}, not counted as work.`,
+
+	// /wfh
+	"wfh.usage": `/wfh [YYYY-MM-DD]
+/wfh cancel [YYYY-MM-DD]
+/wfh list
+
+Without a date, today. Attendance you record on a day you work from home is marked 🏡 remote.`,
+	"wfh.invalid_date":      `❌ Pick today or a day up to {{.Days}} days ahead (YYYY-MM-DD).`,
+	"wfh.exists":            `You already asked to work from home on {{.Date}}: {{.Status}}. Type /wfh cancel {{.Date}} to withdraw it.`,
+	"wfh.approved_now":      `🏡 You work from home on {{.Date}}. Your attendance that day is marked remote.`,
+	"wfh.requested":         `📝 Request #{{.ID}} to work from home on {{.Date}} was sent to the admins. You will be notified once it is decided.`,
+	"wfh.admin_unreachable": `⚠️ Request #{{.ID}} was saved, but the admins could not be reached. Please tell an admin directly.`,
+	"wfh.cancelled":         `🗑️ Working from home on {{.Date}} cancelled.`,
+	"wfh.not_found_date":    `You did not ask to work from home on {{.Date}}.`,
+	"wfh.list_title":        `🏡 Your days working from home:`,
+	"wfh.list_entry":        `• {{.Date}}: {{.Status}}`,
+	"wfh.list_empty":        `You have no upcoming days working from home.`,
+	"wfh.status.pending":    `⏳ awaiting approval`,
+	"wfh.status.approved":   `✅ approved`,
+	"wfh.status.rejected":   `🚫 rejected`,
+	"wfh.approval_request": `🏡 Work from home request #{{.ID}}
+
+Name: {{.Name}} (@{{.Username}}, ID {{.UserID}})
+Date: {{.Date}}`,
+	"wfh.approve_button":   `✅ Approve`,
+	"wfh.reject_button":    `🚫 Reject`,
+	"wfh.admin_only":       `Only admins can decide on this request.`,
+	"wfh.decide_failed":    `Failed to process the request. Please try again.`,
+	"wfh.not_found":        `Request not found.`,
+	"wfh.already_approved": `This request was already approved.`,
+	"wfh.already_rejected": `This request was already rejected.`,
+	"wfh.outcome_approved": `✅ Approved`,
+	"wfh.outcome_rejected": `🚫 Rejected`,
+	"wfh.decided_by":       `{{.Outcome}} by {{.Name}}`,
+	"wfh.decided_notice":   `{{.Outcome}}: request #{{.ID}} to work from home on {{.Date}}.`,
+
+	// /subscribe
+	"digest.daily":              `Daily attendance report`,
+	"digest.weekly":             `Weekly attendance summary`,
+	"digest.unknown":            `❌ Report {{printf "%q" .Digest}} is not available. Type /subscribe to see the reports.`,
+	"digest.already_subscribed": `ℹ️ You are already subscribed to the {{.Digest}} report.`,
+	"digest.subscribed":         `✅ Subscribed to the {{.Digest}} report. {{.Note}}`,
+	"digest.unsubscribe_usage": `/unsubscribe [report name]
+
+Example: /unsubscribe daily`,
+	"digest.not_subscribed": `ℹ️ You are not subscribed to the {{.Digest}} report.`,
+	"digest.unsubscribed":   `✅ Unsubscribed from the {{.Digest}} report.`,
+	"digest.private_only":   `ℹ️ Please send /subscribe in a private chat with the bot so reports are delivered to you directly.`,
+	"digest.refused":        `🙏 Sorry, company report subscriptions are only available to admins and supervisors. You can still see your own attendance with /status and /history.`,
+	"digest.list_title": `📬 Report Subscriptions
+
+Available reports:`,
+	"digest.status_subscribed":  `✅ subscribed`,
+	"digest.status_coming_soon": `(coming soon)`,
+	"digest.none":               `You are not subscribed to any report yet.`,
+	"digest.list_usage":         `Use /subscribe [name] to subscribe and /unsubscribe [name] to stop.`,
+	"digest.daily_schedule":     `The daily report is sent every day at {{.Clock}} {{.Zone}}.`,
+	"digest.delivery_disabled":  `Scheduled delivery is currently turned off by an admin.`,
+	"digest.delivery_daily":     `The report will be sent every day at {{.Clock}} {{.Zone}}.`,
+	"monthly.usage": `/monthly [YYYY-MM] [department]
+
+Example: /monthly 2025-01 or /monthly 2025-01 Finance
+Without a month, the current month is summarized; without a department, everyone by department.`,
+	"monthly.future": `❌ That month has not started yet.`,
+	"overtime.usage": `/overtime [YYYY-MM] [department]
+
+Example: /overtime 2025-01 or /overtime 2025-01 Finance
+Without a month, the current month is shown; without a department, everyone by department.`,
+
+	// Admin alerts
+	"panic.alert": `🚨 The bot panicked and recovered.
+
+Location: {{.Site}}
+Message: {{.Value}}
+Request ID: {{.RequestID}}
+
+Further alerts for the same location are held back for 1 hour.`,
+
+	// Live report
+	"live_report.closed":   `🔒 Report closed at {{.Clock}} {{.Zone}}.`,
+	"live_report.updated":  `🔄 Updated automatically, last at {{.Clock}} {{.Zone}}.`,
+	"common.stale_command": `⏳ Sorry, the bot has just come back online. Your earlier command was not processed, please send it again.`,
+
+	// Messages mirrored to Slack and Discord
+	"mirror.late_arrival": `⏰ {{.Name}} is late: checked in at {{.Time}} {{.Zone}}, shift {{.Shift}} starts at {{.Start}}.`,
+
+	// Late arrival alerts to the late alert chat
+	"late_alert.instant":      `⏰ *{{.Name}}* is late: checked in at {{.Time}} {{.Zone}}, shift {{.Shift}} starts at {{.Start}}.`,
+	"late_alert.digest_title": `⏰ *Late arrivals {{.Date}}* ({{.Count}})`,
+	"late_alert.digest_line":  `*{{.Name}}* checked in at {{.Time}} {{.Zone}}, shift {{.Shift}} starts at {{.Start}}`,
+
+	// /start and /help
+	"start.welcome": `🎯 *Welcome to Attendance Bot!*
+
+To check in or out, send your {{.Digits}}-digit OTP code.
+
+*Available Commands:*
+📝 Send an OTP - Check in/out
+📊 /report - See today's attendance report
+📈 /history - See your attendance history
+🏷️ /alias - Check in under another name
+🔄 /status - Check today's attendance status
+⏱️ /duration - See how long you have worked today
+☕ /break - Start or end a break
+👷 /who - See who is at work
+🏖️ /leave - Request annual leave, permission or sick leave
+🏡 /wfh - Work from home today
+📋 /fullreport - Download the full report (CSV/Excel/PDF, admins only)
+🌐 /language - Change the bot's language
+🕐 /timezone - Work in another timezone
+❓ /help - Show this help message
+
+*Attendance System:*
+• First entry = Check-in
+• Second entry = Check-out`,
+	"help.message": `❓ *Attendance Bot Help*
+
+*How to use:*
+1. Get an OTP from your authenticator app
+2. Send the {{.Digits}}-digit code to this bot
+3. The bot decides automatically whether it is a check-in or a check-out
+
+*Attendance System:*
+• First entry of the day = *Check-in*
+• Second entry of the day = *Check-out*
+
+*Commands:*
+📊 /report - See today's attendance report
+   Per department: /report [department]
+📈 /history [YYYY-MM | YYYY-MM-DD YYYY-MM-DD] - See your attendance history (last 30 days) and export it as CSV
+🔄 /status - Check today's attendance status (in/out)
+⏱️ /duration - See how long you have worked today
+☕ /break start, /break end - Take a break, which is not counted as work
+👷 /who - See employees who checked in but not out yet
+🏖️ /leave - Request annual leave (cuti), permission (izin) or sick leave (sakit) for admin approval
+   Format: /leave [cuti|izin|sakit] [start date] [end date] [reason]
+   Example: /leave sakit 2025-03-10 Fever
+🏡 /wfh - Work from home today, or /wfh [YYYY-MM-DD] on a later day; your attendance is marked remote
+   Cancel: /wfh cancel [YYYY-MM-DD]; list: /wfh list
+🏷️ /alias - Use a nickname/alias for attendance
+   Format: /alias [First Name] [Last Name]
+   Example: /alias John Doe
+📋 /fullreport - Download the full report as CSV, Excel or a PDF summary (admins only)
+   Format: Enter a date range (YYYY-MM-DD YYYY-MM-DD)
+   Per department: /fullreport [YYYY-MM-DD] [YYYY-MM-DD] [csv|xlsx|pdf] [department]
+📆 /monthly - Monthly summary per employee: present, late, average check-in, total work (admins only)
+   Format: /monthly [YYYY-MM] [department], without a month for the current month
+⏱️ /overtime - Overtime per employee for payroll (admins only)
+   Format: /overtime [YYYY-MM] [department], without a month for the current month
+🕘 /shift - Manage shift hours and their employees (admins only)
+📍 /geofence - Manage the office areas check-ins are expected from (admins only)
+🛠️ /fix - Add, change or delete an employee's check-in or check-out (admins only)
+   Format: /fix [user ID] [YYYY-MM-DD] [in|out] [HH:MM|delete] [reason]
+🧾 /auditlog - See the latest sensitive operations (admins only)
+   Export: /auditlog csv [YYYY-MM-DD] [YYYY-MM-DD]
+🪪 /whoami - See the identity data the bot records
+🌐 /language - Change the bot's language (Indonesia/English)
+🕐 /timezone - Set your own timezone, e.g. /timezone Asia/Makassar; reset: /timezone reset
+🔔 /remind - Reminders before your shift starts and when it ends
+   Format: /remind on [minutes before the shift], /remind off
+📅 /weekly - The summary of your past week you get every week; stop it with /weekly off
+📸 Send a selfie after checking in as proof of presence (when enabled)
+📍 Share your location before sending your OTP code to record where you are
+🆘 /forgot - Tell the admins if you lost your phone/authenticator app
+📬 /subscribe - Subscribe to the daily report (admins/supervisors only)
+   Stop: /unsubscribe daily
+👥 /register - In a group: make it an office channel for the daily report and OTPs (admins only)
+   Stop: /unregister
+🪪 /registeruser - Add an employee to the directory with their employee ID and department (admins only)
+   Format: /registeruser [user ID] [employee ID] [department]; remove: /unregisteruser [user ID]; list: /employees
+📅 /holiday - Holidays, which are not working days (admins only)
+   Format: /holiday add [YYYY-MM-DD] [name]; remove: /holiday remove [YYYY-MM-DD]; national holidays: /holiday import [YYYY]
+📥 /import - Import historical attendance from a CSV file sent with the command (admins only)
+   Check without saving: /import dryrun
+💾 /backup now - Back up the database; list the backups: /backup list (admins only)`,
+	"common.unknown_command": `❓ Unknown command. Type /help to see the commands.`,
+	"common.rate_limited":    `⏳ You are sending commands too quickly. Please wait a minute before trying again.`,
+	"common.command_failed":  `❌ Something went wrong while running {{.Command}}. Please try again later.`,
+	"report.refresh_button":  `🔄 Refresh`,
+	"report.invalid_date":    `Invalid report date.`,
+	"report.failed":          `Failed to create the report.`,
+
+	// /history, /status and /duration
+	"history.empty":     `📭 No attendance in the last 30 days.`,
+	"history.not_yours": `This is someone else's history. Send /history to see yours.`,
+	"history.failed":    `Failed to get your attendance history.`,
+	"history.usage": `/history - The last 30 days
+/history YYYY-MM - A month
+/history YYYY-MM-DD YYYY-MM-DD - A date range
+
+Example: /history 2025-01 or /history 2025-01-01 2025-01-31`,
+	"history.start_after_end": `❌ The start date may not be after the end date.`,
+	"history.range_too_long":  `❌ The date range may be at most {{.Days}} days.`,
+	"history.empty_range":     `📭 No attendance from {{.Start}} to {{.End}}.`,
+	"history.title_range":     `📈 *Your Attendance History ({{.Start}} to {{.End}})*`,
+	"history.export_button":   `📥 Export CSV`,
+	"history.exporting":       `⏳ Creating the CSV file...`,
+	"history.export_caption":  `📈 Your attendance from {{.Start}} to {{.End}} ({{.Days}} days)`,
+	"status.none": `❌ *Attendance Status*
+
+You have not checked in today.
+Send your OTP to *check in*.`,
+	"status.checked_in": `🟡 *Attendance Status*
+
+✅ Check-in: {{.CheckIn}}
+❌ Check-out: Not yet
+
+Send your OTP to *check out*.`,
+	"status.complete": `✅ *Attendance Status*
+
+✅ Check-in: {{.CheckIn}}
+✅ Check-out: {{.CheckOut}}
+⌛ Work duration: {{.Duration}}
+
+Today's attendance is complete.`,
+	"status.breaks":           `☕ Breaks: {{.Count}} ({{.Duration}}, not counted as work)`,
+	"status.on_break":         `☕ You are on a break. Type /break end when you are back.`,
+	"duration.not_checked_in": `❌ You have not checked in today. Send your OTP to check in.`,
+	"duration.checked_out": `✅ You checked out at {{.CheckOut}}.
+⌛ Today's work duration: {{.Duration}}`,
+	"duration.elapsed":         `⏱️ Working for {{.Elapsed}} (checked in at {{.CheckIn}})`,
+	"duration.remaining":       `, {{.Remaining}} left to the target`,
+	"duration.target_reached":  `, the working hours target is reached 🎉`,
+	"duration.breaks":          `☕ {{.Breaks}} of breaks not counted`,
+	"alias.usage":              `/alias [First Name] [Last Name]`,
+	"alias.invalid_first_name": `❌ Invalid first name.`,
+	"alias.set":                `✅ Alias set: {{.Alias}}`,
+
+	// /fullreport
+	"fullreport.prompt":           "📊 *Full Attendance Report*\n\nPick a period with the buttons below, or enter a date range in the format:\n`YYYY-MM-DD YYYY-MM-DD`\n\n*Example:*\n`2025-01-01 2025-01-31`\n\n*Note:* After picking the period, pick the report format: CSV, Excel or PDF.",
+	"fullreport.preset.7d":        `📅 Last 7 days`,
+	"fullreport.preset.month":     `🗓️ This month`,
+	"fullreport.preset.lastmonth": `⏮️ Last month`,
+	"fullreport.admin_only_toast": `The full report is only available to admins.`,
+	"fullreport.progress":         `⏳ Creating the {{.Format}} report {{.Start}} to {{.End}}... Please wait.`,
+	"otp.forwarded":               `⛔ Forwarded OTP codes are not accepted. Type the code from your authenticator app yourself.`,
+	"otp.stale":                   `⏳ Your OTP message arrived too late. Please send the latest code from your authenticator app.`,
+	"otp.failure_alert":           `🚨 Security alert: {{.Username}} (ID {{.UserID}}) entered a wrong OTP {{.Count}} times in the last {{.Minutes}} minutes.`,
+	"otp.lockout_alert": `🔒 Security alert: {{.Username}} (ID {{.UserID}}) is locked out until {{.Until}} after {{.Count}} wrong OTPs since {{.Since}}.
+Unlock early with /otpunlock {{.UserID}}`,
+
+	// /otpfailures
+	"otpfailures.usage":   `/otpfailures [hours]`,
+	"otpfailures.none":    `✅ No failed OTPs in the last {{.Hours}} hours.`,
+	"otpfailures.title":   `🔐 Failed OTPs in the last {{.Hours}} hours: {{.Count}}`,
+	"common.and_more":     `... and {{.Count}} more`,
+	"otpfailures.locked":  `🔒 Locked out now:`,
+	"otpfailures.lockout": `• ID {{.UserID}}: {{.Count}} wrong OTPs, until {{.Until}} · /otpunlock {{.UserID}}`,
+
+	// /otpunlock
+	"otpunlock.usage":      `/otpunlock [user ID]`,
+	"otpunlock.done":       `🔓 User {{.UserID}} is unlocked and may send OTPs again.`,
+	"otpunlock.not_locked": `ℹ️ User {{.UserID}} is not locked out.`,
+	"otpunlock.disabled":   `ℹ️ OTP lockouts are disabled (OTP_LOCKOUT_THRESHOLD=0).`,
+	"otpunlock.notice":     `🔓 An admin unlocked you. You may send your OTP again.`,
+
+	// /archive
+	"archive.usage": `/archive [year]
+
+Example: /archive 2023 (archives 2023 and earlier)`,
+	"archive.invalid_year": `❌ Invalid year.`,
+	"archive.progress":     `⏳ Archiving attendance of {{.Year}} and earlier... Please wait.`,
+	"archive.future_year":  `❌ Only past years can be archived.`,
+	"archive.interrupted":  `⚠️ Archiving stopped after {{.Moved}} records were moved. Run /archive {{.Year}} again to continue.`,
+	"archive.done": `✅ Archiving finished.
+
+📦 Moved: {{.Moved}} records ({{.Batches}} batches)
+🗄️ Total archived before {{.Before}}: {{.Archived}} records
+
+Reports and exports still include archived data.`,
+
+	// /anomalies
+	"anomalies.usage": `/anomalies YYYY-MM-DD YYYY-MM-DD
+
+Example: /anomalies 2025-01-01 2025-01-31`,
+	"anomalies.title":         `🔎 Missing Check-outs`,
+	"anomalies.period":        `📅 Period: {{.Start}} to {{.End}}`,
+	"anomalies.today_skipped": `ℹ️ Today is not counted since check-outs may still come in.`,
+	"anomalies.none":          `✅ No check-ins without a check-out in this period.`,
+	"anomalies.total":         `⚠️ Total: {{.Days}} days from {{.Users}} employees`,
+	"anomalies.more_users":    `... and {{.Count}} more employees`,
+	"anomalies.user":          `• {{.Name}}: {{.Days}} days`,
+	"anomalies.details":       `Details per date are in the CSV file.`,
+
+	// Report files
+	"file.create_csv_failed": `❌ Something went wrong while creating the CSV file.`,
+	"file.open_failed":       `❌ Something went wrong while opening the report file.`,
+	"file.send_failed":       `❌ Something went wrong while sending the report.`,
+	"file.create_failed":     `❌ Something went wrong while creating the {{.Format}} report.`,
+	"common.send_otp":        `📝 Send your {{.Digits}}-digit OTP code to check in or out, or type /help for help.`,
+	"history.title":          `📈 *Your Attendance History (last 30 days)*`,
+	"history.check_in":       `   ⏰ In: {{.Time}}`,
+	"history.check_out":      `   🏠 Out: {{.Time}}`,
+	"history.summary": `*Summary:*
+📊 Total Days: {{.Days}}
+📝 Total Entries: {{.Records}}
+⚠️ Late: {{.Late}} days`,
+	"history.left_early": `⏪ Left Early: {{.Days}} days`,
+	"history.shift":      `🕘 Shift: {{.Shift}}`,
+	"fullreport.invalid_input": `❌ Invalid input. Use the format: YYYY-MM-DD YYYY-MM-DD
+
+Example: 2025-01-01 2025-01-31`,
+	"fullreport.invalid_start":   `❌ Invalid start date. Make sure the date format is correct (YYYY-MM-DD).`,
+	"fullreport.invalid_end":     `❌ Invalid end date. Make sure the date format is correct (YYYY-MM-DD).`,
+	"fullreport.start_after_end": `❌ The start date may not be after the end date.`,
+	"fullreport.pick_format":     `📊 Pick the report format for {{.Start}} to {{.End}}:`,
+	"fullreport.unknown_format":  `❌ Unknown report format. Pick csv, xlsx or pdf.`,
+	"fullreport.creating":        `⏳ Creating the {{.Format}} report... Please wait.`,
+	"fullreport.empty":           `📭 No attendance data in the given date range.`,
+	"fullreport.caption": `📊 *Attendance Report*
+
+📅 Period: {{.Start}} to {{.End}}
+📈 Total Records: {{.Records}}`,
+	"fullreport.caption_leave":      `🏖️ Leave Days: {{.Days}}`,
+	"fullreport.caption_department": `🏢 Department: {{.Department}}`,
+	"fullreport.caption_summary": `📑 *Attendance Summary*
+
+📅 Period: {{.Start}} to {{.End}}
+👥 Employees: {{.Employees}}`,
+
+	// PDF summary, set in a font without emoji
+	"pdf.title":                    `Attendance Summary`,
+	"pdf.period":                   `Period: {{.Start}} to {{.End}}`,
+	"pdf.department":               `Department: {{.Department}}`,
+	"pdf.generated":                `Generated {{.Time}} {{.Zone}}`,
+	"pdf.empty":                    `No attendance data in this period.`,
+	"pdf.column.no":                `No`,
+	"pdf.column.name":              `Name`,
+	"pdf.column.employee_id":       `Employee ID`,
+	"pdf.column.department":        `Department`,
+	"pdf.column.present":           `Present`,
+	"pdf.column.late":              `Late`,
+	"pdf.column.average_check_in":  `Avg check-in`,
+	"pdf.column.work":              `Work (h:mm)`,
+	"pdf.column.missing_checkouts": `No check-out`,
+	"pdf.column.absent":            `Absent`,
+	"pdf.working_days":             `Working days: {{.Days}}`,
+	"pdf.total":                    `Total ({{.Count}} employees)`,
+	"pdf.prepared_by":              `Prepared by`,
+	"pdf.approved_by":              `Approved by`,
+	"pdf.signature_date":           `Name and date`,
+	"pdf.page":                     `Page {{.Page}} of {{.Pages}}`,
+
+	// Auto check-out
+	"auto_checkout.reminder": `⏰ You checked in at {{.Time}} today but have not checked out. Send an OTP code to check out before midnight.`,
+	"auto_checkout.recorded": `🤖 You forgot to check out today, so an automatic check-out was recorded at {{.Time}}. Contact an admin if the time needs correcting.`,
+
+	// /remind and reminders
+	"remind.usage": `/remind on [minutes] - Remind me to check in some minutes before my shift starts (default 15) and to check out when it ends
+/remind off - Turn reminders off`,
+	"remind.status_off": `🔕 Reminders are off.`,
+	"remind.status_on": `🔔 Reminders are on: {{.Lead}} minutes before your shift starts, and when it ends if you have not checked out.
+🕘 Your shift: {{.Shift}}`,
+	"remind.enabled":      `✅ Reminders turned on. You will be reminded {{.Lead}} minutes before your shift starts if you have not checked in, and when it ends if you have not checked out.`,
+	"remind.disabled":     `✅ Reminders turned off.`,
+	"remind.not_enabled":  `ℹ️ Your reminders are already off.`,
+	"remind.invalid_lead": `❌ The reminder minutes must be a number between 1 and {{.Max}}.`,
+	"reminder.check_in":   `⏰ Your shift {{.Shift}} starts at {{.Start}}. Don't forget to check in by sending your OTP code.`,
+	"reminder.check_out":  `🏠 Your shift {{.Shift}} ends at {{.End}} and you have not checked out. Send your OTP code to check out.`,
+
+	// /weekly
+	"weekly.usage": `/weekly off - Stop the summary of your past week
+/weekly on - Get it again`,
+	"weekly.status_on":   `📅 Every week you get a summary of your past week: days present, late days, total work and missing check-outs.`,
+	"weekly.status_off":  `🔕 You do not get the weekly summary.`,
+	"weekly.enabled":     `✅ You will get the summary of your past week again.`,
+	"weekly.disabled":    `✅ You will no longer get the weekly summary.`,
+	"weekly.unavailable": `ℹ️ Weekly summaries are turned off on this bot.`,
+	"weekly.summary": `📅 *Your week, {{.Start}} to {{.End}}*
+
+✅ Present: {{.Present}} of {{.WorkingDays}} working days
+⏰ Late: {{.Late}} days
+⏱️ Total work: {{.WorkDuration}}
+⚠️ No check-out: {{.MissingCheckouts}} days
+
+Stop these summaries with /weekly off.`,
+
+	// Proof-of-presence photos and /photo
+	"photo.prompt":           `📸 Send a selfie within {{.Minutes}} minutes as proof of presence.`,
+	"photo.attached":         `✅ Photo attached to your check-in at {{.Time}}.`,
+	"photo.no_check_in":      `❌ A photo can only be attached within {{.Minutes}} minutes after checking in with an OTP code. Send your OTP code first.`,
+	"photo.already_attached": `ℹ️ Your check-in today already has a photo.`,
+	"photo.forwarded":        `❌ Forwarded photos are not accepted. Take and send your own photo.`,
+	"photo.usage": `/photo [record ID]
+
+The record ID is in the ID column of the CSV report.`,
+	"photo.not_found": `❌ No attendance record with ID {{.ID}}.`,
+	"photo.none":      `ℹ️ Attendance record {{.ID}} has no photo.`,
+	"photo.caption":   `{{.Name}} - {{.Type}} {{.Date}} {{.Time}}`,
+
+	// Locations and /geofence
+	"geofence.shared_inside":     `📍 Location received: you are in the {{.Geofence}} area. Send your OTP code within {{.Minutes}} minutes.`,
+	"geofence.shared_outside":    `📍 Location received, but you are outside the office areas ({{.Distance}} m from {{.Geofence}}). Send your OTP code within {{.Minutes}} minutes.`,
+	"geofence.shared":            `📍 Location received. Send your OTP code within {{.Minutes}} minutes.`,
+	"geofence.forwarded":         `❌ Forwarded locations are not accepted. Share your own location.`,
+	"geofence.location_required": `📍 Checking in requires your location. Share your location (📎 > Location), then send your OTP code within {{.Minutes}} minutes.`,
+	"geofence.outside":           `❌ You are outside the office areas ({{.Distance}} m from {{.Geofence}}), so the check-in was not recorded. Share your location again once you are at the office.`,
+	"geofence.usage": `/geofence list
+/geofence set [name] [latitude] [longitude] [radius meters]
+/geofence delete [name]
+
+Coordinates use decimal degrees, e.g. -6.200000 106.816666.`,
+	"geofence.list_title": `📍 Office areas (mode: {{.Mode}})`,
+	"geofence.list_empty": `No areas yet. Without areas, check-ins are not checked against a location.`,
+	"geofence.entry":      `• {{.Name}}: {{.Latitude}}, {{.Longitude}}, radius {{.Radius}} m`,
+	"geofence.invalid":    `❌ Invalid area. Names may only contain lowercase letters, digits and hyphens; the latitude is -90 to 90, the longitude -180 to 180 and the radius 1-{{.MaxRadius}} meters.`,
+	"geofence.saved":      `✅ Area saved: {{.Geofence}}`,
+	"geofence.not_found":  `ℹ️ Area {{.Name}} not found.`,
+	"geofence.deleted":    `🗑️ Area {{.Name}} deleted.`,
+
+	// Group chats and /register
+	"group.private_only":      `🔒 {{.Command}} only works in a private chat with the bot.`,
+	"group.register_in_group": `ℹ️ Send /register or /unregister in the group chat you want to make an office channel.`,
+	"group.registered": `🏢 This group is now an office channel. {{.Note}}
+Members may send their {{.Digits}}-digit OTP here; the bot deletes it once processed, so it needs the right to delete messages.`,
+	"group.report_daily":       `The daily report is posted here every day at {{.Clock}} {{.Zone}}.`,
+	"group.report_disabled":    `The daily report is not scheduled (DAILY_REPORT_TIME is off).`,
+	"group.already_registered": `ℹ️ This group is already an office channel.`,
+	"group.unregistered":       `✅ This group is no longer an office channel.`,
+	"group.not_registered":     `ℹ️ This group is not an office channel.`,
+
+	// /enroll
+	"enroll.usage": `/enroll [user_id]`,
+	"enroll.caption": `🔐 Scan this QR code with your authenticator app (Google Authenticator, Authy, etc.), then send the {{.Digits}}-digit code it shows to check in or out.
+
+Keep it private: anyone with this QR code can create your codes. Delete this message once scanned.`,
+	"enroll.sent":        `✅ QR code sent to user ID {{.UserID}} in their private chat with the bot.`,
+	"enroll.send_failed": `❌ Could not send the QR code to user ID {{.UserID}}. Ask them to send /start to the bot first, then try again.`,
+
+	// /rotatesecret
+	"rotate.explain": `🔄 /rotatesecret confirm replaces the shared TOTP secret with a new one.
+
+Codes from the old secret keep working for {{.Days}} days (TOTP_ROTATION_GRACE_DAYS). Everyone who recorded attendance in the last 90 days is sent the new QR code in their private chat; users with a personal code sheet are not affected.`,
+	"rotate.encryption_key_missing": `❌ SECRETS_ENCRYPTION_KEY is not set, so the new secret cannot be stored.`,
+	"rotate.in_progress":            `⏳ The previous rotation is still in its grace period until {{.Until}}. Rotate again after that, so nobody is left with a secret that no longer works.`,
+	"rotate.done":                   `✅ TOTP secret rotated. The old secret works until {{.Until}}. Sending the new QR code to {{.Count}} users...`,
+	"rotate.notified":               `📨 New QR code sent to {{.Sent}} users.`,
+	"rotate.notified_failed": `📨 New QR code sent to {{.Sent}} users. {{.Failed}} could not be reached (they never started the bot or blocked it): {{.UserIDs}}
+Send it to them with /enroll once they have sent /start to the bot.`,
+	"rotate.notice": `🔄 The attendance code secret has changed. Scan this QR code with your authenticator app (Google Authenticator, Authy, etc.) and send the {{.Digits}}-digit code it shows from now on.
+
+Your old code keeps working until {{.Until}}. Keep this QR code private and delete this message once scanned.`,
+
+	// /fix and /auditlog
+	"fix.usage": `/fix [user ID] [YYYY-MM-DD] [in|out] [HH:MM] [reason]
+/fix [user ID] [YYYY-MM-DD] [in|out] delete [reason]
+
+Example: /fix 123456789 2025-03-10 out 17:30 forgot to check out`,
+	"fix.reason_too_long":        `❌ The reason is too long (at most {{.Max}} characters).`,
+	"fix.archived":               `❌ Attendance before {{.Before}} is archived and cannot be corrected.`,
+	"fix.nothing_to_delete":      `ℹ️ There is no such record to delete on that date.`,
+	"fix.delete_check_out_first": `❌ Delete the check-out before the check-in.`,
+	"fix.check_in_first":         `❌ There is no check-in on that date. Add the check-in first.`,
+	"fix.after_check_out":        `❌ The check-in must be before the check-out ({{.Time}}).`,
+	"fix.future":                 `❌ The corrected time is in the future.`,
+	"fix.unknown_user":           `❌ User {{.UserID}} has no attendance in the last {{.Days}} days, so their name is unknown.`,
+	"fix.done": `✅ Attendance corrected and recorded in the audit log:
+{{.Entry}}`,
+	"auditlog.usage": `/auditlog [user ID]
+/auditlog csv [YYYY-MM-DD] [YYYY-MM-DD]`,
+	"auditlog.empty":                        `ℹ️ The audit log is empty.`,
+	"auditlog.title":                        `🧾 Latest {{.Count}} audit log entries:`,
+	"auditlog.entry":                        `• {{.Time}} {{.Action}} by {{.ActorID}} for {{.UserID}}: {{.Details}}`,
+	"auditlog.entry_no_user":                `• {{.Time}} {{.Action}} by {{.ActorID}}: {{.Details}}`,
+	"auditlog.empty_range":                  `ℹ️ No audit log entries from {{.Start}} to {{.End}}.`,
+	"auditlog.caption":                      `🧾 Audit log {{.Start}} to {{.End}}: {{.Count}} entries`,
+	"auditlog.reason":                       `Reason: {{.Reason}}`,
+	"auditlog.action.attendance_added":      `Attendance added`,
+	"auditlog.action.attendance_changed":    `Attendance changed`,
+	"auditlog.action.attendance_deleted":    `Attendance deleted`,
+	"auditlog.action.report_downloaded":     `Report downloaded`,
+	"auditlog.action.alias_set":             `Alias set`,
+	"auditlog.action.alias_approved":        `Alias approved`,
+	"auditlog.action.alias_rejected":        `Alias rejected`,
+	"auditlog.action.alias_cleared":         `Alias cleared`,
+	"auditlog.action.otp_failed":            `Failed OTP`,
+	"auditlog.action.otp_locked":            `Locked out`,
+	"auditlog.action.otp_unlocked":          `Unlocked`,
+	"auditlog.action.group_registered":      `Group registered`,
+	"auditlog.action.group_unregistered":    `Group unregistered`,
+	"auditlog.action.totp_enrolled":         `QR code sent`,
+	"auditlog.action.totp_rotated":          `Secret rotated`,
+	"auditlog.action.employee_registered":   `Employee registered`,
+	"auditlog.action.employee_unregistered": `Employee unregistered`,
+	"auditlog.action.attendance_imported":   `Attendance imported`,
+	"auditlog.action.holiday_added":         `Holiday added`,
+	"auditlog.action.holiday_removed":       `Holiday removed`,
+	"auditlog.action.holidays_imported":     `Holidays imported`,
+	"auditlog.action.backup_created":        `Backup created`,
+	"auditlog.action.admin_added":           `Admin added`,
+	"auditlog.action.admin_removed":         `Admin removed`,
+}
@@ -0,0 +1,967 @@
+package i18n
+
+// indonesian is the reference catalog; every message must be defined here first
+var indonesian = map[string]string{
+
+	// Durations
+	"duration.hours_minutes": `{{.Hours}} jam {{.Minutes}} menit`,
+	"duration.minutes":       `{{.Minutes}} menit`,
+	"duration.invalid":       `⚠️ tidak valid`,
+	"duration.implausible":   `> 24 jam ⚠️`,
+
+	// Marking attendance
+	"otp.invalid_format":  `❌ Format OTP tidak valid. Harap masukkan {{.Digits}} digit angka.`,
+	"otp.rejected":        `❌ Kode OTP tidak valid atau sudah kedaluwarsa. Silakan coba dengan kode yang baru.`,
+	"otp.locked_out":      `🔒 Terlalu banyak kode salah. Silakan coba lagi dalam {{.Minutes}} menit (pukul {{.Until}}) atau minta admin membuka kunci Anda.`,
+	"otp.previous_secret": `⚠️ Kode Anda masih memakai secret lama. Silakan scan ulang QR code baru sebelum {{.Until}}.`,
+	"bypass.used":         `❌ Kode bypass ini sudah digunakan. Setiap kode hanya berlaku satu kali.`,
+	"bypass.expired":      `❌ Kode bypass sudah kedaluwarsa. Silakan minta kode baru kepada admin.`,
+	"bypass.recorded":     `🔑 Dicatat dengan kode bypass. Segera hubungi admin untuk mendaftarkan ulang autentikator Anda.`,
+	"attendance.checked_in": `✅ **Absen Masuk** tercatat!
+⏰ Waktu: {{.Time}}`,
+	"attendance.checked_out": `🏠 **Absen Pulang** tercatat!
+⏰ Waktu: {{.Time}}
+⌛ Durasi kerja: {{.Duration}}`,
+	"attendance.overtime":  `⏱️ Lembur: {{.Overtime}}`,
+	"attendance.complete":  `❌ Anda sudah absen lengkap hari ini (masuk dan pulang)!`,
+	"attendance.duplicate": `⚠️ Anda sudah {{.Type}} pukul {{.Time}}. Cek dengan /status.`,
+	"kiosk.invalid_format": `❌ Format kode tidak valid. Harap masukkan {{.Digits}} digit angka.`,
+	"kiosk.rejected":       `❌ Kode tidak valid. Gunakan kode berikutnya yang belum dicoret dari lembar kode Anda.`,
+
+	// Daily report
+	"report.empty_date":  `📭 Tidak ada yang absen pada {{.Date}}.`,
+	"report.empty_today": `📭 Belum ada yang absen hari ini.`,
+	"report.title": `📊 **Laporan Absensi**
+📅 {{.Date}}
+`,
+	"report.title_today": `📊 **Laporan Absensi Hari Ini**
+📅 {{.Date}}
+`,
+	"report.check_in":      `   ⏰ Masuk: {{.Time}}`,
+	"report.check_out":     `   🏠 Pulang: {{.Time}}`,
+	"report.duration":      `   ⌛ Durasi: {{.Duration}}`,
+	"report.leave_heading": `🏖️ **Cuti/Izin/Sakit:**`,
+	"report.summary": `**Ringkasan:**
+👥 Total Karyawan: {{.Users}}
+📝 Check-in: {{.CheckIns}}
+🏠 Check-out: {{.CheckOuts}}`,
+	"report.summary_leave":    `🏖️ Cuti/Izin/Sakit: {{.Count}}`,
+	"report.auto_checkout":    ` 🤖`,
+	"report.summary_auto":     `🤖 Check-out otomatis: {{.Count}}`,
+	"report.outside_geofence": ` 🚩`,
+	"report.summary_geofence": `🚩 Check-in di luar area kantor: {{.Count}}`,
+	"report.summary_remote":   `🏡 Kerja dari rumah: {{.Count}}`,
+	"leave.type.cuti":         `Cuti`,
+	"leave.type.izin":         `Izin`,
+	"leave.type.sakit":        `Sakit`,
+	"report.department": `🏢 {{.Department}}
+`,
+	"report.empty_department":   `📭 Tidak ada karyawan {{.Department}} yang absen pada {{.Date}}.`,
+	"report.department_heading": `**Per departemen:**`,
+	"report.department_entry":   `🏢 {{.Department}}: {{.Present}} hadir, {{.Late}} terlambat, {{.Leave}} cuti/izin/sakit`,
+
+	// Who is working
+	"who.empty": `📭 Tidak ada karyawan yang sedang bekerja saat ini.`,
+	"who.title": `👷 **Sedang Bekerja**
+🕒 Per {{.Time}}
+
+`,
+	"who.entry": `   ⏰ Masuk: {{.Time}} · ⏱️ {{.Elapsed}}`,
+	"who.total": `
+👥 Total: {{.Count}}`,
+
+	// Monthly summary
+	"monthly.title": `📆 Rekap Bulanan {{.Month}}
+📅 Periode: {{.Start}} s/d {{.End}}
+`,
+	"monthly.working_days": `🗓️ Hari kerja: {{.Days}} (tanpa akhir pekan dan hari libur)
+`,
+	"monthly.holidays": `🎌 Hari libur:`,
+	"monthly.empty": `
+ℹ️ Tidak ada data absensi pada bulan ini.`,
+	"monthly.users": `👥 Karyawan: {{.Count}}
+`,
+	"monthly.user": `👤 {{.Name}}
+   ✅ Hadir: {{.Present}} hari
+   ⏰ Terlambat: {{.Late}} hari
+   🕘 Rata-rata masuk: {{.AverageCheckIn}}
+   ⏱️ Total kerja: {{.WorkDuration}}
+   ⚠️ Tanpa check-out: {{.MissingCheckouts}} hari
+   ❌ Tidak hadir: {{.Absent}} hari
+`,
+	"monthly.department": `🏢 Departemen: {{.Department}}
+`,
+	"monthly.departments":      `🏢 Per departemen:`,
+	"monthly.department_entry": `• {{.Department}}: {{.Users}} karyawan, {{.Present}} hari hadir, {{.Late}} hari terlambat, {{.WorkDuration}} kerja, {{.Absent}} hari tidak hadir`,
+
+	// Overtime summary
+	"overtime.title": `⏱️ Rekap Lembur {{.Month}}
+📅 Periode: {{.Start}} s/d {{.End}}
+`,
+	"overtime.threshold": `ℹ️ Dihitung mulai {{.Threshold}} setelah shift berakhir.
+`,
+	"overtime.empty": `
+ℹ️ Tidak ada lembur pada periode ini.`,
+	"overtime.total": `👥 Karyawan lembur: {{.Count}}
+⏱️ Total lembur: {{.Overtime}}
+`,
+	"overtime.department": `🏢 {{.Department}}: {{.Overtime}}`,
+	"overtime.user":       `• {{.Name}}: {{.Overtime}} dalam {{.Days}} hari`,
+
+	// Actions in error messages, e.g. "Terjadi kesalahan saat {{action}}"
+	"action.create_bypass":           `membuat kode bypass`,
+	"action.create_report":           `membuat laporan`,
+	"action.create_code_sheet":       `membuat lembar kode`,
+	"action.create_monthly":          `membuat rekap bulanan`,
+	"action.check_alias":             `memeriksa alias`,
+	"action.check_duplicate_names":   `memeriksa nama ganda`,
+	"action.process_attendance":      `memproses absensi`,
+	"action.process_alias_request":   `memproses permintaan alias`,
+	"action.load_roster":             `memuat daftar karyawan`,
+	"action.add_admin":               `menambahkan admin`,
+	"action.find_missing_checkouts":  `mencari check-out yang hilang`,
+	"action.create_qr_code":          `membuat QR code`,
+	"action.rotate_secret":           `mengganti secret`,
+	"action.get_rotation_recipients": `mencari pengguna yang perlu diberi tahu`,
+	"action.enroll_employee":         `mendaftarkan karyawan`,
+	"action.get_alias":               `mengambil alias`,
+	"action.get_admins":              `mengambil daftar admin`,
+	"action.get_on_shift":            `mengambil daftar karyawan yang sedang bekerja`,
+	"action.get_shifts":              `mengambil daftar shift`,
+	"action.get_attendance":          `mengambil data absensi`,
+	"action.get_leave":               `mengambil data cuti`,
+	"action.get_summary":             `mengambil rekap absensi`,
+	"action.get_data":                `mengambil data`,
+	"action.get_subscription":        `mengambil langganan`,
+	"action.get_leave_requests":      `mengambil pengajuan`,
+	"action.get_alias_requests":      `mengambil permintaan alias`,
+	"action.get_history":             `mengambil riwayat`,
+	"action.archive":                 `mengarsipkan data`,
+	"action.assign_shift":            `mengatur shift`,
+	"action.check_status":            `mengecek status`,
+	"action.remove_admin":            `menghapus admin`,
+	"action.remove_subscription":     `menghapus langganan`,
+	"action.delete_shift":            `menghapus shift`,
+	"action.calculate_duration":      `menghitung durasi kerja`,
+	"action.save_alias":              `menyimpan alias`,
+	"action.save_subscription":       `menyimpan langganan`,
+	"action.save_reminder":           `menyimpan pengingat`,
+	"action.get_reminder":            `mengambil pengaturan pengingat`,
+	"action.get_weekly":              `mengambil pengaturan ringkasan mingguan`,
+	"action.set_weekly":              `mengubah pengaturan ringkasan mingguan`,
+	"action.attach_photo":            `melampirkan foto`,
+	"action.get_photo":               `mengambil foto`,
+	"action.share_location":          `membagikan lokasi`,
+	"action.save_geofence":           `menyimpan area`,
+	"action.get_geofences":           `mengambil daftar area`,
+	"action.delete_geofence":         `menghapus area`,
+	"action.fix_attendance":          `mengoreksi absensi`,
+	"action.get_audit_log":           `mengambil log audit`,
+	"action.unlock_otp":              `membuka kunci user`,
+	"action.register_group":          `mendaftarkan grup`,
+	"action.unregister_group":        `menghapus pendaftaran grup`,
+	"action.register_employee":       `mendaftarkan karyawan`,
+	"action.unregister_employee":     `menghapus pendaftaran karyawan`,
+	"action.get_employees":           `mengambil daftar karyawan`,
+	"action.get_holidays":            `mengambil daftar hari libur`,
+	"action.save_holiday":            `menyimpan hari libur`,
+	"action.delete_holiday":          `menghapus hari libur`,
+	"action.import_holidays":         `mengimpor libur nasional`,
+	"action.import_attendance":       `mengimpor absensi`,
+	"action.backup":                  `mencadangkan database`,
+	"action.get_backups":             `menampilkan daftar cadangan`,
+	"action.get_overtime":            `mengambil rekap lembur`,
+	"action.get_departments":         `mengambil daftar departemen`,
+	"action.start_conversation":      `memulai percakapan`,
+	"action.save_leave_request":      `menyimpan pengajuan`,
+	"action.save_break":              `mencatat istirahat Anda`,
+	"action.save_wfh_request":        `menyimpan pengajuan kerja dari rumah`,
+	"action.cancel_wfh_request":      `membatalkan kerja dari rumah`,
+	"action.get_wfh_requests":        `mengambil pengajuan kerja dari rumah`,
+	"action.save_alias_request":      `menyimpan permintaan alias`,
+	"action.save_shift":              `menyimpan shift`,
+	"action.set_language":            `mengatur bahasa`,
+	"action.get_timezone":            `mengambil zona waktu Anda`,
+	"action.set_timezone":            `mengatur zona waktu Anda`,
+
+	// Error replies
+	"error.invalid_date_range":     `❌ Rentang tanggal tidak valid. Gunakan format YYYY-MM-DD dan pastikan tanggal mulai tidak melebihi tanggal akhir.`,
+	"error.not_found":              `❌ Data tidak ditemukan.`,
+	"error.invalid_secret":         `⚠️ Bot salah konfigurasi (secret OTP tidak valid). Silakan hubungi admin.`,
+	"error.encryption_key_missing": `⚠️ Bot salah konfigurasi (kunci enkripsi belum diatur). Silakan hubungi admin.`,
+	"error.storage":                `❌ Database sedang bermasalah saat {{.Action}}. Silakan coba lagi beberapa saat lagi.`,
+	"error.timeout":                `⏱️ Waktu habis saat {{.Action}}. Silakan coba lagi beberapa saat lagi.`,
+	"error.generic":                `❌ Terjadi kesalahan saat {{.Action}}. Silakan coba lagi.`,
+	"error.reference": `
+
+Kode referensi: {{.RequestID}}`,
+
+	// Shared messages
+	"common.button_expired":  `Tombol ini sudah tidak berlaku.`,
+	"common.page":            `📄 Halaman {{.Page}} dari {{.Pages}}`,
+	"common.page_previous":   `◀️ Sebelumnya`,
+	"common.page_next":       `Berikutnya ▶️`,
+	"common.admin_only":      `❌ Perintah ini hanya tersedia untuk admin.`,
+	"common.invalid_user_id": `❌ User ID tidak valid.`,
+	"common.invalid_format":  `❌ Format tidak valid. Gunakan:`,
+
+	// /timezone
+	"timezone.office":  `🕐 Waktu Anda mengikuti zona waktu kantor, {{.Timezone}}.`,
+	"timezone.current": `🕐 Zona waktu Anda: {{.Timezone}} (kantor: {{.Office}})`,
+	"timezone.usage": `/timezone [Area/Kota] - Bekerja di zona waktu lain, misalnya /timezone Asia/Makassar atau /timezone Europe/Berlin
+/timezone reset - Kembali mengikuti zona waktu kantor
+Absen masuk, absen keluar, jam shift, dan pengingat memakai zona waktu Anda; laporan tetap memakai zona waktu kantor.`,
+	"timezone.set":     `✅ Zona waktu diubah ke {{.Timezone}}. Di sana sekarang pukul {{.Time}}.`,
+	"timezone.reset":   `✅ Waktu Anda kembali mengikuti zona waktu kantor, {{.Timezone}}.`,
+	"timezone.invalid": `❌ Zona waktu "{{.Timezone}}" tidak dikenal. Gunakan nama IANA seperti Asia/Jayapura atau America/New_York.`,
+
+	// /language
+	"language.current": `🌐 Bahasa Anda: {{.Language}}
+
+Pilih bahasa, atau Auto untuk mengikuti bahasa aplikasi Telegram Anda.`,
+	"language.set":         `✅ Bahasa diubah ke {{.Language}}.`,
+	"language.auto":        `✅ Bahasa mengikuti aplikasi Telegram Anda ({{.Language}}).`,
+	"language.unsupported": `❌ Bahasa tidak didukung. Pilih salah satu: {{.Languages}}, atau auto.`,
+	"language.failed":      `Gagal mengubah bahasa. Silakan coba lagi.`,
+
+	// /shift
+	"shift.usage": `/shift list
+/shift set [nama] [HH:MM mulai] [HH:MM selesai] [toleransi menit]
+/shift delete [nama]
+/shift assign [user_id] [nama]
+/shift unassign [user_id]
+
+Shift bernama "default" berlaku untuk karyawan tanpa shift.`,
+	"shift.invalid_grace":     `❌ Toleransi harus berupa jumlah menit.`,
+	"shift.list_title":        `🕘 Daftar Shift`,
+	"shift.list_entry":        `• {{.Shift}}, {{.Users}} karyawan`,
+	"shift.list_builtin":      `• {{.Shift}}, bawaan untuk karyawan tanpa shift`,
+	"shift.invalid":           `❌ Shift tidak valid. Nama hanya boleh huruf kecil, angka dan tanda hubung; jam dalam format HH:MM dengan jam mulai berbeda dari jam selesai; toleransi 0-{{.MaxGrace}} menit.`,
+	"shift.saved":             `✅ Shift disimpan: {{.Shift}}`,
+	"shift.in_use":            `❌ Shift {{.Name}} masih dipakai karyawan. Pindahkan mereka dengan /shift assign atau /shift unassign terlebih dahulu.`,
+	"shift.not_found":         `ℹ️ Shift {{.Name}} tidak ditemukan.`,
+	"shift.deleted":           `🗑️ Shift {{.Name}} dihapus.`,
+	"shift.unknown":           `❌ Shift {{.Name}} tidak ditemukan. Lihat daftar shift dengan /shift list.`,
+	"shift.assigned_notice":   `🕘 Shift Anda sekarang: {{.Shift}}`,
+	"shift.assigned":          `✅ User ID {{.UserID}} sekarang di shift {{.Name}}.`,
+	"shift.not_assigned":      `ℹ️ User ID {{.UserID}} tidak memiliki shift khusus.`,
+	"shift.unassigned":        `✅ User ID {{.UserID}} kembali ke shift default.`,
+	"shift.starts_at":         `masuk {{.Start}}`,
+	"shift.grace":             `, toleransi {{.Minutes}} menit`,
+	"common.super_admin_only": `❌ Perintah ini hanya tersedia untuk super-admin.`,
+	"common.admin_chat_only":  `❌ Perintah ini hanya tersedia di chat admin.`,
+
+	// /admin
+	"admin.usage":            `/admin add [user_id], /admin remove [user_id] atau /admin list`,
+	"admin.is_super_admin":   `ℹ️ User ini adalah super-admin; haknya diatur lewat SUPER_ADMIN_ID.`,
+	"admin.already_admin":    `ℹ️ User ID {{.UserID}} sudah menjadi admin.`,
+	"admin.added_notice":     `🛡️ Anda sekarang admin Attendance Bot dan dapat menggunakan /fullreport.`,
+	"admin.added":            `✅ User ID {{.UserID}} sekarang admin.`,
+	"admin.not_admin":        `ℹ️ User ID {{.UserID}} bukan admin.`,
+	"admin.removed":          `🗑️ User ID {{.UserID}} bukan admin lagi.`,
+	"admin.list_title":       `🛡️ Daftar Admin`,
+	"admin.list_super_admin": `• user ID {{.UserID}} (super-admin)`,
+	"admin.list_entry":       `• user ID {{.UserID}}, ditambahkan {{.AddedAt}} oleh {{.AddedBy}}`,
+	"admin.list_empty":       `Belum ada admin lain. Tambahkan dengan /admin add [user_id].`,
+
+	// Employee directory
+	"employee.register_usage": `Format: /registeruser [user_id] [ID karyawan] [departemen]
+Contoh: /registeruser 123456789 EMP-0042 Keuangan
+Mendaftarkan ulang pengguna mengubah ID karyawan dan departemennya.`,
+	"employee.unregister_usage":   `Format: /unregisteruser [user_id]`,
+	"employee.invalid_id":         `❌ ID karyawan tidak valid. Gunakan maksimal 32 huruf, angka, ".", "_", "/" atau "-", diawali huruf atau angka.`,
+	"employee.invalid_department": `❌ Nama departemen tidak valid. Gunakan maksimal 40 huruf, angka, spasi atau & . , ' / ( ) -, diawali huruf atau angka.`,
+	"employee.id_taken":           `❌ ID karyawan {{.EmployeeID}} sudah terdaftar untuk pengguna lain.`,
+	"employee.registered":         `✅ User ID {{.UserID}} terdaftar sebagai {{.Employee}}.`,
+	"employee.updated":            `✅ Data user ID {{.UserID}} diubah menjadi {{.Employee}}.`,
+	"employee.registered_notice":  `✅ Anda sudah terdaftar sebagai karyawan {{.EmployeeID}}. Sekarang Anda bisa mengirim OTP untuk absen.`,
+	"employee.unregistered":       `🗑️ User ID {{.UserID}} dihapus dari daftar karyawan.`,
+	"employee.not_found":          `User ID {{.UserID}} tidak ada di daftar karyawan.`,
+	"employee.with_department":    `{{.EmployeeID}} ({{.Department}})`,
+	"employee.list_title":         `👥 Daftar karyawan ({{.Count}})`,
+	"employee.list_entry":         `• {{.EmployeeID}}: user ID {{.UserID}}`,
+	"employee.list_empty":         `Daftar karyawan masih kosong. Tambahkan karyawan dengan /registeruser.`,
+	"employee.no_department":      `Tanpa departemen`,
+	"employee.not_required":       `ℹ️ Pendaftaran belum diwajibkan, semua orang masih bisa absen (REQUIRE_REGISTRATION).`,
+	"employee.not_registered": `👋 Anda belum terdaftar sebagai karyawan, jadi absensi Anda belum bisa dicatat.
+Kirim ID Telegram Anda {{.UserID}} ke admin untuk didaftarkan.`,
+	"employee.unknown_department": `❌ Departemen {{.Department}} tidak ada. Departemen yang ada: {{.Departments}}`,
+	"employee.no_departments":     `ℹ️ Belum ada karyawan dengan departemen. Admin bisa mengaturnya dengan /registeruser.`,
+
+	// /holiday
+	"holiday.usage": `/holiday [list [YYYY]]
+/holiday add [YYYY-MM-DD] [nama]
+/holiday remove [YYYY-MM-DD]
+/holiday import [YYYY]
+
+Contoh: /holiday add 2025-01-01 "Tahun Baru"
+Hari libur dan akhir pekan bukan hari kerja: tidak ada yang dihitung absen.`,
+	"holiday.list_title":      `📅 Hari Libur {{.Year}}`,
+	"holiday.list_empty":      `Belum ada hari libur.`,
+	"holiday.source.import":   `(nasional)`,
+	"holiday.source.config":   `(HOLIDAYS)`,
+	"holiday.added":           `✅ Hari libur ditambahkan: {{.Holiday}}`,
+	"holiday.renamed":         `✅ Nama hari libur diubah: {{.Holiday}}`,
+	"holiday.removed":         `🗑️ Hari libur {{.Date}} dihapus.`,
+	"holiday.not_found":       `Tidak ada hari libur pada {{.Date}} untuk dihapus. Hari libur dari pengaturan HOLIDAYS hanya bisa dihapus di sana.`,
+	"holiday.imported":        `✅ Libur nasional {{.Year}} diimpor: {{.Added}} ditambahkan, {{.Skipped}} sudah ada di kalender.`,
+	"holiday.import_disabled": `ℹ️ Impor libur nasional dinonaktifkan (HOLIDAY_FEED_URL). Tambahkan hari libur dengan /holiday add.`,
+
+	// /import
+	"import.usage": `Kirim file CSV dengan keterangan /import, atau balas file tersebut dengan /import. Tambahkan dryrun untuk hanya memeriksanya.
+Kolom: user_id,name,date,type,time
+user_id adalah ID pengguna Telegram atau ID karyawan; kosongkan untuk mencari karyawan terdaftar berdasarkan nama.
+Contoh baris: ,Budi Santoso,2025-01-06,check_in,08:02`,
+	"import.too_large":       `❌ File terlalu besar untuk diimpor, batasnya {{.MB}} MB. Pecah file atau gunakan CLI import.`,
+	"import.invalid_file":    `❌ Ini bukan file CSV absensi. Baris pertama harus berupa header {{.Columns}}.`,
+	"import.dry_run":         `🔍 Uji coba, tidak ada yang disimpan: {{.Rows}} baris, {{.Valid}} valid ({{.Resolved}} dicocokkan lewat nama atau ID karyawan), {{.Errors}} tidak valid.`,
+	"import.done":            `✅ {{.Rows}} baris diimpor: {{.Inserted}} ditambahkan, {{.Duplicates}} sudah tercatat, {{.Errors}} tidak valid.`,
+	"import.rejects_caption": `Baris yang tidak diimpor terlampir beserta alasannya. Perbaiki lalu impor file tersebut lagi.`,
+
+	// /backup
+	"backup.usage": `/backup now - Cadangkan database sekarang
+/backup list - Tampilkan cadangan yang disimpan`,
+	"backup.disabled":         `ℹ️ Bot hanya mencadangkan database SQLite. Cadangkan PostgreSQL dengan pg_dump.`,
+	"backup.running":          `⏳ Pencadangan sedang berjalan. Silakan coba lagi sebentar lagi.`,
+	"backup.done":             `✅ Cadangan {{.Name}} ditulis ({{.Size}}). Cadangan lama yang dihapus: {{.Removed}}.`,
+	"backup.uploaded":         `☁️ Cadangan telah diunggah ke bucket cadangan.`,
+	"backup.incomplete":       `⚠️ Menghapus cadangan lama atau mengunggah cadangan ini gagal; lihat log bot.`,
+	"backup.list_title":       `💾 Cadangan ({{.Count}}, {{.Keep}} terbaru disimpan):`,
+	"backup.list_entry":       `• {{.Time}} - {{.Name}} ({{.Size}})`,
+	"backup.list_more":        `… dan {{.Count}} yang lebih lama`,
+	"backup.list_empty":       `Belum ada cadangan. Buat dengan /backup now.`,
+	"backup.failed_alert":     `❌ Pencadangan database terjadwal gagal: {{.Error}}`,
+	"backup.incomplete_alert": `⚠️ Cadangan database terjadwal telah ditulis, tetapi: {{.Error}}`,
+
+	// /forgot and /bypass
+	"forgot.no_admin_chat": `ℹ️ Silakan hubungi admin secara langsung untuk mendapatkan kode absen sementara.`,
+	"forgot.alert": `🆘 {{.Name}} (ID {{.UserID}}) melaporkan kehilangan aplikasi autentikator.
+
+Setelah memverifikasi identitasnya, terbitkan kode sementara dengan:
+/bypass {{.UserID}}
+Atau kirim QR code untuk ponsel barunya dengan:
+/enroll {{.UserID}}`,
+	"forgot.failed": `❌ Gagal menghubungi admin. Silakan hubungi admin secara langsung.`,
+	"forgot.sent":   `📨 Admin sudah diberi tahu. Admin akan memberikan kode sementara secara langsung kepada Anda; kirimkan kode itu ke bot ini seperti OTP biasa. Kode berlaku {{.Minutes}} menit dan hanya sekali pakai.`,
+	"bypass.usage":  `/bypass [user_id]`,
+	"bypass.issued": `🔑 Kode bypass untuk user ID {{.UserID}}: {{.Code}}
+
+⏰ Berlaku sampai pukul {{.Expires}} {{.Zone}}, hanya untuk satu kali absen.
+Sampaikan kode ini langsung (lisan) kepada karyawan tersebut. Kode sebelumnya yang belum dipakai tidak berlaku lagi.`,
+	"bypass.used_alert":         `🔑 {{.Username}} (ID {{.UserID}}) mencatat {{.Type}} pukul {{.Time}} dengan kode bypass.`,
+	"attendance.type.check_in":  `absen masuk`,
+	"attendance.type.check_out": `absen pulang`,
+
+	// Aliases
+	"alias.taken":            `❌ Nama {{printf "%q" .Alias}} sudah dipakai oleh karyawan lain. Silakan gunakan nama lain agar laporan absensi tidak tertukar.`,
+	"alias.approval_request": `⚠️ {{.Name}} (ID {{.UserID}}) ingin memakai alias {{printf "%q" .Alias}}, yang sama dengan:`,
+	"alias.approval_commands": `Setujui: /aliasapprove {{.UserID}}
+Tolak: /aliasreject {{.UserID}}`,
+	"alias.request_failed":  `❌ Gagal menghubungi admin. Silakan coba lagi nanti atau gunakan nama lain.`,
+	"alias.requested":       `⚠️ Nama {{printf "%q" .Alias}} mirip dengan nama karyawan lain, sehingga perlu persetujuan admin. Permintaan Anda sudah diteruskan; alias lama Anda tetap berlaku sampai disetujui.`,
+	"alias.no_request":      `ℹ️ Tidak ada permintaan alias dari user ID {{.UserID}}.`,
+	"alias.rejected":        `🚫 Alias {{printf "%q" .Alias}} untuk user ID {{.UserID}} ditolak.`,
+	"alias.rejected_notice": `🚫 Permintaan alias {{printf "%q" .Alias}} ditolak oleh admin. Silakan gunakan nama lain.`,
+	"alias.approved":        `✅ Alias {{printf "%q" .Alias}} untuk user ID {{.UserID}} disetujui.`,
+	"alias.approved_notice": `✅ Alias {{printf "%q" .Alias}} disetujui oleh admin dan sudah berlaku.`,
+	"alias.conflicts_title": `🏷️ Nama Ganda`,
+	"alias.conflicts_empty": `Tidak ada nama yang dipakai oleh lebih dari satu karyawan.`,
+	"alias.pending":         `⏳ Menunggu persetujuan:`,
+	"alias.match_telegram":  `• nama Telegram user ID {{.UserID}}: {{.Name}}`,
+	"alias.match_alias":     `• alias user ID {{.UserID}}: {{.Name}}`,
+
+	// /whoami
+	"whoami.title":            `🪪 *Data Identitas Anda*`,
+	"whoami.recorded":         `Data berikut dicatat bersama setiap absensi Anda:`,
+	"whoami.telegram_id":      `• ID Telegram: {{.UserID}}`,
+	"whoami.username":         `• Username: @{{.Username}}`,
+	"whoami.username_missing": `• Username: (tidak ada, dicatat sebagai {{.Username}})`,
+	"whoami.first_name":       `• Nama depan: {{.Name}}`,
+	"whoami.last_name":        `• Nama belakang: {{.Name}}`,
+	"whoami.alias":            `• Alias: {{.Alias}}`,
+	"whoami.language":         `• Bahasa: {{.Language}} (ubah dengan /language)`,
+	"whoami.not_recorded":     `Informasi lain dari pesan ini (tidak disimpan):`,
+	"whoami.chat_id":          `• Chat ID: {{.ChatID}}`,
+	"whoami.language_code":    `• Kode bahasa: {{.Code}}`,
+	"whoami.none":             `(tidak ada)`,
+	"whoami.not_detected":     `(tidak terdeteksi)`,
+	"whoami.empty":            `(kosong)`,
+	"whoami.sanitized":        `{{.Name}} (di Telegram: {{.Original}})`,
+	"common.previous_button":  `◀️ Sebelumnya`,
+	"common.next_button":      `Berikutnya ▶️`,
+
+	// Kiosk
+	"kiosk.chat_only":          `❌ Perintah ini hanya tersedia di chat kiosk.`,
+	"kiosk.enter_code":         `🔐 Masukkan kode {{.Digits}} digit untuk {{.Name}}, atau tekan Batal.`,
+	"kiosk.not_enrolled":       `❌ {{.Name}} belum memiliki lembar kode pribadi. Silakan hubungi admin.`,
+	"kiosk.attempts_left":      `Sisa percobaan: {{.Attempts}}.`,
+	"kiosk.too_many_attempts":  `⛔ Terlalu banyak kode salah. Silakan pilih nama Anda lagi, atau hubungi admin jika lembar kode Anda hilang.`,
+	"kiosk.button_elsewhere":   `Tombol ini hanya berlaku di kiosk.`,
+	"kiosk.roster_failed":      `Gagal memuat daftar karyawan.`,
+	"kiosk.pick_failed":        `Gagal memilih karyawan. Silakan coba lagi.`,
+	"kiosk.employee_not_found": `Karyawan tidak ditemukan di daftar kiosk.`,
+	"kiosk.prompt": `🔐 *{{.Name}}*
+Masukkan kode berikutnya dari lembar kode pribadi Anda.`,
+	"kiosk.cancel_button":   `❎ Batal`,
+	"kiosk.cancelled_toast": `Dibatalkan`,
+	"kiosk.cancelled":       `❎ Dibatalkan.`,
+	"kiosk.roster_empty": `🏢 *Absensi Kiosk*
+
+Belum ada karyawan yang terdaftar untuk kiosk. Admin dapat mendaftarkan karyawan dengan /kioskenroll.`,
+	"kiosk.roster": `🏢 *Absensi Kiosk*
+
+Pilih nama Anda, lalu masukkan kode berikutnya dari lembar kode pribadi Anda.
+Halaman {{.Page}} dari {{.Pages}}`,
+	"kiosk.enroll_usage":           `/kioskenroll [user_id]`,
+	"kiosk.encryption_key_missing": `❌ SECRETS_ENCRYPTION_KEY belum diatur, sehingga kode pribadi tidak dapat dibuat.`,
+	"kiosk.sheet_title":            `📄 Lembar kode pribadi untuk user ID {{.UserID}}`,
+	"kiosk.sheet_instructions":     `Cetak dan serahkan langsung kepada karyawan. Kode dipakai berurutan, masing-masing sekali; lembar sebelumnya tidak berlaku lagi. Atur nama yang tampil di kiosk dengan alias jika karyawan tidak memakai Telegram.`,
+
+	// /leave
+	"leave.usage": `/leave [cuti|izin|sakit] [tanggal mulai] [tanggal selesai] [alasan]
+
+Tanggal selesai boleh dihilangkan untuk izin satu hari.
+Contoh: /leave cuti 2025-03-10 2025-03-12 Acara keluarga
+Atau ketik /leave new untuk ditanya langkah demi langkah.`,
+	"leave.ask_type": `🏖️ Pengajuan baru. Jenisnya apa? Balas cuti, izin atau sakit.
+
+Kirim perintah apa saja untuk membatalkan.`,
+	"leave.ask_dates":         `📅 Balas dengan hari pertama dan terakhir dalam format YYYY-MM-DD YYYY-MM-DD, atau satu tanggal untuk satu hari.`,
+	"leave.ask_reason":        `✏️ Terakhir, balas dengan alasannya (maksimal {{.Max}} karakter).`,
+	"leave.invalid_start":     `❌ Tanggal mulai tidak valid. Pastikan format tanggal benar (YYYY-MM-DD).`,
+	"leave.reason_too_long":   `❌ Alasan terlalu panjang (maksimal {{.Max}} karakter).`,
+	"leave.unknown_type":      `❌ Jenis pengajuan tidak dikenal. Pilih salah satu: cuti, izin atau sakit.`,
+	"leave.invalid_range":     `❌ Rentang tanggal tidak valid. Tanggal mulai tidak boleh setelah tanggal selesai.`,
+	"leave.too_long":          `❌ Satu pengajuan paling lama {{.Days}} hari.`,
+	"leave.too_old":           `❌ Tanggal mulai paling lama {{.Days}} hari yang lalu.`,
+	"leave.overlap":           `❌ Anda sudah memiliki pengajuan cuti/izin/sakit pada tanggal tersebut. Ketik /leave untuk melihatnya.`,
+	"leave.admin_unreachable": `⚠️ Pengajuan #{{.ID}} tersimpan, tetapi admin belum dapat dihubungi. Silakan beri tahu admin secara langsung.`,
+	"leave.requested":         `📝 Pengajuan {{.Type}} #{{.ID}} ({{.Period}}) sudah dikirim ke admin. Anda akan diberi tahu setelah diputuskan.`,
+	"leave.list_title":        `🏖️ Pengajuan Anda:`,
+	"leave.list_entry":        `• #{{.ID}} {{.Type}}, {{.Period}}: {{.Status}}`,
+	"leave.status.pending":    `⏳ menunggu persetujuan`,
+	"leave.status.approved":   `✅ disetujui`,
+	"leave.status.rejected":   `🚫 ditolak`,
+	"leave.approval_request": `🏖️ Pengajuan {{.Type}} #{{.ID}}
+
+Nama: {{.Name}} (@{{.Username}}, ID {{.UserID}})
+Tanggal: {{.Period}}
+Alasan: {{.Reason}}`,
+	"leave.approve_button":   `✅ Setujui`,
+	"leave.reject_button":    `🚫 Tolak`,
+	"leave.admin_only":       `Hanya admin yang dapat memutuskan pengajuan ini.`,
+	"leave.decide_failed":    `Gagal memproses pengajuan. Silakan coba lagi.`,
+	"leave.not_found":        `Pengajuan tidak ditemukan.`,
+	"leave.already_approved": `Pengajuan ini sudah disetujui.`,
+	"leave.already_rejected": `Pengajuan ini sudah ditolak.`,
+	"leave.outcome_approved": `✅ Disetujui`,
+	"leave.outcome_rejected": `🚫 Ditolak`,
+	"leave.decided_by":       `{{.Outcome}} oleh {{.Name}}`,
+	"leave.decided_notice":   `{{.Outcome}}: pengajuan {{.Type}} #{{.ID}} ({{.Period}}).`,
+	"leave.period":           `{{.Start}} s/d {{.End}}`,
+
+	// /break
+	"break.usage": `/break start
+/break end
+
+Istirahat tidak dihitung sebagai waktu kerja. Mulai setelah absen masuk dan akhiri sebelum absen pulang.`,
+	"break.disabled":        `❌ Istirahat tidak dicatat di perusahaan ini.`,
+	"break.not_checked_in":  `❌ Anda belum absen masuk hari ini. Kirim OTP Anda untuk check-in terlebih dahulu.`,
+	"break.checked_out":     `❌ Anda sudah absen pulang hari ini.`,
+	"break.already_started": `☕ Anda sedang istirahat. Ketik /break end setelah kembali.`,
+	"break.not_started":     `❌ Anda sedang tidak istirahat. Ketik /break start untuk mulai istirahat.`,
+	"break.limit":           `❌ Anda sudah istirahat {{.Max}} kali hari ini, batas maksimalnya.`,
+	"break.started":         `☕ Istirahat ke-{{.Count}} dari {{.Max}} dimulai pukul {{.Time}}. Ketik /break end setelah kembali.`,
+	"break.ended":           `✅ Istirahat selesai pukul {{.Time}}. Total istirahat hari ini: {{.Total}}, tidak dihitung sebagai waktu kerja.`,
+
+	// /wfh
+	"wfh.usage": `/wfh [YYYY-MM-DD]
+/wfh cancel [YYYY-MM-DD]
+/wfh list
+
+Tanpa tanggal, hari ini. Absensi yang Anda catat pada hari kerja dari rumah ditandai 🏡 remote.`,
+	"wfh.invalid_date":      `❌ Pilih hari ini atau paling lama {{.Days}} hari ke depan (YYYY-MM-DD).`,
+	"wfh.exists":            `Anda sudah mengajukan kerja dari rumah pada {{.Date}}: {{.Status}}. Ketik /wfh cancel {{.Date}} untuk membatalkannya.`,
+	"wfh.approved_now":      `🏡 Anda kerja dari rumah pada {{.Date}}. Absensi Anda hari itu ditandai remote.`,
+	"wfh.requested":         `📝 Pengajuan #{{.ID}} kerja dari rumah pada {{.Date}} sudah dikirim ke admin. Anda akan diberi tahu setelah diputuskan.`,
+	"wfh.admin_unreachable": `⚠️ Pengajuan #{{.ID}} tersimpan, tetapi admin belum dapat dihubungi. Silakan beri tahu admin secara langsung.`,
+	"wfh.cancelled":         `🗑️ Kerja dari rumah pada {{.Date}} dibatalkan.`,
+	"wfh.not_found_date":    `Anda tidak mengajukan kerja dari rumah pada {{.Date}}.`,
+	"wfh.list_title":        `🏡 Hari kerja dari rumah Anda:`,
+	"wfh.list_entry":        `• {{.Date}}: {{.Status}}`,
+	"wfh.list_empty":        `Belum ada hari kerja dari rumah yang akan datang.`,
+	"wfh.status.pending":    `⏳ menunggu persetujuan`,
+	"wfh.status.approved":   `✅ disetujui`,
+	"wfh.status.rejected":   `🚫 ditolak`,
+	"wfh.approval_request": `🏡 Pengajuan kerja dari rumah #{{.ID}}
+
+Nama: {{.Name}} (@{{.Username}}, ID {{.UserID}})
+Tanggal: {{.Date}}`,
+	"wfh.approve_button":   `✅ Setujui`,
+	"wfh.reject_button":    `🚫 Tolak`,
+	"wfh.admin_only":       `Hanya admin yang dapat memutuskan pengajuan ini.`,
+	"wfh.decide_failed":    `Gagal memproses pengajuan. Silakan coba lagi.`,
+	"wfh.not_found":        `Pengajuan tidak ditemukan.`,
+	"wfh.already_approved": `Pengajuan ini sudah disetujui.`,
+	"wfh.already_rejected": `Pengajuan ini sudah ditolak.`,
+	"wfh.outcome_approved": `✅ Disetujui`,
+	"wfh.outcome_rejected": `🚫 Ditolak`,
+	"wfh.decided_by":       `{{.Outcome}} oleh {{.Name}}`,
+	"wfh.decided_notice":   `{{.Outcome}}: pengajuan #{{.ID}} kerja dari rumah pada {{.Date}}.`,
+
+	// /subscribe
+	"digest.daily":              `Laporan absensi harian`,
+	"digest.weekly":             `Ringkasan absensi mingguan`,
+	"digest.unknown":            `❌ Laporan {{printf "%q" .Digest}} tidak tersedia. Ketik /subscribe untuk melihat daftar laporan.`,
+	"digest.already_subscribed": `ℹ️ Anda sudah berlangganan laporan {{.Digest}}.`,
+	"digest.subscribed":         `✅ Berhasil berlangganan laporan {{.Digest}}. {{.Note}}`,
+	"digest.unsubscribe_usage": `/unsubscribe [nama laporan]
+
+Contoh: /unsubscribe daily`,
+	"digest.not_subscribed": `ℹ️ Anda tidak berlangganan laporan {{.Digest}}.`,
+	"digest.unsubscribed":   `✅ Langganan laporan {{.Digest}} dihentikan.`,
+	"digest.private_only":   `ℹ️ Silakan kirim /subscribe melalui chat pribadi dengan bot agar laporan dikirim langsung kepada Anda.`,
+	"digest.refused":        `🙏 Maaf, langganan laporan perusahaan hanya tersedia untuk admin dan supervisor. Anda tetap dapat melihat absensi Anda dengan /status dan /history.`,
+	"digest.list_title": `📬 Langganan Laporan
+
+Laporan yang tersedia:`,
+	"digest.status_subscribed":  `✅ berlangganan`,
+	"digest.status_coming_soon": `(segera hadir)`,
+	"digest.none":               `Anda belum berlangganan laporan apa pun.`,
+	"digest.list_usage":         `Gunakan /subscribe [nama] untuk berlangganan dan /unsubscribe [nama] untuk berhenti.`,
+	"digest.daily_schedule":     `Laporan harian dikirim setiap hari pukul {{.Clock}} {{.Zone}}.`,
+	"digest.delivery_disabled":  `Pengiriman terjadwal sedang dinonaktifkan oleh admin.`,
+	"digest.delivery_daily":     `Laporan akan dikirim setiap hari pukul {{.Clock}} {{.Zone}}.`,
+	"monthly.usage": `/monthly [YYYY-MM] [departemen]
+
+Contoh: /monthly 2025-01 atau /monthly 2025-01 Finance
+Tanpa bulan, rekap bulan ini yang ditampilkan; tanpa departemen, semua karyawan per departemen.`,
+	"monthly.future": `❌ Bulan tersebut belum berjalan.`,
+	"overtime.usage": `/overtime [YYYY-MM] [departemen]
+
+Contoh: /overtime 2025-01 atau /overtime 2025-01 Finance
+Tanpa bulan, lembur bulan ini yang ditampilkan; tanpa departemen, semua karyawan per departemen.`,
+
+	// Admin alerts
+	"panic.alert": `🚨 Bot mengalami panic dan telah pulih.
+
+Lokasi: {{.Site}}
+Pesan: {{.Value}}
+ID permintaan: {{.RequestID}}
+
+Peringatan berikutnya untuk lokasi yang sama ditahan selama 1 jam.`,
+
+	// Live report
+	"live_report.closed":   `🔒 Laporan ditutup pukul {{.Clock}} {{.Zone}}.`,
+	"live_report.updated":  `🔄 Diperbarui otomatis, terakhir pukul {{.Clock}} {{.Zone}}.`,
+	"common.stale_command": `⏳ Maaf, bot baru saja hidup kembali. Perintah Anda sebelumnya tidak diproses, silakan kirim ulang.`,
+
+	// Messages mirrored to Slack and Discord
+	"mirror.late_arrival": `⏰ {{.Name}} terlambat: absen masuk pukul {{.Time}} {{.Zone}}, shift {{.Shift}} mulai pukul {{.Start}}.`,
+
+	// Late arrival alerts to the late alert chat
+	"late_alert.instant":      `⏰ *{{.Name}}* terlambat: absen masuk pukul {{.Time}} {{.Zone}}, shift {{.Shift}} mulai pukul {{.Start}}.`,
+	"late_alert.digest_title": `⏰ *Keterlambatan {{.Date}}* ({{.Count}})`,
+	"late_alert.digest_line":  `*{{.Name}}* absen masuk pukul {{.Time}} {{.Zone}}, shift {{.Shift}} mulai pukul {{.Start}}`,
+
+	// /start and /help
+	"start.welcome": `🎯 *Selamat datang di Attendance Bot!*
+
+Untuk absen, kirimkan kode OTP {{.Digits}} digit Anda.
+
+*Perintah yang Tersedia:*
+📝 Kirim OTP - Absen (masuk/pulang)
+📊 /report - Lihat laporan absensi hari ini
+📈 /history - Lihat riwayat absensi Anda
+🏷️ /alias - Absen dengan nama lain
+🔄 /status - Cek status absensi hari ini
+⏱️ /duration - Lihat lama bekerja hari ini
+☕ /break - Mulai atau akhiri istirahat
+👷 /who - Lihat siapa yang sedang bekerja
+🏖️ /leave - Ajukan cuti, izin atau sakit
+🏡 /wfh - Kerja dari rumah hari ini
+📋 /fullreport - Download laporan lengkap (CSV/Excel/PDF, khusus admin)
+🌐 /language - Ganti bahasa bot
+🕐 /timezone - Bekerja di zona waktu lain
+❓ /help - Tampilkan pesan bantuan ini
+
+*Sistem Absensi:*
+• Absen pertama = Masuk (check-in)
+• Absen kedua = Pulang (check-out)`,
+	"help.message": `❓ *Bantuan Attendance Bot*
+
+*Cara menggunakan:*
+1. Dapatkan OTP dari aplikasi autentikator Anda
+2. Kirimkan kode {{.Digits}} digit ke bot ini
+3. Sistem akan otomatis menentukan check-in atau check-out
+
+*Sistem Absensi:*
+• Absen pertama dalam hari = *Check-in* (Masuk)
+• Absen kedua dalam hari = *Check-out* (Pulang)
+
+*Perintah:*
+📊 /report - Lihat laporan absensi hari ini
+   Per departemen: /report [departemen]
+📈 /history [YYYY-MM | YYYY-MM-DD YYYY-MM-DD] - Lihat riwayat absensi Anda (30 hari terakhir) dan ekspor sebagai CSV
+🔄 /status - Cek status absensi hari ini (masuk/pulang)
+⏱️ /duration - Lihat sudah berapa lama Anda bekerja hari ini
+☕ /break start, /break end - Istirahat, yang tidak dihitung sebagai waktu kerja
+👷 /who - Lihat karyawan yang sudah masuk tetapi belum pulang
+🏖️ /leave - Ajukan cuti, izin atau sakit untuk disetujui admin
+   Format: /leave [cuti|izin|sakit] [tanggal mulai] [tanggal selesai] [alasan]
+   Contoh: /leave sakit 2025-03-10 Demam
+🏡 /wfh - Kerja dari rumah hari ini, atau /wfh [YYYY-MM-DD] untuk hari lain; absensi Anda ditandai remote
+   Batal: /wfh cancel [YYYY-MM-DD]; daftar: /wfh list
+🏷️ /alias - Gunakan nama panggilan/alias untuk absensi
+   Format: /alias [Nama Depan] [Nama Belakang]
+   Contoh: /alias John Doe
+📋 /fullreport - Download laporan lengkap dalam format CSV, Excel atau rekap PDF (khusus admin)
+   Format: Masukkan rentang tanggal (YYYY-MM-DD YYYY-MM-DD)
+   Per departemen: /fullreport [YYYY-MM-DD] [YYYY-MM-DD] [csv|xlsx|pdf] [departemen]
+📆 /monthly - Rekap bulanan per karyawan: hadir, terlambat, rata-rata jam masuk, total kerja (khusus admin)
+   Format: /monthly [YYYY-MM] [departemen], tanpa bulan untuk bulan ini
+⏱️ /overtime - Rekap lembur per karyawan untuk penggajian (khusus admin)
+   Format: /overtime [YYYY-MM] [departemen], tanpa bulan untuk bulan ini
+🕘 /shift - Atur jam kerja shift dan karyawannya (khusus admin)
+📍 /geofence - Atur area kantor tempat check-in diharapkan (khusus admin)
+🛠️ /fix - Tambah, ubah, atau hapus absen masuk/pulang karyawan (khusus admin)
+   Format: /fix [user ID] [YYYY-MM-DD] [in|out] [HH:MM|delete] [alasan]
+🧾 /auditlog - Lihat operasi sensitif terbaru (khusus admin)
+   Ekspor: /auditlog csv [YYYY-MM-DD] [YYYY-MM-DD]
+🪪 /whoami - Lihat data identitas yang dicatat bot
+🌐 /language - Ganti bahasa bot (Indonesia/English)
+🕐 /timezone - Atur zona waktu Anda sendiri, misalnya /timezone Asia/Makassar; kembali: /timezone reset
+🔔 /remind - Pengingat sebelum shift dimulai dan saat shift berakhir
+   Format: /remind on [menit sebelum shift], /remind off
+📅 /weekly - Ringkasan minggu lalu yang Anda terima setiap minggu; hentikan dengan /weekly off
+📸 Kirim foto selfie setelah check-in sebagai bukti kehadiran (jika diaktifkan)
+📍 Bagikan lokasi Anda sebelum mengirim kode OTP untuk mencatat tempat absensi
+🆘 /forgot - Laporkan ke admin jika HP/aplikasi autentikator Anda hilang
+📬 /subscribe - Berlangganan laporan harian (khusus admin/supervisor)
+   Berhenti: /unsubscribe daily
+👥 /register - Di grup: jadikan kanal kantor untuk laporan harian dan OTP (khusus admin)
+   Berhenti: /unregister
+🪪 /registeruser - Daftarkan karyawan dengan ID karyawan dan departemennya (khusus admin)
+   Format: /registeruser [user ID] [ID karyawan] [departemen]; hapus: /unregisteruser [user ID]; daftar: /employees
+📅 /holiday - Hari libur, yang bukan hari kerja (khusus admin)
+   Format: /holiday add [YYYY-MM-DD] [nama]; hapus: /holiday remove [YYYY-MM-DD]; libur nasional: /holiday import [YYYY]
+📥 /import - Impor riwayat absensi dari file CSV yang dikirim bersama perintah (khusus admin)
+   Periksa tanpa menyimpan: /import dryrun
+💾 /backup now - Cadangkan database; daftar cadangan: /backup list (khusus admin)`,
+	"common.unknown_command": `❓ Perintah tidak dikenal. Ketik /help untuk melihat daftar perintah.`,
+	"common.rate_limited":    `⏳ Anda mengirim perintah terlalu cepat. Silakan tunggu satu menit sebelum mencoba lagi.`,
+	"common.command_failed":  `❌ Terjadi kesalahan saat menjalankan {{.Command}}. Silakan coba lagi nanti.`,
+	"report.refresh_button":  `🔄 Perbarui`,
+	"report.invalid_date":    `Tanggal laporan tidak valid.`,
+	"report.failed":          `Gagal membuat laporan.`,
+
+	// /history, /status and /duration
+	"history.empty":     `📭 Tidak ada riwayat absensi dalam 30 hari terakhir.`,
+	"history.not_yours": `Ini riwayat orang lain. Kirim /history untuk melihat riwayat Anda.`,
+	"history.failed":    `Gagal mengambil riwayat absensi Anda.`,
+	"history.usage": `/history - 30 hari terakhir
+/history YYYY-MM - Satu bulan
+/history YYYY-MM-DD YYYY-MM-DD - Rentang tanggal
+
+Contoh: /history 2025-01 atau /history 2025-01-01 2025-01-31`,
+	"history.start_after_end": `❌ Tanggal mulai tidak boleh setelah tanggal akhir.`,
+	"history.range_too_long":  `❌ Rentang tanggal paling lama {{.Days}} hari.`,
+	"history.empty_range":     `📭 Tidak ada absensi dari {{.Start}} sampai {{.End}}.`,
+	"history.title_range":     `📈 *Riwayat Absensi Anda ({{.Start}} sampai {{.End}})*`,
+	"history.export_button":   `📥 Ekspor CSV`,
+	"history.exporting":       `⏳ Membuat file CSV...`,
+	"history.export_caption":  `📈 Absensi Anda dari {{.Start}} sampai {{.End}} ({{.Days}} hari)`,
+	"status.none": `❌ *Status Absensi*
+
+Anda belum absen hari ini.
+Kirim OTP Anda untuk *check-in*.`,
+	"status.checked_in": `🟡 *Status Absensi*
+
+✅ Check-in: {{.CheckIn}}
+❌ Check-out: Belum
+
+Kirim OTP Anda untuk *check-out*.`,
+	"status.complete": `✅ *Status Absensi*
+
+✅ Check-in: {{.CheckIn}}
+✅ Check-out: {{.CheckOut}}
+⌛ Durasi kerja: {{.Duration}}
+
+Absensi hari ini sudah lengkap.`,
+	"status.breaks":           `☕ Istirahat: {{.Count}} kali ({{.Duration}}, tidak dihitung sebagai waktu kerja)`,
+	"status.on_break":         `☕ Anda sedang istirahat. Ketik /break end setelah kembali.`,
+	"duration.not_checked_in": `❌ Anda belum absen masuk hari ini. Kirim OTP Anda untuk check-in.`,
+	"duration.checked_out": `✅ Anda sudah absen pulang pukul {{.CheckOut}}.
+⌛ Durasi kerja hari ini: {{.Duration}}`,
+	"duration.elapsed":         `⏱️ Sudah bekerja {{.Elapsed}} (masuk pukul {{.CheckIn}})`,
+	"duration.remaining":       `, target tersisa {{.Remaining}}`,
+	"duration.target_reached":  `, target jam kerja sudah tercapai 🎉`,
+	"duration.breaks":          `☕ {{.Breaks}} istirahat tidak dihitung`,
+	"alias.usage":              `/alias [Nama Depan] [Nama Belakang]`,
+	"alias.invalid_first_name": `❌ Nama depan tidak valid.`,
+	"alias.set":                `✅ Alias berhasil diatur: {{.Alias}}`,
+
+	// /fullreport
+	"fullreport.prompt":           "📊 *Laporan Lengkap Absensi*\n\nPilih periode dengan tombol di bawah, atau masukkan rentang tanggal dalam format:\n`YYYY-MM-DD YYYY-MM-DD`\n\n*Contoh:*\n`2025-01-01 2025-01-31`\n\n*Catatan:* Setelah periode dipilih, pilih format laporan: CSV, Excel atau PDF.",
+	"fullreport.preset.7d":        `📅 7 hari terakhir`,
+	"fullreport.preset.month":     `🗓️ Bulan ini`,
+	"fullreport.preset.lastmonth": `⏮️ Bulan lalu`,
+	"fullreport.admin_only_toast": `Laporan lengkap hanya tersedia untuk admin.`,
+	"fullreport.progress":         `⏳ Membuat laporan {{.Format}} {{.Start}} s/d {{.End}}... Mohon tunggu.`,
+	"otp.forwarded":               `⛔ Kode OTP yang diteruskan (forward) tidak diterima. Ketik sendiri kode dari aplikasi autentikator Anda.`,
+	"otp.stale":                   `⏳ Pesan OTP Anda sudah terlalu lama diterima. Silakan kirim kode terbaru dari aplikasi autentikator Anda.`,
+	"otp.failure_alert":           `🚨 Peringatan keamanan: {{.Username}} (ID {{.UserID}}) gagal memasukkan OTP {{.Count}} kali dalam {{.Minutes}} menit terakhir.`,
+	"otp.lockout_alert": `🔒 Peringatan keamanan: {{.Username}} (ID {{.UserID}}) dikunci sampai pukul {{.Until}} setelah {{.Count}} OTP salah sejak pukul {{.Since}}.
+Buka kunci lebih awal dengan /otpunlock {{.UserID}}`,
+
+	// /otpfailures
+	"otpfailures.usage":   `/otpfailures [jam]`,
+	"otpfailures.none":    `✅ Tidak ada OTP gagal dalam {{.Hours}} jam terakhir.`,
+	"otpfailures.title":   `🔐 OTP gagal dalam {{.Hours}} jam terakhir: {{.Count}}`,
+	"common.and_more":     `... dan {{.Count}} lainnya`,
+	"otpfailures.locked":  `🔒 Sedang dikunci:`,
+	"otpfailures.lockout": `• ID {{.UserID}}: {{.Count}} OTP salah, sampai pukul {{.Until}} · /otpunlock {{.UserID}}`,
+
+	// /otpunlock
+	"otpunlock.usage":      `/otpunlock [user ID]`,
+	"otpunlock.done":       `🔓 Kunci user {{.UserID}} dibuka, OTP dapat dikirim lagi.`,
+	"otpunlock.not_locked": `ℹ️ User {{.UserID}} tidak sedang dikunci.`,
+	"otpunlock.disabled":   `ℹ️ Penguncian OTP dinonaktifkan (OTP_LOCKOUT_THRESHOLD=0).`,
+	"otpunlock.notice":     `🔓 Admin telah membuka kunci Anda. Silakan kirim OTP Anda lagi.`,
+
+	// /archive
+	"archive.usage": `/archive [tahun]
+
+Contoh: /archive 2023 (mengarsipkan data tahun 2023 dan sebelumnya)`,
+	"archive.invalid_year": `❌ Tahun tidak valid.`,
+	"archive.progress":     `⏳ Mengarsipkan data absensi tahun {{.Year}} dan sebelumnya... Mohon tunggu.`,
+	"archive.future_year":  `❌ Hanya tahun yang sudah lewat yang dapat diarsipkan.`,
+	"archive.interrupted":  `⚠️ Pengarsipan terhenti setelah {{.Moved}} data dipindahkan. Jalankan kembali /archive {{.Year}} untuk melanjutkan.`,
+	"archive.done": `✅ Pengarsipan selesai.
+
+📦 Dipindahkan: {{.Moved}} data ({{.Batches}} batch)
+🗄️ Total di arsip sebelum {{.Before}}: {{.Archived}} data
+
+Laporan dan ekspor tetap menyertakan data arsip.`,
+
+	// /anomalies
+	"anomalies.usage": `/anomalies YYYY-MM-DD YYYY-MM-DD
+
+Contoh: /anomalies 2025-01-01 2025-01-31`,
+	"anomalies.title":         `🔎 Check-out yang Hilang`,
+	"anomalies.period":        `📅 Periode: {{.Start}} s/d {{.End}}`,
+	"anomalies.today_skipped": `ℹ️ Hari ini tidak dihitung karena check-out masih bisa dilakukan.`,
+	"anomalies.none":          `✅ Tidak ada check-in tanpa check-out dalam periode ini.`,
+	"anomalies.total":         `⚠️ Total: {{.Days}} hari dari {{.Users}} karyawan`,
+	"anomalies.more_users":    `... dan {{.Count}} karyawan lainnya`,
+	"anomalies.user":          `• {{.Name}}: {{.Days}} hari`,
+	"anomalies.details":       `Rincian per tanggal ada di file CSV.`,
+
+	// Report files
+	"file.create_csv_failed": `❌ Terjadi kesalahan saat membuat file CSV.`,
+	"file.open_failed":       `❌ Terjadi kesalahan saat membuka file laporan.`,
+	"file.send_failed":       `❌ Terjadi kesalahan saat mengirim laporan.`,
+	"file.create_failed":     `❌ Terjadi kesalahan saat membuat laporan {{.Format}}.`,
+	"common.send_otp":        `📝 Kirimkan kode OTP {{.Digits}} digit Anda untuk absen, atau ketik /help untuk bantuan.`,
+	"history.title":          `📈 *Riwayat Absensi Anda (30 hari terakhir)*`,
+	"history.check_in":       `   ⏰ Masuk: {{.Time}}`,
+	"history.check_out":      `   🏠 Pulang: {{.Time}}`,
+	"history.summary": `*Ringkasan:*
+📊 Total Hari: {{.Days}}
+📝 Total Absensi: {{.Records}}
+⚠️ Terlambat: {{.Late}} hari`,
+	"history.left_early": `⏪ Pulang Awal: {{.Days}} hari`,
+	"history.shift":      `🕘 Shift: {{.Shift}}`,
+	"fullreport.invalid_input": `❌ Format input tidak valid. Gunakan format: YYYY-MM-DD YYYY-MM-DD
+
+Contoh: 2025-01-01 2025-01-31`,
+	"fullreport.invalid_start":   `❌ Tanggal mulai tidak valid. Pastikan format tanggal benar (YYYY-MM-DD).`,
+	"fullreport.invalid_end":     `❌ Tanggal akhir tidak valid. Pastikan format tanggal benar (YYYY-MM-DD).`,
+	"fullreport.start_after_end": `❌ Tanggal mulai tidak boleh lebih besar dari tanggal akhir.`,
+	"fullreport.pick_format":     `📊 Pilih format laporan {{.Start}} s/d {{.End}}:`,
+	"fullreport.unknown_format":  `❌ Format laporan tidak dikenal. Pilih csv, xlsx atau pdf.`,
+	"fullreport.creating":        `⏳ Membuat laporan {{.Format}}... Mohon tunggu.`,
+	"fullreport.empty":           `📭 Tidak ada data absensi dalam rentang tanggal yang ditentukan.`,
+	"fullreport.caption": `📊 *Laporan Absensi*
+
+📅 Periode: {{.Start}} s/d {{.End}}
+📈 Total Records: {{.Records}}`,
+	"fullreport.caption_leave":      `🏖️ Hari Cuti/Izin/Sakit: {{.Days}}`,
+	"fullreport.caption_department": `🏢 Departemen: {{.Department}}`,
+	"fullreport.caption_summary": `📑 *Rekap Absensi*
+
+📅 Periode: {{.Start}} s/d {{.End}}
+👥 Karyawan: {{.Employees}}`,
+
+	// PDF summary, set in a font without emoji
+	"pdf.title":                    `Rekap Absensi`,
+	"pdf.period":                   `Periode: {{.Start}} s/d {{.End}}`,
+	"pdf.department":               `Departemen: {{.Department}}`,
+	"pdf.generated":                `Dibuat {{.Time}} {{.Zone}}`,
+	"pdf.empty":                    `Tidak ada data absensi pada periode ini.`,
+	"pdf.column.no":                `No`,
+	"pdf.column.name":              `Nama`,
+	"pdf.column.employee_id":       `ID Karyawan`,
+	"pdf.column.department":        `Departemen`,
+	"pdf.column.present":           `Hadir`,
+	"pdf.column.late":              `Terlambat`,
+	"pdf.column.average_check_in":  `Rata2 masuk`,
+	"pdf.column.work":              `Kerja (j:mm)`,
+	"pdf.column.missing_checkouts": `Tanpa pulang`,
+	"pdf.column.absent":            `Tidak hadir`,
+	"pdf.working_days":             `Hari kerja: {{.Days}}`,
+	"pdf.total":                    `Total ({{.Count}} karyawan)`,
+	"pdf.prepared_by":              `Dibuat oleh`,
+	"pdf.approved_by":              `Disetujui oleh`,
+	"pdf.signature_date":           `Nama dan tanggal`,
+	"pdf.page":                     `Halaman {{.Page}} dari {{.Pages}}`,
+
+	// Auto check-out
+	"auto_checkout.reminder": `⏰ Anda check-in pukul {{.Time}} hari ini tetapi belum check-out. Kirim kode OTP untuk check-out sebelum tengah malam.`,
+	"auto_checkout.recorded": `🤖 Anda lupa check-out hari ini, jadi check-out otomatis dicatat pukul {{.Time}}. Hubungi admin jika jamnya perlu dikoreksi.`,
+
+	// /remind and reminders
+	"remind.usage": `/remind on [menit] - Ingatkan saya check-in beberapa menit sebelum shift dimulai (bawaan 15) dan check-out saat shift berakhir
+/remind off - Matikan pengingat`,
+	"remind.status_off": `🔕 Pengingat tidak aktif.`,
+	"remind.status_on": `🔔 Pengingat aktif: {{.Lead}} menit sebelum shift dimulai, dan saat shift berakhir jika Anda belum check-out.
+🕘 Shift Anda: {{.Shift}}`,
+	"remind.enabled":      `✅ Pengingat diaktifkan. Anda akan diingatkan {{.Lead}} menit sebelum shift dimulai jika belum check-in, dan saat shift berakhir jika belum check-out.`,
+	"remind.disabled":     `✅ Pengingat dimatikan.`,
+	"remind.not_enabled":  `ℹ️ Pengingat Anda memang tidak aktif.`,
+	"remind.invalid_lead": `❌ Menit pengingat harus berupa angka antara 1 dan {{.Max}}.`,
+	"reminder.check_in":   `⏰ Shift {{.Shift}} Anda dimulai pukul {{.Start}}. Jangan lupa check-in dengan mengirim kode OTP Anda.`,
+	"reminder.check_out":  `🏠 Shift {{.Shift}} Anda berakhir pukul {{.End}} dan Anda belum check-out. Kirim kode OTP Anda untuk check-out.`,
+
+	// /weekly
+	"weekly.usage": `/weekly off - Berhenti menerima ringkasan minggu lalu
+/weekly on - Terima lagi`,
+	"weekly.status_on":   `📅 Setiap minggu Anda menerima ringkasan minggu lalu: hari hadir, hari terlambat, total kerja, dan check-out yang terlewat.`,
+	"weekly.status_off":  `🔕 Anda tidak menerima ringkasan mingguan.`,
+	"weekly.enabled":     `✅ Anda akan menerima ringkasan minggu lalu lagi.`,
+	"weekly.disabled":    `✅ Anda tidak akan menerima ringkasan mingguan lagi.`,
+	"weekly.unavailable": `ℹ️ Ringkasan mingguan tidak diaktifkan di bot ini.`,
+	"weekly.summary": `📅 *Minggu Anda, {{.Start}} sampai {{.End}}*
+
+✅ Hadir: {{.Present}} dari {{.WorkingDays}} hari kerja
+⏰ Terlambat: {{.Late}} hari
+⏱️ Total kerja: {{.WorkDuration}}
+⚠️ Tanpa check-out: {{.MissingCheckouts}} hari
+
+Berhenti menerima ringkasan ini dengan /weekly off.`,
+
+	// Proof-of-presence photos and /photo
+	"photo.prompt":           `📸 Kirim foto selfie dalam {{.Minutes}} menit sebagai bukti kehadiran.`,
+	"photo.attached":         `✅ Foto dilampirkan pada check-in pukul {{.Time}}.`,
+	"photo.no_check_in":      `❌ Foto hanya dapat dilampirkan dalam {{.Minutes}} menit setelah check-in dengan kode OTP. Kirim kode OTP Anda terlebih dahulu.`,
+	"photo.already_attached": `ℹ️ Check-in Anda hari ini sudah memiliki foto.`,
+	"photo.forwarded":        `❌ Foto yang diteruskan tidak diterima. Ambil dan kirim foto Anda sendiri.`,
+	"photo.usage": `/photo [ID absensi]
+
+ID absensi ada di kolom ID laporan CSV.`,
+	"photo.not_found": `❌ Data absensi dengan ID {{.ID}} tidak ditemukan.`,
+	"photo.none":      `ℹ️ Data absensi {{.ID}} tidak memiliki foto.`,
+	"photo.caption":   `{{.Name}} - {{.Type}} {{.Date}} {{.Time}}`,
+
+	// Locations and /geofence
+	"geofence.shared_inside":     `📍 Lokasi diterima: Anda berada di area {{.Geofence}}. Kirim kode OTP Anda dalam {{.Minutes}} menit.`,
+	"geofence.shared_outside":    `📍 Lokasi diterima, tetapi Anda berada di luar area kantor ({{.Distance}} m dari {{.Geofence}}). Kirim kode OTP Anda dalam {{.Minutes}} menit.`,
+	"geofence.shared":            `📍 Lokasi diterima. Kirim kode OTP Anda dalam {{.Minutes}} menit.`,
+	"geofence.forwarded":         `❌ Lokasi yang diteruskan tidak diterima. Bagikan lokasi Anda sendiri.`,
+	"geofence.location_required": `📍 Check-in memerlukan lokasi Anda. Bagikan lokasi Anda (📎 > Lokasi), lalu kirim kode OTP dalam {{.Minutes}} menit.`,
+	"geofence.outside":           `❌ Anda berada di luar area kantor ({{.Distance}} m dari {{.Geofence}}), jadi check-in tidak dicatat. Bagikan lokasi Anda lagi setelah tiba di kantor.`,
+	"geofence.usage": `/geofence list
+/geofence set [nama] [lintang] [bujur] [radius meter]
+/geofence delete [nama]
+
+Koordinat memakai derajat desimal, mis. -6.200000 106.816666.`,
+	"geofence.list_title": `📍 Area kantor (mode: {{.Mode}})`,
+	"geofence.list_empty": `Belum ada area. Tanpa area, check-in tidak diperiksa lokasinya.`,
+	"geofence.entry":      `• {{.Name}}: {{.Latitude}}, {{.Longitude}}, radius {{.Radius}} m`,
+	"geofence.invalid":    `❌ Area tidak valid. Nama hanya boleh berisi huruf kecil, angka, dan tanda hubung; lintang -90 sampai 90; bujur -180 sampai 180; radius 1-{{.MaxRadius}} meter.`,
+	"geofence.saved":      `✅ Area disimpan: {{.Geofence}}`,
+	"geofence.not_found":  `ℹ️ Area {{.Name}} tidak ditemukan.`,
+	"geofence.deleted":    `🗑️ Area {{.Name}} dihapus.`,
+
+	// Group chats and /register
+	"group.private_only":      `🔒 {{.Command}} hanya bisa digunakan di chat pribadi dengan bot.`,
+	"group.register_in_group": `ℹ️ Kirim /register atau /unregister di grup yang ingin dijadikan kanal kantor.`,
+	"group.registered": `🏢 Grup ini sekarang menjadi kanal kantor. {{.Note}}
+Anggota dapat mengirim OTP {{.Digits}} digit di sini; bot menghapusnya setelah diproses, jadi bot memerlukan izin menghapus pesan.`,
+	"group.report_daily":       `Laporan harian dikirim ke sini setiap hari pukul {{.Clock}} {{.Zone}}.`,
+	"group.report_disabled":    `Laporan harian tidak dijadwalkan (DAILY_REPORT_TIME nonaktif).`,
+	"group.already_registered": `ℹ️ Grup ini sudah menjadi kanal kantor.`,
+	"group.unregistered":       `✅ Grup ini tidak lagi menjadi kanal kantor.`,
+	"group.not_registered":     `ℹ️ Grup ini bukan kanal kantor.`,
+
+	// /enroll
+	"enroll.usage": `/enroll [user_id]`,
+	"enroll.caption": `🔐 Scan QR code ini dengan aplikasi autentikator Anda (Google Authenticator, Authy, dll.), lalu kirim kode {{.Digits}} digit yang ditampilkan untuk absen masuk atau pulang.
+
+Jaga kerahasiaannya: siapa pun yang memiliki QR code ini dapat membuat kode Anda. Hapus pesan ini setelah discan.`,
+	"enroll.sent":        `✅ QR code telah dikirim ke user ID {{.UserID}} di chat pribadinya dengan bot.`,
+	"enroll.send_failed": `❌ Gagal mengirim QR code ke user ID {{.UserID}}. Minta pengguna mengirim /start ke bot terlebih dahulu, lalu coba lagi.`,
+
+	// /rotatesecret
+	"rotate.explain": `🔄 /rotatesecret confirm mengganti secret TOTP bersama dengan yang baru.
+
+Kode dari secret lama tetap berlaku selama {{.Days}} hari (TOTP_ROTATION_GRACE_DAYS). Semua yang tercatat absen dalam 90 hari terakhir akan dikirimi QR code baru di chat pribadinya; pengguna dengan lembar kode pribadi tidak terpengaruh.`,
+	"rotate.encryption_key_missing": `❌ SECRETS_ENCRYPTION_KEY belum diatur, sehingga secret baru tidak dapat disimpan.`,
+	"rotate.in_progress":            `⏳ Penggantian sebelumnya masih dalam masa tenggang hingga {{.Until}}. Ganti lagi setelah itu, agar tidak ada yang tertinggal dengan secret yang sudah tidak berlaku.`,
+	"rotate.done":                   `✅ Secret TOTP telah diganti. Secret lama berlaku hingga {{.Until}}. Mengirim QR code baru ke {{.Count}} pengguna...`,
+	"rotate.notified":               `📨 QR code baru telah dikirim ke {{.Sent}} pengguna.`,
+	"rotate.notified_failed": `📨 QR code baru telah dikirim ke {{.Sent}} pengguna. {{.Failed}} pengguna tidak dapat dihubungi (belum pernah memulai bot atau memblokirnya): {{.UserIDs}}
+Kirimkan dengan /enroll setelah mereka mengirim /start ke bot.`,
+	"rotate.notice": `🔄 Secret kode absensi telah diganti. Scan QR code ini dengan aplikasi autentikator Anda (Google Authenticator, Authy, dll.) dan mulai sekarang kirim kode {{.Digits}} digit yang ditampilkan.
+
+Kode lama Anda tetap berlaku hingga {{.Until}}. Jaga kerahasiaan QR code ini dan hapus pesan ini setelah discan.`,
+
+	// /fix and /auditlog
+	"fix.usage": `/fix [user ID] [YYYY-MM-DD] [in|out] [HH:MM] [alasan]
+/fix [user ID] [YYYY-MM-DD] [in|out] delete [alasan]
+
+Contoh: /fix 123456789 2025-03-10 out 17:30 lupa absen pulang`,
+	"fix.reason_too_long":        `❌ Alasan terlalu panjang (maksimal {{.Max}} karakter).`,
+	"fix.archived":               `❌ Absensi sebelum {{.Before}} sudah diarsipkan dan tidak dapat dikoreksi.`,
+	"fix.nothing_to_delete":      `ℹ️ Tidak ada catatan untuk dihapus pada tanggal itu.`,
+	"fix.delete_check_out_first": `❌ Hapus absen pulang terlebih dahulu sebelum absen masuk.`,
+	"fix.check_in_first":         `❌ Belum ada absen masuk pada tanggal itu. Tambahkan absen masuk terlebih dahulu.`,
+	"fix.after_check_out":        `❌ Absen masuk harus sebelum absen pulang ({{.Time}}).`,
+	"fix.future":                 `❌ Waktu koreksi belum terjadi.`,
+	"fix.unknown_user":           `❌ User {{.UserID}} tidak memiliki absensi dalam {{.Days}} hari terakhir, jadi namanya tidak diketahui.`,
+	"fix.done": `✅ Absensi dikoreksi dan dicatat di log audit:
+{{.Entry}}`,
+	"auditlog.usage": `/auditlog [user ID]
+/auditlog csv [YYYY-MM-DD] [YYYY-MM-DD]`,
+	"auditlog.empty":                        `ℹ️ Log audit masih kosong.`,
+	"auditlog.title":                        `🧾 {{.Count}} entri log audit terbaru:`,
+	"auditlog.entry":                        `• {{.Time}} {{.Action}} oleh {{.ActorID}} untuk {{.UserID}}: {{.Details}}`,
+	"auditlog.entry_no_user":                `• {{.Time}} {{.Action}} oleh {{.ActorID}}: {{.Details}}`,
+	"auditlog.empty_range":                  `ℹ️ Tidak ada entri log audit dari {{.Start}} sampai {{.End}}.`,
+	"auditlog.caption":                      `🧾 Log audit {{.Start}} sampai {{.End}}: {{.Count}} entri`,
+	"auditlog.reason":                       `Alasan: {{.Reason}}`,
+	"auditlog.action.attendance_added":      `Absensi ditambahkan`,
+	"auditlog.action.attendance_changed":    `Absensi diubah`,
+	"auditlog.action.attendance_deleted":    `Absensi dihapus`,
+	"auditlog.action.report_downloaded":     `Laporan diunduh`,
+	"auditlog.action.alias_set":             `Alias diatur`,
+	"auditlog.action.alias_approved":        `Alias disetujui`,
+	"auditlog.action.alias_rejected":        `Alias ditolak`,
+	"auditlog.action.alias_cleared":         `Alias dihapus`,
+	"auditlog.action.otp_failed":            `OTP gagal`,
+	"auditlog.action.otp_locked":            `Dikunci`,
+	"auditlog.action.otp_unlocked":          `Kunci dibuka`,
+	"auditlog.action.group_registered":      `Grup didaftarkan`,
+	"auditlog.action.group_unregistered":    `Pendaftaran grup dihapus`,
+	"auditlog.action.totp_enrolled":         `QR code dikirim`,
+	"auditlog.action.totp_rotated":          `Secret diganti`,
+	"auditlog.action.employee_registered":   `Karyawan didaftarkan`,
+	"auditlog.action.employee_unregistered": `Pendaftaran karyawan dihapus`,
+	"auditlog.action.attendance_imported":   `Absensi diimpor`,
+	"auditlog.action.holiday_added":         `Hari libur ditambahkan`,
+	"auditlog.action.holiday_removed":       `Hari libur dihapus`,
+	"auditlog.action.holidays_imported":     `Hari libur diimpor`,
+	"auditlog.action.backup_created":        `Cadangan dibuat`,
+	"auditlog.action.admin_added":           `Admin ditambahkan`,
+	"auditlog.action.admin_removed":         `Admin dihapus`,
+}
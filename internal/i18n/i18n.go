@@ -0,0 +1,129 @@
+// Package i18n translates user-facing messages. Each language has a catalog mapping message keys
+// to text/template strings; the Indonesian catalog is the reference every other catalog must match.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Default is the language of chats without a preference, such as groups and the admin chat
+const Default = "id"
+
+// catalogs holds the message templates per language
+var catalogs = map[string]map[string]string{
+	"id": indonesian,
+	"en": english,
+}
+
+// names describes each supported language in its own language
+var names = map[string]string{
+	"id": "Bahasa Indonesia",
+	"en": "English",
+}
+
+// templates holds the parsed catalogs, per language and key
+var templates = make(map[string]map[string]*template.Template)
+
+func init() {
+	if err := parseCatalogs(); err != nil {
+		panic(err)
+	}
+}
+
+// parseCatalogs parses every template and checks that all catalogs have the same keys
+func parseCatalogs() error {
+	for lang, catalog := range catalogs {
+		templates[lang] = make(map[string]*template.Template, len(catalog))
+		for key, text := range catalog {
+			if _, ok := catalogs[Default][key]; !ok {
+				return fmt.Errorf("i18n: %s message %q is not in the %s catalog", lang, key, Default)
+			}
+			parsed, err := template.New(key).Option("missingkey=error").Parse(text)
+			if err != nil {
+				return fmt.Errorf("i18n: invalid %s message %q: %w", lang, key, err)
+			}
+			templates[lang][key] = parsed
+		}
+		for key := range catalogs[Default] {
+			if _, ok := catalog[key]; !ok {
+				return fmt.Errorf("i18n: %s catalog is missing message %q", lang, key)
+			}
+		}
+	}
+	return nil
+}
+
+// T renders the message key in lang with the given key-value pairs as template data, e.g.
+// T("en", "otp.invalid_format", "Digits", 6). Unsupported languages fall back to Default, and an
+// unknown key or a failing template renders the key itself so the problem is visible.
+func T(lang, key string, args ...any) string {
+	parsed, ok := templates[lang][key]
+	if !ok {
+		parsed, ok = templates[Default][key]
+	}
+	if !ok {
+		return key
+	}
+
+	data := make(map[string]any, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		data[fmt.Sprint(args[i])] = args[i+1]
+	}
+
+	var text strings.Builder
+	if err := parsed.Execute(&text, data); err != nil {
+		return key
+	}
+	return text.String()
+}
+
+// Normalize returns the supported language matching a Telegram language_code such as "en-US",
+// or Default if there is none
+func Normalize(code string) string {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	if _, ok := catalogs[base]; ok {
+		return base
+	}
+	return Default
+}
+
+// IsSupported reports whether lang has a catalog
+func IsSupported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Supported returns the supported language codes in sorted order
+func Supported() []string {
+	codes := make([]string, 0, len(catalogs))
+	for code := range catalogs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Name returns the name of a supported language in that language
+func Name(lang string) string {
+	return names[lang]
+}
+
+// contextKey is the context key of the language of the current update
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying lang
+func NewContext(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext returns the language carried by ctx, or Default
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok {
+		return lang
+	}
+	return Default
+}
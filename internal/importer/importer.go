@@ -0,0 +1,324 @@
+// Package importer loads historical attendance from CSV files, as written by hand or exported from
+// a previous attendance system. It is shared by the bot's /import command and the import CLIs.
+package importer
+
+import (
+	"attendance-bot/internal/database"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Columns are the expected CSV header columns, in order
+var Columns = []string{"user_id", "name", "date", "type", "time"}
+
+// ErrInvalidFile is returned for a file that is not a CSV file with the Columns header
+var ErrInvalidFile = errors.New("invalid import file")
+
+// Reject is a CSV row that could not be imported
+type Reject struct {
+	Line   int
+	Row    []string
+	Reason string
+}
+
+// Result reports the outcome of an import
+type Result struct {
+	Rows       int      `json:"rows"`
+	Valid      int      `json:"valid"`
+	Inserted   int      `json:"inserted"`
+	Duplicates int      `json:"duplicates"`
+	Errors     int      `json:"errors"`
+	Resolved   int      `json:"resolved"` // Valid rows whose user was found by name or employee ID
+	DryRun     bool     `json:"dry_run"`
+	Dates      []string `json:"-"` // Distinct dates of the inserted rows
+	Rejects    []Reject `json:"-"` // Invalid and duplicate rows, in that order
+}
+
+// Import validates every row of a CSV file with the Columns header and, unless dryRun is set,
+// inserts the valid ones in a single transaction. Rows already recorded are skipped as duplicates.
+//
+// The user_id column holds a Telegram user ID, or the employee ID of an entry of the employee
+// directory, or is left empty to find the registered employee by name. Rows whose name matches
+// no registered employee, or several, are rejected. So are rows dated before the archive boundary,
+// whose days are read-only.
+func Import(ctx context.Context, repo database.Repository, r io.Reader, dryRun bool) (*Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV header: %v", ErrInvalidFile, err)
+	}
+	for i, column := range Columns {
+		if i >= len(header) || strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))) != column {
+			return nil, fmt.Errorf("%w: unexpected CSV header %v, expected %v", ErrInvalidFile, header, Columns)
+		}
+	}
+
+	archivedBefore, err := repo.GetArchivedBefore(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archive boundary: %w", err)
+	}
+
+	result := &Result{DryRun: dryRun}
+	users := &userResolver{repo: repo, byName: make(map[string]nameLookup), byEmployeeID: make(map[string]int64)}
+	now := time.Now()
+
+	var records []models.AttendanceRecord
+	var rows [][]string
+	var lines []int
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				result.Rejects = append(result.Rejects, Reject{Line: line, Row: row, Reason: err.Error()})
+				continue
+			}
+			return nil, fmt.Errorf("%w: failed to read CSV: %v", ErrInvalidFile, err)
+		}
+
+		record, resolved, err := parseRow(ctx, users, row, now, archivedBefore)
+		if errors.Is(err, errLookup) {
+			return nil, err
+		}
+		if err != nil {
+			result.Rejects = append(result.Rejects, Reject{Line: line, Row: row, Reason: err.Error()})
+			continue
+		}
+
+		if resolved {
+			result.Resolved++
+		}
+		records = append(records, *record)
+		rows = append(rows, row)
+		lines = append(lines, line)
+	}
+
+	result.Rows = len(records) + len(result.Rejects)
+	result.Valid = len(records)
+	result.Errors = len(result.Rejects)
+
+	if dryRun || len(records) == 0 {
+		return result, nil
+	}
+
+	inserted, duplicates, err := repo.InsertAttendanceBatch(ctx, records)
+	if err != nil {
+		return nil, err
+	}
+	result.Inserted = inserted
+	result.Duplicates = len(duplicates)
+
+	skipped := make(map[int]bool, len(duplicates))
+	for _, i := range duplicates {
+		skipped[i] = true
+		result.Rejects = append(result.Rejects, Reject{
+			Line:   lines[i],
+			Row:    rows[i],
+			Reason: "duplicate: record already exists",
+		})
+	}
+
+	dates := make(map[string]bool)
+	for i, record := range records {
+		if !skipped[i] && !dates[record.Date] {
+			dates[record.Date] = true
+			result.Dates = append(result.Dates, record.Date)
+		}
+	}
+
+	return result, nil
+}
+
+// WriteRejects writes rejected rows as CSV with their line number and reason
+func WriteRejects(w io.Writer, rejects []Reject) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append([]string{"line", "reason"}, Columns...)); err != nil {
+		return fmt.Errorf("failed to write rejects header: %w", err)
+	}
+
+	for _, reject := range rejects {
+		row := append([]string{fmt.Sprintf("%d", reject.Line), reject.Reason}, reject.Row...)
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write rejected row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// errLookup indicates the database could not be asked who a row belongs to, which fails the
+// whole import rather than the row
+var errLookup = errors.New("failed to look up user")
+
+// parseRow converts a CSV row into an attendance record, interpreting times in utils.Location. It
+// reports whether the user was found by name or employee ID rather than given as a Telegram user ID.
+// Dates before archivedBefore, unless it is empty, are refused.
+func parseRow(ctx context.Context, users *userResolver, row []string, now time.Time, archivedBefore string) (*models.AttendanceRecord, bool, error) {
+	if len(row) != len(Columns) {
+		return nil, false, fmt.Errorf("expected %d columns, got %d", len(Columns), len(row))
+	}
+
+	nameParts := strings.Fields(row[1])
+	if len(nameParts) == 0 {
+		return nil, false, fmt.Errorf("name is empty")
+	}
+	firstName := utils.SanitizeName(nameParts[0])
+	if firstName == "" {
+		return nil, false, fmt.Errorf("invalid name %q", row[1])
+	}
+	var lastName *string
+	if len(nameParts) > 1 {
+		if lastNameVal := utils.SanitizeName(strings.Join(nameParts[1:], " ")); lastNameVal != "" {
+			lastName = &lastNameVal
+		}
+	}
+
+	date := strings.TrimSpace(row[2])
+	if _, err := utils.ParseDate(date); err != nil || !utils.IsValidDateFormat(date) {
+		return nil, false, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", row[2])
+	}
+	if archivedBefore != "" && date < archivedBefore {
+		return nil, false, fmt.Errorf("date %s is archived, days before %s cannot be imported", date, archivedBefore)
+	}
+
+	attendanceType := strings.TrimSpace(row[3])
+	if attendanceType != "check_in" && attendanceType != "check_out" {
+		return nil, false, fmt.Errorf("invalid type %q, expected check_in or check_out", row[3])
+	}
+
+	timestamp, err := parseTime(date, strings.TrimSpace(row[4]))
+	if err != nil {
+		return nil, false, err
+	}
+	if timestamp.After(now) {
+		return nil, false, fmt.Errorf("time %s %s is in the future", date, strings.TrimSpace(row[4]))
+	}
+
+	userID, resolved, err := users.resolve(ctx, strings.TrimSpace(row[0]), row[1])
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &models.AttendanceRecord{
+		UserID:    userID,
+		Username:  fmt.Sprintf("user_%d", userID),
+		FirstName: firstName,
+		LastName:  lastName,
+		Timestamp: timestamp,
+		Type:      attendanceType,
+		Date:      date,
+		Source:    models.SourceAdmin,
+	}, resolved, nil
+}
+
+// parseTime parses HH:MM or HH:MM:SS on the given date in utils.Location
+func parseTime(date, clock string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02 15:04:05"} {
+		if timestamp, err := time.ParseInLocation(layout, date+" "+clock, utils.Location); err == nil {
+			return timestamp, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected HH:MM or HH:MM:SS", clock)
+}
+
+// userResolver finds the Telegram user ID of a row, remembering the answers so that a file with
+// many rows per employee looks each of them up once. A zero ID is remembered for unknown employee IDs.
+type userResolver struct {
+	repo         database.Repository
+	byName       map[string]nameLookup
+	byEmployeeID map[string]int64
+}
+
+// nameLookup is the user a name was resolved to, or why it was not
+type nameLookup struct {
+	userID int64
+	err    error
+}
+
+// resolve returns the Telegram user ID given in the user_id column, else the user registered in
+// the employee directory under that employee ID or, when the column is empty, under the name
+func (u *userResolver) resolve(ctx context.Context, value, name string) (int64, bool, error) {
+	if value == "" {
+		userID, err := u.resolveName(ctx, name)
+		return userID, true, err
+	}
+
+	if userID, err := utils.ParseInteger(value); err == nil {
+		if !utils.IsValidTelegramUserID(userID) {
+			return 0, false, fmt.Errorf("invalid user_id %q", value)
+		}
+		return userID, false, nil
+	}
+
+	userID, ok := u.byEmployeeID[value]
+	if !ok {
+		employee, err := u.repo.FindRegisteredEmployee(ctx, value)
+		if err != nil {
+			return 0, false, fmt.Errorf("%w: %v", errLookup, err)
+		}
+		if employee != nil {
+			userID = employee.UserID
+		}
+		u.byEmployeeID[value] = userID
+	}
+	if userID == 0 {
+		return 0, false, fmt.Errorf("unknown employee ID %q", value)
+	}
+	return userID, true, nil
+}
+
+// resolveName returns the registered employee whose alias or Telegram name is name
+func (u *userResolver) resolveName(ctx context.Context, name string) (int64, error) {
+	key := utils.NameKey(name, "")
+	if lookup, ok := u.byName[key]; ok {
+		return lookup.userID, lookup.err
+	}
+
+	matches, err := u.repo.FindNameMatches(ctx, 0, key)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errLookup, err)
+	}
+
+	var candidates []int64
+	seen := make(map[int64]bool)
+	for _, match := range matches {
+		if seen[match.UserID] {
+			continue
+		}
+		seen[match.UserID] = true
+
+		employee, err := u.repo.GetRegisteredEmployee(ctx, match.UserID)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", errLookup, err)
+		}
+		if employee != nil {
+			candidates = append(candidates, match.UserID)
+		}
+	}
+
+	var lookup nameLookup
+	switch len(candidates) {
+	case 0:
+		lookup.err = fmt.Errorf("no registered employee named %q, set user_id", strings.TrimSpace(name))
+	case 1:
+		lookup.userID = candidates[0]
+	default:
+		lookup.err = fmt.Errorf("name %q matches %d registered employees, set user_id", strings.TrimSpace(name), len(candidates))
+	}
+	u.byName[key] = lookup
+	return lookup.userID, lookup.err
+}
@@ -0,0 +1,159 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// RotatingWriter is an io.Writer appending to a log file, rotating it once it would exceed
+// MaxSize. Backups are named <path>.1 (newest) to <path>.N. It is safe for concurrent use.
+type RotatingWriter struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// RotateOptions configures a RotatingWriter
+type RotateOptions struct {
+	MaxSize    int64         // Bytes before the file is rotated, 0 disables rotation
+	MaxBackups int           // Rotated files to keep, 0 keeps none
+	MaxAge     time.Duration // Rotated files older than this are removed, 0 keeps them regardless of age
+}
+
+// NewRotatingWriter opens path for appending, creating it and its directory if needed
+func NewRotatingWriter(path string, options *RotateOptions) (*RotatingWriter, error) {
+	if options == nil {
+		options = &RotateOptions{}
+	}
+
+	w := &RotatingWriter{
+		path:       path,
+		maxSize:    options.MaxSize,
+		maxBackups: options.MaxBackups,
+		maxAge:     options.MaxAge,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Write appends p to the log file, rotating first if p would push it past the size limit
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Reopen closes and reopens the log file, for use after an external tool such as logrotate moved it
+func (w *RotatingWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("failed to close log file: %w", err)
+		}
+	}
+	return w.open()
+}
+
+// Close closes the log file
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// open opens the log file for appending and records its current size
+func (w *RotatingWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// rotate shifts the backups up by one, moves the current file to <path>.1 and starts a new file
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	w.file = nil
+
+	if w.maxBackups > 0 {
+		os.Remove(w.backupPath(w.maxBackups))
+		for i := w.maxBackups - 1; i >= 1; i-- {
+			if err := os.Rename(w.backupPath(i), w.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to shift log backup: %w", err)
+			}
+		}
+		if err := os.Rename(w.path, w.backupPath(1)); err != nil {
+			return fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	} else if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove log file: %w", err)
+	}
+
+	w.removeExpired()
+	return w.open()
+}
+
+// removeExpired deletes backups older than the configured maximum age
+func (w *RotatingWriter) removeExpired() {
+	if w.maxAge <= 0 {
+		return
+	}
+
+	cutoff := time.Now().Add(-w.maxAge)
+	for i := 1; i <= w.maxBackups; i++ {
+		info, err := os.Stat(w.backupPath(i))
+		if err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(w.backupPath(i))
+		}
+	}
+}
+
+// backupPath returns the path of the n-th most recent backup
+func (w *RotatingWriter) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", w.path, n)
+}
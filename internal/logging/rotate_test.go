@@ -0,0 +1,210 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestWriter opens a rotating writer on app.log in a temporary directory
+func newTestWriter(t *testing.T, options *RotateOptions) (*RotatingWriter, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	w, err := NewRotatingWriter(path, options)
+	if err != nil {
+		t.Fatalf("NewRotatingWriter: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w, path
+}
+
+// write writes each line to w
+func write(t *testing.T, w *RotatingWriter, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write(%q): %v", line, err)
+		}
+	}
+}
+
+// assertFiles checks the contents of files, where "" means the file must not exist
+func assertFiles(t *testing.T, want map[string]string) {
+	t.Helper()
+	for path, content := range want {
+		data, err := os.ReadFile(path)
+		if content == "" {
+			if !os.IsNotExist(err) {
+				t.Errorf("%s exists with %q, want it absent", filepath.Base(path), data)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ReadFile(%s): %v", filepath.Base(path), err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", filepath.Base(path), data, content)
+		}
+	}
+}
+
+func TestRotatingWriterRotatesAtSize(t *testing.T) {
+	w, path := newTestWriter(t, &RotateOptions{MaxSize: 10, MaxBackups: 2})
+
+	write(t, w, "aaaa\n", "bbbb\n")
+	assertFiles(t, map[string]string{path: "aaaa\nbbbb\n", path + ".1": ""})
+
+	write(t, w, "cccc\n")
+	assertFiles(t, map[string]string{path: "cccc\n", path + ".1": "aaaa\nbbbb\n"})
+
+	write(t, w, "dddd\n", "eeee\n")
+	assertFiles(t, map[string]string{path: "eeee\n", path + ".1": "cccc\ndddd\n", path + ".2": "aaaa\nbbbb\n"})
+
+	// The oldest backup is dropped beyond MaxBackups
+	write(t, w, "ffff\n", "gggg\n")
+	assertFiles(t, map[string]string{
+		path:        "gggg\n",
+		path + ".1": "eeee\nffff\n",
+		path + ".2": "cccc\ndddd\n",
+		path + ".3": "",
+	})
+}
+
+func TestRotatingWriterOversizedWrite(t *testing.T) {
+	w, path := newTestWriter(t, &RotateOptions{MaxSize: 4, MaxBackups: 1})
+
+	// A write larger than MaxSize goes to an empty file whole rather than rotating forever
+	write(t, w, "0123456789\n")
+	assertFiles(t, map[string]string{path: "0123456789\n", path + ".1": ""})
+
+	write(t, w, "x\n")
+	assertFiles(t, map[string]string{path: "x\n", path + ".1": "0123456789\n"})
+}
+
+func TestRotatingWriterWithoutBackups(t *testing.T) {
+	w, path := newTestWriter(t, &RotateOptions{MaxSize: 10})
+
+	write(t, w, "aaaa\n", "bbbb\n", "cccc\n")
+	assertFiles(t, map[string]string{path: "cccc\n", path + ".1": ""})
+}
+
+func TestRotatingWriterWithoutMaxSize(t *testing.T) {
+	w, path := newTestWriter(t, nil)
+
+	write(t, w, strings.Repeat("x", 4096), "\n")
+	assertFiles(t, map[string]string{path: strings.Repeat("x", 4096) + "\n", path + ".1": ""})
+}
+
+func TestRotatingWriterCountsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("earlier\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewRotatingWriter(path, &RotateOptions{MaxSize: 10, MaxBackups: 1})
+	if err != nil {
+		t.Fatalf("NewRotatingWriter: %v", err)
+	}
+	defer w.Close()
+
+	write(t, w, "now\n")
+	assertFiles(t, map[string]string{path: "now\n", path + ".1": "earlier\n"})
+}
+
+func TestRotatingWriterRemovesExpiredBackups(t *testing.T) {
+	w, path := newTestWriter(t, &RotateOptions{MaxSize: 10, MaxBackups: 3, MaxAge: time.Hour})
+
+	write(t, w, "aaaa\n", "bbbb\n", "cccc\n")
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(path+".1", old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// The old backup is shifted to .2 and then removed for its age; the new .1 is kept
+	write(t, w, "dddd\n", "eeee\n")
+	assertFiles(t, map[string]string{path: "eeee\n", path + ".1": "cccc\ndddd\n", path + ".2": ""})
+}
+
+func TestRotatingWriterReopen(t *testing.T) {
+	w, path := newTestWriter(t, nil)
+
+	write(t, w, "before\n")
+	if err := os.Rename(path, path+".moved"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reopen(); err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	write(t, w, "after\n")
+
+	assertFiles(t, map[string]string{path: "after\n", path + ".moved": "before\n"})
+}
+
+func TestRotatingWriterClosed(t *testing.T) {
+	w, _ := newTestWriter(t, nil)
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := w.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write after Close = %v, want %v", err, os.ErrClosed)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close = %v, want nil", err)
+	}
+}
+
+// TestRotatingWriterConcurrent writes from several goroutines with a tiny size: no line is lost,
+// split between files or pushes a file past MaxSize
+func TestRotatingWriterConcurrent(t *testing.T) {
+	const writers, lines = 8, 50
+	w, path := newTestWriter(t, &RotateOptions{MaxSize: 100, MaxBackups: writers * lines})
+
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range lines {
+				if _, err := fmt.Fprintf(w, "w%d l%03d\n", i, j); err != nil {
+					t.Errorf("Write: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	files, err := filepath.Glob(path + "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > 100 {
+			t.Errorf("%s has %d bytes, want at most 100", filepath.Base(file), len(data))
+		}
+		for _, line := range strings.SplitAfter(string(data), "\n") {
+			if line == "" {
+				continue
+			}
+			if len(line) != len("w0 l000\n") {
+				t.Errorf("%s has a torn line %q", filepath.Base(file), line)
+			}
+			seen[line] = true
+		}
+	}
+	if len(seen) != writers*lines {
+		t.Errorf("lines found = %d, want %d", len(seen), writers*lines)
+	}
+}
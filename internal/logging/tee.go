@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// teeHandler sends each record to every handler that accepts its level
+type teeHandler struct {
+	handlers []slog.Handler
+}
+
+// NewTeeHandler returns a handler writing to all of handlers, each filtering by its own level
+func NewTeeHandler(handlers ...slog.Handler) slog.Handler {
+	return &teeHandler{handlers: handlers}
+}
+
+// Enabled reports whether any handler accepts the level
+func (t *teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t.handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle passes the record to each handler that accepts its level
+func (t *teeHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, h := range t.handlers {
+		if h.Enabled(ctx, record.Level) {
+			if err := h.Handle(ctx, record.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a tee of the handlers with the attributes added
+func (t *teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(t.handlers))
+	for i, h := range t.handlers {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return &teeHandler{handlers: handlers}
+}
+
+// WithGroup returns a tee of the handlers with the group opened
+func (t *teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(t.handlers))
+	for i, h := range t.handlers {
+		handlers[i] = h.WithGroup(name)
+	}
+	return &teeHandler{handlers: handlers}
+}
+
+// ParseLevel converts a configured level name to a slog.Level, defaulting to info
+func ParseLevel(name string) slog.Level {
+	switch name {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
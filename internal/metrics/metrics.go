@@ -0,0 +1,253 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the histogram upper bounds in seconds
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics exposed by the bot. Callers only increment or observe; registration happens here.
+var (
+	UpdatesReceived = NewCounterVec("attendance_bot_updates_received_total",
+		"Telegram updates received from getUpdates.")
+	UpdatesHandled = NewCounterVec("attendance_bot_updates_handled_total",
+		"Telegram updates handled, by result.", "result")
+	Commands = NewCounterVec("attendance_bot_commands_total",
+		"Bot commands received, by command name.", "command")
+	AttendanceMarks = NewCounterVec("attendance_bot_attendance_marks_total",
+		"MarkAttendance calls, by outcome.", "outcome")
+	ReportDuration = NewHistogramVec("attendance_bot_report_duration_seconds",
+		"Time spent generating reports, by report type.", DefaultBuckets, "report")
+	DBQueryDuration = NewHistogramVec("attendance_bot_db_query_duration_seconds",
+		"Repository query durations, by query.", DefaultBuckets, "query")
+	TelegramRequests = NewCounterVec("attendance_bot_telegram_requests_total",
+		"Outgoing Telegram Bot API calls, by method and HTTP status.", "method", "status")
+	WebhookDeliveries = NewCounterVec("attendance_bot_webhook_deliveries_total",
+		"Event webhook deliveries, by event and outcome.", "event", "outcome")
+)
+
+// collector is a metric family that can write itself in the Prometheus text format
+type collector interface {
+	name() string
+	write(w io.Writer)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []collector
+)
+
+// register adds a metric family to the exposition output
+func register(c collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, c)
+}
+
+// Handler serves all registered metrics in the Prometheus text exposition format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w)
+	})
+}
+
+// WriteText writes all registered metrics in the Prometheus text exposition format
+func WriteText(w io.Writer) {
+	registryMu.Lock()
+	collectors := append([]collector(nil), registry...)
+	registryMu.Unlock()
+
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].name() < collectors[j].name() })
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// CounterVec is a monotonically increasing counter partitioned by labels
+type CounterVec struct {
+	metricName string
+	help       string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounterVec creates and registers a counter with the given label names
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		values:     make(map[string]float64),
+	}
+	register(c)
+	return c
+}
+
+// Inc adds one to the counter for the given label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta to the counter for the given label values
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	key := labelKey(c.labels, labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] += delta
+}
+
+func (c *CounterVec) name() string { return c.metricName }
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.metricName, c.help, c.metricName)
+	if len(c.labels) == 0 && len(c.values) == 0 {
+		fmt.Fprintf(w, "%s 0\n", c.metricName)
+		return
+	}
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.metricName, key, formatFloat(c.values[key]))
+	}
+}
+
+// HistogramVec samples observations into cumulative buckets, partitioned by labels
+type HistogramVec struct {
+	metricName string
+	help       string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogram
+}
+
+// histogram holds the bucket counts of one label combination
+type histogram struct {
+	labelValues []string
+	counts      []uint64 // Non-cumulative count per bucket
+	count       uint64
+	sum         float64
+}
+
+// NewHistogramVec creates and registers a histogram with the given buckets and label names
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		buckets:    buckets,
+		series:     make(map[string]*histogram),
+	}
+	register(h)
+	return h
+}
+
+// Observe records a value for the given label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := labelKey(h.labels, labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+			break
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+// ObserveSince records the seconds elapsed since start
+func (h *HistogramVec) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+func (h *HistogramVec) name() string { return h.metricName }
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.metricName, h.help, h.metricName)
+
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := h.series[key]
+
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName,
+				labelKey(append(h.labels, "le"), append(s.labelValues, formatFloat(bound))), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName,
+			labelKey(append(h.labels, "le"), append(s.labelValues, "+Inf")), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.metricName, key, formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.metricName, key, s.count)
+	}
+}
+
+// labelKey renders label pairs as {a="x",b="y"}, which doubles as the series map key
+func labelKey(names, values []string) string {
+	if len(names) != len(values) {
+		panic(fmt.Sprintf("metrics: expected %d label values, got %d", len(names), len(values)))
+	}
+	if len(names) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + strconv.Quote(values[i])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// sortedKeys returns the map keys in a stable order for deterministic output
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatFloat formats a sample value as Prometheus expects
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
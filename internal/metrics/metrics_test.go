@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// scrape fetches the metrics endpoint like Prometheus does
+func scrape(t *testing.T) string {
+	t.Helper()
+
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("scrape: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("scrape status = %d", response.StatusCode)
+	}
+	if contentType := response.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("scrape Content-Type = %q", contentType)
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("failed to read scrape: %v", err)
+	}
+	return string(body)
+}
+
+// assertLines fails unless every line is in the scraped body
+func assertLines(t *testing.T, body string, lines ...string) {
+	t.Helper()
+
+	for _, line := range lines {
+		if !strings.Contains(body, "\n"+line+"\n") && !strings.HasPrefix(body, line+"\n") {
+			t.Errorf("scrape lacks %q", line)
+		}
+	}
+}
+
+func TestScrapeCounter(t *testing.T) {
+	counter := NewCounterVec("test_requests_total", "Requests handled.", "method", "status")
+	counter.Inc("sendMessage", "200")
+	counter.Inc("sendMessage", "200")
+	counter.Add(3, "getUpdates", "502")
+	unlabelled := NewCounterVec("test_unlabelled_total", "Never incremented.")
+
+	body := scrape(t)
+	assertLines(t, body,
+		"# HELP test_requests_total Requests handled.",
+		"# TYPE test_requests_total counter",
+		`test_requests_total{method="getUpdates",status="502"} 3`,
+		`test_requests_total{method="sendMessage",status="200"} 2`,
+		"# TYPE test_unlabelled_total counter",
+		"test_unlabelled_total 0",
+	)
+	unlabelled.Inc()
+	assertLines(t, scrape(t), "test_unlabelled_total 1")
+}
+
+func TestScrapeHistogram(t *testing.T) {
+	histogram := NewHistogramVec("test_duration_seconds", "Durations.", []float64{0.1, 1}, "report")
+	histogram.Observe(0.05, "csv")
+	histogram.Observe(0.1, "csv")
+	histogram.Observe(0.5, "csv")
+	histogram.Observe(3, "csv")
+
+	assertLines(t, scrape(t),
+		"# TYPE test_duration_seconds histogram",
+		`test_duration_seconds_bucket{report="csv",le="0.1"} 2`,
+		`test_duration_seconds_bucket{report="csv",le="1"} 3`,
+		`test_duration_seconds_bucket{report="csv",le="+Inf"} 4`,
+		`test_duration_seconds_sum{report="csv"} 3.65`,
+		`test_duration_seconds_count{report="csv"} 4`,
+	)
+}
+
+func TestScrapeBotMetrics(t *testing.T) {
+	UpdatesReceived.Inc()
+	Commands.Inc("start")
+	AttendanceMarks.Inc("checked_in")
+	TelegramRequests.Inc("sendMessage", "429")
+	ReportDuration.Observe(0.2, "csv")
+
+	body := scrape(t)
+	for _, family := range []string{
+		"attendance_bot_updates_received_total",
+		"attendance_bot_updates_handled_total",
+		"attendance_bot_commands_total",
+		"attendance_bot_attendance_marks_total",
+		"attendance_bot_report_duration_seconds",
+		"attendance_bot_db_query_duration_seconds",
+		"attendance_bot_telegram_requests_total",
+		"attendance_bot_webhook_deliveries_total",
+	} {
+		if !strings.Contains(body, "# TYPE "+family+" ") {
+			t.Errorf("scrape lacks %s", family)
+		}
+	}
+	assertLines(t, body,
+		`attendance_bot_commands_total{command="start"} 1`,
+		`attendance_bot_telegram_requests_total{method="sendMessage",status="429"} 1`,
+	)
+}
+
+func TestConcurrentUpdatesAndScrapes(t *testing.T) {
+	counter := NewCounterVec("test_concurrent_total", "Concurrent increments.", "worker")
+	histogram := NewHistogramVec("test_concurrent_seconds", "Concurrent observations.", DefaultBuckets)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				counter.Inc("w")
+				histogram.Observe(0.01)
+			}
+		}()
+	}
+	for range 5 {
+		WriteText(io.Discard)
+	}
+	wg.Wait()
+
+	assertLines(t, scrape(t), `test_concurrent_total{worker="w"} 800`, "test_concurrent_seconds_count 800")
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	counter := NewCounterVec("test_mismatch_total", "Mismatched labels.", "method")
+	defer func() {
+		if recover() == nil {
+			t.Error("Inc with too few label values did not panic")
+		}
+	}()
+	counter.Inc()
+}
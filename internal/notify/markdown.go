@@ -0,0 +1,74 @@
+package notify
+
+import "strings"
+
+// toSlack renders a message as Slack mrkdwn. Telegram's *bold*, _italic_ and `code` mean the same
+// there; only &, < and > must be escaped.
+func toSlack(msg Message) string {
+	literal := func(r rune) string {
+		switch r {
+		case '&':
+			return "&amp;"
+		case '<':
+			return "&lt;"
+		case '>':
+			return "&gt;"
+		}
+		return string(r)
+	}
+	return convert(msg, "*", literal)
+}
+
+// toDiscord renders a message as Discord Markdown, where bold is **bold** and Markdown characters
+// meant literally are escaped with a backslash
+func toDiscord(msg Message) string {
+	literal := func(r rune) string {
+		switch r {
+		case '*', '_', '`', '~', '|', '>', '\\':
+			return "\\" + string(r)
+		}
+		return string(r)
+	}
+	return convert(msg, "**", literal)
+}
+
+// convert rewrites Telegram legacy Markdown with the platform's bold marker, passing every character
+// meant literally through literal. Plain messages are entirely literal. Reports mark bold with ** as
+// well as *, which Telegram renders alike.
+func convert(msg Message, bold string, literal func(rune) string) string {
+	var out strings.Builder
+	escaped, inCode := false, false
+	previous := rune(0)
+	for _, r := range msg.Text {
+		if msg.Markdown && !escaped && !inCode && r == '*' && previous == '*' {
+			previous = 0
+			continue
+		}
+		previous = r
+
+		switch {
+		case !msg.Markdown:
+			out.WriteString(literal(r))
+		case escaped:
+			out.WriteString(literal(r))
+			escaped, previous = false, 0
+		case inCode:
+			if r == '`' {
+				inCode = false
+			}
+			out.WriteRune(r)
+		case r == '\\':
+			escaped = true
+		case r == '`':
+			inCode = true
+			out.WriteRune(r)
+		case r == '*':
+			out.WriteString(bold)
+		case r == '_':
+			out.WriteRune(r)
+		default:
+			out.WriteString(literal(r))
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,179 @@
+// Package notify mirrors bot messages to team chat platforms through their incoming webhooks
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Events that can be mirrored
+const (
+	EventDailyReport = "daily_report"
+	EventLateArrival = "late_arrival"
+)
+
+// Events lists the events that can be mirrored, all of them mirrored by default
+var Events = []string{EventDailyReport, EventLateArrival}
+
+// Platform limits
+const (
+	slackMaxLength   = 3000 // Slack truncates longer texts in some clients
+	discordMaxLength = 2000 // Discord rejects longer contents
+	requestTimeout   = 10 * time.Second
+	maxRetryAfter    = 30 * time.Second // Longest rate limit delay waited for before giving up
+)
+
+// Message is a text to mirror
+type Message struct {
+	Text     string
+	Markdown bool // Text is in Telegram's legacy Markdown, as the bot sends it
+}
+
+// Notifier posts messages to a chat platform
+type Notifier interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// Slack posts to a Slack incoming webhook
+type Slack struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlack creates a notifier for a Slack incoming webhook URL
+func NewSlack(url string) *Slack {
+	return &Slack{url: url, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Name returns "slack"
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+// Send posts the message as mrkdwn, split into several posts if it is too long
+func (s *Slack) Send(ctx context.Context, msg Message) error {
+	for _, chunk := range split(toSlack(msg), slackMaxLength) {
+		if err := post(ctx, s.client, s.url, map[string]any{"text": chunk}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Discord posts to a Discord channel webhook
+type Discord struct {
+	url    string
+	client *http.Client
+}
+
+// NewDiscord creates a notifier for a Discord webhook URL
+func NewDiscord(url string) *Discord {
+	return &Discord{url: url, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Name returns "discord"
+func (d *Discord) Name() string {
+	return "discord"
+}
+
+// Send posts the message as Discord Markdown, split into several posts if it is too long. Mentions
+// are disabled, so names in a report never ping anyone.
+func (d *Discord) Send(ctx context.Context, msg Message) error {
+	for _, chunk := range split(toDiscord(msg), discordMaxLength) {
+		payload := map[string]any{
+			"content":          chunk,
+			"allowed_mentions": map[string]any{"parse": []string{}},
+		}
+		if err := post(ctx, d.client, d.url, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post sends a JSON payload to a webhook, retrying once after the delay asked for when rate limited
+func post(ctx context.Context, client *http.Client, target string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	retryAfter, err := postOnce(ctx, client, target, body)
+	if retryAfter <= 0 || retryAfter > maxRetryAfter {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return err
+	case <-time.After(retryAfter):
+	}
+	_, err = postOnce(ctx, client, target, body)
+	return err
+}
+
+// postOnce sends one request, returning how long to wait when rate limited
+func postOnce(ctx context.Context, client *http.Client, target string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// The error names the URL, which holds the webhook's token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return 0, fmt.Errorf("failed to send: %w", err)
+	}
+	defer resp.Body.Close()
+
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return 0, nil
+	}
+
+	err = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(excerpt))
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return 0, err
+	}
+	seconds, parseErr := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64)
+	if parseErr != nil || seconds <= 0 {
+		seconds = 1
+	}
+	return time.Duration(seconds * float64(time.Second)), err
+}
+
+// split cuts text into chunks of at most limit bytes, at line breaks where possible
+func split(text string, limit int) []string {
+	var chunks []string
+	for len(text) > limit {
+		cut := strings.LastIndex(text[:limit], "\n")
+		if cut <= 0 {
+			cut = limit
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		chunks = append(chunks, text[:cut])
+		text = strings.TrimPrefix(text[cut:], "\n")
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
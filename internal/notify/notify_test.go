@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     Message
+		slack   string
+		discord string
+	}{
+		{"plain", Message{Text: "Sari <late> & *early*"},
+			"Sari &lt;late&gt; &amp; *early*", "Sari <late\\> & \\*early\\*"},
+		{"bold", Message{Text: "*Daily report*", Markdown: true},
+			"*Daily report*", "**Daily report**"},
+		{"report bold", Message{Text: "1. **Budi**", Markdown: true},
+			"1. *Budi*", "1. **Budi**"},
+		{"escaped", Message{Text: `PT\_Maju \*1\*`, Markdown: true},
+			"PT_Maju *1*", `PT\_Maju \*1\*`},
+		{"code", Message{Text: "OTP `*123*` & more", Markdown: true},
+			"OTP `*123*` &amp; more", "OTP `*123*` & more"},
+		{"italic", Message{Text: "_late_ > 08:00", Markdown: true},
+			"_late_ &gt; 08:00", "_late_ \\> 08:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toSlack(tt.msg); got != tt.slack {
+				t.Errorf("toSlack(%q) = %q, want %q", tt.msg.Text, got, tt.slack)
+			}
+			if got := toDiscord(tt.msg); got != tt.discord {
+				t.Errorf("toDiscord(%q) = %q, want %q", tt.msg.Text, got, tt.discord)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{"short", "hello", 10, []string{"hello"}},
+		{"empty", "", 10, nil},
+		{"at line breaks", "line one\nline two\nline three", 18, []string{"line one\nline two", "line three"}},
+		{"long line", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"multibyte rune", "aé€b", 4, []string{"aé", "€b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := split(tt.text, tt.limit)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("split(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+			}
+			for _, chunk := range got {
+				if len(chunk) > tt.limit {
+					t.Errorf("chunk %q is longer than %d bytes", chunk, tt.limit)
+				}
+			}
+		})
+	}
+}
+
+// TestNotifierSend posts messages to a fake webhook that may rate limit or refuse them
+func TestNotifierSend(t *testing.T) {
+	tests := []struct {
+		name     string
+		notifier func(url string) Notifier
+		text     string
+		statuses []int // Statuses answered in turn, then 204
+		posts    int   // Posts that should arrive, failed ones included
+		field    string
+		wantErr  bool
+	}{
+		{name: "slack", notifier: func(url string) Notifier { return NewSlack(url) },
+			text: "hello", posts: 1, field: "text"},
+		{name: "discord", notifier: func(url string) Notifier { return NewDiscord(url) },
+			text: "hello", posts: 1, field: "content"},
+		{name: "discord, long", notifier: func(url string) Notifier { return NewDiscord(url) },
+			text: strings.Repeat("x", 1500) + "\n" + strings.Repeat("y", 1500), posts: 2, field: "content"},
+		{name: "rate limited once", notifier: func(url string) Notifier { return NewSlack(url) },
+			text: "hello", statuses: []int{http.StatusTooManyRequests}, posts: 2, field: "text"},
+		{name: "rate limited twice", notifier: func(url string) Notifier { return NewSlack(url) },
+			text: "hello", statuses: []int{http.StatusTooManyRequests, http.StatusTooManyRequests}, posts: 2, field: "text", wantErr: true},
+		{name: "refused", notifier: func(url string) Notifier { return NewDiscord(url) },
+			text: "hello", statuses: []int{http.StatusNotFound}, posts: 1, field: "content", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var texts, delivered []string
+			statuses := tt.statuses
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var payload map[string]any
+				json.NewDecoder(r.Body).Decode(&payload)
+
+				mu.Lock()
+				defer mu.Unlock()
+				texts = append(texts, payload[tt.field].(string))
+				if len(statuses) > 0 {
+					status := statuses[0]
+					statuses = statuses[1:]
+					if status == http.StatusTooManyRequests {
+						w.Header().Set("Retry-After", "0.01")
+					}
+					http.Error(w, "no", status)
+					return
+				}
+				delivered = append(delivered, texts[len(texts)-1])
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			url := server.URL + "/hooks/secret-token"
+			err := tt.notifier(url).Send(context.Background(), Message{Text: tt.text})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send error = %v, want an error: %v", err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "secret-token") {
+				t.Errorf("Send error %q reveals the webhook URL", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(texts) != tt.posts {
+				t.Errorf("got %d posts, want %d", len(texts), tt.posts)
+			}
+			if !tt.wantErr && strings.Join(delivered, "\n") != tt.text {
+				t.Errorf("delivered %q, want %q", delivered, tt.text)
+			}
+		})
+	}
+}
+
+// TestSendHidesWebhookURL fails to reach a webhook: the error must not reveal the URL, whose path
+// is the webhook's token
+func TestSendHidesWebhookURL(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL + "/hooks/secret-token"
+	server.Close()
+
+	for _, notifier := range []Notifier{NewSlack(url), NewDiscord(url)} {
+		err := notifier.Send(context.Background(), Message{Text: "hello"})
+		if err == nil {
+			t.Errorf("%s: Send to a closed server succeeded", notifier.Name())
+		} else if strings.Contains(err.Error(), "secret-token") {
+			t.Errorf("%s: Send error %q reveals the webhook URL", notifier.Name(), err)
+		}
+	}
+}
@@ -3,46 +3,43 @@ package reports
 import (
 	"attendance-bot/internal/utils"
 	"attendance-bot/pkg/models"
+	"bytes"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
+	"strconv"
 	"time"
 )
 
-// CSVGenerator handles CSV report generation
-type CSVGenerator struct {
-	outputDir string
-}
+// CSVGenerator handles CSV report generation. Reports are rendered in memory and uploaded from
+// there, so they need no disk space and leave no files behind.
+type CSVGenerator struct{}
 
 // NewCSVGenerator creates a new CSV generator
-func NewCSVGenerator(outputDir string) *CSVGenerator {
-	return &CSVGenerator{
-		outputDir: outputDir,
-	}
+func NewCSVGenerator() *CSVGenerator {
+	return &CSVGenerator{}
 }
 
-// GenerateAttendanceReport creates a CSV file with attendance data
-func (g *CSVGenerator) GenerateAttendanceReport(records []models.AttendanceRecord, startDate, endDate string) (string, error) {
-	// Ensure output directory exists
-	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	// Generate filename
-	filename := fmt.Sprintf("attendance_report_%s_to_%s.csv", startDate, endDate)
-	filepath := filepath.Join(g.outputDir, filename)
-
-	// Create CSV file
-	file, err := os.Create(filepath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create CSV file: %w", err)
+// GenerateAttendanceReport renders a CSV with attendance data and approved leave
+func (g *CSVGenerator) GenerateAttendanceReport(records []models.AttendanceRecord, leave []models.LeaveDay) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteAttendanceCSV(&buf, records, leave); err != nil {
+		return nil, err
 	}
-	defer file.Close()
+	return buf.Bytes(), nil
+}
 
+// WriteAttendanceCSV writes one CSV row per attendance record, followed by one row per day of
+// approved leave with the leave type (Cuti, Izin or Sakit) as its type. The Photo column holds the
+// Telegram file ID of the check-in's selfie, which /photo shows in the admin chat, and the location
+// columns the position the user shared with the geofence it was in. Employee ID and Department come
+// from the employee directory and are empty for unregistered users. Overtime Minutes is set on
+// check-outs, for payroll, and Work Mode is Remote on days the user worked from home, else Office.
+// Both the bot and cmd/export use it so their output is identical.
+func WriteAttendanceCSV(w io.Writer, records []models.AttendanceRecord, leave []models.LeaveDay) error {
 	// Create CSV writer
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	writer := csv.NewWriter(w)
 
 	// Write header
 	header := []string{
@@ -55,9 +52,17 @@ func (g *CSVGenerator) GenerateAttendanceReport(records []models.AttendanceRecor
 		"Type",
 		"Time",
 		"Timestamp",
+		"Photo",
+		"Latitude",
+		"Longitude",
+		"Geofence",
+		"Employee ID",
+		"Department",
+		"Overtime Minutes",
+		"Work Mode",
 	}
 	if err := writer.Write(header); err != nil {
-		return "", fmt.Errorf("failed to write CSV header: %w", err)
+		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
 	// Write records
@@ -79,49 +84,386 @@ func (g *CSVGenerator) GenerateAttendanceReport(records []models.AttendanceRecor
 			record.Type,
 			timeStr,
 			record.Timestamp.Format(time.RFC3339),
+			record.PhotoFileID,
+			formatCoordinate(record.Latitude),
+			formatCoordinate(record.Longitude),
+			record.Geofence,
+			record.EmployeeID,
+			record.Department,
+			strconv.Itoa(record.OvertimeMinutes),
+			workMode(&record),
 		}
 
 		if err := writer.Write(row); err != nil {
-			return "", fmt.Errorf("failed to write CSV row: %w", err)
+			return fmt.Errorf("failed to write CSV row: %w", err)
 		}
 	}
 
-	return filepath, nil
+	for _, day := range leaveWithoutAttendance(records, leave) {
+		lastName := ""
+		if day.Leave.LastName != nil {
+			lastName = *day.Leave.LastName
+		}
+
+		row := []string{
+			"",
+			fmt.Sprintf("%d", day.Leave.UserID),
+			day.Leave.Username,
+			day.Leave.FirstName,
+			lastName,
+			day.Date,
+			day.Leave.Label(),
+			"-",
+			"",
+			"",
+			"",
+			"",
+			"",
+			day.Leave.EmployeeID,
+			day.Leave.Department,
+			"",
+			"",
+		}
+
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush CSV: %w", err)
+	}
+
+	return nil
 }
 
-// GenerateDailyReport creates a CSV for a specific date
-func (g *CSVGenerator) GenerateDailyReport(records []models.AttendanceRecord, date string) (string, error) {
-	return g.GenerateAttendanceReport(records, date, date)
+// WritePivotCSV writes one CSV row per user and day with check-in, check-out, work duration less
+// breaks, minutes on breaks, minutes of overtime and status: Present or Late by the user's shift, with Left Early appended for an early check-out
+// and Remote on a day worked from home, or the leave type for a day of approved leave. Nil shifts judge everyone by models.BuiltinShift.
+func WritePivotCSV(w io.Writer, records []models.AttendanceRecord, leave []models.LeaveDay, shifts *models.ShiftAssignments) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(pivotHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, row := range pivotRows(records, leave, shifts) {
+		if err := writer.Write(row.fields()); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush CSV: %w", err)
+	}
+
+	return nil
 }
 
-// GenerateUserReport creates a CSV for a specific user's attendance
-func (g *CSVGenerator) GenerateUserReport(records []models.AttendanceRecord, userID int64, days int) (string, error) {
-	if len(records) == 0 {
-		return "", fmt.Errorf("no records found for user %d", userID)
+// pivotHeader names the columns of pivot reports
+var pivotHeader = []string{
+	"Date",
+	"User ID",
+	"Username",
+	"Name",
+	"Employee ID",
+	"Department",
+	"Check-in Time",
+	"Check-out Time",
+	"Work Duration",
+	"Break Minutes",
+	"Overtime Minutes",
+	"Status",
+}
+
+// pivotRow is one user's day in a pivot report
+type pivotRow struct {
+	date, username, name        string
+	employeeID, department      string
+	userID                      int64
+	checkIn, checkOut, duration string
+	breaks                      string // Minutes, "-" for leave
+	overtime                    string // Minutes, "-" without a check-out
+	overtimeMinutes             int
+	status                      string
+	late, leftEarly             bool
+	leave                       bool // A day of approved leave without attendance
+}
+
+// fields returns the row's values in pivotHeader order
+func (r *pivotRow) fields() []string {
+	return []string{
+		r.date,
+		fmt.Sprintf("%d", r.userID),
+		r.username,
+		r.name,
+		r.employeeID,
+		r.department,
+		r.checkIn,
+		r.checkOut,
+		r.duration,
+		r.breaks,
+		r.overtime,
+		r.status,
+	}
+}
+
+// pivotRows groups records into one row per user and day, in the order the records arrive (sorted
+// by date and time), with the leave days without attendance merged in date order
+func pivotRows(records []models.AttendanceRecord, leave []models.LeaveDay, shifts *models.ShiftAssignments) []pivotRow {
+	var rows []pivotRow
+
+	// Leave days are added before the first attendance day after them, keeping date order
+	leaveDays := leaveWithoutAttendance(records, leave)
+	addLeaveUntil := func(date string) {
+		for len(leaveDays) > 0 && (date == "" || leaveDays[0].Date < date) {
+			day := leaveDays[0]
+			leaveDays = leaveDays[1:]
+
+			rows = append(rows, pivotRow{
+				date:       day.Date,
+				userID:     day.Leave.UserID,
+				username:   day.Leave.Username,
+				name:       fullName(day.Leave.FirstName, day.Leave.LastName),
+				employeeID: day.Leave.EmployeeID,
+				department: day.Leave.Department,
+				checkIn:    "-",
+				checkOut:   "-",
+				duration:   "-",
+				breaks:     "-",
+				overtime:   "-",
+				status:     day.Leave.Label(),
+				leave:      true,
+			})
+		}
+	}
+
+	for _, day := range models.GroupByDay(records) {
+		addLeaveUntil(day.Date)
+
+		base := day.Record()
+		row := pivotRow{
+			date:       day.Date,
+			userID:     day.UserID,
+			username:   base.Username,
+			name:       fullName(base.FirstName, base.LastName),
+			employeeID: base.EmployeeID,
+			department: base.Department,
+			checkIn:    "-",
+			checkOut:   "-",
+			duration:   "-",
+			breaks:     strconv.Itoa(int(day.BreakTime() / time.Minute)),
+			overtime:   "-",
+			status:     "-",
+		}
+		if day.CheckIn != nil {
+			row.checkIn = utils.FormatTime(day.CheckIn.Timestamp, "HH:mm:ss")
+			row.status, row.late, row.leftEarly = dayStatus(&day, shifts.For(day.UserID))
+		}
+		if day.CheckOut != nil {
+			row.checkOut = utils.FormatTime(day.CheckOut.Timestamp, "HH:mm:ss")
+			row.overtimeMinutes = day.CheckOut.OvertimeMinutes
+			row.overtime = strconv.Itoa(row.overtimeMinutes)
+			if day.CheckIn != nil {
+				row.duration = utils.CalculateWorkDuration(day.CheckIn.Timestamp, day.CheckOut.Timestamp, day.BreakTime(), utils.DefaultLanguage)
+			}
+		}
+		rows = append(rows, row)
+	}
+	addLeaveUntil("")
+
+	return rows
+}
+
+// leaveWithoutAttendance drops the leave days on which the user recorded attendance anyway
+func leaveWithoutAttendance(records []models.AttendanceRecord, leave []models.LeaveDay) []models.LeaveDay {
+	type dayKey struct {
+		date   string
+		userID int64
 	}
 
-	// Use the date range from the records
-	startDate := records[len(records)-1].Date // oldest
-	endDate := records[0].Date                // newest
+	attended := make(map[dayKey]bool, len(records))
+	for _, record := range records {
+		attended[dayKey{record.Date, record.UserID}] = true
+	}
 
-	filename := fmt.Sprintf("user_%d_attendance_%s_to_%s.csv", userID, startDate, endDate)
-	filepath := filepath.Join(g.outputDir, filename)
+	var days []models.LeaveDay
+	for _, day := range leave {
+		if !attended[dayKey{day.Date, day.Leave.UserID}] {
+			days = append(days, day)
+		}
+	}
+	return days
+}
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create output directory: %w", err)
+// dayStatus returns Present or Late for a day with a check-in, appending Left Early when the
+// check-out came before the end of the shift, Auto Check-out when the bot recorded it and Remote
+// when the user worked from home
+func dayStatus(day *models.DayAttendance, shift models.Shift) (status string, late, leftEarly bool) {
+	late = utils.IsLate(day.CheckIn.Timestamp, shift)
+	leftEarly = day.CheckOut != nil && utils.LeftEarly(day.CheckIn.Timestamp, day.CheckOut.Timestamp, shift)
+
+	status = "Present"
+	if late {
+		status = "Late"
+	}
+	if leftEarly {
+		status += ", Left Early"
+	}
+	if day.CheckOut != nil && day.CheckOut.Source == models.SourceAuto {
+		status += ", Auto Check-out"
+	}
+	if day.CheckIn.Remote {
+		status += ", Remote"
 	}
+	return status, late, leftEarly
+}
 
-	// Create CSV file
-	file, err := os.Create(filepath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create CSV file: %w", err)
+// workMode names where the record was made from: Remote on a day worked from home, else Office
+func workMode(record *models.AttendanceRecord) string {
+	if record.Remote {
+		return "Remote"
+	}
+	return "Office"
+}
+
+// fullName joins a first name and an optional last name
+func fullName(firstName string, lastName *string) string {
+	if lastName != nil && *lastName != "" {
+		return firstName + " " + *lastName
+	}
+	return firstName
+}
+
+// formatCoordinate formats an optional coordinate with six decimals, about 10 cm
+func formatCoordinate(coordinate *float64) string {
+	if coordinate == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*coordinate, 'f', 6, 64)
+}
+
+// WriteAttendanceJSON writes the records as an indented JSON array
+func WriteAttendanceJSON(w io.Writer, records []models.AttendanceRecord) error {
+	if records == nil {
+		records = []models.AttendanceRecord{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(records); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	return nil
+}
+
+// GenerateMissingCheckoutsReport renders a CSV listing days with a check-in but no check-out
+func (g *CSVGenerator) GenerateMissingCheckoutsReport(missing []models.MissingCheckout) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteMissingCheckoutsCSV(&buf, missing); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteMissingCheckoutsCSV writes one CSV row per day with a check-in but no check-out
+func WriteMissingCheckoutsCSV(w io.Writer, missing []models.MissingCheckout) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"Date", "User ID", "Name", "Username", "Check-in Time"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, day := range missing {
+		row := []string{
+			day.Date,
+			fmt.Sprintf("%d", day.UserID),
+			day.Name,
+			day.Username,
+			utils.FormatTime(day.CheckIn, "HH:mm:ss"),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush CSV: %w", err)
+	}
+
+	return nil
+}
+
+// GenerateAuditReport renders a CSV of audit log entries
+func (g *CSVGenerator) GenerateAuditReport(entries []models.AuditEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteAuditCSV(&buf, entries); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteAuditCSV writes one CSV row per audit log entry, with empty cells for a missing target user
+// or record
+func WriteAuditCSV(w io.Writer, entries []models.AuditEntry) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"Time", "Actor ID", "Action", "Target User ID", "Record ID", "Details", "Reason"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, entry := range entries {
+		row := []string{
+			utils.FormatDate(entry.CreatedAt, "yyyy-MM-dd") + " " + utils.FormatTime(entry.CreatedAt, "HH:mm:ss"),
+			fmt.Sprintf("%d", entry.ActorID),
+			entry.Action,
+			optionalID(entry.TargetUserID),
+			optionalID(entry.RecordID),
+			entry.Details,
+			entry.Reason,
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush CSV: %w", err)
+	}
+
+	return nil
+}
+
+// optionalID formats an ID, or returns an empty string when it is 0
+func optionalID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", id)
+}
+
+// GenerateDailyReport renders a CSV for a specific date
+func (g *CSVGenerator) GenerateDailyReport(records []models.AttendanceRecord) ([]byte, error) {
+	return g.GenerateAttendanceReport(records, nil)
+}
+
+// GenerateUserReport renders a CSV of a specific user's attendance, judging lateness by their shift
+// and showing times in its timezone, the one the user works in
+func (g *CSVGenerator) GenerateUserReport(records []models.AttendanceRecord, userID int64, shift models.Shift) ([]byte, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no records found for user %d", userID)
 	}
-	defer file.Close()
 
 	// Create CSV writer
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
 
 	// Write header
 	header := []string{
@@ -132,22 +474,16 @@ func (g *CSVGenerator) GenerateUserReport(records []models.AttendanceRecord, use
 		"Status",
 	}
 	if err := writer.Write(header); err != nil {
-		return "", fmt.Errorf("failed to write CSV header: %w", err)
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
-	// Group records by date
-	dailyRecords := make(map[string]map[string]*models.AttendanceRecord)
-	for _, record := range records {
-		if dailyRecords[record.Date] == nil {
-			dailyRecords[record.Date] = make(map[string]*models.AttendanceRecord)
-		}
-		dailyRecords[record.Date][record.Type] = &record
-	}
-
-	// Write records grouped by date
-	for date, dayRecords := range dailyRecords {
-		checkIn := dayRecords["check_in"]
-		checkOut := dayRecords["check_out"]
+	// Write records grouped by date, newest first
+	location := utils.ShiftLocation(shift)
+	grouped := models.GroupByDay(records)
+	models.SortDaysNewestFirst(grouped)
+	for _, day := range grouped {
+		checkIn := day.CheckIn
+		checkOut := day.CheckOut
 
 		checkInTime := "-"
 		checkOutTime := "-"
@@ -155,22 +491,19 @@ func (g *CSVGenerator) GenerateUserReport(records []models.AttendanceRecord, use
 		status := "Absent"
 
 		if checkIn != nil {
-			checkInTime = utils.FormatTime(checkIn.Timestamp, "HH:mm:ss")
-			status = "Present"
-			if checkIn.Timestamp.Hour() >= 9 {
-				status = "Late"
-			}
+			checkInTime = utils.FormatTimeIn(checkIn.Timestamp, "HH:mm:ss", location)
+			status, _, _ = dayStatus(&day, shift)
 		}
 
 		if checkOut != nil {
-			checkOutTime = utils.FormatTime(checkOut.Timestamp, "HH:mm:ss")
+			checkOutTime = utils.FormatTimeIn(checkOut.Timestamp, "HH:mm:ss", location)
 			if checkIn != nil {
-				duration = utils.CalculateWorkDuration(checkIn.Timestamp, checkOut.Timestamp)
+				duration = utils.CalculateWorkDuration(checkIn.Timestamp, checkOut.Timestamp, day.BreakTime(), utils.DefaultLanguage)
 			}
 		}
 
 		row := []string{
-			date,
+			day.Date,
 			checkInTime,
 			checkOutTime,
 			duration,
@@ -178,9 +511,14 @@ func (g *CSVGenerator) GenerateUserReport(records []models.AttendanceRecord, use
 		}
 
 		if err := writer.Write(row); err != nil {
-			return "", fmt.Errorf("failed to write CSV row: %w", err)
+			return nil, fmt.Errorf("failed to write CSV row: %w", err)
 		}
 	}
 
-	return filepath, nil
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to flush CSV: %w", err)
+	}
+
+	return buf.Bytes(), nil
 }
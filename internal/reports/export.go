@@ -0,0 +1,82 @@
+package reports
+
+import (
+	"attendance-bot/pkg/models"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Writer writes attendance records and days of approved leave in one output format, judging
+// lateness by the users' shifts where the format has a status
+type Writer func(io.Writer, []models.AttendanceRecord, []models.LeaveDay, *models.ShiftAssignments) error
+
+// ExportFormat is one output format of attendance exports
+type ExportFormat struct {
+	Write       Writer
+	Extension   string
+	ContentType string
+}
+
+// ExportFormats maps each export format to its writer. Both cmd/export and the HTTP API use it so
+// their output is identical; csv matches the bot's /fullreport CSV. JSON holds attendance records only.
+var ExportFormats = map[string]ExportFormat{
+	"csv": {
+		Write: func(w io.Writer, records []models.AttendanceRecord, leave []models.LeaveDay, _ *models.ShiftAssignments) error {
+			return WriteAttendanceCSV(w, records, leave)
+		},
+		Extension:   "csv",
+		ContentType: "text/csv; charset=utf-8",
+	},
+	"pivot": {
+		Write:       WritePivotCSV,
+		Extension:   "csv",
+		ContentType: "text/csv; charset=utf-8",
+	},
+	"xlsx": {
+		Write:       WriteAttendanceXLSX,
+		Extension:   "xlsx",
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	},
+	"json": {
+		Write: func(w io.Writer, records []models.AttendanceRecord, _ []models.LeaveDay, _ *models.ShiftAssignments) error {
+			return WriteAttendanceJSON(w, records)
+		},
+		Extension:   "json",
+		ContentType: "application/json",
+	},
+}
+
+// FilterDepartment keeps the records and leave days of the users of a department of the employee
+// directory, matched ignoring case and spacing, so every export filters alike
+func FilterDepartment(records []models.AttendanceRecord, leave []models.LeaveDay, department string) ([]models.AttendanceRecord, []models.LeaveDay) {
+	key := departmentKey(department)
+	records = slices.DeleteFunc(records, func(record models.AttendanceRecord) bool {
+		return departmentKey(record.Department) != key
+	})
+	leave = slices.DeleteFunc(leave, func(day models.LeaveDay) bool {
+		return departmentKey(day.Leave.Department) != key
+	})
+	return records, leave
+}
+
+// Work modes exports can be limited to
+const (
+	WorkModeOffice = "office" // Attendance recorded at the office
+	WorkModeRemote = "remote" // Attendance recorded on days worked from home
+)
+
+// FilterWorkMode keeps the records made in a work mode, office or remote. Leave days are dropped,
+// as nobody worked on them.
+func FilterWorkMode(records []models.AttendanceRecord, leave []models.LeaveDay, mode string) ([]models.AttendanceRecord, []models.LeaveDay) {
+	remote := mode == WorkModeRemote
+	records = slices.DeleteFunc(records, func(record models.AttendanceRecord) bool {
+		return record.Remote != remote
+	})
+	return records, nil
+}
+
+// departmentKey normalizes a department name for comparison
+func departmentKey(department string) string {
+	return strings.ToLower(strings.Join(strings.Fields(department), " "))
+}
@@ -0,0 +1,115 @@
+package reports
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"strings"
+	"time"
+)
+
+// monthlyUsersPerMessage bounds the employees listed per message, keeping each message well
+// under Telegram's length limit
+const monthlyUsersPerMessage = 20
+
+// FormatMonthlySummary renders a monthly summary in the given language as one or more plain-text
+// Telegram messages. Users are listed under their department when the summary has department totals.
+func FormatMonthlySummary(summary *models.MonthlySummary, lang string) []string {
+	var header strings.Builder
+	month := summary.Month
+	if first, err := utils.ParseDate(summary.StartDate); err == nil {
+		month = utils.FormatDateIn(first, "MMMM yyyy", lang)
+	}
+	header.WriteString(i18n.T(lang, "monthly.title", "Month", month, "Start", summary.StartDate, "End", summary.EndDate))
+	if summary.Department != "" {
+		header.WriteString(i18n.T(lang, "monthly.department", "Department", summary.Department))
+	}
+	header.WriteString(i18n.T(lang, "monthly.working_days", "Days", summary.WorkingDays))
+	if len(summary.Holidays) > 0 {
+		header.WriteString(i18n.T(lang, "monthly.holidays"))
+		for _, holiday := range summary.Holidays {
+			header.WriteString("\n• " + FormatHoliday(holiday, lang))
+		}
+		header.WriteString("\n")
+	}
+
+	if len(summary.Users) == 0 {
+		header.WriteString(i18n.T(lang, "monthly.empty"))
+		return []string{header.String()}
+	}
+	header.WriteString(i18n.T(lang, "monthly.users", "Count", len(summary.Users)))
+	if len(summary.Departments) > 0 {
+		header.WriteString("\n" + i18n.T(lang, "monthly.departments"))
+		for _, department := range summary.Departments {
+			header.WriteString("\n" + i18n.T(lang, "monthly.department_entry",
+				"Department", departmentName(department.Name, lang),
+				"Users", department.Users,
+				"Present", department.DaysPresent,
+				"Late", department.DaysLate,
+				"WorkDuration", utils.FormatDuration(department.WorkDuration, lang),
+				"Absent", department.DaysAbsent))
+		}
+		header.WriteString("\n")
+	}
+
+	var messages []string
+	for start := 0; start < len(summary.Users); start += monthlyUsersPerMessage {
+		var message strings.Builder
+		if start == 0 {
+			message.WriteString(header.String())
+		}
+		for i := start; i < min(start+monthlyUsersPerMessage, len(summary.Users)); i++ {
+			user := &summary.Users[i]
+			// Each message names the department it continues with
+			if len(summary.Departments) > 0 && (i == start || summary.Users[i-1].Department != user.Department) {
+				message.WriteString("\n🏢 " + departmentName(user.Department, lang) + "\n")
+			}
+			message.WriteString("\n" + formatMonthlyUser(user, lang))
+		}
+		messages = append(messages, message.String())
+	}
+
+	return messages
+}
+
+// departmentName returns the name of a department shown to users, naming the lack of one
+func departmentName(department, lang string) string {
+	if department == "" {
+		return i18n.T(lang, "employee.no_department")
+	}
+	return department
+}
+
+// formatMonthlyUser renders one employee's monthly totals
+func formatMonthlyUser(user *models.MonthlyUserSummary, lang string) string {
+	name := user.Name
+	if user.Username != "" {
+		name += " (@" + user.Username + ")"
+	}
+
+	averageCheckIn := "-"
+	if user.DaysCheckedIn > 0 {
+		averageCheckIn = time.Time{}.Add(user.AverageCheckIn).Format("15:04")
+	}
+
+	return i18n.T(lang, "monthly.user",
+		"Name", name,
+		"Present", user.DaysPresent,
+		"Late", user.DaysLate,
+		"AverageCheckIn", averageCheckIn,
+		"WorkDuration", utils.FormatDuration(user.WorkDuration, lang),
+		"MissingCheckouts", user.MissingCheckouts,
+		"Absent", user.DaysAbsent)
+}
+
+// FormatHoliday renders a holiday's date and name, if it has one
+func FormatHoliday(holiday models.Holiday, lang string) string {
+	date := holiday.Date
+	if day, err := utils.ParseDate(holiday.Date); err == nil {
+		date = utils.FormatDateIn(day, "dd MMMM yyyy", lang)
+	}
+	if holiday.Name == "" {
+		return date
+	}
+	return date + " - " + holiday.Name
+}
@@ -0,0 +1,79 @@
+package reports
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"strings"
+	"time"
+)
+
+// FormatOvertimeSummary renders each employee's overtime of a summary in the given language as one
+// or more plain-text Telegram messages, noting the threshold overtime is counted from. Users are
+// listed under their department when the summary has department totals.
+func FormatOvertimeSummary(summary *models.MonthlySummary, threshold time.Duration, lang string) []string {
+	var header strings.Builder
+	month := summary.Month
+	if first, err := utils.ParseDate(summary.StartDate); err == nil {
+		month = utils.FormatDateIn(first, "MMMM yyyy", lang)
+	}
+	header.WriteString(i18n.T(lang, "overtime.title", "Month", month, "Start", summary.StartDate, "End", summary.EndDate))
+	if summary.Department != "" {
+		header.WriteString(i18n.T(lang, "monthly.department", "Department", summary.Department))
+	}
+	if threshold > 0 {
+		header.WriteString(i18n.T(lang, "overtime.threshold", "Threshold", utils.FormatDuration(threshold, lang)))
+	}
+
+	if len(summary.Users) == 0 {
+		header.WriteString(i18n.T(lang, "overtime.empty"))
+		return []string{header.String()}
+	}
+	var total time.Duration
+	for i := range summary.Users {
+		total += summary.Users[i].Overtime
+	}
+	header.WriteString(i18n.T(lang, "overtime.total", "Count", len(summary.Users), "Overtime", utils.FormatDuration(total, lang)))
+
+	departments := make(map[string]time.Duration, len(summary.Departments))
+	for _, department := range summary.Departments {
+		departments[department.Name] = department.Overtime
+	}
+
+	var messages []string
+	for start := 0; start < len(summary.Users); start += monthlyUsersPerMessage {
+		var message strings.Builder
+		if start == 0 {
+			message.WriteString(header.String())
+		}
+		for i := start; i < min(start+monthlyUsersPerMessage, len(summary.Users)); i++ {
+			user := &summary.Users[i]
+			// Each message names the department it continues with
+			if len(summary.Departments) > 0 && (i == start || summary.Users[i-1].Department != user.Department) {
+				message.WriteString("\n" + i18n.T(lang, "overtime.department",
+					"Department", departmentName(user.Department, lang),
+					"Overtime", utils.FormatDuration(departments[user.Department], lang)) + "\n")
+			}
+			message.WriteString("\n" + formatOvertimeUser(user, lang))
+		}
+		messages = append(messages, message.String())
+	}
+
+	return messages
+}
+
+// formatOvertimeUser renders one employee's overtime, with their employee ID for payroll
+func formatOvertimeUser(user *models.MonthlyUserSummary, lang string) string {
+	name := user.Name
+	if user.EmployeeID != "" {
+		name += " [" + user.EmployeeID + "]"
+	}
+	if user.Username != "" {
+		name += " (@" + user.Username + ")"
+	}
+
+	return i18n.T(lang, "overtime.user",
+		"Name", name,
+		"Overtime", utils.FormatDuration(user.Overtime, lang),
+		"Days", user.DaysOvertime)
+}
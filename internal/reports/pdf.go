@@ -0,0 +1,394 @@
+package reports
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Page layout of PDF summaries in points: A4 landscape, so the table fits on the page width
+const (
+	pdfPageWidth  = 842
+	pdfPageHeight = 595
+	pdfMargin     = 40
+	pdfRowHeight  = 16
+	pdfFontSize   = 9
+	pdfCellPad    = 4
+)
+
+// PDF fonts: the standard Helvetica faces every viewer has, so no font is embedded
+const (
+	pdfRegular = "F1"
+	pdfBold    = "F2"
+)
+
+// PDFGenerator handles PDF report generation
+type PDFGenerator struct {
+	outputDir string
+}
+
+// NewPDFGenerator creates a new PDF generator
+func NewPDFGenerator(outputDir string) *PDFGenerator {
+	return &PDFGenerator{
+		outputDir: outputDir,
+	}
+}
+
+// GenerateSummaryReport creates a PDF file with the attendance summary of a period
+func (g *PDFGenerator) GenerateSummaryReport(summary *models.MonthlySummary, lang string) (string, error) {
+	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Create the file with a unique name so concurrent requests for the same period don't collide
+	pattern := fmt.Sprintf("attendance_summary_%s_to_%s_*.pdf", summary.StartDate, summary.EndDate)
+	file, err := os.CreateTemp(g.outputDir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create PDF file: %w", err)
+	}
+	defer file.Close()
+
+	if err := WriteSummaryPDF(file, summary, lang, utils.Now()); err != nil {
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+// pdfColumn is one column of the summary table
+type pdfColumn struct {
+	key   string  // Message "pdf.column.<key>" of the header
+	width float64 // In points
+	right bool    // Right-aligned, for numbers
+}
+
+// pdfColumns are the columns of the summary table, filling the width between the margins
+var pdfColumns = []pdfColumn{
+	{key: "no", width: 28, right: true},
+	{key: "name", width: 145},
+	{key: "employee_id", width: 80},
+	{key: "department", width: 120},
+	{key: "present", width: 60, right: true},
+	{key: "late", width: 55, right: true},
+	{key: "average_check_in", width: 75, right: true},
+	{key: "work", width: 75, right: true},
+	{key: "missing_checkouts", width: 79, right: true},
+	{key: "absent", width: 45, right: true},
+}
+
+// WriteSummaryPDF writes a printable attendance summary for HR sign-off: a header with the period,
+// a table with one row per employee, a totals row and signature lines. Pages are A4 landscape
+// with the table header repeated and numbered in the footer. Text is set in Helvetica, which
+// covers Latin script only; other characters are printed as "?".
+func WriteSummaryPDF(w io.Writer, summary *models.MonthlySummary, lang string, generated time.Time) error {
+	doc := &pdfDocument{}
+	page := doc.addPage()
+	y := float64(pdfPageHeight - pdfMargin)
+
+	// Header
+	title := i18n.T(lang, "pdf.title")
+	if first, err := utils.ParseDate(summary.StartDate); err == nil && summary.Month != "" {
+		title += " " + utils.FormatDateIn(first, "MMMM yyyy", lang)
+	}
+	y -= 16
+	page.text(pdfBold, 16, pdfMargin, y, title)
+	y -= 18
+	page.text(pdfRegular, 10, pdfMargin, y, i18n.T(lang, "pdf.period", "Start", summary.StartDate, "End", summary.EndDate))
+	if summary.Department != "" {
+		y -= 14
+		page.text(pdfRegular, 10, pdfMargin, y, i18n.T(lang, "pdf.department", "Department", summary.Department))
+	}
+	y -= 14
+	page.text(pdfRegular, 10, pdfMargin, y, i18n.T(lang, "pdf.working_days", "Days", summary.WorkingDays))
+	y -= 14
+	page.text(pdfRegular, 8, pdfMargin, y, i18n.T(lang, "pdf.generated",
+		"Time", generated.Format("2006-01-02 15:04"), "Zone", generated.Format("MST")))
+	y -= 16
+
+	if len(summary.Users) == 0 {
+		y -= 14
+		page.text(pdfRegular, 10, pdfMargin, y, i18n.T(lang, "pdf.empty"))
+		return doc.write(w, title, lang)
+	}
+
+	// Table, continued on new pages with the header repeated
+	header := make([]string, len(pdfColumns))
+	for i, column := range pdfColumns {
+		header[i] = i18n.T(lang, "pdf.column."+column.key)
+	}
+	page.tableRow(y, header, true, true)
+	y -= pdfRowHeight
+
+	var total models.MonthlyUserSummary
+	var checkInMinutes int
+	for i := range summary.Users {
+		user := &summary.Users[i]
+		if y-pdfRowHeight < pdfMargin+pdfRowHeight {
+			page = doc.addPage()
+			y = float64(pdfPageHeight - pdfMargin - pdfRowHeight)
+			page.tableRow(y, header, true, true)
+			y -= pdfRowHeight
+		}
+
+		page.tableRow(y, summaryCells(fmt.Sprintf("%d", i+1), user, lang), false, false)
+		y -= pdfRowHeight
+
+		total.DaysPresent += user.DaysPresent
+		total.DaysCheckedIn += user.DaysCheckedIn
+		total.DaysLate += user.DaysLate
+		total.WorkDuration += user.WorkDuration
+		total.MissingCheckouts += user.MissingCheckouts
+		total.DaysAbsent += user.DaysAbsent
+		checkInMinutes += int(user.AverageCheckIn.Minutes()) * user.DaysCheckedIn
+	}
+
+	// The totals row averages check-ins over all days checked in
+	if total.DaysCheckedIn > 0 {
+		total.AverageCheckIn = time.Duration(checkInMinutes/total.DaysCheckedIn) * time.Minute
+	}
+	total.Name = i18n.T(lang, "pdf.total", "Count", len(summary.Users))
+	if y-pdfRowHeight < pdfMargin+pdfRowHeight {
+		page = doc.addPage()
+		y = float64(pdfPageHeight - pdfMargin - pdfRowHeight)
+	}
+	page.tableRow(y, summaryCells("", &total, lang), true, false)
+	y -= pdfRowHeight
+
+	// Signature lines, kept together on one page
+	const signatureHeight = 70
+	if y-signatureHeight < pdfMargin+pdfRowHeight {
+		page = doc.addPage()
+		y = float64(pdfPageHeight - pdfMargin)
+	}
+	y -= signatureHeight
+	for i, key := range []string{"pdf.prepared_by", "pdf.approved_by"} {
+		x := float64(pdfMargin + i*320)
+		page.text(pdfRegular, 10, x, y+52, i18n.T(lang, key))
+		page.line(x, y+12, x+220, y+12)
+		page.text(pdfRegular, 8, x, y, i18n.T(lang, "pdf.signature_date"))
+	}
+
+	return doc.write(w, title, lang)
+}
+
+// summaryCells returns the table cells of one employee's totals
+func summaryCells(number string, user *models.MonthlyUserSummary, lang string) []string {
+	averageCheckIn := "-"
+	if user.DaysCheckedIn > 0 {
+		averageCheckIn = time.Time{}.Add(user.AverageCheckIn).Format("15:04")
+	}
+	department := user.Department
+	if department == "" && user.UserID != 0 {
+		department = "-"
+	}
+
+	return []string{
+		number,
+		user.Name,
+		user.EmployeeID,
+		department,
+		fmt.Sprintf("%d", user.DaysPresent),
+		fmt.Sprintf("%d", user.DaysLate),
+		averageCheckIn,
+		formatHours(user.WorkDuration),
+		fmt.Sprintf("%d", user.MissingCheckouts),
+		fmt.Sprintf("%d", user.DaysAbsent),
+	}
+}
+
+// formatHours renders a duration as hours and minutes, e.g. 163:05
+func formatHours(d time.Duration) string {
+	minutes := int(d.Minutes())
+	return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+}
+
+// pdfDocument is a PDF being built, one content stream per page
+type pdfDocument struct {
+	pages []*pdfPage
+}
+
+// pdfPage is the content stream of one page
+type pdfPage struct {
+	content strings.Builder
+}
+
+// addPage starts a new page
+func (d *pdfDocument) addPage() *pdfPage {
+	page := &pdfPage{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// text draws a line of text with its baseline starting at x, y
+func (p *pdfPage) text(font string, size, x, y float64, text string) {
+	fmt.Fprintf(&p.content, "BT /%s %g Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(text))
+}
+
+// line draws a thin line
+func (p *pdfPage) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// tableRow draws one row of the summary table with its top at y, in bold for the header and
+// totals, on grey for the header. Cells too wide for their column are shortened.
+func (p *pdfPage) tableRow(y float64, cells []string, bold, shaded bool) {
+	font := pdfRegular
+	if bold {
+		font = pdfBold
+	}
+
+	width := 0.0
+	for _, column := range pdfColumns {
+		width += column.width
+	}
+	if shaded {
+		fmt.Fprintf(&p.content, "0.85 g %d %.2f %.2f %d re f 0 g\n", pdfMargin, y-pdfRowHeight, width, pdfRowHeight)
+	}
+
+	x := float64(pdfMargin)
+	baseline := y - pdfRowHeight + 5
+	for i, column := range pdfColumns {
+		text := fitText(cells[i], font, column.width-2*pdfCellPad)
+		switch {
+		case text == "":
+		case column.right:
+			p.text(font, pdfFontSize, x+column.width-pdfCellPad-textWidth(text, font, pdfFontSize), baseline, text)
+		default:
+			p.text(font, pdfFontSize, x+pdfCellPad, baseline, text)
+		}
+		x += column.width
+	}
+	p.line(pdfMargin, y-pdfRowHeight, pdfMargin+width, y-pdfRowHeight)
+}
+
+// fitText shortens text with "..." until it fits in width at the table's font size
+func fitText(text, font string, width float64) string {
+	if textWidth(text, font, pdfFontSize) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		shortened := strings.TrimSpace(string(runes)) + "..."
+		if textWidth(shortened, font, pdfFontSize) <= width {
+			return shortened
+		}
+	}
+	return ""
+}
+
+// textWidth returns the width of text in points, using the Helvetica metrics of ASCII
+// characters and an average width for the rest
+func textWidth(text, font string, size float64) float64 {
+	widths := helveticaWidths
+	if font == pdfBold {
+		widths = helveticaBoldWidths
+	}
+
+	units := 0
+	for _, r := range text {
+		if r >= 32 && r < 127 {
+			units += widths[r-32]
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// pdfString encodes text as the body of a PDF string in WinAnsiEncoding, which matches Latin-1 for
+// the characters used here; characters outside it become "?"
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// write writes the document: the catalog, the page tree, both fonts, the pages with their
+// content streams and a page number footer, the document information and the cross-reference table
+func (d *pdfDocument) write(w io.Writer, title, lang string) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 4 are fixed; each page then takes two, the page and its content stream
+	const firstPage = 5
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		footer := i18n.T(lang, "pdf.page", "Page", i+1, "Pages", len(d.pages))
+		page.text(pdfRegular, 8, pdfMargin, pdfMargin-16, title)
+		page.text(pdfRegular, 8, pdfPageWidth-pdfMargin-textWidth(footer, pdfRegular, 8), pdfMargin-16, footer)
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, pdfRegular, pdfBold, firstPage+2*i+1))
+		content := page.content.String()
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+	object(fmt.Sprintf("<< /Title (%s) /Producer (attendance-bot) >>", pdfString(title)))
+	info := len(offsets)
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, info, xref)
+
+	if _, err := w.Write(out.Bytes()); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+// helveticaWidths are the widths of the ASCII characters from space to tilde in Helvetica, in
+// thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+// helveticaBoldWidths are the widths of the ASCII characters from space to tilde in Helvetica-Bold
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611, // 0 to ?
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556, // P to _
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611, // ` to o
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584, // p to ~
+}
@@ -0,0 +1,28 @@
+package reports
+
+import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+)
+
+// FormatWeeklySummary renders an employee's totals of the week from startDate to endDate as a
+// Telegram message to them, in the given language
+func FormatWeeklySummary(user *models.MonthlyUserSummary, startDate, endDate string, workingDays int, lang string) string {
+	start, end := startDate, endDate
+	if day, err := utils.ParseDate(startDate); err == nil {
+		start = utils.FormatDateIn(day, "dd MMMM yyyy", lang)
+	}
+	if day, err := utils.ParseDate(endDate); err == nil {
+		end = utils.FormatDateIn(day, "dd MMMM yyyy", lang)
+	}
+
+	return i18n.T(lang, "weekly.summary",
+		"Start", start,
+		"End", end,
+		"Present", user.DaysPresent,
+		"WorkingDays", workingDays,
+		"Late", user.DaysLate,
+		"WorkDuration", utils.FormatDuration(user.WorkDuration, lang),
+		"MissingCheckouts", user.MissingCheckouts)
+}
@@ -0,0 +1,360 @@
+package reports
+
+import (
+	"archive/zip"
+	"attendance-bot/pkg/models"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Cell styles defined in xlsxStyles, by index into cellXfs
+const (
+	xlsxStyleDefault = 0
+	xlsxStyleHeader  = 1
+	xlsxStyleTotal   = 2
+)
+
+// Column widths in characters, bounding the width derived from the content
+const (
+	xlsxMinColumnWidth = 8
+	xlsxMaxColumnWidth = 50
+)
+
+// XLSXGenerator handles Excel report generation
+type XLSXGenerator struct {
+	outputDir string
+}
+
+// NewXLSXGenerator creates a new Excel generator
+func NewXLSXGenerator(outputDir string) *XLSXGenerator {
+	return &XLSXGenerator{
+		outputDir: outputDir,
+	}
+}
+
+// GenerateAttendanceReport creates an Excel file with attendance data and approved leave
+func (g *XLSXGenerator) GenerateAttendanceReport(records []models.AttendanceRecord, leave []models.LeaveDay, shifts *models.ShiftAssignments, startDate, endDate string) (string, error) {
+	// Ensure output directory exists
+	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Create the file with a unique name so concurrent requests for the same period don't collide
+	pattern := fmt.Sprintf("attendance_report_%s_to_%s_*.xlsx", startDate, endDate)
+	file, err := os.CreateTemp(g.outputDir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create XLSX file: %w", err)
+	}
+	defer file.Close()
+
+	if err := WriteAttendanceXLSX(file, records, leave, shifts); err != nil {
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+// WriteAttendanceXLSX writes an Excel workbook with two sheets: "Absensi" with the rows of
+// WritePivotCSV, late and early check-outs highlighted, and "Ringkasan" with a summary per day.
+// Both sheets have a frozen header row.
+func WriteAttendanceXLSX(w io.Writer, records []models.AttendanceRecord, leave []models.LeaveDay, shifts *models.ShiftAssignments) error {
+	rows := pivotRows(records, leave, shifts)
+
+	attendance := &xlsxSheet{name: "Absensi"}
+	attendance.addRow(xlsxStyleHeader, stringCells(pivotHeader)...)
+	for i := range rows {
+		row := &rows[i]
+		attendance.addRow(xlsxStyleDefault,
+			xlsxCell{value: row.date},
+			xlsxCell{value: fmt.Sprintf("%d", row.userID), number: true},
+			xlsxCell{value: row.username},
+			xlsxCell{value: row.name},
+			xlsxCell{value: row.employeeID},
+			xlsxCell{value: row.department},
+			xlsxCell{value: row.checkIn},
+			xlsxCell{value: row.checkOut},
+			xlsxCell{value: row.duration},
+			xlsxCell{value: row.breaks, number: row.breaks != "-"},
+			xlsxCell{value: row.overtime, number: row.overtime != "-"},
+			xlsxCell{value: row.status},
+		)
+	}
+	// Highlight by the Status column, so rows stay highlighted when sorted or edited
+	if len(rows) > 0 {
+		status := xlsxColumn(len(pivotHeader) - 1)
+		attendance.highlights = []xlsxHighlight{
+			{formula: fmt.Sprintf(`ISNUMBER(SEARCH("Late",$%s2))`, status), dxf: 0},
+			{formula: fmt.Sprintf(`ISNUMBER(SEARCH("Left Early",$%s2))`, status), dxf: 1},
+		}
+	}
+
+	summary := &xlsxSheet{name: "Ringkasan"}
+	summary.addRow(xlsxStyleHeader, stringCells([]string{"Tanggal", "Hadir", "Tepat Waktu", "Terlambat", "Pulang Awal", "Cuti/Izin/Sakit", "Lembur (menit)"})...)
+	days := summarizeDays(rows)
+	var total daySummary
+	for _, day := range days {
+		summary.addRow(xlsxStyleDefault, append([]xlsxCell{{value: day.date}}, day.cells()...)...)
+		total.present += day.present
+		total.onTime += day.onTime
+		total.late += day.late
+		total.leftEarly += day.leftEarly
+		total.leave += day.leave
+		total.overtime += day.overtime
+	}
+	if len(days) > 0 {
+		summary.addRow(xlsxStyleTotal, append([]xlsxCell{{value: "Total"}}, total.cells()...)...)
+	}
+
+	return writeXLSX(w, attendance, summary)
+}
+
+// daySummary counts the rows of one day
+type daySummary struct {
+	date                  string
+	present, onTime, late int
+	leftEarly, leave      int
+	overtime              int // Minutes
+}
+
+// cells returns the counts as numeric cells, in the summary sheet's column order
+func (d *daySummary) cells() []xlsxCell {
+	var cells []xlsxCell
+	for _, count := range []int{d.present, d.onTime, d.late, d.leftEarly, d.leave, d.overtime} {
+		cells = append(cells, xlsxCell{value: fmt.Sprintf("%d", count), number: true})
+	}
+	return cells
+}
+
+// summarizeDays counts attendance, lateness, early check-outs, leave and minutes of overtime per
+// day, in date order
+func summarizeDays(rows []pivotRow) []daySummary {
+	var days []daySummary
+	index := make(map[string]int)
+	for i := range rows {
+		row := &rows[i]
+		j, ok := index[row.date]
+		if !ok {
+			j = len(days)
+			index[row.date] = j
+			days = append(days, daySummary{date: row.date})
+		}
+
+		day := &days[j]
+		switch {
+		case row.leave:
+			day.leave++
+		case row.checkIn != "-":
+			day.present++
+			if row.late {
+				day.late++
+			} else {
+				day.onTime++
+			}
+			if row.leftEarly {
+				day.leftEarly++
+			}
+			day.overtime += row.overtimeMinutes
+		}
+	}
+
+	sort.SliceStable(days, func(i, j int) bool { return days[i].date < days[j].date })
+	return days
+}
+
+// xlsxSheet is a worksheet being built
+type xlsxSheet struct {
+	name       string
+	rows       [][]xlsxCell
+	styles     []int
+	highlights []xlsxHighlight // Conditional formats applied to all data rows
+}
+
+// xlsxCell is one cell value; numbers are written as numbers, everything else as text
+type xlsxCell struct {
+	value  string
+	number bool
+}
+
+// xlsxHighlight fills data rows for which formula, written for the first data row, is true,
+// using the differential format dxf of xlsxStyles
+type xlsxHighlight struct {
+	formula string
+	dxf     int
+}
+
+// addRow appends a row with one style for all its cells
+func (s *xlsxSheet) addRow(style int, cells ...xlsxCell) {
+	s.rows = append(s.rows, cells)
+	s.styles = append(s.styles, style)
+}
+
+// stringCells converts values to text cells
+func stringCells(values []string) []xlsxCell {
+	cells := make([]xlsxCell, len(values))
+	for i, value := range values {
+		cells[i] = xlsxCell{value: value}
+	}
+	return cells
+}
+
+// columns returns the number of columns of the widest row
+func (s *xlsxSheet) columns() int {
+	columns := 0
+	for _, row := range s.rows {
+		columns = max(columns, len(row))
+	}
+	return columns
+}
+
+// xml renders the worksheet part
+func (s *xlsxSheet) xml() string {
+	var b strings.Builder
+	columns := s.columns()
+
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0">`)
+	b.WriteString(`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`)
+	b.WriteString(`<selection pane="bottomLeft" activeCell="A2" sqref="A2"/>`)
+	b.WriteString(`</sheetView></sheetViews>`)
+
+	// Size each column to its longest value
+	if columns > 0 {
+		b.WriteString(`<cols>`)
+		for c := 0; c < columns; c++ {
+			width := xlsxMinColumnWidth
+			for _, row := range s.rows {
+				if c < len(row) {
+					width = max(width, utf8.RuneCountInString(row[c].value)+2)
+				}
+			}
+			width = min(width, xlsxMaxColumnWidth)
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, c+1, c+1, width)
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	for r, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := fmt.Sprintf("%s%d", xlsxColumn(c), r+1)
+			if cell.number {
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, s.styles[r], cell.value)
+				continue
+			}
+			fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, s.styles[r])
+			xml.EscapeText(&b, []byte(cell.value))
+			b.WriteString(`</t></is></c>`)
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData>`)
+
+	if len(s.highlights) > 0 && len(s.rows) > 1 {
+		fmt.Fprintf(&b, `<conditionalFormatting sqref="A2:%s%d">`, xlsxColumn(columns-1), len(s.rows))
+		for i, highlight := range s.highlights {
+			fmt.Fprintf(&b, `<cfRule type="expression" dxfId="%d" priority="%d"><formula>`, highlight.dxf, i+1)
+			xml.EscapeText(&b, []byte(highlight.formula))
+			b.WriteString(`</formula></cfRule>`)
+		}
+		b.WriteString(`</conditionalFormatting>`)
+	}
+
+	b.WriteString(`</worksheet>`)
+	return b.String()
+}
+
+// xlsxColumn returns the letters of a zero-based column index, e.g. 0 is A and 26 is AA
+func xlsxColumn(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxStyles defines the cell styles (default, bold header on grey, bold total) and the
+// differential formats used for highlighting (0: late in red, 1: left early in amber)
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FFD9D9D9"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`<dxfs count="2">` +
+	`<dxf><font><color rgb="FF9C0006"/></font><fill><patternFill><bgColor rgb="FFFFC7CE"/></patternFill></fill></dxf>` +
+	`<dxf><font><color rgb="FF9C5700"/></font><fill><patternFill><bgColor rgb="FFFFEB9C"/></patternFill></fill></dxf>` +
+	`</dxfs></styleSheet>`
+
+// writeXLSX writes a workbook with the given sheets as a zip package
+func writeXLSX(w io.Writer, sheets ...*xlsxSheet) error {
+	var contentTypes, workbook, workbookRels strings.Builder
+
+	contentTypes.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	for i, sheet := range sheets {
+		n := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		workbook.WriteString(`<sheet name="`)
+		xml.EscapeText(&workbook, []byte(sheet.name))
+		fmt.Fprintf(&workbook, `" sheetId="%d" r:id="rId%d"/>`, n, n)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1)
+
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	workbookRels.WriteString(`</Relationships>`)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet.xml()})
+	}
+
+	archive := zip.NewWriter(w)
+	for _, part := range parts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("failed to create XLSX part %s: %w", part.name, err)
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return fmt.Errorf("failed to write XLSX part %s: %w", part.name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish XLSX: %w", err)
+	}
+
+	return nil
+}
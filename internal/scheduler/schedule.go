@@ -0,0 +1,227 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchDays bounds how far ahead Next looks; a 29 February schedule can wait eight years
+const searchDays = 9 * 366
+
+// macros are the supported shorthands for common schedules
+var macros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// Schedule is a parsed five-field cron expression: minute, hour, day of month, month and day of
+// week (0 or 7 is Sunday). Fields accept *, numbers, ranges (1-5), lists (1,3) and steps (*/15).
+// As in cron, a day matches when either day field matches if both are restricted.
+type Schedule struct {
+	expr             string
+	minute, hour     uint64
+	dom, month, dow  uint64
+	domStar, dowStar bool
+}
+
+// field describes the allowed values of one cron field
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = [5]field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Parse parses a cron expression or one of @hourly, @daily, @weekly and @monthly
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	spec := expr
+	if macro, ok := macros[strings.ToLower(expr)]; ok {
+		spec = macro
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, got %d", expr, len(parts))
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		var err error
+		if bits[i], err = parseField(part, fields[i]); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", expr, err)
+		}
+	}
+
+	// Sunday may be written as 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	schedule := &Schedule{
+		expr:    expr,
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}
+
+	if schedule.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("invalid schedule %q: never runs", expr)
+	}
+
+	return schedule, nil
+}
+
+// Daily returns a schedule running every day at the given time
+func Daily(hour, minute int) *Schedule {
+	schedule, err := Parse(fmt.Sprintf("%d %d * * *", minute, hour))
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+// Hourly returns a schedule running at the start of every hour
+func Hourly() *Schedule {
+	schedule, err := Parse("0 * * * *")
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+// EveryMinute returns a schedule running at the start of every minute
+func EveryMinute() *Schedule {
+	schedule, err := Parse("* * * * *")
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+// parseField parses one comma-separated cron field into a bit set of its values
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%s: invalid step %q", f.name, stepPart)
+			}
+		}
+
+		low, high := f.min, f.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseValue(from, f); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(to, f); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("%s: range %q is reversed", f.name, rangePart)
+			}
+		default:
+			var err error
+			if low, err = parseValue(rangePart, f); err != nil {
+				return 0, err
+			}
+			// A single value with a step runs from that value to the end of the field
+			if !hasStep {
+				high = low
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// parseValue parses a number within the field's bounds
+func parseValue(value string, f field) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid value %q", f.name, value)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s: %d is out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first time strictly after after, in after's location, at which the schedule
+// runs, or the zero time if it does not run within the next years
+func (s *Schedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute(), 0, 0, loc).Add(time.Minute)
+
+	for day := 0; day < searchDays; day++ {
+		if s.matchesDay(t) {
+			for hour := t.Hour(); hour < 24; hour++ {
+				if s.hour&(1<<hour) == 0 {
+					continue
+				}
+				minute := 0
+				if hour == t.Hour() {
+					minute = t.Minute()
+				}
+				for ; minute < 60; minute++ {
+					if s.minute&(1<<minute) != 0 {
+						return time.Date(t.Year(), t.Month(), t.Day(), hour, minute, 0, 0, loc)
+					}
+				}
+			}
+		}
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+	}
+
+	return time.Time{}
+}
+
+// matchesDay reports whether the schedule runs on t's day
+func (s *Schedule) matchesDay(t time.Time) bool {
+	if s.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+
+	domMatch := s.dom&(1<<t.Day()) != 0
+	dowMatch := s.dow&(1<<int(t.Weekday())) != 0
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dowMatch
+	case s.dowStar:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+// String returns the expression the schedule was parsed from
+func (s *Schedule) String() string {
+	return s.expr
+}
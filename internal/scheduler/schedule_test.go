@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "expected 5 fields, got 0"},
+		{"0 8 * *", "expected 5 fields, got 4"},
+		{"0 8 * * * *", "expected 5 fields, got 6"},
+		{"60 8 * * *", "minute: 60 is out of range 0-59"},
+		{"0 24 * * *", "hour: 24 is out of range 0-23"},
+		{"0 8 0 * *", "day of month: 0 is out of range 1-31"},
+		{"0 8 * 13 *", "month: 13 is out of range 1-12"},
+		{"0 8 * * 8", "day of week: 8 is out of range 0-7"},
+		{"0 8 * * 5-1", `day of week: range "5-1" is reversed`},
+		{"*/0 8 * * *", `minute: invalid step "0"`},
+		{"x 8 * * *", `minute: invalid value "x"`},
+		{"0 8 31 2 *", "never runs"},
+		{"@yearly", "expected 5 fields, got 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse(%q) = %v, %v; want an error containing %q", tt.expr, schedule, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	// Monday 4 March 2024, 08:30:45
+	monday := time.Date(2024, 3, 4, 8, 30, 45, 0, jakarta)
+
+	tests := []struct {
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"* * * * *", monday, time.Date(2024, 3, 4, 8, 31, 0, 0, jakarta)},
+		{"@hourly", monday, time.Date(2024, 3, 4, 9, 0, 0, 0, jakarta)},
+		{"@daily", monday, time.Date(2024, 3, 5, 0, 0, 0, 0, jakarta)},
+		{"@weekly", monday, time.Date(2024, 3, 10, 0, 0, 0, 0, jakarta)},
+		{"@monthly", monday, time.Date(2024, 4, 1, 0, 0, 0, 0, jakarta)},
+		{"30 8 * * *", monday, time.Date(2024, 3, 5, 8, 30, 0, 0, jakarta)},
+		{"45 8 * * *", monday, time.Date(2024, 3, 4, 8, 45, 0, 0, jakarta)},
+		{"*/15 9-17 * * 1-5", monday, time.Date(2024, 3, 4, 9, 0, 0, 0, jakarta)},
+		{"0 17 * * 1-5", time.Date(2024, 3, 8, 17, 0, 0, 0, jakarta), time.Date(2024, 3, 11, 17, 0, 0, 0, jakarta)},
+		{"0 8 * * 7", monday, time.Date(2024, 3, 10, 8, 0, 0, 0, jakarta)},
+		{"0 8 1,15 * *", monday, time.Date(2024, 3, 15, 8, 0, 0, 0, jakarta)},
+		// Either day field matches when both are restricted
+		{"0 8 15 * 3", monday, time.Date(2024, 3, 6, 8, 0, 0, 0, jakarta)},
+		{"0 0 29 2 *", monday, time.Date(2028, 2, 29, 0, 0, 0, 0, jakarta)},
+		{"0 8 * * *", time.Date(2024, 12, 31, 9, 0, 0, 0, jakarta), time.Date(2025, 1, 1, 8, 0, 0, 0, jakarta)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			if got := schedule.Next(tt.after); !got.Equal(tt.want) || got.Location() != jakarta {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleHelpers(t *testing.T) {
+	after := time.Date(2024, 3, 4, 8, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule *Schedule
+		want     time.Time
+	}{
+		{"Daily", Daily(17, 5), time.Date(2024, 3, 4, 17, 5, 0, 0, time.UTC)},
+		{"Hourly", Hourly(), time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)},
+		{"EveryMinute", EveryMinute(), time.Date(2024, 3, 4, 8, 31, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Next(after); !got.Equal(tt.want) {
+				t.Errorf("%s().Next(%v) = %v, want %v", tt.name, after, got, tt.want)
+			}
+		})
+	}
+}
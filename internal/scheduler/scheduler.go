@@ -0,0 +1,136 @@
+// Package scheduler runs background jobs at times given by cron expressions
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Job is work run on a schedule
+type Job struct {
+	Name         string
+	Schedule     *Schedule
+	Run          func(ctx context.Context)
+	SkipHolidays bool // Do not run on days the scheduler's calendar marks as holidays
+}
+
+// Calendar tells which days are holidays
+type Calendar interface {
+	IsHoliday(day time.Time) bool
+}
+
+// Holidays is a Calendar of fixed dates
+type Holidays map[string]bool
+
+// ParseHolidays parses YYYY-MM-DD dates into a Calendar, ignoring empty entries
+func ParseHolidays(dates []string) (Holidays, error) {
+	holidays := make(Holidays, len(dates))
+	for _, date := range dates {
+		date = strings.TrimSpace(date)
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid holiday %q: %w", date, err)
+		}
+		holidays[date] = true
+	}
+	return holidays, nil
+}
+
+// IsHoliday reports whether the day, in its own location, is one of the dates
+func (h Holidays) IsHoliday(day time.Time) bool {
+	return h[day.Format("2006-01-02")]
+}
+
+// Scheduler runs jobs at their scheduled times in one location
+type Scheduler struct {
+	location *time.Location
+	calendar Calendar
+	logger   *slog.Logger
+	jobs     []Job
+	now      func() time.Time
+}
+
+// New creates a scheduler evaluating schedules in the given location
+func New(location *time.Location, logger *slog.Logger) *Scheduler {
+	return &Scheduler{
+		location: location,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// SetCalendar sets the holidays skipped by jobs with SkipHolidays
+func (s *Scheduler) SetCalendar(calendar Calendar) {
+	s.calendar = calendar
+}
+
+// Add registers a job. Jobs must be added before Run.
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Len returns the number of registered jobs
+func (s *Scheduler) Len() int {
+	return len(s.jobs)
+}
+
+// Run runs the jobs until ctx is cancelled, then waits for running jobs to return. Jobs
+// receive ctx and should stop promptly once it is cancelled. A run that is due while the
+// previous run of the same job is still going is skipped.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+// loop waits for each scheduled time of the job and runs it
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	logger := s.logger.With("job", job.Name)
+
+	for {
+		next := job.Schedule.Next(s.now().In(s.location))
+		if next.IsZero() {
+			logger.Warn("Job has no upcoming run")
+			return
+		}
+		logger.Debug("Job scheduled", "at", next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if job.SkipHolidays && s.calendar != nil && s.calendar.IsHoliday(next) {
+			logger.Info("Skipping job on holiday", "date", next.Format("2006-01-02"))
+			continue
+		}
+
+		s.run(ctx, logger, job)
+	}
+}
+
+// run runs the job once, recovering from a panic so later runs still happen
+func (s *Scheduler) run(ctx context.Context, logger *slog.Logger, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Panic in scheduled job", "panic", r)
+		}
+	}()
+
+	job.Run(ctx)
+}
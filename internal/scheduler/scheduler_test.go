@@ -0,0 +1,49 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHolidays(t *testing.T) {
+	holidays, err := ParseHolidays([]string{"2024-03-11", " 2024-04-10 ", ""})
+	if err != nil {
+		t.Fatalf("ParseHolidays: %v", err)
+	}
+
+	tests := []struct {
+		day  time.Time
+		want bool
+	}{
+		{time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 4, 10, 23, 59, 0, 0, time.UTC), true},
+		{time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := holidays.IsHoliday(tt.day); got != tt.want {
+			t.Errorf("IsHoliday(%s) = %v, want %v", tt.day.Format("2006-01-02"), got, tt.want)
+		}
+	}
+
+	if _, err := ParseHolidays([]string{"2024-02-30"}); err == nil {
+		t.Error("ParseHolidays accepted 2024-02-30")
+	}
+}
+
+// TestRunRecoversFromPanic runs a job that panics: the panic is logged instead of stopping the
+// scheduler
+func TestRunRecoversFromPanic(t *testing.T) {
+	var log bytes.Buffer
+	s := New(time.UTC, slog.New(slog.NewTextHandler(&log, nil)))
+
+	job := Job{Name: "broken", Schedule: EveryMinute(), Run: func(ctx context.Context) { panic("boom") }}
+	s.run(context.Background(), s.logger.With("job", job.Name), job)
+
+	if !strings.Contains(log.String(), "Panic in scheduled job") || !strings.Contains(log.String(), "panic=boom") {
+		t.Errorf("log = %q, want the panic logged", log.String())
+	}
+}
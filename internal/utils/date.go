@@ -1,67 +1,140 @@
 package utils
 
 import (
+	"attendance-bot/internal/i18n"
+	"attendance-bot/pkg/models"
 	"fmt"
 	"time"
 )
 
-// JakartaLocation represents the Asia/Jakarta timezone
-var JakartaLocation *time.Location
+// DefaultTimezone is the timezone dates, shifts and schedules are in unless TIMEZONE sets another
+const DefaultTimezone = "Asia/Jakarta"
+
+// Location is the timezone dates, shifts and schedules are in, DefaultTimezone unless SetLocation
+// is called at start-up
+var Location *time.Location
 
 func init() {
 	var err error
-	JakartaLocation, err = time.LoadLocation("Asia/Jakarta")
+	Location, err = time.LoadLocation(DefaultTimezone)
 	if err != nil {
 		// Fallback to UTC+7 if timezone data is not available
-		JakartaLocation = time.FixedZone("WIB", 7*60*60)
+		Location = time.FixedZone("WIB", 7*60*60)
+	}
+}
+
+// LoadLocation loads an IANA timezone such as Asia/Makassar or Europe/Berlin. An empty name and
+// Local, which depend on the host rather than the office, are refused.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("invalid timezone %q", name)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return location, nil
+}
+
+// SetLocation sets the timezone of Location. It must be called before any goroutine uses dates.
+func SetLocation(name string) error {
+	location, err := LoadLocation(name)
+	if err != nil {
+		return err
 	}
+	Location = location
+	return nil
 }
 
-// FormatDate formats a date according to the given format string
+// ZoneName returns the abbreviation of Location at t, e.g. WIB or CET, for messages that name it
+func ZoneName(t time.Time) string {
+	return t.In(Location).Format("MST")
+}
+
+// DefaultLanguage is the language of month and weekday names in formatted dates
+const DefaultLanguage = i18n.Default
+
+// monthNames holds month names per language, January first
+var monthNames = map[string][12]string{
+	"id": {"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+	"en": {"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+}
+
+// weekdayNames holds weekday names per language, Sunday first
+var weekdayNames = map[string][7]string{
+	"id": {"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"},
+	"en": {"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+}
+
+// FormatDate formats a date according to the given format string, naming months in DefaultLanguage
 func FormatDate(t time.Time, format string) string {
-	jakartaTime := t.In(JakartaLocation)
+	return FormatDateIn(t, format, DefaultLanguage)
+}
+
+// FormatDateIn formats a date like FormatDate, naming months and weekdays in the given language.
+// Unknown languages fall back to DefaultLanguage.
+func FormatDateIn(t time.Time, format, language string) string {
+	local := t.In(Location)
+
+	months, ok := monthNames[language]
+	if !ok {
+		months = monthNames[DefaultLanguage]
+	}
+	weekdays, ok := weekdayNames[language]
+	if !ok {
+		weekdays = weekdayNames[DefaultLanguage]
+	}
 
 	switch format {
 	case "yyyy-MM-dd":
-		return jakartaTime.Format("2006-01-02")
+		return local.Format("2006-01-02")
 	case "dd MMMM yyyy":
-		return jakartaTime.Format("02 January 2006")
+		return fmt.Sprintf("%02d %s %d", local.Day(), months[local.Month()-1], local.Year())
+	case "MMMM yyyy":
+		return fmt.Sprintf("%s %d", months[local.Month()-1], local.Year())
+	case "EEEE, dd MMMM yyyy":
+		return fmt.Sprintf("%s, %02d %s %d", weekdays[local.Weekday()], local.Day(), months[local.Month()-1], local.Year())
 	case "dd/MM/yyyy":
-		return jakartaTime.Format("02/01/2006")
+		return local.Format("02/01/2006")
 	default:
-		return jakartaTime.Format(format)
+		return local.Format(format)
 	}
 }
 
-// FormatTime formats a time according to the given format string
+// FormatTime formats a time in Location according to the given format string
 func FormatTime(t time.Time, format string) string {
-	jakartaTime := t.In(JakartaLocation)
+	return FormatTimeIn(t, format, Location)
+}
+
+// FormatTimeIn formats a time in the given location, e.g. a user's own timezone
+func FormatTimeIn(t time.Time, format string, location *time.Location) string {
+	local := t.In(location)
 
 	switch format {
 	case "HH:mm":
-		return jakartaTime.Format("15:04")
+		return local.Format("15:04")
 	case "HH:mm:ss":
-		return jakartaTime.Format("15:04:05")
+		return local.Format("15:04:05")
 	default:
-		return jakartaTime.Format(format)
+		return local.Format(format)
 	}
 }
 
-// IsToday checks if the given time is today in Jakarta timezone
+// IsToday checks if the given time is today in Location
 func IsToday(t time.Time) bool {
-	now := time.Now().In(JakartaLocation)
-	target := t.In(JakartaLocation)
+	now := Now()
+	target := t.In(Location)
 
 	return now.Year() == target.Year() &&
 		now.Month() == target.Month() &&
 		now.Day() == target.Day()
 }
 
-// IsYesterday checks if the given time is yesterday in Jakarta timezone
+// IsYesterday checks if the given time is yesterday in Location
 func IsYesterday(t time.Time) bool {
-	now := time.Now().In(JakartaLocation)
+	now := Now()
 	yesterday := now.AddDate(0, 0, -1)
-	target := t.In(JakartaLocation)
+	target := t.In(Location)
 
 	return yesterday.Year() == target.Year() &&
 		yesterday.Month() == target.Month() &&
@@ -70,12 +143,77 @@ func IsYesterday(t time.Time) bool {
 
 // GetTodayDate returns today's date in YYYY-MM-DD format
 func GetTodayDate() string {
-	return FormatDate(time.Now(), "yyyy-MM-dd")
+	return FormatDate(Now(), "yyyy-MM-dd")
 }
 
-// ParseDate parses a date string in YYYY-MM-DD format
+// ParseDate parses a date string in YYYY-MM-DD format as midnight in Location
 func ParseDate(dateStr string) (time.Time, error) {
-	return time.Parse("2006-01-02", dateStr)
+	return time.ParseInLocation("2006-01-02", dateStr, Location)
+}
+
+// IsLate reports whether a check-in, in the shift's location whatever location the timestamp
+// carries, happened at or after the shift start plus its grace period on the same day. For a shift ending the next
+// day, check-ins after midnight and before the end also count as late.
+func IsLate(checkIn time.Time, shift models.Shift) bool {
+	start, err := ParseClock(shift.Start)
+	if err != nil {
+		return false
+	}
+	local := checkIn.In(ShiftLocation(shift))
+	minute := local.Hour()*60 + local.Minute()
+
+	end, err := ParseClock(shift.End)
+	if err != nil || end.Minutes() > start.Minutes() {
+		return minute >= start.Minutes()+shift.GraceMinutes
+	}
+
+	// Overnight: measure from the start, wrapping at midnight, and stop at the end of the shift
+	sinceStart := (minute - start.Minutes() + 24*60) % (24 * 60)
+	length := (end.Minutes() - start.Minutes() + 24*60) % (24 * 60)
+	return sinceStart >= shift.GraceMinutes && sinceStart < length
+}
+
+// LeftEarly reports whether a check-out happened before the end of the shift the check-in started.
+// Shifts without an end never end early.
+func LeftEarly(checkIn, checkOut time.Time, shift models.Shift) bool {
+	shiftEnd, ok := ShiftEnd(checkIn, shift)
+	return ok && checkOut.Before(shiftEnd)
+}
+
+// Overtime returns how long a check-out came after the end of the shift the check-in started,
+// 0 when it came before or the shift has no end
+func Overtime(checkIn, checkOut time.Time, shift models.Shift) time.Duration {
+	shiftEnd, ok := ShiftEnd(checkIn, shift)
+	if !ok || !checkOut.After(shiftEnd) {
+		return 0
+	}
+	return checkOut.Sub(shiftEnd)
+}
+
+// ShiftEnd returns when the shift the check-in started ends, and false for shifts without an end
+func ShiftEnd(checkIn time.Time, shift models.Shift) (time.Time, bool) {
+	start, startErr := ParseClock(shift.Start)
+	end, endErr := ParseClock(shift.End)
+	if startErr != nil || endErr != nil {
+		return time.Time{}, false
+	}
+
+	local := checkIn.In(ShiftLocation(shift))
+	shiftEnd := end.On(local)
+	// An overnight shift checked into before midnight ends the next day
+	if end.Minutes() <= start.Minutes() && local.Hour()*60+local.Minute() >= end.Minutes() {
+		shiftEnd = shiftEnd.AddDate(0, 0, 1)
+	}
+	return shiftEnd, true
+}
+
+// ShiftLocation returns the timezone of the shift's hours: the user's own timezone when the shift
+// was resolved for a user who set one, else Location
+func ShiftLocation(shift models.Shift) *time.Location {
+	if shift.Location != nil {
+		return shift.Location
+	}
+	return Location
 }
 
 // AddDays adds the specified number of days to the given time
@@ -83,19 +221,110 @@ func AddDays(t time.Time, days int) time.Time {
 	return t.AddDate(0, 0, days)
 }
 
-// CalculateWorkDuration calculates the duration between check-in and check-out times
-func CalculateWorkDuration(checkIn, checkOut time.Time) string {
-	duration := checkOut.Sub(checkIn)
-	hours := int(duration.Hours())
-	minutes := int(duration.Minutes()) % 60
+// maxWorkDuration is the longest span between check-in and check-out treated as plausible
+const maxWorkDuration = 24 * time.Hour
+
+// WorkDuration is the time between a check-in and its check-out, less the breaks in between
+type WorkDuration struct {
+	Duration    time.Duration
+	Valid       bool // False when the check-out is earlier than the check-in
+	Implausible bool // Longer than 24 hours, e.g. a forgotten check-out corrected to the wrong day
+}
+
+// NewWorkDuration measures the span between check-in and check-out, flags impossible values and
+// deducts the time spent on breaks
+func NewWorkDuration(checkIn, checkOut time.Time, breaks time.Duration) WorkDuration {
+	span := checkOut.Sub(checkIn)
+	return WorkDuration{
+		Duration:    max(span-breaks, 0),
+		Valid:       span >= 0,
+		Implausible: span > maxWorkDuration,
+	}
+}
+
+// Countable reports whether the duration may be added to totals
+func (w WorkDuration) Countable() bool {
+	return w.Valid && !w.Implausible
+}
+
+// String renders the duration in DefaultLanguage
+func (w WorkDuration) String() string {
+	return w.Format(DefaultLanguage)
+}
+
+// Format renders the duration in the given language, with a warning for negative or implausible spans
+func (w WorkDuration) Format(language string) string {
+	switch {
+	case !w.Valid:
+		return i18n.T(language, "duration.invalid")
+	case w.Implausible:
+		return i18n.T(language, "duration.implausible")
+	}
+
+	return FormatDuration(w.Duration, language)
+}
+
+// FormatDuration renders a non-negative duration in hours and minutes in the given language,
+// e.g. "5 jam 12 menit"
+func FormatDuration(d time.Duration, language string) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
 
 	if hours > 0 {
-		return fmt.Sprintf("%d jam %d menit", hours, minutes)
+		return i18n.T(language, "duration.hours_minutes", "Hours", hours, "Minutes", minutes)
 	}
-	return fmt.Sprintf("%d menit", minutes)
+	return i18n.T(language, "duration.minutes", "Minutes", minutes)
+}
+
+// CalculateWorkDuration renders the duration between check-in and check-out times, less breaks, in
+// the given language
+func CalculateWorkDuration(checkIn, checkOut time.Time, breaks time.Duration, language string) string {
+	return NewWorkDuration(checkIn, checkOut, breaks).Format(language)
+}
+
+// clock returns the current time; tests pin it to check behaviour around midnight in Location
+var clock = time.Now
+
+// Now returns the current time in Location
+func Now() time.Time {
+	return clock().In(Location)
+}
+
+// Clock is a time of day, e.g. when a scheduled report is sent
+type Clock struct {
+	Hour   int
+	Minute int
+}
+
+// ParseClock parses a time of day in HH:MM format
+func ParseClock(value string) (Clock, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return Clock{}, err
+	}
+	return Clock{Hour: parsed.Hour(), Minute: parsed.Minute()}, nil
+}
+
+// On returns the clock time on t's day, in t's location
+func (c Clock) On(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), c.Hour, c.Minute, 0, 0, t.Location())
+}
+
+// Next returns the first occurrence of the clock time strictly after now
+func (c Clock) Next(now time.Time) time.Time {
+	next := c.On(now)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Minutes returns the minutes since midnight
+func (c Clock) Minutes() int {
+	return c.Hour*60 + c.Minute
 }
 
-// NowInJakarta returns the current time in Jakarta timezone
-func NowInJakarta() time.Time {
-	return time.Now().In(JakartaLocation)
+// String formats the clock as HH:MM
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", c.Hour, c.Minute)
 }
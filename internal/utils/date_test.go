@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"attendance-bot/pkg/models"
+	"testing"
+	"time"
+)
+
+// pinClock makes Now return instant for the rest of the test
+func pinClock(t *testing.T, instant time.Time) {
+	t.Helper()
+
+	previous := clock
+	clock = func() time.Time { return instant }
+	t.Cleanup(func() { clock = previous })
+}
+
+// TestEarlyMorningInLocation pins the clock between 00:00 and 07:00 WIB, when the UTC date is
+// still the previous day: dates and day checks must follow Location, not UTC
+func TestEarlyMorningInLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		at   time.Time // UTC instant
+	}{
+		{"midnight", time.Date(2024, 3, 3, 17, 0, 0, 0, time.UTC)},
+		{"01:30 WIB", time.Date(2024, 3, 3, 18, 30, 0, 0, time.UTC)},
+		{"06:59 WIB", time.Date(2024, 3, 3, 23, 59, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pinClock(t, tt.at)
+
+			if got := GetTodayDate(); got != "2024-03-04" {
+				t.Errorf("GetTodayDate() = %q, want %q", got, "2024-03-04")
+			}
+			if got := Now(); got.Location() != Location || got.Day() != 4 {
+				t.Errorf("Now() = %v, want 4 March in %v", got, Location)
+			}
+			if got := FormatDate(Now(), "dd MMMM yyyy"); got != "04 Maret 2024" {
+				t.Errorf("FormatDate(Now()) = %q, want %q", got, "04 Maret 2024")
+			}
+
+			earlierToday := time.Date(2024, 3, 3, 17, 0, 0, 0, time.UTC)
+			lateYesterday := time.Date(2024, 3, 3, 16, 59, 0, 0, time.UTC)
+			if !IsToday(earlierToday) {
+				t.Errorf("IsToday(%v) = false, want true", earlierToday)
+			}
+			if IsToday(lateYesterday) || !IsYesterday(lateYesterday) {
+				t.Errorf("IsToday/IsYesterday(%v) = %v/%v, want false/true", lateYesterday, IsToday(lateYesterday), IsYesterday(lateYesterday))
+			}
+		})
+	}
+}
+
+func TestParseDateIsMidnightInLocation(t *testing.T) {
+	date, err := ParseDate("2024-03-04")
+	if err != nil {
+		t.Fatalf("ParseDate: %v", err)
+	}
+	if want := time.Date(2024, 3, 4, 0, 0, 0, 0, Location); !date.Equal(want) {
+		t.Errorf("ParseDate() = %v, want %v", date, want)
+	}
+	if got := FormatDate(date, "yyyy-MM-dd"); got != "2024-03-04" {
+		t.Errorf("formatted back = %q, want %q", got, "2024-03-04")
+	}
+}
+
+// TestIsLateReadsHourInLocation checks check-ins carrying UTC, whose own hour is 7 hours behind
+func TestIsLateReadsHourInLocation(t *testing.T) {
+	tests := []struct {
+		name    string
+		checkIn time.Time
+		want    bool
+	}{
+		{"06:30 WIB", time.Date(2024, 3, 3, 23, 30, 0, 0, time.UTC), false},
+		{"08:59 WIB", time.Date(2024, 3, 4, 1, 59, 0, 0, time.UTC), false},
+		{"09:00 WIB", time.Date(2024, 3, 4, 2, 0, 0, 0, time.UTC), true},
+		{"15:00 WIB", time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsLate(tt.checkIn, models.BuiltinShift); got != tt.want {
+				t.Errorf("IsLate(%v) = %v, want %v", tt.checkIn, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLateWithShifts(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, Location)
+	}
+	morning := models.Shift{Name: "morning", Start: "08:00", End: "16:00", GraceMinutes: 15}
+	night := models.Shift{Name: "night", Start: "22:00", End: "06:00", GraceMinutes: 10}
+
+	tests := []struct {
+		name    string
+		shift   models.Shift
+		checkIn time.Time
+		want    bool
+	}{
+		{"early", morning, at(4, 7, 45), false},
+		{"within the grace period", morning, at(4, 8, 14), false},
+		{"end of the grace period", morning, at(4, 8, 15), true},
+		{"afternoon", morning, at(4, 13, 0), true},
+		{"night, before the start", night, at(4, 21, 50), false},
+		{"night, within the grace period", night, at(4, 22, 9), false},
+		{"night, after the grace period", night, at(4, 22, 10), true},
+		{"night, after midnight", night, at(5, 1, 0), true},
+		{"night, after the end", night, at(5, 6, 0), false},
+		{"no grace period", models.Shift{Start: "09:00"}, at(4, 9, 0), true},
+		{"invalid start", models.Shift{Start: "9am"}, at(4, 13, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsLate(tt.checkIn, tt.shift); got != tt.want {
+				t.Errorf("IsLate(%s, %s-%s +%d) = %v, want %v", tt.checkIn.Format("15:04"), tt.shift.Start, tt.shift.End, tt.shift.GraceMinutes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateWorkDuration(t *testing.T) {
+	checkIn := time.Date(2024, 3, 4, 8, 0, 0, 0, Location)
+
+	tests := []struct {
+		name      string
+		checkOut  time.Time
+		breaks    time.Duration
+		want      string
+		wantEN    string
+		countable bool
+	}{
+		{"negative", checkIn.Add(-65 * time.Minute), 0, "⚠️ tidak valid", "⚠️ invalid", false},
+		{"a second before", checkIn.Add(-time.Second), 0, "⚠️ tidak valid", "⚠️ invalid", false},
+		{"zero", checkIn, 0, "0 menit", "0 min", true},
+		{"sub-minute", checkIn.Add(59 * time.Second), 0, "0 menit", "0 min", true},
+		{"minutes", checkIn.Add(45*time.Minute + 30*time.Second), 0, "45 menit", "45 min", true},
+		{"hours and minutes", checkIn.Add(9*time.Hour + 5*time.Minute), 0, "9 jam 5 menit", "9 h 5 min", true},
+		{"breaks deducted", checkIn.Add(9 * time.Hour), time.Hour, "8 jam 0 menit", "8 h 0 min", true},
+		{"breaks beyond the span", checkIn.Add(30 * time.Minute), time.Hour, "0 menit", "0 min", true},
+		{"exactly 24h", checkIn.Add(24 * time.Hour), 0, "24 jam 0 menit", "24 h 0 min", true},
+		{"over 24h", checkIn.Add(24*time.Hour + time.Minute), 0, "> 24 jam ⚠️", "> 24 h ⚠️", false},
+		{"days", checkIn.Add(72 * time.Hour), 0, "> 24 jam ⚠️", "> 24 h ⚠️", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateWorkDuration(checkIn, tt.checkOut, tt.breaks, "id"); got != tt.want {
+				t.Errorf("CalculateWorkDuration() = %q, want %q", got, tt.want)
+			}
+			if got := CalculateWorkDuration(checkIn, tt.checkOut, tt.breaks, "en"); got != tt.wantEN {
+				t.Errorf("CalculateWorkDuration(en) = %q, want %q", got, tt.wantEN)
+			}
+
+			duration := NewWorkDuration(checkIn, tt.checkOut, tt.breaks)
+			if duration.Countable() != tt.countable {
+				t.Errorf("Countable() = %v, want %v", duration.Countable(), tt.countable)
+			}
+			if duration.Duration < 0 {
+				t.Errorf("Duration = %v, want it clamped at 0", duration.Duration)
+			}
+		})
+	}
+}
+
+func TestFormatDateMonths(t *testing.T) {
+	indonesian := []string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+	english := []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+
+	for i := range 12 {
+		date := time.Date(2025, time.Month(i+1), 2, 10, 0, 0, 0, Location)
+		if got, want := FormatDate(date, "dd MMMM yyyy"), "02 "+indonesian[i]+" 2025"; got != want {
+			t.Errorf("FormatDate(%s) = %q, want %q", date.Month(), got, want)
+		}
+		if got, want := FormatDateIn(date, "MMMM yyyy", "en"), english[i]+" 2025"; got != want {
+			t.Errorf("FormatDateIn(%s, en) = %q, want %q", date.Month(), got, want)
+		}
+	}
+}
+
+func TestFormatDateWeekdays(t *testing.T) {
+	tests := []struct {
+		day    int // Of March 2025, which starts on a Saturday
+		want   string
+		wantEN string
+	}{
+		{1, "Sabtu, 01 Maret 2025", "Saturday, 01 March 2025"},
+		{2, "Minggu, 02 Maret 2025", "Sunday, 02 March 2025"},
+		{3, "Senin, 03 Maret 2025", "Monday, 03 March 2025"},
+		{4, "Selasa, 04 Maret 2025", "Tuesday, 04 March 2025"},
+		{5, "Rabu, 05 Maret 2025", "Wednesday, 05 March 2025"},
+		{6, "Kamis, 06 Maret 2025", "Thursday, 06 March 2025"},
+		{7, "Jumat, 07 Maret 2025", "Friday, 07 March 2025"},
+	}
+
+	for _, tt := range tests {
+		date := time.Date(2025, 3, tt.day, 12, 0, 0, 0, Location)
+		if got := FormatDate(date, "EEEE, dd MMMM yyyy"); got != tt.want {
+			t.Errorf("FormatDate(%d March) = %q, want %q", tt.day, got, tt.want)
+		}
+		if got := FormatDateIn(date, "EEEE, dd MMMM yyyy", "en"); got != tt.wantEN {
+			t.Errorf("FormatDateIn(%d March, en) = %q, want %q", tt.day, got, tt.wantEN)
+		}
+	}
+}
+
+func TestFormatDateIn(t *testing.T) {
+	// 23:30 UTC on 31 January is 1 February in Jakarta
+	date := time.Date(2025, 1, 31, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		format   string
+		language string
+		want     string
+	}{
+		{"dd MMMM yyyy", "id", "01 Februari 2025"},
+		{"dd MMMM yyyy", "fr", "01 Februari 2025"}, // Unknown languages fall back to Indonesian
+		{"yyyy-MM-dd", "en", "2025-02-01"},
+		{"dd/MM/yyyy", "id", "01/02/2025"},
+		{"Jan 2", "id", "Feb 1"}, // Other formats are Go layouts
+	}
+
+	for _, tt := range tests {
+		if got := FormatDateIn(date, tt.format, tt.language); got != tt.want {
+			t.Errorf("FormatDateIn(%q, %s) = %q, want %q", tt.format, tt.language, got, tt.want)
+		}
+	}
+}
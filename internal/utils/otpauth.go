@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// OTPAuthKey is the content of an otpauth://totp/ URI, as encoded in the QR codes of
+// authenticator apps and provisioned hardware tokens
+type OTPAuthKey struct {
+	Issuer    string
+	Account   string
+	Secret    string // Normalized base32 secret
+	Algorithm string // SHA1, SHA256 or SHA512
+	Digits    int
+	Period    int // Time step in seconds
+}
+
+// ParseOTPAuthURI parses an otpauth://totp/ URI. Parameters left out get the defaults of the key
+// URI format: SHA1, 6 digits and 30 seconds.
+func ParseOTPAuthURI(uri string) (*OTPAuthKey, error) {
+	parsed, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return nil, fmt.Errorf("invalid otpauth URI: %w", err)
+	}
+	if parsed.Scheme != "otpauth" {
+		return nil, fmt.Errorf("invalid otpauth URI: scheme must be otpauth")
+	}
+	if parsed.Host != "totp" {
+		return nil, fmt.Errorf("invalid otpauth URI: only totp keys are supported, got %q", parsed.Host)
+	}
+
+	key := &OTPAuthKey{Algorithm: "SHA1", Digits: 6, Period: 30}
+
+	// The label is "issuer:account" or just "account"; the issuer parameter takes precedence
+	label := strings.TrimPrefix(parsed.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		key.Issuer, key.Account = strings.TrimSpace(issuer), strings.TrimSpace(account)
+	} else {
+		key.Account = strings.TrimSpace(label)
+	}
+
+	query := parsed.Query()
+	if issuer := query.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+
+	key.Secret = NormalizeSecret(query.Get("secret"))
+	if strings.TrimRight(key.Secret, "=") == "" {
+		return nil, fmt.Errorf("invalid otpauth URI: secret is missing")
+	}
+
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		key.Algorithm = strings.ToUpper(strings.ReplaceAll(algorithm, "-", ""))
+		switch key.Algorithm {
+		case "SHA1", "SHA256", "SHA512":
+		default:
+			return nil, fmt.Errorf("invalid otpauth URI: unsupported algorithm %q", algorithm)
+		}
+	}
+	if digits := query.Get("digits"); digits != "" {
+		if key.Digits, err = strconv.Atoi(digits); err != nil || key.Digits < 6 || key.Digits > 8 {
+			return nil, fmt.Errorf("invalid otpauth URI: digits must be between 6 and 8, got %q", digits)
+		}
+	}
+	if period := query.Get("period"); period != "" {
+		if key.Period, err = strconv.Atoi(period); err != nil || key.Period <= 0 {
+			return nil, fmt.Errorf("invalid otpauth URI: period must be a positive number of seconds, got %q", period)
+		}
+	}
+
+	return key, nil
+}
@@ -4,20 +4,21 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
-// ValidateOTP checks if the provided string is a valid 6-digit OTP
-func ValidateOTP(otp string) bool {
+// ValidateOTP checks if the provided string is a valid OTP with the given number of digits
+func ValidateOTP(otp string, digits int) bool {
 	// Remove any whitespace
 	otp = strings.TrimSpace(otp)
 
-	// Check if it's exactly 6 digits
-	if len(otp) != 6 {
+	// Check if it's exactly the expected number of digits
+	if len(otp) != digits {
 		return false
 	}
 
 	// Check if all characters are digits
-	matched, err := regexp.MatchString(`^\d{6}$`, otp)
+	matched, err := regexp.MatchString(`^\d+$`, otp)
 	if err != nil {
 		return false
 	}
@@ -25,6 +26,26 @@ func ValidateOTP(otp string) bool {
 	return matched
 }
 
+// NormalizeSecret cleans up a base32 secret as typically displayed by authenticator apps:
+// spaces and dashes are removed, letters are uppercased and missing padding is restored
+func NormalizeSecret(secret string) string {
+	secret = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\n', '\r', '-':
+			return -1
+		}
+		return r
+	}, secret)
+
+	secret = strings.ToUpper(strings.TrimRight(secret, "="))
+
+	if remainder := len(secret) % 8; remainder != 0 {
+		secret += strings.Repeat("=", 8-remainder)
+	}
+
+	return secret
+}
+
 // IsValidTelegramUserID checks if the provided user ID is valid
 func IsValidTelegramUserID(userID int64) bool {
 	return userID > 0
@@ -37,16 +58,30 @@ func SanitizeUsername(username string) string {
 	return reg.ReplaceAllString(username, "")
 }
 
-// SanitizeName removes potentially harmful characters from names
+// maxNameLength is the maximum length of a sanitized name, in characters
+const maxNameLength = 50
+
+// SanitizeName removes potentially harmful characters from names. Letters and combining marks of
+// any script are kept, along with spaces, apostrophes and hyphens; digits, symbols, emoji, control
+// characters and Markdown syntax are dropped. Runs of whitespace collapse to a single space.
 func SanitizeName(name string) string {
-	// Allow letters, spaces, apostrophes, and hyphens
-	reg := regexp.MustCompile(`[^a-zA-Z\s'\-]`)
-	cleaned := reg.ReplaceAllString(name, "")
-
-	// Trim whitespace and limit length
-	cleaned = strings.TrimSpace(cleaned)
-	if len(cleaned) > 50 {
-		cleaned = cleaned[:50]
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsMark(r):
+			return r
+		case r == '\'' || r == '’' || r == '-':
+			return r
+		case unicode.IsSpace(r):
+			return ' '
+		}
+		return -1
+	}, name)
+
+	cleaned = strings.Join(strings.Fields(cleaned), " ")
+
+	// Limit length without splitting a multi-byte character
+	if runes := []rune(cleaned); len(runes) > maxNameLength {
+		cleaned = strings.TrimSpace(string(runes[:maxNameLength]))
 	}
 
 	return cleaned
@@ -65,3 +100,9 @@ func IsValidDateFormat(date string) bool {
 	}
 	return matched
 }
+
+// NameKey normalizes a display name for comparison: case-insensitive, with whitespace collapsed,
+// so "budi  SANTOSO" and "Budi Santoso" compare equal
+func NameKey(firstName, lastName string) string {
+	return strings.ToLower(strings.Join(strings.Fields(firstName+" "+lastName), " "))
+}
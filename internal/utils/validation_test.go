@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNormalizeSecret(t *testing.T) {
+	tests := []struct {
+		secret, normalized string
+	}{
+		{"JBSWY3DPEHPK3PXP", "JBSWY3DPEHPK3PXP"},
+		{"jbsw y3dp ehpk 3pxp", "JBSWY3DPEHPK3PXP"},
+		{"JBSW-Y3DP-EHPK-3PXP", "JBSWY3DPEHPK3PXP"},
+		{" jbswy3dp\tehpk3pxp\r\n", "JBSWY3DPEHPK3PXP"},
+		{"GEZDGNBVGY3TQOJQGE", "GEZDGNBVGY3TQOJQGE======"},
+		{"gezd gnbv gy3t qojq ge==", "GEZDGNBVGY3TQOJQGE======"},
+		{"GEZDGNBVGY3TQOJQGE======", "GEZDGNBVGY3TQOJQGE======"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeSecret(tt.secret); got != tt.normalized {
+			t.Errorf("NormalizeSecret(%q) = %q, want %q", tt.secret, got, tt.normalized)
+		}
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"ascii", "Budi Santoso", "Budi Santoso"},
+		{"indonesian accents", "Ngurah Putu Éka", "Ngurah Putu Éka"},
+		{"curly apostrophe", "Nurul À’isyah", "Nurul À’isyah"},
+		{"apostrophe and hyphen", "O'Neil Jean-Luc", "O'Neil Jean-Luc"},
+		{"combining marks", "José Niño", "José Niño"},
+		{"arabic", "عائشة بنت أبي بكر", "عائشة بنت أبي بكر"},
+		{"chinese", "王小明", "王小明"},
+		{"japanese", "山田 太郎", "山田 太郎"},
+		{"korean", "김민준", "김민준"},
+		{"devanagari with marks", "अनुष्का", "अनुष्का"},
+		{"emoji troll", "🔥💯 xX_Budi_Xx 💀🚀", "xXBudiXx"},
+		{"only emoji", "🔥💯💀", ""},
+		{"markdown syntax", "*Budi* _Santoso_ [link](x) `code`", "Budi Santoso linkx code"},
+		{"digits and symbols", "Budi123 #1 @home", "Budi home"},
+		{"control characters", "Bu\x00di\u200b \x1bSari", "Budi Sari"},
+		{"whitespace runs", "  Sari \t\n  Dewi  ", "Sari Dewi"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeName(tt.input); got != tt.want {
+				t.Errorf("SanitizeName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeNameTruncatesRunes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"ascii", strings.Repeat("a", 60), strings.Repeat("a", maxNameLength)},
+		{"two-byte letters", strings.Repeat("é", 60), strings.Repeat("é", maxNameLength)},
+		{"three-byte letters", strings.Repeat("王", 60), strings.Repeat("王", maxNameLength)},
+		{"space at the cut", strings.Repeat("a", maxNameLength-1) + " bcd", strings.Repeat("a", maxNameLength-1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeName(tt.input)
+			if got != tt.want {
+				t.Errorf("SanitizeName() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("SanitizeName() = %q, is not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func TestNameKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		firstName string
+		lastName  string
+		want      string
+	}{
+		{"plain", "Budi", "Santoso", "budi santoso"},
+		{"upper case", "BUDI", "SANTOSO", "budi santoso"},
+		{"mixed case", "bUdI", "sAnToSo", "budi santoso"},
+		{"doubled spaces", "Budi  Santoso", "", "budi santoso"},
+		{"tabs and newlines", "Budi\t", "\nSantoso", "budi santoso"},
+		{"padded", "  Budi ", " Santoso  ", "budi santoso"},
+		{"no-break space", "Budi\u00a0Santoso", "", "budi santoso"},
+		{"first name only", "Budi", "", "budi"},
+		{"unicode case", "ÉKA", "Putu", "éka putu"},
+		{"blank", " ", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NameKey(tt.firstName, tt.lastName); got != tt.want {
+				t.Errorf("NameKey(%q, %q) = %q, want %q", tt.firstName, tt.lastName, got, tt.want)
+			}
+		})
+	}
+}
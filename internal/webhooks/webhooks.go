@@ -0,0 +1,328 @@
+package webhooks
+
+import (
+	"attendance-bot/internal/metrics"
+	"attendance-bot/internal/utils"
+	"attendance-bot/pkg/models"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Events sent to the webhooks
+const (
+	EventCheckIn     = "check_in"
+	EventCheckOut    = "check_out"
+	EventLateArrival = "late_arrival" // Sent after the check_in event of a check-in late for the user's shift
+)
+
+// DefaultMaxAttempts is how often a delivery is tried before it is given up on
+const DefaultMaxAttempts = 5
+
+// Delivery limits
+const (
+	queueSize         = 256
+	workers           = 4
+	requestTimeout    = 10 * time.Second
+	defaultRetryDelay = 2 * time.Second // Delay before the first retry, doubled for each further one
+	maxErrorBodyBytes = 512
+)
+
+// Service is the subset of the attendance service the dispatcher uses
+type Service interface {
+	DisplayName(ctx context.Context, record *models.AttendanceRecord) string
+	GetUserShift(ctx context.Context, userID int64) (models.Shift, error)
+	RecordWebhookFailure(ctx context.Context, failure *models.WebhookFailure) error
+}
+
+// Event is the JSON payload POSTed to the webhooks
+type Event struct {
+	ID         string     `json:"id"`
+	Event      string     `json:"event"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Attendance Attendance `json:"attendance"`
+}
+
+// Attendance describes the record an event is about
+type Attendance struct {
+	RecordID  int64     `json:"record_id"`
+	UserID    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Date      string    `json:"date"`
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+	Shift     string    `json:"shift,omitempty"` // Shift the check-in is judged against
+	Late      bool      `json:"late"`
+}
+
+// Dispatcher POSTs attendance events to the configured URLs in the background. Failed deliveries
+// are retried with exponential backoff and recorded as webhook failures after the last attempt.
+type Dispatcher struct {
+	urls        []string
+	secret      []byte
+	maxAttempts int
+	retryDelay  time.Duration
+	service     Service
+	client      *http.Client
+	queue       chan models.AttendanceRecord
+	logger      *slog.Logger
+}
+
+// NewDispatcher creates a dispatcher sending events to urls, signed with secret unless it is empty
+func NewDispatcher(urls []string, secret string, maxAttempts int, service Service, logger *slog.Logger) *Dispatcher {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	return &Dispatcher{
+		urls:        urls,
+		secret:      []byte(secret),
+		maxAttempts: maxAttempts,
+		retryDelay:  defaultRetryDelay,
+		service:     service,
+		client:      &http.Client{Timeout: requestTimeout},
+		queue:       make(chan models.AttendanceRecord, queueSize),
+		logger:      logger.With("component", "webhooks"),
+	}
+}
+
+// Notify queues the events of a new record without blocking. It is meant as attendance hook.
+func (d *Dispatcher) Notify(record *models.AttendanceRecord) {
+	select {
+	case d.queue <- *record:
+	default:
+		d.logger.Error("Webhook queue full, recording events as failed", "record_id", record.ID)
+		d.failAll(context.Background(), *record, "queue full")
+	}
+}
+
+// Run delivers queued events until ctx is cancelled. Events not delivered by then are recorded as
+// failed, so Run must return before the database is closed.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case record := <-d.queue:
+					d.dispatch(ctx, record)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for {
+		select {
+		case record := <-d.queue:
+			d.failAll(context.WithoutCancel(ctx), record, "not sent before shutdown")
+		default:
+			return
+		}
+	}
+}
+
+// dispatch sends every event of the record to every URL
+func (d *Dispatcher) dispatch(ctx context.Context, record models.AttendanceRecord) {
+	for _, event := range d.events(ctx, record) {
+		body, err := json.Marshal(event)
+		if err != nil {
+			d.logger.Error("Failed to encode webhook event", "event", event.Event, "error", err)
+			continue
+		}
+		for _, target := range d.urls {
+			d.deliver(ctx, target, event, body)
+		}
+	}
+}
+
+// events returns the events of a new record: check_in or check_out, and late_arrival after a late
+// check-in
+func (d *Dispatcher) events(ctx context.Context, record models.AttendanceRecord) []Event {
+	attendance := Attendance{
+		RecordID:  record.ID,
+		UserID:    record.UserID,
+		Username:  record.Username,
+		Name:      d.service.DisplayName(ctx, &record),
+		Type:      record.Type,
+		Date:      record.Date,
+		Timestamp: record.Timestamp,
+		Source:    record.Source,
+	}
+
+	if record.Type == "check_out" {
+		return []Event{newEvent(EventCheckOut, attendance)}
+	}
+
+	shift, err := d.service.GetUserShift(ctx, record.UserID)
+	if err != nil {
+		d.logger.Warn("Failed to get shift for webhook event, not judging lateness", "user_id", record.UserID, "error", err)
+		return []Event{newEvent(EventCheckIn, attendance)}
+	}
+	attendance.Shift = shift.Name
+	attendance.Late = utils.IsLate(record.Timestamp, shift)
+
+	events := []Event{newEvent(EventCheckIn, attendance)}
+	if attendance.Late {
+		events = append(events, newEvent(EventLateArrival, attendance))
+	}
+	return events
+}
+
+// newEvent creates an event with a random ID, which receivers can use to drop duplicates
+func newEvent(name string, attendance Attendance) Event {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		// Without randomness the time still tells events apart well enough
+		id = []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+	}
+	return Event{ID: hex.EncodeToString(id), Event: name, CreatedAt: time.Now().UTC(), Attendance: attendance}
+}
+
+// deliver POSTs the event to target, retrying with exponential backoff, and records it as failed
+// once the attempts are used up, the receiver rejects it or ctx is cancelled
+func (d *Dispatcher) deliver(ctx context.Context, target string, event Event, body []byte) {
+	logger := d.logger.With("url", redactURL(target), "event", event.Event, "event_id", event.ID)
+	delay := d.retryDelay
+
+	var lastErr error
+	attempts := 0
+	for attempts < d.maxAttempts {
+		if attempts > 0 {
+			select {
+			case <-ctx.Done():
+				d.fail(ctx, target, event, body, attempts, fmt.Errorf("not retried before shutdown: %w", lastErr))
+				return
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		attempts++
+		retry, err := d.post(ctx, target, event, body)
+		if err == nil {
+			metrics.WebhookDeliveries.Inc(event.Event, "delivered")
+			logger.Debug("Webhook delivered", "attempts", attempts)
+			return
+		}
+		lastErr = err
+		logger.Warn("Webhook delivery failed", "attempt", attempts, "retry", retry && attempts < d.maxAttempts, "error", err)
+		if !retry {
+			break
+		}
+	}
+
+	d.fail(ctx, target, event, body, attempts, lastErr)
+}
+
+// post sends one attempt, reporting whether a failure is worth retrying: network errors, timeouts,
+// rate limiting and server errors are, other rejections are not
+func (d *Dispatcher) post(ctx context.Context, target string, event Event, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "attendance-bot")
+	req.Header.Set("X-Attendance-Event", event.Event)
+	req.Header.Set("X-Attendance-Delivery", event.ID)
+	req.Header.Set("X-Attendance-Timestamp", timestamp)
+	if len(d.secret) > 0 {
+		req.Header.Set("X-Attendance-Signature", "sha256="+Sign(d.secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return true, nil
+	}
+
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	err = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(excerpt))
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return retry, err
+}
+
+// Sign returns the hex HMAC-SHA256 of "timestamp.body" with the secret, as sent in the
+// X-Attendance-Signature header
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// fail records a delivery given up on
+func (d *Dispatcher) fail(ctx context.Context, target string, event Event, body []byte, attempts int, cause error) {
+	metrics.WebhookDeliveries.Inc(event.Event, "failed")
+	if cause == nil {
+		cause = errors.New("no attempt made")
+	}
+
+	failure := &models.WebhookFailure{
+		CreatedAt: time.Now(),
+		URL:       target,
+		Event:     event.Event,
+		EventID:   event.ID,
+		Payload:   string(body),
+		Attempts:  attempts,
+		LastError: cause.Error(),
+	}
+	// Failures are recorded even when shutdown has cancelled ctx
+	if err := d.service.RecordWebhookFailure(context.WithoutCancel(ctx), failure); err != nil {
+		d.logger.Error("Failed to record webhook failure", "url", redactURL(target), "event", event.Event,
+			"event_id", event.ID, "error", err)
+		return
+	}
+	d.logger.Error("Webhook delivery given up", "url", redactURL(target), "event", event.Event,
+		"event_id", event.ID, "attempts", attempts, "failure_id", failure.ID, "error", cause)
+}
+
+// failAll records every event of a record as failed without trying to send it
+func (d *Dispatcher) failAll(ctx context.Context, record models.AttendanceRecord, reason string) {
+	for _, event := range d.events(ctx, record) {
+		body, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		for _, target := range d.urls {
+			d.fail(ctx, target, event, body, 0, errors.New(reason))
+		}
+	}
+}
+
+// redactURL reduces a webhook URL to its scheme and host for logging, since relay URLs often
+// carry a token in their path
+func redactURL(target string) string {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Host == "" {
+		return "(invalid url)"
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 // AttendanceRecord represents a single attendance entry
 type AttendanceRecord struct {
@@ -10,8 +13,128 @@ type AttendanceRecord struct {
 	FirstName string    `json:"first_name" db:"first_name"`
 	LastName  *string   `json:"last_name,omitempty" db:"last_name"`
 	Timestamp time.Time `json:"timestamp" db:"timestamp"`
-	Type      string    `json:"type" db:"type"` // "check_in" or "check_out"
-	Date      string    `json:"date" db:"date"` // YYYY-MM-DD format
+	Type      string    `json:"type" db:"type"`     // "check_in", "check_out", "break_start" or "break_end"
+	Date      string    `json:"date" db:"date"`     // YYYY-MM-DD format
+	Source    string    `json:"source" db:"source"` // How the attendance was verified, see Source*
+
+	PhotoFileID string   `json:"photo_file_id,omitempty" db:"photo_file_id"` // Telegram file ID of the selfie attached as proof of presence
+	Latitude    *float64 `json:"latitude,omitempty" db:"latitude"`           // Location the user shared before recording, if any
+	Longitude   *float64 `json:"longitude,omitempty" db:"longitude"`
+	Geofence    string   `json:"geofence,omitempty" db:"geofence"` // Geofence the location was in, empty when outside all of them
+
+	OvertimeMinutes int  `json:"overtime_minutes" db:"overtime_minutes"` // Time worked past the end of the shift, set on check-outs only
+	Remote          bool `json:"remote" db:"remote"`                     // Recorded on a day the user works from home, see WFHRequest
+
+	EmployeeID string `json:"employee_id,omitempty" db:"-"` // From the employee directory, only filled in for reports
+	Department string `json:"department,omitempty" db:"-"`
+}
+
+// Attendance record sources
+const (
+	SourceOTP    = "otp"           // Verified with a TOTP or HOTP code
+	SourceBypass = "bypass"        // Verified with an admin-issued bypass code
+	SourceAdmin  = "admin"         // Entered by an administrator
+	SourceKiosk  = "kiosk"         // Verified with the employee's personal code at the office kiosk
+	SourceAuto   = "auto_checkout" // Check-out the bot recorded at the end of the day for a forgotten one
+	SourceSelf   = "self"          // Break the checked-in user started or ended with /break, without a code
+)
+
+// DayAttendance pairs one user's check-in and check-out records for a date
+type DayAttendance struct {
+	Date     string
+	UserID   int64
+	CheckIn  *AttendanceRecord
+	CheckOut *AttendanceRecord
+	Breaks   []*AttendanceRecord // Break starts and ends, in the order of the records
+}
+
+// Record returns the check-in, or the check-out for a day without one, for the user's details
+func (d *DayAttendance) Record() *AttendanceRecord {
+	if d.CheckIn != nil {
+		return d.CheckIn
+	}
+	return d.CheckOut
+}
+
+// BreakTime returns the time spent on breaks, a break not ended counting until the check-out
+func (d *DayAttendance) BreakTime() time.Duration {
+	var until time.Time
+	if d.CheckOut != nil {
+		until = d.CheckOut.Timestamp
+	}
+	return BreakTime(d.Breaks, until)
+}
+
+// BreakTime adds up the breaks among one user's records of a day, sorted by time. A break not
+// ended yet counts until until, the check-out or the current time, or not at all when until is zero.
+func BreakTime(records []*AttendanceRecord, until time.Time) time.Duration {
+	var total time.Duration
+	var start *time.Time
+	for _, record := range records {
+		switch {
+		case record.Type == "break_start" && start == nil:
+			start = &record.Timestamp
+		case record.Type == "break_end" && start != nil:
+			total += max(record.Timestamp.Sub(*start), 0)
+			start = nil
+		}
+	}
+	if start != nil && until.After(*start) {
+		total += until.Sub(*start)
+	}
+	return total
+}
+
+// GroupByDay pairs records by date and user, in the order each pair is first seen.
+// The returned records point into the records slice, so they stay distinct per entry.
+func GroupByDay(records []AttendanceRecord) []DayAttendance {
+	type dayKey struct {
+		date   string
+		userID int64
+	}
+
+	var days []DayAttendance
+	index := make(map[dayKey]int)
+	for i := range records {
+		record := &records[i]
+		key := dayKey{date: record.Date, userID: record.UserID}
+
+		pos, ok := index[key]
+		if !ok {
+			pos = len(days)
+			index[key] = pos
+			days = append(days, DayAttendance{Date: record.Date, UserID: record.UserID})
+		}
+
+		switch record.Type {
+		case "check_in":
+			days[pos].CheckIn = record
+		case "check_out":
+			days[pos].CheckOut = record
+		case "break_start", "break_end":
+			days[pos].Breaks = append(days[pos].Breaks, record)
+		}
+	}
+
+	return days
+}
+
+// WithoutBreaks returns the check-ins and check-outs among records, leaving out break starts and ends
+func WithoutBreaks(records []AttendanceRecord) []AttendanceRecord {
+	kept := make([]AttendanceRecord, 0, len(records))
+	for _, record := range records {
+		if record.Type == "check_in" || record.Type == "check_out" {
+			kept = append(kept, record)
+		}
+	}
+	return kept
+}
+
+// SortDaysNewestFirst orders days by date, newest first, keeping the original order within a date
+func SortDaysNewestFirst(days []DayAttendance) {
+	sort.SliceStable(days, func(i, j int) bool {
+		return days[i].Date > days[j].Date
+	})
 }
 
 // UserAlias represents a user's custom display name
@@ -21,10 +144,485 @@ type UserAlias struct {
 	LastName  *string `json:"last_name,omitempty" db:"last_name"`
 }
 
+// Kinds of names a NameMatch can refer to
+const (
+	NameKindAlias    = "alias"    // A custom display name set with /alias
+	NameKindTelegram = "telegram" // A name from the user's Telegram profile
+)
+
+// NameMatch is a name of a user that normalizes to the same key as another name
+type NameMatch struct {
+	Key       string  `json:"key"` // Normalized name, see utils.NameKey
+	UserID    int64   `json:"user_id"`
+	Kind      string  `json:"kind"`
+	FirstName string  `json:"first_name"`
+	LastName  *string `json:"last_name,omitempty"`
+}
+
+// AliasRequest is an alias awaiting admin approval because it conflicts with another user's name
+type AliasRequest struct {
+	UserID      int64     `json:"user_id" db:"user_id"`
+	FirstName   string    `json:"first_name" db:"first_name"`
+	LastName    *string   `json:"last_name,omitempty" db:"last_name"`
+	RequestedAt time.Time `json:"requested_at" db:"requested_at"`
+}
+
+// Admin roles. The super-admin is configured with SUPER_ADMIN_ID and never stored.
+const (
+	RoleAdmin      = "admin"
+	RoleSuperAdmin = "super_admin"
+)
+
+// Admin is a user granted admin rights by the super-admin
+type Admin struct {
+	UserID  int64     `json:"user_id" db:"user_id"`
+	Role    string    `json:"role" db:"role"`
+	AddedBy int64     `json:"added_by" db:"added_by"`
+	AddedAt time.Time `json:"added_at" db:"added_at"`
+}
+
 // AttendanceStatus represents a user's attendance status for a given day
 type AttendanceStatus struct {
-	HasCheckedIn   bool              `json:"has_checked_in"`
-	HasCheckedOut  bool              `json:"has_checked_out"`
-	CheckInRecord  *AttendanceRecord `json:"check_in_record,omitempty"`
-	CheckOutRecord *AttendanceRecord `json:"check_out_record,omitempty"`
+	HasCheckedIn   bool                `json:"has_checked_in"`
+	HasCheckedOut  bool                `json:"has_checked_out"`
+	CheckInRecord  *AttendanceRecord   `json:"check_in_record,omitempty"`
+	CheckOutRecord *AttendanceRecord   `json:"check_out_record,omitempty"`
+	Breaks         []*AttendanceRecord `json:"breaks,omitempty"` // Break starts and ends, earliest first
+}
+
+// OnBreak reports whether a break was started and not ended before the check-out, if any
+func (s *AttendanceStatus) OnBreak() bool {
+	return !s.HasCheckedOut && len(s.Breaks) > 0 && s.Breaks[len(s.Breaks)-1].Type == "break_start"
+}
+
+// BreakCount returns the number of breaks started
+func (s *AttendanceStatus) BreakCount() int {
+	count := 0
+	for _, record := range s.Breaks {
+		if record.Type == "break_start" {
+			count++
+		}
+	}
+	return count
+}
+
+// BreakTime returns the time spent on breaks, a break not ended counting until the check-out or now
+func (s *AttendanceStatus) BreakTime(now time.Time) time.Duration {
+	if s.HasCheckedOut {
+		now = s.CheckOutRecord.Timestamp
+	}
+	return BreakTime(s.Breaks, now)
+}
+
+// FailedOTP represents a rejected OTP verification attempt
+type FailedOTP struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Username  string    `json:"username" db:"username"`
+	ChatType  string    `json:"chat_type" db:"chat_type"`
+	Code      string    `json:"code" db:"code"` // Truncated, never the full code
+	Timestamp time.Time `json:"timestamp" db:"timestamp"`
+}
+
+// OTPLockout counts a user's failed OTP attempts in the current window and, once they reached the
+// lockout threshold, until when their attempts are refused
+type OTPLockout struct {
+	UserID      int64     `json:"user_id" db:"user_id"`
+	Failures    int       `json:"failures" db:"failures"`
+	WindowStart time.Time `json:"window_start" db:"window_start"` // First failure of the window
+	LockedUntil time.Time `json:"locked_until" db:"locked_until"` // Zero when not locked out
+}
+
+// BypassCode is a single-use code an admin issues to a user who lost their authenticator
+type BypassCode struct {
+	ID           int64      `json:"id" db:"id"`
+	UserID       int64      `json:"user_id" db:"user_id"`
+	CodeHash     string     `json:"-" db:"code_hash"` // The code itself is never stored
+	IssuedBy     int64      `json:"issued_by" db:"issued_by"`
+	IssuedAt     time.Time  `json:"issued_at" db:"issued_at"`
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt       *time.Time `json:"used_at,omitempty" db:"used_at"`
+	AttendanceID *int64     `json:"attendance_id,omitempty" db:"attendance_id"`
+}
+
+// MissingCheckout is a day on which a user checked in but never checked out
+type MissingCheckout struct {
+	Date     string    `json:"date"`
+	UserID   int64     `json:"user_id"`
+	Name     string    `json:"name"` // Display name, preferring the user's alias
+	Username string    `json:"username"`
+	CheckIn  time.Time `json:"check_in"`
+}
+
+// AttendanceDay is one user's earliest check-in and latest check-out of a date, either of which
+// may be missing
+type AttendanceDay struct {
+	Date      string
+	UserID    int64
+	Username  string
+	FirstName string
+	LastName  *string
+	CheckIn   *time.Time
+	CheckOut  *time.Time
+	Overtime  time.Duration // Recorded on the day's check-out
+	Breaks    time.Duration // Time on breaks, not counted as work
+}
+
+// MonthlySummary holds per-employee attendance totals for a month or another period
+type MonthlySummary struct {
+	Month     string               `json:"month,omitempty"` // YYYY-MM, empty for other periods
+	StartDate string               `json:"start_date"`
+	EndDate   string               `json:"end_date"` // The last day counted, today when the period runs past it
+	Users     []MonthlyUserSummary `json:"users"`
+
+	Department  string                     `json:"department,omitempty"`  // Department the summary is limited to, if any
+	Departments []MonthlyDepartmentSummary `json:"departments,omitempty"` // Totals per department, when any user has one
+
+	WorkingDays int       `json:"working_days"`       // Days of the period neither on a weekend nor a holiday
+	Holidays    []Holiday `json:"holidays,omitempty"` // Holidays of the period
+}
+
+// MonthlyDepartmentSummary totals a month's attendance of the users of one department
+type MonthlyDepartmentSummary struct {
+	Name         string        `json:"name"` // Empty for users without a department
+	Users        int           `json:"users"`
+	DaysPresent  int           `json:"days_present"`
+	DaysLate     int           `json:"days_late"`
+	WorkDuration time.Duration `json:"work_duration"`
+	Overtime     time.Duration `json:"overtime"`
+	DaysAbsent   int           `json:"days_absent"`
+}
+
+// MonthlyUserSummary is one employee's attendance totals for a month
+type MonthlyUserSummary struct {
+	UserID           int64         `json:"user_id"`
+	Name             string        `json:"name"` // Display name, preferring the user's alias
+	Username         string        `json:"username"`
+	DaysPresent      int           `json:"days_present"`
+	DaysCheckedIn    int           `json:"days_checked_in"` // Days present with a check-in, the rest only checked out
+	DaysLate         int           `json:"days_late"`
+	AverageCheckIn   time.Duration `json:"average_check_in"` // Since midnight in TIMEZONE
+	WorkDuration     time.Duration `json:"work_duration"`    // Total of the days with a plausible check-out
+	MissingCheckouts int           `json:"missing_checkouts"`
+	DaysAbsent       int           `json:"days_absent"`           // Working days before today without attendance or approved leave
+	Overtime         time.Duration `json:"overtime"`              // Total past the end of the shift, see AttendanceRecord.OvertimeMinutes
+	DaysOvertime     int           `json:"days_overtime"`         // Days with any overtime
+	EmployeeID       string        `json:"employee_id,omitempty"` // From the employee directory
+	Department       string        `json:"department,omitempty"`
+}
+
+// UserLanguage is the language messages to a user are written in
+type UserLanguage struct {
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Language  string    `json:"language" db:"language"`
+	Chosen    bool      `json:"chosen" db:"chosen"` // Set with /language rather than taken from Telegram's language_code
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UserTimezone is the timezone a user set with /timezone, overriding TIMEZONE for their attendance
+type UserTimezone struct {
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Timezone  string    `json:"timezone" db:"timezone"` // IANA name, e.g. Europe/Berlin
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// LiveReport identifies the day's pinned report message kept up to date by the bot
+type LiveReport struct {
+	Date      string `json:"date"` // YYYY-MM-DD the report covers
+	ChatID    int64  `json:"chat_id"`
+	MessageID int64  `json:"message_id"`
+	Closed    bool   `json:"closed"` // The final "laporan ditutup" edit was made
+}
+
+// Subscription represents a chat receiving a scheduled digest
+type Subscription struct {
+	ChatID    int64     `json:"chat_id" db:"chat_id"`
+	UserID    int64     `json:"user_id" db:"user_id"` // User who subscribed the chat
+	Digest    string    `json:"digest" db:"digest"`   // e.g. "daily"
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// OfficeGroup is a group chat registered with /register as an office channel: the daily report is
+// posted there and members may send their OTPs, which are deleted once processed
+type OfficeGroup struct {
+	ChatID       int64     `json:"chat_id" db:"chat_id"`
+	Title        string    `json:"title" db:"title"`
+	RegisteredBy int64     `json:"registered_by" db:"registered_by"`
+	RegisteredAt time.Time `json:"registered_at" db:"registered_at"`
+}
+
+// RegisteredEmployee is an entry of the employee directory, added with /registeruser. When
+// REQUIRE_REGISTRATION is on, only registered users may mark attendance.
+type RegisteredEmployee struct {
+	UserID       int64     `json:"user_id" db:"user_id"`
+	EmployeeID   string    `json:"employee_id" db:"employee_id"` // The company's own ID, e.g. from payroll
+	Department   string    `json:"department" db:"department"`   // Empty when not assigned
+	RegisteredBy int64     `json:"registered_by" db:"registered_by"`
+	RegisteredAt time.Time `json:"registered_at" db:"registered_at"`
+}
+
+// Session is a user's position in a multi-step conversation with the bot, stored so that it
+// survives restarts. Kiosk conversations are keyed by the kiosk chat ID instead of a user ID.
+type Session struct {
+	UserID    int64     `json:"user_id" db:"user_id"`
+	State     string    `json:"state" db:"state"`
+	Payload   string    `json:"payload" db:"payload"` // Flow-specific JSON, empty when the flow carries none
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// Holiday is a day off for everyone, kept with /holiday. Holidays and weekends are not working days.
+type Holiday struct {
+	Date    string    `json:"date" db:"date"` // YYYY-MM-DD format
+	Name    string    `json:"name" db:"name"`
+	Source  string    `json:"source" db:"source"`     // How the holiday was added, see HolidaySource*
+	AddedBy int64     `json:"added_by" db:"added_by"` // Admin who added or imported it
+	AddedAt time.Time `json:"added_at" db:"added_at"`
+}
+
+// Holiday sources
+const (
+	HolidaySourceAdmin  = "admin"  // Added by an admin with /holiday add
+	HolidaySourceImport = "import" // Imported from the national holiday calendar
+	HolidaySourceConfig = "config" // Listed in the HOLIDAYS setting, never stored
+)
+
+// WebhookFailure is an event webhook delivery given up on after its last attempt, kept so it can
+// be inspected and resent by hand
+type WebhookFailure struct {
+	ID        int64     `json:"id" db:"id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	URL       string    `json:"url" db:"url"`
+	Event     string    `json:"event" db:"event"`
+	EventID   string    `json:"event_id" db:"event_id"`
+	Payload   string    `json:"payload" db:"payload"`
+	Attempts  int       `json:"attempts" db:"attempts"`
+	LastError string    `json:"last_error" db:"last_error"`
+}
+
+// Geofence is a circular office area check-ins are expected to be made from
+type Geofence struct {
+	Name      string  `json:"name" db:"name"`
+	Latitude  float64 `json:"latitude" db:"latitude"`
+	Longitude float64 `json:"longitude" db:"longitude"`
+	Radius    int     `json:"radius_m" db:"radius_m"` // Meters
+}
+
+// Audit log actions
+const (
+	AuditAttendanceAdded      = "attendance_added"      // An admin added a missing attendance record
+	AuditAttendanceChanged    = "attendance_changed"    // An admin changed the time of an attendance record
+	AuditAttendanceDeleted    = "attendance_deleted"    // An admin deleted an attendance record
+	AuditAttendanceImported   = "attendance_imported"   // An admin imported historical attendance from a CSV file
+	AuditReportDownloaded     = "report_downloaded"     // A report file was sent to an admin or exported over the API
+	AuditAliasSet             = "alias_set"             // A user set their own alias, or the CLI set one
+	AuditAliasApproved        = "alias_approved"        // An admin approved a conflicting alias
+	AuditAliasRejected        = "alias_rejected"        // An admin rejected a conflicting alias
+	AuditAliasCleared         = "alias_cleared"         // An alias was removed
+	AuditOTPFailed            = "otp_failed"            // A user sent an OTP that was rejected
+	AuditOTPLocked            = "otp_locked"            // A user was locked out after too many rejected OTPs
+	AuditOTPUnlocked          = "otp_unlocked"          // An admin lifted a user's lockout
+	AuditGroupRegistered      = "group_registered"      // An admin registered a group chat as an office channel
+	AuditGroupUnregistered    = "group_unregistered"    // A group chat stopped being an office channel
+	AuditTOTPEnrolled         = "totp_enrolled"         // An admin sent a user the QR code of the shared secret
+	AuditTOTPRotated          = "totp_rotated"          // An admin replaced the shared secret with /rotatesecret
+	AuditEmployeeRegistered   = "employee_registered"   // An admin added a user to the employee directory or changed their entry
+	AuditEmployeeUnregistered = "employee_unregistered" // An admin removed a user from the employee directory
+	AuditHolidayAdded         = "holiday_added"         // An admin added a holiday or renamed one
+	AuditHolidayRemoved       = "holiday_removed"       // An admin removed a holiday
+	AuditHolidaysImported     = "holidays_imported"     // An admin imported the national holidays of a year
+	AuditBackupCreated        = "backup_created"        // A database backup was made, by an admin or on schedule
+	AuditAdminAdded           = "admin_added"           // An admin was granted admin rights with cmd/admin
+	AuditAdminRemoved         = "admin_removed"         // An admin's rights were revoked with cmd/admin
+)
+
+// AuditEntry is a sensitive operation recorded in the audit log: who did what, to whom and when
+type AuditEntry struct {
+	ID           int64     `json:"id" db:"id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ActorID      int64     `json:"actor_id" db:"actor_id"`                       // Telegram user ID, 0 for the server CLI and the API
+	Action       string    `json:"action" db:"action"`                           // See Audit* actions
+	TargetUserID int64     `json:"target_user_id,omitempty" db:"target_user_id"` // 0 when the change concerns no user
+	RecordID     int64     `json:"record_id,omitempty" db:"record_id"`           // Attendance record changed, 0 when none
+	Details      string    `json:"details" db:"details"`                         // What changed, e.g. "check_out 2024-01-15: - → 17:30"
+	Reason       string    `json:"reason,omitempty" db:"reason"`
+}
+
+// ReminderPreference is a user's opt-in to reminders before their shift starts and when it ends
+type ReminderPreference struct {
+	UserID      int64     `json:"user_id" db:"user_id"`
+	LeadMinutes int       `json:"lead_minutes" db:"lead_minutes"` // Minutes before the shift start the check-in reminder is sent
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// Reminder is a reminder due for a user: Type is the attendance ("check_in" or "check_out") the
+// user has not recorded yet
+type Reminder struct {
+	UserID int64
+	Type   string
+	Shift  Shift
+}
+
+// LateArrival is a check-in after the start and grace period of the user's shift
+type LateArrival struct {
+	Record AttendanceRecord
+	Shift  Shift
+}
+
+// Employee is a user with the name their attendance is recorded under
+type Employee struct {
+	UserID    int64   `json:"user_id"`
+	Username  string  `json:"username"` // Empty if the user never recorded attendance
+	FirstName string  `json:"first_name"`
+	LastName  *string `json:"last_name,omitempty"`
+}
+
+// HOTPEnrollment represents a user enrolled in counter-based one-time codes
+type HOTPEnrollment struct {
+	UserID  int64  `json:"user_id" db:"user_id"`
+	Secret  string `json:"-" db:"-"` // Decrypted on demand, never stored in plaintext
+	Counter uint64 `json:"counter" db:"counter"`
+}
+
+// UserSecret represents a per-user secret encrypted at rest
+type UserSecret struct {
+	UserID     int64  `json:"user_id" db:"user_id"`
+	Ciphertext []byte `json:"-" db:"ciphertext"`
+	Nonce      []byte `json:"-" db:"nonce"`
+}
+
+// Leave types
+const (
+	LeaveCuti  = "cuti"  // Annual leave
+	LeaveIzin  = "izin"  // Permitted absence
+	LeaveSakit = "sakit" // Sick leave
+)
+
+// LeaveTypeLabels are the names of the leave types shown in reports
+var LeaveTypeLabels = map[string]string{
+	LeaveCuti:  "Cuti",
+	LeaveIzin:  "Izin",
+	LeaveSakit: "Sakit",
+}
+
+// Leave request statuses
+const (
+	LeavePending  = "pending"
+	LeaveApproved = "approved"
+	LeaveRejected = "rejected"
+)
+
+// Leave is an employee's request to be absent on the days from StartDate to EndDate (inclusive)
+type Leave struct {
+	ID          int64      `json:"id" db:"id"`
+	UserID      int64      `json:"user_id" db:"user_id"`
+	Username    string     `json:"username" db:"username"`
+	FirstName   string     `json:"first_name" db:"first_name"`
+	LastName    *string    `json:"last_name,omitempty" db:"last_name"`
+	Type        string     `json:"type" db:"type"`             // See Leave* types
+	StartDate   string     `json:"start_date" db:"start_date"` // YYYY-MM-DD format
+	EndDate     string     `json:"end_date" db:"end_date"`     // YYYY-MM-DD format
+	Reason      string     `json:"reason" db:"reason"`
+	Status      string     `json:"status" db:"status"` // See Leave* statuses
+	RequestedAt time.Time  `json:"requested_at" db:"requested_at"`
+	DecidedBy   int64      `json:"decided_by,omitempty" db:"decided_by"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty" db:"decided_at"`
+
+	EmployeeID string `json:"employee_id,omitempty" db:"-"` // From the employee directory
+	Department string `json:"department,omitempty" db:"-"`
+}
+
+// Label returns the report name of the leave type
+func (l *Leave) Label() string {
+	if label, ok := LeaveTypeLabels[l.Type]; ok {
+		return label
+	}
+	return l.Type
+}
+
+// WFH request statuses. Without WFH_APPROVAL requests are approved as they are made.
+const (
+	WFHPending  = "pending"
+	WFHApproved = "approved"
+	WFHRejected = "rejected"
+)
+
+// WFHRequest is an employee's request to work from home on a date. Attendance recorded on an
+// approved date is marked remote.
+type WFHRequest struct {
+	ID          int64      `json:"id" db:"id"`
+	UserID      int64      `json:"user_id" db:"user_id"`
+	Username    string     `json:"username" db:"username"`
+	FirstName   string     `json:"first_name" db:"first_name"`
+	LastName    *string    `json:"last_name,omitempty" db:"last_name"`
+	Date        string     `json:"date" db:"date"`     // YYYY-MM-DD format
+	Status      string     `json:"status" db:"status"` // See WFH* statuses
+	RequestedAt time.Time  `json:"requested_at" db:"requested_at"`
+	DecidedBy   int64      `json:"decided_by,omitempty" db:"decided_by"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty" db:"decided_at"`
+}
+
+// LeaveDay is one day of approved leave
+type LeaveDay struct {
+	Date  string `json:"date"`
+	Leave *Leave `json:"leave"`
+}
+
+// ExpandLeave returns the days of the leaves that fall between startDate and endDate (inclusive),
+// ordered by date and then by the order of leaves
+func ExpandLeave(leaves []Leave, startDate, endDate string) []LeaveDay {
+	var days []LeaveDay
+	for i := range leaves {
+		from := max(leaves[i].StartDate, startDate)
+		to := min(leaves[i].EndDate, endDate)
+		day, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			continue
+		}
+		for date := from; date <= to; date = day.Format("2006-01-02") {
+			days = append(days, LeaveDay{Date: date, Leave: &leaves[i]})
+			day = day.AddDate(0, 0, 1)
+		}
+	}
+
+	sort.SliceStable(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days
+}
+
+// DefaultShiftName is the shift that applies to users without an assigned shift, when it exists
+const DefaultShiftName = "default"
+
+// Shift is a working schedule. Check-ins at or after Start plus the grace period are late and
+// check-outs before End are early; an End before Start means the shift ends the next day.
+type Shift struct {
+	Name         string         `json:"name" db:"name"`
+	Start        string         `json:"start" db:"start_time"` // HH:MM in TIMEZONE, or the user's own timezone
+	End          string         `json:"end" db:"end_time"`     // HH:MM like Start, "" when check-outs are not checked
+	GraceMinutes int            `json:"grace_minutes" db:"grace_minutes"`
+	Users        int            `json:"users" db:"-"` // Number of users assigned to the shift
+	Location     *time.Location `json:"-" db:"-"`     // The user's own timezone, set by ShiftAssignments.For; nil for TIMEZONE
+}
+
+// BuiltinShift applies when no shift is assigned and no default shift is defined: late from 9:00
+// with no end
+var BuiltinShift = Shift{Name: DefaultShiftName, Start: "09:00"}
+
+// ShiftAssignments resolves the shift of each user
+type ShiftAssignments struct {
+	Default   Shift
+	Users     map[int64]Shift
+	Locations map[int64]*time.Location // Timezones users set with /timezone, which their shift hours are in
+}
+
+// For returns the user's assigned shift, the default shift, or BuiltinShift on a nil receiver,
+// with the user's own timezone if they set one
+func (a *ShiftAssignments) For(userID int64) Shift {
+	if a == nil {
+		return BuiltinShift
+	}
+	shift, ok := a.Users[userID]
+	if !ok {
+		shift = a.Default
+	}
+	shift.Location = a.Locations[userID]
+	return shift
 }
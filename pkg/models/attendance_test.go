@@ -0,0 +1,112 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWithoutBreaks(t *testing.T) {
+	records := []AttendanceRecord{
+		{ID: 1, Type: "check_in"},
+		{ID: 2, Type: "break_start"},
+		{ID: 3, Type: "break_end"},
+		{ID: 4, Type: "check_out"},
+	}
+
+	kept := WithoutBreaks(records)
+	if len(kept) != 2 || kept[0].ID != 1 || kept[1].ID != 4 {
+		t.Errorf("WithoutBreaks kept %+v, want the check-in and check-out", kept)
+	}
+	if len(records) != 4 {
+		t.Errorf("WithoutBreaks changed its argument to %d records", len(records))
+	}
+}
+
+func TestGroupByDay(t *testing.T) {
+	// Interleaved days and users, as a report query over a range may return them
+	records := []AttendanceRecord{
+		{ID: 1, UserID: 7, Date: "2024-03-04", Type: "check_in"},
+		{ID: 2, UserID: 8, Date: "2024-03-04", Type: "check_in"},
+		{ID: 3, UserID: 7, Date: "2024-03-05", Type: "check_in"},
+		{ID: 4, UserID: 7, Date: "2024-03-04", Type: "break_start"},
+		{ID: 5, UserID: 7, Date: "2024-03-04", Type: "break_end"},
+		{ID: 6, UserID: 7, Date: "2024-03-05", Type: "check_out"},
+		{ID: 7, UserID: 7, Date: "2024-03-04", Type: "check_out"},
+		{ID: 8, UserID: 8, Date: "2024-03-06", Type: "check_out"},
+	}
+
+	type want struct {
+		date     string
+		userID   int64
+		checkIn  int64
+		checkOut int64
+		breaks   []int64
+	}
+	wants := []want{
+		{"2024-03-04", 7, 1, 7, []int64{4, 5}},
+		{"2024-03-04", 8, 2, 0, nil},
+		{"2024-03-05", 7, 3, 6, nil},
+		{"2024-03-06", 8, 0, 8, nil},
+	}
+
+	id := func(record *AttendanceRecord) int64 {
+		if record == nil {
+			return 0
+		}
+		return record.ID
+	}
+
+	days := GroupByDay(records)
+	if len(days) != len(wants) {
+		t.Fatalf("GroupByDay returned %d days, want %d", len(days), len(wants))
+	}
+	for i, w := range wants {
+		day := days[i]
+		if day.Date != w.date || day.UserID != w.userID {
+			t.Errorf("day %d = %s of %d, want %s of %d", i, day.Date, day.UserID, w.date, w.userID)
+		}
+		if id(day.CheckIn) != w.checkIn || id(day.CheckOut) != w.checkOut {
+			t.Errorf("day %d check-in/out = %d/%d, want %d/%d", i, id(day.CheckIn), id(day.CheckOut), w.checkIn, w.checkOut)
+		}
+		var breaks []int64
+		for _, record := range day.Breaks {
+			breaks = append(breaks, record.ID)
+		}
+		if fmt.Sprint(breaks) != fmt.Sprint(w.breaks) {
+			t.Errorf("day %d breaks = %v, want %v", i, breaks, w.breaks)
+		}
+	}
+
+	// Each entry points at its own element of records, never at a shared loop variable
+	if days[0].CheckIn != &records[0] || days[2].CheckOut != &records[5] {
+		t.Error("GroupByDay records do not point into the records slice")
+	}
+}
+
+func TestGroupByDayEmpty(t *testing.T) {
+	if days := GroupByDay(nil); len(days) != 0 {
+		t.Errorf("GroupByDay(nil) = %v, want no days", days)
+	}
+}
+
+func TestSortDaysNewestFirst(t *testing.T) {
+	days := []DayAttendance{
+		{Date: "2024-03-05", UserID: 1},
+		{Date: "2024-03-04", UserID: 1},
+		{Date: "2024-03-06", UserID: 2},
+		{Date: "2024-03-05", UserID: 2},
+		{Date: "2024-03-06", UserID: 1},
+	}
+
+	SortDaysNewestFirst(days)
+
+	var got []string
+	for _, day := range days {
+		got = append(got, fmt.Sprintf("%s/%d", day.Date, day.UserID))
+	}
+	// Days of the same date keep their order
+	want := "[2024-03-06/2 2024-03-06/1 2024-03-05/1 2024-03-05/2 2024-03-04/1]"
+	if fmt.Sprint(got) != want {
+		t.Errorf("SortDaysNewestFirst() = %v, want %s", got, want)
+	}
+}